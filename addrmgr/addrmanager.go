@@ -109,6 +109,11 @@ type AddrManager struct {
 
 	// triedBucketSize is the maximum number of addresses in each tried bucket.
 	triedBucketSize int
+
+	// asmap is the optional AS map used to group addresses by the autonomous
+	// system that announces them.  It is set prior to starting the address
+	// manager and treated as immutable after that.
+	asmap *ASMap
 }
 
 // serializedKnownAddress is used to represent the serializable state of a
@@ -121,6 +126,13 @@ type serializedKnownAddress struct {
 	TimeStamp   int64
 	LastAttempt int64
 	LastSuccess int64
+
+	// AddrType and SrcType are the network address types of the address
+	// and its source.  They were added in version 2 of the serialized format
+	// since the string representation of an address does not necessarily
+	// identify the network it belongs to.
+	AddrType NetAddressType
+	SrcType  NetAddressType
 }
 
 // serializedAddrManager is used to represent the serializable state of an
@@ -131,6 +143,12 @@ type serializedAddrManager struct {
 	Addresses    []*serializedKnownAddress
 	NewBuckets   [newBucketCount][]string
 	TriedBuckets [triedBucketCount][]string
+
+	// ASMapChecksum is the checksum of the AS map that was used to group the
+	// addresses into the buckets or empty when no AS map was used.  It is
+	// optional since the bucket positions only need to be recalculated when
+	// it differs.
+	ASMapChecksum string `json:",omitempty"`
 }
 
 type localAddress struct {
@@ -230,7 +248,11 @@ const (
 	getKnownAddressPercentage = 23
 
 	// serialisationVersion is the current version of the on-disk format.
-	serialisationVersion = 1
+	//
+	// Version 2 adds the network address types of the addresses.  Version 1
+	// files are still loaded and are upgraded the next time the known
+	// addresses are saved.
+	serialisationVersion = 2
 )
 
 // addOrUpdateAddress is a helper function to either update an address already known
@@ -370,13 +392,14 @@ func (a *AddrManager) getOldestAddressIndex(bucket int) int {
 	return idx
 }
 
-// getNewBucket returns a psuedorandom new bucket index for the provided
-// addresses.
-func getNewBucket(key [32]byte, netAddr, srcAddr *NetAddress) int {
+// getNewBucket returns a psuedorandom new bucket index for addresses in the
+// provided network group that were obtained from a source in the provided
+// network group.
+func getNewBucket(key [32]byte, netGroup, srcGroup string) int {
 	data1 := []byte{}
 	data1 = append(data1, key[:]...)
-	data1 = append(data1, []byte(netAddr.GroupKey())...)
-	data1 = append(data1, []byte(srcAddr.GroupKey())...)
+	data1 = append(data1, []byte(netGroup)...)
+	data1 = append(data1, []byte(srcGroup)...)
 	hash1 := chainhash.HashB(data1)
 	hash64 := binary.LittleEndian.Uint64(hash1)
 	hash64 %= newBucketsPerGroup
@@ -384,7 +407,7 @@ func getNewBucket(key [32]byte, netAddr, srcAddr *NetAddress) int {
 	binary.LittleEndian.PutUint64(hashbuf[:], hash64)
 	data2 := []byte{}
 	data2 = append(data2, key[:]...)
-	data2 = append(data2, srcAddr.GroupKey()...)
+	data2 = append(data2, srcGroup...)
 	data2 = append(data2, hashbuf[:]...)
 
 	hash2 := chainhash.HashB(data2)
@@ -392,8 +415,8 @@ func getNewBucket(key [32]byte, netAddr, srcAddr *NetAddress) int {
 }
 
 // getTriedBucket returns a psuedorandom tried bucket index for the provided
-// address.
-func getTriedBucket(key [32]byte, netAddr *NetAddress) int {
+// address which is part of the provided network group.
+func getTriedBucket(key [32]byte, netAddr *NetAddress, netGroup string) int {
 	data1 := []byte{}
 	data1 = append(data1, key[:]...)
 	data1 = append(data1, []byte(netAddr.Key())...)
@@ -404,7 +427,7 @@ func getTriedBucket(key [32]byte, netAddr *NetAddress) int {
 	binary.LittleEndian.PutUint64(hashbuf[:], hash64)
 	data2 := []byte{}
 	data2 = append(data2, key[:]...)
-	data2 = append(data2, netAddr.GroupKey()...)
+	data2 = append(data2, netGroup...)
 	data2 = append(data2, hashbuf[:]...)
 
 	hash2 := chainhash.HashB(data2)
@@ -446,6 +469,7 @@ func (a *AddrManager) savePeers() {
 	sam := new(serializedAddrManager)
 	sam.Version = serialisationVersion
 	copy(sam.Key[:], a.key[:])
+	sam.ASMapChecksum = a.asmapChecksum()
 
 	sam.Addresses = make([]*serializedKnownAddress, len(a.addrIndex))
 	i := 0
@@ -454,6 +478,8 @@ func (a *AddrManager) savePeers() {
 		ska.Addr = k
 		ska.TimeStamp = v.na.Timestamp.Unix()
 		ska.Src = v.srcAddr.Key()
+		ska.AddrType = v.na.Type
+		ska.SrcType = v.srcAddr.Type
 		ska.Attempts = v.attempts
 		ska.LastAttempt = v.lastattempt.Unix()
 		ska.LastSuccess = v.lastsuccess.Unix()
@@ -524,6 +550,26 @@ func (a *AddrManager) loadPeers() {
 	log.Infof("Loaded %d addresses from file '%s'", a.numAddresses(), a.peersFile)
 }
 
+// deserializeNetAddress creates a new address manager network address from the
+// provided serialized address string and network address type.  Version 1 of
+// the serialized format does not include the network address type, so it is
+// determined from the address string in that case.
+func (a *AddrManager) deserializeNetAddress(version int, netAddrType NetAddressType, addr string) (*NetAddress, error) {
+	netAddr, err := a.newAddressFromString(addr)
+	if err != nil {
+		return nil, err
+	}
+	if version < 2 || netAddr.Type == netAddrType {
+		return netAddr, nil
+	}
+
+	// The string representation of the address is ambiguous in the case of
+	// CJDNS addresses since they are also valid IPv6 addresses, so use the
+	// serialized type.
+	return NewNetAddressFromParams(netAddrType, netAddr.IP, netAddr.Port,
+		netAddr.Timestamp, netAddr.Services)
+}
+
 func (a *AddrManager) deserializePeers(filePath string) error {
 	_, err := os.Stat(filePath)
 	if os.IsNotExist(err) {
@@ -542,19 +588,21 @@ func (a *AddrManager) deserializePeers(filePath string) error {
 		return fmt.Errorf("error reading %s: %v", filePath, err)
 	}
 
-	if sam.Version != serialisationVersion {
+	if sam.Version < 1 || sam.Version > serialisationVersion {
 		return fmt.Errorf("unknown version %v in serialized "+
 			"addrmanager", sam.Version)
 	}
 	copy(a.key[:], sam.Key[:])
 
 	for _, v := range sam.Addresses {
-		netAddr, err := a.newAddressFromString(v.Addr)
+		netAddr, err := a.deserializeNetAddress(sam.Version, v.AddrType,
+			v.Addr)
 		if err != nil {
 			return fmt.Errorf("failed to deserialize netaddress "+
 				"%s: %v", v.Addr, err)
 		}
-		srcAddr, err := a.newAddressFromString(v.Src)
+		srcAddr, err := a.deserializeNetAddress(sam.Version, v.SrcType,
+			v.Src)
 		if err != nil {
 			return fmt.Errorf("failed to deserialize netaddress "+
 				"%s: %v", v.Src, err)
@@ -570,6 +618,33 @@ func (a *AddrManager) deserializePeers(filePath string) error {
 		a.addrIndex[ka.na.Key()] = ka
 	}
 
+	// The bucket positions depend on the network groups of the addresses
+	// which in turn depend on the AS map, so they are recalculated when the
+	// AS map differs from the one the addresses were saved with.  Addresses
+	// that no longer fit in their buckets are discarded in that case.
+	rebucket := sam.ASMapChecksum != a.asmapChecksum()
+	if rebucket {
+		log.Infof("AS map changed since the addresses were saved -- "+
+			"recalculating bucket positions of %d addresses",
+			len(a.addrIndex))
+		a.addrChanged = true
+	}
+	addNew := func(bucket int, key string, ka *KnownAddress) {
+		if rebucket {
+			bucket = a.getNewBucket(ka.na, ka.srcAddr)
+			if len(a.addrNew[bucket]) >= newBucketSize {
+				return
+			}
+			if _, ok := a.addrNew[bucket][key]; ok {
+				return
+			}
+		}
+		if ka.refs == 0 {
+			a.nNew++
+		}
+		ka.refs++
+		a.addrNew[bucket][key] = ka
+	}
 	for i := range sam.NewBuckets {
 		for _, val := range sam.NewBuckets[i] {
 			ka, ok := a.addrIndex[val]
@@ -577,12 +652,7 @@ func (a *AddrManager) deserializePeers(filePath string) error {
 				return fmt.Errorf("new buckets contains %s but "+
 					"none in address list", val)
 			}
-
-			if ka.refs == 0 {
-				a.nNew++
-			}
-			ka.refs++
-			a.addrNew[i][val] = ka
+			addNew(i, val, ka)
 		}
 	}
 	for i := range sam.TriedBuckets {
@@ -593,9 +663,26 @@ func (a *AddrManager) deserializePeers(filePath string) error {
 					"none in address list", val)
 			}
 
+			bucket := i
+			if rebucket {
+				// Move the address back to the new buckets when its
+				// tried bucket is full.
+				bucket = a.getTriedBucket(ka.na)
+				if len(a.addrTried[bucket]) >= a.triedBucketSize {
+					addNew(bucket, val, ka)
+					continue
+				}
+			}
 			ka.tried = true
 			a.nTried++
-			a.addrTried[i] = append(a.addrTried[i], ka)
+			a.addrTried[bucket] = append(a.addrTried[bucket], ka)
+		}
+	}
+	if rebucket {
+		for k, v := range a.addrIndex {
+			if v.refs == 0 && !v.tried {
+				delete(a.addrIndex, k)
+			}
 		}
 	}
 
@@ -746,22 +833,91 @@ func (a *AddrManager) reset() {
 	}
 	a.addrChanged = true
 	a.getNewBucket = func(netAddr, srcAddr *NetAddress) int {
-		return getNewBucket(a.key, netAddr, srcAddr)
+		return getNewBucket(a.key, a.GroupKey(netAddr), a.GroupKey(srcAddr))
 	}
 	a.getTriedBucket = func(netAddr *NetAddress) int {
-		return getTriedBucket(a.key, netAddr)
+		return getTriedBucket(a.key, netAddr, a.GroupKey(netAddr))
 	}
 }
 
+// SetASMap sets the AS map used to group addresses by the autonomous system
+// that announces them instead of by their network prefix.  Addresses that are
+// not mapped by the AS map continue to be grouped by their network prefix.
+//
+// This MUST be called prior to starting the address manager.
+func (a *AddrManager) SetASMap(asmap *ASMap) {
+	a.asmap = asmap
+}
+
+// asmapChecksum returns the checksum of the AS map used by the address manager
+// or an empty string when it does not use one.
+func (a *AddrManager) asmapChecksum() string {
+	if a.asmap == nil {
+		return ""
+	}
+	return a.asmap.Checksum()
+}
+
+// ASN returns the autonomous system number the provided address is mapped to
+// by the AS map of the address manager or 0 when it is not mapped or the
+// address manager does not use an AS map.
+//
+// This function is safe for concurrent access.
+func (a *AddrManager) ASN(na *NetAddress) uint32 {
+	if a.asmap == nil {
+		return 0
+	}
+	return a.asmap.ASN(na)
+}
+
+// GroupKey returns a string representing the network group the provided
+// address is part of.  It is the string "as" followed by the autonomous system
+// number when the address is mapped by the AS map of the address manager and
+// the result of the GroupKey method of the address otherwise.
+//
+// Addresses in the same network group are likely to be controlled by the same
+// entity, so the network groups are used to diversify the addresses stored by
+// the address manager and the peers that are connected to.
+//
+// This function is safe for concurrent access.
+func (a *AddrManager) GroupKey(na *NetAddress) string {
+	if asn := a.ASN(na); asn != 0 {
+		return fmt.Sprintf("as%d", asn)
+	}
+	return na.GroupKey()
+}
+
 // HostToNetAddress parses and returns a network address given a hostname in a
-// supported format (IPv4, IPv6, TORv2).  If the hostname cannot be immediately
-// converted from a known address format, it will be resolved using the lookup
-// function provided to the address manager. If it cannot be resolved, an error
-// is returned.
+// supported format (IPv4, IPv6, TORv2, TORv3, I2P, CJDNS).  IPv6 addresses in
+// the fc00::/8 range are considered CJDNS addresses.  If the hostname cannot be
+// immediately converted from a known address format, it will be resolved using
+// the lookup function provided to the address manager. If it cannot be
+// resolved, an error is returned.
 //
 // This function is safe for concurrent access.
 func (a *AddrManager) HostToNetAddress(host string, port uint16, services wire.ServiceFlag) (*NetAddress, error) {
-	// Tor address is 16 char base32 + ".onion"
+	// Tor v3 address is 56 char base32 + ".onion"
+	if len(host) == torV3HostLen+6 && host[torV3HostLen:] == ".onion" {
+		pubKey, err := decodeTorV3Host(host[:torV3HostLen])
+		if err != nil {
+			return nil, err
+		}
+		return NewNetAddressFromParams(TORv3Address, pubKey, port,
+			time.Now(), services)
+	}
+
+	// I2P address is 52 char base32 + ".b32.i2p"
+	if len(host) == i2pHostLen+8 && host[i2pHostLen:] == ".b32.i2p" {
+		hash, err := base32NoPadding.DecodeString(
+			strings.ToUpper(host[:i2pHostLen]))
+		if err != nil {
+			return nil, err
+		}
+		return NewNetAddressFromParams(I2PAddress, hash, port, time.Now(),
+			services)
+	}
+
+	// Tor v2 address is 16 char base32 + ".onion"
 	var ip net.IP
 	if len(host) == 22 && host[16:] == ".onion" {
 		// go base32 encoding uses capitals (as does the rfc
@@ -785,6 +941,10 @@ func (a *AddrManager) HostToNetAddress(host string, port uint16, services wire.S
 		ip = ips[0]
 	}
 
+	if isCJDNS(ip) {
+		return NewNetAddressFromParams(CJDNSAddress, ip, port, time.Now(),
+			services)
+	}
 	return NewNetAddressIPPort(ip, port, services), nil
 }
 
@@ -1009,6 +1169,65 @@ func (a *AddrManager) Good(addr *NetAddress) error {
 	return nil
 }
 
+// Services returns the services last advertised by the provided known address.
+// If the address is unknown then an error is returned.
+//
+// This function is safe for concurrent access.
+func (a *AddrManager) Services(addr *NetAddress) (wire.ServiceFlag, error) {
+	a.mtx.Lock()
+	defer a.mtx.Unlock()
+
+	ka := a.find(addr)
+	if ka == nil {
+		str := fmt.Sprintf("address %s not found", addr)
+		return 0, makeError(ErrAddressNotFound, str)
+	}
+
+	ka.mtx.Lock()
+	services := ka.na.Services
+	ka.mtx.Unlock()
+
+	// Don't report the v2 transport service for addresses that previously
+	// failed to negotiate it since the services of known addresses are
+	// updated with those relayed by other peers, which might incorrectly
+	// claim support for it.
+	if ka.v2TransportFailed {
+		services &^= wire.SFNodeP2PV2
+	}
+	return services, nil
+}
+
+// MarkV2TransportFailed records that negotiating the v2 transport with the
+// provided known address failed.  The v2 transport service is removed from the
+// address and is no longer reported for it by Services, even when other peers
+// relay the address with it again.  This allows callers to fall back to the v1
+// transport for future connections to remote peers that were incorrectly
+// advertised as supporting the v2 transport.  If the address is unknown then
+// an error is returned.
+//
+// This function is safe for concurrent access.
+func (a *AddrManager) MarkV2TransportFailed(addr *NetAddress) error {
+	a.mtx.Lock()
+	defer a.mtx.Unlock()
+
+	ka := a.find(addr)
+	if ka == nil {
+		str := fmt.Sprintf("address %s not found", addr)
+		return makeError(ErrAddressNotFound, str)
+	}
+
+	ka.v2TransportFailed = true
+	if ka.na.Services&wire.SFNodeP2PV2 == wire.SFNodeP2PV2 {
+		// ka.na is immutable, so replace it.
+		ka.mtx.Lock()
+		naCopy := ka.na.Clone()
+		naCopy.Services &^= wire.SFNodeP2PV2
+		ka.na = naCopy
+		ka.mtx.Unlock()
+	}
+	return nil
+}
+
 // SetServices sets the services for the provided known address to the
 // provided value.  If the address is unknown then an error is returned.
 func (a *AddrManager) SetServices(addr *NetAddress, services wire.ServiceFlag) error {
@@ -1116,7 +1335,8 @@ const (
 	// Ipv6Strong represents a connection state between two IPV6 addresses.
 	Ipv6Strong
 
-	// Private represents a connection state connect between two Tor addresses.
+	// Private represents a connection state connect between two addresses
+	// on the same private network such as Tor, I2P, or CJDNS.
 	Private
 )
 
@@ -1129,8 +1349,11 @@ func getReachabilityFrom(localAddr, remoteAddr *NetAddress) NetAddressReach {
 		return Unreachable
 	}
 
-	if isOnionCatTor(remoteAddr.IP) {
-		if isOnionCatTor(localAddr.IP) {
+	isTor := func(netAddr *NetAddress) bool {
+		return netAddr.Type == TORv3Address || isOnionCatTor(netAddr.IP)
+	}
+	if isTor(remoteAddr) {
+		if isTor(localAddr) {
 			return Private
 		}
 
@@ -1141,6 +1364,24 @@ func getReachabilityFrom(localAddr, remoteAddr *NetAddress) NetAddressReach {
 		return Default
 	}
 
+	// I2P and CJDNS addresses are only reachable from the same network.
+	if remoteAddr.Type == I2PAddress || remoteAddr.Type == CJDNSAddress {
+		if localAddr.Type == remoteAddr.Type {
+			return Private
+		}
+		return Unreachable
+	}
+
+	// Local addresses on the private networks are not directly reachable
+	// from IP addresses.
+	switch localAddr.Type {
+	case TORv3Address, I2PAddress, CJDNSAddress:
+		if isIPv4(remoteAddr.IP) {
+			return Unreachable
+		}
+		return Default
+	}
+
 	if isRFC4380(remoteAddr.IP) {
 		if !localAddr.IsRoutable() {
 			return Default
@@ -1219,7 +1460,7 @@ func (a *AddrManager) GetBestLocalAddress(remoteAddr *NetAddress) *NetAddress {
 
 		// Send something unroutable if nothing suitable.
 		var ip net.IP
-		if !isIPv4(remoteAddr.IP) && !isOnionCatTor(remoteAddr.IP) {
+		if remoteAddr.Type == IPv6Address || remoteAddr.Type == CJDNSAddress {
 			ip = net.IPv6zero
 		} else {
 			ip = net.IPv4zero
@@ -12,6 +12,7 @@ import (
 	"os"
 	"path/filepath"
 	"reflect"
+	"strings"
 	"testing"
 	"time"
 
@@ -772,6 +773,135 @@ func TestValidatePeerNa(t *testing.T) {
 	}
 }
 
+// TestPrivateNetworkReachability ensures the reachability of addresses on the
+// private networks such as Tor v3, I2P, and CJDNS from local addresses on the
+// various networks is calculated as expected.
+func TestPrivateNetworkReachability(t *testing.T) {
+	addressManager := New("testPrivateNetworkReachability", nil)
+	hostToNetAddr := func(host string) *NetAddress {
+		na, err := addressManager.HostToNetAddress(host, 8333, 0)
+		if err != nil {
+			t.Fatalf("unexpected error parsing %s: %v", host, err)
+		}
+		return na
+	}
+	torV2 := hostToNetAddr("a5ccbdkubbr2jlcp.onion")
+	torV3 := hostToNetAddr("duckduckgogg42xjoc72x3sjasowoarfbgcmvfimaftt6twag" +
+		"swzczad.onion")
+	i2p := hostToNetAddr("aaaqeayeaudaocajbifqydiob4ibceqtcqkrmfyydenbwha5dy" +
+		"pq.b32.i2p")
+	cjdns := hostToNetAddr("fc32:17ea:e415:c3bf:9808:149d:b5a2:c9aa")
+	ipv4 := hostToNetAddr("12.1.2.3")
+	ipv6 := hostToNetAddr("2003::")
+
+	tests := []struct {
+		name   string
+		local  *NetAddress
+		remote *NetAddress
+		reach  NetAddressReach
+	}{
+		{"torv3 to torv3", torV3, torV3, Private},
+		{"torv2 to torv3", torV2, torV3, Private},
+		{"torv3 to torv2", torV3, torV2, Private},
+		{"routable ipv4 to torv3", ipv4, torV3, Ipv4},
+		{"routable ipv6 to torv3", ipv6, torV3, Default},
+		{"i2p to i2p", i2p, i2p, Private},
+		{"torv3 to i2p", torV3, i2p, Unreachable},
+		{"routable ipv4 to i2p", ipv4, i2p, Unreachable},
+		{"cjdns to cjdns", cjdns, cjdns, Private},
+		{"routable ipv6 to cjdns", ipv6, cjdns, Unreachable},
+		{"torv3 to routable ipv4", torV3, ipv4, Unreachable},
+		{"torv3 to routable ipv6", torV3, ipv6, Default},
+		{"cjdns to routable ipv6", cjdns, ipv6, Default},
+	}
+
+	for _, test := range tests {
+		reach := getReachabilityFrom(test.local, test.remote)
+		if reach != test.reach {
+			t.Errorf("%q: unexpected reach - got %v, want %v", test.name,
+				reach, test.reach)
+		}
+	}
+}
+
+// TestPeersFileUpgrade ensures that peers files in the previous version of the
+// serialized format are loaded and that addresses on the private networks are
+// saved and loaded with their network address types.
+func TestPeersFileUpgrade(t *testing.T) {
+	dir, err := os.MkdirTemp("", "testpeersfileupgrade")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	// Write a version 1 peers file that contains a single IPv4 address in
+	// the first new bucket.
+	const v1Addr = "173.194.115.66:8333"
+	v1Peers := fmt.Sprintf(`{"Version":1,"Key":[0%s],"Addresses":[{"Addr":`+
+		`"%s","Src":"%s","Attempts":0,"TimeStamp":%d,"LastAttempt":0,`+
+		`"LastSuccess":0}],"NewBuckets":[["%s"]%s],"TriedBuckets":[[]%s]}`,
+		strings.Repeat(",0", 31), v1Addr, v1Addr, time.Now().Unix(), v1Addr,
+		strings.Repeat(",[]", newBucketCount-1),
+		strings.Repeat(",[]", triedBucketCount-1))
+	peersFile := filepath.Join(dir, peersFilename)
+	if err := os.WriteFile(peersFile, []byte(v1Peers), 0600); err != nil {
+		t.Fatalf("unable to write peers file: %v", err)
+	}
+
+	// Ensure the address in the version 1 file is loaded.
+	amgr := New(dir, nil)
+	amgr.Start()
+	if ka := amgr.GetAddress(); ka == nil || ka.na.Key() != v1Addr ||
+		ka.na.Type != IPv4Address {
+
+		t.Fatalf("address manager does not contain expected address %s",
+			v1Addr)
+	}
+
+	// Add addresses on each of the private networks and ensure they are
+	// loaded with the same types after the peers file is saved.
+	wantAddrs := []string{
+		"duckduckgogg42xjoc72x3sjasowoarfbgcmvfimaftt6twagswzczad.onion:9108",
+		"aaaqeayeaudaocajbifqydiob4ibceqtcqkrmfyydenbwha5dypq.b32.i2p:0",
+		"[fc32:17ea:e415:c3bf:9808:149d:b5a2:c9aa]:9108",
+	}
+	wantTypes := map[string]NetAddressType{
+		v1Addr:       IPv4Address,
+		wantAddrs[0]: TORv3Address,
+		wantAddrs[1]: I2PAddress,
+		wantAddrs[2]: CJDNSAddress,
+	}
+	for _, addr := range wantAddrs {
+		na, err := amgr.newAddressFromString(addr)
+		if err != nil {
+			t.Fatalf("unexpected error parsing %s: %v", addr, err)
+		}
+		amgr.AddAddresses([]*NetAddress{na}, na)
+	}
+	if err := amgr.Stop(); err != nil {
+		t.Fatalf("address manager failed to stop: %v", err)
+	}
+
+	amgr = New(dir, nil)
+	amgr.Start()
+	defer amgr.Stop()
+	if len(amgr.addrIndex) != len(wantTypes) {
+		t.Fatalf("unexpected number of addresses - got %d, want %d",
+			len(amgr.addrIndex), len(wantTypes))
+	}
+	for addr, wantType := range wantTypes {
+		ka, ok := amgr.addrIndex[addr]
+		if !ok {
+			t.Errorf("address manager does not contain address %s", addr)
+			continue
+		}
+		if ka.na.Type != wantType {
+			t.Errorf("unexpected type for %s - got %v, want %v", addr,
+				ka.na.Type, wantType)
+		}
+	}
+}
+
 // TestHostToNetAddress ensures that HostToNetAddress behaves as expected
 // given valid and invalid host name arguments.
 func TestHostToNetAddress(t *testing.T) {
@@ -779,6 +909,17 @@ func TestHostToNetAddress(t *testing.T) {
 	// lookupFunc provided to the address manager instance for each test.
 	const hostnameForLookup = "hostname.test"
 	const services = wire.SFNodeNetwork
+	const torV3Host = "duckduckgogg42xjoc72x3sjasowoarfbgcmvfimaftt6twagswzczad" +
+		".onion"
+	const i2pHost = "aaaqeayeaudaocajbifqydiob4ibceqtcqkrmfyydenbwha5dypq.b32.i2p"
+	mustNetAddr := func(netAddrType NetAddressType, addrBytes []byte) *NetAddress {
+		na, err := NewNetAddressFromParams(netAddrType, addrBytes, 8333,
+			time.Now(), services)
+		if err != nil {
+			t.Fatalf("unexpected error creating network address: %v", err)
+		}
+		return na
+	}
 
 	tests := []struct {
 		name       string
@@ -803,6 +944,51 @@ func TestHostToNetAddress(t *testing.T) {
 		lookupFunc: nil,
 		wantErr:    true,
 		want:       nil,
+	}, {
+		name:       "valid torv3 address",
+		host:       torV3Host,
+		port:       8333,
+		lookupFunc: nil,
+		wantErr:    false,
+		want: mustNetAddr(TORv3Address, hexToBytes("1d04a1d04a338c6e6ae970"+
+			"bfabee49049d6702250984ca950c01673f4ec034ad")),
+	}, {
+		name:       "torv3 address with invalid checksum",
+		host:       "e" + torV3Host[1:],
+		port:       8333,
+		lookupFunc: nil,
+		wantErr:    true,
+		want:       nil,
+	}, {
+		name:       "torv3 address with invalid version",
+		host:       torV3Host[:55] + "a.onion",
+		port:       8333,
+		lookupFunc: nil,
+		wantErr:    true,
+		want:       nil,
+	}, {
+		name:       "valid i2p address",
+		host:       i2pHost,
+		port:       8333,
+		lookupFunc: nil,
+		wantErr:    false,
+		want: mustNetAddr(I2PAddress, hexToBytes("000102030405060708090a0b"+
+			"0c0d0e0f101112131415161718191a1b1c1d1e1f")),
+	}, {
+		name:       "invalid i2p address",
+		host:       "1" + i2pHost[1:],
+		port:       8333,
+		lookupFunc: nil,
+		wantErr:    true,
+		want:       nil,
+	}, {
+		name:       "valid cjdns address",
+		host:       "fc32:17ea:e415:c3bf:9808:149d:b5a2:c9aa",
+		port:       8333,
+		lookupFunc: nil,
+		wantErr:    false,
+		want: mustNetAddr(CJDNSAddress,
+			net.ParseIP("fc32:17ea:e415:c3bf:9808:149d:b5a2:c9aa")),
 	}, {
 		name: "unresolvable host name",
 		host: hostnameForLookup,
@@ -901,4 +1087,76 @@ func TestSetServices(t *testing.T) {
 		t.Fatalf("netAddrB has invalid services - got %x, want %x",
 			netAddrB.Services, newServiceFlags)
 	}
+
+	// Ensure the updated services are returned for the known address and an
+	// error is returned for the unknown address.
+	gotServices, err := addressManager.Services(netAddr)
+	if err != nil {
+		t.Fatalf("unexpected error getting services: %v", err)
+	}
+	if gotServices != newServiceFlags {
+		t.Fatalf("unexpected services - got %x, want %x", gotServices,
+			newServiceFlags)
+	}
+	unknownAddr := NewNetAddressIPPort(net.ParseIP("4.3.2.1"), 8333, services)
+	if _, err := addressManager.Services(unknownAddr); err == nil {
+		t.Fatal("getting services for unknown address should return error")
+	}
+}
+
+// TestMarkV2TransportFailed ensures that the v2 transport service is no longer
+// reported for a known address once negotiating the v2 transport with it
+// failed, even when other peers relay the address with the service again.
+func TestMarkV2TransportFailed(t *testing.T) {
+	addressManager := New("testMarkV2TransportFailed", nil)
+	const services = wire.SFNodeNetwork | wire.SFNodeP2PV2
+
+	// Attempt to mark an address not known to the address manager.
+	notKnownAddr := NewNetAddressIPPort(net.ParseIP("1.2.3.4"), 8333, services)
+	if err := addressManager.MarkV2TransportFailed(notKnownAddr); err == nil {
+		t.Fatal("marking unknown address should return error")
+	}
+
+	// Add a new address to the address manager that claims to support the v2
+	// transport.
+	netAddr := NewNetAddressIPPort(net.ParseIP("1.2.3.4"), 8333, services)
+	srcAddr := NewNetAddressIPPort(net.ParseIP("5.6.7.8"), 8333, services)
+	addressManager.addOrUpdateAddress(netAddr, srcAddr)
+	gotServices, err := addressManager.Services(netAddr)
+	if err != nil {
+		t.Fatalf("unexpected error getting services: %v", err)
+	}
+	if gotServices != services {
+		t.Fatalf("unexpected services - got %x, want %x", gotServices,
+			services)
+	}
+
+	// Mark the address and ensure the v2 transport service is no longer
+	// reported for it.
+	const wantServices = wire.SFNodeNetwork
+	if err := addressManager.MarkV2TransportFailed(netAddr); err != nil {
+		t.Fatalf("unexpected error marking address: %v", err)
+	}
+	gotServices, err = addressManager.Services(netAddr)
+	if err != nil {
+		t.Fatalf("unexpected error getting services: %v", err)
+	}
+	if gotServices != wantServices {
+		t.Fatalf("unexpected services - got %x, want %x", gotServices,
+			wantServices)
+	}
+
+	// Ensure the v2 transport service is still not reported after the address
+	// is relayed again with a newer timestamp and the service.
+	relayedAddr := NewNetAddressIPPort(net.ParseIP("1.2.3.4"), 8333, services)
+	relayedAddr.Timestamp = netAddr.Timestamp.Add(time.Minute)
+	addressManager.addOrUpdateAddress(relayedAddr, srcAddr)
+	gotServices, err = addressManager.Services(netAddr)
+	if err != nil {
+		t.Fatalf("unexpected error getting services: %v", err)
+	}
+	if gotServices != wantServices {
+		t.Fatalf("unexpected services - got %x, want %x", gotServices,
+			wantServices)
+	}
 }
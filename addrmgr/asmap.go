@@ -0,0 +1,406 @@
+// Copyright (c) 2023 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package addrmgr
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"math/bits"
+	"net"
+	"os"
+)
+
+// An AS map maps IP address prefixes to the autonomous system number (ASN) of
+// the network that announces them.  It is encoded as a compact binary trie in
+// the form of a program for a simple virtual machine which consumes the bits of
+// an IPv6 address, with IPv4 addresses mapped into the ::ffff:0:0/96 prefix,
+// from the most significant bit until the program returns the ASN.  The format
+// is the same one used by Bitcoin Core so existing AS map files may be used.
+//
+// The program is a sequence of instructions that are packed into bits starting
+// from the least significant bit of each byte.  Each instruction consists of
+// an opcode followed by its argument:
+//
+//   RETURN <asn>    - return the provided ASN
+//   JUMP <offset>   - consume an address bit and skip the provided number of
+//                     bits of the program when it is set
+//   MATCH <bits>    - consume the provided address bits and return the
+//                     default ASN when they do not match
+//   DEFAULT <asn>   - set the ASN to return when a later MATCH fails
+//
+// The opcodes and arguments are encoded with a variable length encoding that
+// is described by a minimum value and a list of mantissa sizes.  Each size,
+// except the final one, is preceded by a bit that indicates whether the value
+// exceeds the range of that size in which case the range is added to the value
+// and the next size is considered.  Otherwise, the value is the sum of the
+// preceding ranges, the minimum value, and a mantissa of that size stored from
+// the most significant bit.
+
+const (
+	// asmapInvalid is the value returned when decoding a value from an AS map
+	// fails due to reaching the end of the data.
+	asmapInvalid = ^uint32(0)
+
+	// asmapIPBits is the number of address bits that are looked up in AS
+	// maps.
+	asmapIPBits = 128
+
+	// asmapMaxPadding is the maximum number of zero bits allowed after the
+	// final instruction of an AS map in order to complete the final byte.
+	asmapMaxPadding = 7
+)
+
+// asmapOpcode identifies an AS map instruction.
+type asmapOpcode uint32
+
+// These constants define the AS map instructions.
+const (
+	asmapReturn asmapOpcode = iota
+	asmapJump
+	asmapMatch
+	asmapDefault
+)
+
+var (
+	// asmapOpcodeBitSizes, asmapASNBitSizes, asmapMatchBitSizes, and
+	// asmapJumpBitSizes are the mantissa sizes used to encode opcodes, ASNs,
+	// match arguments, and jump offsets, respectively.
+	asmapOpcodeBitSizes = []uint8{0, 0, 1}
+	asmapASNBitSizes    = []uint8{15, 16, 17, 18, 19, 20, 21, 22, 23, 24}
+	asmapMatchBitSizes  = []uint8{1, 2, 3, 4, 5, 6, 7, 8}
+	asmapJumpBitSizes   = []uint8{5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16,
+		17, 18, 19, 20, 21, 22, 23, 24, 25, 26, 27, 28, 29, 30}
+)
+
+// asmapDecoder decodes the instructions of an AS map program.
+type asmapDecoder struct {
+	data   []byte
+	pos    int
+	endPos int
+}
+
+// bit returns whether the bit of the program at the current position is set
+// and advances the position.
+func (d *asmapDecoder) bit() bool {
+	set := d.data[d.pos>>3]>>(d.pos&7)&1 == 1
+	d.pos++
+	return set
+}
+
+// decode decodes a value with the provided minimum value and mantissa sizes
+// starting at the current position.  It returns asmapInvalid when the end of
+// the program is reached.
+func (d *asmapDecoder) decode(minVal uint32, bitSizes []uint8) uint32 {
+	val := minVal
+	for i, size := range bitSizes {
+		if i != len(bitSizes)-1 {
+			if d.pos == d.endPos {
+				break
+			}
+			if d.bit() {
+				val += 1 << size
+				continue
+			}
+		}
+		for b := int(size) - 1; b >= 0; b-- {
+			if d.pos == d.endPos {
+				return asmapInvalid
+			}
+			if d.bit() {
+				val += 1 << uint(b)
+			}
+		}
+		return val
+	}
+	return asmapInvalid
+}
+
+// opcode decodes the opcode at the current position.
+func (d *asmapDecoder) opcode() asmapOpcode {
+	return asmapOpcode(d.decode(0, asmapOpcodeBitSizes))
+}
+
+// asn decodes the ASN argument at the current position.
+func (d *asmapDecoder) asn() uint32 {
+	return d.decode(1, asmapASNBitSizes)
+}
+
+// match decodes the match argument at the current position.  The bits to
+// match are the bits following the most significant set bit.
+func (d *asmapDecoder) match() uint32 {
+	return d.decode(2, asmapMatchBitSizes)
+}
+
+// jump decodes the jump offset at the current position.
+func (d *asmapDecoder) jump() uint32 {
+	return d.decode(17, asmapJumpBitSizes)
+}
+
+// ASMap houses a validated AS map that maps IP addresses to the autonomous
+// system number (ASN) of the network that announces them.
+//
+// An AS map is immutable and is therefore safe for concurrent access.
+type ASMap struct {
+	data     []byte
+	checksum string
+}
+
+// validateASMap ensures the provided AS map program is well formed.  This
+// entails ensuring every path through the program consumes no more than the
+// available address bits and ends with a return instruction, that all jumps
+// land on instructions that are otherwise unreachable, and that the program
+// does not contain redundant instructions or trailing data.
+func validateASMap(data []byte) error {
+	d := asmapDecoder{data: data, endPos: len(data) * 8}
+
+	// jumps houses the positions of all pending jump targets along with the
+	// number of address bits remaining at them.  They are always ordered from
+	// the furthest to the nearest position.
+	type jumpTarget struct {
+		pos  int
+		bits int
+	}
+	jumps := make([]jumpTarget, 0, asmapIPBits)
+	addrBits := asmapIPBits
+	prevOpcode := asmapJump
+	var hadIncompleteMatch bool
+	for d.pos != d.endPos {
+		if len(jumps) > 0 && d.pos >= jumps[len(jumps)-1].pos {
+			return makeError(ErrInvalidASMap, "jump into the middle of an "+
+				"instruction")
+		}
+
+		opcode := d.opcode()
+		switch opcode {
+		case asmapReturn:
+			if prevOpcode == asmapDefault {
+				return makeError(ErrInvalidASMap, "return instruction "+
+					"immediately follows a default instruction")
+			}
+			if d.asn() == asmapInvalid {
+				return makeError(ErrInvalidASMap, "return instruction "+
+					"extends past the end of the data")
+			}
+			if len(jumps) == 0 {
+				// There is nothing left to execute, so only padding to
+				// complete the final byte is allowed.
+				if d.endPos-d.pos > asmapMaxPadding {
+					return makeError(ErrInvalidASMap, "excessive data after "+
+						"the final instruction")
+				}
+				for d.pos != d.endPos {
+					if d.bit() {
+						return makeError(ErrInvalidASMap, "nonzero padding "+
+							"bit")
+					}
+				}
+				return nil
+			}
+
+			// Continue by executing the next jump target since the
+			// instructions are otherwise unreachable.
+			target := jumps[len(jumps)-1]
+			if d.pos != target.pos {
+				return makeError(ErrInvalidASMap, "unreachable instructions")
+			}
+			addrBits = target.bits
+			jumps = jumps[:len(jumps)-1]
+			prevOpcode = asmapJump
+
+		case asmapJump:
+			jump := d.jump()
+			if jump == asmapInvalid {
+				return makeError(ErrInvalidASMap, "jump instruction extends "+
+					"past the end of the data")
+			}
+			if int64(jump) > int64(d.endPos-d.pos) {
+				return makeError(ErrInvalidASMap, "jump past the end of the "+
+					"data")
+			}
+			if addrBits == 0 {
+				return makeError(ErrInvalidASMap, "jump instruction consumes "+
+					"more than the available address bits")
+			}
+			addrBits--
+			targetPos := d.pos + int(jump)
+			if len(jumps) > 0 && targetPos >= jumps[len(jumps)-1].pos {
+				return makeError(ErrInvalidASMap, "intersecting jumps")
+			}
+			jumps = append(jumps, jumpTarget{pos: targetPos, bits: addrBits})
+			prevOpcode = asmapJump
+
+		case asmapMatch:
+			match := d.match()
+			if match == asmapInvalid {
+				return makeError(ErrInvalidASMap, "match instruction extends "+
+					"past the end of the data")
+			}
+			matchLen := bits.Len32(match) - 1
+			if prevOpcode != asmapMatch {
+				hadIncompleteMatch = false
+			}
+			if matchLen < 8 && hadIncompleteMatch {
+				return makeError(ErrInvalidASMap, "multiple incomplete "+
+					"match instructions in a sequence")
+			}
+			hadIncompleteMatch = matchLen < 8
+			if addrBits < matchLen {
+				return makeError(ErrInvalidASMap, "match instruction "+
+					"consumes more than the available address bits")
+			}
+			addrBits -= matchLen
+			prevOpcode = asmapMatch
+
+		case asmapDefault:
+			if prevOpcode == asmapDefault {
+				return makeError(ErrInvalidASMap, "successive default "+
+					"instructions")
+			}
+			if d.asn() == asmapInvalid {
+				return makeError(ErrInvalidASMap, "default instruction "+
+					"extends past the end of the data")
+			}
+			prevOpcode = asmapDefault
+
+		default:
+			return makeError(ErrInvalidASMap, "instruction extends past the "+
+				"end of the data")
+		}
+	}
+
+	return makeError(ErrInvalidASMap, "end of the data reached without a "+
+		"return instruction")
+}
+
+// NewASMap returns an AS map from the provided encoded AS map after ensuring it
+// is valid.
+func NewASMap(data []byte) (*ASMap, error) {
+	if err := validateASMap(data); err != nil {
+		return nil, err
+	}
+	checksum := sha256.Sum256(data)
+	return &ASMap{
+		data:     data,
+		checksum: hex.EncodeToString(checksum[:]),
+	}, nil
+}
+
+// LoadASMap reads the encoded AS map from the file at the provided path and
+// returns it after ensuring it is valid.
+func LoadASMap(path string) (*ASMap, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	asmap, err := NewASMap(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return asmap, nil
+}
+
+// Checksum returns the hex-encoded SHA-256 hash of the encoded AS map.  It
+// uniquely identifies the AS map.
+func (m *ASMap) Checksum() string {
+	return m.checksum
+}
+
+// lookup returns the ASN the provided IPv6 address, with IPv4 addresses mapped
+// into the ::ffff:0:0/96 prefix, is mapped to or 0 when it is not mapped.
+func (m *ASMap) lookup(ip net.IP) uint32 {
+	d := asmapDecoder{data: m.data, endPos: len(m.data) * 8}
+	ipBit := func(i int) bool {
+		return ip[i>>3]>>(7-uint(i&7))&1 == 1
+	}
+
+	// Note that the conditions that result in 0 being returned below are
+	// not possible since the AS map was validated.
+	addrBits := asmapIPBits
+	var defaultASN uint32
+	for d.pos < d.endPos {
+		switch d.opcode() {
+		case asmapReturn:
+			asn := d.asn()
+			if asn == asmapInvalid {
+				return 0
+			}
+			return asn
+
+		case asmapJump:
+			jump := d.jump()
+			if jump == asmapInvalid || addrBits == 0 ||
+				int64(jump) >= int64(d.endPos-d.pos) {
+
+				return 0
+			}
+			if ipBit(asmapIPBits - addrBits) {
+				d.pos += int(jump)
+			}
+			addrBits--
+
+		case asmapMatch:
+			match := d.match()
+			if match == asmapInvalid {
+				return 0
+			}
+			matchLen := bits.Len32(match) - 1
+			if addrBits < matchLen {
+				return 0
+			}
+			for i := 0; i < matchLen; i++ {
+				want := match>>uint(matchLen-1-i)&1 == 1
+				if ipBit(asmapIPBits-addrBits) != want {
+					return defaultASN
+				}
+				addrBits--
+			}
+
+		case asmapDefault:
+			defaultASN = d.asn()
+			if defaultASN == asmapInvalid {
+				return 0
+			}
+
+		default:
+			return 0
+		}
+	}
+	return 0
+}
+
+// ASN returns the autonomous system number the provided address is mapped to
+// or 0 when it is not mapped.  Only routable IPv4 and IPv6 addresses are
+// mapped.  IPv6 addresses that embed an IPv4 address, such as 6to4, Teredo,
+// and NAT64 addresses, are mapped via the embedded IPv4 address.
+//
+// This function is safe for concurrent access.
+func (m *ASMap) ASN(na *NetAddress) uint32 {
+	if na.Type != IPv4Address && na.Type != IPv6Address {
+		return 0
+	}
+	netIP := net.IP(na.IP)
+	if !IsRoutable(netIP) {
+		return 0
+	}
+
+	switch {
+	case isIPv4(netIP):
+		netIP = netIP.To16()
+	case isRFC6145(netIP), isRFC6052(netIP):
+		netIP = net.IP(netIP[12:16]).To16()
+	case isRFC3964(netIP):
+		netIP = net.IP(netIP[2:6]).To16()
+	case isRFC4380(netIP):
+		// Teredo addresses have the last 4 bytes as the IPv4 address XOR
+		// 0xff.
+		ipv4 := make(net.IP, net.IPv4len)
+		for i, b := range netIP[12:16] {
+			ipv4[i] = b ^ 0xff
+		}
+		netIP = ipv4.To16()
+	}
+	return m.lookup(netIP)
+}
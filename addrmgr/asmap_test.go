@@ -0,0 +1,334 @@
+// Copyright (c) 2023 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package addrmgr
+
+import (
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// asmapProgram houses the bits of an AS map program that is being built for
+// tests.
+type asmapProgram []bool
+
+// encode appends the provided value encoded with the provided minimum value
+// and mantissa sizes.
+func (p asmapProgram) encode(val, minVal uint32, bitSizes []uint8) asmapProgram {
+	val -= minVal
+	for i, size := range bitSizes {
+		if i != len(bitSizes)-1 {
+			if val >= 1<<size {
+				p = append(p, true)
+				val -= 1 << size
+				continue
+			}
+			p = append(p, false)
+		}
+		for b := int(size) - 1; b >= 0; b-- {
+			p = append(p, val>>uint(b)&1 == 1)
+		}
+		return p
+	}
+	panic("value out of range")
+}
+
+// ret appends a return instruction for the provided ASN.
+func (p asmapProgram) ret(asn uint32) asmapProgram {
+	p = p.encode(uint32(asmapReturn), 0, asmapOpcodeBitSizes)
+	return p.encode(asn, 1, asmapASNBitSizes)
+}
+
+// def appends a default instruction for the provided ASN.
+func (p asmapProgram) def(asn uint32) asmapProgram {
+	p = p.encode(uint32(asmapDefault), 0, asmapOpcodeBitSizes)
+	return p.encode(asn, 1, asmapASNBitSizes)
+}
+
+// match appends the match instructions needed to match the provided bits.
+func (p asmapProgram) match(bits ...bool) asmapProgram {
+	for len(bits) > 0 {
+		n := len(bits)
+		if n > 8 {
+			n = 8
+		}
+		match := uint32(1)
+		for _, bit := range bits[:n] {
+			match <<= 1
+			if bit {
+				match |= 1
+			}
+		}
+		p = p.encode(uint32(asmapMatch), 0, asmapOpcodeBitSizes)
+		p = p.encode(match, 2, asmapMatchBitSizes)
+		bits = bits[n:]
+	}
+	return p
+}
+
+// matchBytes appends the match instructions needed to match the bits of the
+// provided bytes.
+func (p asmapProgram) matchBytes(bytes ...byte) asmapProgram {
+	var bits []bool
+	for _, b := range bytes {
+		for i := 7; i >= 0; i-- {
+			bits = append(bits, b>>uint(i)&1 == 1)
+		}
+	}
+	return p.match(bits...)
+}
+
+// branch appends a jump instruction that consumes an address bit followed by
+// the provided programs for when the bit is unset and set, respectively.
+func (p asmapProgram) branch(unset, set asmapProgram) asmapProgram {
+	p = p.encode(uint32(asmapJump), 0, asmapOpcodeBitSizes)
+	p = p.encode(uint32(len(unset)), 17, asmapJumpBitSizes)
+	p = append(p, unset...)
+	return append(p, set...)
+}
+
+// bytes returns the program packed into bytes starting from the least
+// significant bit of each byte.
+func (p asmapProgram) bytes() []byte {
+	data := make([]byte, (len(p)+7)/8)
+	for i, bit := range p {
+		if bit {
+			data[i/8] |= 1 << uint(i%8)
+		}
+	}
+	return data
+}
+
+// testASMapProgram returns an AS map program that maps 1.2.0.0/16 to AS 100,
+// 8.8.0.0/16 to AS 15169, all other IPv4 addresses in 0.0.0.0/4 to AS 64512,
+// and everything else to no AS.
+func testASMapProgram() asmapProgram {
+	// The first bits of 1 and 8 are 0000 followed by 0 and 1, respectively.
+	ipv4Prefix := []byte{0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0xff, 0xff}
+	return asmapProgram{}.
+		matchBytes(ipv4Prefix...).
+		match(false, false, false, false).
+		def(64512).
+		branch(
+			asmapProgram{}.match(false, false, true).matchBytes(2).ret(100),
+			asmapProgram{}.match(false, false, false).matchBytes(8).ret(15169),
+		)
+}
+
+// TestASMapLookup ensures addresses are mapped to the expected autonomous
+// system numbers.
+func TestASMapLookup(t *testing.T) {
+	asmap, err := NewASMap(testASMapProgram().bytes())
+	if err != nil {
+		t.Fatalf("unexpected error creating AS map: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		addr string
+		want uint32
+	}{{
+		name: "IPv4 in first prefix",
+		addr: "1.2.3.4",
+		want: 100,
+	}, {
+		name: "IPv4 in second prefix",
+		addr: "8.8.4.4",
+		want: 15169,
+	}, {
+		name: "IPv4 that only matches default",
+		addr: "9.9.9.9",
+		want: 64512,
+	}, {
+		name: "IPv4 that matches the start of the first prefix",
+		addr: "1.3.3.4",
+		want: 64512,
+	}, {
+		name: "unmapped IPv4",
+		addr: "173.194.115.66",
+		want: 0,
+	}, {
+		name: "unroutable IPv4",
+		addr: "10.0.0.1",
+		want: 0,
+	}, {
+		name: "IPv6",
+		addr: "2001:4860:4860::8888",
+		want: 0,
+	}, {
+		name: "6to4 with embedded IPv4 in first prefix",
+		addr: "2002:0102:0304::1",
+		want: 100,
+	}, {
+		name: "NAT64 with embedded IPv4 in second prefix",
+		addr: "64:ff9b::808:808",
+		want: 15169,
+	}}
+
+	for _, test := range tests {
+		na := NewNetAddressIPPort(net.ParseIP(test.addr), 9108, 0)
+		if got := asmap.ASN(na); got != test.want {
+			t.Errorf("%q: mismatched ASN -- got %d, want %d", test.name, got,
+				test.want)
+		}
+	}
+
+	// Ensure addresses on networks other than IPv4 and IPv6 are not mapped.
+	amgr := New("testasmaplookup", nil)
+	na, err := amgr.newAddressFromString(
+		"duckduckgogg42xjoc72x3sjasowoarfbgcmvfimaftt6twagswzczad.onion:9108")
+	if err != nil {
+		t.Fatalf("unexpected error parsing address: %v", err)
+	}
+	if got := asmap.ASN(na); got != 0 {
+		t.Fatalf("mismatched ASN for Tor address -- got %d, want 0", got)
+	}
+}
+
+// TestASMapValidation ensures malformed AS maps are rejected.
+func TestASMapValidation(t *testing.T) {
+	// A single return instruction is 17 bits and thus has 7 padding bits.
+	valid := testASMapProgram().bytes()
+	padded := asmapProgram{}.ret(1).bytes()
+	nonzeroPadding := append([]byte(nil), padded...)
+	nonzeroPadding[len(nonzeroPadding)-1] |= 0x80
+
+	tests := []struct {
+		name string
+		data []byte
+		err  error
+	}{{
+		name: "valid",
+		data: valid,
+	}, {
+		name: "only return",
+		data: padded,
+	}, {
+		name: "empty",
+		data: nil,
+		err:  ErrInvalidASMap,
+	}, {
+		name: "truncated",
+		data: valid[:len(valid)-1],
+		err:  ErrInvalidASMap,
+	}, {
+		name: "excessive padding",
+		data: append(append([]byte(nil), padded...), 0),
+		err:  ErrInvalidASMap,
+	}, {
+		name: "nonzero padding",
+		data: nonzeroPadding,
+		err:  ErrInvalidASMap,
+	}, {
+		name: "no return",
+		data: asmapProgram{}.def(1).bytes(),
+		err:  ErrInvalidASMap,
+	}, {
+		name: "return after default",
+		data: asmapProgram{}.def(1).ret(2).bytes(),
+		err:  ErrInvalidASMap,
+	}, {
+		name: "successive defaults",
+		data: asmapProgram{}.def(1).def(2).ret(3).bytes(),
+		err:  ErrInvalidASMap,
+	}, {
+		name: "multiple incomplete matches",
+		data: asmapProgram{}.match(true).match(false).ret(1).bytes(),
+		err:  ErrInvalidASMap,
+	}, {
+		name: "consumes too many address bits",
+		data: asmapProgram{}.match(make([]bool, asmapIPBits+1)...).ret(1).
+			bytes(),
+		err: ErrInvalidASMap,
+	}, {
+		name: "unreachable instructions",
+		data: asmapProgram{}.branch(asmapProgram{}.ret(1).ret(2),
+			asmapProgram{}.ret(3)).bytes(),
+		err: ErrInvalidASMap,
+	}}
+
+	for _, test := range tests {
+		_, err := NewASMap(test.data)
+		if !errors.Is(err, test.err) {
+			t.Errorf("%q: mismatched error -- got %v, want %v", test.name,
+				err, test.err)
+		}
+	}
+}
+
+// TestAddrManagerASMap ensures the address manager groups addresses by the
+// autonomous system they are mapped to and recalculates the bucket positions
+// of saved addresses when the AS map changes.
+func TestAddrManagerASMap(t *testing.T) {
+	dir, err := os.MkdirTemp("", "testaddrmanagerasmap")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	asmapFile := filepath.Join(dir, "asmap.dat")
+	err = os.WriteFile(asmapFile, testASMapProgram().bytes(), 0600)
+	if err != nil {
+		t.Fatalf("unable to write AS map: %v", err)
+	}
+	asmap, err := LoadASMap(asmapFile)
+	if err != nil {
+		t.Fatalf("unexpected error loading AS map: %v", err)
+	}
+
+	// Ensure the group keys are the mapped ASN when available and the
+	// network prefix otherwise.
+	mapped := NewNetAddressIPPort(net.ParseIP("8.8.4.4"), 9108, 0)
+	unmapped := NewNetAddressIPPort(net.ParseIP("173.194.115.66"), 9108, 0)
+	amgr := New(dir, nil)
+	if got := amgr.GroupKey(mapped); got != "8.8.0.0" {
+		t.Fatalf("mismatched group key without AS map -- got %q, want %q",
+			got, "8.8.0.0")
+	}
+	amgr.SetASMap(asmap)
+	if got := amgr.GroupKey(mapped); got != "as15169" {
+		t.Fatalf("mismatched group key -- got %q, want %q", got, "as15169")
+	}
+	if got := amgr.GroupKey(unmapped); got != "173.194.0.0" {
+		t.Fatalf("mismatched group key -- got %q, want %q", got,
+			"173.194.0.0")
+	}
+	if got := amgr.ASN(mapped); got != 15169 {
+		t.Fatalf("mismatched ASN -- got %d, want 15169", got)
+	}
+
+	// Save addresses without an AS map and ensure they are all loaded into
+	// the buckets for the AS map when loaded with one.
+	amgr = New(dir, nil)
+	amgr.Start()
+	amgr.AddAddresses([]*NetAddress{mapped, unmapped}, unmapped)
+	if err := amgr.Good(unmapped); err != nil {
+		t.Fatalf("unexpected error marking address good: %v", err)
+	}
+	if err := amgr.Stop(); err != nil {
+		t.Fatalf("address manager failed to stop: %v", err)
+	}
+
+	amgr = New(dir, nil)
+	amgr.SetASMap(asmap)
+	amgr.Start()
+	defer amgr.Stop()
+	if amgr.nNew != 1 || amgr.nTried != 1 {
+		t.Fatalf("unexpected number of addresses -- got %d new and %d "+
+			"tried, want 1 new and 1 tried", amgr.nNew, amgr.nTried)
+	}
+	ka := amgr.addrIndex[mapped.Key()]
+	if ka == nil {
+		t.Fatalf("address manager does not contain address %s", mapped)
+	}
+	bucket := amgr.getNewBucket(ka.na, ka.srcAddr)
+	if _, ok := amgr.addrNew[bucket][mapped.Key()]; !ok {
+		t.Fatalf("address %s is not in new bucket %d", mapped, bucket)
+	}
+	if !amgr.addrChanged {
+		t.Fatal("address manager was not marked changed after rebucketing")
+	}
+}
@@ -29,6 +29,12 @@ generally helps provide greater peer diversity, and perhaps more importantly,
 drastically reduces the chances an attacker is able to coerce your peer into
 only connecting to nodes they control.
 
+By default, the groups are based on the network prefixes of the addresses.
+Since a single entity, such as a large hosting provider, may control many
+network prefixes, an AS map that maps addresses to the autonomous system that
+announces them may optionally be provided via SetASMap in which case addresses
+are grouped by their autonomous system instead.
+
 The address manager also understands routability and Tor addresses and tries
 hard to only return routable addresses.  In addition, it uses the information
 provided by the caller about connected, known good, and attempted addresses to
@@ -14,6 +14,17 @@ const (
 	// ErrAddressNotFound indicates that an operation in the address manager
 	// failed due to an address lookup failure.
 	ErrAddressNotFound = ErrorKind("ErrAddressNotFound")
+
+	// ErrUnknownAddressType indicates that a network address is of a type
+	// that is not supported by the address manager.
+	ErrUnknownAddressType = ErrorKind("ErrUnknownAddressType")
+
+	// ErrMismatchedAddressType indicates that the bytes of a network address
+	// are not valid for the type of the address.
+	ErrMismatchedAddressType = ErrorKind("ErrMismatchedAddressType")
+
+	// ErrInvalidASMap indicates that an AS map is malformed.
+	ErrInvalidASMap = ErrorKind("ErrInvalidASMap")
 )
 
 // Error satisfies the error interface and prints human-readable errors.
@@ -7,5 +7,6 @@ require (
 	github.com/EXCCoin/exccd/chaincfg/v3 v3.0.0-20230214161233-275859970533 // indirect
 	github.com/EXCCoin/exccd/wire v0.0.0-20231114084634-503e41f75524
 	github.com/decred/slog v1.2.0
+	golang.org/x/crypto v0.15.0
 	golang.org/x/net v0.7.0 // indirect
 )
@@ -39,6 +39,11 @@ type KnownAddress struct {
 	// exists in.  This is updated as the address moves between new and tried
 	// buckets.
 	refs int
+
+	// v2TransportFailed indicates whether an attempt to negotiate the v2
+	// transport with the address failed.  The v2 transport service is not
+	// reported for the address when it is set.
+	v2TransportFailed bool
 }
 
 // NetAddress returns the underlying wire.NetAddress associated with the
@@ -6,19 +6,92 @@ package addrmgr
 
 import (
 	"encoding/base32"
+	"fmt"
 	"net"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/EXCCoin/exccd/wire"
+	"golang.org/x/crypto/sha3"
 )
 
+const (
+	// torV3Version is the version byte that is encoded in Tor v3 onion
+	// addresses.
+	torV3Version = 0x03
+
+	// torV3HostLen is the length of the host portion of a Tor v3 onion
+	// address, which is the base32 encoding of the 32 byte public key, 2 byte
+	// checksum, and 1 byte version, excluding the .onion suffix.
+	torV3HostLen = 56
+
+	// i2pHostLen is the length of the host portion of an I2P address, which
+	// is the unpadded base32 encoding of the 32 byte destination hash,
+	// excluding the .b32.i2p suffix.
+	i2pHostLen = 52
+)
+
+// base32NoPadding is the base32 encoding without padding used by Tor v3 onion
+// and I2P addresses.
+var base32NoPadding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// torV3Checksum returns the checksum that is encoded in Tor v3 onion addresses
+// for the provided public key.
+func torV3Checksum(pubKey []byte) [2]byte {
+	h := sha3.New256()
+	h.Write([]byte(".onion checksum"))
+	h.Write(pubKey)
+	h.Write([]byte{torV3Version})
+	var checksum [2]byte
+	copy(checksum[:], h.Sum(nil))
+	return checksum
+}
+
+// encodeTorV3Host returns the .onion host for the provided Tor v3 public key.
+func encodeTorV3Host(pubKey []byte) string {
+	var data [35]byte
+	copy(data[:32], pubKey)
+	checksum := torV3Checksum(pubKey)
+	copy(data[32:34], checksum[:])
+	data[34] = torV3Version
+	return strings.ToLower(base32NoPadding.EncodeToString(data[:])) + ".onion"
+}
+
+// decodeTorV3Host returns the public key encoded in the provided Tor v3 host
+// without the .onion suffix after ensuring its version and checksum are valid.
+func decodeTorV3Host(host string) ([]byte, error) {
+	// go base32 encoding uses capitals (as does the rfc but Tor tends to use
+	// lowercase, so we switch case here.
+	data, err := base32NoPadding.DecodeString(strings.ToUpper(host))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) != 35 {
+		return nil, fmt.Errorf("invalid tor v3 address length %d", len(data))
+	}
+	if data[34] != torV3Version {
+		return nil, fmt.Errorf("unsupported tor address version %d", data[34])
+	}
+	pubKey := data[:32]
+	checksum := torV3Checksum(pubKey)
+	if data[32] != checksum[0] || data[33] != checksum[1] {
+		return nil, fmt.Errorf("invalid tor v3 address checksum")
+	}
+	return pubKey, nil
+}
+
 // NetAddress defines information about a peer on the network.
 type NetAddress struct {
+	// Type is the network the address belongs to.  It determines how the IP
+	// field is interpreted.
+	Type NetAddressType
+
 	// IP address of the peer. It is defined as a byte array to support various
 	// address types that are not standard to the net module and therefore not
-	// entirely appropriate to store as a net.IP.
+	// entirely appropriate to store as a net.IP.  For Tor v3 addresses it is
+	// the 32 byte public key of the hidden service and for I2P addresses it is
+	// the 32 byte hash of the destination.
 	IP []byte
 
 	// Port is the port of the remote peer.
@@ -34,14 +107,29 @@ type NetAddress struct {
 // IsRoutable returns a boolean indicating whether the network address is
 // routable.
 func (netAddr *NetAddress) IsRoutable() bool {
+	switch netAddr.Type {
+	case TORv3Address, I2PAddress:
+		return len(netAddr.IP) == 32
+	case CJDNSAddress:
+		return isCJDNS(netAddr.IP)
+	}
 	return IsRoutable(netAddr.IP)
 }
 
 // ipString returns a string representation of the network address' IP field.
-// If the ip is in the range used for TORv2 addresses then it will be
-// transformed into the respective .onion address.  It does not include the
-// port.
+// Tor v3 and I2P addresses are transformed into their respective .onion and
+// .b32.i2p addresses.  Similarly, if the ip is in the range used for TORv2
+// addresses then it will be transformed into the respective .onion address.
+// It does not include the port.
 func (netAddr *NetAddress) ipString() string {
+	switch netAddr.Type {
+	case TORv3Address:
+		return encodeTorV3Host(netAddr.IP)
+	case I2PAddress:
+		host := base32NoPadding.EncodeToString(netAddr.IP)
+		return strings.ToLower(host) + ".b32.i2p"
+	}
+
 	netIP := netAddr.IP
 	if isOnionCatTor(netIP) {
 		// We know now that na.IP is long enough.
@@ -95,13 +183,65 @@ func (a *AddrManager) newAddressFromString(addr string) (*NetAddress, error) {
 }
 
 // NewNetAddressIPPort creates a new address manager network address given an ip,
-// port, and the supported service flags for the address.
+// port, and the supported service flags for the address.  The type of the
+// address is IPv4Address, IPv6Address, or TORv2Address depending on the ip.
 func NewNetAddressIPPort(ip net.IP, port uint16, services wire.ServiceFlag) *NetAddress {
+	netAddrType := IPv6Address
+	switch {
+	case isIPv4(ip):
+		netAddrType = IPv4Address
+	case isOnionCatTor(ip):
+		netAddrType = TORv2Address
+	}
+
 	timestamp := time.Unix(time.Now().Unix(), 0)
 	return &NetAddress{
+		Type:      netAddrType,
 		IP:        ip,
 		Port:      port,
 		Services:  services,
 		Timestamp: timestamp,
 	}
 }
+
+// NewNetAddressFromParams creates a new address manager network address of the
+// provided type given the address bytes, port, timestamp, and the supported
+// service flags for the address.  The timestamp is rounded to single second
+// precision.
+//
+// An error is returned when the type is not one of the supported network
+// address types or the address bytes are not valid for the type.
+func NewNetAddressFromParams(netAddrType NetAddressType, addrBytes []byte,
+	port uint16, timestamp time.Time, services wire.ServiceFlag) (*NetAddress, error) {
+
+	var valid bool
+	switch netAddrType {
+	case IPv4Address:
+		valid = (len(addrBytes) == net.IPv4len ||
+			len(addrBytes) == net.IPv6len) && isIPv4(addrBytes)
+	case IPv6Address:
+		valid = len(addrBytes) == net.IPv6len && !isIPv4(addrBytes)
+	case TORv2Address:
+		valid = len(addrBytes) == net.IPv6len && isOnionCatTor(addrBytes)
+	case TORv3Address, I2PAddress:
+		valid = len(addrBytes) == 32
+	case CJDNSAddress:
+		valid = len(addrBytes) == net.IPv6len && isCJDNS(addrBytes)
+	default:
+		str := fmt.Sprintf("unknown network address type %d", netAddrType)
+		return nil, makeError(ErrUnknownAddressType, str)
+	}
+	if !valid {
+		str := fmt.Sprintf("address %x is not a valid address of type %v",
+			addrBytes, netAddrType)
+		return nil, makeError(ErrMismatchedAddressType, str)
+	}
+
+	return &NetAddress{
+		Type:      netAddrType,
+		IP:        addrBytes,
+		Port:      port,
+		Services:  services,
+		Timestamp: time.Unix(timestamp.Unix(), 0),
+	}, nil
+}
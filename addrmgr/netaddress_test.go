@@ -5,9 +5,12 @@
 package addrmgr
 
 import (
+	"encoding/hex"
+	"errors"
 	"net"
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/EXCCoin/exccd/wire"
 )
@@ -121,3 +124,143 @@ func TestAddService(t *testing.T) {
 			netAddr.Services, wire.SFNodeNetwork)
 	}
 }
+
+// hexToBytes converts the passed hex string into bytes and will panic if there
+// is an error.  This is only provided for the hard-coded constants so errors in
+// the source code can be detected. It will only (and must only) be called with
+// hard-coded values.
+func hexToBytes(s string) []byte {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		panic("invalid hex in source file: " + s)
+	}
+	return b
+}
+
+// TestNewNetAddressFromParams ensures that network addresses of the various
+// supported types are created as expected, that invalid parameters are
+// rejected, and that the resulting addresses have the expected keys, group
+// keys, and routability.
+func TestNewNetAddressFromParams(t *testing.T) {
+	torV3PubKey := hexToBytes("1d04a1d04a338c6e6ae970bfabee49049d6702250984" +
+		"ca950c01673f4ec034ad")
+	i2pHash := make([]byte, 32)
+	for i := range i2pHash {
+		i2pHash[i] = byte(i)
+	}
+
+	tests := []struct {
+		name      string
+		addrType  NetAddressType
+		addrBytes []byte
+		port      uint16
+		wantErr   error
+		wantKey   string
+		wantGroup string
+		routable  bool
+	}{{
+		name:      "ipv4",
+		addrType:  IPv4Address,
+		addrBytes: net.ParseIP("12.1.2.3").To4(),
+		port:      8333,
+		wantKey:   "12.1.2.3:8333",
+		wantGroup: "12.1.0.0",
+		routable:  true,
+	}, {
+		name:      "ipv4 mapped ipv6 bytes",
+		addrType:  IPv4Address,
+		addrBytes: net.ParseIP("12.1.2.3"),
+		port:      8333,
+		wantKey:   "12.1.2.3:8333",
+		wantGroup: "12.1.0.0",
+		routable:  true,
+	}, {
+		name:      "ipv6",
+		addrType:  IPv6Address,
+		addrBytes: net.ParseIP("2003::1"),
+		port:      8333,
+		wantKey:   "[2003::1]:8333",
+		wantGroup: "2003::",
+		routable:  true,
+	}, {
+		name:      "torv3",
+		addrType:  TORv3Address,
+		addrBytes: torV3PubKey,
+		port:      9108,
+		wantKey: "duckduckgogg42xjoc72x3sjasowoarfbgcmvfimaftt6twagswzczad." +
+			"onion:9108",
+		wantGroup: "tor:13",
+		routable:  true,
+	}, {
+		name:      "i2p",
+		addrType:  I2PAddress,
+		addrBytes: i2pHash,
+		port:      0,
+		wantKey:   "aaaqeayeaudaocajbifqydiob4ibceqtcqkrmfyydenbwha5dypq.b32.i2p:0",
+		wantGroup: "i2p:0",
+		routable:  true,
+	}, {
+		name:      "cjdns",
+		addrType:  CJDNSAddress,
+		addrBytes: net.ParseIP("fc32:17ea:e415:c3bf:9808:149d:b5a2:c9aa"),
+		port:      9108,
+		wantKey:   "[fc32:17ea:e415:c3bf:9808:149d:b5a2:c9aa]:9108",
+		wantGroup: "cjdns:3",
+		routable:  true,
+	}, {
+		name:      "ipv4 with ipv6 bytes",
+		addrType:  IPv4Address,
+		addrBytes: net.ParseIP("2003::1"),
+		wantErr:   ErrMismatchedAddressType,
+	}, {
+		name:      "ipv6 with ipv4 bytes",
+		addrType:  IPv6Address,
+		addrBytes: net.ParseIP("12.1.2.3"),
+		wantErr:   ErrMismatchedAddressType,
+	}, {
+		name:      "torv3 with short public key",
+		addrType:  TORv3Address,
+		addrBytes: torV3PubKey[:31],
+		wantErr:   ErrMismatchedAddressType,
+	}, {
+		name:      "cjdns outside of fc00::/8",
+		addrType:  CJDNSAddress,
+		addrBytes: net.ParseIP("fd00::1"),
+		wantErr:   ErrMismatchedAddressType,
+	}, {
+		name:      "local address type",
+		addrType:  LocalAddress,
+		addrBytes: net.ParseIP("127.0.0.1"),
+		wantErr:   ErrUnknownAddressType,
+	}}
+
+	for _, test := range tests {
+		netAddr, err := NewNetAddressFromParams(test.addrType, test.addrBytes,
+			test.port, time.Now(), wire.SFNodeNetwork)
+		if !errors.Is(err, test.wantErr) {
+			t.Errorf("%q: unexpected error - got %v, want %v", test.name,
+				err, test.wantErr)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+
+		if netAddr.Type != test.addrType {
+			t.Errorf("%q: unexpected type - got %v, want %v", test.name,
+				netAddr.Type, test.addrType)
+		}
+		if key := netAddr.Key(); key != test.wantKey {
+			t.Errorf("%q: unexpected key - got %s, want %s", test.name, key,
+				test.wantKey)
+		}
+		if group := netAddr.GroupKey(); group != test.wantGroup {
+			t.Errorf("%q: unexpected group key - got %s, want %s", test.name,
+				group, test.wantGroup)
+		}
+		if routable := netAddr.IsRoutable(); routable != test.routable {
+			t.Errorf("%q: unexpected routability - got %v, want %v",
+				test.name, routable, test.routable)
+		}
+	}
+}
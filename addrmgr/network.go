@@ -83,6 +83,11 @@ var (
 	// { magic 6 bytes, 10 bytes base32 decode of key hash }
 	onionCatNet = ipNet("fd87:d87e:eb43::", 48, 128)
 
+	// cjdnsNet defines the IPv6 address block used by CJDNS (FC00::/8).
+	// Note that this range is part of the RFC4193 unique local IPv6 range,
+	// so CJDNS addresses are distinguished by their address type.
+	cjdnsNet = ipNet("fc00::", 8, 128)
+
 	// zero4Net defines the IPv4 address block for address staring with 0
 	// (0.0.0.0/8).
 	zero4Net = ipNet("0.0.0.0", 8, 32)
@@ -116,6 +121,12 @@ func isOnionCatTor(netIP net.IP) bool {
 	return onionCatNet.Contains(netIP)
 }
 
+// isCJDNS returns whether or not the passed address is in the IPv6 range used
+// by CJDNS (fc00::/8).
+func isCJDNS(netIP net.IP) bool {
+	return len(netIP) == net.IPv6len && cjdnsNet.Contains(netIP)
+}
+
 // NetAddressType is used to indicate which network a network address belongs
 // to.
 type NetAddressType uint8
@@ -125,8 +136,32 @@ const (
 	IPv4Address
 	IPv6Address
 	TORv2Address
+	TORv3Address
+	I2PAddress
+	CJDNSAddress
 )
 
+// String returns the network address type in human-readable form.
+func (t NetAddressType) String() string {
+	switch t {
+	case LocalAddress:
+		return "local"
+	case IPv4Address:
+		return "ipv4"
+	case IPv6Address:
+		return "ipv6"
+	case TORv2Address:
+		return "torv2"
+	case TORv3Address:
+		return "torv3"
+	case I2PAddress:
+		return "i2p"
+	case CJDNSAddress:
+		return "cjdns"
+	}
+	return fmt.Sprintf("unknown (%d)", uint8(t))
+}
+
 // addressType returns the network address type of the provided network address.
 func addressType(netIP net.IP) NetAddressType {
 	switch {
@@ -259,9 +294,34 @@ func IsRoutable(netIP net.IP) bool {
 // GroupKey returns a string representing the network group an address is part
 // of.  This is the /16 for IPv4, the /32 (/36 for he.net) for IPv6, the string
 // "local" for a local address, the string "tor:key" where key is the /4 of the
-// onion address for Tor address, and the string "unroutable" for an unroutable
-// address.
+// onion address for Tor address, the string "i2p:key" where key is the /4 of
+// the destination hash for I2P addresses, the string "cjdns:key" where key is
+// the /4 following the fc00::/8 prefix for CJDNS addresses, and the string
+// "unroutable" for an unroutable address.
 func (na *NetAddress) GroupKey() string {
+	switch na.Type {
+	case TORv3Address, I2PAddress:
+		if !na.IsRoutable() {
+			return "unroutable"
+		}
+
+		// group is keyed off the first 4 bits of the public key or the
+		// destination hash.
+		network := "tor"
+		if na.Type == I2PAddress {
+			network = "i2p"
+		}
+		return fmt.Sprintf("%s:%d", network, na.IP[0]&((1<<4)-1))
+
+	case CJDNSAddress:
+		if !na.IsRoutable() {
+			return "unroutable"
+		}
+
+		// group is keyed off the 4 bits following the fixed prefix.
+		return fmt.Sprintf("cjdns:%d", na.IP[1]>>4)
+	}
+
 	netIP := net.IP(na.IP)
 	if isLocal(netIP) {
 		return "local"
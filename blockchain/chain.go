@@ -139,6 +139,7 @@ type BlockChain struct {
 	latestCheckpoint *chaincfg.Checkpoint
 	deploymentVers   map[string]uint32
 	db               database.DB
+	noWrites         bool
 	minTestNetTarget *big.Int
 	dbInfo           *databaseInfo
 	chainParams      *chaincfg.Params
@@ -328,17 +329,19 @@ type VoteInfo struct {
 	AgendaStatus []ThresholdStateTuple
 }
 
-// prevScript represents script and script version information for a previous
-// outpoint.
+// prevScript represents script, script version, and amount information for a
+// previous outpoint.
 type prevScript struct {
 	scriptVersion uint16
 	pkScript      []byte
+	amount        int64
 }
 
-// prevScriptsSnapshot represents a snapshot of script and script version
-// information related to previous outpoints from a utxo viewpoint.
+// prevScriptsSnapshot represents a snapshot of script, script version, and
+// amount information related to previous outpoints from a utxo viewpoint.
 //
-// This implements the indexers.PrevScripter interface.
+// This implements the indexers.PrevScripter and indexers.PrevAmounter
+// interfaces.
 type prevScriptsSnapshot struct {
 	entries map[wire.OutPoint]prevScript
 }
@@ -346,6 +349,9 @@ type prevScriptsSnapshot struct {
 // Ensure prevScriptSnapshot implements the indexers.PrevScripter interface.
 var _ indexers.PrevScripter = (*prevScriptsSnapshot)(nil)
 
+// Ensure prevScriptSnapshot implements the indexers.PrevAmounter interface.
+var _ indexers.PrevAmounter = (*prevScriptsSnapshot)(nil)
+
 // newPrevScriptSnapshot creates a script and script version snapshot from
 // the provided utxo viewpoint.
 func newPrevScriptSnapshot(view *UtxoViewpoint) *prevScriptsSnapshot {
@@ -368,6 +374,7 @@ func newPrevScriptSnapshot(view *UtxoViewpoint) *prevScriptsSnapshot {
 		snapshot.entries[k] = prevScript{
 			scriptVersion: v.scriptVersion,
 			pkScript:      pkScript,
+			amount:        v.amount,
 		}
 	}
 
@@ -386,6 +393,16 @@ func (p *prevScriptsSnapshot) PrevScript(prevOut *wire.OutPoint) (uint16, []byte
 	return entry.scriptVersion, entry.pkScript, true
 }
 
+// PrevAmount returns the amount associated with the provided previous outpoint
+// along with a bool that indicates whether or not the requested entry exists.
+func (p *prevScriptsSnapshot) PrevAmount(prevOut *wire.OutPoint) (int64, bool) {
+	entry := p.entries[*prevOut]
+	if entry.pkScript == nil {
+		return 0, false
+	}
+	return entry.amount, true
+}
+
 // EnableBulkImportMode provides a mechanism to indicate that several validation
 // checks can be avoided when bulk importing blocks already known to be valid.
 // This must NOT be enabled in any other circumstance where blocks need to be
@@ -743,14 +760,18 @@ func (b *BlockChain) connectBlock(node *blockNode, block, parent *dcrutil.Block,
 		return err
 	}
 
-	// Conditionally flush the utxo cache to the database.  Force a flush if the
-	// chain believes it is current since blocks are connected infrequently at
-	// that point.  Only log the flush when the chain is not current as it is
-	// mostly useful to see the flush details when many blocks are being connected
-	// (and subsequently flushed) in quick succession.
+	// Conditionally flush the utxo cache to the database.  Force a flush once
+	// the initial sync has finished, as indicated by the chain having latched
+	// to current, since blocks are connected infrequently at that point and
+	// keeping the database up to date minimizes the work lost on an unclean
+	// shutdown.  This also applies when the chain briefly falls behind after
+	// the initial sync, such as after a short outage.  Only log the flush when
+	// the chain is not current as it is mostly useful to see the flush details
+	// when many blocks are being connected (and subsequently flushed) in quick
+	// succession.
 	isCurrent := b.isCurrent(node)
-	err = b.utxoCache.MaybeFlush(&node.hash, uint32(node.height), isCurrent,
-		!isCurrent)
+	err = b.utxoCache.MaybeFlush(&node.hash, uint32(node.height),
+		b.isCurrentLatch, !isCurrent)
 	if err != nil {
 		return err
 	}
@@ -784,15 +805,25 @@ func (b *BlockChain) connectBlock(node *blockNode, block, parent *dcrutil.Block,
 	// Notify the spend pruner of the connected block.
 	go b.spendPruner.NotifyConnectedBlock(block.Hash())
 
+	// Determine the tickets missed and expired by the block so they can be
+	// included in the notification.
+	var ticketsMissed, ticketsExpired []chainhash.Hash
+	if node.height >= b.chainParams.StakeValidationHeight {
+		ticketsMissed, ticketsExpired = missedAndExpiredTickets(
+			stakeNode.UndoData())
+	}
+
 	// Notify the caller that the block was connected to the main chain.
 	// The caller would typically want to react with actions such as
 	// updating wallets.
 	b.chainLock.Unlock()
 	b.sendNotification(NTBlockConnected, &BlockConnectedNtfnsData{
-		Block:        block,
-		ParentBlock:  parent,
-		CheckTxFlags: checkTxFlags,
-		PrevScripts:  prevScripter,
+		Block:          block,
+		ParentBlock:    parent,
+		CheckTxFlags:   checkTxFlags,
+		PrevScripts:    prevScripter,
+		TicketsMissed:  ticketsMissed,
+		TicketsExpired: ticketsExpired,
 	})
 	b.chainLock.Lock()
 
@@ -1214,6 +1245,14 @@ func (b *BlockChain) reorganizeChainInternal(target *blockNode) error {
 		// Store the loaded block as parent of next iteration.
 		prevBlockAttached = block
 
+		// Start loading the outputs spent by the next block to attach into the
+		// utxo cache in the background while this one is connected.
+		if i+1 < len(attachNodes) {
+			if nextBlock, ok := b.lookupRecentBlock(&attachNodes[i+1].hash); ok {
+				b.prefetchBlockInputs(nextBlock)
+			}
+		}
+
 		// Determine if treasury agenda is active.
 		isTreasuryEnabled, err := b.isTreasuryAgendaActive(n.parent)
 		if err != nil {
@@ -1500,6 +1539,10 @@ func (b *BlockChain) forceHeadReorganization(formerBest chainhash.Hash, newBest
 //
 // This function is safe for concurrent access.
 func (b *BlockChain) ForceHeadReorganization(formerBest chainhash.Hash, newBest chainhash.Hash) error {
+	if b.noWrites {
+		return noWritesError("force a head reorganization")
+	}
+
 	b.processLock.Lock()
 	b.chainLock.Lock()
 	err := b.forceHeadReorganization(formerBest, newBest)
@@ -2093,6 +2136,7 @@ func stxosToScriptSource(block *dcrutil.Block, stxos []spentTxOut, isTreasuryEna
 			source[*prevOut] = scriptSourceEntry{
 				version: stxo.scriptVersion,
 				script:  stxo.pkScript,
+				amount:  stxo.amount,
 			}
 		}
 	}
@@ -2115,6 +2159,7 @@ func stxosToScriptSource(block *dcrutil.Block, stxos []spentTxOut, isTreasuryEna
 			source[*prevOut] = scriptSourceEntry{
 				version: stxo.scriptVersion,
 				script:  stxo.pkScript,
+				amount:  stxo.amount,
 			}
 		}
 	}
@@ -2151,7 +2196,7 @@ func (q *ChainQueryerAdapter) IsTreasuryEnabled(hash *chainhash.Hash) (bool, err
 // API of the BlockChain type itself.
 //
 // This is part of the indexers.ChainQueryer interface.
-func (q *ChainQueryerAdapter) PrevScripts(dbTx database.Tx, block *dcrutil.Block) (indexers.PrevScripter, error) {
+func (q *ChainQueryerAdapter) PrevScripts(block *dcrutil.Block) (indexers.PrevScripter, error) {
 	prevHash := &block.MsgBlock().Header.PrevBlock
 	isTreasuryEnabled, err := q.IsTreasuryAgendaActive(prevHash)
 	if err != nil {
@@ -2159,7 +2204,12 @@ func (q *ChainQueryerAdapter) PrevScripts(dbTx database.Tx, block *dcrutil.Block
 	}
 
 	// Load all of the spent transaction output data from the database.
-	stxos, err := dbFetchSpendJournalEntry(dbTx, block, isTreasuryEnabled)
+	var stxos []spentTxOut
+	err = q.db.View(func(dbTx database.Tx) error {
+		var err error
+		stxos, err = dbFetchSpendJournalEntry(dbTx, block, isTreasuryEnabled)
+		return err
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -2174,6 +2224,10 @@ func (q *ChainQueryerAdapter) PrevScripts(dbTx database.Tx, block *dcrutil.Block
 //
 // This function is safe for concurrent access.
 func (b *BlockChain) RemoveSpendEntry(hash *chainhash.Hash) error {
+	if b.noWrites {
+		return noWritesError("remove spend journal entries")
+	}
+
 	b.processLock.Lock()
 	defer b.processLock.Unlock()
 
@@ -2227,8 +2281,11 @@ func (q *ChainQueryerAdapter) AddSpendConsumer(consumer spendpruner.SpendConsume
 
 // RemoveSpendConsumerDependency removes the provided spend consumer dependency
 // associated with the provided block hash from the spend pruner.
-func (q *ChainQueryerAdapter) RemoveSpendConsumerDependency(dbTx database.Tx, blockHash *chainhash.Hash, consumerID string) error {
-	return q.spendPruner.RemoveSpendConsumerDependency(dbTx, blockHash, consumerID)
+func (q *ChainQueryerAdapter) RemoveSpendConsumerDependency(blockHash *chainhash.Hash, consumerID string) error {
+	return q.db.Update(func(dbTx database.Tx) error {
+		return q.spendPruner.RemoveSpendConsumerDependency(dbTx, blockHash,
+			consumerID)
+	})
 }
 
 // FetchSpendConsumer returns the spend journal consumer associated with the
@@ -2326,6 +2383,27 @@ type Config struct {
 	//
 	// This field is required.
 	UtxoCache UtxoCacher
+
+	// NoWrites disables all modifications to the block and UTXO databases.
+	// It is intended for tools that query the chain state from databases
+	// that are open in read-only mode, such as while they are in use by
+	// another process.
+	//
+	// Creating the chain fails with ErrNoWrites when the databases are not
+	// initialized or require an upgrade, and all operations that would
+	// modify the databases, such as processing blocks, fail with
+	// ErrNoWrites.  Any blocks the UTXO database is behind the best chain
+	// are replayed into the UTXO cache in memory without being flushed.
+	NoWrites bool
+
+	// ReindexChainState rebuilds the UTXO set, spend journal, and ticket
+	// database by resetting them to the genesis block and reconnecting all of
+	// the stored blocks that form the current best chain.  The block data and
+	// block index are retained.
+	//
+	// An interrupted reindex is automatically resumed the next time the chain
+	// is created regardless of this setting.
+	ReindexChainState bool
 }
 
 // New returns a BlockChain instance using the provided configuration details.
@@ -2371,6 +2449,7 @@ func New(ctx context.Context, config *Config) (*BlockChain, error) {
 		deploymentVers:                deploymentVers,
 		minTestNetTarget:              minTestNetTarget,
 		db:                            config.DB,
+		noWrites:                      config.NoWrites,
 		chainParams:                   params,
 		timeSource:                    config.TimeSource,
 		notifications:                 config.Notifications,
@@ -2395,7 +2474,8 @@ func New(ctx context.Context, config *Config) (*BlockChain, error) {
 	// Initialize the chain state from the passed database.  When the db
 	// does not yet contain any chain state, both it and the chain state
 	// will be initialized to contain only the genesis block.
-	if err := b.initChainState(ctx, config.UtxoBackend); err != nil {
+	err = b.initChainState(ctx, config.UtxoBackend, config.ReindexChainState)
+	if err != nil {
 		return nil, err
 	}
 
@@ -2410,6 +2490,15 @@ func New(ctx context.Context, config *Config) (*BlockChain, error) {
 		return nil, err
 	}
 
+	// Rebuild the chain state from the stored blocks when a reindex is in
+	// progress.  This is skipped when writes are disabled since the chain
+	// ensures no reindex is in progress in that case.
+	if !b.noWrites {
+		if err := b.reindexChainState(ctx); err != nil {
+			return nil, err
+		}
+	}
+
 	log.Infof("Blockchain database version info: chain: %d, compression: "+
 		"%d, block index: %d, spend journal: %d", b.dbInfo.version,
 		b.dbInfo.compVer, b.dbInfo.bidxVer, b.dbInfo.stxoVer)
@@ -2423,8 +2512,9 @@ func New(ctx context.Context, config *Config) (*BlockChain, error) {
 		"set: %d", utxoDbInfo.version, utxoDbInfo.compVer, utxoDbInfo.utxoVer)
 
 	// Manually invalidate any chains on version 3 of the test network that were
-	// created prior to enforcement of the maximum difficulty rules.
-	if b.isTestNet3() {
+	// created prior to enforcement of the maximum difficulty rules.  This is
+	// skipped when writes are disabled since it modifies the database.
+	if b.isTestNet3() && !b.noWrites {
 		// Discover any existing nodes at the max diff activation height that do
 		// not have the expected hash and have not already been invalidated.
 		invalidateNodes := make([]*blockNode, 0, 1)
@@ -1101,20 +1101,10 @@ func dbFetchBestState(dbTx database.Tx) (bestChainState, error) {
 // genesis block.  This includes creating the necessary buckets and inserting
 // the genesis block, so it must only be called on an uninitialized database.
 func (b *BlockChain) createChainState() error {
-	// Create a new node from the genesis block and set it as the best node.
+	// Create a new node from the genesis block and set it as the best node
+	// along with initializing the state related to the best block.
 	genesisBlock := dcrutil.NewBlock(b.chainParams.GenesisBlock)
-	header := &genesisBlock.MsgBlock().Header
-	node := newBlockNode(header, nil)
-	node.status = statusDataStored | statusValidated
-	node.isFullyLinked = true
-
-	// Initialize the state related to the best block.  Since it is the
-	// genesis block, use its timestamp for the median time.
-	numTxns := uint64(len(genesisBlock.MsgBlock().Transactions))
-	blockSize := uint64(genesisBlock.MsgBlock().SerializeSize())
-	stateSnapshot := newBestState(node, blockSize, numTxns, numTxns,
-		time.Unix(node.timestamp, 0), 0, 0, b.chainParams.MinimumStakeDiff,
-		nil, nil, nil, earlyFinalState)
+	node, stateSnapshot := b.genesisBestState()
 
 	// Create the initial the database chain state including creating the
 	// necessary index buckets and inserting the genesis block.
@@ -1282,11 +1272,20 @@ func loadBlockIndex(dbTx database.Tx, genesisHash *chainhash.Hash, index *blockI
 // initChainState attempts to load and initialize the chain state from the
 // database.  When the db does not yet contain any chain state, both it and the
 // chain state are initialized to the genesis block.
+//
+// When the reindex flag is set, or a previously started reindex was
+// interrupted, the chain state is reset to the genesis block so it can be
+// rebuilt from the stored blocks.  See maybeResetChainState for details.
 func (b *BlockChain) initChainState(ctx context.Context,
-	utxoBackend UtxoBackend) error {
+	utxoBackend UtxoBackend, reindex bool) error {
 
-	// Update database versioning scheme if needed.
-	err := b.db.Update(func(dbTx database.Tx) error {
+	// Update database versioning scheme if needed.  The migration is only
+	// detected when writes are disabled so a useful error can be returned.
+	update := b.db.Update
+	if b.noWrites {
+		update = b.db.View
+	}
+	err := update(func(dbTx database.Tx) error {
 		// No versioning upgrade is needed if the dbinfo bucket does not
 		// exist or the legacy key does not exist.
 		bucket := dbTx.Metadata().Bucket(bcdbInfoBucketName)
@@ -1304,6 +1303,9 @@ func (b *BlockChain) initChainState(ctx context.Context,
 		}
 
 		// Load and deserialize the legacy version information.
+		if b.noWrites {
+			return noWritesError("migrate the database versioning scheme")
+		}
 		log.Infof("Migrating versioning scheme...")
 		dbi, err := deserializeDatabaseInfoV2(legacyBytes)
 		if err != nil {
@@ -1370,23 +1372,57 @@ func (b *BlockChain) initChainState(ctx context.Context,
 
 	// Initialize the database if it has not already been done.
 	if !isStateInitialized {
+		if b.noWrites {
+			return noWritesError("initialize the chain state")
+		}
 		if err := b.createChainState(); err != nil {
 			return err
 		}
 	}
 
-	// Initialize the UTXO database info.  This must be initialized after the
-	// block database info is loaded, but before block database migrations are
-	// run, since setting the initial UTXO set version depends on the block
-	// database version as that is where it originally resided.
-	if err := utxoBackend.InitInfo(b.dbInfo.version); err != nil {
-		return err
-	}
+	if b.noWrites {
+		// Ensure the databases do not require initialization or upgrades
+		// since they are not possible when writes are disabled.
+		if err := b.checkNoWritesVersions(utxoBackend); err != nil {
+			return err
+		}
 
-	// Upgrade the database as needed.
-	err = upgradeDB(ctx, b.db, b.chainParams, b.dbInfo)
-	if err != nil {
-		return err
+		// The chain state is not usable while it is being rebuilt.
+		if reindex {
+			return noWritesError("reindex the chain state")
+		}
+		err := b.db.View(func(dbTx database.Tx) error {
+			state, err := dbFetchReindexState(dbTx)
+			if err == nil && state != nil {
+				err = noWritesError("resume reindexing the chain state")
+			}
+			return err
+		})
+		if err != nil {
+			return err
+		}
+	} else {
+		// Initialize the UTXO database info.  This must be initialized after
+		// the block database info is loaded, but before block database
+		// migrations are run, since setting the initial UTXO set version
+		// depends on the block database version as that is where it
+		// originally resided.
+		if err := utxoBackend.InitInfo(b.dbInfo.version); err != nil {
+			return err
+		}
+
+		// Upgrade the database as needed.
+		err = upgradeDB(ctx, b.db, b.chainParams, b.dbInfo)
+		if err != nil {
+			return err
+		}
+
+		// Reset the chain state so it can be rebuilt from the stored blocks
+		// when requested or a previous attempt to do so was interrupted.
+		err = b.maybeResetChainState(ctx, utxoBackend, reindex)
+		if err != nil {
+			return err
+		}
 	}
 
 	// Attempt to load the chain state and block index from the database.
@@ -1488,10 +1524,48 @@ func (b *BlockChain) initChainState(ctx context.Context,
 		return err
 	}
 
-	// Upgrade the spend journal as needed.
+	// Upgrade the spend journal as needed.  The versions were already checked
+	// above when writes are disabled.
+	if b.noWrites {
+		return nil
+	}
 	return upgradeSpendJournal(ctx, b)
 }
 
+// checkNoWritesVersions returns an error of kind ErrNoWrites when the block
+// database or the provided UTXO backend is not initialized or requires an
+// upgrade.  It is used in place of initializing and upgrading the databases
+// when writes are disabled.
+func (b *BlockChain) checkNoWritesVersions(utxoBackend UtxoBackend) error {
+	dbInfo := b.dbInfo
+	if dbInfo.version != currentDatabaseVersion ||
+		dbInfo.bidxVer != currentBlockIndexVersion ||
+		dbInfo.stxoVer != currentSpendJournalVersion {
+
+		return noWritesError("upgrade the block database")
+	}
+
+	utxoDbInfo, err := utxoBackend.FetchInfo()
+	if err != nil {
+		return err
+	}
+	if utxoDbInfo == nil {
+		return noWritesError("initialize the UTXO database")
+	}
+	if utxoDbInfo.version > currentUtxoDatabaseVersion {
+		return fmt.Errorf("the current UTXO database is no longer compatible "+
+			"with this version of the software (%d > %d)",
+			utxoDbInfo.version, currentUtxoDatabaseVersion)
+	}
+	if utxoDbInfo.version != currentUtxoDatabaseVersion ||
+		utxoDbInfo.utxoVer != uint32(utxoKeySetVersions[utxoKeySetUtxoSet]) {
+
+		return noWritesError("upgrade the UTXO database")
+	}
+
+	return nil
+}
+
 // dbFetchBlockByNode uses an existing database transaction to retrieve the raw
 // block for the provided node, deserialize it, and return a dcrutil.Block.
 func dbFetchBlockByNode(dbTx database.Tx, node *blockNode) (*dcrutil.Block, error) {
@@ -627,6 +627,11 @@ const (
 	// ErrSerializeHeader indicates an attempt to serialize a block header failed.
 	ErrSerializeHeader = ErrorKind("ErrSerializeHeader")
 
+	// ErrNoWrites indicates an operation that requires modifying the database
+	// was attempted on a chain instance that was created with writes
+	// disabled.
+	ErrNoWrites = ErrorKind("ErrNoWrites")
+
 	// ------------------------------------------
 	// Errors related to the UTXO backend.
 	// ------------------------------------------
@@ -704,6 +709,13 @@ func unknownBlockError(hash *chainhash.Hash) ContextError {
 	return contextError(ErrUnknownBlock, str)
 }
 
+// noWritesError creates an error with the kind ErrNoWrites and a description
+// that includes the provided operation.
+func noWritesError(operation string) ContextError {
+	str := fmt.Sprintf("unable to %s since writes are disabled", operation)
+	return contextError(ErrNoWrites, str)
+}
+
 // RuleError identifies a rule violation.  It is used to indicate that
 // processing of a block or transaction failed due to one of the many validation
 // rules.  It has full support for errors.Is and errors.As, so the caller can
@@ -159,6 +159,7 @@ func TestErrorKindStringer(t *testing.T) {
 		{ErrNoTreasuryBalance, "ErrNoTreasuryBalance"},
 		{ErrInvalidateGenesisBlock, "ErrInvalidateGenesisBlock"},
 		{ErrSerializeHeader, "ErrSerializeHeader"},
+		{ErrNoWrites, "ErrNoWrites"},
 		{ErrUtxoBackend, "ErrUtxoBackend"},
 		{ErrUtxoBackendCorruption, "ErrUtxoBackendCorruption"},
 		{ErrUtxoBackendNotOpen, "ErrUtxoBackendNotOpen"},
@@ -0,0 +1,1264 @@
+// Copyright (c) 2023 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package indexers
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/EXCCoin/exccd/chaincfg/chainhash"
+	"github.com/EXCCoin/exccd/chaincfg/v3"
+	"github.com/EXCCoin/exccd/database/v3"
+	"github.com/EXCCoin/exccd/dcrutil/v4"
+	"github.com/EXCCoin/exccd/txscript/v4/stdaddr"
+	"github.com/EXCCoin/exccd/txscript/v4/stdscript"
+	"github.com/EXCCoin/exccd/wire"
+)
+
+const (
+	// addrBalanceIndexName is the human-readable name for the index.
+	addrBalanceIndexName = "address balance index"
+
+	// addrBalanceIndexVersion is the current version of the address balance
+	// index.
+	addrBalanceIndexVersion = 1
+
+	// The following constants define the prefixes of the different kinds of
+	// entries stored in the address balance index bucket.
+	addrBalancePrefix = 'b'
+	addrUtxoPrefix    = 'u'
+	addrDeltaPrefix   = 'd'
+	addrSpendPrefix   = 's'
+
+	// addrBalanceKeySize is the size of an address balance key.  It consists
+	// of the 1 byte prefix and the address key.
+	addrBalanceKeySize = 1 + addrKeySize
+
+	// addrBalanceEntrySize is the size of an address balance entry.  It
+	// consists of the 8 byte total received, the 8 byte total sent, and the 4
+	// byte number of unspent outputs.
+	addrBalanceEntrySize = 8 + 8 + 4
+
+	// addrUtxoKeySize is the size of an address utxo key.  It consists of the
+	// 1 byte prefix, the address key, the 4 byte block height, the 32 byte
+	// transaction hash, and the 4 byte output index.
+	addrUtxoKeySize = 1 + addrKeySize + 4 + chainhash.HashSize + 4
+
+	// addrUtxoEntrySize is the size of an address utxo entry.  It consists of
+	// the 8 byte amount and the 1 byte transaction tree.
+	addrUtxoEntrySize = 8 + 1
+
+	// addrDeltaKeySize is the size of an address delta key.  It consists of
+	// the 1 byte prefix, the address key, the 4 byte block height, the 4 byte
+	// position of the transaction within the block, the 1 byte direction,
+	// and the 4 byte input or output index.
+	addrDeltaKeySize = 1 + addrKeySize + 4 + 4 + 1 + 4
+
+	// addrDeltaEntrySize is the size of an address delta entry.  It consists
+	// of the 32 byte transaction hash and the 8 byte signed amount.
+	addrDeltaEntrySize = chainhash.HashSize + 8
+
+	// addrSpendKeySize is the size of an address spend journal key.  It
+	// consists of the 1 byte prefix, the 4 byte block height, the 4 byte
+	// position of the transaction within the block, and the 4 byte input
+	// index.
+	addrSpendKeySize = 1 + 4 + 4 + 4
+
+	// addrDeltaInput and addrDeltaOutput are the directions stored in the
+	// address delta keys.  Inputs sort before outputs of the same
+	// transaction.
+	addrDeltaInput  = 0
+	addrDeltaOutput = 1
+)
+
+var (
+	// addrBalanceIndexKey is the key of the address balance index and the db
+	// bucket used to house it.
+	addrBalanceIndexKey = []byte("addrbalidx")
+
+	// sortableOrder is the byte order used for the fields of the address
+	// balance index keys so they sort by block height and position.
+	sortableOrder = binary.BigEndian
+)
+
+// -----------------------------------------------------------------------------
+// The address balance index tracks the unspent outputs, the received and sent
+// totals, and the individual credits and debits of every address in the main
+// chain.  Only outputs with a non-zero value that pay to exactly one supported
+// address are indexed.
+//
+// All entries are stored in a single flat bucket and are distinguished by a
+// one byte prefix.  Fixed-width integers in keys are big endian so the entries
+// of an address sort by block height and then by position within the block.
+//
+// The serialized format for the balance entries is:
+//
+//   'b'<addr key> = <received><sent><num utxos>
+//
+//   Field           Type              Size
+//   addr key        [21]byte          21 bytes
+//   received        uint64            8 bytes
+//   sent            uint64            8 bytes
+//   num utxos       uint32            4 bytes
+//   -----
+//   Total: 42 bytes
+//
+// The serialized format for the unspent output entries is:
+//
+//   'u'<addr key><block height><txhash><output index> = <amount><tree>
+//
+//   Field           Type              Size
+//   addr key        [21]byte          21 bytes
+//   block height    uint32            4 bytes
+//   txhash          chainhash.Hash    32 bytes
+//   output index    uint32            4 bytes
+//   amount          uint64            8 bytes
+//   tree            int8              1 byte
+//   -----
+//   Total: 71 bytes
+//
+// The serialized format for the delta entries is:
+//
+//   'd'<addr key><block height><tx position><direction><index> =
+//     <txhash><amount>
+//
+//   Field           Type              Size
+//   addr key        [21]byte          21 bytes
+//   block height    uint32            4 bytes
+//   tx position     uint32            4 bytes
+//   direction       uint8             1 byte
+//   index           uint32            4 bytes
+//   txhash          chainhash.Hash    32 bytes
+//   amount          int64             8 bytes
+//   -----
+//   Total: 75 bytes
+//
+// The position of a transaction is its index in the regular tree of the block
+// or, for the stake tree, its index offset by the number of regular
+// transactions.  The direction is 0 for inputs and 1 for outputs.
+//
+// Finally, the regular tree of a block is disconnected from the utxo set when
+// the next block disapproves it, which requires knowing the address of every
+// output its inputs spent.  Since the scripts of those outputs are not
+// available at that point, the index keeps a journal that maps every indexed
+// input of a regular transaction to the address it debits:
+//
+//   's'<block height><tx position><input index> = <addr key>
+//
+//   Field           Type              Size
+//   block height    uint32            4 bytes
+//   tx position     uint32            4 bytes
+//   input index     uint32            4 bytes
+//   addr key        [21]byte          21 bytes
+//   -----
+//   Total: 34 bytes
+// -----------------------------------------------------------------------------
+
+// AddrBalance houses the confirmed totals of an address in the address balance
+// index.
+type AddrBalance struct {
+	// Received is the total amount of all outputs paid to the address.
+	Received int64
+
+	// Sent is the total amount of all outputs paid to the address that have
+	// been spent.
+	Sent int64
+
+	// NumUtxos is the number of unspent outputs paid to the address.
+	NumUtxos uint32
+}
+
+// Balance returns the amount of all unspent outputs paid to the address.
+func (b *AddrBalance) Balance() int64 {
+	return b.Received - b.Sent
+}
+
+// AddrUtxo houses information about an unspent output paid to an address.
+type AddrUtxo struct {
+	// OutPoint identifies the unspent output.
+	OutPoint wire.OutPoint
+
+	// Amount is the value of the output.
+	Amount int64
+
+	// BlockHeight is the height of the block that contains the output.
+	BlockHeight int64
+}
+
+// AddrDelta houses information about a single credit or debit of an address.
+type AddrDelta struct {
+	// TxHash is the hash of the transaction that credits or debits the
+	// address.
+	TxHash chainhash.Hash
+
+	// IsInput specifies whether the delta is a debit made by an input of the
+	// transaction as opposed to a credit made by one of its outputs.
+	IsInput bool
+
+	// Index is the index of the input or output within the transaction.
+	Index uint32
+
+	// Amount is the signed amount of the delta.  It is negative for debits.
+	Amount int64
+
+	// BlockHeight is the height of the block that contains the transaction.
+	// It is zero for unconfirmed deltas.
+	BlockHeight int64
+}
+
+// addrBalanceKey returns the address balance index key of the balance entry
+// for the provided address key.
+func addrBalanceKey(addrKey [addrKeySize]byte) []byte {
+	key := make([]byte, addrBalanceKeySize)
+	key[0] = addrBalancePrefix
+	copy(key[1:], addrKey[:])
+	return key
+}
+
+// serializeAddrBalance returns the serialization of the provided address
+// balance.
+func serializeAddrBalance(balance *AddrBalance) []byte {
+	serialized := make([]byte, addrBalanceEntrySize)
+	byteOrder.PutUint64(serialized, uint64(balance.Received))
+	byteOrder.PutUint64(serialized[8:], uint64(balance.Sent))
+	byteOrder.PutUint32(serialized[16:], balance.NumUtxos)
+	return serialized
+}
+
+// deserializeAddrBalance decodes the provided serialized address balance.
+func deserializeAddrBalance(serialized []byte) (*AddrBalance, error) {
+	if len(serialized) != addrBalanceEntrySize {
+		return nil, errDeserialize(fmt.Sprintf("unexpected address balance "+
+			"entry length %d", len(serialized)))
+	}
+
+	return &AddrBalance{
+		Received: int64(byteOrder.Uint64(serialized)),
+		Sent:     int64(byteOrder.Uint64(serialized[8:])),
+		NumUtxos: byteOrder.Uint32(serialized[16:]),
+	}, nil
+}
+
+// addrUtxoKey returns the address balance index key of the unspent output
+// entry for the provided address key, block height, and outpoint.
+func addrUtxoKey(addrKey [addrKeySize]byte, height int64, outpoint *wire.OutPoint) []byte {
+	key := make([]byte, addrUtxoKeySize)
+	key[0] = addrUtxoPrefix
+	offset := 1
+	copy(key[offset:], addrKey[:])
+	offset += addrKeySize
+	sortableOrder.PutUint32(key[offset:], uint32(height))
+	offset += 4
+	copy(key[offset:], outpoint.Hash[:])
+	offset += chainhash.HashSize
+	sortableOrder.PutUint32(key[offset:], outpoint.Index)
+	return key
+}
+
+// serializeAddrUtxoEntry returns the serialization of an unspent output entry
+// with the provided amount and transaction tree.
+func serializeAddrUtxoEntry(amount int64, tree int8) []byte {
+	serialized := make([]byte, addrUtxoEntrySize)
+	byteOrder.PutUint64(serialized, uint64(amount))
+	serialized[8] = byte(tree)
+	return serialized
+}
+
+// deserializeAddrUtxo decodes the provided unspent output key and entry.
+func deserializeAddrUtxo(key, serialized []byte) (*AddrUtxo, error) {
+	if len(key) != addrUtxoKeySize {
+		return nil, errDeserialize(fmt.Sprintf("unexpected address utxo "+
+			"key length %d", len(key)))
+	}
+	if len(serialized) != addrUtxoEntrySize {
+		return nil, errDeserialize(fmt.Sprintf("unexpected address utxo "+
+			"entry length %d", len(serialized)))
+	}
+
+	var utxo AddrUtxo
+	offset := 1 + addrKeySize
+	utxo.BlockHeight = int64(sortableOrder.Uint32(key[offset:]))
+	offset += 4
+	copy(utxo.OutPoint.Hash[:], key[offset:])
+	offset += chainhash.HashSize
+	utxo.OutPoint.Index = sortableOrder.Uint32(key[offset:])
+	utxo.Amount = int64(byteOrder.Uint64(serialized))
+	utxo.OutPoint.Tree = int8(serialized[8])
+	return &utxo, nil
+}
+
+// addrDeltaKey returns the address balance index key of the delta entry for
+// the provided address key, block height, transaction position, direction, and
+// index.
+func addrDeltaKey(addrKey [addrKeySize]byte, height int64, txPos int, isInput bool, index uint32) []byte {
+	key := make([]byte, addrDeltaKeySize)
+	key[0] = addrDeltaPrefix
+	offset := 1
+	copy(key[offset:], addrKey[:])
+	offset += addrKeySize
+	sortableOrder.PutUint32(key[offset:], uint32(height))
+	offset += 4
+	sortableOrder.PutUint32(key[offset:], uint32(txPos))
+	offset += 4
+	key[offset] = addrDeltaOutput
+	if isInput {
+		key[offset] = addrDeltaInput
+	}
+	offset++
+	sortableOrder.PutUint32(key[offset:], index)
+	return key
+}
+
+// serializeAddrDeltaEntry returns the serialization of a delta entry with the
+// provided transaction hash and signed amount.
+func serializeAddrDeltaEntry(txHash *chainhash.Hash, amount int64) []byte {
+	serialized := make([]byte, addrDeltaEntrySize)
+	copy(serialized, txHash[:])
+	byteOrder.PutUint64(serialized[chainhash.HashSize:], uint64(amount))
+	return serialized
+}
+
+// deserializeAddrDelta decodes the provided delta key and entry.
+func deserializeAddrDelta(key, serialized []byte) (*AddrDelta, error) {
+	if len(key) != addrDeltaKeySize {
+		return nil, errDeserialize(fmt.Sprintf("unexpected address delta "+
+			"key length %d", len(key)))
+	}
+	if len(serialized) != addrDeltaEntrySize {
+		return nil, errDeserialize(fmt.Sprintf("unexpected address delta "+
+			"entry length %d", len(serialized)))
+	}
+
+	var delta AddrDelta
+	offset := 1 + addrKeySize
+	delta.BlockHeight = int64(sortableOrder.Uint32(key[offset:]))
+	offset += 4 + 4
+	delta.IsInput = key[offset] == addrDeltaInput
+	offset++
+	delta.Index = sortableOrder.Uint32(key[offset:])
+	copy(delta.TxHash[:], serialized)
+	delta.Amount = int64(byteOrder.Uint64(serialized[chainhash.HashSize:]))
+	return &delta, nil
+}
+
+// addrSpendKey returns the address balance index key of the spend journal
+// entry for the provided block height, transaction position, and input index.
+func addrSpendKey(height int64, txPos int, inputIdx uint32) []byte {
+	key := make([]byte, addrSpendKeySize)
+	key[0] = addrSpendPrefix
+	sortableOrder.PutUint32(key[1:], uint32(height))
+	sortableOrder.PutUint32(key[5:], uint32(txPos))
+	sortableOrder.PutUint32(key[9:], inputIdx)
+	return key
+}
+
+// addrBalanceUpdate houses the modifications made to the address balance index
+// while connecting or disconnecting the transactions of a block.  The updated
+// balances are cached until they are flushed to the bucket.
+type addrBalanceUpdate struct {
+	bucket   database.Bucket
+	balances map[[addrKeySize]byte]*AddrBalance
+}
+
+// balance returns the balance of the provided address key, loading it from the
+// bucket when it is not already cached.
+func (u *addrBalanceUpdate) balance(addrKey [addrKeySize]byte) (*AddrBalance, error) {
+	if balance, ok := u.balances[addrKey]; ok {
+		return balance, nil
+	}
+
+	balance := new(AddrBalance)
+	serialized := u.bucket.Get(addrBalanceKey(addrKey))
+	if serialized != nil {
+		var err error
+		balance, err = deserializeAddrBalance(serialized)
+		if err != nil {
+			str := fmt.Sprintf("corrupt address balance entry for %x: %v",
+				addrKey, err)
+			return nil, makeDbErr(database.ErrCorruption, str)
+		}
+	}
+	u.balances[addrKey] = balance
+	return balance, nil
+}
+
+// addOutput credits the provided address key with an output.
+func (u *addrBalanceUpdate) addOutput(addrKey [addrKeySize]byte, height int64, txPos int, outpoint *wire.OutPoint, amount int64) error {
+	err := u.bucket.Put(addrUtxoKey(addrKey, height, outpoint),
+		serializeAddrUtxoEntry(amount, outpoint.Tree))
+	if err != nil {
+		return err
+	}
+	err = u.bucket.Put(addrDeltaKey(addrKey, height, txPos, false,
+		outpoint.Index), serializeAddrDeltaEntry(&outpoint.Hash, amount))
+	if err != nil {
+		return err
+	}
+
+	balance, err := u.balance(addrKey)
+	if err != nil {
+		return err
+	}
+	balance.Received += amount
+	balance.NumUtxos++
+	return nil
+}
+
+// removeOutput reverses the credit of an output to the provided address key.
+func (u *addrBalanceUpdate) removeOutput(addrKey [addrKeySize]byte, height int64, txPos int, outpoint *wire.OutPoint, amount int64) error {
+	err := u.bucket.Delete(addrUtxoKey(addrKey, height, outpoint))
+	if err != nil {
+		return err
+	}
+	err = u.bucket.Delete(addrDeltaKey(addrKey, height, txPos, false,
+		outpoint.Index))
+	if err != nil {
+		return err
+	}
+
+	balance, err := u.balance(addrKey)
+	if err != nil {
+		return err
+	}
+	balance.Received -= amount
+	balance.NumUtxos--
+	return nil
+}
+
+// addSpend debits the provided address key with the output spent by the
+// provided input.
+func (u *addrBalanceUpdate) addSpend(addrKey [addrKeySize]byte, height int64, txPos int, txHash *chainhash.Hash, inputIdx uint32, txIn *wire.TxIn) error {
+	spentHeight := int64(txIn.BlockHeight)
+	err := u.bucket.Delete(addrUtxoKey(addrKey, spentHeight,
+		&txIn.PreviousOutPoint))
+	if err != nil {
+		return err
+	}
+	err = u.bucket.Put(addrDeltaKey(addrKey, height, txPos, true, inputIdx),
+		serializeAddrDeltaEntry(txHash, -txIn.ValueIn))
+	if err != nil {
+		return err
+	}
+
+	balance, err := u.balance(addrKey)
+	if err != nil {
+		return err
+	}
+	balance.Sent += txIn.ValueIn
+	balance.NumUtxos--
+	return nil
+}
+
+// removeSpend reverses the debit of the provided address key made by the
+// provided input which restores the output it spent.
+func (u *addrBalanceUpdate) removeSpend(addrKey [addrKeySize]byte, height int64, txPos int, inputIdx uint32, txIn *wire.TxIn) error {
+	spentHeight := int64(txIn.BlockHeight)
+	prevOut := &txIn.PreviousOutPoint
+	err := u.bucket.Put(addrUtxoKey(addrKey, spentHeight, prevOut),
+		serializeAddrUtxoEntry(txIn.ValueIn, prevOut.Tree))
+	if err != nil {
+		return err
+	}
+	err = u.bucket.Delete(addrDeltaKey(addrKey, height, txPos, true,
+		inputIdx))
+	if err != nil {
+		return err
+	}
+
+	balance, err := u.balance(addrKey)
+	if err != nil {
+		return err
+	}
+	balance.Sent -= txIn.ValueIn
+	balance.NumUtxos++
+	return nil
+}
+
+// flush writes all cached balances to the bucket.  Balances of addresses that
+// no longer have any history are removed.
+func (u *addrBalanceUpdate) flush() error {
+	for addrKey, balance := range u.balances {
+		key := addrBalanceKey(addrKey)
+		if balance.Received == 0 && balance.Sent == 0 && balance.NumUtxos == 0 {
+			if err := u.bucket.Delete(key); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if err := u.bucket.Put(key, serializeAddrBalance(balance)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// inputAddrFunc defines the signature of a function that returns the address
+// key debited by an input along with whether or not it is indexed.
+type inputAddrFunc func(height int64, txPos int, inputIdx uint32, txIn *wire.TxIn) ([addrKeySize]byte, bool)
+
+// AddrBalanceIndex implements an address balance index.  That is to say, it
+// supports querying the balance, the unspent outputs, and the history of
+// credits and debits of any address in the main chain.
+//
+// In addition, support is provided for a memory-only index of the deltas made
+// by unconfirmed transactions such as those which are kept in the memory pool
+// before inclusion in a block.
+type AddrBalanceIndex struct {
+	// The following fields are set when the instance is created and can't
+	// be changed afterwards, so there is no need to protect them with a
+	// separate mutex.
+	db          database.DB
+	chain       ChainQueryer
+	chainParams *chaincfg.Params
+	sub         *IndexSubscription
+	consumer    *SpendConsumer
+
+	// The following fields are used to track the deltas made by
+	// transactions that have not been included into a block yet.  They are
+	// protected by the unconfirmedLock field.
+	//
+	// The deltasByAddr field maps an address to the deltas made to it by
+	// each unconfirmed transaction and the addrsByTx field is the reverse
+	// which allows efficient removal of transactions.
+	unconfirmedLock sync.RWMutex
+	deltasByAddr    map[[addrKeySize]byte]map[chainhash.Hash][]AddrDelta
+	addrsByTx       map[chainhash.Hash]map[[addrKeySize]byte]struct{}
+
+	subscribers map[chan bool]struct{}
+	mtx         sync.Mutex
+	cancel      context.CancelFunc
+}
+
+// Ensure the AddrBalanceIndex type implements the Indexer interface.
+var _ Indexer = (*AddrBalanceIndex)(nil)
+
+// Ensure the AddrBalanceIndex type implements the NeedsInputser interface.
+var _ NeedsInputser = (*AddrBalanceIndex)(nil)
+
+// NeedsInputs signals that the index requires the referenced inputs in order
+// to properly create the index.
+//
+// This implements the NeedsInputser interface.
+func (idx *AddrBalanceIndex) NeedsInputs() bool {
+	return true
+}
+
+// Init initializes the address balance index.
+//
+// This is part of the Indexer interface.
+func (idx *AddrBalanceIndex) Init(ctx context.Context, chainParams *chaincfg.Params) error {
+	if interruptRequested(ctx) {
+		return indexerError(ErrInterruptRequested, interruptMsg)
+	}
+
+	// Finish any drops that were previously interrupted.
+	if err := finishDrop(ctx, idx); err != nil {
+		return err
+	}
+
+	// Create the initial state for the index as needed.
+	if err := createIndex(idx, &chainParams.GenesisHash); err != nil {
+		return err
+	}
+
+	// Upgrade the index as needed.
+	if err := upgradeIndex(ctx, idx, &chainParams.GenesisHash); err != nil {
+		return err
+	}
+
+	// Recover the address balance index and its dependents to the main chain
+	// if needed.
+	return recover(ctx, idx)
+}
+
+// Key returns the database key to use for the index as a byte slice.
+//
+// This is part of the Indexer interface.
+func (idx *AddrBalanceIndex) Key() []byte {
+	return addrBalanceIndexKey
+}
+
+// Name returns the human-readable name of the index.
+//
+// This is part of the Indexer interface.
+func (idx *AddrBalanceIndex) Name() string {
+	return addrBalanceIndexName
+}
+
+// Version returns the current version of the index.
+//
+// This is part of the Indexer interface.
+func (idx *AddrBalanceIndex) Version() uint32 {
+	return addrBalanceIndexVersion
+}
+
+// DB returns the database of the index.
+//
+// This is part of the Indexer interface.
+func (idx *AddrBalanceIndex) DB() database.DB {
+	return idx.db
+}
+
+// Queryer returns the chain queryer.
+//
+// This is part of the Indexer interface.
+func (idx *AddrBalanceIndex) Queryer() ChainQueryer {
+	return idx.chain
+}
+
+// Tip returns the current tip of the index.
+//
+// This is part of the Indexer interface.
+func (idx *AddrBalanceIndex) Tip() (int64, *chainhash.Hash, error) {
+	return tip(idx.db, idx.Key())
+}
+
+// Create is invoked when the index is created for the first time.  It creates
+// the bucket for the address balance index.
+//
+// This is part of the Indexer interface.
+func (idx *AddrBalanceIndex) Create(dbTx database.Tx) error {
+	_, err := dbTx.Metadata().CreateBucket(addrBalanceIndexKey)
+	return err
+}
+
+// IndexSubscription returns the subscription for index updates.
+//
+// This is part of the Indexer interface.
+func (idx *AddrBalanceIndex) IndexSubscription() *IndexSubscription {
+	return idx.sub
+}
+
+// Subscribers returns all client channels waiting for the next index update.
+//
+// This is part of the Indexer interface.
+func (idx *AddrBalanceIndex) Subscribers() map[chan bool]struct{} {
+	idx.mtx.Lock()
+	defer idx.mtx.Unlock()
+	return fetchIndexerSubscribers(idx.subscribers)
+}
+
+// WaitForSync subscribes clients for the next index sync update.
+//
+// This is part of the Indexer interface.
+func (idx *AddrBalanceIndex) WaitForSync() chan bool {
+	c := make(chan bool)
+
+	removeIndexerSub := func() {
+		idx.mtx.Lock()
+		delete(idx.subscribers, c)
+		idx.mtx.Unlock()
+	}
+
+	go purgeIndexerSubscription(c, removeIndexerSub)
+
+	idx.mtx.Lock()
+	idx.subscribers[c] = struct{}{}
+	idx.mtx.Unlock()
+
+	return c
+}
+
+// pkScriptAddrKey returns the address key of the passed public key script
+// along with whether or not outputs with the script are indexed.  Only scripts
+// that pay to exactly one supported address are indexed.
+func (idx *AddrBalanceIndex) pkScriptAddrKey(scriptVersion uint16, pkScript []byte) ([addrKeySize]byte, bool) {
+	_, addrs := stdscript.ExtractAddrs(scriptVersion, pkScript, idx.chainParams)
+	if len(addrs) != 1 {
+		return [addrKeySize]byte{}, false
+	}
+
+	addrKey, err := addrToKey(addrs[0])
+	if err != nil {
+		// Ignore unsupported address types.
+		return [addrKeySize]byte{}, false
+	}
+	return addrKey, true
+}
+
+// prevScriptsInputAddr returns a function that determines the address key
+// debited by an input by using the provided previous scripter.
+func (idx *AddrBalanceIndex) prevScriptsInputAddr(block *dcrutil.Block, prevScripts PrevScripter) inputAddrFunc {
+	return func(_ int64, _ int, _ uint32, txIn *wire.TxIn) ([addrKeySize]byte, bool) {
+		// Outputs without a value are not indexed.
+		if txIn.ValueIn == 0 {
+			return [addrKeySize]byte{}, false
+		}
+
+		// The input should always be available since the index contract
+		// requires it, however, be safe and simply ignore any missing
+		// entries.
+		origin := &txIn.PreviousOutPoint
+		version, pkScript, ok := prevScripts.PrevScript(origin)
+		if !ok {
+			log.Warnf("Missing input %v:%d while indexing block %v "+
+				"(height %v)", origin, origin.Tree, block.Hash(),
+				block.Height())
+			return [addrKeySize]byte{}, false
+		}
+		return idx.pkScriptAddrKey(version, pkScript)
+	}
+}
+
+// journalInputAddr returns a function that determines the address key debited
+// by an input of a regular transaction by using the spend journal entries
+// stored in the provided bucket.
+func journalInputAddr(bucket database.Bucket) inputAddrFunc {
+	return func(height int64, txPos int, inputIdx uint32, _ *wire.TxIn) ([addrKeySize]byte, bool) {
+		var addrKey [addrKeySize]byte
+		serialized := bucket.Get(addrSpendKey(height, txPos, inputIdx))
+		if len(serialized) != addrKeySize {
+			return addrKey, false
+		}
+		copy(addrKey[:], serialized)
+		return addrKey, true
+	}
+}
+
+// connectTxns applies the deltas made by the provided transactions, which are
+// all from the same tree of the block at the provided height, to the provided
+// update.  The spend journal entries are written for the inputs when requested.
+func (idx *AddrBalanceIndex) connectTxns(u *addrBalanceUpdate, txns []*dcrutil.Tx, height int64, posOffset int, tree int8, inputAddr inputAddrFunc, writeJournal bool) error {
+	for txIdx, tx := range txns {
+		txPos := posOffset + txIdx
+		msgTx := tx.MsgTx()
+		for inputIdx, txIn := range msgTx.TxIn {
+			// Inputs that do not spend an output such as those of coinbases,
+			// stakebases, treasurybases, and treasury spends have nothing to
+			// debit.
+			if isNullOutPoint(&txIn.PreviousOutPoint) {
+				continue
+			}
+
+			addrKey, ok := inputAddr(height, txPos, uint32(inputIdx), txIn)
+			if !ok {
+				continue
+			}
+			if writeJournal {
+				key := addrSpendKey(height, txPos, uint32(inputIdx))
+				if err := u.bucket.Put(key, addrKey[:]); err != nil {
+					return err
+				}
+			}
+			err := u.addSpend(addrKey, height, txPos, tx.Hash(),
+				uint32(inputIdx), txIn)
+			if err != nil {
+				return err
+			}
+		}
+
+		for outputIdx, txOut := range msgTx.TxOut {
+			if txOut.Value == 0 {
+				continue
+			}
+			addrKey, ok := idx.pkScriptAddrKey(txOut.Version, txOut.PkScript)
+			if !ok {
+				continue
+			}
+
+			outpoint := wire.OutPoint{
+				Hash:  *tx.Hash(),
+				Index: uint32(outputIdx),
+				Tree:  tree,
+			}
+			err := u.addOutput(addrKey, height, txPos, &outpoint,
+				txOut.Value)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// disconnectTxns reverses the deltas made by the provided transactions, which
+// are all from the same tree of the block at the provided height, in the
+// provided update.  The transactions are processed in reverse order so outputs
+// spent within the same tree are restored properly.  The spend journal entries
+// of the inputs are removed when requested.
+func (idx *AddrBalanceIndex) disconnectTxns(u *addrBalanceUpdate, txns []*dcrutil.Tx, height int64, posOffset int, tree int8, inputAddr inputAddrFunc, removeJournal bool) error {
+	for txIdx := len(txns) - 1; txIdx >= 0; txIdx-- {
+		tx := txns[txIdx]
+		txPos := posOffset + txIdx
+		msgTx := tx.MsgTx()
+		for outputIdx, txOut := range msgTx.TxOut {
+			if txOut.Value == 0 {
+				continue
+			}
+			addrKey, ok := idx.pkScriptAddrKey(txOut.Version, txOut.PkScript)
+			if !ok {
+				continue
+			}
+
+			outpoint := wire.OutPoint{
+				Hash:  *tx.Hash(),
+				Index: uint32(outputIdx),
+				Tree:  tree,
+			}
+			err := u.removeOutput(addrKey, height, txPos, &outpoint,
+				txOut.Value)
+			if err != nil {
+				return err
+			}
+		}
+
+		for inputIdx, txIn := range msgTx.TxIn {
+			if isNullOutPoint(&txIn.PreviousOutPoint) {
+				continue
+			}
+
+			addrKey, ok := inputAddr(height, txPos, uint32(inputIdx), txIn)
+			if !ok {
+				continue
+			}
+			if removeJournal {
+				key := addrSpendKey(height, txPos, uint32(inputIdx))
+				if err := u.bucket.Delete(key); err != nil {
+					return err
+				}
+			}
+			err := u.removeSpend(addrKey, height, txPos, uint32(inputIdx),
+				txIn)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// connectBlock applies the deltas made by all transactions in the provided
+// block to the index.
+func (idx *AddrBalanceIndex) connectBlock(dbTx database.Tx, block, parent *dcrutil.Block, prevScripts PrevScripter) error {
+	bucket := dbTx.Metadata().Bucket(addrBalanceIndexKey)
+	u := &addrBalanceUpdate{
+		bucket:   bucket,
+		balances: make(map[[addrKeySize]byte]*AddrBalance),
+	}
+
+	// Reverse the deltas made by the regular tree of the parent block when
+	// the block disapproves it since the outputs it created are no longer
+	// spendable and the outputs it spent are spendable again.  The genesis
+	// block is never indexed, so there is nothing to reverse for it.
+	header := &block.MsgBlock().Header
+	if !dcrutil.IsFlagSet16(header.VoteBits, dcrutil.BlockValid) &&
+		parent.Height() > 0 {
+
+		err := idx.disconnectTxns(u, parent.Transactions(), parent.Height(),
+			0, wire.TxTreeRegular, journalInputAddr(bucket), false)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Apply the deltas made by the stake tree before the regular tree to
+	// match the order the transactions are connected to the utxo set.
+	inputAddr := idx.prevScriptsInputAddr(block, prevScripts)
+	regularTxns := block.Transactions()
+	err := idx.connectTxns(u, block.STransactions(), block.Height(),
+		len(regularTxns), wire.TxTreeStake, inputAddr, false)
+	if err != nil {
+		return err
+	}
+	err = idx.connectTxns(u, regularTxns, block.Height(), 0,
+		wire.TxTreeRegular, inputAddr, true)
+	if err != nil {
+		return err
+	}
+	if err := u.flush(); err != nil {
+		return err
+	}
+
+	// Update the current index tip.
+	return dbPutIndexerTip(dbTx, idx.Key(), block.Hash(), int32(block.Height()))
+}
+
+// disconnectBlock reverses the deltas made by all transactions in the provided
+// block in the index.
+func (idx *AddrBalanceIndex) disconnectBlock(dbTx database.Tx, block, parent *dcrutil.Block, prevScripts PrevScripter) error {
+	bucket := dbTx.Metadata().Bucket(addrBalanceIndexKey)
+	u := &addrBalanceUpdate{
+		bucket:   bucket,
+		balances: make(map[[addrKeySize]byte]*AddrBalance),
+	}
+
+	inputAddr := idx.prevScriptsInputAddr(block, prevScripts)
+	regularTxns := block.Transactions()
+	err := idx.disconnectTxns(u, regularTxns, block.Height(), 0,
+		wire.TxTreeRegular, inputAddr, true)
+	if err != nil {
+		return err
+	}
+	err = idx.disconnectTxns(u, block.STransactions(), block.Height(),
+		len(regularTxns), wire.TxTreeStake, inputAddr, false)
+	if err != nil {
+		return err
+	}
+
+	// Reapply the deltas made by the regular tree of the parent block when
+	// the block disapproved it.
+	header := &block.MsgBlock().Header
+	if !dcrutil.IsFlagSet16(header.VoteBits, dcrutil.BlockValid) &&
+		parent.Height() > 0 {
+
+		err := idx.connectTxns(u, parent.Transactions(), parent.Height(), 0,
+			wire.TxTreeRegular, journalInputAddr(bucket), false)
+		if err != nil {
+			return err
+		}
+	}
+	if err := u.flush(); err != nil {
+		return err
+	}
+
+	// Update the current index tip.
+	return dbPutIndexerTip(dbTx, idx.Key(), &block.MsgBlock().Header.PrevBlock,
+		int32(block.Height()-1))
+}
+
+// Balance returns the confirmed totals of the provided address.  A zero
+// balance is returned for addresses that have never been paid.
+//
+// This function is safe for concurrent access.
+func (idx *AddrBalanceIndex) Balance(addr stdaddr.Address) (*AddrBalance, error) {
+	addrKey, err := addrToKey(addr)
+	if err != nil {
+		return nil, err
+	}
+
+	var balance *AddrBalance
+	err = idx.db.View(func(dbTx database.Tx) error {
+		u := &addrBalanceUpdate{
+			bucket:   dbTx.Metadata().Bucket(addrBalanceIndexKey),
+			balances: make(map[[addrKeySize]byte]*AddrBalance),
+		}
+		var err error
+		balance, err = u.balance(addrKey)
+		return err
+	})
+	return balance, err
+}
+
+// forEachAddrEntry invokes the provided function with the key and value of the
+// entries with the provided prefix for the provided address in key order
+// after skipping the requested number of them.  At most numRequested entries
+// are visited.  The number of entries actually skipped is returned since it
+// could be less in the case where there are not enough entries.
+func (idx *AddrBalanceIndex) forEachAddrEntry(prefix byte, addrKey [addrKeySize]byte, numToSkip, numRequested uint32, fn func(k, v []byte) error) (uint32, error) {
+	var skipped uint32
+	err := idx.db.View(func(dbTx database.Tx) error {
+		seek := make([]byte, 1+addrKeySize)
+		seek[0] = prefix
+		copy(seek[1:], addrKey[:])
+
+		var visited uint32
+		cursor := dbTx.Metadata().Bucket(addrBalanceIndexKey).Cursor()
+		for ok := cursor.Seek(seek); ok && visited < numRequested; ok = cursor.Next() {
+			if !bytes.HasPrefix(cursor.Key(), seek) {
+				break
+			}
+			if skipped < numToSkip {
+				skipped++
+				continue
+			}
+			if err := fn(cursor.Key(), cursor.Value()); err != nil {
+				return err
+			}
+			visited++
+		}
+		return nil
+	})
+	return skipped, err
+}
+
+// Utxos returns the confirmed unspent outputs paid to the provided address
+// ordered by block height according to the specified number to skip and
+// number requested.  It also returns the number actually skipped since it
+// could be less in the case where there are not enough entries.
+//
+// This function is safe for concurrent access.
+func (idx *AddrBalanceIndex) Utxos(addr stdaddr.Address, numToSkip, numRequested uint32) ([]AddrUtxo, uint32, error) {
+	addrKey, err := addrToKey(addr)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var utxos []AddrUtxo
+	skipped, err := idx.forEachAddrEntry(addrUtxoPrefix, addrKey, numToSkip,
+		numRequested, func(k, v []byte) error {
+			utxo, err := deserializeAddrUtxo(k, v)
+			if err != nil {
+				str := fmt.Sprintf("corrupt address utxo entry for %x: %v",
+					addrKey, err)
+				return makeDbErr(database.ErrCorruption, str)
+			}
+			utxos = append(utxos, *utxo)
+			return nil
+		})
+	return utxos, skipped, err
+}
+
+// Deltas returns the confirmed credits and debits of the provided address in
+// the order they appear in the blockchain according to the specified number
+// to skip and number requested.  It also returns the number actually skipped
+// since it could be less in the case where there are not enough entries.
+//
+// NOTE: These results only include deltas confirmed in blocks.  See the
+// UnconfirmedDeltas method for obtaining unconfirmed deltas.
+//
+// This function is safe for concurrent access.
+func (idx *AddrBalanceIndex) Deltas(addr stdaddr.Address, numToSkip, numRequested uint32) ([]AddrDelta, uint32, error) {
+	addrKey, err := addrToKey(addr)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var deltas []AddrDelta
+	skipped, err := idx.forEachAddrEntry(addrDeltaPrefix, addrKey, numToSkip,
+		numRequested, func(k, v []byte) error {
+			delta, err := deserializeAddrDelta(k, v)
+			if err != nil {
+				str := fmt.Sprintf("corrupt address delta entry for %x: %v",
+					addrKey, err)
+				return makeDbErr(database.ErrCorruption, str)
+			}
+			deltas = append(deltas, *delta)
+			return nil
+		})
+	return deltas, skipped, err
+}
+
+// addUnconfirmedDelta adds the provided delta made by an unconfirmed
+// transaction to the provided address key.
+//
+// This function MUST be called with the unconfirmed lock held (for writes).
+func (idx *AddrBalanceIndex) addUnconfirmedDelta(addrKey [addrKeySize]byte, delta AddrDelta) {
+	deltasByTx := idx.deltasByAddr[addrKey]
+	if deltasByTx == nil {
+		deltasByTx = make(map[chainhash.Hash][]AddrDelta)
+		idx.deltasByAddr[addrKey] = deltasByTx
+	}
+	deltasByTx[delta.TxHash] = append(deltasByTx[delta.TxHash], delta)
+
+	addrs := idx.addrsByTx[delta.TxHash]
+	if addrs == nil {
+		addrs = make(map[[addrKeySize]byte]struct{})
+		idx.addrsByTx[delta.TxHash] = addrs
+	}
+	addrs[addrKey] = struct{}{}
+}
+
+// AddUnconfirmedTx adds the deltas made by the transaction to the unconfirmed
+// (memory-only) address balance index.
+//
+// NOTE: This transaction MUST have already been validated by the memory pool
+// before calling this function with it and have all of the inputs available via
+// the provided previous scripter interface.  Failure to do so could result in
+// some or all deltas not being indexed.
+//
+// This function is safe for concurrent access.
+func (idx *AddrBalanceIndex) AddUnconfirmedTx(tx *dcrutil.Tx, prevScripts PrevScripter) {
+	idx.unconfirmedLock.Lock()
+	defer idx.unconfirmedLock.Unlock()
+
+	// The input amounts are reliable since the memory pool checks them
+	// against the outputs they spend.
+	msgTx := tx.MsgTx()
+	for inputIdx, txIn := range msgTx.TxIn {
+		if isNullOutPoint(&txIn.PreviousOutPoint) || txIn.ValueIn == 0 {
+			continue
+		}
+
+		version, pkScript, ok := prevScripts.PrevScript(&txIn.PreviousOutPoint)
+		if !ok {
+			// Ignore missing entries.  This should never happen in practice
+			// since the function comments specifically call out all inputs must
+			// be available.
+			continue
+		}
+		addrKey, ok := idx.pkScriptAddrKey(version, pkScript)
+		if !ok {
+			continue
+		}
+		idx.addUnconfirmedDelta(addrKey, AddrDelta{
+			TxHash:  *tx.Hash(),
+			IsInput: true,
+			Index:   uint32(inputIdx),
+			Amount:  -txIn.ValueIn,
+		})
+	}
+
+	for outputIdx, txOut := range msgTx.TxOut {
+		if txOut.Value == 0 {
+			continue
+		}
+		addrKey, ok := idx.pkScriptAddrKey(txOut.Version, txOut.PkScript)
+		if !ok {
+			continue
+		}
+		idx.addUnconfirmedDelta(addrKey, AddrDelta{
+			TxHash: *tx.Hash(),
+			Index:  uint32(outputIdx),
+			Amount: txOut.Value,
+		})
+	}
+}
+
+// RemoveUnconfirmedTx removes the passed transaction from the unconfirmed
+// (memory-only) address balance index.
+//
+// This function is safe for concurrent access.
+func (idx *AddrBalanceIndex) RemoveUnconfirmedTx(hash *chainhash.Hash) {
+	idx.unconfirmedLock.Lock()
+	defer idx.unconfirmedLock.Unlock()
+
+	for addrKey := range idx.addrsByTx[*hash] {
+		delete(idx.deltasByAddr[addrKey], *hash)
+		if len(idx.deltasByAddr[addrKey]) == 0 {
+			delete(idx.deltasByAddr, addrKey)
+		}
+	}
+	delete(idx.addrsByTx, *hash)
+}
+
+// UnconfirmedDeltas returns all deltas made to the passed address by the
+// transactions currently in the unconfirmed (memory-only) address balance
+// index.  The deltas are ordered by transaction hash and then by their
+// position in the transaction.  Unsupported address types are ignored and will
+// result in no results.
+//
+// This function is safe for concurrent access.
+func (idx *AddrBalanceIndex) UnconfirmedDeltas(addr stdaddr.Address) []AddrDelta {
+	// Ignore unsupported address types.
+	addrKey, err := addrToKey(addr)
+	if err != nil {
+		return nil
+	}
+
+	idx.unconfirmedLock.RLock()
+	var deltas []AddrDelta
+	for _, txDeltas := range idx.deltasByAddr[addrKey] {
+		deltas = append(deltas, txDeltas...)
+	}
+	idx.unconfirmedLock.RUnlock()
+
+	sort.Slice(deltas, func(i, j int) bool {
+		a, b := &deltas[i], &deltas[j]
+		if cmp := bytes.Compare(a.TxHash[:], b.TxHash[:]); cmp != 0 {
+			return cmp < 0
+		}
+		if a.IsInput != b.IsInput {
+			return a.IsInput
+		}
+		return a.Index < b.Index
+	})
+	return deltas
+}
+
+// NewAddrBalanceIndex returns a new instance of an indexer that is used to
+// track the balance, unspent outputs, and history of credits and debits of all
+// addresses in the blockchain.
+func NewAddrBalanceIndex(subscriber *IndexSubscriber, db database.DB, chain ChainQueryer) (*AddrBalanceIndex, error) {
+	idx := &AddrBalanceIndex{
+		db:           db,
+		chain:        chain,
+		chainParams:  chain.ChainParams(),
+		deltasByAddr: make(map[[addrKeySize]byte]map[chainhash.Hash][]AddrDelta),
+		addrsByTx:    make(map[chainhash.Hash]map[[addrKeySize]byte]struct{}),
+		subscribers:  make(map[chan bool]struct{}),
+		cancel:       subscriber.cancel,
+	}
+
+	sc, err := chain.FetchSpendConsumer(idx.Name())
+	if err != nil {
+		return nil, err
+	}
+
+	consumer, ok := sc.(*SpendConsumer)
+	if !ok {
+		return nil, indexerError(ErrInvalidSpendConsumerType,
+			"consumer not of type SpendConsumer")
+	}
+
+	idx.consumer = consumer
+
+	// The address balance index is an optional index. It has no
+	// prerequisite and is updated asynchronously.
+	sub, err := subscriber.Subscribe(idx, noPrereqs)
+	if err != nil {
+		return nil, err
+	}
+
+	idx.sub = sub
+
+	err = idx.Init(subscriber.ctx, idx.chainParams)
+	if err != nil {
+		return nil, err
+	}
+
+	return idx, nil
+}
+
+// DropAddrBalanceIndex drops the address balance index from the provided
+// database if it exists.
+func DropAddrBalanceIndex(ctx context.Context, db database.DB) error {
+	return dropFlatIndex(ctx, db, addrBalanceIndexKey, addrBalanceIndexName)
+}
+
+// DropIndex drops the address balance index from the provided database if it
+// exists.
+func (*AddrBalanceIndex) DropIndex(ctx context.Context, db database.DB) error {
+	return DropAddrBalanceIndex(ctx, db)
+}
+
+// spendConsumer returns the spend journal consumer of the address balance
+// index.
+//
+// This is part of the spendConsumerIndexer interface.
+func (idx *AddrBalanceIndex) spendConsumer() *SpendConsumer {
+	return idx.consumer
+}
+
+// ProcessNotification indexes the provided notification based on its
+// notification type.
+//
+// This is part of the Indexer interface.
+func (idx *AddrBalanceIndex) ProcessNotification(dbTx database.Tx, ntfn *IndexNtfn) error {
+	switch ntfn.NtfnType {
+	case ConnectNtfn:
+		err := idx.connectBlock(dbTx, ntfn.Block, ntfn.Parent,
+			ntfn.PrevScripts)
+		if err != nil {
+			msg := fmt.Sprintf("%s: unable to connect block: %v",
+				idx.Name(), err)
+			return indexerError(ErrConnectBlock, msg)
+		}
+
+		idx.consumer.UpdateTip(ntfn.Block.Hash())
+
+	case DisconnectNtfn:
+		err := idx.disconnectBlock(dbTx, ntfn.Block, ntfn.Parent,
+			ntfn.PrevScripts)
+		if err != nil {
+			msg := fmt.Sprintf("%s: unable to disconnect block: %v",
+				idx.Name(), err)
+			return indexerError(ErrDisconnectBlock, msg)
+		}
+
+		idx.consumer.UpdateTip(ntfn.Parent.Hash())
+
+	default:
+		msg := fmt.Sprintf("%s: unknown notification type provided: %d",
+			idx.Name(), ntfn.NtfnType)
+		return indexerError(ErrInvalidNotificationType, msg)
+	}
+
+	return nil
+}
@@ -0,0 +1,424 @@
+// Copyright (c) 2023 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package indexers
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/EXCCoin/exccd/blockchain/v4/chaingen"
+	"github.com/EXCCoin/exccd/chaincfg/chainhash"
+	"github.com/EXCCoin/exccd/chaincfg/v3"
+	"github.com/EXCCoin/exccd/dcrutil/v4"
+	"github.com/EXCCoin/exccd/txscript/v4"
+	"github.com/EXCCoin/exccd/txscript/v4/stdaddr"
+	"github.com/EXCCoin/exccd/wire"
+)
+
+// hexToBytes converts the passed hex string into bytes and will panic if there
+// is an error.  This is only provided for the hard-coded constants so errors in
+// the source code can be detected.  It will only (and must only) be called with
+// hard-coded values.
+func hexToBytes(s string) []byte {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		panic("invalid hex in source file: " + s)
+	}
+	return b
+}
+
+// TestAddrBalanceSerialization ensures serializing and deserializing address
+// balance entries works as expected.
+func TestAddrBalanceSerialization(t *testing.T) {
+	t.Parallel()
+
+	balance := AddrBalance{
+		Received: 2100000000000000,
+		Sent:     1234567890,
+		NumUtxos: 258,
+	}
+	want := hexToBytes("0040075af0750700" + "d202964900000000" + "02010000")
+	got := serializeAddrBalance(&balance)
+	if !bytes.Equal(got, want) {
+		t.Fatalf("mismatched bytes - got %x, want %x", got, want)
+	}
+
+	decoded, err := deserializeAddrBalance(got)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(*decoded, balance) {
+		t.Fatalf("mismatched balance - got %v, want %v", *decoded, balance)
+	}
+	if decoded.Balance() != balance.Received-balance.Sent {
+		t.Fatalf("mismatched balance amount - got %d, want %d",
+			decoded.Balance(), balance.Received-balance.Sent)
+	}
+
+	// Ensure entries with an invalid length are rejected.
+	for _, size := range []int{0, addrBalanceEntrySize - 1, addrBalanceEntrySize + 1} {
+		_, err := deserializeAddrBalance(make([]byte, size))
+		if !isDeserializeErr(err) {
+			t.Errorf("size %d: did not receive expected deserialize error "+
+				"- got %v", size, err)
+		}
+	}
+}
+
+// TestAddrUtxoSerialization ensures serializing and deserializing address
+// unspent output keys and entries works as expected.
+func TestAddrUtxoSerialization(t *testing.T) {
+	t.Parallel()
+
+	addrKey := [addrKeySize]byte{addrKeyTypePubKeyHash, 0x01, 0x02}
+	hash, err := chainhash.NewHashFromStr("0e7e2ddab4e6fef4a8b1e00a04fa4e45" +
+		"a4bd0b6a4f9ad9e2a2b7e1f1c7a29f30")
+	if err != nil {
+		t.Fatalf("invalid hash: %v", err)
+	}
+	utxo := AddrUtxo{
+		OutPoint: wire.OutPoint{
+			Hash:  *hash,
+			Index: 2,
+			Tree:  wire.TxTreeStake,
+		},
+		Amount:      150000000,
+		BlockHeight: 778899,
+	}
+
+	key := addrUtxoKey(addrKey, utxo.BlockHeight, &utxo.OutPoint)
+	wantKey := hexToBytes("75" + "000102" + strings.Repeat("00", 18) +
+		"000be293" + "309fa2c7f1e1b7a2e2d99a4f6a0bbda4454efa040ae0b1a8f4fee6b4" +
+		"da2d7e0e" + "00000002")
+	if !bytes.Equal(key, wantKey) {
+		t.Fatalf("mismatched key - got %x, want %x", key, wantKey)
+	}
+
+	entry := serializeAddrUtxoEntry(utxo.Amount, utxo.OutPoint.Tree)
+	wantEntry := hexToBytes("80d1f00800000000" + "01")
+	if !bytes.Equal(entry, wantEntry) {
+		t.Fatalf("mismatched entry - got %x, want %x", entry, wantEntry)
+	}
+
+	decoded, err := deserializeAddrUtxo(key, entry)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(*decoded, utxo) {
+		t.Fatalf("mismatched utxo - got %v, want %v", *decoded, utxo)
+	}
+
+	// Ensure keys and entries with an invalid length are rejected.
+	_, err = deserializeAddrUtxo(key[:len(key)-1], entry)
+	if !isDeserializeErr(err) {
+		t.Errorf("short key: did not receive expected deserialize error - "+
+			"got %v", err)
+	}
+	_, err = deserializeAddrUtxo(key, entry[:len(entry)-1])
+	if !isDeserializeErr(err) {
+		t.Errorf("short entry: did not receive expected deserialize error - "+
+			"got %v", err)
+	}
+}
+
+// TestAddrDeltaSerialization ensures serializing and deserializing address
+// delta keys and entries works as expected.
+func TestAddrDeltaSerialization(t *testing.T) {
+	t.Parallel()
+
+	addrKey := [addrKeySize]byte{addrKeyTypeScriptHash, 0xff}
+	tests := []struct {
+		name  string
+		delta AddrDelta
+		txPos int
+		key   []byte
+		entry []byte
+	}{{
+		name: "credit",
+		delta: AddrDelta{
+			TxHash:      chainhash.Hash{0x01},
+			Index:       3,
+			Amount:      100000000,
+			BlockHeight: 1,
+		},
+		txPos: 5,
+		key: hexToBytes("64" + "03ff" + strings.Repeat("00", 19) +
+			"00000001" + "00000005" + "01" + "00000003"),
+		entry: hexToBytes("01" + strings.Repeat("00", 31) +
+			"00e1f50500000000"),
+	}, {
+		name: "debit",
+		delta: AddrDelta{
+			TxHash:      chainhash.Hash{0x02},
+			IsInput:     true,
+			Index:       0,
+			Amount:      -100000000,
+			BlockHeight: 65536,
+		},
+		txPos: 0,
+		key: hexToBytes("64" + "03ff" + strings.Repeat("00", 19) +
+			"00010000" + "00000000" + "00" + "00000000"),
+		entry: hexToBytes("02" + strings.Repeat("00", 31) +
+			"001f0afaffffffff"),
+	}}
+
+	for _, test := range tests {
+		d := &test.delta
+		key := addrDeltaKey(addrKey, d.BlockHeight, test.txPos, d.IsInput,
+			d.Index)
+		if !bytes.Equal(key, test.key) {
+			t.Errorf("%q: mismatched key - got %x, want %x", test.name, key,
+				test.key)
+			continue
+		}
+
+		entry := serializeAddrDeltaEntry(&d.TxHash, d.Amount)
+		if !bytes.Equal(entry, test.entry) {
+			t.Errorf("%q: mismatched entry - got %x, want %x", test.name,
+				entry, test.entry)
+			continue
+		}
+
+		decoded, err := deserializeAddrDelta(key, entry)
+		if err != nil {
+			t.Errorf("%q: unexpected error: %v", test.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(*decoded, test.delta) {
+			t.Errorf("%q: mismatched delta - got %v, want %v", test.name,
+				*decoded, test.delta)
+			continue
+		}
+	}
+
+	// Ensure the keys sort by block height, then by transaction position, and
+	// then with inputs before outputs.
+	orderedKeys := [][]byte{
+		addrDeltaKey(addrKey, 255, 9, false, 9),
+		addrDeltaKey(addrKey, 256, 0, false, 0),
+		addrDeltaKey(addrKey, 256, 1, true, 7),
+		addrDeltaKey(addrKey, 256, 1, false, 0),
+	}
+	for i := 1; i < len(orderedKeys); i++ {
+		if bytes.Compare(orderedKeys[i-1], orderedKeys[i]) >= 0 {
+			t.Errorf("key %d does not sort before key %d", i-1, i)
+		}
+	}
+}
+
+// TestAddrBalanceIndexConnectDisconnect ensures the address balance index
+// tracks the balance, unspent outputs, and deltas of an address as blocks are
+// connected and disconnected, including when the regular transaction tree of a
+// block is disapproved by the next block.
+func TestAddrBalanceIndexConnectDisconnect(t *testing.T) {
+	db, path := setupDB(t, "test_addrbalanceindex")
+	defer teardownDB(db, path)
+
+	chain, err := newTestChain()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	params := chaincfg.SimNetParams()
+	g, err := chaingen.MakeGenerator(params, nil)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	// All outputs created by the generator pay to the same p2sh script, so
+	// track the address of that script.
+	addr, err := stdaddr.NewAddressScriptHashV0([]byte{txscript.OP_TRUE},
+		params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, pkScript := addr.PaymentScript()
+
+	// Add two blocks to the chain and grab an output from the coinbase of the
+	// second one to spend.
+	addBlock(t, chain, &g, "bk1")
+	bk2 := addBlock(t, chain, &g, "bk2")
+	spend := g.OldestCoinbaseOuts()[0]
+	outpoint := spend.PrevOut()
+
+	// Initialize the address balance index.
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	subber := NewIndexSubscriber(ctx)
+	go subber.Run(ctx)
+
+	err = AddIndexSpendConsumers(db, chain)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	idx, err := NewAddrBalanceIndex(subber, db, chain)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = subber.CatchUp(ctx, chain)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertIndexTip(t, idx, bk2)
+
+	// addrState houses the balance, unspent outputs, and deltas of the
+	// address.
+	type addrState struct {
+		balance AddrBalance
+		utxos   []AddrUtxo
+		deltas  []AddrDelta
+	}
+
+	// fetchState returns the current state of the address from the index.
+	fetchState := func(desc string) *addrState {
+		t.Helper()
+
+		balance, err := idx.Balance(addr)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", desc, err)
+		}
+		utxos, _, err := idx.Utxos(addr, 0, 1000)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", desc, err)
+		}
+		deltas, _, err := idx.Deltas(addr, 0, 1000)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", desc, err)
+		}
+		return &addrState{balance: *balance, utxos: utxos, deltas: deltas}
+	}
+
+	// paidOutputs returns the total amount and number of the outputs in the
+	// regular tree of the provided block that pay to the address.
+	paidOutputs := func(block *dcrutil.Block) (int64, uint32) {
+		var amount int64
+		var num uint32
+		for _, tx := range block.MsgBlock().Transactions {
+			for _, txOut := range tx.TxOut {
+				if txOut.Value != 0 && bytes.Equal(txOut.PkScript, pkScript) {
+					amount += txOut.Value
+					num++
+				}
+			}
+		}
+		return amount, num
+	}
+
+	// assertState ensures the state of the address is the provided base
+	// state with the outputs paid by the provided blocks added, the amount of
+	// the spent output added as sent when spent is set, and the number of
+	// deltas matching the changes.  It also ensures the spent output is only
+	// included in the unspent outputs when it is not spent.
+	assertState := func(desc string, base *addrState, spent bool, blocks ...*dcrutil.Block) *addrState {
+		t.Helper()
+
+		want := base.balance
+		wantDeltas := len(base.deltas)
+		for _, block := range blocks {
+			amount, num := paidOutputs(block)
+			want.Received += amount
+			want.NumUtxos += num
+			wantDeltas += int(num)
+		}
+		if spent {
+			want.Sent += int64(spend.Amount())
+			want.NumUtxos--
+			wantDeltas++
+		}
+
+		state := fetchState(desc)
+		if state.balance != want {
+			t.Fatalf("%s: unexpected balance -- got %+v, want %+v", desc,
+				state.balance, want)
+		}
+		if len(state.utxos) != int(want.NumUtxos) {
+			t.Fatalf("%s: unexpected number of utxos -- got %d, want %d",
+				desc, len(state.utxos), want.NumUtxos)
+		}
+		if len(state.deltas) != wantDeltas {
+			t.Fatalf("%s: unexpected number of deltas -- got %d, want %d",
+				desc, len(state.deltas), wantDeltas)
+		}
+		var foundSpend bool
+		for _, utxo := range state.utxos {
+			if utxo.OutPoint == outpoint {
+				foundSpend = true
+				break
+			}
+		}
+		if foundSpend == spent {
+			t.Fatalf("%s: unexpected spent output presence in utxos -- "+
+				"got %v, want %v", desc, foundSpend, !spent)
+		}
+		return state
+	}
+
+	// assertSameState ensures the current state of the address matches the
+	// provided state.
+	assertSameState := func(desc string, want *addrState) {
+		t.Helper()
+
+		state := fetchState(desc)
+		if !reflect.DeepEqual(state, want) {
+			t.Fatalf("%s: mismatched state -- got %+v, want %+v", desc,
+				state, want)
+		}
+	}
+
+	// Ensure the outputs paid by the blocks that were caught up are indexed.
+	bk2State := fetchState("after catchup")
+	if bk2State.balance.Received == 0 || bk2State.balance.Sent != 0 {
+		t.Fatalf("after catchup: unexpected balance %+v",
+			bk2State.balance)
+	}
+
+	// Connect a block that spends the output and ensure the spend and the
+	// new outputs are indexed.
+	bk3 := addSpendBlock(t, chain, &g, "bk3", &spend)
+	notifyConnect(t, subber, chain, bk3, bk2)
+	assertIndexTip(t, idx, bk3)
+	bk3State := assertState("after spend", bk2State, true, bk3)
+
+	// Connect a block that disapproves the regular tree of the block that
+	// spent the output and ensure the deltas it made are reversed.
+	bk4 := addSpendBlock(t, chain, &g, "bk4", nil, disapproveParent)
+	notifyConnect(t, subber, chain, bk4, bk3)
+	assertIndexTip(t, idx, bk4)
+	assertState("after disapproval", bk2State, false, bk4)
+
+	// Disconnect the disapproving block and ensure the deltas made by the
+	// disapproved block are restored.
+	notifyDisconnect(t, subber, chain, bk4, bk3)
+	assertIndexTip(t, idx, bk3)
+	assertSameState("after disconnecting disapproval", bk3State)
+
+	// Connect a block that both disapproves the regular tree of the block
+	// that spent the output and spends it again and ensure only the new spend
+	// is indexed.
+	g.SetTip("bk3")
+	bk4a := addSpendBlock(t, chain, &g, "bk4a", &spend, disapproveParent)
+	notifyConnect(t, subber, chain, bk4a, bk3)
+	assertIndexTip(t, idx, bk4a)
+	assertState("after respend", bk2State, true, bk4a)
+
+	// Disconnect the block that spent the output again and ensure the
+	// disapproved spend is restored.
+	notifyDisconnect(t, subber, chain, bk4a, bk3)
+	assertIndexTip(t, idx, bk3)
+	assertSameState("after disconnecting respend", bk3State)
+
+	// Disconnect the block that spent the output and ensure the address is
+	// back to its original state.
+	notifyDisconnect(t, subber, chain, bk3, bk2)
+	assertIndexTip(t, idx, bk2)
+	assertSameState("after disconnecting spend", bk2State)
+}
@@ -1173,6 +1173,13 @@ func (*AddrIndex) DropIndex(ctx context.Context, db database.DB) error {
 	return DropAddrIndex(ctx, db)
 }
 
+// spendConsumer returns the spend journal consumer of the address index.
+//
+// This is part of the spendConsumerIndexer interface.
+func (idx *AddrIndex) spendConsumer() *SpendConsumer {
+	return idx.consumer
+}
+
 // ProcessNotification indexes the provided notification based on its
 // notification type.
 //
@@ -1199,17 +1206,6 @@ func (idx *AddrIndex) ProcessNotification(dbTx database.Tx, ntfn *IndexNtfn) err
 			return indexerError(ErrDisconnectBlock, msg)
 		}
 
-		// Remove the associated spend consumer dependency for the disconnected
-		// block.
-		err = idx.Queryer().RemoveSpendConsumerDependency(dbTx, ntfn.Block.Hash(),
-			idx.consumer.id)
-		if err != nil {
-			msg := fmt.Sprintf("%s: unable to remove spend consumer "+
-				"dependency for block %s: %v", idx.Name(),
-				ntfn.Block.Hash(), err)
-			return indexerError(ErrDisconnectBlock, msg)
-		}
-
 		idx.consumer.UpdateTip(ntfn.Parent.Hash())
 
 	default:
@@ -293,7 +293,7 @@ func TestAddrIndexAsync(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	g, err := chaingen.MakeGenerator(chaincfg.SimNetParams())
+	g, err := chaingen.MakeGenerator(chaincfg.SimNetParams(), nil)
 	if err != nil {
 		t.Fatalf("unexpected error %v", err)
 	}
@@ -326,7 +326,7 @@ func TestAddrIndexAsync(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	err = subber.CatchUp(ctx, db, chain)
+	err = subber.CatchUp(ctx, chain)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -423,8 +423,10 @@ func TestAddrIndexAsync(t *testing.T) {
 			bk5.Hash().String(), tipHash.String())
 	}
 
-	// Fetch the first address paid to by bk5's coinbase.
-	out := bk5.MsgBlock().Transactions[0].TxOut[0]
+	// Fetch the first address paid to by bk5's coinbase.  The first output
+	// of the coinbase is a data-only output, so the address is paid to by
+	// the second one.
+	out := bk5.MsgBlock().Transactions[0].TxOut[1]
 	_, addrs := stdscript.ExtractAddrs(out.Version, out.PkScript,
 		addrIdx.chainParams)
 
@@ -478,7 +480,7 @@ func TestAddrIndexAsync(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	err = subber.CatchUp(ctx, db, chain)
+	err = subber.CatchUp(ctx, chain)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -637,7 +639,7 @@ func TestAddrIndexAsync(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	err = subber.CatchUp(ctx, db, chain)
+	err = subber.CatchUp(ctx, chain)
 	if err != nil {
 		t.Fatal(err)
 	}
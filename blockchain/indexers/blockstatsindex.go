@@ -0,0 +1,681 @@
+// Copyright (c) 2023 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package indexers
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/EXCCoin/exccd/blockchain/stake/v4"
+	"github.com/EXCCoin/exccd/blockchain/standalone/v2"
+	"github.com/EXCCoin/exccd/chaincfg/chainhash"
+	"github.com/EXCCoin/exccd/chaincfg/v3"
+	"github.com/EXCCoin/exccd/database/v3"
+	"github.com/EXCCoin/exccd/dcrutil/v4"
+	"github.com/EXCCoin/exccd/txscript/v4"
+	"github.com/EXCCoin/exccd/wire"
+)
+
+const (
+	// blockStatsIndexName is the human-readable name for the index.
+	blockStatsIndexName = "block statistics index"
+
+	// blockStatsIndexVersion is the current version of the block statistics
+	// index.
+	blockStatsIndexVersion = 1
+
+	// blockStatsEntrySize is the size of a block statistics entry.  It
+	// consists of ten 4 byte counts, seven 8 byte amounts and fee rates, the
+	// five 8 byte fee rate percentiles, and four 4 byte transaction sizes.
+	blockStatsEntrySize = 10*4 + 7*8 + numFeeRatePercentiles*8 + 4*4
+
+	// numFeeRatePercentiles is the number of fee rate percentiles tracked for
+	// each block.
+	numFeeRatePercentiles = 5
+)
+
+var (
+	// blockStatsIndexKey is the key of the block statistics index and the db
+	// bucket used to house it.
+	blockStatsIndexKey = []byte("blockstatsidx")
+
+	// FeeRatePercentiles are the percentiles of the fee rates, weighted by
+	// transaction size, that are tracked for each block.
+	FeeRatePercentiles = [numFeeRatePercentiles]uint8{10, 25, 50, 75, 90}
+)
+
+// -----------------------------------------------------------------------------
+// The block statistics index consists of an entry for every block in the main
+// chain which maps the hash of the block to a summary of the transactions it
+// contains.
+//
+// The statistics only depend on the contents of the block, so they are
+// calculated once when the block is connected and removed when it is
+// disconnected.  The values of the outputs spent by the transactions in the
+// block are loaded from the spend journal.
+//
+// The serialized format for the keys and values in the block statistics index
+// bucket is:
+//
+//   <block hash> = <counts><amounts><percentiles><sizes>
+//
+//   Field              Type              Size
+//   block hash         chainhash.Hash    32 bytes
+//   size               uint32            4 bytes
+//   num txns           uint32            4 bytes
+//   num stake txns     uint32            4 bytes
+//   num votes          uint32            4 bytes
+//   num tickets        uint32            4 bytes
+//   num revocations    uint32            4 bytes
+//   num inputs         uint32            4 bytes
+//   num outputs        uint32            4 bytes
+//   utxo increase      int32             4 bytes
+//   num fee txns       uint32            4 bytes
+//   total out          int64             8 bytes
+//   total fee          int64             8 bytes
+//   min fee            int64             8 bytes
+//   max fee            int64             8 bytes
+//   median fee         int64             8 bytes
+//   min fee rate       int64             8 bytes
+//   max fee rate       int64             8 bytes
+//   fee percentiles    [5]int64          40 bytes
+//   total tx size      uint32            4 bytes
+//   min tx size        uint32            4 bytes
+//   max tx size        uint32            4 bytes
+//   median tx size     uint32            4 bytes
+//   -----
+//   Total: 152 bytes
+// -----------------------------------------------------------------------------
+
+// BlockStats houses statistics about the transactions in a block.
+//
+// The fee statistics only consider the transactions that pay fees, which are
+// all regular transactions other than the coinbase along with the ticket
+// purchases and revocations.  Fee rates are in atoms per kilobyte.
+type BlockStats struct {
+	// Size is the serialized size of the block.
+	Size uint32
+
+	// NumTxns and NumStakeTxns are the number of transactions in the regular
+	// and stake transaction trees, respectively.
+	NumTxns      uint32
+	NumStakeTxns uint32
+
+	// NumVotes, NumTickets, and NumRevocations are the number of votes,
+	// ticket purchases, and revocations in the block.
+	NumVotes       uint32
+	NumTickets     uint32
+	NumRevocations uint32
+
+	// NumInputs is the number of inputs that spend a previous output and
+	// NumOutputs is the number of outputs created by all transactions.
+	NumInputs  uint32
+	NumOutputs uint32
+
+	// UtxoIncrease is the number of spendable outputs created minus the number
+	// of outputs spent by the transactions in the block.
+	UtxoIncrease int32
+
+	// NumFeeTxns is the number of transactions that pay fees.
+	NumFeeTxns uint32
+
+	// TotalOut is the total value of the outputs of the transactions that pay
+	// fees.
+	TotalOut int64
+
+	// TotalFee, MinFee, MaxFee, and MedianFee summarize the fees paid.
+	TotalFee  int64
+	MinFee    int64
+	MaxFee    int64
+	MedianFee int64
+
+	// MinFeeRate and MaxFeeRate are the lowest and highest fee rates paid.
+	MinFeeRate int64
+	MaxFeeRate int64
+
+	// FeeRates are the fee rates at each of the percentiles defined by
+	// FeeRatePercentiles weighted by transaction size.
+	FeeRates [numFeeRatePercentiles]int64
+
+	// TotalTxSize, MinTxSize, MaxTxSize, and MedianTxSize summarize the
+	// serialized sizes of the transactions that pay fees.
+	TotalTxSize  uint32
+	MinTxSize    uint32
+	MaxTxSize    uint32
+	MedianTxSize uint32
+}
+
+// serializeBlockStats returns the serialization of the provided block
+// statistics.
+func serializeBlockStats(stats *BlockStats) []byte {
+	serialized := make([]byte, blockStatsEntrySize)
+	offset := 0
+	putUint32 := func(v uint32) {
+		byteOrder.PutUint32(serialized[offset:], v)
+		offset += 4
+	}
+	putInt64 := func(v int64) {
+		byteOrder.PutUint64(serialized[offset:], uint64(v))
+		offset += 8
+	}
+
+	putUint32(stats.Size)
+	putUint32(stats.NumTxns)
+	putUint32(stats.NumStakeTxns)
+	putUint32(stats.NumVotes)
+	putUint32(stats.NumTickets)
+	putUint32(stats.NumRevocations)
+	putUint32(stats.NumInputs)
+	putUint32(stats.NumOutputs)
+	putUint32(uint32(stats.UtxoIncrease))
+	putUint32(stats.NumFeeTxns)
+	putInt64(stats.TotalOut)
+	putInt64(stats.TotalFee)
+	putInt64(stats.MinFee)
+	putInt64(stats.MaxFee)
+	putInt64(stats.MedianFee)
+	putInt64(stats.MinFeeRate)
+	putInt64(stats.MaxFeeRate)
+	for _, feeRate := range stats.FeeRates {
+		putInt64(feeRate)
+	}
+	putUint32(stats.TotalTxSize)
+	putUint32(stats.MinTxSize)
+	putUint32(stats.MaxTxSize)
+	putUint32(stats.MedianTxSize)
+	return serialized
+}
+
+// deserializeBlockStats decodes the provided serialized block statistics.
+func deserializeBlockStats(serialized []byte) (*BlockStats, error) {
+	if len(serialized) != blockStatsEntrySize {
+		return nil, errDeserialize(fmt.Sprintf("unexpected block statistics "+
+			"entry length %d", len(serialized)))
+	}
+
+	offset := 0
+	uint32At := func() uint32 {
+		v := byteOrder.Uint32(serialized[offset:])
+		offset += 4
+		return v
+	}
+	int64At := func() int64 {
+		v := int64(byteOrder.Uint64(serialized[offset:]))
+		offset += 8
+		return v
+	}
+
+	var stats BlockStats
+	stats.Size = uint32At()
+	stats.NumTxns = uint32At()
+	stats.NumStakeTxns = uint32At()
+	stats.NumVotes = uint32At()
+	stats.NumTickets = uint32At()
+	stats.NumRevocations = uint32At()
+	stats.NumInputs = uint32At()
+	stats.NumOutputs = uint32At()
+	stats.UtxoIncrease = int32(uint32At())
+	stats.NumFeeTxns = uint32At()
+	stats.TotalOut = int64At()
+	stats.TotalFee = int64At()
+	stats.MinFee = int64At()
+	stats.MaxFee = int64At()
+	stats.MedianFee = int64At()
+	stats.MinFeeRate = int64At()
+	stats.MaxFeeRate = int64At()
+	for i := range stats.FeeRates {
+		stats.FeeRates[i] = int64At()
+	}
+	stats.TotalTxSize = uint32At()
+	stats.MinTxSize = uint32At()
+	stats.MaxTxSize = uint32At()
+	stats.MedianTxSize = uint32At()
+	return &stats, nil
+}
+
+// txFeeInfo houses the fee and size of a transaction that pays fees.
+type txFeeInfo struct {
+	fee     int64
+	size    int64
+	feeRate int64
+}
+
+// medianInt64 returns the median of the provided sorted values.  The mean of
+// the two middle values is returned when there is an even number of values.
+func medianInt64(sorted []int64) int64 {
+	n := len(sorted)
+	if n == 0 {
+		return 0
+	}
+	if n%2 == 0 {
+		return (sorted[n/2-1] + sorted[n/2]) / 2
+	}
+	return sorted[n/2]
+}
+
+// calcFeeRatePercentiles returns the fee rates at each of the percentiles
+// defined by FeeRatePercentiles weighted by the size of the transactions.  The
+// provided transactions must be sorted by fee rate.
+func calcFeeRatePercentiles(sorted []txFeeInfo, totalSize int64) [numFeeRatePercentiles]int64 {
+	var feeRates [numFeeRatePercentiles]int64
+	if len(sorted) == 0 {
+		return feeRates
+	}
+
+	var cumulativeSize int64
+	var idx int
+	for _, info := range sorted {
+		cumulativeSize += info.size
+		for idx < numFeeRatePercentiles && cumulativeSize*100 >=
+			totalSize*int64(FeeRatePercentiles[idx]) {
+
+			feeRates[idx] = info.feeRate
+			idx++
+		}
+	}
+	for ; idx < numFeeRatePercentiles; idx++ {
+		feeRates[idx] = sorted[len(sorted)-1].feeRate
+	}
+	return feeRates
+}
+
+// calcBlockStats calculates the statistics for the provided block.  The values
+// of the outputs spent by the block are obtained from the provided source of
+// previous outputs when it provides them, otherwise the input values committed
+// to by the transactions, which were proven correct when the block was
+// validated, are used.
+func calcBlockStats(block *dcrutil.Block, prevScripts PrevScripter, isTreasuryEnabled bool) *BlockStats {
+	msgBlock := block.MsgBlock()
+	prevAmounts, _ := prevScripts.(PrevAmounter)
+	inputValue := func(txIn *wire.TxIn) int64 {
+		if prevAmounts != nil {
+			amount, ok := prevAmounts.PrevAmount(&txIn.PreviousOutPoint)
+			if ok {
+				return amount
+			}
+		}
+		return txIn.ValueIn
+	}
+
+	stats := BlockStats{
+		Size:         uint32(msgBlock.SerializeSize()),
+		NumTxns:      uint32(len(msgBlock.Transactions)),
+		NumStakeTxns: uint32(len(msgBlock.STransactions)),
+	}
+	var feeInfos []txFeeInfo
+	processTx := func(tx *wire.MsgTx, paysFees bool) {
+		var totalIn, totalOut int64
+		for _, txIn := range tx.TxIn {
+			if isNullOutPoint(&txIn.PreviousOutPoint) {
+				continue
+			}
+			stats.NumInputs++
+			stats.UtxoIncrease--
+			if paysFees {
+				totalIn += inputValue(txIn)
+			}
+		}
+		for _, txOut := range tx.TxOut {
+			stats.NumOutputs++
+			if !txscript.IsUnspendable(txOut.Value, txOut.PkScript) {
+				stats.UtxoIncrease++
+			}
+			totalOut += txOut.Value
+		}
+		if !paysFees {
+			return
+		}
+
+		size := int64(tx.SerializeSize())
+		fee := totalIn - totalOut
+		feeInfos = append(feeInfos, txFeeInfo{
+			fee:     fee,
+			size:    size,
+			feeRate: fee * 1000 / size,
+		})
+		stats.TotalOut += totalOut
+	}
+
+	for i, tx := range msgBlock.Transactions {
+		processTx(tx, i != 0)
+	}
+	for i, tx := range msgBlock.STransactions {
+		switch {
+		case stake.IsSStx(tx):
+			stats.NumTickets++
+			processTx(tx, true)
+
+		case stake.IsSSGen(tx, isTreasuryEnabled):
+			stats.NumVotes++
+			processTx(tx, false)
+
+		case isTreasuryEnabled && i == 0 && standalone.IsTreasuryBase(tx):
+			processTx(tx, false)
+
+		default:
+			stats.NumRevocations++
+			processTx(tx, true)
+		}
+	}
+
+	// Summarize the fees and sizes of the transactions that pay fees.
+	stats.NumFeeTxns = uint32(len(feeInfos))
+	if len(feeInfos) == 0 {
+		return &stats
+	}
+	fees := make([]int64, 0, len(feeInfos))
+	sizes := make([]int64, 0, len(feeInfos))
+	var totalSize int64
+	for _, info := range feeInfos {
+		fees = append(fees, info.fee)
+		sizes = append(sizes, info.size)
+		stats.TotalFee += info.fee
+		totalSize += info.size
+	}
+	sort.Slice(fees, func(i, j int) bool { return fees[i] < fees[j] })
+	sort.Slice(sizes, func(i, j int) bool { return sizes[i] < sizes[j] })
+	sort.SliceStable(feeInfos, func(i, j int) bool {
+		return feeInfos[i].feeRate < feeInfos[j].feeRate
+	})
+
+	stats.MinFee = fees[0]
+	stats.MaxFee = fees[len(fees)-1]
+	stats.MedianFee = medianInt64(fees)
+	stats.MinFeeRate = feeInfos[0].feeRate
+	stats.MaxFeeRate = feeInfos[len(feeInfos)-1].feeRate
+	stats.FeeRates = calcFeeRatePercentiles(feeInfos, totalSize)
+	stats.TotalTxSize = uint32(totalSize)
+	stats.MinTxSize = uint32(sizes[0])
+	stats.MaxTxSize = uint32(sizes[len(sizes)-1])
+	stats.MedianTxSize = uint32(medianInt64(sizes))
+	return &stats
+}
+
+// BlockStatsIndex implements a block statistics index.  That is to say, it
+// supports querying a summary of the fees, sizes, and transactions of any
+// block in the main chain.
+type BlockStatsIndex struct {
+	// The following fields are set when the instance is created and can't
+	// be changed afterwards, so there is no need to protect them with a
+	// separate mutex.
+	db       database.DB
+	chain    ChainQueryer
+	sub      *IndexSubscription
+	consumer *SpendConsumer
+
+	subscribers map[chan bool]struct{}
+	mtx         sync.Mutex
+	cancel      context.CancelFunc
+}
+
+// Ensure the BlockStatsIndex type implements the Indexer interface.
+var _ Indexer = (*BlockStatsIndex)(nil)
+
+// Init initializes the block statistics index.
+//
+// This is part of the Indexer interface.
+func (idx *BlockStatsIndex) Init(ctx context.Context, chainParams *chaincfg.Params) error {
+	if interruptRequested(ctx) {
+		return indexerError(ErrInterruptRequested, interruptMsg)
+	}
+
+	// Finish any drops that were previously interrupted.
+	if err := finishDrop(ctx, idx); err != nil {
+		return err
+	}
+
+	// Create the initial state for the index as needed.
+	if err := createIndex(idx, &chainParams.GenesisHash); err != nil {
+		return err
+	}
+
+	// Upgrade the index as needed.
+	if err := upgradeIndex(ctx, idx, &chainParams.GenesisHash); err != nil {
+		return err
+	}
+
+	// Recover the block statistics index and its dependents to the main chain
+	// if needed.
+	return recover(ctx, idx)
+}
+
+// Key returns the database key to use for the index as a byte slice.
+//
+// This is part of the Indexer interface.
+func (idx *BlockStatsIndex) Key() []byte {
+	return blockStatsIndexKey
+}
+
+// Name returns the human-readable name of the index.
+//
+// This is part of the Indexer interface.
+func (idx *BlockStatsIndex) Name() string {
+	return blockStatsIndexName
+}
+
+// Version returns the current version of the index.
+//
+// This is part of the Indexer interface.
+func (idx *BlockStatsIndex) Version() uint32 {
+	return blockStatsIndexVersion
+}
+
+// DB returns the database of the index.
+//
+// This is part of the Indexer interface.
+func (idx *BlockStatsIndex) DB() database.DB {
+	return idx.db
+}
+
+// Queryer returns the chain queryer.
+//
+// This is part of the Indexer interface.
+func (idx *BlockStatsIndex) Queryer() ChainQueryer {
+	return idx.chain
+}
+
+// Tip returns the current tip of the index.
+//
+// This is part of the Indexer interface.
+func (idx *BlockStatsIndex) Tip() (int64, *chainhash.Hash, error) {
+	return tip(idx.db, idx.Key())
+}
+
+// Create is invoked when the index is created for the first time.  It creates
+// the bucket for the block statistics index.
+//
+// This is part of the Indexer interface.
+func (idx *BlockStatsIndex) Create(dbTx database.Tx) error {
+	_, err := dbTx.Metadata().CreateBucket(blockStatsIndexKey)
+	return err
+}
+
+// IndexSubscription returns the subscription for index updates.
+//
+// This is part of the Indexer interface.
+func (idx *BlockStatsIndex) IndexSubscription() *IndexSubscription {
+	return idx.sub
+}
+
+// Subscribers returns all client channels waiting for the next index update.
+//
+// This is part of the Indexer interface.
+func (idx *BlockStatsIndex) Subscribers() map[chan bool]struct{} {
+	idx.mtx.Lock()
+	defer idx.mtx.Unlock()
+	return fetchIndexerSubscribers(idx.subscribers)
+}
+
+// WaitForSync subscribes clients for the next index sync update.
+//
+// This is part of the Indexer interface.
+func (idx *BlockStatsIndex) WaitForSync() chan bool {
+	c := make(chan bool)
+
+	removeIndexerSub := func() {
+		idx.mtx.Lock()
+		delete(idx.subscribers, c)
+		idx.mtx.Unlock()
+	}
+
+	go purgeIndexerSubscription(c, removeIndexerSub)
+
+	idx.mtx.Lock()
+	idx.subscribers[c] = struct{}{}
+	idx.mtx.Unlock()
+
+	return c
+}
+
+// connectBlock adds the statistics entry for the passed block.
+func (idx *BlockStatsIndex) connectBlock(dbTx database.Tx, block *dcrutil.Block, prevScripts PrevScripter, isTreasuryEnabled bool) error {
+	stats := calcBlockStats(block, prevScripts, isTreasuryEnabled)
+	bucket := dbTx.Metadata().Bucket(blockStatsIndexKey)
+	err := bucket.Put(block.Hash()[:], serializeBlockStats(stats))
+	if err != nil {
+		return err
+	}
+
+	// Update the current index tip.
+	return dbPutIndexerTip(dbTx, idx.Key(), block.Hash(), int32(block.Height()))
+}
+
+// disconnectBlock removes the statistics entry for the passed block.
+func (idx *BlockStatsIndex) disconnectBlock(dbTx database.Tx, block *dcrutil.Block) error {
+	bucket := dbTx.Metadata().Bucket(blockStatsIndexKey)
+	if err := bucket.Delete(block.Hash()[:]); err != nil {
+		return err
+	}
+
+	// Update the current index tip.
+	return dbPutIndexerTip(dbTx, idx.Key(), &block.MsgBlock().Header.PrevBlock,
+		int32(block.Height()-1))
+}
+
+// Stats returns the statistics for the block with the provided hash from the
+// block statistics index.  When there is no entry for the block, which is the
+// case for blocks that are not in the main chain or have not been indexed yet,
+// nil will be returned for both the statistics and the error.
+//
+// This function is safe for concurrent access.
+func (idx *BlockStatsIndex) Stats(hash *chainhash.Hash) (*BlockStats, error) {
+	var stats *BlockStats
+	err := idx.db.View(func(dbTx database.Tx) error {
+		bucket := dbTx.Metadata().Bucket(blockStatsIndexKey)
+		serialized := bucket.Get(hash[:])
+		if serialized == nil {
+			return nil
+		}
+
+		var err error
+		stats, err = deserializeBlockStats(serialized)
+		if err != nil {
+			str := fmt.Sprintf("corrupt block statistics entry for %v: %v",
+				hash, err)
+			return makeDbErr(database.ErrCorruption, str)
+		}
+		return nil
+	})
+	return stats, err
+}
+
+// NewBlockStatsIndex returns a new instance of an indexer that is used to
+// create a mapping of all blocks in the main chain to statistics about the
+// transactions they contain.
+func NewBlockStatsIndex(subscriber *IndexSubscriber, db database.DB, chain ChainQueryer) (*BlockStatsIndex, error) {
+	idx := &BlockStatsIndex{
+		db:          db,
+		chain:       chain,
+		subscribers: make(map[chan bool]struct{}),
+		cancel:      subscriber.cancel,
+	}
+
+	sc, err := chain.FetchSpendConsumer(idx.Name())
+	if err != nil {
+		return nil, err
+	}
+
+	consumer, ok := sc.(*SpendConsumer)
+	if !ok {
+		return nil, indexerError(ErrInvalidSpendConsumerType,
+			"consumer not of type SpendConsumer")
+	}
+
+	idx.consumer = consumer
+
+	// The block statistics index is an optional index. It has no
+	// prerequisite and is updated asynchronously.
+	sub, err := subscriber.Subscribe(idx, noPrereqs)
+	if err != nil {
+		return nil, err
+	}
+
+	idx.sub = sub
+
+	err = idx.Init(subscriber.ctx, chain.ChainParams())
+	if err != nil {
+		return nil, err
+	}
+
+	return idx, nil
+}
+
+// DropBlockStatsIndex drops the block statistics index from the provided
+// database if it exists.
+func DropBlockStatsIndex(ctx context.Context, db database.DB) error {
+	return dropFlatIndex(ctx, db, blockStatsIndexKey, blockStatsIndexName)
+}
+
+// DropIndex drops the block statistics index from the provided database if it
+// exists.
+func (*BlockStatsIndex) DropIndex(ctx context.Context, db database.DB) error {
+	return DropBlockStatsIndex(ctx, db)
+}
+
+// spendConsumer returns the spend journal consumer of the block statistics
+// index.
+//
+// This is part of the spendConsumerIndexer interface.
+func (idx *BlockStatsIndex) spendConsumer() *SpendConsumer {
+	return idx.consumer
+}
+
+// ProcessNotification indexes the provided notification based on its
+// notification type.
+//
+// This is part of the Indexer interface.
+func (idx *BlockStatsIndex) ProcessNotification(dbTx database.Tx, ntfn *IndexNtfn) error {
+	switch ntfn.NtfnType {
+	case ConnectNtfn:
+		err := idx.connectBlock(dbTx, ntfn.Block, ntfn.PrevScripts,
+			ntfn.IsTreasuryEnabled)
+		if err != nil {
+			msg := fmt.Sprintf("%s: unable to connect block: %v",
+				idx.Name(), err)
+			return indexerError(ErrConnectBlock, msg)
+		}
+
+		idx.consumer.UpdateTip(ntfn.Block.Hash())
+
+	case DisconnectNtfn:
+		err := idx.disconnectBlock(dbTx, ntfn.Block)
+		if err != nil {
+			msg := fmt.Sprintf("%s: unable to disconnect block: %v",
+				idx.Name(), err)
+			return indexerError(ErrDisconnectBlock, msg)
+		}
+
+		idx.consumer.UpdateTip(ntfn.Parent.Hash())
+
+	default:
+		msg := fmt.Sprintf("%s: unknown notification type provided: %d",
+			idx.Name(), ntfn.NtfnType)
+		return indexerError(ErrInvalidNotificationType, msg)
+	}
+
+	return nil
+}
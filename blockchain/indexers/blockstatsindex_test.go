@@ -0,0 +1,335 @@
+// Copyright (c) 2023 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package indexers
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/EXCCoin/exccd/blockchain/v4/chaingen"
+	"github.com/EXCCoin/exccd/chaincfg/chainhash"
+	"github.com/EXCCoin/exccd/chaincfg/v3"
+	"github.com/EXCCoin/exccd/dcrutil/v4"
+	"github.com/EXCCoin/exccd/wire"
+)
+
+// testPrevOutputs provides a source of previous output scripts and amounts for
+// the block statistics tests.
+type testPrevOutputs map[wire.OutPoint]int64
+
+// PrevScript returns an empty script for the provided previous outpoint when it
+// exists in the source.
+func (p testPrevOutputs) PrevScript(prevOut *wire.OutPoint) (uint16, []byte, bool) {
+	_, ok := p[*prevOut]
+	return 0, nil, ok
+}
+
+// PrevAmount returns the amount of the provided previous outpoint when it
+// exists in the source.
+func (p testPrevOutputs) PrevAmount(prevOut *wire.OutPoint) (int64, bool) {
+	amount, ok := p[*prevOut]
+	return amount, ok
+}
+
+// TestBlockStatsSerialization ensures serializing and deserializing block
+// statistics entries works as expected.
+func TestBlockStatsSerialization(t *testing.T) {
+	t.Parallel()
+
+	stats := BlockStats{
+		Size:           1,
+		NumTxns:        2,
+		NumStakeTxns:   3,
+		NumVotes:       4,
+		NumTickets:     5,
+		NumRevocations: 6,
+		NumInputs:      7,
+		NumOutputs:     8,
+		UtxoIncrease:   -9,
+		NumFeeTxns:     10,
+		TotalOut:       11,
+		TotalFee:       12,
+		MinFee:         13,
+		MaxFee:         14,
+		MedianFee:      15,
+		MinFeeRate:     16,
+		MaxFeeRate:     17,
+		FeeRates:       [numFeeRatePercentiles]int64{18, 19, 20, 21, 22},
+		TotalTxSize:    23,
+		MinTxSize:      24,
+		MaxTxSize:      25,
+		MedianTxSize:   26,
+	}
+	serialized := serializeBlockStats(&stats)
+	if len(serialized) != blockStatsEntrySize {
+		t.Fatalf("unexpected serialized length - got %d, want %d",
+			len(serialized), blockStatsEntrySize)
+	}
+	decoded, err := deserializeBlockStats(serialized)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(*decoded, stats) {
+		t.Fatalf("mismatched stats - got %+v, want %+v", *decoded, stats)
+	}
+
+	// Ensure entries with an invalid length are rejected.
+	for _, size := range []int{0, blockStatsEntrySize - 1, blockStatsEntrySize + 1} {
+		_, err := deserializeBlockStats(make([]byte, size))
+		if !isDeserializeErr(err) {
+			t.Errorf("size %d: did not receive expected deserialize error "+
+				"- got %v", size, err)
+		}
+	}
+}
+
+// TestCalcFeeRatePercentiles ensures the size-weighted fee rate percentiles
+// are calculated as expected.
+func TestCalcFeeRatePercentiles(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		infos []txFeeInfo
+		want  [numFeeRatePercentiles]int64
+	}{{
+		name: "no transactions",
+		want: [numFeeRatePercentiles]int64{},
+	}, {
+		name:  "single transaction",
+		infos: []txFeeInfo{{size: 250, feeRate: 10000}},
+		want:  [numFeeRatePercentiles]int64{10000, 10000, 10000, 10000, 10000},
+	}, {
+		name: "weighted by size",
+		infos: []txFeeInfo{
+			{size: 100, feeRate: 10000},
+			{size: 100, feeRate: 20000},
+			{size: 600, feeRate: 30000},
+			{size: 200, feeRate: 40000},
+		},
+		want: [numFeeRatePercentiles]int64{10000, 30000, 30000, 30000, 40000},
+	}}
+
+	for _, test := range tests {
+		var totalSize int64
+		for _, info := range test.infos {
+			totalSize += info.size
+		}
+		got := calcFeeRatePercentiles(test.infos, totalSize)
+		if got != test.want {
+			t.Errorf("%q: mismatched fee rates - got %v, want %v", test.name,
+				got, test.want)
+		}
+	}
+}
+
+// TestCalcBlockStats ensures the statistics calculated for a block are the
+// expected values.
+func TestCalcBlockStats(t *testing.T) {
+	t.Parallel()
+
+	p2pkhScript := hexToBytes("76a914000000000000000000000000000000000000000088ac")
+	nullDataScript := hexToBytes("6a0401020304")
+	nullOutPoint := wire.OutPoint{Index: wire.MaxPrevOutIndex}
+
+	coinbase := wire.NewMsgTx()
+	coinbase.AddTxIn(wire.NewTxIn(&nullOutPoint, 300000000, nil))
+	coinbase.AddTxOut(wire.NewTxOut(0, nullDataScript))
+	coinbase.AddTxOut(wire.NewTxOut(300000000, p2pkhScript))
+
+	// The first transaction spends an output that is available in the source
+	// of previous outputs, which takes precedence over the input value.
+	prevOut1 := wire.OutPoint{Hash: chainhash.Hash{0x01}}
+	tx1 := wire.NewMsgTx()
+	tx1.AddTxIn(wire.NewTxIn(&prevOut1, 0, nil))
+	tx1.AddTxOut(wire.NewTxOut(99990000, p2pkhScript))
+
+	// The second transaction spends outputs that are not available in the
+	// source of previous outputs, so the input values are used.
+	prevOut2 := wire.OutPoint{Hash: chainhash.Hash{0x02}}
+	prevOut3 := wire.OutPoint{Hash: chainhash.Hash{0x03}, Index: 1}
+	tx2 := wire.NewMsgTx()
+	tx2.AddTxIn(wire.NewTxIn(&prevOut2, 50000000, nil))
+	tx2.AddTxIn(wire.NewTxIn(&prevOut3, 50000000, nil))
+	tx2.AddTxOut(wire.NewTxOut(60000000, p2pkhScript))
+	tx2.AddTxOut(wire.NewTxOut(39950000, p2pkhScript))
+	tx2.AddTxOut(wire.NewTxOut(0, nullDataScript))
+
+	msgBlock := &wire.MsgBlock{
+		Header:       wire.BlockHeader{Height: 100},
+		Transactions: []*wire.MsgTx{coinbase, tx1, tx2},
+	}
+	block := dcrutil.NewBlock(msgBlock)
+	prevOutputs := testPrevOutputs{prevOut1: 100000000}
+	stats := calcBlockStats(block, prevOutputs, false)
+
+	// The first transaction is smaller and pays a lower fee rate.
+	size1 := int64(tx1.SerializeSize())
+	size2 := int64(tx2.SerializeSize())
+	feeRate1 := 10000 * 1000 / size1
+	feeRate2 := 50000 * 1000 / size2
+
+	want := BlockStats{
+		Size:         uint32(msgBlock.SerializeSize()),
+		NumTxns:      3,
+		NumStakeTxns: 0,
+		NumInputs:    3,
+		NumOutputs:   6,
+		UtxoIncrease: 4 - 3,
+		NumFeeTxns:   2,
+		TotalOut:     99990000 + 60000000 + 39950000,
+		TotalFee:     60000,
+		MinFee:       10000,
+		MaxFee:       50000,
+		MedianFee:    30000,
+		MinFeeRate:   feeRate1,
+		MaxFeeRate:   feeRate2,
+		TotalTxSize:  uint32(size1 + size2),
+		MinTxSize:    uint32(size1),
+		MaxTxSize:    uint32(size2),
+		MedianTxSize: uint32((size1 + size2) / 2),
+	}
+	want.FeeRates = calcFeeRatePercentiles([]txFeeInfo{
+		{fee: 10000, size: size1, feeRate: feeRate1},
+		{fee: 50000, size: size2, feeRate: feeRate2},
+	}, size1+size2)
+	if !reflect.DeepEqual(*stats, want) {
+		t.Fatalf("mismatched stats - got %+v, want %+v", *stats, want)
+	}
+}
+
+// TestBlockStatsIndexConnectDisconnect ensures the block statistics index
+// stores and removes the statistics of blocks as they are connected and
+// disconnected.
+func TestBlockStatsIndexConnectDisconnect(t *testing.T) {
+	db, path := setupDB(t, "test_blockstatsindex")
+	defer teardownDB(db, path)
+
+	chain, err := newTestChain()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	g, err := chaingen.MakeGenerator(chaincfg.SimNetParams(), nil)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	// Add two blocks to the chain and grab an output from the coinbase of the
+	// second one to spend.
+	bk1 := addBlock(t, chain, &g, "bk1")
+	bk2 := addBlock(t, chain, &g, "bk2")
+	spend := g.OldestCoinbaseOuts()[0]
+
+	// Initialize the block statistics index.
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	subber := NewIndexSubscriber(ctx)
+	go subber.Run(ctx)
+
+	err = AddIndexSpendConsumers(db, chain)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	idx, err := NewBlockStatsIndex(subber, db, chain)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = subber.CatchUp(ctx, chain)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertIndexTip(t, idx, bk2)
+
+	// fetchStats returns the statistics for the provided block from the
+	// index.
+	fetchStats := func(desc string, block *dcrutil.Block) *BlockStats {
+		t.Helper()
+
+		stats, err := idx.Stats(block.Hash())
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", desc, err)
+		}
+		return stats
+	}
+
+	// assertStats ensures the statistics stored for the provided block match
+	// the statistics calculated from it.
+	assertStats := func(desc string, block *dcrutil.Block) *BlockStats {
+		t.Helper()
+
+		prevScripts, err := chain.PrevScripts(block)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := calcBlockStats(block, prevScripts, false)
+		stats := fetchStats(desc, block)
+		if stats == nil || !reflect.DeepEqual(stats, want) {
+			t.Fatalf("%s: mismatched stats for %s -- got %+v, want %+v",
+				desc, block.Hash(), stats, want)
+		}
+		return stats
+	}
+
+	// assertNoStats ensures there are no statistics stored for the provided
+	// block.
+	assertNoStats := func(desc string, block *dcrutil.Block) {
+		t.Helper()
+
+		if stats := fetchStats(desc, block); stats != nil {
+			t.Fatalf("%s: unexpected stats for %s: %+v", desc,
+				block.Hash(), stats)
+		}
+	}
+
+	// Ensure the blocks that were caught up are indexed and the coinbases are
+	// not treated as paying fees.
+	for _, block := range []*dcrutil.Block{bk1, bk2} {
+		stats := assertStats("after catchup", block)
+		if stats.NumTxns != 1 || stats.NumInputs != 0 ||
+			stats.NumFeeTxns != 0 {
+
+			t.Fatalf("after catchup: unexpected stats for %s: %+v",
+				block.Hash(), stats)
+		}
+	}
+
+	// Connect a block that spends an output and ensure its statistics are
+	// stored, including the fee paid by the spending transaction.
+	bk3 := addSpendBlock(t, chain, &g, "bk3", &spend)
+	notifyConnect(t, subber, chain, bk3, bk2)
+	assertIndexTip(t, idx, bk3)
+	stats := assertStats("after spend", bk3)
+	var spendOut int64
+	for _, txOut := range bk3.MsgBlock().Transactions[1].TxOut {
+		spendOut += txOut.Value
+	}
+	wantFee := int64(spend.Amount()) - spendOut
+	if stats.NumTxns != 2 || stats.NumInputs != 1 || stats.NumFeeTxns != 1 ||
+		stats.TotalFee != wantFee {
+
+		t.Fatalf("after spend: unexpected stats %+v (want fee %d)", stats,
+			wantFee)
+	}
+
+	// Disconnect the block and ensure its statistics are removed.
+	notifyDisconnect(t, subber, chain, bk3, bk2)
+	assertIndexTip(t, idx, bk2)
+	assertNoStats("after disconnect", bk3)
+	assertStats("after disconnect", bk2)
+
+	// Connect a side chain block in its place and ensure only the new block
+	// has statistics stored.
+	g.SetTip("bk2")
+	bk3a := addSpendBlock(t, chain, &g, "bk3a", nil)
+	notifyConnect(t, subber, chain, bk3a, bk2)
+	assertIndexTip(t, idx, bk3a)
+	assertStats("after reorg", bk3a)
+	assertNoStats("after reorg", bk3)
+}
@@ -0,0 +1,433 @@
+// Copyright (c) 2023 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package indexers
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/EXCCoin/exccd/chaincfg/chainhash"
+	"github.com/EXCCoin/exccd/chaincfg/v3"
+	"github.com/EXCCoin/exccd/database/v3"
+	"github.com/EXCCoin/exccd/dcrutil/v4"
+	"github.com/EXCCoin/exccd/gcs/v3/blockcf2"
+)
+
+const (
+	// cfHeaderIndexName is the human-readable name for the index.
+	cfHeaderIndexName = "filter header index"
+
+	// cfHeaderIndexVersion is the current version of the filter header index.
+	cfHeaderIndexVersion = 1
+
+	// cfHeaderEntrySize is the size of a filter header index entry.  It
+	// consists of the filter hash followed by the filter header.
+	cfHeaderEntrySize = 2 * chainhash.HashSize
+)
+
+var (
+	// cfHeaderIndexKey is the key of the filter header index and the db
+	// bucket used to house it.
+	cfHeaderIndexKey = []byte("cfheaderidx")
+)
+
+// -----------------------------------------------------------------------------
+// The filter header index consists of an entry for every block in the main
+// chain which maps the hash of the block to the hash of its version 2 GCS
+// filter along with its filter header.
+//
+// The filter headers form a chain where each header commits to the filter of
+// its block and the header of the previous block:
+//
+//   filter header = BLAKE-256(filter hash || previous filter header)
+//
+// The previous filter header of the genesis block is all zeros.  This allows
+// light clients to verify a batch of filters fetched from untrusted sources
+// against a single filter header obtained from a trusted one.
+//
+// The filters only depend on the contents of the blocks and the scripts of the
+// outputs they spend, so the entries are calculated when the block is
+// connected and removed when it is disconnected.  The scripts of the spent
+// outputs are loaded from the spend journal.  The genesis block is never
+// connected, so its entry is calculated when the index is created.
+//
+// The serialized format for the keys and values in the filter header index
+// bucket is:
+//
+//   <block hash> = <filter hash><filter header>
+//
+//   Field           Type              Size
+//   block hash      chainhash.Hash    32 bytes
+//   filter hash     chainhash.Hash    32 bytes
+//   filter header   chainhash.Hash    32 bytes
+//   -----
+//   Total: 96 bytes
+// -----------------------------------------------------------------------------
+
+// CFHeaderEntry houses the hash of the version 2 GCS filter of a block along
+// with the filter header that commits to it and all previous filters.
+type CFHeaderEntry struct {
+	FilterHash chainhash.Hash
+	Header     chainhash.Hash
+}
+
+// calcFilterHeader returns the filter header that commits to the provided
+// filter hash and previous filter header.
+func calcFilterHeader(filterHash, prevHeader *chainhash.Hash) chainhash.Hash {
+	var buf [2 * chainhash.HashSize]byte
+	copy(buf[:], filterHash[:])
+	copy(buf[chainhash.HashSize:], prevHeader[:])
+	return chainhash.HashH(buf[:])
+}
+
+// serializeCFHeaderEntry returns the serialization of the provided filter
+// header index entry.
+func serializeCFHeaderEntry(entry *CFHeaderEntry) []byte {
+	serialized := make([]byte, cfHeaderEntrySize)
+	copy(serialized, entry.FilterHash[:])
+	copy(serialized[chainhash.HashSize:], entry.Header[:])
+	return serialized
+}
+
+// deserializeCFHeaderEntry decodes the provided serialized filter header index
+// entry.
+func deserializeCFHeaderEntry(serialized []byte) (*CFHeaderEntry, error) {
+	if len(serialized) != cfHeaderEntrySize {
+		return nil, errDeserialize(fmt.Sprintf("unexpected filter header "+
+			"entry length %d", len(serialized)))
+	}
+
+	var entry CFHeaderEntry
+	copy(entry.FilterHash[:], serialized)
+	copy(entry.Header[:], serialized[chainhash.HashSize:])
+	return &entry, nil
+}
+
+// CFHeaderIndex implements a filter header index.  That is to say, it supports
+// querying the hash of the version 2 GCS filter of any block in the main chain
+// along with the filter header chain that commits to all of the filters.
+type CFHeaderIndex struct {
+	// The following fields are set when the instance is created and can't
+	// be changed afterwards, so there is no need to protect them with a
+	// separate mutex.
+	db           database.DB
+	chain        ChainQueryer
+	sub          *IndexSubscription
+	consumer     *SpendConsumer
+	chainParams  *chaincfg.Params
+	genesisEntry CFHeaderEntry
+
+	subscribers map[chan bool]struct{}
+	mtx         sync.Mutex
+	cancel      context.CancelFunc
+}
+
+// Ensure the CFHeaderIndex type implements the Indexer interface.
+var _ Indexer = (*CFHeaderIndex)(nil)
+
+// Init initializes the filter header index.
+//
+// This is part of the Indexer interface.
+func (idx *CFHeaderIndex) Init(ctx context.Context, chainParams *chaincfg.Params) error {
+	if interruptRequested(ctx) {
+		return indexerError(ErrInterruptRequested, interruptMsg)
+	}
+
+	// Finish any drops that were previously interrupted.
+	if err := finishDrop(ctx, idx); err != nil {
+		return err
+	}
+
+	// Create the initial state for the index as needed.
+	if err := createIndex(idx, &chainParams.GenesisHash); err != nil {
+		return err
+	}
+
+	// Upgrade the index as needed.
+	if err := upgradeIndex(ctx, idx, &chainParams.GenesisHash); err != nil {
+		return err
+	}
+
+	// Recover the filter header index and its dependents to the main chain if
+	// needed.
+	return recover(ctx, idx)
+}
+
+// Key returns the database key to use for the index as a byte slice.
+//
+// This is part of the Indexer interface.
+func (idx *CFHeaderIndex) Key() []byte {
+	return cfHeaderIndexKey
+}
+
+// Name returns the human-readable name of the index.
+//
+// This is part of the Indexer interface.
+func (idx *CFHeaderIndex) Name() string {
+	return cfHeaderIndexName
+}
+
+// Version returns the current version of the index.
+//
+// This is part of the Indexer interface.
+func (idx *CFHeaderIndex) Version() uint32 {
+	return cfHeaderIndexVersion
+}
+
+// DB returns the database of the index.
+//
+// This is part of the Indexer interface.
+func (idx *CFHeaderIndex) DB() database.DB {
+	return idx.db
+}
+
+// Queryer returns the chain queryer.
+//
+// This is part of the Indexer interface.
+func (idx *CFHeaderIndex) Queryer() ChainQueryer {
+	return idx.chain
+}
+
+// Tip returns the current tip of the index.
+//
+// This is part of the Indexer interface.
+func (idx *CFHeaderIndex) Tip() (int64, *chainhash.Hash, error) {
+	return tip(idx.db, idx.Key())
+}
+
+// Create is invoked when the index is created for the first time.  It creates
+// the bucket for the filter header index and adds the entry for the genesis
+// block.
+//
+// This is part of the Indexer interface.
+func (idx *CFHeaderIndex) Create(dbTx database.Tx) error {
+	bucket, err := dbTx.Metadata().CreateBucket(cfHeaderIndexKey)
+	if err != nil {
+		return err
+	}
+
+	genesisHash := &idx.chainParams.GenesisHash
+	return bucket.Put(genesisHash[:], serializeCFHeaderEntry(&idx.genesisEntry))
+}
+
+// IndexSubscription returns the subscription for index updates.
+//
+// This is part of the Indexer interface.
+func (idx *CFHeaderIndex) IndexSubscription() *IndexSubscription {
+	return idx.sub
+}
+
+// Subscribers returns all client channels waiting for the next index update.
+//
+// This is part of the Indexer interface.
+func (idx *CFHeaderIndex) Subscribers() map[chan bool]struct{} {
+	idx.mtx.Lock()
+	defer idx.mtx.Unlock()
+	return fetchIndexerSubscribers(idx.subscribers)
+}
+
+// WaitForSync subscribes clients for the next index sync update.
+//
+// This is part of the Indexer interface.
+func (idx *CFHeaderIndex) WaitForSync() chan bool {
+	c := make(chan bool)
+
+	removeIndexerSub := func() {
+		idx.mtx.Lock()
+		delete(idx.subscribers, c)
+		idx.mtx.Unlock()
+	}
+
+	go purgeIndexerSubscription(c, removeIndexerSub)
+
+	idx.mtx.Lock()
+	idx.subscribers[c] = struct{}{}
+	idx.mtx.Unlock()
+
+	return c
+}
+
+// dbFetchCFHeaderEntry uses an existing database transaction to fetch the
+// filter header index entry for the provided block.  Nil is returned when
+// there is no entry for the block.
+func dbFetchCFHeaderEntry(dbTx database.Tx, hash *chainhash.Hash) (*CFHeaderEntry, error) {
+	bucket := dbTx.Metadata().Bucket(cfHeaderIndexKey)
+	serialized := bucket.Get(hash[:])
+	if serialized == nil {
+		return nil, nil
+	}
+
+	entry, err := deserializeCFHeaderEntry(serialized)
+	if err != nil {
+		str := fmt.Sprintf("corrupt filter header entry for %v: %v", hash,
+			err)
+		return nil, makeDbErr(database.ErrCorruption, str)
+	}
+	return entry, nil
+}
+
+// connectBlock adds the entry for the passed block which commits to its filter
+// and the filter header of its parent.
+func (idx *CFHeaderIndex) connectBlock(dbTx database.Tx, block *dcrutil.Block, prevScripts PrevScripter) error {
+	prevHash := &block.MsgBlock().Header.PrevBlock
+	prevEntry, err := dbFetchCFHeaderEntry(dbTx, prevHash)
+	if err != nil {
+		return err
+	}
+	if prevEntry == nil {
+		str := fmt.Sprintf("missing filter header entry for %v", prevHash)
+		return makeDbErr(database.ErrCorruption, str)
+	}
+
+	filter, err := blockcf2.Regular(block.MsgBlock(), prevScripts)
+	if err != nil {
+		return err
+	}
+	entry := CFHeaderEntry{FilterHash: filter.Hash()}
+	entry.Header = calcFilterHeader(&entry.FilterHash, &prevEntry.Header)
+
+	bucket := dbTx.Metadata().Bucket(cfHeaderIndexKey)
+	err = bucket.Put(block.Hash()[:], serializeCFHeaderEntry(&entry))
+	if err != nil {
+		return err
+	}
+
+	// Update the current index tip.
+	return dbPutIndexerTip(dbTx, idx.Key(), block.Hash(), int32(block.Height()))
+}
+
+// disconnectBlock removes the entry for the passed block.
+func (idx *CFHeaderIndex) disconnectBlock(dbTx database.Tx, block *dcrutil.Block) error {
+	bucket := dbTx.Metadata().Bucket(cfHeaderIndexKey)
+	if err := bucket.Delete(block.Hash()[:]); err != nil {
+		return err
+	}
+
+	// Update the current index tip.
+	return dbPutIndexerTip(dbTx, idx.Key(), &block.MsgBlock().Header.PrevBlock,
+		int32(block.Height()-1))
+}
+
+// Entry returns the filter hash and filter header for the block with the
+// provided hash from the filter header index.  When there is no entry for the
+// block, which is the case for blocks that are not in the main chain or have
+// not been indexed yet, nil will be returned for both the entry and the error.
+//
+// This function is safe for concurrent access.
+func (idx *CFHeaderIndex) Entry(hash *chainhash.Hash) (*CFHeaderEntry, error) {
+	var entry *CFHeaderEntry
+	err := idx.db.View(func(dbTx database.Tx) error {
+		var err error
+		entry, err = dbFetchCFHeaderEntry(dbTx, hash)
+		return err
+	})
+	return entry, err
+}
+
+// NewCFHeaderIndex returns a new instance of an indexer that is used to create
+// a mapping of all blocks in the main chain to the hashes of their version 2
+// GCS filters along with the filter header chain that commits to them.
+func NewCFHeaderIndex(subscriber *IndexSubscriber, db database.DB, chain ChainQueryer) (*CFHeaderIndex, error) {
+	chainParams := chain.ChainParams()
+	genesisFilter, err := blockcf2.Regular(chainParams.GenesisBlock, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	idx := &CFHeaderIndex{
+		db:          db,
+		chain:       chain,
+		chainParams: chainParams,
+		subscribers: make(map[chan bool]struct{}),
+		cancel:      subscriber.cancel,
+	}
+	idx.genesisEntry.FilterHash = genesisFilter.Hash()
+	idx.genesisEntry.Header = calcFilterHeader(&idx.genesisEntry.FilterHash,
+		&chainhash.Hash{})
+
+	sc, err := chain.FetchSpendConsumer(idx.Name())
+	if err != nil {
+		return nil, err
+	}
+
+	consumer, ok := sc.(*SpendConsumer)
+	if !ok {
+		return nil, indexerError(ErrInvalidSpendConsumerType,
+			"consumer not of type SpendConsumer")
+	}
+
+	idx.consumer = consumer
+
+	// The filter header index is an optional index. It has no prerequisite
+	// and is updated asynchronously.
+	sub, err := subscriber.Subscribe(idx, noPrereqs)
+	if err != nil {
+		return nil, err
+	}
+
+	idx.sub = sub
+
+	err = idx.Init(subscriber.ctx, chainParams)
+	if err != nil {
+		return nil, err
+	}
+
+	return idx, nil
+}
+
+// DropCFHeaderIndex drops the filter header index from the provided database
+// if it exists.
+func DropCFHeaderIndex(ctx context.Context, db database.DB) error {
+	return dropFlatIndex(ctx, db, cfHeaderIndexKey, cfHeaderIndexName)
+}
+
+// DropIndex drops the filter header index from the provided database if it
+// exists.
+func (*CFHeaderIndex) DropIndex(ctx context.Context, db database.DB) error {
+	return DropCFHeaderIndex(ctx, db)
+}
+
+// spendConsumer returns the spend journal consumer of the filter header index.
+//
+// This is part of the spendConsumerIndexer interface.
+func (idx *CFHeaderIndex) spendConsumer() *SpendConsumer {
+	return idx.consumer
+}
+
+// ProcessNotification indexes the provided notification based on its
+// notification type.
+//
+// This is part of the Indexer interface.
+func (idx *CFHeaderIndex) ProcessNotification(dbTx database.Tx, ntfn *IndexNtfn) error {
+	switch ntfn.NtfnType {
+	case ConnectNtfn:
+		err := idx.connectBlock(dbTx, ntfn.Block, ntfn.PrevScripts)
+		if err != nil {
+			msg := fmt.Sprintf("%s: unable to connect block: %v",
+				idx.Name(), err)
+			return indexerError(ErrConnectBlock, msg)
+		}
+
+		idx.consumer.UpdateTip(ntfn.Block.Hash())
+
+	case DisconnectNtfn:
+		err := idx.disconnectBlock(dbTx, ntfn.Block)
+		if err != nil {
+			msg := fmt.Sprintf("%s: unable to disconnect block: %v",
+				idx.Name(), err)
+			return indexerError(ErrDisconnectBlock, msg)
+		}
+
+		idx.consumer.UpdateTip(ntfn.Parent.Hash())
+
+	default:
+		msg := fmt.Sprintf("%s: unknown notification type provided: %d",
+			idx.Name(), ntfn.NtfnType)
+		return indexerError(ErrInvalidNotificationType, msg)
+	}
+
+	return nil
+}
@@ -0,0 +1,206 @@
+// Copyright (c) 2023 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package indexers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/EXCCoin/exccd/blockchain/v4/chaingen"
+	"github.com/EXCCoin/exccd/chaincfg/chainhash"
+	"github.com/EXCCoin/exccd/chaincfg/v3"
+	"github.com/EXCCoin/exccd/dcrutil/v4"
+	"github.com/EXCCoin/exccd/gcs/v3/blockcf2"
+)
+
+// TestCFHeaderEntrySerialization ensures serializing and deserializing filter
+// header index entries works as expected.
+func TestCFHeaderEntrySerialization(t *testing.T) {
+	t.Parallel()
+
+	entry := CFHeaderEntry{
+		FilterHash: chainhash.Hash{0x01, 0x02},
+		Header:     chainhash.Hash{0x03, 0x04},
+	}
+	serialized := serializeCFHeaderEntry(&entry)
+	if len(serialized) != cfHeaderEntrySize {
+		t.Fatalf("unexpected serialized length - got %d, want %d",
+			len(serialized), cfHeaderEntrySize)
+	}
+	decoded, err := deserializeCFHeaderEntry(serialized)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if *decoded != entry {
+		t.Fatalf("mismatched entry - got %+v, want %+v", *decoded, entry)
+	}
+
+	// Ensure entries with an invalid length are rejected.
+	for _, size := range []int{0, cfHeaderEntrySize - 1, cfHeaderEntrySize + 1} {
+		_, err := deserializeCFHeaderEntry(make([]byte, size))
+		if !isDeserializeErr(err) {
+			t.Errorf("size %d: did not receive expected deserialize error "+
+				"- got %v", size, err)
+		}
+	}
+}
+
+// TestCalcFilterHeader ensures filter headers commit to both the filter hash
+// and the previous filter header.
+func TestCalcFilterHeader(t *testing.T) {
+	t.Parallel()
+
+	filterHash := chainhash.Hash{0x01}
+	prevHeader := chainhash.Hash{0x02}
+	header := calcFilterHeader(&filterHash, &prevHeader)
+
+	var concat [2 * chainhash.HashSize]byte
+	copy(concat[:], filterHash[:])
+	copy(concat[chainhash.HashSize:], prevHeader[:])
+	if want := chainhash.HashH(concat[:]); header != want {
+		t.Fatalf("unexpected filter header - got %v, want %v", header, want)
+	}
+
+	// Ensure swapping the inputs or changing either of them results in a
+	// different header.
+	others := []chainhash.Hash{
+		calcFilterHeader(&prevHeader, &filterHash),
+		calcFilterHeader(&chainhash.Hash{0x03}, &prevHeader),
+		calcFilterHeader(&filterHash, &chainhash.Hash{}),
+	}
+	for i, other := range others {
+		if other == header {
+			t.Fatalf("test %d: filter header does not commit to its inputs",
+				i)
+		}
+	}
+}
+
+// TestCFHeaderIndexConnectDisconnect ensures the filter header index maintains
+// a chain of filter headers that commit to the filters of the blocks as they
+// are connected and disconnected.
+func TestCFHeaderIndexConnectDisconnect(t *testing.T) {
+	db, path := setupDB(t, "test_cfheaderindex")
+	defer teardownDB(db, path)
+
+	chain, err := newTestChain()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	params := chaincfg.SimNetParams()
+	g, err := chaingen.MakeGenerator(params, nil)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	// Add two blocks to the chain and grab an output from the coinbase of the
+	// second one to spend.
+	bk1 := addBlock(t, chain, &g, "bk1")
+	bk2 := addBlock(t, chain, &g, "bk2")
+	spend := g.OldestCoinbaseOuts()[0]
+
+	// Initialize the filter header index.
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	subber := NewIndexSubscriber(ctx)
+	go subber.Run(ctx)
+
+	err = AddIndexSpendConsumers(db, chain)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	idx, err := NewCFHeaderIndex(subber, db, chain)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = subber.CatchUp(ctx, chain)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertIndexTip(t, idx, bk2)
+
+	// fetchEntry returns the entry for the provided block from the index.
+	fetchEntry := func(desc string, block *dcrutil.Block) *CFHeaderEntry {
+		t.Helper()
+
+		entry, err := idx.Entry(block.Hash())
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", desc, err)
+		}
+		return entry
+	}
+
+	// assertEntry ensures the entry for the provided block commits to its
+	// filter and the filter header of the provided previous entry and returns
+	// it.
+	assertEntry := func(desc string, block *dcrutil.Block, prevEntry *CFHeaderEntry) *CFHeaderEntry {
+		t.Helper()
+
+		prevScripts, err := chain.PrevScripts(block)
+		if err != nil {
+			t.Fatal(err)
+		}
+		filter, err := blockcf2.Regular(block.MsgBlock(), prevScripts)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", desc, err)
+		}
+		want := CFHeaderEntry{FilterHash: filter.Hash()}
+		want.Header = calcFilterHeader(&want.FilterHash, &prevEntry.Header)
+
+		entry := fetchEntry(desc, block)
+		if entry == nil || *entry != want {
+			t.Fatalf("%s: mismatched entry for %s -- got %+v, want %+v",
+				desc, block.Hash(), entry, want)
+		}
+		return entry
+	}
+
+	// assertNoEntry ensures there is no entry for the provided block.
+	assertNoEntry := func(desc string, block *dcrutil.Block) {
+		t.Helper()
+
+		if entry := fetchEntry(desc, block); entry != nil {
+			t.Fatalf("%s: unexpected entry for %s: %+v", desc,
+				block.Hash(), entry)
+		}
+	}
+
+	// Ensure the genesis block entry commits to a zero previous header and
+	// the blocks that were caught up extend the header chain from it.
+	genesis := dcrutil.NewBlock(params.GenesisBlock)
+	genesisEntry := assertEntry("genesis", genesis, &CFHeaderEntry{})
+	bk1Entry := assertEntry("after catchup", bk1, genesisEntry)
+	bk2Entry := assertEntry("after catchup", bk2, bk1Entry)
+
+	// Connect a block that spends an output and ensure its entry extends the
+	// header chain.
+	bk3 := addSpendBlock(t, chain, &g, "bk3", &spend)
+	notifyConnect(t, subber, chain, bk3, bk2)
+	assertIndexTip(t, idx, bk3)
+	bk3Entry := assertEntry("after spend", bk3, bk2Entry)
+
+	// Disconnect the block and ensure its entry is removed while the entry of
+	// its parent is unchanged.
+	notifyDisconnect(t, subber, chain, bk3, bk2)
+	assertIndexTip(t, idx, bk2)
+	assertNoEntry("after disconnect", bk3)
+	assertEntry("after disconnect", bk2, bk1Entry)
+
+	// Connect a side chain block in its place and ensure its entry extends
+	// the header chain from the same parent with a different header.
+	g.SetTip("bk2")
+	bk3a := addSpendBlock(t, chain, &g, "bk3a", &spend)
+	notifyConnect(t, subber, chain, bk3a, bk2)
+	assertIndexTip(t, idx, bk3a)
+	bk3aEntry := assertEntry("after reorg", bk3a, bk2Entry)
+	assertNoEntry("after reorg", bk3)
+	if bk3aEntry.Header == bk3Entry.Header {
+		t.Fatal("after reorg: side chain block has the same filter header")
+	}
+}
@@ -0,0 +1,742 @@
+// Copyright (c) 2023 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package indexers
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/EXCCoin/exccd/blockchain/standalone/v2"
+	"github.com/EXCCoin/exccd/chaincfg/chainhash"
+	"github.com/EXCCoin/exccd/chaincfg/v3"
+	"github.com/EXCCoin/exccd/database/v3"
+	"github.com/EXCCoin/exccd/dcrec/secp256k1/v4"
+	"github.com/EXCCoin/exccd/dcrutil/v4"
+	"github.com/EXCCoin/exccd/txscript/v4"
+	"github.com/EXCCoin/exccd/wire"
+)
+
+const (
+	// coinStatsIndexName is the human-readable name for the index.
+	coinStatsIndexName = "coin statistics index"
+
+	// coinStatsIndexVersion is the current version of the coin statistics
+	// index.
+	coinStatsIndexVersion = 1
+
+	// serializedSetPointSize is the size of a serialized set hash point.  It
+	// is the size of a compressed secp256k1 point.
+	serializedSetPointSize = secp256k1.PubKeyBytesLenCompressed
+
+	// coinStatsDeltaSize is the size of a serialized coin statistics delta.
+	// It consists of the 8 byte number of outputs, the 8 byte amount, the 8
+	// byte size, and the serialized set hash point.
+	coinStatsDeltaSize = 8 + 8 + 8 + serializedSetPointSize
+
+	// coinStatsEntrySize is the size of a coin statistics entry.  It consists
+	// of the delta for the entire unspent output set followed by the delta
+	// made by the regular tree of the block.
+	coinStatsEntrySize = 2 * coinStatsDeltaSize
+
+	// coinLeafFixedSize is the size of the fixed portion of a serialized
+	// unspent output as committed to by the set hash.  It consists of the 32
+	// byte transaction hash, the 4 byte output index, the 1 byte tree, the 8
+	// byte amount, and the 2 byte script version.
+	coinLeafFixedSize = chainhash.HashSize + 4 + 1 + 8 + 2
+)
+
+var (
+	// coinStatsIndexKey is the key of the coin statistics index and the db
+	// bucket used to house it.
+	coinStatsIndexKey = []byte("coinstatsidx")
+)
+
+// -----------------------------------------------------------------------------
+// The coin statistics index consists of an entry for every block in the main
+// chain which maps the hash of the block to statistics about the unspent
+// transaction output set as of that block.
+//
+// The set is committed to by a rolling set hash, which is an elliptic curve
+// multiset hash.  Every unspent output is mapped to a point on the secp256k1
+// curve and the set hash is the sum of the points of all outputs in the set.
+// This allows the hash to be updated incrementally as outputs are created and
+// spent, independently of the order they are added and removed, and results in
+// the same hash for the same set regardless of the history that produced it.
+//
+// The outputs are serialized for the set hash as follows:
+//
+//   <tx hash><output index><tree><amount><script version><script>
+//
+//   Field              Type              Size
+//   tx hash            chainhash.Hash    32 bytes
+//   output index       uint32            4 bytes
+//   tree               int8              1 byte
+//   amount             uint64            8 bytes
+//   script version     uint16            2 bytes
+//   script             []byte            variable
+//
+// The regular tree of a block is disconnected from the unspent output set when
+// the next block disapproves it.  Since the outputs its transactions spent are
+// not available at that point, every entry also houses the statistics delta
+// made by the regular tree of the block so it can be reversed.
+//
+// Disconnecting a block simply removes its entry since the entry of its parent
+// houses the statistics of the set prior to the block.  The genesis block is
+// never connected to the set, so it does not have an entry.
+//
+// The serialized format for the keys and values in the coin statistics index
+// bucket is:
+//
+//   <block hash> = <set delta><regular tree delta>
+//
+//   Field              Type              Size
+//   block hash         chainhash.Hash    32 bytes
+//   set delta          coinStatsDelta    57 bytes
+//   regular delta      coinStatsDelta    57 bytes
+//   -----
+//   Total: 146 bytes
+//
+// The serialized format for a delta is:
+//
+//   <num outputs><amount><size><set point>
+//
+//   Field              Type              Size
+//   num outputs        int64             8 bytes
+//   amount             int64             8 bytes
+//   size               int64             8 bytes
+//   set point          [33]byte          33 bytes
+//
+// The set point is a compressed secp256k1 point where the point at infinity,
+// which is the hash of the empty set, is represented by all zeros.
+// -----------------------------------------------------------------------------
+
+// CoinStats houses statistics about the unspent transaction output set as of a
+// block in the main chain.
+type CoinStats struct {
+	// Utxos is the number of unspent transaction outputs.
+	Utxos int64
+
+	// TotalAmount is the total value of all unspent transaction outputs.
+	TotalAmount int64
+
+	// Size is the total size of the unspent transaction outputs serialized as
+	// committed to by the set hash.  It does not depend on how the outputs
+	// are stored in the database.
+	Size int64
+
+	// SetHash is the hash of the rolling set hash of all unspent transaction
+	// outputs.
+	SetHash chainhash.Hash
+}
+
+// coinStatsDelta houses changes to the statistics of the unspent transaction
+// output set.  A delta from the empty set represents the statistics of the
+// entire set.
+type coinStatsDelta struct {
+	utxos  int64
+	amount int64
+	size   int64
+	point  secp256k1.JacobianPoint
+}
+
+// hashToSetPoint maps the provided serialized unspent output to a point on the
+// secp256k1 curve with an even y coordinate by hashing it along with an
+// incrementing counter until the result is a valid x coordinate.
+func hashToSetPoint(serialized []byte, result *secp256k1.JacobianPoint) {
+	var buf [chainhash.HashSize + 4]byte
+	leafHash := chainhash.HashH(serialized)
+	copy(buf[:], leafHash[:])
+	for counter := uint32(0); ; counter++ {
+		byteOrder.PutUint32(buf[chainhash.HashSize:], counter)
+		x := chainhash.HashH(buf[:])
+
+		var fx, fy secp256k1.FieldVal
+		if overflow := fx.SetByteSlice(x[:]); overflow {
+			continue
+		}
+		if !secp256k1.DecompressY(&fx, false, &fy) {
+			continue
+		}
+		result.X.Set(&fx)
+		result.Y.Set(fy.Normalize())
+		result.Z.SetInt(1)
+		return
+	}
+}
+
+// serializeCoinLeaf returns the serialization of the provided output as
+// committed to by the set hash.
+func serializeCoinLeaf(outpoint *wire.OutPoint, amount int64, scriptVersion uint16, pkScript []byte) []byte {
+	serialized := make([]byte, coinLeafFixedSize+len(pkScript))
+	offset := copy(serialized, outpoint.Hash[:])
+	byteOrder.PutUint32(serialized[offset:], outpoint.Index)
+	offset += 4
+	serialized[offset] = byte(outpoint.Tree)
+	offset++
+	byteOrder.PutUint64(serialized[offset:], uint64(amount))
+	offset += 8
+	byteOrder.PutUint16(serialized[offset:], scriptVersion)
+	offset += 2
+	copy(serialized[offset:], pkScript)
+	return serialized
+}
+
+// addPoint adds the provided point to the set point of the delta.
+func (d *coinStatsDelta) addPoint(point *secp256k1.JacobianPoint) {
+	var result secp256k1.JacobianPoint
+	secp256k1.AddNonConst(&d.point, point, &result)
+	d.point.Set(&result)
+}
+
+// addOutput updates the delta to include the creation of the provided output.
+func (d *coinStatsDelta) addOutput(outpoint *wire.OutPoint, amount int64, scriptVersion uint16, pkScript []byte) {
+	serialized := serializeCoinLeaf(outpoint, amount, scriptVersion, pkScript)
+	var point secp256k1.JacobianPoint
+	hashToSetPoint(serialized, &point)
+	d.addPoint(&point)
+	d.utxos++
+	d.amount += amount
+	d.size += int64(len(serialized))
+}
+
+// spendOutput updates the delta to include the spending of the provided
+// output.
+func (d *coinStatsDelta) spendOutput(outpoint *wire.OutPoint, amount int64, scriptVersion uint16, pkScript []byte) {
+	serialized := serializeCoinLeaf(outpoint, amount, scriptVersion, pkScript)
+	var point secp256k1.JacobianPoint
+	hashToSetPoint(serialized, &point)
+	point.Y.Negate(1).Normalize()
+	d.addPoint(&point)
+	d.utxos--
+	d.amount -= amount
+	d.size -= int64(len(serialized))
+}
+
+// apply updates the delta to include the changes in the provided delta.  The
+// changes are reversed instead when the reverse flag is set.
+func (d *coinStatsDelta) apply(other *coinStatsDelta, reverse bool) {
+	var point secp256k1.JacobianPoint
+	point.Set(&other.point)
+	sign := int64(1)
+	if reverse {
+		point.Y.Negate(1).Normalize()
+		sign = -1
+	}
+	d.addPoint(&point)
+	d.utxos += sign * other.utxos
+	d.amount += sign * other.amount
+	d.size += sign * other.size
+}
+
+// putSetPoint serializes the provided set point into the passed target byte
+// slice, which must be at least serializedSetPointSize bytes.
+func putSetPoint(target []byte, point *secp256k1.JacobianPoint) {
+	if (point.X.IsZero() && point.Y.IsZero()) || point.Z.IsZero() {
+		copy(target, make([]byte, serializedSetPointSize))
+		return
+	}
+
+	var affine secp256k1.JacobianPoint
+	affine.Set(point)
+	affine.ToAffine()
+	target[0] = secp256k1.PubKeyFormatCompressedEven
+	if affine.Y.IsOdd() {
+		target[0] = secp256k1.PubKeyFormatCompressedOdd
+	}
+	affine.X.PutBytesUnchecked(target[1:])
+}
+
+// readSetPoint decodes the provided serialized set point into the passed
+// point.
+func readSetPoint(serialized []byte, point *secp256k1.JacobianPoint) error {
+	var fx, fy secp256k1.FieldVal
+	switch serialized[0] {
+	case 0:
+		for _, b := range serialized[1:serializedSetPointSize] {
+			if b != 0 {
+				return errDeserialize("invalid set point at infinity")
+			}
+		}
+		point.X.SetInt(0)
+		point.Y.SetInt(0)
+		point.Z.SetInt(0)
+		return nil
+
+	case secp256k1.PubKeyFormatCompressedEven,
+		secp256k1.PubKeyFormatCompressedOdd:
+
+		if overflow := fx.SetByteSlice(serialized[1:serializedSetPointSize]); overflow {
+			return errDeserialize("set point x coordinate is not in the " +
+				"field")
+		}
+		odd := serialized[0] == secp256k1.PubKeyFormatCompressedOdd
+		if !secp256k1.DecompressY(&fx, odd, &fy) {
+			return errDeserialize("set point is not on the curve")
+		}
+	default:
+		return errDeserialize(fmt.Sprintf("invalid set point format %d",
+			serialized[0]))
+	}
+
+	point.X.Set(&fx)
+	point.Y.Set(fy.Normalize())
+	point.Z.SetInt(1)
+	return nil
+}
+
+// putCoinStatsDelta serializes the provided delta into the passed target byte
+// slice, which must be at least coinStatsDeltaSize bytes.
+func putCoinStatsDelta(target []byte, delta *coinStatsDelta) {
+	byteOrder.PutUint64(target, uint64(delta.utxos))
+	byteOrder.PutUint64(target[8:], uint64(delta.amount))
+	byteOrder.PutUint64(target[16:], uint64(delta.size))
+	putSetPoint(target[24:], &delta.point)
+}
+
+// readCoinStatsDelta decodes the provided serialized delta into the passed
+// delta.
+func readCoinStatsDelta(serialized []byte, delta *coinStatsDelta) error {
+	delta.utxos = int64(byteOrder.Uint64(serialized))
+	delta.amount = int64(byteOrder.Uint64(serialized[8:]))
+	delta.size = int64(byteOrder.Uint64(serialized[16:]))
+	return readSetPoint(serialized[24:coinStatsDeltaSize], &delta.point)
+}
+
+// serializeCoinStatsEntry returns the serialization of the provided delta for
+// the entire unspent output set and the delta made by the regular tree of the
+// block.
+func serializeCoinStatsEntry(set, regular *coinStatsDelta) []byte {
+	serialized := make([]byte, coinStatsEntrySize)
+	putCoinStatsDelta(serialized, set)
+	putCoinStatsDelta(serialized[coinStatsDeltaSize:], regular)
+	return serialized
+}
+
+// deserializeCoinStatsEntry decodes the provided serialized coin statistics
+// entry into the delta for the entire unspent output set and the delta made by
+// the regular tree of the block.
+func deserializeCoinStatsEntry(serialized []byte) (*coinStatsDelta, *coinStatsDelta, error) {
+	if len(serialized) != coinStatsEntrySize {
+		return nil, nil, errDeserialize(fmt.Sprintf("unexpected coin "+
+			"statistics entry length %d", len(serialized)))
+	}
+
+	var set, regular coinStatsDelta
+	if err := readCoinStatsDelta(serialized, &set); err != nil {
+		return nil, nil, err
+	}
+	err := readCoinStatsDelta(serialized[coinStatsDeltaSize:], &regular)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &set, &regular, nil
+}
+
+// coinStatsFromDelta returns the coin statistics described by the provided
+// delta for the entire unspent output set.
+func coinStatsFromDelta(set *coinStatsDelta) *CoinStats {
+	var serialized [serializedSetPointSize]byte
+	putSetPoint(serialized[:], &set.point)
+	return &CoinStats{
+		Utxos:       set.utxos,
+		TotalAmount: set.amount,
+		Size:        set.size,
+		SetHash:     chainhash.HashH(serialized[:]),
+	}
+}
+
+// calcCoinStatsDeltas calculates the changes made to the unspent output set by
+// the stake and regular trees of the provided block.  The values and scripts
+// of the outputs spent by the block are obtained from the provided source of
+// previous outputs, falling back to the input values committed to by the
+// transactions, which were proven correct when the block was validated, when
+// it does not provide them.
+func calcCoinStatsDeltas(block *dcrutil.Block, prevScripts PrevScripter, isTreasuryEnabled bool) (*coinStatsDelta, *coinStatsDelta) {
+	prevAmounts, _ := prevScripts.(PrevAmounter)
+	processTx := func(delta *coinStatsDelta, tx *wire.MsgTx, tree int8) {
+		for _, txIn := range tx.TxIn {
+			// Ignore the coinbase, stakebase, and treasury spend inputs
+			// since they do not spend a previous output.
+			prevOut := &txIn.PreviousOutPoint
+			if isNullOutPoint(prevOut) {
+				continue
+			}
+
+			amount := txIn.ValueIn
+			if prevAmounts != nil {
+				if prevAmount, ok := prevAmounts.PrevAmount(prevOut); ok {
+					amount = prevAmount
+				}
+			}
+			version, pkScript, _ := prevScripts.PrevScript(prevOut)
+			delta.spendOutput(prevOut, amount, version, pkScript)
+		}
+
+		// Add all of the outputs that are not provably unspendable since they
+		// are not added to the unspent output set.
+		outpoint := wire.OutPoint{Hash: tx.TxHash(), Tree: tree}
+		for txOutIdx, txOut := range tx.TxOut {
+			if txscript.IsUnspendable(txOut.Value, txOut.PkScript) {
+				continue
+			}
+			outpoint.Index = uint32(txOutIdx)
+			delta.addOutput(&outpoint, txOut.Value, txOut.Version,
+				txOut.PkScript)
+		}
+	}
+
+	var stakeDelta, regularDelta coinStatsDelta
+	msgBlock := block.MsgBlock()
+	for i, tx := range msgBlock.STransactions {
+		// Treasurybase transactions don't have any inputs to spend or outputs
+		// to add.
+		if isTreasuryEnabled && i == 0 && standalone.IsTreasuryBase(tx) {
+			continue
+		}
+		processTx(&stakeDelta, tx, wire.TxTreeStake)
+	}
+	for _, tx := range msgBlock.Transactions {
+		processTx(&regularDelta, tx, wire.TxTreeRegular)
+	}
+	return &stakeDelta, &regularDelta
+}
+
+// CoinStatsIndex implements a coin statistics index.  That is to say, it
+// supports querying statistics about the unspent transaction output set along
+// with a commitment to it as of any block in the main chain.
+type CoinStatsIndex struct {
+	// The following fields are set when the instance is created and can't
+	// be changed afterwards, so there is no need to protect them with a
+	// separate mutex.
+	db          database.DB
+	chain       ChainQueryer
+	sub         *IndexSubscription
+	consumer    *SpendConsumer
+	chainParams *chaincfg.Params
+
+	subscribers map[chan bool]struct{}
+	mtx         sync.Mutex
+	cancel      context.CancelFunc
+}
+
+// Ensure the CoinStatsIndex type implements the Indexer interface.
+var _ Indexer = (*CoinStatsIndex)(nil)
+
+// Init initializes the coin statistics index.
+//
+// This is part of the Indexer interface.
+func (idx *CoinStatsIndex) Init(ctx context.Context, chainParams *chaincfg.Params) error {
+	if interruptRequested(ctx) {
+		return indexerError(ErrInterruptRequested, interruptMsg)
+	}
+
+	// Finish any drops that were previously interrupted.
+	if err := finishDrop(ctx, idx); err != nil {
+		return err
+	}
+
+	// Create the initial state for the index as needed.
+	if err := createIndex(idx, &chainParams.GenesisHash); err != nil {
+		return err
+	}
+
+	// Upgrade the index as needed.
+	if err := upgradeIndex(ctx, idx, &chainParams.GenesisHash); err != nil {
+		return err
+	}
+
+	// Recover the coin statistics index and its dependents to the main chain
+	// if needed.
+	return recover(ctx, idx)
+}
+
+// Key returns the database key to use for the index as a byte slice.
+//
+// This is part of the Indexer interface.
+func (idx *CoinStatsIndex) Key() []byte {
+	return coinStatsIndexKey
+}
+
+// Name returns the human-readable name of the index.
+//
+// This is part of the Indexer interface.
+func (idx *CoinStatsIndex) Name() string {
+	return coinStatsIndexName
+}
+
+// Version returns the current version of the index.
+//
+// This is part of the Indexer interface.
+func (idx *CoinStatsIndex) Version() uint32 {
+	return coinStatsIndexVersion
+}
+
+// DB returns the database of the index.
+//
+// This is part of the Indexer interface.
+func (idx *CoinStatsIndex) DB() database.DB {
+	return idx.db
+}
+
+// Queryer returns the chain queryer.
+//
+// This is part of the Indexer interface.
+func (idx *CoinStatsIndex) Queryer() ChainQueryer {
+	return idx.chain
+}
+
+// Tip returns the current tip of the index.
+//
+// This is part of the Indexer interface.
+func (idx *CoinStatsIndex) Tip() (int64, *chainhash.Hash, error) {
+	return tip(idx.db, idx.Key())
+}
+
+// Create is invoked when the index is created for the first time.  It creates
+// the bucket for the coin statistics index.
+//
+// This is part of the Indexer interface.
+func (idx *CoinStatsIndex) Create(dbTx database.Tx) error {
+	_, err := dbTx.Metadata().CreateBucket(coinStatsIndexKey)
+	return err
+}
+
+// IndexSubscription returns the subscription for index updates.
+//
+// This is part of the Indexer interface.
+func (idx *CoinStatsIndex) IndexSubscription() *IndexSubscription {
+	return idx.sub
+}
+
+// Subscribers returns all client channels waiting for the next index update.
+//
+// This is part of the Indexer interface.
+func (idx *CoinStatsIndex) Subscribers() map[chan bool]struct{} {
+	idx.mtx.Lock()
+	defer idx.mtx.Unlock()
+	return fetchIndexerSubscribers(idx.subscribers)
+}
+
+// WaitForSync subscribes clients for the next index sync update.
+//
+// This is part of the Indexer interface.
+func (idx *CoinStatsIndex) WaitForSync() chan bool {
+	c := make(chan bool)
+
+	removeIndexerSub := func() {
+		idx.mtx.Lock()
+		delete(idx.subscribers, c)
+		idx.mtx.Unlock()
+	}
+
+	go purgeIndexerSubscription(c, removeIndexerSub)
+
+	idx.mtx.Lock()
+	idx.subscribers[c] = struct{}{}
+	idx.mtx.Unlock()
+
+	return c
+}
+
+// dbFetchCoinStatsEntry uses an existing database transaction to fetch the
+// deltas stored for the provided block.  The genesis block is never connected
+// to the unspent output set, so the deltas for the empty set are returned for
+// it.  An error is returned when there is no entry for any other block.
+func (idx *CoinStatsIndex) dbFetchCoinStatsEntry(dbTx database.Tx, hash *chainhash.Hash) (*coinStatsDelta, *coinStatsDelta, error) {
+	if *hash == idx.chainParams.GenesisHash {
+		return new(coinStatsDelta), new(coinStatsDelta), nil
+	}
+
+	bucket := dbTx.Metadata().Bucket(coinStatsIndexKey)
+	serialized := bucket.Get(hash[:])
+	if serialized == nil {
+		str := fmt.Sprintf("missing coin statistics entry for %v", hash)
+		return nil, nil, makeDbErr(database.ErrCorruption, str)
+	}
+	set, regular, err := deserializeCoinStatsEntry(serialized)
+	if err != nil {
+		str := fmt.Sprintf("corrupt coin statistics entry for %v: %v", hash,
+			err)
+		return nil, nil, makeDbErr(database.ErrCorruption, str)
+	}
+	return set, regular, nil
+}
+
+// connectBlock adds the entry for the passed block which houses the statistics
+// of the unspent output set after applying the changes made by the block.
+func (idx *CoinStatsIndex) connectBlock(dbTx database.Tx, block, parent *dcrutil.Block, prevScripts PrevScripter, isTreasuryEnabled bool) error {
+	header := &block.MsgBlock().Header
+	set, parentRegular, err := idx.dbFetchCoinStatsEntry(dbTx,
+		&header.PrevBlock)
+	if err != nil {
+		return err
+	}
+
+	// Reverse the changes made by the regular tree of the parent block when
+	// the block disapproves it since the outputs it created are no longer
+	// spendable and the outputs it spent are spendable again.
+	if !dcrutil.IsFlagSet16(header.VoteBits, dcrutil.BlockValid) &&
+		parent.Height() > 0 {
+
+		set.apply(parentRegular, true)
+	}
+
+	// Apply the changes made by the stake tree before the regular tree to
+	// match the order the transactions are connected to the utxo set.
+	stakeDelta, regularDelta := calcCoinStatsDeltas(block, prevScripts,
+		isTreasuryEnabled)
+	set.apply(stakeDelta, false)
+	set.apply(regularDelta, false)
+
+	bucket := dbTx.Metadata().Bucket(coinStatsIndexKey)
+	err = bucket.Put(block.Hash()[:], serializeCoinStatsEntry(set,
+		regularDelta))
+	if err != nil {
+		return err
+	}
+
+	// Update the current index tip.
+	return dbPutIndexerTip(dbTx, idx.Key(), block.Hash(), int32(block.Height()))
+}
+
+// disconnectBlock removes the entry for the passed block.
+func (idx *CoinStatsIndex) disconnectBlock(dbTx database.Tx, block *dcrutil.Block) error {
+	bucket := dbTx.Metadata().Bucket(coinStatsIndexKey)
+	if err := bucket.Delete(block.Hash()[:]); err != nil {
+		return err
+	}
+
+	// Update the current index tip.
+	return dbPutIndexerTip(dbTx, idx.Key(), &block.MsgBlock().Header.PrevBlock,
+		int32(block.Height()-1))
+}
+
+// Stats returns the statistics of the unspent transaction output set as of the
+// block with the provided hash from the coin statistics index.  When there is
+// no entry for the block, which is the case for blocks that are not in the
+// main chain or have not been indexed yet, nil will be returned for both the
+// statistics and the error.
+//
+// This function is safe for concurrent access.
+func (idx *CoinStatsIndex) Stats(hash *chainhash.Hash) (*CoinStats, error) {
+	if *hash == idx.chainParams.GenesisHash {
+		return coinStatsFromDelta(new(coinStatsDelta)), nil
+	}
+
+	var stats *CoinStats
+	err := idx.db.View(func(dbTx database.Tx) error {
+		bucket := dbTx.Metadata().Bucket(coinStatsIndexKey)
+		if bucket.Get(hash[:]) == nil {
+			return nil
+		}
+
+		set, _, err := idx.dbFetchCoinStatsEntry(dbTx, hash)
+		if err != nil {
+			return err
+		}
+		stats = coinStatsFromDelta(set)
+		return nil
+	})
+	return stats, err
+}
+
+// NewCoinStatsIndex returns a new instance of an indexer that is used to
+// create a mapping of all blocks in the main chain to statistics about the
+// unspent transaction output set as of those blocks.
+func NewCoinStatsIndex(subscriber *IndexSubscriber, db database.DB, chain ChainQueryer) (*CoinStatsIndex, error) {
+	idx := &CoinStatsIndex{
+		db:          db,
+		chain:       chain,
+		chainParams: chain.ChainParams(),
+		subscribers: make(map[chan bool]struct{}),
+		cancel:      subscriber.cancel,
+	}
+
+	sc, err := chain.FetchSpendConsumer(idx.Name())
+	if err != nil {
+		return nil, err
+	}
+
+	consumer, ok := sc.(*SpendConsumer)
+	if !ok {
+		return nil, indexerError(ErrInvalidSpendConsumerType,
+			"consumer not of type SpendConsumer")
+	}
+
+	idx.consumer = consumer
+
+	// The coin statistics index is an optional index. It has no prerequisite
+	// and is updated asynchronously.
+	sub, err := subscriber.Subscribe(idx, noPrereqs)
+	if err != nil {
+		return nil, err
+	}
+
+	idx.sub = sub
+
+	err = idx.Init(subscriber.ctx, chain.ChainParams())
+	if err != nil {
+		return nil, err
+	}
+
+	return idx, nil
+}
+
+// DropCoinStatsIndex drops the coin statistics index from the provided
+// database if it exists.
+func DropCoinStatsIndex(ctx context.Context, db database.DB) error {
+	return dropFlatIndex(ctx, db, coinStatsIndexKey, coinStatsIndexName)
+}
+
+// DropIndex drops the coin statistics index from the provided database if it
+// exists.
+func (*CoinStatsIndex) DropIndex(ctx context.Context, db database.DB) error {
+	return DropCoinStatsIndex(ctx, db)
+}
+
+// spendConsumer returns the spend journal consumer of the coin statistics
+// index.
+//
+// This is part of the spendConsumerIndexer interface.
+func (idx *CoinStatsIndex) spendConsumer() *SpendConsumer {
+	return idx.consumer
+}
+
+// ProcessNotification indexes the provided notification based on its
+// notification type.
+//
+// This is part of the Indexer interface.
+func (idx *CoinStatsIndex) ProcessNotification(dbTx database.Tx, ntfn *IndexNtfn) error {
+	switch ntfn.NtfnType {
+	case ConnectNtfn:
+		err := idx.connectBlock(dbTx, ntfn.Block, ntfn.Parent,
+			ntfn.PrevScripts, ntfn.IsTreasuryEnabled)
+		if err != nil {
+			msg := fmt.Sprintf("%s: unable to connect block: %v",
+				idx.Name(), err)
+			return indexerError(ErrConnectBlock, msg)
+		}
+
+		idx.consumer.UpdateTip(ntfn.Block.Hash())
+
+	case DisconnectNtfn:
+		err := idx.disconnectBlock(dbTx, ntfn.Block)
+		if err != nil {
+			msg := fmt.Sprintf("%s: unable to disconnect block: %v",
+				idx.Name(), err)
+			return indexerError(ErrDisconnectBlock, msg)
+		}
+
+		idx.consumer.UpdateTip(ntfn.Parent.Hash())
+
+	default:
+		msg := fmt.Sprintf("%s: unknown notification type provided: %d",
+			idx.Name(), ntfn.NtfnType)
+		return indexerError(ErrInvalidNotificationType, msg)
+	}
+
+	return nil
+}
@@ -0,0 +1,312 @@
+// Copyright (c) 2023 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package indexers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/EXCCoin/exccd/blockchain/v4/chaingen"
+	"github.com/EXCCoin/exccd/chaincfg/chainhash"
+	"github.com/EXCCoin/exccd/chaincfg/v3"
+	"github.com/EXCCoin/exccd/dcrutil/v4"
+	"github.com/EXCCoin/exccd/txscript/v4"
+	"github.com/EXCCoin/exccd/wire"
+)
+
+// TestCoinStatsSetHash ensures the rolling set hash of the coin statistics
+// index only depends on the outputs in the set and not the order or history of
+// the changes that produced it.
+func TestCoinStatsSetHash(t *testing.T) {
+	t.Parallel()
+
+	outA := wire.OutPoint{Hash: chainhash.Hash{0x01}, Index: 0}
+	outB := wire.OutPoint{Hash: chainhash.Hash{0x02}, Index: 1}
+	outC := wire.OutPoint{Hash: chainhash.Hash{0x03}, Tree: wire.TxTreeStake}
+	scriptA := []byte{0x76, 0xa9, 0x14}
+	scriptB := []byte{0x51}
+	scriptC := []byte{0xba, 0x76}
+
+	// Create the same set in different orders, with one of them including an
+	// output that is later spent.
+	var forward, backward, spent coinStatsDelta
+	forward.addOutput(&outA, 100, 0, scriptA)
+	forward.addOutput(&outB, 200, 0, scriptB)
+	backward.addOutput(&outB, 200, 0, scriptB)
+	backward.addOutput(&outA, 100, 0, scriptA)
+	spent.addOutput(&outC, 300, 0, scriptC)
+	spent.addOutput(&outA, 100, 0, scriptA)
+	spent.addOutput(&outB, 200, 0, scriptB)
+	spent.spendOutput(&outC, 300, 0, scriptC)
+
+	want := coinStatsFromDelta(&forward)
+	for _, delta := range []*coinStatsDelta{&backward, &spent} {
+		got := coinStatsFromDelta(delta)
+		if *got != *want {
+			t.Fatalf("mismatched stats - got %+v, want %+v", *got, *want)
+		}
+	}
+	if want.Utxos != 2 || want.TotalAmount != 300 {
+		t.Fatalf("unexpected stats %+v", *want)
+	}
+	wantSize := int64(2*coinLeafFixedSize + len(scriptA) + len(scriptB))
+	if want.Size != wantSize {
+		t.Fatalf("unexpected size - got %d, want %d", want.Size, wantSize)
+	}
+
+	// Ensure a different amount for the same output results in a different
+	// set hash.
+	var other coinStatsDelta
+	other.addOutput(&outA, 101, 0, scriptA)
+	other.addOutput(&outB, 200, 0, scriptB)
+	if coinStatsFromDelta(&other).SetHash == want.SetHash {
+		t.Fatal("set hash does not commit to the output amounts")
+	}
+
+	// Ensure reversing the changes of a delta results in the hash of the
+	// empty set.
+	empty := coinStatsFromDelta(new(coinStatsDelta))
+	forward.apply(&backward, true)
+	if got := coinStatsFromDelta(&forward); *got != *empty {
+		t.Fatalf("mismatched stats - got %+v, want %+v", *got, *empty)
+	}
+}
+
+// TestCoinStatsEntrySerialization ensures serializing and deserializing coin
+// statistics entries works as expected.
+func TestCoinStatsEntrySerialization(t *testing.T) {
+	t.Parallel()
+
+	var set, regular coinStatsDelta
+	for i := uint32(0); i < 10; i++ {
+		outpoint := wire.OutPoint{Hash: chainhash.Hash{byte(i)}, Index: i}
+		set.addOutput(&outpoint, int64(i)*100, 0, []byte{byte(i)})
+	}
+	spentOut := wire.OutPoint{Hash: chainhash.Hash{0x05}, Index: 5}
+	regular.spendOutput(&spentOut, 500, 0, []byte{0x05})
+
+	tests := []struct {
+		name    string
+		set     *coinStatsDelta
+		regular *coinStatsDelta
+	}{{
+		name:    "empty set",
+		set:     new(coinStatsDelta),
+		regular: new(coinStatsDelta),
+	}, {
+		name:    "populated set with regular tree spend",
+		set:     &set,
+		regular: &regular,
+	}}
+
+	for _, test := range tests {
+		serialized := serializeCoinStatsEntry(test.set, test.regular)
+		if len(serialized) != coinStatsEntrySize {
+			t.Fatalf("%s: unexpected serialized length - got %d, want %d",
+				test.name, len(serialized), coinStatsEntrySize)
+		}
+		gotSet, gotRegular, err := deserializeCoinStatsEntry(serialized)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", test.name, err)
+		}
+		if *coinStatsFromDelta(gotSet) != *coinStatsFromDelta(test.set) {
+			t.Fatalf("%s: mismatched set stats - got %+v, want %+v",
+				test.name, *coinStatsFromDelta(gotSet),
+				*coinStatsFromDelta(test.set))
+		}
+		if *coinStatsFromDelta(gotRegular) != *coinStatsFromDelta(test.regular) {
+			t.Fatalf("%s: mismatched regular stats - got %+v, want %+v",
+				test.name, *coinStatsFromDelta(gotRegular),
+				*coinStatsFromDelta(test.regular))
+		}
+	}
+
+	// Ensure entries with an invalid length or set point are rejected.
+	for _, size := range []int{0, coinStatsEntrySize - 1, coinStatsEntrySize + 1} {
+		_, _, err := deserializeCoinStatsEntry(make([]byte, size))
+		if !isDeserializeErr(err) {
+			t.Errorf("size %d: did not receive expected deserialize error "+
+				"- got %v", size, err)
+		}
+	}
+	serialized := serializeCoinStatsEntry(new(coinStatsDelta),
+		new(coinStatsDelta))
+	serialized[24] = 0x04
+	_, _, err := deserializeCoinStatsEntry(serialized)
+	if !isDeserializeErr(err) {
+		t.Errorf("did not receive expected deserialize error for invalid set "+
+			"point - got %v", err)
+	}
+}
+
+// TestCoinStatsIndexConnectDisconnect ensures the coin statistics index tracks
+// the unspent transaction output set as blocks are connected and disconnected,
+// including when the regular transaction tree of a block is disapproved by the
+// next block.
+func TestCoinStatsIndexConnectDisconnect(t *testing.T) {
+	db, path := setupDB(t, "test_coinstatsindex")
+	defer teardownDB(db, path)
+
+	chain, err := newTestChain()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	g, err := chaingen.MakeGenerator(chaincfg.SimNetParams(), nil)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	// Add two blocks to the chain and grab an output from the coinbase of the
+	// second one to spend.
+	bk1 := addBlock(t, chain, &g, "bk1")
+	bk2 := addBlock(t, chain, &g, "bk2")
+	spend := g.OldestCoinbaseOuts()[0]
+
+	// Initialize the coin statistics index.
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	subber := NewIndexSubscriber(ctx)
+	go subber.Run(ctx)
+
+	err = AddIndexSpendConsumers(db, chain)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	idx, err := NewCoinStatsIndex(subber, db, chain)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = subber.CatchUp(ctx, chain)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertIndexTip(t, idx, bk2)
+
+	// calcWantStats independently builds the unspent output set that results
+	// from connecting the provided blocks in order and returns its
+	// statistics.  The regular tree of a block is skipped when the next block
+	// disapproves it.
+	calcWantStats := func(blocks ...*dcrutil.Block) *CoinStats {
+		utxos := make(map[wire.OutPoint]*wire.TxOut)
+		connectTxns := func(txns []*wire.MsgTx, tree int8) {
+			for _, tx := range txns {
+				for _, txIn := range tx.TxIn {
+					delete(utxos, txIn.PreviousOutPoint)
+				}
+				txHash := tx.TxHash()
+				for txOutIdx, txOut := range tx.TxOut {
+					if txscript.IsUnspendable(txOut.Value, txOut.PkScript) {
+						continue
+					}
+					outpoint := wire.OutPoint{
+						Hash:  txHash,
+						Index: uint32(txOutIdx),
+						Tree:  tree,
+					}
+					utxos[outpoint] = txOut
+				}
+			}
+		}
+		for i, block := range blocks {
+			msgBlock := block.MsgBlock()
+			connectTxns(msgBlock.STransactions, wire.TxTreeStake)
+			if i+1 < len(blocks) {
+				voteBits := blocks[i+1].MsgBlock().Header.VoteBits
+				if !dcrutil.IsFlagSet16(voteBits, dcrutil.BlockValid) {
+					continue
+				}
+			}
+			connectTxns(msgBlock.Transactions, wire.TxTreeRegular)
+		}
+
+		var set coinStatsDelta
+		for outpoint, txOut := range utxos {
+			outpoint := outpoint
+			set.addOutput(&outpoint, txOut.Value, txOut.Version,
+				txOut.PkScript)
+		}
+		return coinStatsFromDelta(&set)
+	}
+
+	// assertStats ensures the statistics stored for the last provided block
+	// match the statistics of the set that results from connecting all of the
+	// provided blocks.
+	assertStats := func(desc string, blocks ...*dcrutil.Block) {
+		t.Helper()
+
+		block := blocks[len(blocks)-1]
+		stats, err := idx.Stats(block.Hash())
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", desc, err)
+		}
+		want := calcWantStats(blocks...)
+		if stats == nil || *stats != *want {
+			t.Fatalf("%s: mismatched stats for %s -- got %+v, want %+v",
+				desc, block.Hash(), stats, want)
+		}
+	}
+
+	// assertNoStats ensures there are no statistics stored for the provided
+	// block.
+	assertNoStats := func(desc string, block *dcrutil.Block) {
+		t.Helper()
+
+		stats, err := idx.Stats(block.Hash())
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", desc, err)
+		}
+		if stats != nil {
+			t.Fatalf("%s: unexpected stats for %s: %+v", desc,
+				block.Hash(), stats)
+		}
+	}
+
+	// Ensure the blocks that were caught up are indexed.
+	assertStats("after catchup", bk1)
+	assertStats("after catchup", bk1, bk2)
+
+	// Connect a block that spends an output and ensure the statistics reflect
+	// the spend.
+	bk3 := addSpendBlock(t, chain, &g, "bk3", &spend)
+	notifyConnect(t, subber, chain, bk3, bk2)
+	assertIndexTip(t, idx, bk3)
+	assertStats("after spend", bk1, bk2, bk3)
+
+	// Connect a block that disapproves the regular tree of the block that
+	// spent the output and ensure the changes it made are reversed.
+	bk4 := addSpendBlock(t, chain, &g, "bk4", nil, disapproveParent)
+	notifyConnect(t, subber, chain, bk4, bk3)
+	assertIndexTip(t, idx, bk4)
+	assertStats("after disapproval", bk1, bk2, bk3, bk4)
+
+	// Disconnect the disapproving block and ensure its entry is removed while
+	// the entry for the disapproved block is unchanged.
+	notifyDisconnect(t, subber, chain, bk4, bk3)
+	assertIndexTip(t, idx, bk3)
+	assertNoStats("after disconnecting disapproval", bk4)
+	assertStats("after disconnecting disapproval", bk1, bk2, bk3)
+
+	// Connect a block that both disapproves the regular tree of the block
+	// that spent the output and spends it again and ensure the statistics
+	// only reflect the new spend.
+	g.SetTip("bk3")
+	bk4a := addSpendBlock(t, chain, &g, "bk4a", &spend, disapproveParent)
+	notifyConnect(t, subber, chain, bk4a, bk3)
+	assertIndexTip(t, idx, bk4a)
+	assertStats("after respend", bk1, bk2, bk3, bk4a)
+
+	// Disconnect both blocks and ensure their entries are removed.
+	notifyDisconnect(t, subber, chain, bk4a, bk3)
+	assertIndexTip(t, idx, bk3)
+	assertNoStats("after disconnecting respend", bk4a)
+	notifyDisconnect(t, subber, chain, bk3, bk2)
+	assertIndexTip(t, idx, bk2)
+	assertNoStats("after disconnecting spend", bk3)
+	assertStats("after disconnecting spend", bk1, bk2)
+}
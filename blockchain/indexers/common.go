@@ -56,6 +56,15 @@ type PrevScripter interface {
 	PrevScript(*wire.OutPoint) (uint16, []byte, bool)
 }
 
+// PrevAmounter defines an interface that provides access to the amounts of
+// previous outputs keyed by an outpoint.  It is optionally implemented by the
+// sources of previous scripts in order to provide indexes with the values of
+// the outputs spent by transactions within a block.  The boolean return
+// indicates whether or not the amount for the provided outpoint was found.
+type PrevAmounter interface {
+	PrevAmount(*wire.OutPoint) (int64, bool)
+}
+
 // ChainQueryer provides a generic interface that is used to provide access to
 // the chain details required by indexes.
 //
@@ -94,7 +103,11 @@ type ChainQueryer interface {
 
 	// RemoveSpendConsumerDependency removes the provided spend consumer
 	// dependency associated with the provided block hash.
-	RemoveSpendConsumerDependency(dbTx database.Tx, blockHash *chainhash.Hash, consumerID string) error
+	//
+	// The dependency is tracked by the chain database, which is not
+	// necessarily the same database the indexes are stored in, so this
+	// MUST NOT be called with an open index database transaction.
+	RemoveSpendConsumerDependency(blockHash *chainhash.Hash, consumerID string) error
 
 	// FetchSpendConsumer returns the spend journal consumer associated with
 	// the provided id.
@@ -102,11 +115,23 @@ type ChainQueryer interface {
 
 	// PrevScripts returns a source of previous transaction scripts and their
 	// associated versions spent by the given block.
-	PrevScripts(database.Tx, *dcrutil.Block) (PrevScripter, error)
+	//
+	// The scripts are loaded from the chain database, which is not
+	// necessarily the same database the indexes are stored in, so this
+	// MUST NOT be called with an open index database transaction.
+	PrevScripts(*dcrutil.Block) (PrevScripter, error)
 
 	// IsTreasuryAgendaActive returns true if the treasury agenda is active at
 	// the provided block.
 	IsTreasuryAgendaActive(*chainhash.Hash) (bool, error)
+
+	// TicketsMissedAndExpiredByBlock returns the tickets that were missed and
+	// the tickets that expired by the main chain block with the given hash.
+	//
+	// The ticket details are loaded from the chain database, which is not
+	// necessarily the same database the indexes are stored in, so this
+	// MUST NOT be called with an open index database transaction.
+	TicketsMissedAndExpiredByBlock(*chainhash.Hash) ([]chainhash.Hash, []chainhash.Hash, error)
 }
 
 // Indexer defines a generic interface for an indexer.
@@ -158,6 +183,12 @@ type IndexDropper interface {
 	DropIndex(context.Context, database.DB) error
 }
 
+// spendConsumerIndexer describes an indexer which consumes spend journal data
+// through a spend consumer.
+type spendConsumerIndexer interface {
+	spendConsumer() *SpendConsumer
+}
+
 // AssertError identifies an error that indicates an internal code consistency
 // issue and should be treated as a critical and unrecoverable error.
 type AssertError string
@@ -465,6 +496,50 @@ func tip(db database.DB, key []byte) (int64, *chainhash.Hash, error) {
 	return int64(height), hash, err
 }
 
+// bucketSize returns the total size of the keys and values stored in the
+// provided bucket, including all of its nested buckets.
+func bucketSize(bucket database.Bucket) (int64, error) {
+	var size int64
+	err := bucket.ForEach(func(k, v []byte) error {
+		size += int64(len(k) + len(v))
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	err = bucket.ForEachBucket(func(k []byte) error {
+		nestedSize, err := bucketSize(bucket.Bucket(k))
+		if err != nil {
+			return err
+		}
+		size += int64(len(k)) + nestedSize
+		return nil
+	})
+	return size, err
+}
+
+// IndexDiskUsage returns the approximate number of bytes used by the entries of
+// the provided index in its database.  It does not account for the overhead
+// of the underlying storage, so it is only intended as an estimate.
+//
+// Note that this iterates every entry of the index, which can take a while for
+// large indexes.
+func IndexDiskUsage(indexer Indexer) (int64, error) {
+	var size int64
+	err := indexer.DB().View(func(dbTx database.Tx) error {
+		bucket := dbTx.Metadata().Bucket(indexer.Key())
+		if bucket == nil {
+			return nil
+		}
+
+		var err error
+		size, err = bucketSize(bucket)
+		return err
+	})
+	return size, err
+}
+
 // fetchIndexerSubscribers returns a copy of the provided indexer subscriber
 // set.
 //
@@ -542,26 +617,18 @@ func recover(ctx context.Context, idx Indexer) error {
 		}
 		cachedBlock = parent
 
-		var prevScripts PrevScripter
-		err = idx.DB().Update(func(dbTx database.Tx) error {
-			if interruptRequested(ctx) {
-				return indexerError(ErrInterruptRequested, interruptMsg)
-			}
+		if interruptRequested(ctx) {
+			return indexerError(ErrInterruptRequested, interruptMsg)
+		}
 
-			// Fetch the associated script information for previous outputs
-			// of the block if the index requires them.
-			if indexNeedsInputs(idx) {
-				var err error
-				prevScripts, err = queryer.PrevScripts(dbTx, block)
-				if err != nil {
-					return err
-				}
+		// Fetch the associated script information for previous outputs
+		// of the block if the index requires them.
+		var prevScripts PrevScripter
+		if indexNeedsInputs(idx) {
+			prevScripts, err = queryer.PrevScripts(block)
+			if err != nil {
+				return err
 			}
-
-			return nil
-		})
-		if err != nil {
-			return err
 		}
 
 		isTreasuryEnabled, err := queryer.IsTreasuryAgendaActive(parentHash)
@@ -746,16 +813,15 @@ func notifyDependent(ctx context.Context, indexer Indexer, ntfn *IndexNtfn) erro
 		return indexerError(ErrFetchSubscription, msg)
 	}
 
-	// Notify the dependent subscription if set.
-	sub.mtx.Lock()
-	if sub.dependent != nil {
-		err := updateIndex(ctx, sub.dependent.idx, ntfn)
+	// Notify the dependent subscription if set and it is not still catching
+	// up to the main chain.
+	dependent := sub.fetchDependent()
+	if dependent != nil && !dependent.IsCatchingUp() {
+		err := updateIndex(ctx, dependent.idx, ntfn)
 		if err != nil {
-			sub.mtx.Unlock()
 			return err
 		}
 	}
-	sub.mtx.Unlock()
 
 	return nil
 }
@@ -790,6 +856,18 @@ func updateIndex(ctx context.Context, indexer Indexer, ntfn *IndexNtfn) error {
 			indexer.Name(), ntfn.Block.Height())
 		notifyDependent(ctx, indexer, ntfn)
 
+	case ntfn.NtfnType == DisconnectNtfn &&
+		ntfn.Block.Height() > expectedHeight:
+
+		// Relay disconnect notifications with a height higher than the
+		// index tip since the index never connected the block.  This is
+		// possible when an index that caught up to the main chain while
+		// running receives the notification for a block that was
+		// disconnected right before it finished catching up.
+		log.Tracef("%s: relaying notification for disconnected height %d "+
+			"to dependent", indexer.Name(), ntfn.Block.Height())
+		return notifyDependent(ctx, indexer, ntfn)
+
 	case ntfn.Block.Height() > expectedHeight:
 		// Receiving a notification with a height higher than the expected
 		// implies a missed index update.
@@ -806,6 +884,25 @@ func updateIndex(ctx context.Context, indexer Indexer, ntfn *IndexNtfn) error {
 			return err
 		}
 
+		// Remove the spend consumer dependency of the disconnected block
+		// for indexes that consume spend journal data.  This is done once
+		// the index update is committed since the dependency is tracked by
+		// the chain database which may differ from the index database.
+		// Failing to remove it only delays pruning the spend journal entry.
+		if c, ok := indexer.(spendConsumerIndexer); ok &&
+			ntfn.NtfnType == DisconnectNtfn {
+
+			consumerID := c.spendConsumer().ID()
+			err := indexer.Queryer().RemoveSpendConsumerDependency(
+				ntfn.Block.Hash(), consumerID)
+			if err != nil {
+				msg := fmt.Sprintf("%s: unable to remove spend consumer "+
+					"dependency for block %s: %v", indexer.Name(),
+					ntfn.Block.Hash(), err)
+				return indexerError(ErrDisconnectBlock, msg)
+			}
+		}
+
 		err = notifyDependent(ctx, indexer, ntfn)
 		if err != nil {
 			return err
@@ -820,20 +917,59 @@ func updateIndex(ctx context.Context, indexer Indexer, ntfn *IndexNtfn) error {
 	return nil
 }
 
+// HasIndexes returns whether or not the provided database houses any of the
+// optional indexes.
+func HasIndexes(db database.DB) (bool, error) {
+	var exists bool
+	err := db.View(func(dbTx database.Tx) error {
+		indexesBucket := dbTx.Metadata().Bucket(indexTipsBucketName)
+		if indexesBucket == nil {
+			return nil
+		}
+
+		idxKeys := [][]byte{txIndexKey, addrIndexKey, existsAddrIndexKey,
+			spenderIndexKey, addrBalanceIndexKey, blockStatsIndexKey,
+			ticketIndexKey, coinStatsIndexKey, cfHeaderIndexKey,
+			scriptIndexKey}
+		for _, idxKey := range idxKeys {
+			if indexesBucket.Get(idxKey) != nil {
+				exists = true
+				break
+			}
+		}
+		return nil
+	})
+	return exists, err
+}
+
 // AddIndexSpendConsumers adds spend consumers for applicable optional indexes
 // to the chain queryer.
 func AddIndexSpendConsumers(db database.DB, chain ChainQueryer) error {
-	// Only the address index requires a spend consumer currently.
-	_, tipHash, err := tip(db, addrIndexKey)
-	if err != nil {
-		if !errors.Is(err, database.ErrValueNotFound) &&
-			!errors.Is(err, database.ErrBucketNotFound) {
-			msg := fmt.Sprintf("unable to fetch index tip for "+
-				"address index %s", err)
-			return indexerError(ErrFetchTip, msg)
+	// The address index, the address balance index, the block statistics
+	// index, the coin statistics index, and the filter header index require
+	// spend consumers.
+	consumers := []struct {
+		key  []byte
+		name string
+	}{
+		{addrIndexKey, addrIndexName},
+		{addrBalanceIndexKey, addrBalanceIndexName},
+		{blockStatsIndexKey, blockStatsIndexName},
+		{coinStatsIndexKey, coinStatsIndexName},
+		{cfHeaderIndexKey, cfHeaderIndexName},
+	}
+	for _, c := range consumers {
+		_, tipHash, err := tip(db, c.key)
+		if err != nil {
+			if !errors.Is(err, database.ErrValueNotFound) &&
+				!errors.Is(err, database.ErrBucketNotFound) {
+				msg := fmt.Sprintf("unable to fetch index tip for "+
+					"%s %s", c.name, err)
+				return indexerError(ErrFetchTip, msg)
+			}
 		}
-	}
 
-	chain.AddSpendConsumer(NewSpendConsumer(addrIndexName, tipHash, chain))
+		chain.AddSpendConsumer(NewSpendConsumer(c.name, tipHash, chain))
+	}
 	return nil
 }
@@ -0,0 +1,66 @@
+// Copyright (c) 2023 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package indexers
+
+import (
+	"testing"
+
+	"github.com/EXCCoin/exccd/database/v3"
+)
+
+// TestBucketSize ensures the size of a bucket includes the keys and values of
+// the bucket along with all of its nested buckets.
+func TestBucketSize(t *testing.T) {
+	db, path := setupDB(t, "test_bucketsize")
+	defer teardownDB(db, path)
+
+	err := db.Update(func(dbTx database.Tx) error {
+		meta := dbTx.Metadata()
+		bucket, err := meta.CreateBucket([]byte("idx"))
+		if err != nil {
+			return err
+		}
+
+		// Ensure an empty bucket has no size.
+		size, err := bucketSize(bucket)
+		if err != nil {
+			return err
+		}
+		if size != 0 {
+			t.Fatalf("unexpected empty bucket size - got %d, want 0", size)
+		}
+
+		// Add entries to the bucket along with a nested bucket.
+		if err := bucket.Put([]byte("key1"), []byte("value1")); err != nil {
+			return err
+		}
+		if err := bucket.Put([]byte("k2"), []byte("v")); err != nil {
+			return err
+		}
+		nested, err := bucket.CreateBucket([]byte("nested"))
+		if err != nil {
+			return err
+		}
+		if err := nested.Put([]byte("nk"), []byte("nested value")); err != nil {
+			return err
+		}
+
+		// The expected size includes the nested bucket key along with its
+		// entries.
+		want := int64(len("key1") + len("value1") + len("k2") + len("v") +
+			len("nested") + len("nk") + len("nested value"))
+		size, err = bucketSize(bucket)
+		if err != nil {
+			return err
+		}
+		if size != want {
+			t.Fatalf("unexpected bucket size - got %d, want %d", size, want)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
@@ -25,7 +25,7 @@ func TestExistsAddrIndexAsync(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	g, err := chaingen.MakeGenerator(chaincfg.SimNetParams())
+	g, err := chaingen.MakeGenerator(chaincfg.SimNetParams(), nil)
 	if err != nil {
 		t.Fatalf("unexpected error %v", err)
 	}
@@ -47,7 +47,7 @@ func TestExistsAddrIndexAsync(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	err = subber.CatchUp(ctx, db, chain)
+	err = subber.CatchUp(ctx, chain)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -104,8 +104,10 @@ func TestExistsAddrIndexAsync(t *testing.T) {
 			bk5.Hash().String(), tipHash.String())
 	}
 
-	// Fetch the first address paid to by bk5's coinbase.
-	out := bk5.MsgBlock().Transactions[0].TxOut[0]
+	// Fetch the first address paid to by bk5's coinbase.  The first output
+	// of the coinbase is a data-only output, so the address is paid to by
+	// the second one.
+	out := bk5.MsgBlock().Transactions[0].TxOut[1]
 	_, addrs := stdscript.ExtractAddrs(out.Version, out.PkScript,
 		idx.chain.ChainParams())
 
@@ -141,7 +143,7 @@ func TestExistsAddrIndexAsync(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	err = subber.CatchUp(ctx, db, chain)
+	err = subber.CatchUp(ctx, chain)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -238,7 +240,7 @@ func TestExistsAddrIndexAsync(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	err = subber.CatchUp(ctx, db, chain)
+	err = subber.CatchUp(ctx, chain)
 	if err != nil {
 		t.Fatal(err)
 	}
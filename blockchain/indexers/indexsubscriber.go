@@ -11,6 +11,7 @@ import (
 	"sync/atomic"
 
 	"github.com/EXCCoin/exccd/blockchain/v4/internal/progresslog"
+	"github.com/EXCCoin/exccd/chaincfg/chainhash"
 	"github.com/EXCCoin/exccd/database/v3"
 	"github.com/EXCCoin/exccd/dcrutil/v4"
 )
@@ -34,6 +35,22 @@ var (
 
 	// noPrereqs indicates no index prerequisites.
 	noPrereqs = "none"
+
+	// catchUpFetchWorkers is the number of workers that concurrently load
+	// blocks and the data needed to index them during catch up.
+	catchUpFetchWorkers = 4
+
+	// catchUpPrefetchDepth is the maximum number of blocks that are loaded
+	// ahead of the block being batched during catch up.
+	catchUpPrefetchDepth = 256
+
+	// catchUpBatchSize is the number of blocks that are committed to an
+	// index in a single database transaction during catch up.
+	catchUpBatchSize = 64
+
+	// catchUpBatchQueue is the maximum number of batches that are queued for
+	// an index during catch up.
+	catchUpBatchQueue = 2
 )
 
 // IndexNtfn represents an index notification detailing a block connection
@@ -44,6 +61,8 @@ type IndexNtfn struct {
 	Parent            *dcrutil.Block
 	PrevScripts       PrevScripter
 	IsTreasuryEnabled bool
+	TicketsMissed     []chainhash.Hash
+	TicketsExpired    []chainhash.Hash
 	Done              chan bool
 }
 
@@ -66,6 +85,11 @@ type IndexSubscription struct {
 	// notifications before it does. A nil dependency indicates the subscription
 	// has no dependencies.
 	dependent *IndexSubscription
+
+	// catchingUp indicates the subscription was made while the subscriber is
+	// running and the associated index has not caught up to the main chain
+	// yet.  Notifications are not delivered to the index until it has.
+	catchingUp bool
 }
 
 // newIndexSubscription initializes a new index subscription.
@@ -78,16 +102,39 @@ func newIndexSubscription(subber *IndexSubscriber, indexer Indexer, prereq strin
 	}
 }
 
+// IsCatchingUp returns whether or not the index associated with the
+// subscription is still catching up to the main chain after being subscribed
+// while the subscriber is running.
+//
+// This function is safe for concurrent access.
+func (s *IndexSubscription) IsCatchingUp() bool {
+	s.mtx.Lock()
+	catchingUp := s.catchingUp
+	s.mtx.Unlock()
+	return catchingUp
+}
+
+// fetchDependent returns the dependent of the subscription, if any.
+//
+// This function is safe for concurrent access.
+func (s *IndexSubscription) fetchDependent() *IndexSubscription {
+	s.mtx.Lock()
+	dependent := s.dependent
+	s.mtx.Unlock()
+	return dependent
+}
+
 // stop prevents any future index updates from being delivered and
 // unsubscribes the associated subscription.
 func (s *IndexSubscription) stop() error {
+	subber := s.subscriber
 
 	// If the subscription has a prerequisite, find it and remove the
 	// subscription as a dependency.
 	if s.prerequisite != noPrereqs {
-		s.mtx.Lock()
-		prereq, ok := s.subscriber.subscriptions[s.prerequisite]
-		s.mtx.Unlock()
+		subber.mtx.Lock()
+		prereq, ok := subber.subscriptions[s.prerequisite]
+		subber.mtx.Unlock()
 		if !ok {
 			return fmt.Errorf("no subscription found with id %s", s.prerequisite)
 		}
@@ -96,12 +143,14 @@ func (s *IndexSubscription) stop() error {
 		prereq.dependent = nil
 		prereq.mtx.Unlock()
 
+		atomic.AddUint32(&subber.subscribers, ^uint32(0))
+
 		return nil
 	}
 
 	// If the subscription has a dependent, stop it as well.
-	if s.dependent != nil {
-		err := s.dependent.stop()
+	if dependent := s.fetchDependent(); dependent != nil {
+		err := dependent.stop()
 		if err != nil {
 			return err
 		}
@@ -109,9 +158,11 @@ func (s *IndexSubscription) stop() error {
 
 	// If the subscription is independent, remove it from the
 	// index subscriber's subscriptions.
-	s.mtx.Lock()
-	delete(s.subscriber.subscriptions, s.id)
-	s.mtx.Unlock()
+	subber.mtx.Lock()
+	delete(subber.subscriptions, s.id)
+	subber.mtx.Unlock()
+
+	atomic.AddUint32(&subber.subscribers, ^uint32(0))
 
 	return nil
 }
@@ -121,11 +172,24 @@ type IndexSubscriber struct {
 	subscribers uint32 // update atomically.
 
 	c             chan IndexNtfn
+	requests      chan subscriberRequest
 	subscriptions map[string]*IndexSubscription
 	mtx           sync.Mutex
 	ctx           context.Context
 	cancel        context.CancelFunc
 	quit          chan struct{}
+
+	// running indicates the notification handler has been started.  It is
+	// protected by mtx.
+	running bool
+}
+
+// subscriberRequest describes a function to be executed by the notification
+// handler of the index subscriber between notifications along with the
+// channel its result is delivered on.
+type subscriberRequest struct {
+	fn     func(ctx context.Context) error
+	result chan error
 }
 
 // NewIndexSubscriber creates a new index subscriber. It also starts the
@@ -134,6 +198,7 @@ func NewIndexSubscriber(sCtx context.Context) *IndexSubscriber {
 	ctx, cancel := context.WithCancel(sCtx)
 	s := &IndexSubscriber{
 		c:             make(chan IndexNtfn, bufferSize),
+		requests:      make(chan subscriberRequest),
 		subscriptions: make(map[string]*IndexSubscription),
 		ctx:           ctx,
 		cancel:        cancel,
@@ -145,8 +210,14 @@ func NewIndexSubscriber(sCtx context.Context) *IndexSubscriber {
 // Subscribe subscribes an index for updates.  The returned index subscription
 // has functions to retrieve a channel that produces a stream of index updates
 // and to stop the stream when the caller no longer wishes to receive updates.
+//
+// Indexes subscribed after the notification handler has been started do not
+// receive notifications until they are synced via SyncIndex or CatchUp.
 func (s *IndexSubscriber) Subscribe(index Indexer, prerequisite string) (*IndexSubscription, error) {
 	sub := newIndexSubscription(s, index, prerequisite)
+	s.mtx.Lock()
+	sub.catchingUp = s.running
+	s.mtx.Unlock()
 
 	// If the subscription has a prequisite, find it and set the subscription
 	// as a dependency.
@@ -197,13 +268,13 @@ func (s *IndexSubscriber) Notify(ntfn *IndexNtfn) {
 	}
 }
 
-// findLowestIndexTipHeight determines the lowest index tip height among
-// subscribed indexes and their dependencies.
-func (s *IndexSubscriber) findLowestIndexTipHeight(queryer ChainQueryer) (int64, int64, error) {
+// findLowestIndexTipHeight determines the lowest index tip height among the
+// provided subscriptions and their dependencies.
+func findLowestIndexTipHeight(queryer ChainQueryer, subs []*IndexSubscription) (int64, int64, error) {
 	// Find the lowest tip height to catch up among subscribed indexes.
 	bestHeight, _ := queryer.Best()
 	lowestHeight := bestHeight
-	for _, sub := range s.subscriptions {
+	for _, sub := range subs {
 		tipHeight, tipHash, err := sub.idx.Tip()
 		if err != nil {
 			return 0, bestHeight, err
@@ -220,9 +291,9 @@ func (s *IndexSubscriber) findLowestIndexTipHeight(queryer ChainQueryer) (int64,
 		}
 
 		// Update the lowest tip height if a dependent has a lower tip height.
-		dependent := sub.dependent
+		dependent := sub.fetchDependent()
 		for dependent != nil {
-			tipHeight, _, err := sub.dependent.idx.Tip()
+			tipHeight, _, err := dependent.idx.Tip()
 			if err != nil {
 				return 0, bestHeight, err
 			}
@@ -231,19 +302,232 @@ func (s *IndexSubscriber) findLowestIndexTipHeight(queryer ChainQueryer) (int64,
 				lowestHeight = tipHeight
 			}
 
-			dependent = dependent.dependent
+			dependent = dependent.fetchDependent()
 		}
 	}
 
 	return lowestHeight, bestHeight, nil
 }
 
+// catchUpResult houses the index notification prefetched for a block during
+// catch up or the error that prevented it from being prefetched.
+type catchUpResult struct {
+	ntfn *IndexNtfn
+	err  error
+}
+
+// catchUpJob describes a block to be prefetched during catch up along with the
+// channel its result is delivered on.
+type catchUpJob struct {
+	height int64
+	result chan catchUpResult
+}
+
+// fetchCatchUpNtfn loads all of the data needed to connect the main chain
+// block at the provided height to the indexes and returns it as an index
+// notification.
+//
+// The parent of the block is NOT set in the returned notification since it is
+// the block of the previous notification and is set by the caller in order to
+// avoid loading every block twice.
+func fetchCatchUpNtfn(queryer ChainQueryer, height int64) (*IndexNtfn, error) {
+	hash, err := queryer.BlockHashByHeight(height)
+	if err != nil {
+		return nil, err
+	}
+
+	// Ensure the next tip hash is on the main chain.
+	if !queryer.MainChainHasBlock(hash) {
+		msg := fmt.Sprintf("the next block being synced to (%s) "+
+			"at height %d is not on the main chain", hash, height)
+		return nil, indexerError(ErrBlockNotOnMainChain, msg)
+	}
+
+	block, err := queryer.BlockByHash(hash)
+	if err != nil {
+		return nil, err
+	}
+
+	prevScripts, err := queryer.PrevScripts(block)
+	if err != nil {
+		return nil, err
+	}
+
+	parentHash := &block.MsgBlock().Header.PrevBlock
+	isTreasuryEnabled, err := queryer.IsTreasuryAgendaActive(parentHash)
+	if err != nil {
+		return nil, err
+	}
+
+	missed, expired, err := queryer.TicketsMissedAndExpiredByBlock(hash)
+	if err != nil {
+		return nil, err
+	}
+
+	return &IndexNtfn{
+		NtfnType:          ConnectNtfn,
+		Block:             block,
+		PrevScripts:       prevScripts,
+		IsTreasuryEnabled: isTreasuryEnabled,
+		TicketsMissed:     missed,
+		TicketsExpired:    expired,
+	}, nil
+}
+
+// prefetchCatchUpNtfns launches workers that concurrently load the index
+// notifications for the main chain blocks in the provided range of heights.
+//
+// The returned channel produces a channel per block, in order of height, that
+// delivers the result for that block once it has been loaded.  This allows the
+// blocks to be loaded out of order while still being processed in order.  The
+// number of blocks that are loaded ahead of the caller is limited by the
+// capacity of the returned channel.
+//
+// The returned channel is closed once all of the blocks in the range have been
+// handed out or the provided context is canceled.
+func prefetchCatchUpNtfns(ctx context.Context, queryer ChainQueryer, startHeight, endHeight int64) <-chan chan catchUpResult {
+	pending := make(chan chan catchUpResult, catchUpPrefetchDepth)
+	jobs := make(chan catchUpJob)
+	for i := 0; i < catchUpFetchWorkers; i++ {
+		go func() {
+			for job := range jobs {
+				ntfn, err := fetchCatchUpNtfn(queryer, job.height)
+				job.result <- catchUpResult{ntfn: ntfn, err: err}
+			}
+		}()
+	}
+
+	go func() {
+		defer close(pending)
+		defer close(jobs)
+
+		for height := startHeight; height <= endHeight; height++ {
+			// The result channel is buffered so workers never block on
+			// delivering results that are no longer wanted.
+			result := make(chan catchUpResult, 1)
+			select {
+			case pending <- result:
+			case <-ctx.Done():
+				return
+			}
+			select {
+			case jobs <- catchUpJob{height: height, result: result}:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return pending
+}
+
+// updateIndexBatch processes the provided batch of connected blocks, which
+// MUST be ordered by height and contiguous, for the provided index and relays
+// the batch to its dependent.
+//
+// All of the blocks in the batch that the index has not already processed are
+// committed in a single database transaction.  Since every block connected to
+// the index also updates the index tip in the same transaction, an interrupted
+// catch up always leaves the index at the last fully committed block.
+func updateIndexBatch(ctx context.Context, indexer Indexer, batch []*IndexNtfn) error {
+	tip, _, err := indexer.Tip()
+	if err != nil {
+		msg := fmt.Sprintf("%s: unable to fetch index tip: %v",
+			indexer.Name(), err)
+		return indexerError(ErrFetchTip, msg)
+	}
+
+	// Skip the blocks the index has already processed since it is possible
+	// for an index to have a higher tip than the lowest index tip.
+	connect := batch
+	for len(connect) > 0 && connect[0].Block.Height() <= tip {
+		connect = connect[1:]
+	}
+
+	if len(connect) > 0 {
+		// Receiving a batch that starts with a height higher than the
+		// expected implies a missed index update.
+		if height := connect[0].Block.Height(); height != tip+1 {
+			msg := fmt.Sprintf("%s: missing index notification, expected "+
+				"notification for height %d, got %d", indexer.Name(),
+				tip+1, height)
+			return indexerError(ErrMissingNotification, msg)
+		}
+
+		err = indexer.DB().Update(func(dbTx database.Tx) error {
+			for _, ntfn := range connect {
+				if interruptRequested(ctx) {
+					return indexerError(ErrInterruptRequested,
+						interruptMsg)
+				}
+				err := indexer.ProcessNotification(dbTx, ntfn)
+				if err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		err = maybeNotifySubscribers(ctx, indexer)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Relay the batch to the dependent since it is possible for a dependent
+	// to have a lower tip height than its prerequisite.
+	sub := indexer.IndexSubscription()
+	if sub == nil {
+		msg := fmt.Sprintf("%s: no index update subscription found",
+			indexer.Name())
+		return indexerError(ErrFetchSubscription, msg)
+	}
+	if dependent := sub.fetchDependent(); dependent != nil {
+		return updateIndexBatch(ctx, dependent.idx, batch)
+	}
+
+	return nil
+}
+
 // CatchUp syncs all subscribed indexes to the main chain by connecting blocks
 // from after the lowest index tip to the current main chain tip.
 //
 // This should be called after all indexes have subscribed for updates.
-func (s *IndexSubscriber) CatchUp(ctx context.Context, db database.DB, queryer ChainQueryer) error {
-	lowestHeight, bestHeight, err := s.findLowestIndexTipHeight(queryer)
+func (s *IndexSubscriber) CatchUp(ctx context.Context, queryer ChainQueryer) error {
+	subs := s.fetchSubscriptions(true)
+	err := s.catchUp(ctx, queryer, subs)
+	if err != nil {
+		// Stop the index subscriber when any of the indexes fail to catch
+		// up in order to match the behavior of the notification handler.
+		s.cancel()
+		return err
+	}
+
+	// Start delivering notifications to the indexes that were subscribed
+	// after the notification handler was started now that they are synced.
+	for _, sub := range subs {
+		for dep := sub; dep != nil; dep = dep.fetchDependent() {
+			dep.mtx.Lock()
+			dep.catchingUp = false
+			dep.mtx.Unlock()
+		}
+	}
+	return nil
+}
+
+// catchUp syncs the indexes of the provided subscriptions and their
+// dependents to the main chain by connecting blocks from after the lowest
+// index tip to the current main chain tip.
+//
+// The catch up is pipelined.  Multiple workers load the blocks along with the
+// data needed to index them from the database concurrently ahead of the
+// indexes, each index processes the blocks concurrently with the others, and
+// the blocks are committed to the indexes in ordered batches.
+func (s *IndexSubscriber) catchUp(ctx context.Context, queryer ChainQueryer, subs []*IndexSubscription) error {
+	lowestHeight, bestHeight, err := findLowestIndexTipHeight(queryer, subs)
 	if err != nil {
 		return err
 	}
@@ -261,102 +545,244 @@ func (s *IndexSubscriber) CatchUp(ctx context.Context, db database.DB, queryer C
 	log.Infof("Catching up from height %d to %d", lowestHeight,
 		bestHeight)
 
-	var cachedParent *dcrutil.Block
-	for height := lowestHeight + 1; height <= bestHeight; height++ {
-		if interruptRequested(ctx) {
-			return indexerError(ErrInterruptRequested, interruptMsg)
-		}
+	// Ensure the prefetch workers and the index workers are stopped when
+	// returning early.
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// Launch a worker per subscribed index that processes batches of blocks
+	// in order.  Dependents are processed by the worker of their
+	// prerequisite so that the processing order between them is maintained.
+	var wg sync.WaitGroup
+	errs := make(chan error, len(subs))
+	batchChans := make([]chan []*IndexNtfn, 0, len(subs))
+	for _, sub := range subs {
+		batches := make(chan []*IndexNtfn, catchUpBatchQueue)
+		batchChans = append(batchChans, batches)
+		wg.Add(1)
+		go func(indexer Indexer) {
+			defer wg.Done()
+			for batch := range batches {
+				err := updateIndexBatch(ctx, indexer, batch)
+				if err != nil {
+					errs <- err
+					cancel()
+					return
+				}
+			}
+		}(sub.idx)
+	}
 
-		hash, err := queryer.BlockHashByHeight(height)
-		if err != nil {
-			return err
+	// dispatch hands the provided batch to all of the index workers.
+	dispatch := func(batch []*IndexNtfn) bool {
+		for _, batches := range batchChans {
+			select {
+			case batches <- batch:
+			case <-ctx.Done():
+				return false
+			}
 		}
+		return true
+	}
 
-		// Ensure the next tip hash is on the main chain.
-		if !queryer.MainChainHasBlock(hash) {
-			msg := fmt.Sprintf("the next block being synced to (%s) "+
-				"at height %d is not on the main chain", hash, height)
-			return indexerError(ErrBlockNotOnMainChain, msg)
+	// Load the blocks ahead of the index workers and batch them up in order.
+	var dispatchErr error
+	var parent *dcrutil.Block
+	batch := make([]*IndexNtfn, 0, catchUpBatchSize)
+	pending := prefetchCatchUpNtfns(ctx, queryer, lowestHeight+1, bestHeight)
+	for result := range pending {
+		var res catchUpResult
+		select {
+		case res = <-result:
+		case <-ctx.Done():
+		}
+		if interruptRequested(ctx) {
+			break
+		}
+		if res.err != nil {
+			dispatchErr = res.err
+			break
 		}
 
-		var parent *dcrutil.Block
-		if cachedParent == nil && height > 0 {
-			parentHash, err := queryer.BlockHashByHeight(height - 1)
-			if err != nil {
-				return err
-			}
+		ntfn := res.ntfn
+		if parent == nil {
+			parentHash := &ntfn.Block.MsgBlock().Header.PrevBlock
 			parent, err = queryer.BlockByHash(parentHash)
 			if err != nil {
-				return err
+				dispatchErr = err
+				break
 			}
-		} else {
-			parent = cachedParent
 		}
+		ntfn.Parent = parent
+		parent = ntfn.Block
 
-		child, err := queryer.BlockByHash(hash)
-		if err != nil {
-			return err
-		}
+		progressLogger.LogProgress(ntfn.Block.MsgBlock(), bestHeight)
 
-		// Construct and send the index notification.
-		var prevScripts PrevScripter
-		err = db.View(func(dbTx database.Tx) error {
-			if interruptRequested(ctx) {
-				return indexerError(ErrInterruptRequested, interruptMsg)
+		batch = append(batch, ntfn)
+		if len(batch) == catchUpBatchSize || ntfn.Block.Height() == bestHeight {
+			if !dispatch(batch) {
+				break
 			}
+			batch = make([]*IndexNtfn, 0, catchUpBatchSize)
+		}
+	}
+	if dispatchErr != nil {
+		cancel()
+	}
+
+	// Wait for the index workers to finish the dispatched batches.
+	for _, batches := range batchChans {
+		close(batches)
+	}
+	wg.Wait()
+	close(errs)
+
+	if err := <-errs; err != nil {
+		return err
+	}
+	if dispatchErr != nil {
+		return dispatchErr
+	}
+	if interruptRequested(ctx) {
+		return indexerError(ErrInterruptRequested, interruptMsg)
+	}
+
+	log.Infof("Caught up to height %d", bestHeight)
+
+	return nil
+}
+
+// fetchSubscriptions returns the independent subscriptions of the subscriber.
+// Subscriptions with indexes that are still catching up are only included when
+// requested.
+//
+// This function is safe for concurrent access.
+func (s *IndexSubscriber) fetchSubscriptions(includeCatchingUp bool) []*IndexSubscription {
+	s.mtx.Lock()
+	subs := make([]*IndexSubscription, 0, len(s.subscriptions))
+	for _, sub := range s.subscriptions {
+		if !includeCatchingUp && sub.IsCatchingUp() {
+			continue
+		}
+		subs = append(subs, sub)
+	}
+	s.mtx.Unlock()
+	return subs
+}
 
-			prevScripts, err = queryer.PrevScripts(dbTx, child)
+// request executes the provided function from the notification handler
+// between notifications and returns its result.  This ensures no
+// notifications are delivered to the subscribed indexes while it runs.
+func (s *IndexSubscriber) request(ctx context.Context, fn func(ctx context.Context) error) error {
+	s.mtx.Lock()
+	running := s.running
+	s.mtx.Unlock()
+	if !running {
+		return fmt.Errorf("index subscriber is not running")
+	}
+
+	req := subscriberRequest{fn: fn, result: make(chan error, 1)}
+	select {
+	case s.requests <- req:
+	case <-s.quit:
+		return indexerError(ErrInterruptRequested, interruptMsg)
+	case <-ctx.Done():
+		return indexerError(ErrInterruptRequested, interruptMsg)
+	}
+
+	// The result is always delivered once the request has been accepted.
+	return <-req.result
+}
+
+// SyncIndex catches the provided index, which must have been subscribed while
+// the notification handler is running, along with its dependent up to the
+// main chain and then starts delivering notifications to them.
+//
+// The bulk of the catch up is done without blocking the notification handler.
+// Only the final blocks connected while catching up are processed from the
+// notification handler so that no notifications are missed.
+//
+// This blocks until the index is synced or the provided context is canceled,
+// so it is typically run as a goroutine.
+func (s *IndexSubscriber) SyncIndex(ctx context.Context, queryer ChainQueryer, indexer Indexer) error {
+	sub := indexer.IndexSubscription()
+	if sub == nil {
+		msg := fmt.Sprintf("%s: no index update subscription found",
+			indexer.Name())
+		return indexerError(ErrFetchSubscription, msg)
+	}
+
+	// syncSubscription reverts the indexes of the subscription and its
+	// dependents that are on a side chain back to the main chain and then
+	// catches them up to the main chain tip.
+	syncSubscription := func(ctx context.Context) error {
+		for dep := sub; dep != nil; dep = dep.fetchDependent() {
+			err := recover(ctx, dep.idx)
 			if err != nil {
 				return err
 			}
-
-			return nil
-		})
-		if err != nil {
-			return err
 		}
+		return s.catchUp(ctx, queryer, []*IndexSubscription{sub})
+	}
+
+	err := syncSubscription(ctx)
+	if err != nil {
+		return err
+	}
 
-		isTreasuryEnabled, err := queryer.IsTreasuryAgendaActive(parent.Hash())
+	return s.request(ctx, func(runCtx context.Context) error {
+		err := syncSubscription(runCtx)
 		if err != nil {
 			return err
 		}
 
-		ntfn := &IndexNtfn{
-			NtfnType:          ConnectNtfn,
-			Block:             child,
-			Parent:            parent,
-			PrevScripts:       prevScripts,
-			IsTreasuryEnabled: isTreasuryEnabled,
-		}
+		for dep := sub; dep != nil; dep = dep.fetchDependent() {
+			dep.mtx.Lock()
+			dep.catchingUp = false
+			dep.mtx.Unlock()
 
-		// Relay the index update to subscribed indexes.
-		for _, sub := range s.subscriptions {
-			err := updateIndex(ctx, sub.idx, ntfn)
+			err := maybeNotifySubscribers(runCtx, dep.idx)
 			if err != nil {
-				s.cancel()
 				return err
 			}
 		}
 
-		cachedParent = child
+		log.Infof("%s: synced and receiving updates", indexer.Name())
+		return nil
+	})
+}
 
-		progressLogger.LogBlockHeight(child.MsgBlock(), parent.MsgBlock())
+// Unsubscribe stops delivering notifications to the provided index along with
+// its dependent and removes their subscriptions.
+//
+// Since it waits for the notification handler to finish any notification that
+// is in progress, the indexes are no longer updated once it returns.
+func (s *IndexSubscriber) Unsubscribe(ctx context.Context, indexer Indexer) error {
+	sub := indexer.IndexSubscription()
+	if sub == nil {
+		msg := fmt.Sprintf("%s: no index update subscription found",
+			indexer.Name())
+		return indexerError(ErrFetchSubscription, msg)
 	}
 
-	log.Infof("Caught up to height %d", bestHeight)
-
-	return nil
+	return s.request(ctx, func(context.Context) error {
+		return sub.stop()
+	})
 }
 
 // Run relays index notifications to subscribed indexes.
 //
 // This should be run as a goroutine.
 func (s *IndexSubscriber) Run(ctx context.Context) {
+	s.mtx.Lock()
+	s.running = true
+	s.mtx.Unlock()
+
 	for {
 		select {
 		case ntfn := <-s.c:
 			// Relay the index update to subscribed indexes.
-			for _, sub := range s.subscriptions {
+			for _, sub := range s.fetchSubscriptions(false) {
 				err := updateIndex(ctx, sub.idx, &ntfn)
 				if err != nil {
 					log.Error(err)
@@ -369,6 +795,9 @@ func (s *IndexSubscriber) Run(ctx context.Context) {
 				close(ntfn.Done)
 			}
 
+		case req := <-s.requests:
+			req.result <- req.fn(ctx)
+
 		case <-ctx.Done():
 			log.Infof("Index subscriber shutting down")
 
@@ -376,7 +805,7 @@ func (s *IndexSubscriber) Run(ctx context.Context) {
 
 			// Stop all updates to subscribed indexes and terminate their
 			// processes.
-			for _, sub := range s.subscriptions {
+			for _, sub := range s.fetchSubscriptions(true) {
 				err := sub.stop()
 				if err != nil {
 					log.Error("unable to stop index subscription: %v", err)
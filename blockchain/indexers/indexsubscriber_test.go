@@ -6,10 +6,14 @@ package indexers
 
 import (
 	"context"
+	"errors"
 	"testing"
 
 	"github.com/EXCCoin/exccd/blockchain/v4/chaingen"
+	"github.com/EXCCoin/exccd/chaincfg/chainhash"
 	"github.com/EXCCoin/exccd/chaincfg/v3"
+	"github.com/EXCCoin/exccd/dcrutil/v4"
+	"github.com/EXCCoin/exccd/wire"
 )
 
 // TestIndexSubscriberAsync ensures the index subscriber
@@ -23,7 +27,7 @@ func TestIndexSubscriberAsync(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	g, err := chaingen.MakeGenerator(chaincfg.SimNetParams())
+	g, err := chaingen.MakeGenerator(chaincfg.SimNetParams(), nil)
 	if err != nil {
 		t.Fatalf("unexpected error %v", err)
 	}
@@ -60,7 +64,7 @@ func TestIndexSubscriberAsync(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	err = subber.CatchUp(ctx, db, chain)
+	err = subber.CatchUp(ctx, chain)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -232,3 +236,118 @@ func TestIndexSubscriberAsync(t *testing.T) {
 			bk5.Hash().String(), existsAddrIdxTipHash.String())
 	}
 }
+
+// testCatchUpQueryer provides a chain queryer backed by a fixed main chain for
+// testing the catch up prefetching.  Only the methods used to prefetch blocks
+// are implemented.
+type testCatchUpQueryer struct {
+	ChainQueryer
+	blocks     []*dcrutil.Block
+	failHeight int64
+}
+
+// newTestCatchUpQueryer returns a test chain queryer with a main chain of the
+// provided number of blocks after the genesis block.
+func newTestCatchUpQueryer(numBlocks int) *testCatchUpQueryer {
+	q := &testCatchUpQueryer{failHeight: -1}
+	var prevHash chainhash.Hash
+	for i := 0; i <= numBlocks; i++ {
+		block := dcrutil.NewBlock(&wire.MsgBlock{Header: wire.BlockHeader{
+			PrevBlock: prevHash,
+			Height:    uint32(i),
+		}})
+		q.blocks = append(q.blocks, block)
+		prevHash = *block.Hash()
+	}
+	return q
+}
+
+func (q *testCatchUpQueryer) BlockHashByHeight(height int64) (*chainhash.Hash, error) {
+	if height == q.failHeight {
+		return nil, errors.New("unable to fetch block hash")
+	}
+	return q.blocks[height].Hash(), nil
+}
+
+func (q *testCatchUpQueryer) MainChainHasBlock(hash *chainhash.Hash) bool {
+	return true
+}
+
+func (q *testCatchUpQueryer) BlockByHash(hash *chainhash.Hash) (*dcrutil.Block, error) {
+	for _, block := range q.blocks {
+		if *block.Hash() == *hash {
+			return block, nil
+		}
+	}
+	return nil, errors.New("block not found")
+}
+
+func (q *testCatchUpQueryer) PrevScripts(*dcrutil.Block) (PrevScripter, error) {
+	return nil, nil
+}
+
+func (q *testCatchUpQueryer) IsTreasuryAgendaActive(*chainhash.Hash) (bool, error) {
+	return false, nil
+}
+
+func (q *testCatchUpQueryer) TicketsMissedAndExpiredByBlock(*chainhash.Hash) ([]chainhash.Hash, []chainhash.Hash, error) {
+	return nil, nil, nil
+}
+
+// TestPrefetchCatchUpNtfns ensures the notifications prefetched for catch up
+// are delivered in order of height and that failures and cancellation are
+// handled as expected.
+func TestPrefetchCatchUpNtfns(t *testing.T) {
+	t.Parallel()
+
+	// Ensure all blocks in the range are delivered in order even though they
+	// are loaded concurrently.
+	numBlocks := catchUpPrefetchDepth + 3*catchUpFetchWorkers
+	q := newTestCatchUpQueryer(numBlocks)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	wantHeight := int64(1)
+	for result := range prefetchCatchUpNtfns(ctx, q, 1, int64(numBlocks)) {
+		res := <-result
+		if res.err != nil {
+			t.Fatalf("unexpected error: %v", res.err)
+		}
+		if res.ntfn.Block.Height() != wantHeight {
+			t.Fatalf("unexpected block height - got %d, want %d",
+				res.ntfn.Block.Height(), wantHeight)
+		}
+		if res.ntfn.NtfnType != ConnectNtfn {
+			t.Fatalf("unexpected notification type %v", res.ntfn.NtfnType)
+		}
+		wantHeight++
+	}
+	if wantHeight != int64(numBlocks)+1 {
+		t.Fatalf("unexpected number of blocks - got %d, want %d",
+			wantHeight-1, numBlocks)
+	}
+
+	// Ensure a failure to load a block is delivered as its result.
+	q.failHeight = 5
+	var gotErr error
+	for result := range prefetchCatchUpNtfns(ctx, q, 1, 10) {
+		if res := <-result; res.err != nil {
+			gotErr = res.err
+			break
+		}
+	}
+	if gotErr == nil {
+		t.Fatal("did not receive expected error")
+	}
+
+	// Ensure the pending channel is closed once the context is canceled.
+	cancel()
+	pending := prefetchCatchUpNtfns(ctx, q, 1, int64(numBlocks))
+	var numPending int
+	for range pending {
+		numPending++
+	}
+	if numPending > catchUpPrefetchDepth {
+		t.Fatalf("unexpected number of pending blocks after cancellation "+
+			"- got %d", numPending)
+	}
+}
@@ -0,0 +1,593 @@
+// Copyright (c) 2023 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package indexers
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/EXCCoin/exccd/chaincfg/chainhash"
+	"github.com/EXCCoin/exccd/chaincfg/v3"
+	"github.com/EXCCoin/exccd/database/v3"
+	"github.com/EXCCoin/exccd/dcrutil/v4"
+	"github.com/EXCCoin/exccd/txscript/v4"
+	"github.com/EXCCoin/exccd/txscript/v4/stdscript"
+	"github.com/EXCCoin/exccd/wire"
+)
+
+const (
+	// scriptIndexName is the human-readable name for the index.
+	scriptIndexName = "script index"
+
+	// scriptIndexVersion is the current version of the script index.
+	scriptIndexVersion = 1
+
+	// The following constants define the prefixes of the different kinds of
+	// entries stored in the script index bucket.
+	scriptHashPrefix = 's'
+	nullDataPrefix   = 'd'
+
+	// nullDataKeyPrefixSize is the number of bytes of the data pushed by
+	// null data scripts that are used to key the null data entries.  Shorter
+	// data is padded with zeros.
+	nullDataKeyPrefixSize = 8
+
+	// scriptOutputKeySuffixSize is the size of the suffix shared by all keys
+	// in the script index.  It consists of the 4 byte block height, the 32
+	// byte transaction hash, and the 4 byte output index.
+	scriptOutputKeySuffixSize = 4 + chainhash.HashSize + 4
+
+	// scriptHashKeySize is the size of a script hash key.  It consists of the
+	// 1 byte prefix, the 32 byte script hash, and the shared suffix.
+	scriptHashKeySize = 1 + chainhash.HashSize + scriptOutputKeySuffixSize
+
+	// nullDataKeySize is the size of a null data key.  It consists of the 1
+	// byte prefix, the data prefix, and the shared suffix.
+	nullDataKeySize = 1 + nullDataKeyPrefixSize + scriptOutputKeySuffixSize
+
+	// scriptOutputEntryMinSize is the minimum size of a script output entry.
+	// It consists of the 1 byte tree, the 8 byte amount, the 2 byte script
+	// version, and the script.
+	scriptOutputEntryMinSize = 1 + 8 + 2
+)
+
+var (
+	// scriptIndexKey is the key of the script index and the db bucket used to
+	// house it.
+	scriptIndexKey = []byte("scriptidx")
+)
+
+// -----------------------------------------------------------------------------
+// The script index maps the scripts of the outputs in the main chain that the
+// address index is unable to track to the outputs that contain them.  That is
+// to say, it covers the outputs with non-standard scripts, scripts with
+// versions that are not known, and null data scripts, none of which decode to
+// any addresses.
+//
+// Only the regular transaction tree is indexed since the transactions in the
+// stake tree are restricted to standard forms that are tracked by the address
+// index.
+//
+// All entries are stored in a single flat bucket and are distinguished by a
+// one byte prefix.  Every indexed output has an entry keyed by the BLAKE-256
+// hash of its script.  Null data outputs additionally have an entry keyed by
+// the beginning of the data they carry in order to support searching for the
+// outputs of protocols that identify themselves with a data prefix.
+// Fixed-width integers in the keys are big endian so they sort by height.
+//
+// The serialized format for the script hash entries is:
+//
+//   's'<script hash><block height><tx hash><output index> = <output>
+//
+//   Field              Type              Size
+//   script hash        chainhash.Hash    32 bytes
+//   block height       uint32            4 bytes
+//   tx hash            chainhash.Hash    32 bytes
+//   output index       uint32            4 bytes
+//   -----
+//   Total: 73 bytes
+//
+// The serialized format for the null data entries is:
+//
+//   'd'<data prefix><block height><tx hash><output index> = <output>
+//
+//   Field              Type              Size
+//   data prefix        [8]byte           8 bytes
+//   block height       uint32            4 bytes
+//   tx hash            chainhash.Hash    32 bytes
+//   output index       uint32            4 bytes
+//   -----
+//   Total: 49 bytes
+//
+// The serialized format for the output of both kinds of entries is:
+//
+//   <tree><amount><script version><script>
+//
+//   Field              Type              Size
+//   tree               int8              1 byte
+//   amount             uint64            8 bytes
+//   script version     uint16            2 bytes
+//   script             []byte            variable
+// -----------------------------------------------------------------------------
+
+// ScriptOutput houses information about an output in the script index.
+type ScriptOutput struct {
+	// OutPoint identifies the output and BlockHeight is the height of the
+	// block that contains it.
+	OutPoint    wire.OutPoint
+	BlockHeight int64
+
+	// Amount, ScriptVersion, and Script describe the output.
+	Amount        int64
+	ScriptVersion uint16
+	Script        []byte
+}
+
+// scriptOutputKeySuffix returns the portion of a script index key that
+// identifies the provided output.
+func scriptOutputKeySuffix(height int64, outpoint *wire.OutPoint) []byte {
+	suffix := make([]byte, scriptOutputKeySuffixSize)
+	sortableOrder.PutUint32(suffix, uint32(height))
+	copy(suffix[4:], outpoint.Hash[:])
+	sortableOrder.PutUint32(suffix[4+chainhash.HashSize:], outpoint.Index)
+	return suffix
+}
+
+// scriptHashSeek returns the common prefix of all script hash keys for the
+// provided script hash.
+func scriptHashSeek(scriptHash *chainhash.Hash) []byte {
+	seek := make([]byte, 1+chainhash.HashSize)
+	seek[0] = scriptHashPrefix
+	copy(seek[1:], scriptHash[:])
+	return seek
+}
+
+// nullDataSeek returns the common prefix of all null data keys for data that
+// begins with the provided prefix.  Only up to the first nullDataKeyPrefixSize
+// bytes of the prefix are part of the keys.
+func nullDataSeek(prefix []byte) []byte {
+	if len(prefix) > nullDataKeyPrefixSize {
+		prefix = prefix[:nullDataKeyPrefixSize]
+	}
+	seek := make([]byte, 1+len(prefix))
+	seek[0] = nullDataPrefix
+	copy(seek[1:], prefix)
+	return seek
+}
+
+// scriptHashKey returns the script hash key for the provided output.
+func scriptHashKey(scriptHash *chainhash.Hash, height int64, outpoint *wire.OutPoint) []byte {
+	key := make([]byte, 0, scriptHashKeySize)
+	key = append(key, scriptHashSeek(scriptHash)...)
+	return append(key, scriptOutputKeySuffix(height, outpoint)...)
+}
+
+// nullDataKey returns the null data key for the provided output that carries
+// the provided data.
+func nullDataKey(data []byte, height int64, outpoint *wire.OutPoint) []byte {
+	var dataPrefix [nullDataKeyPrefixSize]byte
+	copy(dataPrefix[:], data)
+	key := make([]byte, 0, nullDataKeySize)
+	key = append(key, nullDataPrefix)
+	key = append(key, dataPrefix[:]...)
+	return append(key, scriptOutputKeySuffix(height, outpoint)...)
+}
+
+// serializeScriptOutput returns the serialized output of a script index entry
+// for the provided output.
+func serializeScriptOutput(tree int8, txOut *wire.TxOut) []byte {
+	serialized := make([]byte, scriptOutputEntryMinSize+len(txOut.PkScript))
+	serialized[0] = byte(tree)
+	byteOrder.PutUint64(serialized[1:], uint64(txOut.Value))
+	byteOrder.PutUint16(serialized[9:], txOut.Version)
+	copy(serialized[scriptOutputEntryMinSize:], txOut.PkScript)
+	return serialized
+}
+
+// deserializeScriptOutput decodes the provided script index key and serialized
+// output into a script output.
+func deserializeScriptOutput(key, serialized []byte) (*ScriptOutput, error) {
+	if len(key) < scriptOutputKeySuffixSize {
+		return nil, errDeserialize(fmt.Sprintf("unexpected script index key "+
+			"length %d", len(key)))
+	}
+	if len(serialized) < scriptOutputEntryMinSize {
+		return nil, errDeserialize(fmt.Sprintf("unexpected script output "+
+			"entry length %d", len(serialized)))
+	}
+
+	suffix := key[len(key)-scriptOutputKeySuffixSize:]
+	var output ScriptOutput
+	output.BlockHeight = int64(sortableOrder.Uint32(suffix))
+	copy(output.OutPoint.Hash[:], suffix[4:])
+	output.OutPoint.Index = sortableOrder.Uint32(suffix[4+chainhash.HashSize:])
+	output.OutPoint.Tree = int8(serialized[0])
+	output.Amount = int64(byteOrder.Uint64(serialized[1:]))
+	output.ScriptVersion = byteOrder.Uint16(serialized[9:])
+	output.Script = make([]byte, len(serialized)-scriptOutputEntryMinSize)
+	copy(output.Script, serialized[scriptOutputEntryMinSize:])
+	return &output, nil
+}
+
+// nullData returns the data carried by the provided null data script along
+// with whether or not the script is a null data script.
+func nullData(scriptVersion uint16, script []byte) ([]byte, bool) {
+	if !stdscript.IsNullDataScript(scriptVersion, script) {
+		return nil, false
+	}
+
+	tokenizer := txscript.MakeScriptTokenizer(scriptVersion, script[1:])
+	if !tokenizer.Next() {
+		return nil, true
+	}
+	return tokenizer.Data(), true
+}
+
+// ScriptIndex implements a script index.  That is to say, it supports querying
+// the outputs in the main chain with scripts that do not decode to addresses
+// by the hash of the script and the outputs with null data scripts by the
+// beginning of the data they carry.
+type ScriptIndex struct {
+	// The following fields are set when the instance is created and can't
+	// be changed afterwards, so there is no need to protect them with a
+	// separate mutex.
+	db          database.DB
+	chain       ChainQueryer
+	chainParams *chaincfg.Params
+	sub         *IndexSubscription
+
+	subscribers map[chan bool]struct{}
+	mtx         sync.Mutex
+	cancel      context.CancelFunc
+}
+
+// Ensure the ScriptIndex type implements the Indexer interface.
+var _ Indexer = (*ScriptIndex)(nil)
+
+// Init initializes the script index.
+//
+// This is part of the Indexer interface.
+func (idx *ScriptIndex) Init(ctx context.Context, chainParams *chaincfg.Params) error {
+	if interruptRequested(ctx) {
+		return indexerError(ErrInterruptRequested, interruptMsg)
+	}
+
+	// Finish any drops that were previously interrupted.
+	if err := finishDrop(ctx, idx); err != nil {
+		return err
+	}
+
+	// Create the initial state for the index as needed.
+	if err := createIndex(idx, &chainParams.GenesisHash); err != nil {
+		return err
+	}
+
+	// Upgrade the index as needed.
+	if err := upgradeIndex(ctx, idx, &chainParams.GenesisHash); err != nil {
+		return err
+	}
+
+	// Recover the script index and its dependents to the main chain if
+	// needed.
+	return recover(ctx, idx)
+}
+
+// Key returns the database key to use for the index as a byte slice.
+//
+// This is part of the Indexer interface.
+func (idx *ScriptIndex) Key() []byte {
+	return scriptIndexKey
+}
+
+// Name returns the human-readable name of the index.
+//
+// This is part of the Indexer interface.
+func (idx *ScriptIndex) Name() string {
+	return scriptIndexName
+}
+
+// Version returns the current version of the index.
+//
+// This is part of the Indexer interface.
+func (idx *ScriptIndex) Version() uint32 {
+	return scriptIndexVersion
+}
+
+// DB returns the database of the index.
+//
+// This is part of the Indexer interface.
+func (idx *ScriptIndex) DB() database.DB {
+	return idx.db
+}
+
+// Queryer returns the chain queryer.
+//
+// This is part of the Indexer interface.
+func (idx *ScriptIndex) Queryer() ChainQueryer {
+	return idx.chain
+}
+
+// Tip returns the current tip of the index.
+//
+// This is part of the Indexer interface.
+func (idx *ScriptIndex) Tip() (int64, *chainhash.Hash, error) {
+	return tip(idx.db, idx.Key())
+}
+
+// Create is invoked when the index is created for the first time.  It creates
+// the bucket for the script index.
+//
+// This is part of the Indexer interface.
+func (idx *ScriptIndex) Create(dbTx database.Tx) error {
+	_, err := dbTx.Metadata().CreateBucket(scriptIndexKey)
+	return err
+}
+
+// IndexSubscription returns the subscription for index updates.
+//
+// This is part of the Indexer interface.
+func (idx *ScriptIndex) IndexSubscription() *IndexSubscription {
+	return idx.sub
+}
+
+// Subscribers returns all client channels waiting for the next index update.
+//
+// This is part of the Indexer interface.
+func (idx *ScriptIndex) Subscribers() map[chan bool]struct{} {
+	idx.mtx.Lock()
+	defer idx.mtx.Unlock()
+	return fetchIndexerSubscribers(idx.subscribers)
+}
+
+// WaitForSync subscribes clients for the next index sync update.
+//
+// This is part of the Indexer interface.
+func (idx *ScriptIndex) WaitForSync() chan bool {
+	c := make(chan bool)
+
+	removeIndexerSub := func() {
+		idx.mtx.Lock()
+		delete(idx.subscribers, c)
+		idx.mtx.Unlock()
+	}
+
+	go purgeIndexerSubscription(c, removeIndexerSub)
+
+	idx.mtx.Lock()
+	idx.subscribers[c] = struct{}{}
+	idx.mtx.Unlock()
+
+	return c
+}
+
+// forEachIndexedOutput invokes the provided function with the keys of the
+// entries for every output in the passed block that is tracked by the script
+// index along with the output itself.
+func (idx *ScriptIndex) forEachIndexedOutput(block *dcrutil.Block, fn func(keys [][]byte, txOut *wire.TxOut) error) error {
+	height := block.Height()
+	for _, tx := range block.Transactions() {
+		msgTx := tx.MsgTx()
+		for txOutIdx, txOut := range msgTx.TxOut {
+			// Skip outputs that are tracked by the address index.
+			_, addrs := stdscript.ExtractAddrs(txOut.Version, txOut.PkScript,
+				idx.chainParams)
+			if len(addrs) != 0 {
+				continue
+			}
+
+			outpoint := wire.OutPoint{
+				Hash:  *tx.Hash(),
+				Index: uint32(txOutIdx),
+				Tree:  wire.TxTreeRegular,
+			}
+			scriptHash := chainhash.HashH(txOut.PkScript)
+			keys := [][]byte{scriptHashKey(&scriptHash, height, &outpoint)}
+			if data, ok := nullData(txOut.Version, txOut.PkScript); ok {
+				keys = append(keys, nullDataKey(data, height, &outpoint))
+			}
+			if err := fn(keys, txOut); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// connectBlock adds the entries for all of the outputs in the passed block
+// that are tracked by the index.
+func (idx *ScriptIndex) connectBlock(dbTx database.Tx, block *dcrutil.Block) error {
+	bucket := dbTx.Metadata().Bucket(scriptIndexKey)
+	err := idx.forEachIndexedOutput(block, func(keys [][]byte, txOut *wire.TxOut) error {
+		serialized := serializeScriptOutput(wire.TxTreeRegular, txOut)
+		for _, key := range keys {
+			if err := bucket.Put(key, serialized); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	// Update the current index tip.
+	return dbPutIndexerTip(dbTx, idx.Key(), block.Hash(), int32(block.Height()))
+}
+
+// disconnectBlock removes the entries for all of the outputs in the passed
+// block that are tracked by the index.
+func (idx *ScriptIndex) disconnectBlock(dbTx database.Tx, block *dcrutil.Block) error {
+	bucket := dbTx.Metadata().Bucket(scriptIndexKey)
+	err := idx.forEachIndexedOutput(block, func(keys [][]byte, _ *wire.TxOut) error {
+		for _, key := range keys {
+			if err := bucket.Delete(key); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	// Update the current index tip.
+	return dbPutIndexerTip(dbTx, idx.Key(), &block.MsgBlock().Header.PrevBlock,
+		int32(block.Height()-1))
+}
+
+// fetchOutputs returns the outputs of the entries with keys that begin with
+// the provided seek prefix for which the provided filter function returns
+// true, ordered by key, after skipping the requested number of them.  The
+// filter is ignored when it is nil.  The number of outputs that were skipped is
+// also returned.
+func (idx *ScriptIndex) fetchOutputs(seek []byte, filter func(*ScriptOutput) bool, numToSkip, numRequested uint32) ([]ScriptOutput, uint32, error) {
+	var outputs []ScriptOutput
+	var skipped uint32
+	err := idx.db.View(func(dbTx database.Tx) error {
+		cursor := dbTx.Metadata().Bucket(scriptIndexKey).Cursor()
+		for ok := cursor.Seek(seek); ok && uint32(len(outputs)) < numRequested; ok = cursor.Next() {
+			key := cursor.Key()
+			if !bytes.HasPrefix(key, seek) {
+				break
+			}
+
+			output, err := deserializeScriptOutput(key, cursor.Value())
+			if err != nil {
+				str := fmt.Sprintf("corrupt script index entry %x: %v", key,
+					err)
+				return makeDbErr(database.ErrCorruption, str)
+			}
+			if filter != nil && !filter(output) {
+				continue
+			}
+			if skipped < numToSkip {
+				skipped++
+				continue
+			}
+			outputs = append(outputs, *output)
+		}
+		return nil
+	})
+	return outputs, skipped, err
+}
+
+// OutputsByScriptHash returns the outputs in the main chain with a script that
+// has the provided BLAKE-256 hash ordered by height after skipping the
+// requested number of them.  The number of outputs that were skipped is also
+// returned.
+//
+// Only outputs with scripts that do not decode to any addresses are indexed.
+//
+// This function is safe for concurrent access.
+func (idx *ScriptIndex) OutputsByScriptHash(scriptHash *chainhash.Hash, numToSkip, numRequested uint32) ([]ScriptOutput, uint32, error) {
+	return idx.fetchOutputs(scriptHashSeek(scriptHash), nil, numToSkip,
+		numRequested)
+}
+
+// OutputsByScript returns the outputs in the main chain with the provided
+// script ordered by height after skipping the requested number of them.  The
+// number of outputs that were skipped is also returned.
+//
+// Only outputs with scripts that do not decode to any addresses are indexed.
+//
+// This function is safe for concurrent access.
+func (idx *ScriptIndex) OutputsByScript(script []byte, numToSkip, numRequested uint32) ([]ScriptOutput, uint32, error) {
+	scriptHash := chainhash.HashH(script)
+	return idx.OutputsByScriptHash(&scriptHash, numToSkip, numRequested)
+}
+
+// OutputsByNullDataPrefix returns the outputs in the main chain with a null
+// data script that carries data beginning with the provided prefix after
+// skipping the requested number of them.  The number of outputs that were
+// skipped is also returned.
+//
+// The outputs are ordered by the first 8 bytes of the data they carry and then
+// by height, so the outputs are ordered by height when the prefix is at least
+// 8 bytes.
+//
+// This function is safe for concurrent access.
+func (idx *ScriptIndex) OutputsByNullDataPrefix(prefix []byte, numToSkip, numRequested uint32) ([]ScriptOutput, uint32, error) {
+	// The keys only contain the beginning of the data and shorter data is
+	// padded with zeros, so filter by the full data as needed.
+	filter := func(output *ScriptOutput) bool {
+		data, _ := nullData(output.ScriptVersion, output.Script)
+		return bytes.HasPrefix(data, prefix)
+	}
+	return idx.fetchOutputs(nullDataSeek(prefix), filter, numToSkip,
+		numRequested)
+}
+
+// NewScriptIndex returns a new instance of an indexer that is used to create a
+// mapping of the scripts of all outputs in the main chain that do not decode
+// to addresses to the outputs that contain them.
+func NewScriptIndex(subscriber *IndexSubscriber, db database.DB, chain ChainQueryer) (*ScriptIndex, error) {
+	idx := &ScriptIndex{
+		db:          db,
+		chain:       chain,
+		chainParams: chain.ChainParams(),
+		subscribers: make(map[chan bool]struct{}),
+		cancel:      subscriber.cancel,
+	}
+
+	// The script index is an optional index. It has no prerequisite and is
+	// updated asynchronously.
+	sub, err := subscriber.Subscribe(idx, noPrereqs)
+	if err != nil {
+		return nil, err
+	}
+
+	idx.sub = sub
+
+	err = idx.Init(subscriber.ctx, idx.chainParams)
+	if err != nil {
+		return nil, err
+	}
+
+	return idx, nil
+}
+
+// DropScriptIndex drops the script index from the provided database if it
+// exists.
+func DropScriptIndex(ctx context.Context, db database.DB) error {
+	return dropFlatIndex(ctx, db, scriptIndexKey, scriptIndexName)
+}
+
+// DropIndex drops the script index from the provided database if it exists.
+func (*ScriptIndex) DropIndex(ctx context.Context, db database.DB) error {
+	return DropScriptIndex(ctx, db)
+}
+
+// ProcessNotification indexes the provided notification based on its
+// notification type.
+//
+// This is part of the Indexer interface.
+func (idx *ScriptIndex) ProcessNotification(dbTx database.Tx, ntfn *IndexNtfn) error {
+	switch ntfn.NtfnType {
+	case ConnectNtfn:
+		err := idx.connectBlock(dbTx, ntfn.Block)
+		if err != nil {
+			msg := fmt.Sprintf("%s: unable to connect block: %v",
+				idx.Name(), err)
+			return indexerError(ErrConnectBlock, msg)
+		}
+
+	case DisconnectNtfn:
+		err := idx.disconnectBlock(dbTx, ntfn.Block)
+		if err != nil {
+			msg := fmt.Sprintf("%s: unable to disconnect block: %v",
+				idx.Name(), err)
+			return indexerError(ErrDisconnectBlock, msg)
+		}
+
+	default:
+		msg := fmt.Sprintf("%s: unknown notification type provided: %d",
+			idx.Name(), ntfn.NtfnType)
+		return indexerError(ErrInvalidNotificationType, msg)
+	}
+
+	return nil
+}
@@ -0,0 +1,296 @@
+// Copyright (c) 2023 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package indexers
+
+import (
+	"bytes"
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/EXCCoin/exccd/blockchain/v4/chaingen"
+	"github.com/EXCCoin/exccd/chaincfg/chainhash"
+	"github.com/EXCCoin/exccd/chaincfg/v3"
+	"github.com/EXCCoin/exccd/dcrutil/v4"
+	"github.com/EXCCoin/exccd/wire"
+)
+
+// TestScriptOutputSerialization ensures serializing and deserializing script
+// index entries works as expected for both kinds of keys.
+func TestScriptOutputSerialization(t *testing.T) {
+	t.Parallel()
+
+	outpoint := wire.OutPoint{
+		Hash:  chainhash.Hash{0x01, 0x02, 0x03},
+		Index: 7,
+		Tree:  wire.TxTreeRegular,
+	}
+	txOut := wire.TxOut{
+		Value:    12345,
+		Version:  1,
+		PkScript: []byte{0x6a, 0x02, 0xab, 0xcd},
+	}
+	want := ScriptOutput{
+		OutPoint:      outpoint,
+		BlockHeight:   100,
+		Amount:        txOut.Value,
+		ScriptVersion: txOut.Version,
+		Script:        txOut.PkScript,
+	}
+
+	scriptHash := chainhash.HashH(txOut.PkScript)
+	keys := map[string][]byte{
+		"script hash": scriptHashKey(&scriptHash, 100, &outpoint),
+		"null data":   nullDataKey([]byte{0xab, 0xcd}, 100, &outpoint),
+	}
+	if len(keys["script hash"]) != scriptHashKeySize {
+		t.Fatalf("unexpected script hash key length - got %d, want %d",
+			len(keys["script hash"]), scriptHashKeySize)
+	}
+	if len(keys["null data"]) != nullDataKeySize {
+		t.Fatalf("unexpected null data key length - got %d, want %d",
+			len(keys["null data"]), nullDataKeySize)
+	}
+
+	serialized := serializeScriptOutput(outpoint.Tree, &txOut)
+	for name, key := range keys {
+		got, err := deserializeScriptOutput(key, serialized)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", name, err)
+		}
+		if !reflect.DeepEqual(*got, want) {
+			t.Fatalf("%s: mismatched output - got %+v, want %+v", name, *got,
+				want)
+		}
+	}
+
+	// Ensure the keys begin with their respective seek prefixes.
+	if !bytes.HasPrefix(keys["script hash"], scriptHashSeek(&scriptHash)) {
+		t.Fatal("script hash key does not begin with its seek prefix")
+	}
+	if !bytes.HasPrefix(keys["null data"], nullDataSeek([]byte{0xab})) {
+		t.Fatal("null data key does not begin with its seek prefix")
+	}
+
+	// Ensure entries with an invalid key or output length are rejected.
+	_, err := deserializeScriptOutput(keys["null data"][:10], serialized)
+	if !isDeserializeErr(err) {
+		t.Errorf("short key: did not receive expected deserialize error - "+
+			"got %v", err)
+	}
+	_, err = deserializeScriptOutput(keys["null data"], serialized[:10])
+	if !isDeserializeErr(err) {
+		t.Errorf("short output: did not receive expected deserialize error "+
+			"- got %v", err)
+	}
+}
+
+// TestScriptIndexKeyOrder ensures the keys for the same script are ordered by
+// block height and that null data keys with long prefixes are truncated.
+func TestScriptIndexKeyOrder(t *testing.T) {
+	t.Parallel()
+
+	scriptHash := chainhash.Hash{0xff}
+	low := &wire.OutPoint{Hash: chainhash.Hash{0xff}, Index: 0xffffffff}
+	high := &wire.OutPoint{}
+	if bytes.Compare(scriptHashKey(&scriptHash, 1, low),
+		scriptHashKey(&scriptHash, 256, high)) >= 0 {
+
+		t.Fatal("script hash keys are not ordered by height")
+	}
+
+	data := []byte{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}
+	if bytes.Compare(nullDataKey(data, 1, low), nullDataKey(data, 256,
+		high)) >= 0 {
+
+		t.Fatal("null data keys are not ordered by height")
+	}
+	seek := nullDataSeek(data)
+	if len(seek) != 1+nullDataKeyPrefixSize {
+		t.Fatalf("unexpected null data seek length - got %d, want %d",
+			len(seek), 1+nullDataKeyPrefixSize)
+	}
+	if !bytes.HasPrefix(nullDataKey(data, 1, low), seek) {
+		t.Fatal("null data key does not begin with its truncated seek prefix")
+	}
+}
+
+// TestNullData ensures the data carried by null data scripts is extracted as
+// expected.
+func TestNullData(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		version  uint16
+		script   []byte
+		wantData []byte
+		wantOk   bool
+	}{{
+		name:   "no data",
+		script: []byte{0x6a},
+		wantOk: true,
+	}, {
+		name:     "small data push",
+		script:   []byte{0x6a, 0x02, 0xab, 0xcd},
+		wantData: []byte{0xab, 0xcd},
+		wantOk:   true,
+	}, {
+		name:     "pushdata1",
+		script:   append([]byte{0x6a, 0x4c, 0x4c}, bytes.Repeat([]byte{0x01}, 0x4c)...),
+		wantData: bytes.Repeat([]byte{0x01}, 0x4c),
+		wantOk:   true,
+	}, {
+		name:   "not null data",
+		script: []byte{0x51},
+	}, {
+		name:    "unsupported script version",
+		version: 1,
+		script:  []byte{0x6a, 0x02, 0xab, 0xcd},
+	}}
+
+	for _, test := range tests {
+		data, ok := nullData(test.version, test.script)
+		if ok != test.wantOk {
+			t.Errorf("%q: unexpected null data result - got %v, want %v",
+				test.name, ok, test.wantOk)
+			continue
+		}
+		if !bytes.Equal(data, test.wantData) {
+			t.Errorf("%q: unexpected data - got %x, want %x", test.name,
+				data, test.wantData)
+		}
+	}
+}
+
+// TestScriptIndexConnectDisconnect ensures the script index adds and removes
+// the entries for the outputs that do not decode to addresses as blocks are
+// connected and disconnected.
+func TestScriptIndexConnectDisconnect(t *testing.T) {
+	db, path := setupDB(t, "test_scriptindex")
+	defer teardownDB(db, path)
+
+	chain, err := newTestChain()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	g, err := chaingen.MakeGenerator(chaincfg.SimNetParams(), nil)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	// Add two blocks to the chain and grab an output from the coinbase of the
+	// second one to spend.
+	addBlock(t, chain, &g, "bk1")
+	bk2 := addBlock(t, chain, &g, "bk2")
+	spend := g.OldestCoinbaseOuts()[0]
+
+	// Initialize the script index.
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	subber := NewIndexSubscriber(ctx)
+	go subber.Run(ctx)
+
+	idx, err := NewScriptIndex(subber, db, chain)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = subber.CatchUp(ctx, chain)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertIndexTip(t, idx, bk2)
+
+	// assertOutput ensures the output at the provided transaction and output
+	// indices of the regular tree of the provided block is found by both its
+	// script and the data it carries when indexed is set and that it is not
+	// found by either of them otherwise.
+	assertOutput := func(desc string, block *dcrutil.Block, txIdx int, txOutIdx uint32, indexed bool) {
+		t.Helper()
+
+		tx := block.Transactions()[txIdx]
+		txOut := tx.MsgTx().TxOut[txOutIdx]
+		data, ok := nullData(txOut.Version, txOut.PkScript)
+		if !ok {
+			t.Fatalf("%s: output %s:%d is not a null data output", desc,
+				tx.Hash(), txOutIdx)
+		}
+
+		var want []ScriptOutput
+		if indexed {
+			want = []ScriptOutput{{
+				OutPoint: wire.OutPoint{
+					Hash:  *tx.Hash(),
+					Index: txOutIdx,
+					Tree:  wire.TxTreeRegular,
+				},
+				BlockHeight:   block.Height(),
+				Amount:        txOut.Value,
+				ScriptVersion: txOut.Version,
+				Script:        txOut.PkScript,
+			}}
+		}
+
+		byScript, _, err := idx.OutputsByScript(txOut.PkScript, 0, 10)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", desc, err)
+		}
+		if !reflect.DeepEqual(byScript, want) {
+			t.Fatalf("%s: mismatched outputs by script -- got %+v, want %+v",
+				desc, byScript, want)
+		}
+		byData, _, err := idx.OutputsByNullDataPrefix(data, 0, 10)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", desc, err)
+		}
+		if !reflect.DeepEqual(byData, want) {
+			t.Fatalf("%s: mismatched outputs by data -- got %+v, want %+v",
+				desc, byData, want)
+		}
+	}
+
+	// Ensure the null data output of the coinbase of the block that was caught
+	// up is indexed.  Note that the first block only pays the block one
+	// ledger, so it does not have one.
+	assertOutput("after catchup", bk2, 0, 0, true)
+
+	// Connect a block that spends an output and ensure the null data output
+	// of the spending transaction is indexed while the output that pays to an
+	// address is not.
+	bk3 := addSpendBlock(t, chain, &g, "bk3", &spend)
+	notifyConnect(t, subber, chain, bk3, bk2)
+	assertIndexTip(t, idx, bk3)
+	assertOutput("after spend", bk3, 0, 0, true)
+	assertOutput("after spend", bk3, 1, 1, true)
+	p2shScript := bk3.MsgBlock().Transactions[1].TxOut[0].PkScript
+	outputs, _, err := idx.OutputsByScript(p2shScript, 0, 10)
+	if err != nil {
+		t.Fatalf("after spend: unexpected error: %v", err)
+	}
+	if len(outputs) != 0 {
+		t.Fatalf("after spend: unexpected outputs for address script: %+v",
+			outputs)
+	}
+
+	// Disconnect the block and ensure its entries are removed while the
+	// entries of its parent remain.
+	notifyDisconnect(t, subber, chain, bk3, bk2)
+	assertIndexTip(t, idx, bk2)
+	assertOutput("after disconnect", bk3, 0, 0, false)
+	assertOutput("after disconnect", bk3, 1, 1, false)
+	assertOutput("after disconnect", bk2, 0, 0, true)
+
+	// Connect a side chain block in its place and ensure only the outputs of
+	// the new block are indexed.
+	g.SetTip("bk2")
+	bk3a := addSpendBlock(t, chain, &g, "bk3a", &spend)
+	notifyConnect(t, subber, chain, bk3a, bk2)
+	assertIndexTip(t, idx, bk3a)
+	assertOutput("after reorg", bk3a, 1, 1, true)
+	assertOutput("after reorg", bk3, 1, 1, false)
+}
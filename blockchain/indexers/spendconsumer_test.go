@@ -36,7 +36,7 @@ func TestSpendConsumer(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	g, err := chaingen.MakeGenerator(chaincfg.SimNetParams())
+	g, err := chaingen.MakeGenerator(chaincfg.SimNetParams(), nil)
 	if err != nil {
 		t.Fatalf("unexpected error %v", err)
 	}
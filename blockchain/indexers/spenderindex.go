@@ -0,0 +1,494 @@
+// Copyright (c) 2023 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package indexers
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/EXCCoin/exccd/chaincfg/chainhash"
+	"github.com/EXCCoin/exccd/chaincfg/v3"
+	"github.com/EXCCoin/exccd/database/v3"
+	"github.com/EXCCoin/exccd/dcrutil/v4"
+	"github.com/EXCCoin/exccd/wire"
+)
+
+const (
+	// spenderIndexName is the human-readable name for the index.
+	spenderIndexName = "spender index"
+
+	// spenderIndexVersion is the current version of the spender index.
+	spenderIndexVersion = 1
+
+	// spenderKeySize is the size of a spender index key.  It consists of
+	// the 32 byte hash of the transaction that contains the spent output and
+	// the 4 byte index of the output.
+	spenderKeySize = chainhash.HashSize + 4
+
+	// spenderEntrySize is the size of a spender index entry.  It consists of
+	// the 32 byte hash of the spending transaction, the 4 byte index of the
+	// spending input, and the 4 byte height of the block that contains the
+	// spending transaction.
+	spenderEntrySize = chainhash.HashSize + 4 + 4
+)
+
+var (
+	// spenderIndexKey is the key of the spender index and the db bucket used
+	// to house it.
+	spenderIndexKey = []byte("spenderidx")
+)
+
+// -----------------------------------------------------------------------------
+// The spender index consists of an entry for every output spent by a
+// transaction in the main chain which maps the outpoint of the spent output to
+// the transaction that spends it.
+//
+// Only a single spender is stored per outpoint because an output can only be
+// spent once in the main chain.  Note that the regular transaction tree of a
+// block can be disapproved by the next block, in which case the outputs it
+// spent become spendable again.  The entries for the spends made by a
+// disapproved regular tree are therefore removed when the disapproving block is
+// connected and restored when it is disconnected.
+//
+// The serialized format for the keys and values in the spender index bucket
+// is:
+//
+//   <txhash><output index> = <spender hash><input index><block height>
+//
+//   Field           Type              Size
+//   txhash          chainhash.Hash    32 bytes
+//   output index    uint32            4 bytes
+//   spender hash    chainhash.Hash    32 bytes
+//   input index     uint32            4 bytes
+//   block height    uint32            4 bytes
+//   -----
+//   Total: 76 bytes
+// -----------------------------------------------------------------------------
+
+// SpenderIndexEntry houses information about an entry in the spender index.
+type SpenderIndexEntry struct {
+	// SpenderHash is the hash of the transaction that spends the output.
+	SpenderHash chainhash.Hash
+
+	// InputIndex is the index of the input within the spending transaction
+	// that spends the output.
+	InputIndex uint32
+
+	// BlockHeight is the height of the block that contains the spending
+	// transaction.
+	BlockHeight int64
+}
+
+// spenderIndexKeyForOutPoint returns the spender index key for the provided
+// outpoint.
+func spenderIndexKeyForOutPoint(outpoint *wire.OutPoint) []byte {
+	key := make([]byte, spenderKeySize)
+	copy(key, outpoint.Hash[:])
+	byteOrder.PutUint32(key[chainhash.HashSize:], outpoint.Index)
+	return key
+}
+
+// serializeSpenderIndexEntry returns the serialization of the provided spender
+// index entry.
+func serializeSpenderIndexEntry(entry *SpenderIndexEntry) []byte {
+	serialized := make([]byte, spenderEntrySize)
+	copy(serialized, entry.SpenderHash[:])
+	offset := chainhash.HashSize
+	byteOrder.PutUint32(serialized[offset:], entry.InputIndex)
+	offset += 4
+	byteOrder.PutUint32(serialized[offset:], uint32(entry.BlockHeight))
+	return serialized
+}
+
+// deserializeSpenderIndexEntry decodes the provided serialized spender index
+// entry.
+func deserializeSpenderIndexEntry(serialized []byte) (*SpenderIndexEntry, error) {
+	if len(serialized) != spenderEntrySize {
+		return nil, errDeserialize(fmt.Sprintf("unexpected spender index "+
+			"entry length %d", len(serialized)))
+	}
+
+	var entry SpenderIndexEntry
+	copy(entry.SpenderHash[:], serialized[:chainhash.HashSize])
+	offset := chainhash.HashSize
+	entry.InputIndex = byteOrder.Uint32(serialized[offset:])
+	offset += 4
+	entry.BlockHeight = int64(byteOrder.Uint32(serialized[offset:]))
+	return &entry, nil
+}
+
+// dbFetchSpenderIndexEntry uses an existing database transaction to fetch the
+// spender of the provided outpoint from the spender index.  When there is no
+// entry for the provided outpoint, nil will be returned for both the entry and
+// the error.
+func dbFetchSpenderIndexEntry(dbTx database.Tx, outpoint *wire.OutPoint) (*SpenderIndexEntry, error) {
+	spenderIndex := dbTx.Metadata().Bucket(spenderIndexKey)
+	serialized := spenderIndex.Get(spenderIndexKeyForOutPoint(outpoint))
+	if serialized == nil {
+		return nil, nil
+	}
+
+	entry, err := deserializeSpenderIndexEntry(serialized)
+	if err != nil {
+		str := fmt.Sprintf("corrupt spender index entry for %v: %v",
+			outpoint, err)
+		return nil, makeDbErr(database.ErrCorruption, str)
+	}
+	return entry, nil
+}
+
+// isNullOutPoint returns whether or not the provided outpoint is a null
+// outpoint which is referenced by the inputs of coinbases, stakebases,
+// treasurybases, and treasury spends since they do not spend any outputs.
+func isNullOutPoint(outpoint *wire.OutPoint) bool {
+	return outpoint.Index == wire.MaxPrevOutIndex &&
+		outpoint.Hash == chainhash.Hash{}
+}
+
+// forEachSpentOutPointInTxns invokes the provided function with every outpoint
+// spent by the passed transactions along with the spending transaction and the
+// index of the spending input.
+func forEachSpentOutPointInTxns(txns []*dcrutil.Tx, fn func(outpoint *wire.OutPoint, spender *chainhash.Hash, inputIdx uint32) error) error {
+	for _, tx := range txns {
+		for inputIdx, txIn := range tx.MsgTx().TxIn {
+			outpoint := &txIn.PreviousOutPoint
+			if isNullOutPoint(outpoint) {
+				continue
+			}
+
+			err := fn(outpoint, tx.Hash(), uint32(inputIdx))
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// forEachSpentOutPoint invokes the provided function with every outpoint spent
+// by the transactions in the passed block along with the spending transaction
+// and the index of the spending input.
+func forEachSpentOutPoint(block *dcrutil.Block, fn func(outpoint *wire.OutPoint, spender *chainhash.Hash, inputIdx uint32) error) error {
+	if err := forEachSpentOutPointInTxns(block.Transactions(), fn); err != nil {
+		return err
+	}
+	return forEachSpentOutPointInTxns(block.STransactions(), fn)
+}
+
+// disapprovesParent returns whether or not the passed block disapproves the
+// regular transaction tree of its parent.  The genesis block is never indexed,
+// so a block that disapproves it is treated as approving it.
+func disapprovesParent(block, parent *dcrutil.Block) bool {
+	header := &block.MsgBlock().Header
+	return !dcrutil.IsFlagSet16(header.VoteBits, dcrutil.BlockValid) &&
+		parent.Height() > 0
+}
+
+// SpenderIndex implements a spent output index.  That is to say, it supports
+// querying the transaction that spends any given output in the main chain.
+type SpenderIndex struct {
+	// The following fields are set when the instance is created and can't
+	// be changed afterwards, so there is no need to protect them with a
+	// separate mutex.
+	db    database.DB
+	chain ChainQueryer
+	sub   *IndexSubscription
+
+	subscribers map[chan bool]struct{}
+	mtx         sync.Mutex
+	cancel      context.CancelFunc
+}
+
+// Ensure the SpenderIndex type implements the Indexer interface.
+var _ Indexer = (*SpenderIndex)(nil)
+
+// Init initializes the spender index.
+//
+// This is part of the Indexer interface.
+func (idx *SpenderIndex) Init(ctx context.Context, chainParams *chaincfg.Params) error {
+	if interruptRequested(ctx) {
+		return indexerError(ErrInterruptRequested, interruptMsg)
+	}
+
+	// Finish any drops that were previously interrupted.
+	if err := finishDrop(ctx, idx); err != nil {
+		return err
+	}
+
+	// Create the initial state for the index as needed.
+	if err := createIndex(idx, &chainParams.GenesisHash); err != nil {
+		return err
+	}
+
+	// Upgrade the index as needed.
+	if err := upgradeIndex(ctx, idx, &chainParams.GenesisHash); err != nil {
+		return err
+	}
+
+	// Recover the spender index and its dependents to the main chain if
+	// needed.
+	return recover(ctx, idx)
+}
+
+// Key returns the database key to use for the index as a byte slice.
+//
+// This is part of the Indexer interface.
+func (idx *SpenderIndex) Key() []byte {
+	return spenderIndexKey
+}
+
+// Name returns the human-readable name of the index.
+//
+// This is part of the Indexer interface.
+func (idx *SpenderIndex) Name() string {
+	return spenderIndexName
+}
+
+// Version returns the current version of the index.
+//
+// This is part of the Indexer interface.
+func (idx *SpenderIndex) Version() uint32 {
+	return spenderIndexVersion
+}
+
+// DB returns the database of the index.
+//
+// This is part of the Indexer interface.
+func (idx *SpenderIndex) DB() database.DB {
+	return idx.db
+}
+
+// Queryer returns the chain queryer.
+//
+// This is part of the Indexer interface.
+func (idx *SpenderIndex) Queryer() ChainQueryer {
+	return idx.chain
+}
+
+// Tip returns the current tip of the index.
+//
+// This is part of the Indexer interface.
+func (idx *SpenderIndex) Tip() (int64, *chainhash.Hash, error) {
+	return tip(idx.db, idx.Key())
+}
+
+// Create is invoked when the index is created for the first time.  It creates
+// the bucket for the spender index.
+//
+// This is part of the Indexer interface.
+func (idx *SpenderIndex) Create(dbTx database.Tx) error {
+	_, err := dbTx.Metadata().CreateBucket(spenderIndexKey)
+	return err
+}
+
+// IndexSubscription returns the subscription for index updates.
+//
+// This is part of the Indexer interface.
+func (idx *SpenderIndex) IndexSubscription() *IndexSubscription {
+	return idx.sub
+}
+
+// Subscribers returns all client channels waiting for the next index update.
+//
+// This is part of the Indexer interface.
+func (idx *SpenderIndex) Subscribers() map[chan bool]struct{} {
+	idx.mtx.Lock()
+	defer idx.mtx.Unlock()
+	return fetchIndexerSubscribers(idx.subscribers)
+}
+
+// WaitForSync subscribes clients for the next index sync update.
+//
+// This is part of the Indexer interface.
+func (idx *SpenderIndex) WaitForSync() chan bool {
+	c := make(chan bool)
+
+	removeIndexerSub := func() {
+		idx.mtx.Lock()
+		delete(idx.subscribers, c)
+		idx.mtx.Unlock()
+	}
+
+	go purgeIndexerSubscription(c, removeIndexerSub)
+
+	idx.mtx.Lock()
+	idx.subscribers[c] = struct{}{}
+	idx.mtx.Unlock()
+
+	return c
+}
+
+// putSpenderEntries returns a function that adds a spender entry at the
+// provided height for every outpoint it is invoked with.
+func putSpenderEntries(spenderIndex database.Bucket, height int64) func(*wire.OutPoint, *chainhash.Hash, uint32) error {
+	entry := SpenderIndexEntry{BlockHeight: height}
+	return func(outpoint *wire.OutPoint, spender *chainhash.Hash, inputIdx uint32) error {
+		entry.SpenderHash = *spender
+		entry.InputIndex = inputIdx
+		return spenderIndex.Put(spenderIndexKeyForOutPoint(outpoint),
+			serializeSpenderIndexEntry(&entry))
+	}
+}
+
+// removeSpenderEntries returns a function that removes the spender entry for
+// every outpoint it is invoked with.  Only the entries that still refer to the
+// provided spender are removed so that a later spender that replaced them is
+// not removed.
+func removeSpenderEntries(dbTx database.Tx, spenderIndex database.Bucket) func(*wire.OutPoint, *chainhash.Hash, uint32) error {
+	return func(outpoint *wire.OutPoint, spender *chainhash.Hash, inputIdx uint32) error {
+		entry, err := dbFetchSpenderIndexEntry(dbTx, outpoint)
+		if err != nil {
+			return err
+		}
+		if entry == nil || entry.SpenderHash != *spender {
+			return nil
+		}
+		return spenderIndex.Delete(spenderIndexKeyForOutPoint(outpoint))
+	}
+}
+
+// connectBlock adds a spender entry for every output spent by the transactions
+// in the passed block and removes the entries for the outputs spent by the
+// regular tree of the parent block when the block disapproves it.
+func (idx *SpenderIndex) connectBlock(dbTx database.Tx, block, parent *dcrutil.Block, _ PrevScripter, _ bool) error {
+	// Remove the entries for the outputs spent by the regular tree of the
+	// parent block when the block disapproves it since those spends no longer
+	// apply and the outputs are spendable again.  This is done before adding
+	// the entries for the block since it may spend the same outputs again.
+	spenderIndex := dbTx.Metadata().Bucket(spenderIndexKey)
+	if disapprovesParent(block, parent) {
+		err := forEachSpentOutPointInTxns(parent.Transactions(),
+			removeSpenderEntries(dbTx, spenderIndex))
+		if err != nil {
+			return err
+		}
+	}
+
+	err := forEachSpentOutPoint(block, putSpenderEntries(spenderIndex,
+		block.Height()))
+	if err != nil {
+		return err
+	}
+
+	// Update the current index tip.
+	return dbPutIndexerTip(dbTx, idx.Key(), block.Hash(), int32(block.Height()))
+}
+
+// disconnectBlock removes the spender entry for every output spent by the
+// transactions in the passed block and restores the entries for the outputs
+// spent by the regular tree of the parent block when the block disapproved it.
+func (idx *SpenderIndex) disconnectBlock(dbTx database.Tx, block, parent *dcrutil.Block, _ PrevScripter, _ bool) error {
+	spenderIndex := dbTx.Metadata().Bucket(spenderIndexKey)
+	err := forEachSpentOutPoint(block, removeSpenderEntries(dbTx,
+		spenderIndex))
+	if err != nil {
+		return err
+	}
+
+	// Restore the entries for the outputs spent by the regular tree of the
+	// parent block when the block disapproved it.  This is done after
+	// removing the entries for the block since it may have spent the same
+	// outputs again.
+	if disapprovesParent(block, parent) {
+		err := forEachSpentOutPointInTxns(parent.Transactions(),
+			putSpenderEntries(spenderIndex, parent.Height()))
+		if err != nil {
+			return err
+		}
+	}
+
+	// Update the current index tip.
+	return dbPutIndexerTip(dbTx, idx.Key(), &block.MsgBlock().Header.PrevBlock,
+		int32(block.Height()-1))
+}
+
+// Entry returns details about the transaction that spends the provided outpoint
+// from the spender index.  When the outpoint has not been spent in the main
+// chain, nil will be returned for both the entry and the error.
+//
+// This function is safe for concurrent access.
+func (idx *SpenderIndex) Entry(outpoint *wire.OutPoint) (*SpenderIndexEntry, error) {
+	var entry *SpenderIndexEntry
+	err := idx.db.View(func(dbTx database.Tx) error {
+		var err error
+		entry, err = dbFetchSpenderIndexEntry(dbTx, outpoint)
+		return err
+	})
+	return entry, err
+}
+
+// NewSpenderIndex returns a new instance of an indexer that is used to create a
+// mapping of all spent outputs in the blockchain to the transactions that spend
+// them.
+func NewSpenderIndex(subscriber *IndexSubscriber, db database.DB, chain ChainQueryer) (*SpenderIndex, error) {
+	idx := &SpenderIndex{
+		db:          db,
+		chain:       chain,
+		subscribers: make(map[chan bool]struct{}),
+		cancel:      subscriber.cancel,
+	}
+
+	// The spender index is an optional index. It has no prerequisite and is
+	// updated asynchronously.  It does not consume spend journal data since
+	// the outpoints spent by a block are available from the block itself.
+	sub, err := subscriber.Subscribe(idx, noPrereqs)
+	if err != nil {
+		return nil, err
+	}
+
+	idx.sub = sub
+
+	err = idx.Init(subscriber.ctx, chain.ChainParams())
+	if err != nil {
+		return nil, err
+	}
+
+	return idx, nil
+}
+
+// DropSpenderIndex drops the spender index from the provided database if it
+// exists.
+func DropSpenderIndex(ctx context.Context, db database.DB) error {
+	return dropFlatIndex(ctx, db, spenderIndexKey, spenderIndexName)
+}
+
+// DropIndex drops the spender index from the provided database if it exists.
+func (*SpenderIndex) DropIndex(ctx context.Context, db database.DB) error {
+	return DropSpenderIndex(ctx, db)
+}
+
+// ProcessNotification indexes the provided notification based on its
+// notification type.
+//
+// This is part of the Indexer interface.
+func (idx *SpenderIndex) ProcessNotification(dbTx database.Tx, ntfn *IndexNtfn) error {
+	switch ntfn.NtfnType {
+	case ConnectNtfn:
+		err := idx.connectBlock(dbTx, ntfn.Block, ntfn.Parent,
+			ntfn.PrevScripts, ntfn.IsTreasuryEnabled)
+		if err != nil {
+			msg := fmt.Sprintf("%s: unable to connect block: %v",
+				idx.Name(), err)
+			return indexerError(ErrConnectBlock, msg)
+		}
+
+	case DisconnectNtfn:
+		err := idx.disconnectBlock(dbTx, ntfn.Block, ntfn.Parent,
+			ntfn.PrevScripts, ntfn.IsTreasuryEnabled)
+		if err != nil {
+			msg := fmt.Sprintf("%s: unable to disconnect block: %v",
+				idx.Name(), err)
+			return indexerError(ErrDisconnectBlock, msg)
+		}
+
+	default:
+		msg := fmt.Sprintf("%s: unknown notification type provided: %d",
+			idx.Name(), ntfn.NtfnType)
+		return indexerError(ErrInvalidNotificationType, msg)
+	}
+
+	return nil
+}
@@ -0,0 +1,265 @@
+// Copyright (c) 2023 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package indexers
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"reflect"
+	"testing"
+
+	"github.com/EXCCoin/exccd/blockchain/v4/chaingen"
+	"github.com/EXCCoin/exccd/chaincfg/chainhash"
+	"github.com/EXCCoin/exccd/chaincfg/v3"
+	"github.com/EXCCoin/exccd/dcrutil/v4"
+	"github.com/EXCCoin/exccd/wire"
+)
+
+// TestSpenderIndexSerialization ensures serializing and deserializing spender
+// index keys and entries works as expected.
+func TestSpenderIndexSerialization(t *testing.T) {
+	t.Parallel()
+
+	hexToBytes := func(s string) []byte {
+		b, err := hex.DecodeString(s)
+		if err != nil {
+			t.Fatalf("invalid hex in source file: %q", s)
+		}
+		return b
+	}
+	hashFromStr := func(s string) chainhash.Hash {
+		hash, err := chainhash.NewHashFromStr(s)
+		if err != nil {
+			t.Fatalf("invalid hash in source file: %q", s)
+		}
+		return *hash
+	}
+
+	tests := []struct {
+		name       string
+		outpoint   wire.OutPoint
+		key        []byte
+		entry      SpenderIndexEntry
+		serialized []byte
+	}{{
+		name: "first input in block at height 1",
+		outpoint: wire.OutPoint{
+			Hash:  hashFromStr("0e7e2ddab4e6fef4a8b1e00a04fa4e45a4bd0b6a4f9ad9e2a2b7e1f1c7a29f30"),
+			Index: 0,
+		},
+		key: hexToBytes("309fa2c7f1e1b7a2e2d99a4f6a0bbda4454efa040ae0b1a8f4fee6b4da2d7e0e00000000"),
+		entry: SpenderIndexEntry{
+			SpenderHash: hashFromStr("4bc5f9cd5b2e6f8b2a7a97fd07c7a5cc8b4f6f3b6e45cdbff2cd95cbe2b3a1f2"),
+			InputIndex:  0,
+			BlockHeight: 1,
+		},
+		serialized: hexToBytes("f2a1b3e2cb95cdf2bfcd456e3b6f4f8bcca5c707fd977a2a8b6f2e5bcdf9c54b0000000001000000"),
+	}, {
+		name: "later input in block at large height",
+		outpoint: wire.OutPoint{
+			Hash:  hashFromStr("0e7e2ddab4e6fef4a8b1e00a04fa4e45a4bd0b6a4f9ad9e2a2b7e1f1c7a29f30"),
+			Index: 258,
+			Tree:  wire.TxTreeStake,
+		},
+		key: hexToBytes("309fa2c7f1e1b7a2e2d99a4f6a0bbda4454efa040ae0b1a8f4fee6b4da2d7e0e02010000"),
+		entry: SpenderIndexEntry{
+			SpenderHash: hashFromStr("4bc5f9cd5b2e6f8b2a7a97fd07c7a5cc8b4f6f3b6e45cdbff2cd95cbe2b3a1f2"),
+			InputIndex:  3,
+			BlockHeight: 778899,
+		},
+		serialized: hexToBytes("f2a1b3e2cb95cdf2bfcd456e3b6f4f8bcca5c707fd977a2a8b6f2e5bcdf9c54b0300000093e20b00"),
+	}}
+
+	for _, test := range tests {
+		// Ensure the outpoint produces the expected key.
+		gotKey := spenderIndexKeyForOutPoint(&test.outpoint)
+		if !bytes.Equal(gotKey, test.key) {
+			t.Errorf("%q: mismatched key - got %x, want %x", test.name,
+				gotKey, test.key)
+			continue
+		}
+
+		// Ensure the entry serializes to the expected value.
+		gotBytes := serializeSpenderIndexEntry(&test.entry)
+		if !bytes.Equal(gotBytes, test.serialized) {
+			t.Errorf("%q: mismatched bytes - got %x, want %x", test.name,
+				gotBytes, test.serialized)
+			continue
+		}
+
+		// Ensure the serialized bytes are decoded back to the expected entry.
+		entry, err := deserializeSpenderIndexEntry(test.serialized)
+		if err != nil {
+			t.Errorf("%q: unexpected error: %v", test.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(*entry, test.entry) {
+			t.Errorf("%q: mismatched entry - got %v, want %v", test.name,
+				*entry, test.entry)
+			continue
+		}
+	}
+
+	// Ensure entries with an invalid length are rejected.
+	for _, size := range []int{0, spenderEntrySize - 1, spenderEntrySize + 1} {
+		_, err := deserializeSpenderIndexEntry(make([]byte, size))
+		if !isDeserializeErr(err) {
+			t.Errorf("size %d: did not receive expected deserialize error "+
+				"- got %v", size, err)
+		}
+	}
+}
+
+// TestIsNullOutPoint ensures null outpoints referenced by inputs that do not
+// spend any outputs are detected as expected.
+func TestIsNullOutPoint(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		outpoint wire.OutPoint
+		want     bool
+	}{{
+		name:     "null outpoint",
+		outpoint: wire.OutPoint{Index: wire.MaxPrevOutIndex},
+		want:     true,
+	}, {
+		name:     "zero hash with zero index",
+		outpoint: wire.OutPoint{},
+		want:     false,
+	}, {
+		name: "non-zero hash with max index",
+		outpoint: wire.OutPoint{
+			Hash:  chainhash.Hash{0x01},
+			Index: wire.MaxPrevOutIndex,
+		},
+		want: false,
+	}}
+
+	for _, test := range tests {
+		got := isNullOutPoint(&test.outpoint)
+		if got != test.want {
+			t.Errorf("%q: unexpected result - got %v, want %v", test.name,
+				got, test.want)
+		}
+	}
+}
+
+// TestSpenderIndexConnectDisconnect ensures the spender index tracks the
+// spenders of outputs as blocks are connected and disconnected, including when
+// the regular transaction tree of a block is disapproved by the next block.
+func TestSpenderIndexConnectDisconnect(t *testing.T) {
+	db, path := setupDB(t, "test_spenderindex")
+	defer teardownDB(db, path)
+
+	chain, err := newTestChain()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	g, err := chaingen.MakeGenerator(chaincfg.SimNetParams(), nil)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	// Add two blocks to the chain and grab an output from the coinbase of the
+	// second one to spend.
+	bk1 := addBlock(t, chain, &g, "bk1")
+	bk2 := addBlock(t, chain, &g, "bk2")
+	spend := g.OldestCoinbaseOuts()[0]
+	outpoint := spend.PrevOut()
+
+	// Initialize the spender index.
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	subber := NewIndexSubscriber(ctx)
+	go subber.Run(ctx)
+
+	idx, err := NewSpenderIndex(subber, db, chain)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = subber.CatchUp(ctx, chain)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertIndexTip(t, idx, bk2)
+
+	// assertSpender ensures the spender of the output is the first input of
+	// the second transaction of the regular tree of the provided block, or
+	// that the output is not spent when the block is nil.
+	assertSpender := func(desc string, block *dcrutil.Block) {
+		t.Helper()
+
+		entry, err := idx.Entry(&outpoint)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", desc, err)
+		}
+		if block == nil {
+			if entry != nil {
+				t.Fatalf("%s: unexpected spender %s", desc,
+					entry.SpenderHash)
+			}
+			return
+		}
+		want := SpenderIndexEntry{
+			SpenderHash: *block.Transactions()[1].Hash(),
+			InputIndex:  0,
+			BlockHeight: block.Height(),
+		}
+		if entry == nil || *entry != want {
+			t.Fatalf("%s: unexpected spender -- got %+v, want %+v", desc,
+				entry, want)
+		}
+	}
+	assertSpender("before spend", nil)
+
+	// Connect a block that spends the output and ensure it is indexed.
+	bk3 := addSpendBlock(t, chain, &g, "bk3", &spend)
+	notifyConnect(t, subber, chain, bk3, bk2)
+	assertIndexTip(t, idx, bk3)
+	assertSpender("after spend", bk3)
+
+	// Connect a block that disapproves the regular tree of the block that
+	// spent the output and ensure the spend is removed.
+	bk4 := addSpendBlock(t, chain, &g, "bk4", nil, disapproveParent)
+	notifyConnect(t, subber, chain, bk4, bk3)
+	assertIndexTip(t, idx, bk4)
+	assertSpender("after disapproval", nil)
+
+	// Disconnect the disapproving block and ensure the spend is restored.
+	notifyDisconnect(t, subber, chain, bk4, bk3)
+	assertIndexTip(t, idx, bk3)
+	assertSpender("after disconnecting disapproval", bk3)
+
+	// Connect a block that both disapproves the regular tree of the block
+	// that spent the output and spends it again and ensure the new spend
+	// replaces the disapproved one.
+	g.SetTip("bk3")
+	bk4a := addSpendBlock(t, chain, &g, "bk4a", &spend, disapproveParent)
+	notifyConnect(t, subber, chain, bk4a, bk3)
+	assertIndexTip(t, idx, bk4a)
+	assertSpender("after respend", bk4a)
+
+	// Disconnect the block that spent the output again and ensure the
+	// disapproved spend is restored.
+	notifyDisconnect(t, subber, chain, bk4a, bk3)
+	assertIndexTip(t, idx, bk3)
+	assertSpender("after disconnecting respend", bk3)
+
+	// Disconnect the block that originally spent the output and ensure the
+	// output is no longer spent.
+	notifyDisconnect(t, subber, chain, bk3, bk2)
+	assertIndexTip(t, idx, bk2)
+	assertSpender("after disconnecting spend", nil)
+
+	// Ensure disconnecting blocks down to the first block does not remove
+	// anything unexpected and leaves the index at the expected tip.
+	notifyDisconnect(t, subber, chain, bk2, bk1)
+	assertIndexTip(t, idx, bk1)
+}
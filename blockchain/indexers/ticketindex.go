@@ -0,0 +1,765 @@
+// Copyright (c) 2023 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package indexers
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/EXCCoin/exccd/blockchain/stake/v4"
+	"github.com/EXCCoin/exccd/chaincfg/chainhash"
+	"github.com/EXCCoin/exccd/chaincfg/v3"
+	"github.com/EXCCoin/exccd/database/v3"
+	"github.com/EXCCoin/exccd/dcrutil/v4"
+	"github.com/EXCCoin/exccd/txscript/v4/stdaddr"
+	"github.com/EXCCoin/exccd/txscript/v4/stdscript"
+	"github.com/EXCCoin/exccd/wire"
+)
+
+const (
+	// ticketIndexName is the human-readable name for the index.
+	ticketIndexName = "ticket index"
+
+	// ticketIndexVersion is the current version of the ticket index.
+	ticketIndexVersion = 1
+
+	// The following constants define the prefixes of the different kinds of
+	// entries stored in the ticket index bucket.
+	ticketPrefix       = 't'
+	ticketAddrPrefix   = 'a'
+	ticketEventsPrefix = 'e'
+
+	// ticketKeySize is the size of a ticket key.  It consists of the 1 byte
+	// prefix and the 32 byte ticket hash.
+	ticketKeySize = 1 + chainhash.HashSize
+
+	// ticketEntrySize is the size of a ticket entry.  It consists of the 4
+	// byte purchase height, the 8 byte price, the 4 byte vote height, the 32
+	// byte vote hash, the 4 byte vote version, the 2 byte vote bits, the 4
+	// byte revocation height, the 32 byte revocation hash, the 4 byte missed
+	// height, and the 4 byte expired height.
+	ticketEntrySize = 4 + 8 + 4 + chainhash.HashSize + 4 + 2 + 4 +
+		chainhash.HashSize + 4 + 4
+
+	// ticketAddrKeySize is the size of a ticket address key.  It consists of
+	// the 1 byte prefix, the address key, the 4 byte purchase height, and the
+	// 32 byte ticket hash.
+	ticketAddrKeySize = 1 + addrKeySize + 4 + chainhash.HashSize
+
+	// ticketEventsKeySize is the size of a ticket events key.  It consists of
+	// the 1 byte prefix and the 4 byte block height.
+	ticketEventsKeySize = 1 + 4
+)
+
+var (
+	// ticketIndexKey is the key of the ticket index and the db bucket used to
+	// house it.
+	ticketIndexKey = []byte("ticketidx")
+)
+
+// -----------------------------------------------------------------------------
+// The ticket index tracks the lifecycle of every ticket purchased in the main
+// chain from its purchase through the vote or revocation that spends it along
+// with the blocks that missed or expired it.
+//
+// All entries are stored in a single flat bucket and are distinguished by a
+// one byte prefix.  Heights of zero in the ticket entries indicate the event
+// has not happened.  Fixed-width integers in the address and event keys are
+// big endian so they sort by height.
+//
+// The serialized format for the ticket entries is:
+//
+//   't'<ticket hash> = <purchase height><price><vote height><vote hash>
+//     <vote version><vote bits><revocation height><revocation hash>
+//     <missed height><expired height>
+//
+//   Field              Type              Size
+//   ticket hash        chainhash.Hash    32 bytes
+//   purchase height    uint32            4 bytes
+//   price              uint64            8 bytes
+//   vote height        uint32            4 bytes
+//   vote hash          chainhash.Hash    32 bytes
+//   vote version       uint32            4 bytes
+//   vote bits          uint16            2 bytes
+//   revocation height  uint32            4 bytes
+//   revocation hash    chainhash.Hash    32 bytes
+//   missed height      uint32            4 bytes
+//   expired height     uint32            4 bytes
+//   -----
+//   Total: 131 bytes
+//
+// The serialized format for the address entries, which exist for the voting
+// address and every commitment address of a ticket, is:
+//
+//   'a'<addr key><purchase height><ticket hash> = nil
+//
+//   Field              Type              Size
+//   addr key           [21]byte          21 bytes
+//   purchase height    uint32            4 bytes
+//   ticket hash        chainhash.Hash    32 bytes
+//   -----
+//   Total: 58 bytes
+//
+// The serialized format for the event entries, which record the tickets that
+// were missed or expired by a block so they can be restored when the block is
+// disconnected, is:
+//
+//   'e'<block height> = <ticket hash>...
+//
+//   Field              Type              Size
+//   block height       uint32            4 bytes
+//   ticket hashes      []chainhash.Hash  32 bytes each
+// -----------------------------------------------------------------------------
+
+// TicketEntry houses information about the lifecycle of a ticket in the ticket
+// index.  Heights of zero indicate the associated event has not happened.
+type TicketEntry struct {
+	// Hash is the hash of the ticket purchase transaction.
+	Hash chainhash.Hash
+
+	// PurchaseHeight is the height of the block that contains the ticket and
+	// Price is the amount of the ticket.
+	PurchaseHeight int64
+	Price          int64
+
+	// VoteHeight, VoteHash, VoteVersion, and VoteBits describe the vote that
+	// spent the ticket.
+	VoteHeight  int64
+	VoteHash    chainhash.Hash
+	VoteVersion uint32
+	VoteBits    uint16
+
+	// RevocationHeight and RevocationHash describe the revocation that spent
+	// the ticket.
+	RevocationHeight int64
+	RevocationHash   chainhash.Hash
+
+	// MissedHeight is the height of the block that missed the ticket when it
+	// was selected to vote and ExpiredHeight is the height of the block that
+	// expired the ticket.
+	MissedHeight  int64
+	ExpiredHeight int64
+}
+
+// ticketKey returns the ticket index key for the provided ticket hash.
+func ticketKey(hash *chainhash.Hash) []byte {
+	key := make([]byte, ticketKeySize)
+	key[0] = ticketPrefix
+	copy(key[1:], hash[:])
+	return key
+}
+
+// serializeTicketEntry returns the serialization of the provided ticket entry.
+func serializeTicketEntry(entry *TicketEntry) []byte {
+	serialized := make([]byte, ticketEntrySize)
+	offset := 0
+	putUint32 := func(v int64) {
+		byteOrder.PutUint32(serialized[offset:], uint32(v))
+		offset += 4
+	}
+	putHash := func(hash *chainhash.Hash) {
+		copy(serialized[offset:], hash[:])
+		offset += chainhash.HashSize
+	}
+	putUint32(entry.PurchaseHeight)
+	byteOrder.PutUint64(serialized[offset:], uint64(entry.Price))
+	offset += 8
+	putUint32(entry.VoteHeight)
+	putHash(&entry.VoteHash)
+	putUint32(int64(entry.VoteVersion))
+	byteOrder.PutUint16(serialized[offset:], entry.VoteBits)
+	offset += 2
+	putUint32(entry.RevocationHeight)
+	putHash(&entry.RevocationHash)
+	putUint32(entry.MissedHeight)
+	putUint32(entry.ExpiredHeight)
+	return serialized
+}
+
+// deserializeTicketEntry decodes the provided serialized ticket entry of the
+// provided ticket hash.
+func deserializeTicketEntry(hash *chainhash.Hash, serialized []byte) (*TicketEntry, error) {
+	if len(serialized) != ticketEntrySize {
+		return nil, errDeserialize(fmt.Sprintf("unexpected ticket entry "+
+			"length %d", len(serialized)))
+	}
+
+	offset := 0
+	uint32At := func() int64 {
+		v := byteOrder.Uint32(serialized[offset:])
+		offset += 4
+		return int64(v)
+	}
+	hashAt := func(hash *chainhash.Hash) {
+		copy(hash[:], serialized[offset:offset+chainhash.HashSize])
+		offset += chainhash.HashSize
+	}
+	entry := TicketEntry{Hash: *hash}
+	entry.PurchaseHeight = uint32At()
+	entry.Price = int64(byteOrder.Uint64(serialized[offset:]))
+	offset += 8
+	entry.VoteHeight = uint32At()
+	hashAt(&entry.VoteHash)
+	entry.VoteVersion = uint32(uint32At())
+	entry.VoteBits = byteOrder.Uint16(serialized[offset:])
+	offset += 2
+	entry.RevocationHeight = uint32At()
+	hashAt(&entry.RevocationHash)
+	entry.MissedHeight = uint32At()
+	entry.ExpiredHeight = uint32At()
+	return &entry, nil
+}
+
+// ticketAddrKey returns the ticket index address key for the provided address
+// key, purchase height, and ticket hash.
+func ticketAddrKey(addrKey [addrKeySize]byte, purchaseHeight int64, hash *chainhash.Hash) []byte {
+	key := make([]byte, ticketAddrKeySize)
+	key[0] = ticketAddrPrefix
+	offset := 1
+	copy(key[offset:], addrKey[:])
+	offset += addrKeySize
+	sortableOrder.PutUint32(key[offset:], uint32(purchaseHeight))
+	offset += 4
+	copy(key[offset:], hash[:])
+	return key
+}
+
+// ticketEventsKey returns the ticket index events key for the provided block
+// height.
+func ticketEventsKey(height int64) []byte {
+	key := make([]byte, ticketEventsKeySize)
+	key[0] = ticketEventsPrefix
+	sortableOrder.PutUint32(key[1:], uint32(height))
+	return key
+}
+
+// dbFetchTicketEntry uses an existing database transaction to fetch the entry
+// for the provided ticket hash from the ticket index.  When there is no entry
+// for the provided ticket, nil will be returned for both the entry and the
+// error.
+func dbFetchTicketEntry(dbTx database.Tx, hash *chainhash.Hash) (*TicketEntry, error) {
+	ticketIndex := dbTx.Metadata().Bucket(ticketIndexKey)
+	serialized := ticketIndex.Get(ticketKey(hash))
+	if serialized == nil {
+		return nil, nil
+	}
+
+	entry, err := deserializeTicketEntry(hash, serialized)
+	if err != nil {
+		str := fmt.Sprintf("corrupt ticket index entry for %v: %v", hash,
+			err)
+		return nil, makeDbErr(database.ErrCorruption, str)
+	}
+	return entry, nil
+}
+
+// dbUpdateTicketEntry uses an existing database transaction to apply the
+// provided function to the entry for the provided ticket hash in the ticket
+// index and store the result.  An error is returned when there is no entry for
+// the ticket since every ticket spent, missed, or expired in the main chain
+// must have been purchased in it.
+func dbUpdateTicketEntry(dbTx database.Tx, hash *chainhash.Hash, fn func(entry *TicketEntry)) error {
+	entry, err := dbFetchTicketEntry(dbTx, hash)
+	if err != nil {
+		return err
+	}
+	if entry == nil {
+		str := fmt.Sprintf("missing ticket index entry for %v", hash)
+		return makeDbErr(database.ErrCorruption, str)
+	}
+
+	fn(entry)
+	ticketIndex := dbTx.Metadata().Bucket(ticketIndexKey)
+	return ticketIndex.Put(ticketKey(hash), serializeTicketEntry(entry))
+}
+
+// ticketAddrKeys returns the address keys of the voting address and the
+// commitment addresses of the provided ticket purchase without duplicates.
+// Addresses that are not supported are ignored.
+func ticketAddrKeys(tx *wire.MsgTx, params stdaddr.AddressParams) [][addrKeySize]byte {
+	var addrKeys [][addrKeySize]byte
+	addAddrKey := func(addr stdaddr.Address) {
+		addrKey, err := addrToKey(addr)
+		if err != nil {
+			return
+		}
+		for _, key := range addrKeys {
+			if key == addrKey {
+				return
+			}
+		}
+		addrKeys = append(addrKeys, addrKey)
+	}
+
+	for txOutIdx, txOut := range tx.TxOut {
+		if txOutIdx == 0 {
+			_, addrs := stdscript.ExtractAddrs(txOut.Version, txOut.PkScript,
+				params)
+			for _, addr := range addrs {
+				addAddrKey(addr)
+			}
+			continue
+		}
+		if !stake.IsStakeCommitmentTxOut(txOutIdx) {
+			continue
+		}
+		addr, err := stake.AddrFromSStxPkScrCommitment(txOut.PkScript, params)
+		if err != nil {
+			continue
+		}
+		addAddrKey(addr)
+	}
+
+	return addrKeys
+}
+
+// TicketIndex implements a ticket lifecycle index.  That is to say, it supports
+// querying when any given ticket in the main chain was purchased, voted,
+// revoked, missed, or expired as well as the tickets associated with an
+// address.
+type TicketIndex struct {
+	// The following fields are set when the instance is created and can't
+	// be changed afterwards, so there is no need to protect them with a
+	// separate mutex.
+	db          database.DB
+	chain       ChainQueryer
+	chainParams *chaincfg.Params
+	sub         *IndexSubscription
+
+	subscribers map[chan bool]struct{}
+	mtx         sync.Mutex
+	cancel      context.CancelFunc
+}
+
+// Ensure the TicketIndex type implements the Indexer interface.
+var _ Indexer = (*TicketIndex)(nil)
+
+// Init initializes the ticket index.
+//
+// This is part of the Indexer interface.
+func (idx *TicketIndex) Init(ctx context.Context, chainParams *chaincfg.Params) error {
+	if interruptRequested(ctx) {
+		return indexerError(ErrInterruptRequested, interruptMsg)
+	}
+
+	// Finish any drops that were previously interrupted.
+	if err := finishDrop(ctx, idx); err != nil {
+		return err
+	}
+
+	// Create the initial state for the index as needed.
+	if err := createIndex(idx, &chainParams.GenesisHash); err != nil {
+		return err
+	}
+
+	// Upgrade the index as needed.
+	if err := upgradeIndex(ctx, idx, &chainParams.GenesisHash); err != nil {
+		return err
+	}
+
+	// Recover the ticket index and its dependents to the main chain if
+	// needed.
+	return recover(ctx, idx)
+}
+
+// Key returns the database key to use for the index as a byte slice.
+//
+// This is part of the Indexer interface.
+func (idx *TicketIndex) Key() []byte {
+	return ticketIndexKey
+}
+
+// Name returns the human-readable name of the index.
+//
+// This is part of the Indexer interface.
+func (idx *TicketIndex) Name() string {
+	return ticketIndexName
+}
+
+// Version returns the current version of the index.
+//
+// This is part of the Indexer interface.
+func (idx *TicketIndex) Version() uint32 {
+	return ticketIndexVersion
+}
+
+// DB returns the database of the index.
+//
+// This is part of the Indexer interface.
+func (idx *TicketIndex) DB() database.DB {
+	return idx.db
+}
+
+// Queryer returns the chain queryer.
+//
+// This is part of the Indexer interface.
+func (idx *TicketIndex) Queryer() ChainQueryer {
+	return idx.chain
+}
+
+// Tip returns the current tip of the index.
+//
+// This is part of the Indexer interface.
+func (idx *TicketIndex) Tip() (int64, *chainhash.Hash, error) {
+	return tip(idx.db, idx.Key())
+}
+
+// Create is invoked when the index is created for the first time.  It creates
+// the bucket for the ticket index.
+//
+// This is part of the Indexer interface.
+func (idx *TicketIndex) Create(dbTx database.Tx) error {
+	_, err := dbTx.Metadata().CreateBucket(ticketIndexKey)
+	return err
+}
+
+// IndexSubscription returns the subscription for index updates.
+//
+// This is part of the Indexer interface.
+func (idx *TicketIndex) IndexSubscription() *IndexSubscription {
+	return idx.sub
+}
+
+// Subscribers returns all client channels waiting for the next index update.
+//
+// This is part of the Indexer interface.
+func (idx *TicketIndex) Subscribers() map[chan bool]struct{} {
+	idx.mtx.Lock()
+	defer idx.mtx.Unlock()
+	return fetchIndexerSubscribers(idx.subscribers)
+}
+
+// WaitForSync subscribes clients for the next index sync update.
+//
+// This is part of the Indexer interface.
+func (idx *TicketIndex) WaitForSync() chan bool {
+	c := make(chan bool)
+
+	removeIndexerSub := func() {
+		idx.mtx.Lock()
+		delete(idx.subscribers, c)
+		idx.mtx.Unlock()
+	}
+
+	go purgeIndexerSubscription(c, removeIndexerSub)
+
+	idx.mtx.Lock()
+	idx.subscribers[c] = struct{}{}
+	idx.mtx.Unlock()
+
+	return c
+}
+
+// stakeTxType returns the type of the provided stake transaction.
+func stakeTxType(tx *wire.MsgTx) stake.TxType {
+	// It is safe to use the version as a proxy for treasury activation here
+	// for the same reasons detailed by stake.FindSpentTicketsInBlock.  Also,
+	// the automatic ticket revocations agenda only adds rules that
+	// revocations which are already in a block satisfy, so it is not
+	// considered.
+	return stake.DetermineTxType(tx, tx.Version >= 3, false)
+}
+
+// connectBlock records the tickets purchased by the passed block along with
+// the tickets it voted, revoked, missed, and expired.
+func (idx *TicketIndex) connectBlock(dbTx database.Tx, block *dcrutil.Block, missed, expired []chainhash.Hash) error {
+	ticketIndex := dbTx.Metadata().Bucket(ticketIndexKey)
+	height := block.Height()
+	for _, stx := range block.STransactions() {
+		msgTx := stx.MsgTx()
+		switch stakeTxType(msgTx) {
+		case stake.TxTypeSStx:
+			entry := TicketEntry{
+				Hash:           *stx.Hash(),
+				PurchaseHeight: height,
+				Price:          msgTx.TxOut[0].Value,
+			}
+			err := ticketIndex.Put(ticketKey(stx.Hash()),
+				serializeTicketEntry(&entry))
+			if err != nil {
+				return err
+			}
+			for _, addrKey := range ticketAddrKeys(msgTx, idx.chainParams) {
+				key := ticketAddrKey(addrKey, height, stx.Hash())
+				if err := ticketIndex.Put(key, nil); err != nil {
+					return err
+				}
+			}
+
+		case stake.TxTypeSSGen:
+			ticketHash := &msgTx.TxIn[1].PreviousOutPoint.Hash
+			err := dbUpdateTicketEntry(dbTx, ticketHash, func(entry *TicketEntry) {
+				entry.VoteHeight = height
+				entry.VoteHash = *stx.Hash()
+				entry.VoteVersion = stake.SSGenVersion(msgTx)
+				entry.VoteBits = stake.SSGenVoteBits(msgTx)
+			})
+			if err != nil {
+				return err
+			}
+
+		case stake.TxTypeSSRtx:
+			ticketHash := &msgTx.TxIn[0].PreviousOutPoint.Hash
+			err := dbUpdateTicketEntry(dbTx, ticketHash, func(entry *TicketEntry) {
+				entry.RevocationHeight = height
+				entry.RevocationHash = *stx.Hash()
+			})
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	// Record the missed and expired tickets along with an event entry for the
+	// block so they can be restored when it is disconnected.
+	if len(missed)+len(expired) > 0 {
+		events := make([]byte, 0, (len(missed)+len(expired))*chainhash.HashSize)
+		for i := range missed {
+			err := dbUpdateTicketEntry(dbTx, &missed[i], func(entry *TicketEntry) {
+				entry.MissedHeight = height
+			})
+			if err != nil {
+				return err
+			}
+			events = append(events, missed[i][:]...)
+		}
+		for i := range expired {
+			err := dbUpdateTicketEntry(dbTx, &expired[i], func(entry *TicketEntry) {
+				entry.ExpiredHeight = height
+			})
+			if err != nil {
+				return err
+			}
+			events = append(events, expired[i][:]...)
+		}
+		if err := ticketIndex.Put(ticketEventsKey(height), events); err != nil {
+			return err
+		}
+	}
+
+	// Update the current index tip.
+	return dbPutIndexerTip(dbTx, idx.Key(), block.Hash(), int32(height))
+}
+
+// disconnectBlock removes the tickets purchased by the passed block and undoes
+// the votes, revocations, misses, and expirations it recorded.
+func (idx *TicketIndex) disconnectBlock(dbTx database.Tx, block *dcrutil.Block) error {
+	ticketIndex := dbTx.Metadata().Bucket(ticketIndexKey)
+	height := block.Height()
+
+	// Undo the misses and expirations recorded for the block.
+	eventsKey := ticketEventsKey(height)
+	events := ticketIndex.Get(eventsKey)
+	if len(events)%chainhash.HashSize != 0 {
+		str := fmt.Sprintf("corrupt ticket events entry for height %d",
+			height)
+		return makeDbErr(database.ErrCorruption, str)
+	}
+	for offset := 0; offset < len(events); offset += chainhash.HashSize {
+		var ticketHash chainhash.Hash
+		copy(ticketHash[:], events[offset:offset+chainhash.HashSize])
+		err := dbUpdateTicketEntry(dbTx, &ticketHash, func(entry *TicketEntry) {
+			if entry.MissedHeight == height {
+				entry.MissedHeight = 0
+			}
+			if entry.ExpiredHeight == height {
+				entry.ExpiredHeight = 0
+			}
+		})
+		if err != nil {
+			return err
+		}
+	}
+	if err := ticketIndex.Delete(eventsKey); err != nil {
+		return err
+	}
+
+	// Undo the effects of the stake transactions in reverse order.
+	stxns := block.STransactions()
+	for i := len(stxns) - 1; i >= 0; i-- {
+		stx := stxns[i]
+		msgTx := stx.MsgTx()
+		switch stakeTxType(msgTx) {
+		case stake.TxTypeSStx:
+			for _, addrKey := range ticketAddrKeys(msgTx, idx.chainParams) {
+				key := ticketAddrKey(addrKey, height, stx.Hash())
+				if err := ticketIndex.Delete(key); err != nil {
+					return err
+				}
+			}
+			if err := ticketIndex.Delete(ticketKey(stx.Hash())); err != nil {
+				return err
+			}
+
+		case stake.TxTypeSSGen:
+			ticketHash := &msgTx.TxIn[1].PreviousOutPoint.Hash
+			err := dbUpdateTicketEntry(dbTx, ticketHash, func(entry *TicketEntry) {
+				entry.VoteHeight = 0
+				entry.VoteHash = chainhash.Hash{}
+				entry.VoteVersion = 0
+				entry.VoteBits = 0
+			})
+			if err != nil {
+				return err
+			}
+
+		case stake.TxTypeSSRtx:
+			ticketHash := &msgTx.TxIn[0].PreviousOutPoint.Hash
+			err := dbUpdateTicketEntry(dbTx, ticketHash, func(entry *TicketEntry) {
+				entry.RevocationHeight = 0
+				entry.RevocationHash = chainhash.Hash{}
+			})
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	// Update the current index tip.
+	return dbPutIndexerTip(dbTx, idx.Key(), &block.MsgBlock().Header.PrevBlock,
+		int32(height-1))
+}
+
+// Entry returns the lifecycle details of the provided ticket from the ticket
+// index.  When the ticket has not been purchased in the main chain, nil will be
+// returned for both the entry and the error.
+//
+// This function is safe for concurrent access.
+func (idx *TicketIndex) Entry(hash *chainhash.Hash) (*TicketEntry, error) {
+	var entry *TicketEntry
+	err := idx.db.View(func(dbTx database.Tx) error {
+		var err error
+		entry, err = dbFetchTicketEntry(dbTx, hash)
+		return err
+	})
+	return entry, err
+}
+
+// TicketsForAddress returns the lifecycle details of the tickets that commit
+// to the provided address either as the voting address or as a commitment
+// address ordered by purchase height according to the specified number to skip
+// and number requested.  It also returns the number actually skipped since it
+// could be less in the case where there are not enough entries.
+//
+// This function is safe for concurrent access.
+func (idx *TicketIndex) TicketsForAddress(addr stdaddr.Address, numToSkip, numRequested uint32) ([]TicketEntry, uint32, error) {
+	addrKey, err := addrToKey(addr)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var entries []TicketEntry
+	var skipped uint32
+	err = idx.db.View(func(dbTx database.Tx) error {
+		seek := make([]byte, 1+addrKeySize)
+		seek[0] = ticketAddrPrefix
+		copy(seek[1:], addrKey[:])
+
+		cursor := dbTx.Metadata().Bucket(ticketIndexKey).Cursor()
+		for ok := cursor.Seek(seek); ok && uint32(len(entries)) < numRequested; ok = cursor.Next() {
+			key := cursor.Key()
+			if !bytes.HasPrefix(key, seek) {
+				break
+			}
+			if skipped < numToSkip {
+				skipped++
+				continue
+			}
+			if len(key) != ticketAddrKeySize {
+				str := fmt.Sprintf("corrupt ticket address entry for %x",
+					addrKey)
+				return makeDbErr(database.ErrCorruption, str)
+			}
+
+			var ticketHash chainhash.Hash
+			copy(ticketHash[:], key[ticketAddrKeySize-chainhash.HashSize:])
+			entry, err := dbFetchTicketEntry(dbTx, &ticketHash)
+			if err != nil {
+				return err
+			}
+			if entry == nil {
+				str := fmt.Sprintf("missing ticket index entry for %v",
+					ticketHash)
+				return makeDbErr(database.ErrCorruption, str)
+			}
+			entries = append(entries, *entry)
+		}
+		return nil
+	})
+	return entries, skipped, err
+}
+
+// NewTicketIndex returns a new instance of an indexer that is used to track the
+// lifecycle of all tickets in the blockchain.
+func NewTicketIndex(subscriber *IndexSubscriber, db database.DB, chain ChainQueryer) (*TicketIndex, error) {
+	idx := &TicketIndex{
+		db:          db,
+		chain:       chain,
+		chainParams: chain.ChainParams(),
+		subscribers: make(map[chan bool]struct{}),
+		cancel:      subscriber.cancel,
+	}
+
+	// The ticket index is an optional index. It has no prerequisite and is
+	// updated asynchronously.
+	sub, err := subscriber.Subscribe(idx, noPrereqs)
+	if err != nil {
+		return nil, err
+	}
+
+	idx.sub = sub
+
+	err = idx.Init(subscriber.ctx, idx.chainParams)
+	if err != nil {
+		return nil, err
+	}
+
+	return idx, nil
+}
+
+// DropTicketIndex drops the ticket index from the provided database if it
+// exists.
+func DropTicketIndex(ctx context.Context, db database.DB) error {
+	return dropFlatIndex(ctx, db, ticketIndexKey, ticketIndexName)
+}
+
+// DropIndex drops the ticket index from the provided database if it exists.
+func (*TicketIndex) DropIndex(ctx context.Context, db database.DB) error {
+	return DropTicketIndex(ctx, db)
+}
+
+// ProcessNotification indexes the provided notification based on its
+// notification type.
+//
+// This is part of the Indexer interface.
+func (idx *TicketIndex) ProcessNotification(dbTx database.Tx, ntfn *IndexNtfn) error {
+	switch ntfn.NtfnType {
+	case ConnectNtfn:
+		err := idx.connectBlock(dbTx, ntfn.Block, ntfn.TicketsMissed,
+			ntfn.TicketsExpired)
+		if err != nil {
+			msg := fmt.Sprintf("%s: unable to connect block: %v",
+				idx.Name(), err)
+			return indexerError(ErrConnectBlock, msg)
+		}
+
+	case DisconnectNtfn:
+		err := idx.disconnectBlock(dbTx, ntfn.Block)
+		if err != nil {
+			msg := fmt.Sprintf("%s: unable to disconnect block: %v",
+				idx.Name(), err)
+			return indexerError(ErrDisconnectBlock, msg)
+		}
+
+	default:
+		msg := fmt.Sprintf("%s: unknown notification type provided: %d",
+			idx.Name(), ntfn.NtfnType)
+		return indexerError(ErrInvalidNotificationType, msg)
+	}
+
+	return nil
+}
@@ -0,0 +1,368 @@
+// Copyright (c) 2023 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package indexers
+
+import (
+	"bytes"
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/EXCCoin/exccd/blockchain/stake/v4"
+	"github.com/EXCCoin/exccd/blockchain/v4/chaingen"
+	"github.com/EXCCoin/exccd/chaincfg/chainhash"
+	"github.com/EXCCoin/exccd/chaincfg/v3"
+	"github.com/EXCCoin/exccd/dcrutil/v4"
+	"github.com/EXCCoin/exccd/wire"
+)
+
+// TestTicketEntrySerialization ensures serializing and deserializing ticket
+// index entries works as expected.
+func TestTicketEntrySerialization(t *testing.T) {
+	t.Parallel()
+
+	entry := TicketEntry{
+		Hash:             chainhash.Hash{0x01},
+		PurchaseHeight:   100,
+		Price:            14428162590,
+		VoteHeight:       400,
+		VoteHash:         chainhash.Hash{0x02},
+		VoteVersion:      8,
+		VoteBits:         0x0005,
+		RevocationHeight: 0,
+		RevocationHash:   chainhash.Hash{},
+		MissedHeight:     390,
+		ExpiredHeight:    0,
+	}
+	serialized := serializeTicketEntry(&entry)
+	if len(serialized) != ticketEntrySize {
+		t.Fatalf("unexpected serialized length - got %d, want %d",
+			len(serialized), ticketEntrySize)
+	}
+	decoded, err := deserializeTicketEntry(&entry.Hash, serialized)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(*decoded, entry) {
+		t.Fatalf("mismatched entry - got %+v, want %+v", *decoded, entry)
+	}
+
+	// Ensure entries with an invalid length are rejected.
+	for _, size := range []int{0, ticketEntrySize - 1, ticketEntrySize + 1} {
+		_, err := deserializeTicketEntry(&entry.Hash, make([]byte, size))
+		if !isDeserializeErr(err) {
+			t.Errorf("size %d: did not receive expected deserialize error "+
+				"- got %v", size, err)
+		}
+	}
+}
+
+// TestTicketIndexKeys ensures the ticket index address and event keys have the
+// expected size and sort by height.
+func TestTicketIndexKeys(t *testing.T) {
+	t.Parallel()
+
+	var addrKey [addrKeySize]byte
+	addrKey[0] = 0x01
+	hash := chainhash.Hash{0xff}
+	lowKey := ticketAddrKey(addrKey, 255, &hash)
+	highKey := ticketAddrKey(addrKey, 256, &chainhash.Hash{})
+	if len(lowKey) != ticketAddrKeySize {
+		t.Fatalf("unexpected address key length - got %d, want %d",
+			len(lowKey), ticketAddrKeySize)
+	}
+	if bytes.Compare(lowKey, highKey) >= 0 {
+		t.Fatalf("address key for height 255 does not sort before the key " +
+			"for height 256")
+	}
+
+	lowEvents, highEvents := ticketEventsKey(255), ticketEventsKey(256)
+	if len(lowEvents) != ticketEventsKeySize {
+		t.Fatalf("unexpected events key length - got %d, want %d",
+			len(lowEvents), ticketEventsKeySize)
+	}
+	if bytes.Compare(lowEvents, highEvents) >= 0 {
+		t.Fatalf("events key for height 255 does not sort before the key " +
+			"for height 256")
+	}
+}
+
+// TestTicketIndex ensures the ticket index tracks the purchases, votes,
+// revocations, misses, and expirations of tickets as blocks are connected and
+// undoes them as blocks are disconnected, including during a reorg.
+func TestTicketIndex(t *testing.T) {
+	db, path := setupDB(t, "test_ticketindex")
+	defer teardownDB(db, path)
+
+	chain, err := newTestChain()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Use small ticket maturity, expiry, and stake validation heights so that
+	// tickets are voted, missed, and expired within a short chain.
+	params := chaincfg.SimNetParams()
+	params.CoinbaseMaturity = 2
+	params.TicketMaturity = 2
+	params.TicketExpiry = 3
+	params.StakeEnabledHeight = 4
+	params.StakeValidationHeight = 6
+	g, err := chaingen.MakeGenerator(params, nil)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	// Initialize the ticket index.
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	subber := NewIndexSubscriber(ctx)
+	go subber.Run(ctx)
+
+	idx, err := NewTicketIndex(subber, db, chain)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := subber.CatchUp(ctx, chain); err != nil {
+		t.Fatal(err)
+	}
+
+	// entries tracks the expected ticket index entry for every ticket
+	// purchased in the main chain so the tickets that expire can be
+	// determined since the test chain does not track the state of tickets.
+	entries := make(map[chainhash.Hash]TicketEntry)
+	spent := make(map[chainhash.Hash]struct{})
+	expiryHeight := func(entry *TicketEntry) int64 {
+		return entry.PurchaseHeight + int64(params.TicketMaturity) +
+			int64(params.TicketExpiry)
+	}
+
+	// votedTickets returns the tickets voted by the provided block.
+	votedTickets := func(b *wire.MsgBlock) map[chainhash.Hash]struct{} {
+		tickets := make(map[chainhash.Hash]struct{})
+		for _, stx := range b.STransactions {
+			if stakeTxType(stx) == stake.TxTypeSSGen {
+				tickets[stx.TxIn[1].PreviousOutPoint.Hash] = struct{}{}
+			}
+		}
+		return tickets
+	}
+
+	_, genesisHash := chain.Best()
+	genesis, err := chain.BlockByHash(genesisHash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	blocks := []*dcrutil.Block{genesis}
+
+	// connectBlock extends the test chain with a generated block that has the
+	// provided mungers applied and purchases tickets once coinbase outputs are
+	// available.  It records the winning tickets the block does not vote as
+	// missed along with any tickets that expire in it, connects it to the
+	// index, and returns the missed and expired tickets.
+	connectBlock := func(name string, mungers ...func(*wire.MsgBlock)) ([]chainhash.Hash, []chainhash.Hash) {
+		t.Helper()
+
+		var winners map[chainhash.Hash]struct{}
+		recordWinners := func(b *wire.MsgBlock) {
+			winners = votedTickets(b)
+		}
+		mungers = append([]func(*wire.MsgBlock){recordWinners}, mungers...)
+		var msgBlk *wire.MsgBlock
+		if g.TipName() == "genesis" {
+			msgBlk = g.CreateBlockOne(name, 0, mungers...)
+		} else {
+			var ticketOuts []chaingen.SpendableOut
+			if g.NumSpendableCoinbaseOuts() > 0 {
+				ticketOuts = g.OldestCoinbaseOuts()
+			}
+			msgBlk = g.NextBlock(name, nil, ticketOuts, mungers...)
+			g.SaveTipCoinbaseOuts()
+		}
+		blk := dcrutil.NewBlock(msgBlk)
+		if err := chain.AddBlock(blk); err != nil {
+			t.Fatal(err)
+		}
+
+		height := blk.Height()
+		for _, stx := range msgBlk.STransactions {
+			if stakeTxType(stx) == stake.TxTypeSStx {
+				entries[stx.TxHash()] = TicketEntry{
+					Hash:           stx.TxHash(),
+					PurchaseHeight: height,
+					Price:          stx.TxOut[0].Value,
+				}
+			}
+		}
+		voted := votedTickets(msgBlk)
+		var missed []chainhash.Hash
+		for hash := range winners {
+			if _, ok := voted[hash]; !ok {
+				missed = append(missed, hash)
+			}
+			spent[hash] = struct{}{}
+		}
+		var expired []chainhash.Hash
+		for hash, entry := range entries {
+			if _, ok := spent[hash]; !ok && expiryHeight(&entry) == height {
+				expired = append(expired, hash)
+				spent[hash] = struct{}{}
+			}
+		}
+
+		chain.SetTicketsMissedAndExpired(blk.Hash(), missed, expired)
+		notifyConnect(t, subber, chain, blk, blocks[len(blocks)-1])
+		blocks = append(blocks, blk)
+		return missed, expired
+	}
+
+	// connectUntilExpired extends the test chain until a block expires at
+	// least one ticket and returns the expired tickets.
+	connectUntilExpired := func(prefix string) []chainhash.Hash {
+		t.Helper()
+
+		for i := 0; i < 50; i++ {
+			name := prefix + string(rune('a'+i))
+			if _, expired := connectBlock(name); len(expired) > 0 {
+				return expired
+			}
+		}
+		t.Fatal("no tickets expired")
+		return nil
+	}
+
+	// assertEntry ensures the ticket index entry for the provided ticket
+	// matches the provided entry or does not exist when it is nil.
+	assertEntry := func(hash chainhash.Hash, want *TicketEntry) {
+		t.Helper()
+
+		got, err := idx.Entry(&hash)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Fatalf("mismatched entry for ticket %v - got %+v, want %+v",
+				hash, got, want)
+		}
+	}
+
+	// Purchase tickets in every block leading up to the stake validation
+	// height.  The first block uses a fixed timestamp so the winning tickets,
+	// which are selected based on the previous block, are deterministic.
+	connectBlock("bp1", func(b *wire.MsgBlock) {
+		b.Header.Timestamp = time.Unix(1700000000, 0)
+	})
+	for i := int64(2); i < params.StakeValidationHeight; i++ {
+		connectBlock("bp" + string(rune('0'+i)))
+	}
+
+	// Create a block at the stake validation height that only includes four
+	// of the five votes, so the remaining winning ticket is missed, and
+	// replaces the version and bits of the included votes.
+	const voteVersion = 8
+	const voteBits = 0x0005
+	missed, _ := connectBlock("bsvh", g.ReplaceWithNVotes(4),
+		chaingen.ReplaceVotes(voteBits, voteVersion))
+	if len(missed) != 1 {
+		t.Fatalf("unexpected number of missed tickets - got %d, want 1",
+			len(missed))
+	}
+	bsvh := blocks[len(blocks)-1]
+
+	// Determine the expected entries for the first ticket that voted and the
+	// ticket that was missed.
+	vote := bsvh.MsgBlock().STransactions[0]
+	votedTicket := entries[vote.TxIn[1].PreviousOutPoint.Hash]
+	votedTicket.VoteHeight = bsvh.Height()
+	votedTicket.VoteHash = vote.TxHash()
+	votedTicket.VoteVersion = voteVersion
+	votedTicket.VoteBits = voteBits
+	missedTicket := entries[missed[0]]
+	missedTicket.MissedHeight = bsvh.Height()
+
+	// Save the state at the stake validation height so it can be restored
+	// for the side chain below.
+	svhEntries := make(map[chainhash.Hash]TicketEntry, len(entries))
+	for hash, entry := range entries {
+		svhEntries[hash] = entry
+	}
+	svhSpent := make(map[chainhash.Hash]struct{}, len(spent))
+	for hash := range spent {
+		svhSpent[hash] = struct{}{}
+	}
+	g.SnapshotCoinbaseOuts("bsvh")
+
+	// findRevocation returns the revocation of the missed ticket in the block
+	// after the stake validation height.
+	findRevocation := func() *wire.MsgTx {
+		t.Helper()
+
+		for _, stx := range blocks[bsvh.Height()+1].MsgBlock().STransactions {
+			if stakeTxType(stx) == stake.TxTypeSSRtx &&
+				stx.TxIn[0].PreviousOutPoint.Hash == missedTicket.Hash {
+
+				return stx
+			}
+		}
+		t.Fatal("missed ticket was not revoked")
+		return nil
+	}
+
+	// Extend the chain until a ticket expires and ensure the vote, miss,
+	// revocation, and expiration are all recorded.
+	connectBlock("brev")
+	expired := connectUntilExpired("bexp")
+	revocation := findRevocation()
+	revokedTicket := missedTicket
+	revokedTicket.RevocationHeight = bsvh.Height() + 1
+	revokedTicket.RevocationHash = revocation.TxHash()
+	tip := blocks[len(blocks)-1]
+	expiredTicket := entries[expired[0]]
+	expiredTicket.ExpiredHeight = tip.Height()
+	assertEntry(votedTicket.Hash, &votedTicket)
+	assertEntry(revokedTicket.Hash, &revokedTicket)
+	assertEntry(expiredTicket.Hash, &expiredTicket)
+	assertIndexTip(t, idx, tip)
+
+	// Disconnect all blocks after the stake validation height and ensure the
+	// revocation, expiration, and tickets purchased by the disconnected
+	// blocks are removed while the vote and miss remain.
+	purchase := tip.MsgBlock().STransactions[len(tip.MsgBlock().STransactions)-1]
+	if stakeTxType(purchase) != stake.TxTypeSStx {
+		t.Fatalf("last stake transaction of block %s is not a ticket "+
+			"purchase", tip.Hash())
+	}
+	for len(blocks)-1 > int(bsvh.Height()) {
+		block, parent := blocks[len(blocks)-1], blocks[len(blocks)-2]
+		notifyDisconnect(t, subber, chain, block, parent)
+		blocks = blocks[:len(blocks)-1]
+	}
+	g.SetTip("bsvh")
+	expiredTicket.ExpiredHeight = 0
+	assertEntry(votedTicket.Hash, &votedTicket)
+	assertEntry(missedTicket.Hash, &missedTicket)
+	assertEntry(expiredTicket.Hash, &expiredTicket)
+	assertEntry(purchase.TxHash(), nil)
+	assertIndexTip(t, idx, bsvh)
+
+	// Reorganize to a side chain that disapproves the regular transaction tree
+	// of the block at the stake validation height and extend it until a
+	// ticket expires.  Ensure the revocation and expiration of the side chain
+	// are recorded.
+	entries, spent = svhEntries, svhSpent
+	g.RestoreCoinbaseOutsSnapshot("bsvh")
+	connectBlock("brevalt", disapproveParent)
+	expired = connectUntilExpired("bexpalt")
+	revocation = findRevocation()
+	revokedTicket.RevocationHash = revocation.TxHash()
+	tip = blocks[len(blocks)-1]
+	expiredTicket = entries[expired[0]]
+	expiredTicket.ExpiredHeight = tip.Height()
+	assertEntry(votedTicket.Hash, &votedTicket)
+	assertEntry(revokedTicket.Hash, &revokedTicket)
+	assertEntry(expiredTicket.Hash, &expiredTicket)
+	assertIndexTip(t, idx, tip)
+}
@@ -33,6 +33,8 @@ type testChain struct {
 	orphans          map[string]*dcrutil.Block
 	consumers        map[string]spendpruner.SpendConsumer
 	removedSpendDeps map[string][]string
+	ticketsMissed    map[chainhash.Hash][]chainhash.Hash
+	ticketsExpired   map[chainhash.Hash][]chainhash.Hash
 	mtx              sync.Mutex
 }
 
@@ -44,6 +46,8 @@ func newTestChain() (*testChain, error) {
 		orphans:          make(map[string]*dcrutil.Block),
 		consumers:        make(map[string]spendpruner.SpendConsumer),
 		removedSpendDeps: make(map[string][]string),
+		ticketsMissed:    make(map[chainhash.Hash][]chainhash.Hash),
+		ticketsExpired:   make(map[chainhash.Hash][]chainhash.Hash),
 	}
 	genesis := dcrutil.NewBlock(chaincfg.SimNetParams().GenesisBlock)
 	return tc, tc.AddBlock(genesis)
@@ -122,6 +126,27 @@ func (tc *testChain) IsTreasuryAgendaActive(hash *chainhash.Hash) (bool, error)
 	return tc.treasuryActive, nil
 }
 
+// SetTicketsMissedAndExpired sets the tickets that were missed and the tickets
+// that expired by the block with the provided hash since the test chain does
+// not track the state of tickets itself.
+func (tc *testChain) SetTicketsMissedAndExpired(hash *chainhash.Hash, missed, expired []chainhash.Hash) {
+	tc.mtx.Lock()
+	defer tc.mtx.Unlock()
+
+	tc.ticketsMissed[*hash] = missed
+	tc.ticketsExpired[*hash] = expired
+}
+
+// TicketsMissedAndExpiredByBlock returns the tickets that were missed and the
+// tickets that expired by the block with the provided hash as previously set
+// by SetTicketsMissedAndExpired.
+func (tc *testChain) TicketsMissedAndExpiredByBlock(hash *chainhash.Hash) ([]chainhash.Hash, []chainhash.Hash, error) {
+	tc.mtx.Lock()
+	defer tc.mtx.Unlock()
+
+	return tc.ticketsMissed[*hash], tc.ticketsExpired[*hash], nil
+}
+
 // BlockHeightByHash returns the height of the provided block hash if it is
 // part of the chain.
 func (tc *testChain) BlockHeightByHash(hash *chainhash.Hash) (int64, error) {
@@ -177,7 +202,7 @@ func (tc *testChain) AddSpendConsumer(consumer spendpruner.SpendConsumer) {
 
 // RemoveSpendConsumerDependency removes the provided spend consumer dependency
 // associated with the provided block hash.
-func (tc *testChain) RemoveSpendConsumerDependency(_ database.Tx, blockHash *chainhash.Hash, consumerID string) error {
+func (tc *testChain) RemoveSpendConsumerDependency(blockHash *chainhash.Hash, consumerID string) error {
 	tc.mtx.Lock()
 	defer tc.mtx.Unlock()
 
@@ -277,10 +302,134 @@ func (tc *testChain) BlockHeaderByHash(hash *chainhash.Hash) (wire.BlockHeader,
 	return blk.MsgBlock().Header, nil
 }
 
+// testPrevOut houses the script, script version, and amount of a previous
+// output spent by a block in the test chain.
+type testPrevOut struct {
+	version  uint16
+	pkScript []byte
+	amount   int64
+}
+
+// testPrevScripts provides a source of previous output scripts and amounts for
+// the outputs spent by a block in the test chain.  It implements both the
+// PrevScripter and PrevAmounter interfaces.
+type testPrevScripts map[wire.OutPoint]testPrevOut
+
+// PrevScript returns the script and script version of the provided previous
+// outpoint when it exists in the source.
+//
+// This is part of the PrevScripter interface.
+func (p testPrevScripts) PrevScript(prevOut *wire.OutPoint) (uint16, []byte, bool) {
+	out, ok := p[*prevOut]
+	return out.version, out.pkScript, ok
+}
+
+// PrevAmount returns the amount of the provided previous outpoint when it
+// exists in the source.
+//
+// This is part of the PrevAmounter interface.
+func (p testPrevScripts) PrevAmount(prevOut *wire.OutPoint) (int64, bool) {
+	out, ok := p[*prevOut]
+	return out.amount, ok
+}
+
 // PrevScripts returns a source of previous transaction scripts and their
-// associated versions spent by the provided block.
-func (tc *testChain) PrevScripts(database.Tx, *dcrutil.Block) (PrevScripter, error) {
-	return nil, nil
+// associated versions spent by the provided block.  The scripts are looked up
+// in all blocks known to the test chain, including those that were removed
+// from it, since the outputs spent by a block being disconnected must still be
+// available.
+func (tc *testChain) PrevScripts(block *dcrutil.Block) (PrevScripter, error) {
+	tc.mtx.Lock()
+	defer tc.mtx.Unlock()
+
+	// Determine the outpoints spent by the block.
+	prevScripts := make(testPrevScripts)
+	spent := make(map[wire.OutPoint]struct{})
+	err := forEachSpentOutPoint(block, func(outpoint *wire.OutPoint, _ *chainhash.Hash, _ uint32) error {
+		spent[*outpoint] = struct{}{}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Look up the outputs they reference in the known blocks.
+	addOutputs := func(blk *dcrutil.Block) {
+		txns := [][]*wire.MsgTx{blk.MsgBlock().Transactions,
+			blk.MsgBlock().STransactions}
+		for tree, treeTxns := range txns {
+			for _, tx := range treeTxns {
+				for txOutIdx, txOut := range tx.TxOut {
+					outpoint := wire.OutPoint{
+						Hash:  tx.TxHash(),
+						Index: uint32(txOutIdx),
+						Tree:  int8(tree),
+					}
+					if _, ok := spent[outpoint]; !ok {
+						continue
+					}
+					prevScripts[outpoint] = testPrevOut{
+						version:  txOut.Version,
+						pkScript: txOut.PkScript,
+						amount:   txOut.Value,
+					}
+				}
+			}
+		}
+	}
+	for _, blk := range tc.keyedByHash {
+		addOutputs(blk)
+	}
+	for _, blk := range tc.orphans {
+		addOutputs(blk)
+	}
+
+	return prevScripts, nil
+}
+
+// notifyConnect sends a connect notification for the provided block along
+// with the outputs it spends and the tickets it missed and expired and waits
+// for it to be processed.
+func notifyConnect(t *testing.T, subber *IndexSubscriber, chain *testChain, block, parent *dcrutil.Block) {
+	t.Helper()
+
+	prevScripts, err := chain.PrevScripts(block)
+	if err != nil {
+		t.Fatal(err)
+	}
+	missed, expired, err := chain.TicketsMissedAndExpiredByBlock(block.Hash())
+	if err != nil {
+		t.Fatal(err)
+	}
+	notifyAndWait(t, subber, &IndexNtfn{
+		NtfnType:       ConnectNtfn,
+		Block:          block,
+		Parent:         parent,
+		PrevScripts:    prevScripts,
+		TicketsMissed:  missed,
+		TicketsExpired: expired,
+	})
+}
+
+// notifyDisconnect removes the provided block, which must be the tip, from the
+// test chain and sends a disconnect notification for it along with the outputs
+// it spends and waits for it to be processed.
+func notifyDisconnect(t *testing.T, subber *IndexSubscriber, chain *testChain, block, parent *dcrutil.Block) {
+	t.Helper()
+
+	if err := chain.RemoveBlock(block); err != nil {
+		t.Fatal(err)
+	}
+	prevScripts, err := chain.PrevScripts(block)
+	if err != nil {
+		t.Fatal(err)
+	}
+	notifyAndWait(t, subber, &IndexNtfn{
+		NtfnType:    DisconnectNtfn,
+		Block:       block,
+		Parent:      parent,
+		PrevScripts: prevScripts,
+	})
 }
 
 // notifyAndWait sends the provided notification and waits for done signal
@@ -322,6 +471,42 @@ func addBlock(t *testing.T, chain *testChain, gen *chaingen.Generator, name stri
 	return blk
 }
 
+// addSpendBlock extends the provided chain with a generated block that spends
+// the provided output, when it is not nil, and has the provided mungers
+// applied.
+func addSpendBlock(t *testing.T, chain *testChain, gen *chaingen.Generator, name string, spend *chaingen.SpendableOut, mungers ...func(*wire.MsgBlock)) *dcrutil.Block {
+	t.Helper()
+
+	msgBlk := gen.NextBlock(name, spend, nil, mungers...)
+	gen.SaveTipCoinbaseOuts()
+	blk := dcrutil.NewBlock(msgBlk)
+	if err := chain.AddBlock(blk); err != nil {
+		t.Fatal(err)
+	}
+	return blk
+}
+
+// disapproveParent is a munger that marks the regular transaction tree of the
+// parent of the block as disapproved.
+func disapproveParent(b *wire.MsgBlock) {
+	b.Header.VoteBits &^= dcrutil.BlockValid
+}
+
+// assertIndexTip ensures the tip of the provided index is the provided block.
+func assertIndexTip(t *testing.T, idx Indexer, block *dcrutil.Block) {
+	t.Helper()
+
+	tipHeight, tipHash, err := idx.Tip()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tipHeight != block.Height() || *tipHash != *block.Hash() {
+		t.Fatalf("%s: unexpected tip -- got %s (height %d), want %s "+
+			"(height %d)", idx.Name(), tipHash, tipHeight, block.Hash(),
+			block.Height())
+	}
+}
+
 // setupDB initializes the test database.
 func setupDB(t *testing.T, dbName string) (database.DB, string) {
 	dbPath, err := os.MkdirTemp("", dbName)
@@ -354,7 +539,7 @@ func TestTxIndexAsync(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
-	g, err := chaingen.MakeGenerator(chaincfg.SimNetParams())
+	g, err := chaingen.MakeGenerator(chaincfg.SimNetParams(), nil)
 	if err != nil {
 		t.Fatalf("unexpected error %v", err)
 	}
@@ -376,7 +561,7 @@ func TestTxIndexAsync(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	err = subber.CatchUp(ctx, db, chain)
+	err = subber.CatchUp(ctx, chain)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -455,7 +640,7 @@ func TestTxIndexAsync(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	err = subber.CatchUp(ctx, db, chain)
+	err = subber.CatchUp(ctx, chain)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -551,7 +736,7 @@ func TestTxIndexAsync(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	err = subber.CatchUp(ctx, db, chain)
+	err = subber.CatchUp(ctx, chain)
 	if err != nil {
 		t.Fatal(err)
 	}
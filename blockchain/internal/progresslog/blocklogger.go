@@ -6,6 +6,7 @@
 package progresslog
 
 import (
+	"fmt"
 	"sync"
 	"time"
 
@@ -22,6 +23,12 @@ type BlockProgressLogger struct {
 	receivedLogTx     int64
 	lastBlockLogTime  time.Time
 
+	// These fields track the overall progress towards a target height in
+	// order to estimate the remaining time.  They are only used by
+	// LogProgress.
+	startTime   time.Time
+	startHeight int64
+
 	subsystemLogger slog.Logger
 	progressAction  string
 	sync.Mutex
@@ -43,13 +50,73 @@ func NewBlockProgressLogger(progressMessage string, logger slog.Logger) *BlockPr
 // progress to the user. In order to prevent spam, it limits logging to one
 // message every 10 seconds with duration and totals included.
 func (b *BlockProgressLogger) LogBlockHeight(block, parent *wire.MsgBlock) {
+	b.Lock()
+	b.logBlockHeight(block, "")
+	b.Unlock()
+}
+
+// LogProgress logs a new block height as an information message to show
+// progress towards the provided target height to the user along with an
+// estimate of the remaining time.  Like LogBlockHeight, it limits logging to
+// one message every 10 seconds.
+//
+// The estimate is based on the average rate blocks have been processed since
+// the first call, so the block heights passed to it are expected to be
+// increasing.
+//
+// This function is safe for concurrent access.
+func (b *BlockProgressLogger) LogProgress(block *wire.MsgBlock, targetHeight int64) {
 	b.Lock()
 	defer b.Unlock()
 
+	height := int64(block.Header.Height)
+	if b.startTime.IsZero() {
+		b.startTime = time.Now()
+		b.startHeight = height - 1
+	}
+
+	var progress string
+	if total := targetHeight - b.startHeight; total > 0 {
+		done := height - b.startHeight
+		elapsed := time.Since(b.startTime)
+		eta := time.Duration(float64(elapsed) * float64(total-done) /
+			float64(done))
+		progress = fmt.Sprintf(", progress %0.2f%%, eta %s",
+			float64(done)*100/float64(total), eta.Truncate(time.Second))
+	}
+	b.logBlockHeight(block, progress)
+}
+
+// LogHeader logs a new block height based on the provided header as an
+// information message to show progress to the user.  It is useful when the
+// full block is not otherwise needed, so, unlike LogBlockHeight, the message
+// does not include the number of transactions.  It also limits logging to one
+// message every 10 seconds.
+func (b *BlockProgressLogger) LogHeader(header *wire.BlockHeader) {
+	b.Lock()
+	b.receivedLogBlocks++
+	b.maybeLog(header, false, "")
+	b.Unlock()
+}
+
+// logBlockHeight accumulates details for the provided block and periodically
+// logs them along with the provided extra details.
+//
+// This function MUST be called with the logger lock held.
+func (b *BlockProgressLogger) logBlockHeight(block *wire.MsgBlock, extra string) {
 	b.receivedLogBlocks++
 	b.receivedLogTx += int64(len(block.Transactions))
 	b.receivedLogTx += int64(len(block.STransactions))
+	b.maybeLog(&block.Header, true, extra)
+}
 
+// maybeLog logs the accumulated details along with the height and timestamp of
+// the provided header and the provided extra details when enough time has
+// passed since the last message.  The number of transactions is only included
+// when requested.
+//
+// This function MUST be called with the logger lock held.
+func (b *BlockProgressLogger) maybeLog(header *wire.BlockHeader, logTxns bool, extra string) {
 	now := time.Now()
 	duration := now.Sub(b.lastBlockLogTime)
 	if duration < time.Second*10 {
@@ -65,14 +132,17 @@ func (b *BlockProgressLogger) LogBlockHeight(block, parent *wire.MsgBlock) {
 	if b.receivedLogBlocks == 1 {
 		blockStr = "block"
 	}
-	txStr := "transactions"
-	if b.receivedLogTx == 1 {
-		txStr = "transaction"
+	var txns string
+	if logTxns {
+		txStr := "transactions"
+		if b.receivedLogTx == 1 {
+			txStr = "transaction"
+		}
+		txns = fmt.Sprintf("%d %s, ", b.receivedLogTx, txStr)
 	}
-	b.subsystemLogger.Infof("%s %d %s in the last %s (%d %s, height %d, %s)",
-		b.progressAction, b.receivedLogBlocks, blockStr, tDuration,
-		b.receivedLogTx, txStr, block.Header.Height,
-		block.Header.Timestamp)
+	b.subsystemLogger.Infof("%s %d %s in the last %s (%sheight %d, %s%s)",
+		b.progressAction, b.receivedLogBlocks, blockStr, tDuration, txns,
+		header.Height, header.Timestamp, extra)
 
 	b.receivedLogBlocks = 0
 	b.receivedLogTx = 0
@@ -26,6 +26,18 @@ var (
 // initConsumerDepsBucket creates the spend consumer dependencies bucket if it
 // does not exist.
 func initConsumerDepsBucket(db database.DB) error {
+	// Nothing to do when the spend consumer dependencies bucket already
+	// exists.  This also allows the pruner to be created for databases that
+	// are open in read-only mode.
+	var exists bool
+	err := db.View(func(dbTx database.Tx) error {
+		exists = dbTx.Metadata().Bucket(spendConsumerDepsBucketName) != nil
+		return nil
+	})
+	if err != nil || exists {
+		return err
+	}
+
 	// Create the spend consumer dependencies bucket if it does not exist yet.
 	return db.Update(func(dbTx database.Tx) error {
 		meta := dbTx.Metadata()
@@ -136,6 +136,11 @@ type BlockConnectedNtfnsData struct {
 	// PrevScripts provides access to previous transaction scripts and their
 	// associated versions spent by the connected block.
 	PrevScripts indexers.PrevScripter
+
+	// TicketsMissed and TicketsExpired are the tickets that were missed and
+	// the tickets that expired by the connected block, respectively.
+	TicketsMissed  []chainhash.Hash
+	TicketsExpired []chainhash.Hash
 }
 
 // BlockDisconnectedNtfnsData is the structure for data indicating information
@@ -221,6 +221,10 @@ func (b *BlockChain) maybeAcceptBlockHeader(header *wire.BlockHeader, checkHeade
 //
 // This function is safe for concurrent access.
 func (b *BlockChain) ProcessBlockHeader(header *wire.BlockHeader) error {
+	if b.noWrites {
+		return noWritesError("process block headers")
+	}
+
 	b.processLock.Lock()
 	defer b.processLock.Unlock()
 
@@ -429,6 +433,10 @@ func (b *BlockChain) maybeAcceptBlocks(curTip *blockNode, nodes []*blockNode, fl
 //
 // This function is safe for concurrent access.
 func (b *BlockChain) ProcessBlock(block *dcrutil.Block) (int64, error) {
+	if b.noWrites {
+		return 0, noWritesError("process blocks")
+	}
+
 	// Since the chain lock is periodically released to send notifications,
 	// protect the overall processing of blocks with a separate mutex.
 	b.processLock.Lock()
@@ -471,6 +479,13 @@ func (b *BlockChain) ProcessBlock(block *dcrutil.Block) (int64, error) {
 		return 0, err
 	}
 
+	// Start loading the outputs spent by the block into the utxo cache in the
+	// background so they are likely already available by the time the block
+	// is connected.  This is intentionally done after the sanity checks, which
+	// include proof-of-work validation, to avoid doing the work for blocks that
+	// are obviously invalid.
+	b.prefetchBlockInputs(block)
+
 	// Potentially accept the header to the block index when it does not already
 	// exist.
 	//
@@ -646,6 +661,10 @@ func (b *BlockChain) ProcessBlock(block *dcrutil.Block) (int64, error) {
 // with the most cumulative proof of work that is still valid becomes the main
 // chain.
 func (b *BlockChain) InvalidateBlock(hash *chainhash.Hash) error {
+	if b.noWrites {
+		return noWritesError("invalidate blocks")
+	}
+
 	b.processLock.Lock()
 	defer b.processLock.Unlock()
 
@@ -765,6 +784,10 @@ func blockNodeInSlice(node *blockNode, slice []*blockNode) bool {
 // most cumulative proof of work that is valid becomes the tip of the main
 // chain.
 func (b *BlockChain) ReconsiderBlock(hash *chainhash.Hash) error {
+	if b.noWrites {
+		return noWritesError("reconsider blocks")
+	}
+
 	b.processLock.Lock()
 	defer b.processLock.Unlock()
 
@@ -0,0 +1,357 @@
+// Copyright (c) 2023 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package blockchain
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/EXCCoin/exccd/blockchain/stake/v4"
+	"github.com/EXCCoin/exccd/blockchain/v4/internal/progresslog"
+	"github.com/EXCCoin/exccd/chaincfg/chainhash"
+	"github.com/EXCCoin/exccd/database/v3"
+)
+
+var (
+	// reindexChainStateKeyName is the name of the db key used to store the
+	// state of an in progress chain state reindex.  The key only exists while
+	// a reindex is in progress.
+	reindexChainStateKeyName = []byte("reindexchainstate")
+)
+
+// reindexState houses the state of an in progress chain state reindex.  It is
+// persisted to the database so that an interrupted reindex is resumed on the
+// next startup.
+type reindexState struct {
+	// target is the hash of the best chain tip at the time the reindex was
+	// started.  All blocks from the genesis block up to and including it are
+	// reconnected.
+	target chainhash.Hash
+
+	// resetDone indicates whether the UTXO set, spend journal, and ticket
+	// database have been fully reset to the genesis block.
+	resetDone bool
+}
+
+// serializeReindexState returns the serialization of the passed reindex state.
+//
+// The serialized format is:
+//
+//	<target><reset done>
+//
+//	Field        Type             Size
+//	target       chainhash.Hash   chainhash.HashSize
+//	reset done   bool             1 byte
+func serializeReindexState(state *reindexState) []byte {
+	serialized := make([]byte, chainhash.HashSize+1)
+	copy(serialized, state.target[:])
+	if state.resetDone {
+		serialized[chainhash.HashSize] = 1
+	}
+	return serialized
+}
+
+// deserializeReindexState deserializes the passed serialized reindex state.
+func deserializeReindexState(serialized []byte) (*reindexState, error) {
+	if len(serialized) != chainhash.HashSize+1 {
+		return nil, makeDbErr(database.ErrCorruption, fmt.Sprintf("corrupt "+
+			"reindex chain state: unexpected length %d", len(serialized)))
+	}
+
+	var state reindexState
+	copy(state.target[:], serialized[:chainhash.HashSize])
+	state.resetDone = serialized[chainhash.HashSize] != 0
+	return &state, nil
+}
+
+// dbFetchReindexState uses an existing database transaction to fetch the state
+// of an in progress chain state reindex.  Nil is returned when no reindex is in
+// progress.
+func dbFetchReindexState(dbTx database.Tx) (*reindexState, error) {
+	serialized := dbTx.Metadata().Get(reindexChainStateKeyName)
+	if serialized == nil {
+		return nil, nil
+	}
+	return deserializeReindexState(serialized)
+}
+
+// dbPutReindexState uses an existing database transaction to store the state of
+// an in progress chain state reindex.
+func dbPutReindexState(dbTx database.Tx, state *reindexState) error {
+	serialized := serializeReindexState(state)
+	return dbTx.Metadata().Put(reindexChainStateKeyName, serialized)
+}
+
+// genesisBestState returns a fully linked block node for the genesis block
+// along with the best state snapshot for a chain that only consists of it.
+func (b *BlockChain) genesisBestState() (*blockNode, *BestState) {
+	genesisBlock := b.chainParams.GenesisBlock
+	node := newBlockNode(&genesisBlock.Header, nil)
+	node.status = statusDataStored | statusValidated
+	node.isFullyLinked = true
+
+	// Since it is the genesis block, use its timestamp for the median time.
+	numTxns := uint64(len(genesisBlock.Transactions))
+	blockSize := uint64(genesisBlock.SerializeSize())
+	snapshot := newBestState(node, blockSize, numTxns, numTxns,
+		time.Unix(node.timestamp, 0), 0, 0, b.chainParams.MinimumStakeDiff,
+		nil, nil, nil, earlyFinalState)
+	return node, snapshot
+}
+
+// resetUtxoBackend removes all entries from the UTXO set in the provided UTXO
+// backend and sets its state to the genesis block.
+func (b *BlockChain) resetUtxoBackend(ctx context.Context,
+	utxoBackend UtxoBackend) error {
+
+	// doBatch contains the primary logic for removing the UTXO set entries.
+	// This is done because attempting to remove all entries in a single
+	// database transaction could result in massive memory usage and could
+	// potentially crash on many systems due to ulimits.
+	//
+	// It returns whether or not all entries have been removed.
+	const maxEntries = 20000
+	var totalDeleted uint64
+	doBatch := func(tx UtxoBackendTx) (bool, error) {
+		var logProgress bool
+		var numDeleted uint32
+		var err error
+
+		iter := tx.NewIterator(utxoPrefixUtxoSet)
+		defer iter.Release()
+		for iter.Next() {
+			if interruptRequested(ctx) {
+				logProgress = true
+				err = errInterruptRequested
+				break
+			}
+
+			if numDeleted >= maxEntries {
+				logProgress = true
+				err = errBatchFinished
+				break
+			}
+
+			if err := tx.Delete(iter.Key()); err != nil {
+				return false, err
+			}
+			numDeleted++
+		}
+		if iterErr := iter.Error(); iterErr != nil {
+			return false, convertLdbErr(iterErr, iterErr.Error())
+		}
+		isFullyDone := err == nil
+		if (isFullyDone || logProgress) && numDeleted > 0 {
+			totalDeleted += uint64(numDeleted)
+			log.Infof("Deleted %d UTXO set entries (%d total)", numDeleted,
+				totalDeleted)
+		}
+		return isFullyDone, err
+	}
+	if err := utxoBackendBatchedUpdate(ctx, utxoBackend, doBatch); err != nil {
+		return err
+	}
+
+	// Set the UTXO set state to the genesis block.
+	return utxoBackend.PutUtxos(nil, &UtxoSetState{
+		lastFlushHeight: 0,
+		lastFlushHash:   b.chainParams.GenesisHash,
+	})
+}
+
+// maybeResetChainState prepares the chain state to be rebuilt from the blocks
+// stored in the database when a reindex was requested or a previously started
+// reindex was interrupted before the chain state was fully reset.
+//
+// Resetting the chain state entails removing the UTXO set, the spend journal,
+// and the ticket database and setting the best chain state to the genesis
+// block.  The block data and block index are left intact so that the blocks
+// which formed the best chain at the time the reindex was started can be
+// reconnected by reindexChainState once the chain is loaded.
+func (b *BlockChain) maybeResetChainState(ctx context.Context,
+	utxoBackend UtxoBackend, reindex bool) error {
+
+	// Load the state of any reindex that is already in progress and start a
+	// new one targeting the current best chain tip when a reindex was
+	// requested.  The target of a reindex that is already in progress is
+	// retained since the best chain state no longer reflects the original
+	// tip in that case.
+	var state *reindexState
+	err := b.db.Update(func(dbTx database.Tx) error {
+		var err error
+		state, err = dbFetchReindexState(dbTx)
+		if err != nil {
+			return err
+		}
+		if !reindex {
+			return nil
+		}
+
+		if state == nil {
+			bestState, err := dbFetchBestState(dbTx)
+			if err != nil {
+				return err
+			}
+			state = &reindexState{target: bestState.hash}
+		}
+		state.resetDone = false
+		return dbPutReindexState(dbTx, state)
+	})
+	if err != nil {
+		return err
+	}
+	if state == nil || state.resetDone {
+		return nil
+	}
+
+	log.Infof("Resetting chain state to rebuild it from stored blocks...")
+	start := time.Now()
+
+	// Remove all entries from the spend journal.
+	err = incrementalFlatDrop(ctx, b.db, spendJournalBucketName,
+		"spend journal")
+	if err != nil {
+		return err
+	}
+
+	// Reset the UTXO set to the genesis block.
+	if err := b.resetUtxoBackend(ctx, utxoBackend); err != nil {
+		return err
+	}
+
+	// Reset the ticket database and best chain state to the genesis block and
+	// mark the reset as complete.
+	genesisNode, genesisState := b.genesisBestState()
+	err = b.db.Update(func(dbTx database.Tx) error {
+		err := stake.ResetDatabase(dbTx, b.chainParams,
+			&b.chainParams.GenesisHash)
+		if err != nil {
+			return err
+		}
+
+		err = dbPutBestState(dbTx, genesisState, genesisNode.workSum)
+		if err != nil {
+			return err
+		}
+
+		state.resetDone = true
+		return dbPutReindexState(dbTx, state)
+	})
+	if err != nil {
+		return err
+	}
+
+	log.Infof("Done resetting chain state in %v",
+		time.Since(start).Round(time.Millisecond))
+	return nil
+}
+
+// reindexChainState rebuilds the chain state by reconnecting all stored blocks
+// from the current best chain tip up to and including the target of an in
+// progress reindex.  It does nothing when no reindex is in progress.
+//
+// This must only be called during initialization after the chain state, UTXO
+// cache, and spend journal pruner have been initialized.
+func (b *BlockChain) reindexChainState(ctx context.Context) error {
+	var state *reindexState
+	err := b.db.View(func(dbTx database.Tx) error {
+		var err error
+		state, err = dbFetchReindexState(dbTx)
+		return err
+	})
+	if err != nil || state == nil {
+		return err
+	}
+
+	target := b.index.LookupNode(&state.target)
+	if target == nil {
+		return AssertError(fmt.Sprintf("reindexChainState: cannot find "+
+			"target %s in block index", state.target))
+	}
+
+	b.chainLock.Lock()
+	defer b.chainLock.Unlock()
+
+	tip := b.bestChain.Tip()
+	if !tip.IsAncestorOf(target) && tip != target {
+		return AssertError(fmt.Sprintf("reindexChainState: target %s "+
+			"(height %d) is not a descendant of the current tip %s "+
+			"(height %d)", target.hash, target.height, tip.hash,
+			tip.height))
+	}
+
+	// Add the target to the set of best chain candidates since the chain is
+	// reorganized towards it and the set is required to have a candidate with
+	// at least as much work as the current tip at all times.
+	b.index.Lock()
+	b.index.addBestChainCandidate(target)
+	b.index.Unlock()
+
+	// Reconnect the blocks without notification callbacks as the calling code
+	// will not be fully initialized yet at this point and this is being done
+	// as a part of chain initialization.
+	curNtfnCallback := b.notifications
+	b.notifications = nil
+	defer func() {
+		b.notifications = curNtfnCallback
+	}()
+
+	// Process the spend journal pruner signals for the connected blocks while
+	// reindexing since the chain is not running yet.
+	prunerCtx, cancelPruner := context.WithCancel(ctx)
+	go b.spendPruner.HandleSignals(prunerCtx)
+	defer cancelPruner()
+
+	log.Infof("Reindexing chain state from height %d to %d...", tip.height,
+		target.height)
+	start := time.Now()
+	progressLogger := progresslog.NewBlockProgressLogger("Reindexed", log)
+	for tip != target {
+		if interruptRequested(ctx) {
+			// Flush the UTXO cache so the work that has already been done is
+			// not lost.
+			err := b.utxoCache.MaybeFlush(&tip.hash, uint32(tip.height), true,
+				true)
+			if err != nil {
+				return err
+			}
+			return errInterruptRequested
+		}
+
+		// Connect the next block to the main chain.  Since the blocks that are
+		// reconnected formed the best chain before the reindex, they have
+		// already been fully validated and only the chain state is updated.
+		node := target.Ancestor(tip.height + 1)
+		if err := b.reorganizeChainInternal(node); err != nil {
+			return err
+		}
+
+		// Log progress from the header of the node to avoid loading the
+		// block again.
+		header := node.Header()
+		progressLogger.LogHeader(&header)
+
+		tip = node
+	}
+
+	// Ensure the rebuilt UTXO set is flushed to the database before marking
+	// the reindex complete.
+	b.maybeUpdateIsCurrent(tip)
+	err = b.utxoCache.MaybeFlush(&tip.hash, uint32(tip.height), true, true)
+	if err != nil {
+		return err
+	}
+	err = b.db.Update(func(dbTx database.Tx) error {
+		return dbTx.Metadata().Delete(reindexChainStateKeyName)
+	})
+	if err != nil {
+		return err
+	}
+
+	log.Infof("Done reindexing chain state in %v",
+		time.Since(start).Round(time.Millisecond))
+	return nil
+}
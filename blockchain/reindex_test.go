@@ -0,0 +1,99 @@
+// Copyright (c) 2023 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package blockchain
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/EXCCoin/exccd/database/v3"
+)
+
+// TestReindexStateSerialization ensures serializing and deserializing the
+// reindex state works as expected.
+func TestReindexStateSerialization(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		state      reindexState
+		serialized []byte
+	}{{
+		name: "reset not done",
+		state: reindexState{
+			target: *mustParseHash("00000000000000001e6ec1501c858506de1de4703d1be8bab4061126e8f61480"),
+		},
+		serialized: hexToBytes("8014f6e8261106b4bae81b3d70e41dde0685851c50c16e1e000000000000000000"),
+	}, {
+		name: "reset done",
+		state: reindexState{
+			target:    *mustParseHash("00000000000000001e6ec1501c858506de1de4703d1be8bab4061126e8f61480"),
+			resetDone: true,
+		},
+		serialized: hexToBytes("8014f6e8261106b4bae81b3d70e41dde0685851c50c16e1e000000000000000001"),
+	}}
+
+	for _, test := range tests {
+		// Ensure the state serializes to the expected value.
+		gotBytes := serializeReindexState(&test.state)
+		if !bytes.Equal(gotBytes, test.serialized) {
+			t.Errorf("%q: mismatched bytes - got %x, want %x", test.name,
+				gotBytes, test.serialized)
+			continue
+		}
+
+		// Ensure the serialized bytes are decoded back to the expected state.
+		state, err := deserializeReindexState(test.serialized)
+		if err != nil {
+			t.Errorf("%q: unexpected error: %v", test.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(*state, test.state) {
+			t.Errorf("%q: mismatched state - got %v, want %v", test.name,
+				*state, test.state)
+			continue
+		}
+	}
+}
+
+// TestReindexStateDeserializeErrors performs negative tests against
+// deserializing the reindex state to ensure error paths work as expected.
+func TestReindexStateDeserializeErrors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		serialized []byte
+		err        error
+	}{{
+		name:       "nothing serialized",
+		serialized: hexToBytes(""),
+		err:        database.ErrCorruption,
+	}, {
+		name:       "short data in hash",
+		serialized: hexToBytes("8014f6e8"),
+		err:        database.ErrCorruption,
+	}, {
+		name:       "missing reset done flag",
+		serialized: hexToBytes("8014f6e8261106b4bae81b3d70e41dde0685851c50c16e1e0000000000000000"),
+		err:        database.ErrCorruption,
+	}, {
+		name:       "trailing data",
+		serialized: hexToBytes("8014f6e8261106b4bae81b3d70e41dde0685851c50c16e1e00000000000000000100"),
+		err:        database.ErrCorruption,
+	}}
+
+	for _, test := range tests {
+		// Ensure the expected error kind is returned.
+		_, err := deserializeReindexState(test.serialized)
+		if !errors.Is(err, test.err) {
+			t.Errorf("%q: wrong error got: %v, want: %v", test.name, err,
+				test.err)
+			continue
+		}
+	}
+}
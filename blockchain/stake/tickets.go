@@ -914,6 +914,13 @@ func (sn *Node) DisconnectNode(parentLotteryIV chainhash.Hash, parentUtds UndoTi
 	return disconnectNode(sn, parentLotteryIV, parentUtds, parentTickets, dbTx)
 }
 
+// FetchBlockUndoData returns the undo data stored in the database for the main
+// chain block at the provided height.  The undo data details the changes made
+// to the state of the tickets by the block.
+func FetchBlockUndoData(dbTx database.Tx, height uint32) (UndoTicketDataSlice, error) {
+	return ticketdb.DbFetchBlockUndoData(dbTx, height)
+}
+
 // WriteConnectedBestNode writes the newly connected best node to the database
 // under an atomic database transaction, performing all the necessary writes to
 // the database buckets for live, missed, and revoked tickets.
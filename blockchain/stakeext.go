@@ -7,8 +7,11 @@ package blockchain
 
 import (
 	"bytes"
+	"fmt"
 
+	"github.com/EXCCoin/exccd/blockchain/stake/v4"
 	"github.com/EXCCoin/exccd/chaincfg/chainhash"
+	"github.com/EXCCoin/exccd/database/v3"
 	"github.com/EXCCoin/exccd/dcrutil/v4"
 	"github.com/EXCCoin/exccd/txscript/v4/stdaddr"
 	"github.com/EXCCoin/exccd/wire"
@@ -210,3 +213,64 @@ func (b *BlockChain) TicketPoolValue() (dcrutil.Amount, error) {
 	}
 	return dcrutil.Amount(amt), nil
 }
+
+// missedAndExpiredTickets returns the tickets that were newly missed and the
+// tickets that newly expired according to the provided ticket undo data of a
+// block.  Revoked tickets are not included since they were missed or expired
+// by an earlier block.
+func missedAndExpiredTickets(undoData stake.UndoTicketDataSlice) ([]chainhash.Hash, []chainhash.Hash) {
+	var missed, expired []chainhash.Hash
+	for _, undo := range undoData {
+		switch {
+		case undo.Revoked:
+		case undo.Expired:
+			expired = append(expired, undo.TicketHash)
+		case undo.Missed:
+			missed = append(missed, undo.TicketHash)
+		}
+	}
+
+	return missed, expired
+}
+
+// TicketsMissedAndExpiredByBlock returns the tickets that were missed and the
+// tickets that expired by the main chain block with the given hash.  Expired
+// tickets are not included in the missed tickets even though the stake
+// database also considers them missed.
+//
+// This function is safe for concurrent access.
+func (b *BlockChain) TicketsMissedAndExpiredByBlock(hash *chainhash.Hash) ([]chainhash.Hash, []chainhash.Hash, error) {
+	b.chainLock.RLock()
+	defer b.chainLock.RUnlock()
+
+	node := b.index.LookupNode(hash)
+	if node == nil || !b.bestChain.Contains(node) {
+		str := fmt.Sprintf("block %s is not in the main chain", hash)
+		return nil, nil, errNotInMainChain(str)
+	}
+
+	// Tickets are only missed or expired once stake validation begins.
+	if node.height < b.chainParams.StakeValidationHeight {
+		return nil, nil, nil
+	}
+
+	// Use the cached stake node when it is loaded since it contains the undo
+	// data and otherwise load the undo data from the stake database which
+	// stores it for all blocks in the main chain.
+	if node.stakeNode != nil {
+		missed, expired := missedAndExpiredTickets(node.stakeNode.UndoData())
+		return missed, expired, nil
+	}
+	var undoData stake.UndoTicketDataSlice
+	err := b.db.View(func(dbTx database.Tx) error {
+		var err error
+		undoData, err = stake.FetchBlockUndoData(dbTx, uint32(node.height))
+		return err
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	missed, expired := missedAndExpiredTickets(undoData)
+	return missed, expired, nil
+}
@@ -551,15 +551,17 @@ func clearFailedBlockFlagsV2(ctx context.Context, db database.DB) error {
 	return nil
 }
 
-// scriptSourceEntry houses a script and its associated version.
+// scriptSourceEntry houses a script and its associated version and amount.
 type scriptSourceEntry struct {
 	version uint16
 	script  []byte
+	amount  int64
 }
 
 // scriptSource provides a source of transaction output scripts and their
-// associated script version for given outpoints and implements the PrevScripter
-// interface so it may be used in cases that require access to said scripts.
+// associated script version and amount for given outpoints and implements the
+// PrevScripter and PrevAmounter interfaces so it may be used in cases that
+// require access to said scripts or amounts.
 type scriptSource map[wire.OutPoint]scriptSourceEntry
 
 // PrevScript returns the script and script version associated with the provided
@@ -574,6 +576,16 @@ func (s scriptSource) PrevScript(prevOut *wire.OutPoint) (uint16, []byte, bool)
 	return entry.version, entry.script, true
 }
 
+// PrevAmount returns the amount associated with the provided previous outpoint
+// along with a bool that indicates whether or not the requested entry exists.
+func (s scriptSource) PrevAmount(prevOut *wire.OutPoint) (int64, bool) {
+	entry, ok := s[*prevOut]
+	if !ok {
+		return 0, false
+	}
+	return entry.amount, true
+}
+
 // determineMinimalOutputsSizeV1 determines and returns the size of the stored
 // set of minimal outputs in a version 1 spend journal entry.
 func determineMinimalOutputsSizeV1(serialized []byte) (int, error) {
@@ -891,11 +903,15 @@ func scriptSourceFromSpendJournalV1(dbTx database.Tx, block *wire.MsgBlock) (scr
 			offset += scriptSize
 
 			// Create an output in the script source for the referenced script
-			// and version using the data from the spend journal.
+			// and version using the data from the spend journal.  The version 1
+			// spend journal does not store amounts, so the input value, which
+			// was proven correct when the block was validated, is used
+			// instead.
 			prevOut := &txIn.PreviousOutPoint
 			source[*prevOut] = scriptSourceEntry{
 				version: uint16(scriptVersion),
 				script:  decompressScriptV1(pkScript),
+				amount:  txIn.ValueIn,
 			}
 
 			// Deserialize the tx version and minimal outputs for tickets as
@@ -15,6 +15,7 @@ import (
 	"github.com/EXCCoin/exccd/blockchain/standalone/v2"
 	"github.com/EXCCoin/exccd/chaincfg/chainhash"
 	"github.com/EXCCoin/exccd/chaincfg/v3"
+	"github.com/EXCCoin/exccd/database/v3/ldbsnapshot"
 	"github.com/EXCCoin/exccd/wire"
 	"github.com/syndtr/goleveldb/leveldb"
 	ldberrors "github.com/syndtr/goleveldb/leveldb/errors"
@@ -373,6 +374,39 @@ func LoadUtxoDB(ctx context.Context, params *chaincfg.Params, dataDir string) (*
 	return db, nil
 }
 
+// LoadUtxoDBReadOnly opens a read-only snapshot of the existing UTXO database
+// in the provided data directory and returns a handle to it.  Unlike
+// LoadUtxoDB, it never modifies the database and does not require exclusive
+// access to it, so it may be used while the database is in use by another
+// process.  Modifications made to the database by other processes after it is
+// opened are not visible.
+//
+// The returned database is typically used along with the NoWrites option of
+// the chain configuration.
+func LoadUtxoDBReadOnly(dataDir string) (*ldbsnapshot.DB, error) {
+	dbPath := filepath.Join(dataDir, utxoDbName)
+	if !fileExists(dbPath) {
+		str := fmt.Sprintf("UTXO database %q does not exist", dbPath)
+		return nil, contextError(ErrUtxoBackend, str)
+	}
+
+	log.Infof("Loading read-only UTXO database from '%s'", dbPath)
+	opts := opt.Options{
+		Strict:      opt.DefaultStrict,
+		Compression: opt.NoCompression,
+		Filter:      filter.NewBloomFilter(10),
+	}
+	db, err := ldbsnapshot.OpenFile(dbPath, &opts)
+	if err != nil {
+		str := fmt.Sprintf("failed to open UTXO database: %v", err)
+		return nil, convertLdbErr(err, str)
+	}
+
+	log.Info("UTXO database loaded")
+
+	return db, nil
+}
+
 // NewLevelDbUtxoBackend returns a new instance of a backend that uses the
 // provided leveldb database for its underlying storage.
 func NewLevelDbUtxoBackend(db *leveldb.DB) UtxoBackend {
@@ -8,6 +8,7 @@ import (
 	"context"
 	"fmt"
 	"math"
+	"runtime"
 	"sync"
 	"time"
 
@@ -58,8 +59,62 @@ const (
 	// shutdown (or, in the case of an unclean shutdown, a significant amount of
 	// time to initialize the cache when restarted).
 	periodicFlushInterval = time.Minute * 2
+
+	// memStatsInterval is the minimum amount of time to wait between samples
+	// of the runtime memory statistics that are used to detect memory
+	// pressure.  Reading the statistics briefly stops the world, so they are
+	// not read every time a flush is considered.
+	memStatsInterval = time.Second * 10
+
+	// maxPrefetchWorkers is the maximum number of goroutines used to load
+	// entries from the backend in parallel when prefetching.
+	maxPrefetchWorkers = 8
+
+	// minPrefetchBatchSize is the minimum number of entries each prefetch
+	// worker loads.  It prevents spinning up goroutines for tiny batches.
+	minPrefetchBatchSize = 16
+)
+
+// flushReason identifies the reason the utxo cache was flushed.
+type flushReason uint8
+
+// These constants define the reasons the utxo cache may be flushed.
+const (
+	// flushReasonNone indicates no flush is required.
+	flushReasonNone flushReason = iota
+
+	// flushReasonForced indicates the caller forced the flush.
+	flushReasonForced
+
+	// flushReasonMaxSize indicates the cache reached its maximum size.
+	flushReasonMaxSize
+
+	// flushReasonMemoryPressure indicates the memory in use by the process
+	// exceeded the configured memory limit.
+	flushReasonMemoryPressure
+
+	// flushReasonPeriodic indicates the periodic flush interval elapsed.
+	flushReasonPeriodic
 )
 
+// flushReasonStrings is a map of flush reasons back to their names for pretty
+// printing.
+var flushReasonStrings = map[flushReason]string{
+	flushReasonNone:           "none",
+	flushReasonForced:         "forced",
+	flushReasonMaxSize:        "maxsize",
+	flushReasonMemoryPressure: "memorypressure",
+	flushReasonPeriodic:       "periodic",
+}
+
+// String returns the flushReason as a human-readable name.
+func (r flushReason) String() string {
+	if s := flushReasonStrings[r]; s != "" {
+		return s
+	}
+	return fmt.Sprintf("Unknown flushReason (%d)", uint8(r))
+}
+
 // UtxoCacher represents a utxo cache that sits on top of a utxo set backend.
 //
 // The interface contract requires that all of these methods are safe for
@@ -99,6 +154,9 @@ type UtxoCacher interface {
 	// FetchStats returns statistics on the current utxo set.
 	FetchStats(bestHash *chainhash.Hash, bestHeight uint32) (*UtxoStats, error)
 
+	// Info returns information about the state and performance of the cache.
+	Info() *UtxoCacheInfo
+
 	// Initialize initializes the utxo cache and underlying utxo backend.  This
 	// entails running any database migrations as well as ensuring that the utxo
 	// set is caught up to the tip of the best chain.
@@ -108,6 +166,11 @@ type UtxoCacher interface {
 	// be forced by setting the force flush parameter.
 	MaybeFlush(bestHash *chainhash.Hash, bestHeight uint32, forceFlush bool,
 		logFlush bool) error
+
+	// PrefetchEntries loads the requested transaction outputs that are not
+	// already in the cache from the backend in parallel and adds them to the
+	// cache so they are available when they are later fetched.
+	PrefetchEntries(filteredSet ViewFilteredSet) error
 }
 
 // UtxoCache is an unspent transaction output cache that sits on top of the
@@ -145,6 +208,12 @@ type UtxoCache struct {
 	// set when the instance is created and is not changed afterward.
 	maxSize uint64
 
+	// memoryLimit is the amount of memory in use by the process, in bytes, at
+	// which the cache is flushed and entries are evicted to relieve memory
+	// pressure.  A value of zero disables the check.  It is set when the
+	// instance is created and is not changed afterward.
+	memoryLimit uint64
+
 	// cacheLock protects access to the fields in the struct below this point.
 	// A standard mutex is used rather than a read-write mutex since the cache
 	// will often write when reads result in a cache miss, so it is generally
@@ -178,9 +247,26 @@ type UtxoCache struct {
 	totalEntrySize uint64
 
 	// The following fields track the total number of cache hits and misses and
-	// are used to measure the overall cache hit ratio.
-	hits   uint64
-	misses uint64
+	// are used to measure the overall cache hit ratio.  The number of entries
+	// loaded into the cache by prefetching is tracked separately.
+	hits       uint64
+	misses     uint64
+	prefetched uint64
+
+	// The following fields track the number of flushes along with details
+	// about how long they take and why they happened.  The number of flushes
+	// also allows prefetching to detect when the backend was modified while
+	// it was loading entries.
+	numFlushes         uint64
+	lastFlushReason    flushReason
+	lastFlushDuration  time.Duration
+	totalFlushDuration time.Duration
+
+	// memInUse is the amount of memory in use by the process, in bytes, as of
+	// the time the runtime memory statistics were last sampled at
+	// lastMemSample.  It is only sampled when a memory limit is configured.
+	memInUse      uint64
+	lastMemSample time.Time
 
 	// timeNow defines the function to use to get the current local time.  It
 	// defaults to time.Now but an alternative function can be provided for
@@ -192,6 +278,11 @@ type UtxoCache struct {
 	// this cache instance, but an alternative can be provided for testing
 	// purposes.
 	maybeFlushFn func(*chainhash.Hash, uint32, bool, bool) error
+
+	// readMemStats defines the function to use to read the runtime memory
+	// statistics.  It defaults to runtime.ReadMemStats but an alternative
+	// function can be provided for testing purposes.
+	readMemStats func(*runtime.MemStats)
 }
 
 // Ensure UtxoCache implements the UtxoCacher interface.
@@ -217,6 +308,14 @@ type UtxoCacheConfig struct {
 	//
 	// This field is required.
 	MaxSize uint64
+
+	// MemoryLimit defines the amount of memory in use by the process, in bytes,
+	// at which the cache is flushed and entries are evicted early to relieve
+	// memory pressure.
+	//
+	// This field can be zero to only flush based on the maximum size of the
+	// cache and the periodic flush interval.
+	MemoryLimit uint64
 }
 
 // NewUtxoCache returns a UtxoCache instance using the provided configuration
@@ -232,9 +331,11 @@ func NewUtxoCache(config *UtxoCacheConfig) *UtxoCache {
 		backend:       config.Backend,
 		flushBlockDB:  config.FlushBlockDB,
 		maxSize:       config.MaxSize,
+		memoryLimit:   config.MemoryLimit,
 		entries:       make(map[wire.OutPoint]*UtxoEntry, uint64(maxEntries)),
 		lastFlushTime: time.Now(),
 		timeNow:       time.Now,
+		readMemStats:  runtime.ReadMemStats,
 	}
 	c.maybeFlushFn = c.MaybeFlush
 	return c
@@ -554,11 +655,17 @@ func (c *UtxoCache) FetchBackendState() (*UtxoSetState, error) {
 
 // FetchStats returns statistics on the current utxo set.
 func (c *UtxoCache) FetchStats(bestHash *chainhash.Hash, bestHeight uint32) (*UtxoStats, error) {
-	// Force a UTXO cache flush.  This is required in order for the backend to
+	// Force a UTXO cache flush unless the cache has already been flushed
+	// through the best hash.  This is required in order for the backend to
 	// fetch statistics on the full UTXO set.
-	err := c.maybeFlushFn(bestHash, bestHeight, true, false)
-	if err != nil {
-		return nil, err
+	c.cacheLock.Lock()
+	flushed := c.lastFlushHash == *bestHash
+	c.cacheLock.Unlock()
+	if !flushed {
+		err := c.maybeFlushFn(bestHash, bestHeight, true, false)
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	return c.backend.FetchStats()
@@ -674,26 +781,61 @@ func (c *UtxoCache) calcEvictionHeight(bestHeight uint32) uint32 {
 	return c.lastEvictionHeight + uint32(numBlocksToEvict)
 }
 
-// shouldFlush returns whether or not a flush should be performed.
+// underMemoryPressure returns whether or not the memory in use by the process
+// exceeds the configured memory limit.  The runtime memory statistics are
+// sampled at most once per memory statistics interval.
 //
-// If the maximum size of the cache has been reached, or if the periodic flush
+// This function MUST be called with the cache lock held.
+func (c *UtxoCache) underMemoryPressure() bool {
+	if c.memoryLimit == 0 {
+		return false
+	}
+
+	now := c.timeNow()
+	if now.Sub(c.lastMemSample) >= memStatsInterval {
+		// The memory obtained from the OS that has not been released back to
+		// it is used as an approximation of the resident memory of the
+		// process.
+		var memStats runtime.MemStats
+		c.readMemStats(&memStats)
+		c.memInUse = memStats.Sys - memStats.HeapReleased
+		c.lastMemSample = now
+	}
+
+	return c.memInUse >= c.memoryLimit
+}
+
+// shouldFlush returns the reason a flush should be performed or
+// flushReasonNone when a flush is not required.
+//
+// If the maximum size of the cache has been reached, the memory in use by the
+// process has exceeded the configured memory limit, or the periodic flush
 // interval has been reached, then a flush is required.
 //
 // This function MUST be called with the cache lock held.
-func (c *UtxoCache) shouldFlush(bestHash *chainhash.Hash) bool {
+func (c *UtxoCache) shouldFlush(bestHash *chainhash.Hash) flushReason {
 	// No need to flush if the cache has already been flushed through the best
 	// hash.
 	if c.lastFlushHash == *bestHash {
-		return false
+		return flushReasonNone
 	}
 
 	// Flush if the max size of the cache has been reached.
 	if c.totalSize() >= c.maxSize {
-		return true
+		return flushReasonMaxSize
+	}
+
+	// Flush if the process is under memory pressure.
+	if c.underMemoryPressure() {
+		return flushReasonMemoryPressure
 	}
 
 	// Flush if the periodic flush interval has been reached.
-	return time.Since(c.lastFlushTime) >= periodicFlushInterval
+	if time.Since(c.lastFlushTime) >= periodicFlushInterval {
+		return flushReasonPeriodic
+	}
+
+	return flushReasonNone
 }
 
 // flush commits all modified entries to the backend and conditionally evicts
@@ -701,16 +843,20 @@ func (c *UtxoCache) shouldFlush(bestHash *chainhash.Hash) bool {
 //
 // Entries that are nil or spent are always evicted since they are
 // unlikely to be accessed again.  Additionally, if the cache has reached its
-// maximum size, entries are evicted based on the height of the block that they
-// are contained in.
+// maximum size or the flush is due to memory pressure, entries are evicted
+// based on the height of the block that they are contained in.
 //
 // This function MUST be called with the cache lock held.
-func (c *UtxoCache) flush(bestHash *chainhash.Hash, bestHeight uint32, logFlush bool) error {
-	// If the maximum allowed size of the cache has been reached, determine the
-	// eviction height.
+func (c *UtxoCache) flush(bestHash *chainhash.Hash, bestHeight uint32,
+	reason flushReason, logFlush bool) error {
+
+	start := time.Now()
+
+	// If the maximum allowed size of the cache has been reached or the process
+	// is under memory pressure, determine the eviction height.
 	var evictionHeight uint32
 	memUsage := c.totalSize()
-	if memUsage >= c.maxSize {
+	if memUsage >= c.maxSize || reason == flushReasonMemoryPressure {
 		evictionHeight = c.calcEvictionHeight(bestHeight)
 	}
 
@@ -728,8 +874,9 @@ func (c *UtxoCache) flush(bestHash *chainhash.Hash, bestHeight uint32, logFlush
 			evictionLog = fmt.Sprintf(", eviction height: %d", evictionHeight)
 		}
 		log.Debugf("UTXO cache flush starting (%d entries, %.2f MiB (%.2f%%), "+
-			"%.2f%% hit ratio, height: %d%s)", preFlushNumEntries, memUsageMiB,
-			memUsagePercent, hitRatio, bestHeight, evictionLog)
+			"%.2f%% hit ratio, height: %d, reason: %v%s)", preFlushNumEntries,
+			memUsageMiB, memUsagePercent, hitRatio, bestHeight, reason,
+			evictionLog)
 	}
 
 	// Flush the block database to disk.  The block database MUST always be
@@ -792,6 +939,20 @@ func (c *UtxoCache) flush(bestHash *chainhash.Hash, bestHeight uint32, logFlush
 		c.lastEvictionHeight = evictionHeight
 	}
 
+	// Require a fresh sample of the memory statistics before another flush is
+	// attributed to memory pressure since the memory freed by evicting entries
+	// is not reflected in them until it is reclaimed.
+	if reason == flushReasonMemoryPressure {
+		c.memInUse = 0
+		c.lastMemSample = c.timeNow()
+	}
+
+	// Update the flush statistics.
+	c.numFlushes++
+	c.lastFlushReason = reason
+	c.lastFlushDuration = time.Since(start)
+	c.totalFlushDuration += c.lastFlushDuration
+
 	// Log that the flush has been completed and indicate the updated memory
 	// usage as it will be reduced due to evicting entries above.
 	if logFlush {
@@ -800,8 +961,9 @@ func (c *UtxoCache) flush(bestHash *chainhash.Hash, bestHeight uint32, logFlush
 		memUsage = c.totalSize()
 		memUsageMiB := float64(memUsage) / 1024 / 1024
 		memUsagePercent := float64(memUsage) / float64(c.maxSize) * 100
-		log.Debugf("UTXO cache flush completed (%d entries flushed, %d "+
-			"entries remaining, %.2f MiB (%.2f%%))", flushedEntries,
+		log.Debugf("UTXO cache flush completed in %v (%d entries flushed, %d "+
+			"entries remaining, %.2f MiB (%.2f%%))",
+			c.lastFlushDuration.Round(time.Millisecond), flushedEntries,
 			remainingEntries, memUsageMiB, memUsagePercent)
 	}
 
@@ -810,7 +972,8 @@ func (c *UtxoCache) flush(bestHash *chainhash.Hash, bestHeight uint32, logFlush
 
 // MaybeFlush conditionally flushes the cache to the backend.
 //
-// If the maximum size of the cache has been reached, or if the periodic flush
+// If the maximum size of the cache has been reached, the memory in use by the
+// process has exceeded the configured memory limit, or the periodic flush
 // interval has been reached, then a flush is required.  Additionally, a flush
 // can be forced by setting the force flush parameter.
 //
@@ -819,8 +982,12 @@ func (c *UtxoCache) MaybeFlush(bestHash *chainhash.Hash, bestHeight uint32,
 	forceFlush bool, logFlush bool) error {
 
 	c.cacheLock.Lock()
-	if forceFlush || c.shouldFlush(bestHash) {
-		err := c.flush(bestHash, bestHeight, logFlush)
+	reason := flushReasonForced
+	if !forceFlush {
+		reason = c.shouldFlush(bestHash)
+	}
+	if reason != flushReasonNone {
+		err := c.flush(bestHash, bestHeight, reason, logFlush)
 		c.cacheLock.Unlock()
 		return err
 	}
@@ -829,6 +996,185 @@ func (c *UtxoCache) MaybeFlush(bestHash *chainhash.Hash, bestHeight uint32,
 	return nil
 }
 
+// PrefetchEntries loads the requested transaction outputs that are not already
+// in the cache from the backend in parallel and adds them to the cache so they
+// are available when they are later fetched.  Outputs that do not exist in the
+// backend are not added to the cache.
+//
+// The backend is read without holding the cache lock so the cache remains
+// usable while the entries are loaded.  The loaded entries are discarded when
+// the cache was flushed in the mean time since they might no longer reflect
+// the state of the backend.
+//
+// This function is safe for concurrent access.
+func (c *UtxoCache) PrefetchEntries(filteredSet ViewFilteredSet) error {
+	// Determine which of the requested outputs are not already in the cache
+	// along with the number of flushes so it can later be determined whether
+	// or not the backend was modified while loading them.
+	c.cacheLock.Lock()
+	numFlushes := c.numFlushes
+	outpoints := make([]wire.OutPoint, 0, len(filteredSet))
+	for outpoint := range filteredSet {
+		if _, ok := c.entries[outpoint]; !ok {
+			outpoints = append(outpoints, outpoint)
+		}
+	}
+	c.cacheLock.Unlock()
+	if len(outpoints) == 0 {
+		return nil
+	}
+
+	// Load the entries from the backend in parallel.
+	numWorkers := runtime.NumCPU()
+	if numWorkers > maxPrefetchWorkers {
+		numWorkers = maxPrefetchWorkers
+	}
+	if maxWorkers := (len(outpoints) + minPrefetchBatchSize - 1) /
+		minPrefetchBatchSize; numWorkers > maxWorkers {
+
+		numWorkers = maxWorkers
+	}
+	entries := make([]*UtxoEntry, len(outpoints))
+	errs := make([]error, numWorkers)
+	var wg sync.WaitGroup
+	wg.Add(numWorkers)
+	for worker := 0; worker < numWorkers; worker++ {
+		go func(worker int) {
+			defer wg.Done()
+			for i := worker; i < len(outpoints); i += numWorkers {
+				entry, err := c.backend.FetchEntry(outpoints[i])
+				if err != nil {
+					errs[worker] = err
+					return
+				}
+				entries[i] = entry
+			}
+		}(worker)
+	}
+	wg.Wait()
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+
+	// Add the loaded entries to the cache unless the cache was flushed while
+	// they were being loaded or they were otherwise added to the cache in the
+	// mean time.
+	c.cacheLock.Lock()
+	defer c.cacheLock.Unlock()
+	if c.numFlushes != numFlushes {
+		return nil
+	}
+	for i, entry := range entries {
+		if entry == nil {
+			continue
+		}
+		outpoint := outpoints[i]
+		if _, ok := c.entries[outpoint]; ok {
+			continue
+		}
+		c.entries[outpoint] = entry
+		c.totalEntrySize += entry.size()
+		c.prefetched++
+	}
+
+	return nil
+}
+
+// UtxoCacheInfo houses information about the state and performance of the utxo
+// cache.
+type UtxoCacheInfo struct {
+	// MaxSize is the maximum allowed size of the cache, in bytes.
+	MaxSize uint64
+
+	// Size is the approximate current size of the cache, in bytes.
+	Size uint64
+
+	// Entries is the number of entries in the cache.
+	Entries uint64
+
+	// DirtyEntries is the number of entries in the cache that have been
+	// modified since they were last flushed to the backend and DirtySize is
+	// their total size, in bytes.
+	DirtyEntries uint64
+	DirtySize    uint64
+
+	// Hits and Misses are the total number of cache lookups that were and were
+	// not satisfied by the cache, respectively, and HitRatio is the
+	// percentage of lookups that were hits.
+	Hits     uint64
+	Misses   uint64
+	HitRatio float64
+
+	// Prefetched is the total number of entries loaded into the cache by
+	// prefetching.
+	Prefetched uint64
+
+	// MemoryLimit is the configured memory limit at which the cache is flushed
+	// due to memory pressure, in bytes, and MemoryInUse is the most recently
+	// sampled memory in use by the process, in bytes.  They are both zero when
+	// no memory limit is configured.
+	MemoryLimit uint64
+	MemoryInUse uint64
+
+	// Flushes is the total number of flushes to the backend.
+	Flushes uint64
+
+	// LastFlushHash is the hash of the best block as of the last flush.
+	LastFlushHash chainhash.Hash
+
+	// LastFlushTime is the time of the last flush.
+	LastFlushTime time.Time
+
+	// LastFlushReason is the reason for the last flush.
+	LastFlushReason string
+
+	// LastFlushDuration and TotalFlushDuration are the amount of time the
+	// last flush and all flushes took, respectively.
+	LastFlushDuration  time.Duration
+	TotalFlushDuration time.Duration
+}
+
+// Info returns information about the state and performance of the cache.
+//
+// Note that determining the dirty entries requires iterating all entries in
+// the cache, so this should not be called frequently.
+//
+// This function is safe for concurrent access.
+func (c *UtxoCache) Info() *UtxoCacheInfo {
+	c.cacheLock.Lock()
+	defer c.cacheLock.Unlock()
+
+	var dirtyEntries, dirtySize uint64
+	for _, entry := range c.entries {
+		if entry != nil && entry.isModified() {
+			dirtyEntries++
+			dirtySize += entry.size()
+		}
+	}
+
+	return &UtxoCacheInfo{
+		MaxSize:            c.maxSize,
+		Size:               c.totalSize(),
+		Entries:            uint64(len(c.entries)),
+		DirtyEntries:       dirtyEntries,
+		DirtySize:          dirtySize,
+		Hits:               c.hits,
+		Misses:             c.misses,
+		HitRatio:           c.hitRatio(),
+		Prefetched:         c.prefetched,
+		MemoryLimit:        c.memoryLimit,
+		MemoryInUse:        c.memInUse,
+		Flushes:            c.numFlushes,
+		LastFlushHash:      c.lastFlushHash,
+		LastFlushTime:      c.lastFlushTime,
+		LastFlushReason:    c.lastFlushReason.String(),
+		LastFlushDuration:  c.lastFlushDuration,
+		TotalFlushDuration: c.totalFlushDuration,
+	}
+}
+
 // Initialize initializes the utxo cache and underlying utxo backend.  This
 // entails running any database migrations as well as ensuring that the utxo set
 // is caught up to the tip of the best chain.
@@ -843,10 +1189,13 @@ func (c *UtxoCache) Initialize(ctx context.Context, b *BlockChain, tip *blockNod
 	log.Infof("UTXO cache initializing (max size: %d MiB)...",
 		c.maxSize/1024/1024)
 
-	// Upgrade the UTXO backend as needed.
-	err := c.backend.Upgrade(ctx, b)
-	if err != nil {
-		return err
+	// Upgrade the UTXO backend as needed.  The chain ensures no upgrades are
+	// needed when writes are disabled.
+	if !b.noWrites {
+		err := c.backend.Upgrade(ctx, b)
+		if err != nil {
+			return err
+		}
 	}
 
 	// Fetch the utxo set state from the backend.
@@ -859,6 +1208,9 @@ func (c *UtxoCache) Initialize(ctx context.Context, b *BlockChain, tip *blockNod
 	// the case when starting from a fresh backend or a backend that has not
 	// been run with the utxo cache yet.
 	if state == nil {
+		if b.noWrites {
+			return noWritesError("initialize the UTXO set state")
+		}
 		state = &UtxoSetState{
 			lastFlushHeight: uint32(tip.height),
 			lastFlushHash:   tip.hash,
@@ -982,13 +1334,16 @@ func (c *UtxoCache) Initialize(ctx context.Context, b *BlockChain, tip *blockNod
 
 		// Conditionally flush the utxo cache to the backend.  Don't force flush
 		// since many blocks may be disconnected and connected in quick
-		// succession when initializing.
-		const forceFlush = false
-		const logFlush = true
-		err = c.maybeFlushFn(&n.parent.hash, uint32(n.parent.height),
-			forceFlush, logFlush)
-		if err != nil {
-			return err
+		// succession when initializing.  The cache is never flushed when writes
+		// are disabled.
+		if !b.noWrites {
+			const forceFlush = false
+			const logFlush = true
+			err = c.maybeFlushFn(&n.parent.hash, uint32(n.parent.height),
+				forceFlush, logFlush)
+			if err != nil {
+				return err
+			}
 		}
 
 		n = n.parent
@@ -1067,12 +1422,14 @@ func (c *UtxoCache) Initialize(ctx context.Context, b *BlockChain, tip *blockNod
 
 		// Conditionally flush the utxo cache to the backend.  Don't force flush
 		// since many blocks may be connected in quick succession when
-		// initializing.
-		const forceFlush = false
-		const logFlush = true
-		err = c.maybeFlushFn(&n.hash, uint32(n.height), forceFlush, logFlush)
-		if err != nil {
-			return err
+		// initializing.  The cache is never flushed when writes are disabled.
+		if !b.noWrites {
+			const forceFlush = false
+			const logFlush = true
+			err = c.maybeFlushFn(&n.hash, uint32(n.height), forceFlush, logFlush)
+			if err != nil {
+				return err
+			}
 		}
 	}
 
@@ -1093,6 +1450,11 @@ func (c *UtxoCache) Initialize(ctx context.Context, b *BlockChain, tip *blockNod
 //
 // This function should only be called during shutdown.
 func (b *BlockChain) ShutdownUtxoCache() {
+	// There is nothing to flush when writes are disabled.
+	if b.noWrites {
+		return
+	}
+
 	b.chainLock.RLock()
 	defer b.chainLock.RUnlock()
 
@@ -1122,5 +1484,71 @@ func (b *BlockChain) FetchUtxoEntry(outpoint wire.OutPoint) (*UtxoEntry, error)
 // FetchUtxoStats returns statistics on the current utxo set.
 func (b *BlockChain) FetchUtxoStats() (*UtxoStats, error) {
 	tip := b.bestChain.Tip()
+
+	// The statistics are calculated from the backend, which requires flushing
+	// the cache to it unless the backend is already caught up to the tip.
+	if b.noWrites {
+		state, err := b.utxoCache.FetchBackendState()
+		if err != nil {
+			return nil, err
+		}
+		if state == nil || state.lastFlushHash != tip.hash {
+			return nil, noWritesError("flush the UTXO cache to calculate " +
+				"UTXO set statistics")
+		}
+	}
+
 	return b.utxoCache.FetchStats(&tip.hash, uint32(tip.height))
 }
+
+// UtxoCacheInfo returns information about the state and performance of the utxo
+// cache.
+//
+// This function is safe for concurrent access.
+func (b *BlockChain) UtxoCacheInfo() *UtxoCacheInfo {
+	return b.utxoCache.Info()
+}
+
+// prefetchBlockInputs asynchronously loads the outputs spent by the
+// transactions in the provided block into the utxo cache so they are already
+// available when the block is connected.  Since it is only an optimization, any
+// errors are logged and otherwise ignored.
+//
+// Outputs created by transactions in the same block and the special inputs of
+// coinbases, stakebases, treasurybases, and treasury spends are not requested
+// since they never exist in the backend.
+func (b *BlockChain) prefetchBlockInputs(block *dcrutil.Block) {
+	msgBlock := block.MsgBlock()
+	inBlock := make(map[chainhash.Hash]struct{}, len(msgBlock.Transactions))
+	for _, tx := range block.Transactions() {
+		inBlock[*tx.Hash()] = struct{}{}
+	}
+
+	filteredSet := make(ViewFilteredSet)
+	addInputs := func(txns []*wire.MsgTx) {
+		for _, tx := range txns {
+			for _, txIn := range tx.TxIn {
+				prevOut := &txIn.PreviousOutPoint
+				if prevOut.Hash == *zeroHash {
+					continue
+				}
+				if _, ok := inBlock[prevOut.Hash]; ok {
+					continue
+				}
+				filteredSet[*prevOut] = struct{}{}
+			}
+		}
+	}
+	addInputs(msgBlock.Transactions)
+	addInputs(msgBlock.STransactions)
+	if len(filteredSet) == 0 {
+		return
+	}
+
+	go func() {
+		if err := b.utxoCache.PrefetchEntries(filteredSet); err != nil {
+			log.Debugf("Unable to prefetch inputs for block %s: %v",
+				block.Hash(), err)
+		}
+	}()
+}
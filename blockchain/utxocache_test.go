@@ -8,6 +8,7 @@ import (
 	"context"
 	"errors"
 	"reflect"
+	"runtime"
 	"testing"
 	"time"
 
@@ -1039,10 +1040,12 @@ func TestShouldFlush(t *testing.T) {
 		name           string
 		totalEntrySize uint64
 		maxSize        uint64
+		memoryLimit    uint64
+		memInUse       uint64
 		lastFlushTime  time.Time
 		lastFlushHash  *chainhash.Hash
 		bestHash       *chainhash.Hash
-		want           bool
+		want           flushReason
 	}{{
 		name:           "already flushed through the best hash",
 		totalEntrySize: 100,
@@ -1050,7 +1053,7 @@ func TestShouldFlush(t *testing.T) {
 		lastFlushTime:  time.Now(),
 		lastFlushHash:  block1000Hash,
 		bestHash:       block1000Hash,
-		want:           false,
+		want:           flushReasonNone,
 	}, {
 		name:           "less than max size and periodic duration not reached",
 		totalEntrySize: 100,
@@ -1058,7 +1061,7 @@ func TestShouldFlush(t *testing.T) {
 		lastFlushTime:  time.Now(),
 		lastFlushHash:  block1000Hash,
 		bestHash:       block2000Hash,
-		want:           false,
+		want:           flushReasonNone,
 	}, {
 		name:           "equal to max size",
 		totalEntrySize: 1000,
@@ -1066,7 +1069,7 @@ func TestShouldFlush(t *testing.T) {
 		lastFlushTime:  time.Now(),
 		lastFlushHash:  block1000Hash,
 		bestHash:       block2000Hash,
-		want:           true,
+		want:           flushReasonMaxSize,
 	}, {
 		name:           "greater than max size",
 		totalEntrySize: 1001,
@@ -1074,7 +1077,7 @@ func TestShouldFlush(t *testing.T) {
 		lastFlushTime:  time.Now(),
 		lastFlushHash:  block1000Hash,
 		bestHash:       block2000Hash,
-		want:           true,
+		want:           flushReasonMaxSize,
 	}, {
 		name:           "less than max size but periodic duration reached",
 		totalEntrySize: 100,
@@ -1082,18 +1085,43 @@ func TestShouldFlush(t *testing.T) {
 		lastFlushTime:  time.Now().Add(periodicFlushInterval * -1),
 		lastFlushHash:  block1000Hash,
 		bestHash:       block2000Hash,
-		want:           true,
+		want:           flushReasonPeriodic,
+	}, {
+		name:           "less than max size and memory limit not reached",
+		totalEntrySize: 100,
+		maxSize:        1000,
+		memoryLimit:    5000,
+		memInUse:       4999,
+		lastFlushTime:  time.Now(),
+		lastFlushHash:  block1000Hash,
+		bestHash:       block2000Hash,
+		want:           flushReasonNone,
+	}, {
+		name:           "less than max size but memory limit reached",
+		totalEntrySize: 100,
+		maxSize:        1000,
+		memoryLimit:    5000,
+		memInUse:       5000,
+		lastFlushTime:  time.Now(),
+		lastFlushHash:  block1000Hash,
+		bestHash:       block2000Hash,
+		want:           flushReasonMemoryPressure,
 	}}
 
 	for _, test := range tests {
 		// Create a utxo cache and set the field values as specified by the
 		// test.
 		utxoCache := NewUtxoCache(&UtxoCacheConfig{
-			MaxSize: test.maxSize,
+			MaxSize:     test.maxSize,
+			MemoryLimit: test.memoryLimit,
 		})
 		utxoCache.totalEntrySize = test.totalEntrySize
 		utxoCache.lastFlushTime = test.lastFlushTime
 		utxoCache.lastFlushHash = *test.lastFlushHash
+		memInUse := test.memInUse
+		utxoCache.readMemStats = func(memStats *runtime.MemStats) {
+			memStats.Sys = memInUse
+		}
 
 		// Validate that should flush returns the expected value.
 		got := utxoCache.shouldFlush(test.bestHash)
@@ -1104,6 +1132,32 @@ func TestShouldFlush(t *testing.T) {
 	}
 }
 
+// TestFlushReasonStringer tests the stringized output for the flushReason
+// type.
+func TestFlushReasonStringer(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		in   flushReason
+		want string
+	}{
+		{flushReasonNone, "none"},
+		{flushReasonForced, "forced"},
+		{flushReasonMaxSize, "maxsize"},
+		{flushReasonMemoryPressure, "memorypressure"},
+		{flushReasonPeriodic, "periodic"},
+		{0xff, "Unknown flushReason (255)"},
+	}
+
+	for i, test := range tests {
+		result := test.in.String()
+		if result != test.want {
+			t.Errorf("String #%d\n got: %s want: %s", i, result, test.want)
+			continue
+		}
+	}
+}
+
 // TestMaybeFlush validates that the cache is properly flushed to the backend
 // under a variety of conditions.
 func TestMaybeFlush(t *testing.T) {
@@ -202,6 +202,16 @@ func (view *UtxoViewpoint) PrevScript(prevOut *wire.OutPoint) (uint16, []byte, b
 	return version, pkScript, true
 }
 
+// PrevAmount returns the amount associated with the provided previous outpoint
+// along with a bool that indicates whether or not the requested entry exists.
+func (view *UtxoViewpoint) PrevAmount(prevOut *wire.OutPoint) (int64, bool) {
+	entry := view.LookupEntry(*prevOut)
+	if entry == nil {
+		return 0, false
+	}
+	return entry.Amount(), true
+}
+
 // PriorityInput returns the block height and amount associated with the
 // provided previous outpoint along with a bool that indicates whether or not
 // the requested entry exists.  This ensures the caller is able to distinguish
@@ -8,13 +8,16 @@ package main
 import (
 	"encoding/binary"
 	"errors"
+	"fmt"
 	_ "net/http/pprof"
 	"os"
 	"path/filepath"
 
 	"github.com/EXCCoin/exccd/blockchain/v4"
+	"github.com/EXCCoin/exccd/blockchain/v4/indexers"
 	"github.com/EXCCoin/exccd/chaincfg/v3"
 	"github.com/EXCCoin/exccd/database/v3"
+	"github.com/EXCCoin/exccd/database/v3/ffldb"
 	"github.com/EXCCoin/exccd/internal/progresslog"
 )
 
@@ -23,6 +26,16 @@ const (
 	// database type is appended to this value to form the full block
 	// database name.
 	blockDbNamePrefix = "blocks"
+
+	// indexDbNamePrefix is the prefix for the name of the database that
+	// houses the optional indexes when they are stored separately from the
+	// block database.  The database type is appended to this value to form
+	// the full index database name.
+	indexDbNamePrefix = "indexes"
+
+	// utxoDbName is the name of the UTXO database within the UTXO directory.
+	// It must match the name used by the blockchain package.
+	utxoDbName = "utxodb"
 )
 
 // removeDB removes the database at the provided path.  The fi parameter MUST
@@ -60,10 +73,88 @@ func blockDbPath(dbType string) string {
 	if dbType == "sqlite" {
 		dbName = dbName + ".db"
 	}
-	dbPath := filepath.Join(cfg.DataDir, dbName)
+	dbPath := filepath.Join(cfg.BlocksDir, dbName)
 	return dbPath
 }
 
+// indexDbPath returns the path to the separate index database given a database
+// type.
+func indexDbPath(dbType string) string {
+	return filepath.Join(cfg.IndexDir, indexDbNamePrefix+"_"+dbType)
+}
+
+// checkDataLayout ensures the block, UTXO, and separate index databases are
+// either all found in their configured directories or none of them exist yet.
+// This detects configurations that would otherwise silently create new empty
+// databases next to existing ones, such as specifying a new directory for one
+// of the databases without moving it there.
+func checkDataLayout() error {
+	// The databases are recreated on each run in regression test mode, so
+	// there is nothing to check.
+	if cfg.RegNet {
+		return nil
+	}
+
+	// Detect databases that still exist in the data directory after the
+	// directory for them was changed.
+	blockPath := blockDbPath(cfg.DbType)
+	utxoPath := filepath.Join(cfg.UtxoDir, utxoDbName)
+	movedChecks := []struct {
+		name       string
+		option     string
+		dir        string
+		path       string
+		legacyPath string
+	}{{
+		name:       "block",
+		option:     "blocksdir",
+		dir:        cfg.BlocksDir,
+		path:       blockPath,
+		legacyPath: filepath.Join(cfg.DataDir, filepath.Base(blockPath)),
+	}, {
+		name:       "UTXO",
+		option:     "utxodir",
+		dir:        cfg.UtxoDir,
+		path:       utxoPath,
+		legacyPath: filepath.Join(cfg.DataDir, utxoDbName),
+	}}
+	for _, check := range movedChecks {
+		if check.dir == cfg.DataDir || fileExists(check.path) ||
+			!fileExists(check.legacyPath) {
+
+			continue
+		}
+		return fmt.Errorf("the %s database does not exist in the %s "+
+			"directory %q, but one exists in the data directory at %q -- "+
+			"use the dbtool relocate command to move it", check.name,
+			check.option, check.dir, check.legacyPath)
+	}
+
+	// Detect databases that are missing their counterparts which usually
+	// means one of the directories is misconfigured.
+	blockExists := fileExists(blockPath)
+	switch {
+	case !blockExists && fileExists(utxoPath):
+		return fmt.Errorf("the UTXO database at %q exists without the "+
+			"block database at %q -- ensure the blocksdir option is "+
+			"correct", utxoPath, blockPath)
+
+	case blockExists && !fileExists(utxoPath) && cfg.UtxoDir != cfg.DataDir:
+		return fmt.Errorf("the block database at %q exists without the "+
+			"UTXO database at %q -- ensure the utxodir option is "+
+			"correct", blockPath, utxoPath)
+
+	case !blockExists && cfg.IndexDir != "" &&
+		fileExists(indexDbPath(cfg.DbType)):
+
+		return fmt.Errorf("the index database at %q exists without the "+
+			"block database at %q -- ensure the blocksdir option is "+
+			"correct", indexDbPath(cfg.DbType), blockPath)
+	}
+
+	return nil
+}
+
 // warnMultipleDBs shows a warning if multiple block database types are detected.
 // This is not a situation most users want.  It is handy for development however
 // to support multiple side-by-side databases.
@@ -123,22 +214,28 @@ func loadBlockDB(params *chaincfg.Params) (database.DB, error) {
 	// each run, so remove it now if it already exists.
 	removeRegressionDB(dbPath)
 
+	// Compress newly written blocks when requested.
+	dbArgs := []interface{}{dbPath, params.Net}
+	if cfg.CompressBlocks && cfg.DbType == "ffldb" {
+		dbArgs = append(dbArgs, ffldb.BlockCompressionDeflate)
+	}
+
 	// createDB is a convenience func that creates the database with the type
 	// and network specified in the config at the path determined above while
-	// also creating any intermediate directories in the configured data directory
-	// path as needed.
+	// also creating any intermediate directories in the configured blocks
+	// directory path as needed.
 	createDB := func() (database.DB, error) {
-		// Create the data dir if it does not exist.
-		err := os.MkdirAll(cfg.DataDir, 0700)
+		// Create the blocks dir if it does not exist.
+		err := os.MkdirAll(cfg.BlocksDir, 0700)
 		if err != nil {
 			return nil, err
 		}
-		return database.Create(cfg.DbType, dbPath, params.Net)
+		return database.Create(cfg.DbType, dbArgs...)
 	}
 
 	// Open the existing database or create a new one as needed.
 	dcrdLog.Infof("Loading block database from '%s'", dbPath)
-	db, err := database.Open(cfg.DbType, dbPath, params.Net)
+	db, err := database.Open(cfg.DbType, dbArgs...)
 	if err != nil {
 		// Return the error if it's not because the database doesn't exist.
 		if !errors.Is(err, database.ErrDbDoesNotExist) {
@@ -187,6 +284,54 @@ func loadBlockDB(params *chaincfg.Params) (database.DB, error) {
 	return db, nil
 }
 
+// loadIndexDB loads (or creates when needed) the database that houses the
+// optional indexes.  The indexes are stored in the provided block database
+// unless a separate index directory is configured.
+func loadIndexDB(params *chaincfg.Params, blockDB database.DB) (database.DB, error) {
+	if cfg.IndexDir == "" {
+		return blockDB, nil
+	}
+
+	dbPath := indexDbPath(cfg.DbType)
+
+	// The regression test is special in that it needs a clean database for
+	// each run, so remove it now if it already exists.
+	removeRegressionDB(dbPath)
+
+	dcrdLog.Infof("Loading index database from '%s'", dbPath)
+	db, err := database.Open(cfg.DbType, dbPath, params.Net)
+	if err != nil {
+		// Return the error if it's not because the database doesn't exist.
+		if !errors.Is(err, database.ErrDbDoesNotExist) {
+			return nil, err
+		}
+
+		// Refuse to create a new separate index database when the block
+		// database already houses indexes since they would otherwise be
+		// silently rebuilt from scratch while the existing ones linger.
+		hasIndexes, err := indexers.HasIndexes(blockDB)
+		if err != nil {
+			return nil, err
+		}
+		if hasIndexes {
+			return nil, fmt.Errorf("the block database houses existing "+
+				"indexes, but the index database at %q does not exist -- "+
+				"use the dbtool relocate command to move them", dbPath)
+		}
+
+		if err := os.MkdirAll(cfg.IndexDir, 0700); err != nil {
+			return nil, err
+		}
+		db, err = database.Create(cfg.DbType, dbPath, params.Net)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	dcrdLog.Info("Index database loaded")
+	return db, nil
+}
+
 // dumpBlockChain dumps a map of the blockchain blocks as serialized bytes.
 func dumpBlockChain(params *chaincfg.Params, b *blockchain.BlockChain) error {
 	dcrdLog.Infof("Writing the blockchain to flat file %q.  This might take a "+
@@ -384,7 +384,7 @@ func newBlockImporter(ctx context.Context, db database.DB, utxoDb *leveldb.DB, r
 		}
 	}
 
-	err = subber.CatchUp(ctx, db, queryer)
+	err = subber.CatchUp(ctx, queryer)
 	if err != nil {
 		return nil, err
 	}
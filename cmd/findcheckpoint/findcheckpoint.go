@@ -15,6 +15,7 @@ import (
 	"github.com/EXCCoin/exccd/chaincfg/chainhash"
 	"github.com/EXCCoin/exccd/chaincfg/v3"
 	"github.com/EXCCoin/exccd/database/v3"
+	"github.com/EXCCoin/exccd/database/v3/ffldb"
 )
 
 const blockDbNamePrefix = "blocks"
@@ -23,13 +24,15 @@ var (
 	cfg *config
 )
 
-// loadBlockDB opens the block database and returns a handle to it.
+// loadBlockDB opens a read-only snapshot of the block database and returns a
+// handle to it.  This allows the utility to be used while exccd is running.
 func loadBlockDB() (database.DB, error) {
 	// The database name is based on the database type.
 	dbName := blockDbNamePrefix + "_" + cfg.DbType
 	dbPath := filepath.Join(cfg.DataDir, dbName)
 	fmt.Printf("Loading block database from '%s'\n", dbPath)
-	db, err := database.Open(cfg.DbType, dbPath, activeNetParams.Net)
+	db, err := database.Open(cfg.DbType, dbPath, activeNetParams.Net,
+		ffldb.BlockCompressionNone, ffldb.AccessReadOnly)
 	if err != nil {
 		return nil, err
 	}
@@ -149,9 +152,8 @@ func main() {
 	}
 	defer db.Close()
 
-	// Load the UTXO database.
-	utxoDb, err := blockchain.LoadUtxoDB(context.Background(), activeNetParams,
-		cfg.DataDir)
+	// Load a read-only snapshot of the UTXO database.
+	utxoDb, err := blockchain.LoadUtxoDBReadOnly(cfg.DataDir)
 	if err != nil {
 		fmt.Fprintln(os.Stderr, "failed to load UTXO database:", err)
 		return
@@ -159,21 +161,22 @@ func main() {
 	defer utxoDb.Close()
 
 	// Instantiate a UTXO backend and UTXO cache.
-	utxoBackend := blockchain.NewLevelDbUtxoBackend(utxoDb)
+	utxoBackend := blockchain.NewLevelDbUtxoBackend(utxoDb.DB)
 	utxoCache := blockchain.NewUtxoCache(&blockchain.UtxoCacheConfig{
 		Backend:      utxoBackend,
 		FlushBlockDB: db.Flush,
 		MaxSize:      100 * 1024 * 1024, // 100 MiB
 	})
 
-	// Setup chain.  Ignore notifications since they aren't needed for this
-	// util.
+	// Setup chain with writes disabled since the databases are read-only.
+	// Ignore notifications since they aren't needed for this util.
 	chain, err := blockchain.New(context.Background(),
 		&blockchain.Config{
 			DB:          db,
 			ChainParams: activeNetParams,
-			UtxoBackend: blockchain.NewLevelDbUtxoBackend(utxoDb),
+			UtxoBackend: blockchain.NewLevelDbUtxoBackend(utxoDb.DB),
 			UtxoCache:   utxoCache,
+			NoWrites:    true,
 		})
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "failed to initialize chain: %v\n", err)
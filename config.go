@@ -57,6 +57,9 @@ const (
 	defaultMaxRPCWebsockets     = 25
 	defaultMaxRPCConcurrentReqs = 20
 
+	// Defaults for P2P proxy and Tor settings.
+	defaultTorControlPort = "9051"
+
 	// Defaults for P2P network options.
 	defaultMaxSameIP       = 5
 	defaultMaxPeers        = 125
@@ -84,6 +87,13 @@ const (
 	defaultTxIndex           = false
 	defaultAddrIndex         = false
 	defaultNoExistsAddrIndex = false
+	defaultSpenderIndex      = false
+	defaultAddrBalanceIndex  = false
+	defaultBlockStatsIndex   = false
+	defaultTicketIndex       = false
+	defaultCoinStatsIndex    = false
+	defaultCFHeaderIndex     = false
+	defaultScriptIndex       = false
 
 	// Authorization types.
 	authTypeBasic      = "basic"
@@ -123,23 +133,29 @@ func minUint32(a, b uint32) uint32 {
 // See loadConfig for details on the configuration load process.
 type config struct {
 	// General application behavior.
-	ShowVersion      bool   `short:"V" long:"version" description:"Display version information and exit"`
-	HomeDir          string `short:"A" long:"appdata" description:"Path to application home directory"`
-	ConfigFile       string `short:"C" long:"configfile" description:"Path to configuration file"`
-	DataDir          string `short:"b" long:"datadir" description:"Directory to store data"`
-	LogDir           string `long:"logdir" description:"Directory to log output"`
-	LogSize          string `long:"logsize" description:"Maximum size of log file before it is rotated"`
-	NoFileLogging    bool   `long:"nofilelogging" description:"Disable file logging"`
-	DbType           string `long:"dbtype" description:"Database backend to use for the block chain"`
-	Profile          string `long:"profile" description:"Enable HTTP profiling on given [addr:]port -- NOTE port must be between 1024 and 65536"`
-	CPUProfile       string `long:"cpuprofile" description:"Write CPU profile to the specified file"`
-	MemProfile       string `long:"memprofile" description:"Write mem profile to the specified file"`
-	TestNet          bool   `long:"testnet" description:"Use the test network"`
-	SimNet           bool   `long:"simnet" description:"Use the simulation test network"`
-	RegNet           bool   `long:"regnet" description:"Use the regression test network"`
-	DebugLevel       string `short:"d" long:"debuglevel" description:"Logging level for all subsystems {trace, debug, info, warn, error, critical} -- You may also specify <subsystem>=<level>,<subsystem2>=<level>,... to set the log level for individual subsystems -- Use show to list available subsystems"`
-	SigCacheMaxSize  uint   `long:"sigcachemaxsize" description:"The maximum number of entries in the signature verification cache"`
-	UtxoCacheMaxSize uint   `long:"utxocachemaxsize" description:"The maximum size in MiB of the utxo cache"`
+	ShowVersion       bool   `short:"V" long:"version" description:"Display version information and exit"`
+	HomeDir           string `short:"A" long:"appdata" description:"Path to application home directory"`
+	ConfigFile        string `short:"C" long:"configfile" description:"Path to configuration file"`
+	DataDir           string `short:"b" long:"datadir" description:"Directory to store data"`
+	BlocksDir         string `long:"blocksdir" description:"Directory to store the block database (default: datadir)"`
+	UtxoDir           string `long:"utxodir" description:"Directory to store the UTXO database (default: datadir)"`
+	IndexDir          string `long:"indexdir" description:"Directory to store the optional indexes in a database separate from the block database (default: store them in the block database)"`
+	LogDir            string `long:"logdir" description:"Directory to log output"`
+	LogSize           string `long:"logsize" description:"Maximum size of log file before it is rotated"`
+	NoFileLogging     bool   `long:"nofilelogging" description:"Disable file logging"`
+	DbType            string `long:"dbtype" description:"Database backend to use for the block chain"`
+	CompressBlocks    bool   `long:"compressblocks" description:"Compress blocks written to the block database to reduce disk usage -- NOTE: Existing blocks are not affected -- Use the dbtool compressblocks command to compress them"`
+	ReindexChainState bool   `long:"reindexchainstate" description:"Rebuild the UTXO set, spend journal, and ticket database from the blocks already stored in the block database on start up -- NOTE: This can take a long time"`
+	Profile           string `long:"profile" description:"Enable HTTP profiling on given [addr:]port -- NOTE port must be between 1024 and 65536"`
+	CPUProfile        string `long:"cpuprofile" description:"Write CPU profile to the specified file"`
+	MemProfile        string `long:"memprofile" description:"Write mem profile to the specified file"`
+	TestNet           bool   `long:"testnet" description:"Use the test network"`
+	SimNet            bool   `long:"simnet" description:"Use the simulation test network"`
+	RegNet            bool   `long:"regnet" description:"Use the regression test network"`
+	DebugLevel        string `short:"d" long:"debuglevel" description:"Logging level for all subsystems {trace, debug, info, warn, error, critical} -- You may also specify <subsystem>=<level>,<subsystem2>=<level>,... to set the log level for individual subsystems -- Use show to list available subsystems"`
+	SigCacheMaxSize   uint   `long:"sigcachemaxsize" description:"The maximum number of entries in the signature verification cache"`
+	UtxoCacheMaxSize  uint   `long:"utxocachemaxsize" description:"The maximum size in MiB of the utxo cache"`
+	UtxoCacheMemLimit uint   `long:"utxocachememlimit" description:"Flush the utxo cache when the memory in use by the process reaches the specified number of MiB -- 0 disables"`
 
 	// RPC server options and policy.
 	DisableRPC           bool     `long:"norpc" description:"Disable built-in RPC server -- NOTE: The RPC server is disabled by default if no rpcuser/rpcpass or rpclimituser/rpclimitpass is specified"`
@@ -168,6 +184,8 @@ type config struct {
 	OnionProxyPass string `long:"onionpass" default-mask:"-" description:"Password for onion proxy server"`
 	NoOnion        bool   `long:"noonion" description:"Disable connecting to tor hidden services"`
 	TorIsolation   bool   `long:"torisolation" description:"Enable Tor stream isolation by randomizing user credentials for each connection"`
+	TorControl     string `long:"torcontrol" description:"Tor control port used to automatically create a hidden service for the P2P listener (eg. 127.0.0.1:9051)"`
+	TorControlPass string `long:"torcontrolpass" default-mask:"-" description:"Password for the Tor control port"`
 
 	// P2P network options.
 	AddPeers        []string      `short:"a" long:"addpeer" description:"Add a peer to connect with at startup"`
@@ -178,6 +196,9 @@ type config struct {
 	MaxPeers        int           `long:"maxpeers" description:"Max number of inbound and outbound peers"`
 	DialTimeout     time.Duration `long:"dialtimeout" description:"How long to wait for TCP connection completion.  Valid time units are {s, m, h}.  Minimum 1 second"`
 	PeerIdleTimeout time.Duration `long:"peeridletimeout" description:"The duration of inactivity before a peer is timed out. Valid time units are {s,m,h}. Minimum 15 seconds"`
+	NoV2Transport   bool          `long:"nov2transport" description:"Disable the encrypted and authenticated v2 P2P transport"`
+	MaxUploadTarget uint64        `long:"maxuploadtarget" description:"Try to keep the data sent to peers under the target in MiB per 24 hours by no longer serving historical blocks to peers that are not whitelisted once it is reached -- 0 to disable"`
+	ASMap           string        `long:"asmap" description:"Path to a file that maps IP addresses to the autonomous system that announces them in order to diversify peers by AS rather than network prefix"`
 
 	// P2P network discovery options.
 	DisableSeeders bool     `long:"noseeders" description:"Disable seeding for peer discovery"`
@@ -219,12 +240,26 @@ type config struct {
 	AllowUnsyncedMining bool     `long:"allowunsyncedmining" description:"Allow block templates to be generated even when the chain is not considered synced on networks other than the main network.  This is automatically enabled when the simnet option is set.  Don't do this unless you know what you're doing"`
 
 	// Indexing options.
-	TxIndex             bool `long:"txindex" description:"Maintain a full hash-based transaction index which makes all transactions available via the getrawtransaction RPC"`
-	DropTxIndex         bool `long:"droptxindex" description:"Deletes the hash-based transaction index from the database on start up and then exits"`
-	AddrIndex           bool `long:"addrindex" description:"Maintain a full address-based transaction index which makes the searchrawtransactions RPC available"`
-	DropAddrIndex       bool `long:"dropaddrindex" description:"Deletes the address-based transaction index from the database on start up and then exits"`
-	NoExistsAddrIndex   bool `long:"noexistsaddrindex" description:"Disable the exists address index, which tracks whether or not an address has even been used"`
-	DropExistsAddrIndex bool `long:"dropexistsaddrindex" description:"Deletes the exists address index from the database on start up and then exits"`
+	TxIndex              bool `long:"txindex" description:"Maintain a full hash-based transaction index which makes all transactions available via the getrawtransaction RPC"`
+	DropTxIndex          bool `long:"droptxindex" description:"Deletes the hash-based transaction index from the database on start up and then exits"`
+	AddrIndex            bool `long:"addrindex" description:"Maintain a full address-based transaction index which makes the searchrawtransactions RPC available"`
+	DropAddrIndex        bool `long:"dropaddrindex" description:"Deletes the address-based transaction index from the database on start up and then exits"`
+	NoExistsAddrIndex    bool `long:"noexistsaddrindex" description:"Disable the exists address index, which tracks whether or not an address has even been used"`
+	DropExistsAddrIndex  bool `long:"dropexistsaddrindex" description:"Deletes the exists address index from the database on start up and then exits"`
+	SpenderIndex         bool `long:"spenderindex" description:"Maintain a full spent output index which makes the getspendingtx RPC available"`
+	DropSpenderIndex     bool `long:"dropspenderindex" description:"Deletes the spent output index from the database on start up and then exits"`
+	AddrBalanceIndex     bool `long:"addrbalanceindex" description:"Maintain a full address balance and unspent output index which makes the getaddressbalance, getaddressutxos, and getaddressdeltas RPCs available"`
+	DropAddrBalanceIndex bool `long:"dropaddrbalanceindex" description:"Deletes the address balance index from the database on start up and then exits"`
+	BlockStatsIndex      bool `long:"blockstatsindex" description:"Maintain a full block statistics index which makes the getblockstats RPC available"`
+	DropBlockStatsIndex  bool `long:"dropblockstatsindex" description:"Deletes the block statistics index from the database on start up and then exits"`
+	TicketIndex          bool `long:"ticketindex" description:"Maintain a full ticket lifecycle index which makes the getticketinfo and getaddresstickets RPCs available"`
+	DropTicketIndex      bool `long:"dropticketindex" description:"Deletes the ticket index from the database on start up and then exits"`
+	CoinStatsIndex       bool `long:"coinstatsindex" description:"Maintain a full coin statistics index which makes the gettxoutsetinfo RPC respond instantly and for any block height"`
+	DropCoinStatsIndex   bool `long:"dropcoinstatsindex" description:"Deletes the coin statistics index from the database on start up and then exits"`
+	CFHeaderIndex        bool `long:"cfheaderindex" description:"Maintain a full filter header index which makes the getcfilterheaders RPC available"`
+	DropCFHeaderIndex    bool `long:"dropcfheaderindex" description:"Deletes the filter header index from the database on start up and then exits"`
+	ScriptIndex          bool `long:"scriptindex" description:"Maintain a full index of the outputs with scripts that do not decode to addresses, including null data outputs, which makes the searchscripts RPC available"`
+	DropScriptIndex      bool `long:"dropscriptindex" description:"Deletes the script index from the database on start up and then exits"`
 
 	// IPC options.
 	PipeRx         uint `long:"piperx" description:"File descriptor of read end pipe to enable parent -> child process communication"`
@@ -305,6 +340,16 @@ func cleanAndExpandPath(path string) string {
 	return filepath.Join(homeDir, path)
 }
 
+// networkSubDir returns the network specific subdirectory of the passed
+// directory after expanding it, or the passed default directory, which is
+// expected to already be network specific, when no directory is given.
+func networkSubDir(dir, defaultDir, netName string) string {
+	if dir == "" {
+		return defaultDir
+	}
+	return filepath.Join(cleanAndExpandPath(dir), netName)
+}
+
 // validLogLevel returns whether or not logLevel is a valid debug log level.
 func validLogLevel(logLevel string) bool {
 	_, ok := slog.LevelFromString(logLevel)
@@ -657,6 +702,13 @@ func loadConfig(appName string) (*config, []string, error) {
 		TxIndex:           defaultTxIndex,
 		AddrIndex:         defaultAddrIndex,
 		NoExistsAddrIndex: defaultNoExistsAddrIndex,
+		SpenderIndex:      defaultSpenderIndex,
+		AddrBalanceIndex:  defaultAddrBalanceIndex,
+		BlockStatsIndex:   defaultBlockStatsIndex,
+		TicketIndex:       defaultTicketIndex,
+		CoinStatsIndex:    defaultCoinStatsIndex,
+		CFHeaderIndex:     defaultCFHeaderIndex,
+		ScriptIndex:       defaultScriptIndex,
 
 		// Cooked options ready for use.
 		ipv4NetInfo:  types.NetworksResult{Name: "IPV4"},
@@ -863,6 +915,15 @@ func loadConfig(appName string) (*config, []string, error) {
 	cfg.DataDir = cleanAndExpandPath(cfg.DataDir)
 	var oldTestNets []string
 	cfg.DataDir = filepath.Join(cfg.DataDir, cfg.params.Name)
+
+	// The block, UTXO, and index databases may be stored in directories other
+	// than the data directory, such as on different storage devices, so
+	// namespace them per network in the same fashion when they are specified.
+	// The block and UTXO databases otherwise reside in the data directory,
+	// while the indexes are stored in the block database.
+	cfg.BlocksDir = networkSubDir(cfg.BlocksDir, cfg.DataDir, cfg.params.Name)
+	cfg.UtxoDir = networkSubDir(cfg.UtxoDir, cfg.DataDir, cfg.params.Name)
+	cfg.IndexDir = networkSubDir(cfg.IndexDir, "", cfg.params.Name)
 	logRotator = nil
 	if !cfg.NoFileLogging {
 		// Append the network type to the log directory so it is "namespaced"
@@ -926,6 +987,15 @@ func loadConfig(appName string) (*config, []string, error) {
 		return nil, nil, err
 	}
 
+	// The memory database does not have a file path associated with it, so
+	// a separate index database directory does not make sense with it.
+	if cfg.IndexDir != "" && cfg.DbType == "memdb" {
+		str := "%s: the indexdir option can't be used with the memdb " +
+			"database type"
+		err := fmt.Errorf(str, funcName)
+		return nil, nil, err
+	}
+
 	// Enforce the minimum and maximum utxo cache max size.
 	if cfg.UtxoCacheMaxSize < minUtxoCacheMaxSize {
 		cfg.UtxoCacheMaxSize = minUtxoCacheMaxSize
@@ -970,6 +1040,11 @@ func loadConfig(appName string) (*config, []string, error) {
 		return nil, nil, err
 	}
 
+	// Expand the path to the AS map when one is specified.
+	if cfg.ASMap != "" {
+		cfg.ASMap = cleanAndExpandPath(cfg.ASMap)
+	}
+
 	// Don't allow peeridletimeout durations that are too short.
 	if cfg.PeerIdleTimeout < time.Second*15 {
 		str := "%s: the peeridletimeout option may not be less " +
@@ -1158,6 +1233,58 @@ func loadConfig(appName string) (*config, []string, error) {
 		return nil, nil, err
 	}
 
+	// --spenderindex and --dropspenderindex do not mix.
+	if cfg.SpenderIndex && cfg.DropSpenderIndex {
+		err := fmt.Errorf("%s: the --spenderindex and --dropspenderindex "+
+			"options may not be activated at the same time", funcName)
+		return nil, nil, err
+	}
+
+	// --addrbalanceindex and --dropaddrbalanceindex do not mix.
+	if cfg.AddrBalanceIndex && cfg.DropAddrBalanceIndex {
+		err := fmt.Errorf("%s: the --addrbalanceindex and "+
+			"--dropaddrbalanceindex options may not be activated at the same "+
+			"time", funcName)
+		return nil, nil, err
+	}
+
+	// --blockstatsindex and --dropblockstatsindex do not mix.
+	if cfg.BlockStatsIndex && cfg.DropBlockStatsIndex {
+		err := fmt.Errorf("%s: the --blockstatsindex and "+
+			"--dropblockstatsindex options may not be activated at the same "+
+			"time", funcName)
+		return nil, nil, err
+	}
+
+	// --ticketindex and --dropticketindex do not mix.
+	if cfg.TicketIndex && cfg.DropTicketIndex {
+		err := fmt.Errorf("%s: the --ticketindex and --dropticketindex "+
+			"options may not be activated at the same time", funcName)
+		return nil, nil, err
+	}
+
+	// --coinstatsindex and --dropcoinstatsindex do not mix.
+	if cfg.CoinStatsIndex && cfg.DropCoinStatsIndex {
+		err := fmt.Errorf("%s: the --coinstatsindex and "+
+			"--dropcoinstatsindex options may not be activated at the same "+
+			"time", funcName)
+		return nil, nil, err
+	}
+
+	// --cfheaderindex and --dropcfheaderindex do not mix.
+	if cfg.CFHeaderIndex && cfg.DropCFHeaderIndex {
+		err := fmt.Errorf("%s: the --cfheaderindex and --dropcfheaderindex "+
+			"options may not be activated at the same time", funcName)
+		return nil, nil, err
+	}
+
+	// --scriptindex and --dropscriptindex do not mix.
+	if cfg.ScriptIndex && cfg.DropScriptIndex {
+		err := fmt.Errorf("%s: the --scriptindex and --dropscriptindex "+
+			"options may not be activated at the same time", funcName)
+		return nil, nil, err
+	}
+
 	// Check mining addresses are valid and saved parsed versions.
 	cfg.miningAddrs = make([]stdaddr.Address, 0, len(cfg.MiningAddrs))
 	for _, strAddr := range cfg.MiningAddrs {
@@ -1349,6 +1476,19 @@ func loadConfig(appName string) (*config, []string, error) {
 		}
 	}
 
+	// Add the default port to the Tor control port address if needed.  A
+	// hidden service is of no use when connecting to Tor hidden services is
+	// disabled since other peers advertised via it could not be reached.
+	if cfg.TorControl != "" {
+		if cfg.NoOnion {
+			str := "%s: torcontrol and noonion cannot be used together"
+			err := fmt.Errorf(str, funcName)
+			return nil, nil, err
+		}
+		cfg.TorControl = normalizeAddresses([]string{cfg.TorControl},
+			defaultTorControlPort, normalizeInterfaceFirstAddr)[0]
+	}
+
 	// Warn if old testnet directory is present.
 	for _, oldDir := range oldTestNets {
 		if fileExists(oldDir) {
@@ -53,6 +53,45 @@ const (
 	ConnCanceled
 )
 
+// ConnType describes the purpose of an outbound connection.
+type ConnType uint8
+
+// ConnType can be either manual, full relay, or block relay.  Connections
+// requested by callers via Connect are manual unless specified otherwise while
+// the connections the connection manager makes automatically are either full
+// relay or block relay.
+const (
+	// ConnTypeManual is a connection that was explicitly requested by the
+	// caller.
+	ConnTypeManual ConnType = iota
+
+	// ConnTypeFullRelay is an automatic connection that is used to relay
+	// all data such as blocks, transactions, and addresses.
+	ConnTypeFullRelay
+
+	// ConnTypeBlockRelay is an automatic connection that is only used to
+	// relay blocks.  These connections help protect against partitioning
+	// attacks since they do not reveal the network topology via the
+	// transactions and addresses that are relayed over them.
+	ConnTypeBlockRelay
+)
+
+// connTypeStrings is a map of connection types back to their constant names
+// for pretty printing.
+var connTypeStrings = map[ConnType]string{
+	ConnTypeManual:     "manual",
+	ConnTypeFullRelay:  "outbound-full-relay",
+	ConnTypeBlockRelay: "block-relay-only",
+}
+
+// String returns the ConnType in human-readable form.
+func (t ConnType) String() string {
+	if s, ok := connTypeStrings[t]; ok {
+		return s
+	}
+	return fmt.Sprintf("Unknown ConnType (%d)", uint8(t))
+}
+
 // ConnReq is the connection request to a network address. If permanent, the
 // connection will be retried on disconnection.
 type ConnReq struct {
@@ -83,6 +122,11 @@ type ConnReq struct {
 	// manager will try to always maintain the connection including retries with
 	// increasing backoff timeouts.
 	Permanent bool
+
+	// Type is the type of the connection.  It is set by the connection
+	// manager for the connections it makes automatically and defaults to
+	// ConnTypeManual otherwise.
+	Type ConnType
 }
 
 // updateState updates the state of the connection request.
@@ -133,10 +177,28 @@ type Config struct {
 	// connections in that case.
 	OnAccept func(net.Conn)
 
+	// IsBanned is an optional callback that is invoked with the remote
+	// address of every accepted inbound connection prior to the OnAccept
+	// handler.  Connections for which it returns true are closed immediately
+	// without invoking the OnAccept handler.
+	IsBanned func(net.Addr) bool
+
 	// TargetOutbound is the number of outbound network connections to
 	// maintain. Defaults to 8.
 	TargetOutbound uint32
 
+	// TargetBlockRelay is the number of the automatic outbound network
+	// connections that are reserved for block relay only connections.  It
+	// must not exceed TargetOutbound.  Defaults to 0.
+	TargetBlockRelay uint32
+
+	// Anchors is an optional list of addresses to connect to for the block
+	// relay only connections before addresses from GetNewAddress are used.
+	// Typically, they are the addresses of the block relay only peers that
+	// were connected prior to the last shutdown.  Any addresses beyond
+	// TargetBlockRelay are ignored.
+	Anchors []net.Addr
+
 	// RetryDuration is the duration to wait before retrying connection
 	// requests. Defaults to 5s.
 	RetryDuration time.Duration
@@ -273,12 +335,12 @@ func (cm *ConnManager) handleFailedConn(ctx context.Context, c *ConnReq) {
 			go func() {
 				select {
 				case <-time.After(cm.cfg.RetryDuration):
-					cm.newConnReq(ctx)
+					cm.newConnReq(ctx, c.Type, nil)
 				case <-cm.quit:
 				}
 			}()
 		} else {
-			go cm.newConnReq(ctx)
+			go cm.newConnReq(ctx, c.Type, nil)
 		}
 	}
 }
@@ -457,15 +519,16 @@ out:
 	log.Trace("Connection handler done")
 }
 
-// newConnReq creates a new connection request and connects to the
-// corresponding address.
-func (cm *ConnManager) newConnReq(ctx context.Context) {
+// newConnReq creates a new connection request of the provided type and connects
+// to the provided address or, when it is nil, to an address obtained from
+// GetNewAddress.
+func (cm *ConnManager) newConnReq(ctx context.Context, connType ConnType, addr net.Addr) {
 	// Ignore during shutdown.
 	if ctx.Err() != nil {
 		return
 	}
 
-	c := &ConnReq{}
+	c := &ConnReq{Type: connType}
 	atomic.StoreUint64(&c.id, atomic.AddUint64(&cm.connReqCount, 1))
 
 	// Submit a request of a pending connection attempt to the connection
@@ -487,13 +550,16 @@ func (cm *ConnManager) newConnReq(ctx context.Context) {
 		return
 	}
 
-	addr, err := cm.cfg.GetNewAddress()
-	if err != nil {
-		select {
-		case cm.requests <- handleFailed{c, err}:
-		case <-cm.quit:
+	if addr == nil {
+		var err error
+		addr, err = cm.cfg.GetNewAddress()
+		if err != nil {
+			select {
+			case cm.requests <- handleFailed{c, err}:
+			case <-cm.quit:
+			}
+			return
 		}
-		return
 	}
 
 	c.Addr = addr
@@ -662,6 +728,12 @@ func (cm *ConnManager) listenHandler(ctx context.Context, listener net.Listener)
 			}
 			continue
 		}
+		if cm.cfg.IsBanned != nil && cm.cfg.IsBanned(conn.RemoteAddr()) {
+			log.Debugf("Rejecting inbound connection from banned address %s",
+				conn.RemoteAddr())
+			conn.Close()
+			continue
+		}
 		go cm.cfg.OnAccept(conn)
 	}
 
@@ -691,11 +763,24 @@ func (cm *ConnManager) Run(ctx context.Context) {
 	}
 
 	// Start enough outbound connections to reach the target number when not
-	// in manual connect mode.
+	// in manual connect mode.  The first of them are block relay only
+	// connections which prefer the anchor addresses.  The connection type of
+	// each of them is retained when they are replaced.
 	if cm.cfg.GetNewAddress != nil {
+		anchors := cm.cfg.Anchors
+		var numBlockRelay uint32
 		curConnReqCount := atomic.LoadUint64(&cm.connReqCount)
 		for i := curConnReqCount; i < uint64(cm.cfg.TargetOutbound); i++ {
-			go cm.newConnReq(ctx)
+			if numBlockRelay < cm.cfg.TargetBlockRelay {
+				numBlockRelay++
+				var addr net.Addr
+				if len(anchors) > 0 {
+					addr, anchors = anchors[0], anchors[1:]
+				}
+				go cm.newConnReq(ctx, ConnTypeBlockRelay, addr)
+				continue
+			}
+			go cm.newConnReq(ctx, ConnTypeFullRelay, nil)
 		}
 	}
 
@@ -738,6 +823,9 @@ func New(cfg *Config) (*ConnManager, error) {
 	if cfg.TargetOutbound == 0 {
 		cfg.TargetOutbound = defaultTargetOutbound
 	}
+	if cfg.TargetBlockRelay > cfg.TargetOutbound {
+		cfg.TargetBlockRelay = cfg.TargetOutbound
+	}
 	cm := ConnManager{
 		cfg:      *cfg, // Copy so caller can't mutate
 		requests: make(chan interface{}),
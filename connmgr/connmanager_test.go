@@ -11,6 +11,7 @@ import (
 	"fmt"
 	"io"
 	"net"
+	"reflect"
 	"sync"
 	"sync/atomic"
 	"testing"
@@ -237,6 +238,85 @@ func TestTargetOutbound(t *testing.T) {
 	wg.Wait()
 }
 
+// TestBlockRelayConns ensures the expected number of the automatic outbound
+// connections are block relay only connections, that the anchor addresses are
+// used for them, and that they are replaced with connections of the same type.
+func TestBlockRelayConns(t *testing.T) {
+	const targetOutbound, targetBlockRelay = 4, 2
+	anchor := &net.TCPAddr{IP: net.ParseIP("10.0.0.1"), Port: 18555}
+	newAddr := &net.TCPAddr{IP: net.ParseIP("127.0.0.1"), Port: 18555}
+	connected := make(chan *ConnReq)
+	cmgr, err := New(&Config{
+		TargetOutbound:   targetOutbound,
+		TargetBlockRelay: targetBlockRelay,
+		Anchors:          []net.Addr{anchor},
+		Dial:             mockDialer,
+		GetNewAddress: func() (net.Addr, error) {
+			return newAddr, nil
+		},
+		OnConnection: func(c *ConnReq, conn net.Conn) {
+			connected <- c
+		},
+	})
+	if err != nil {
+		t.Fatalf("New error: %v", err)
+	}
+	_, shutdown, wg := runConnMgrAsync(context.Background(), cmgr)
+
+	// Wait for the target outbound conns to be established and ensure the
+	// expected number of each type are made with the anchor address only
+	// being used for a block relay only connection.
+	counts := make(map[ConnType]int)
+	var numAnchors int
+	var blockRelayConn *ConnReq
+	for i := 0; i < targetOutbound; i++ {
+		c := <-connected
+		counts[c.Type]++
+		if c.Addr.String() == anchor.String() {
+			if c.Type != ConnTypeBlockRelay {
+				t.Fatalf("anchor connection type: got %v, want %v", c.Type,
+					ConnTypeBlockRelay)
+			}
+			numAnchors++
+		}
+		if c.Type == ConnTypeBlockRelay {
+			blockRelayConn = c
+		}
+	}
+	wantCounts := map[ConnType]int{
+		ConnTypeBlockRelay: targetBlockRelay,
+		ConnTypeFullRelay:  targetOutbound - targetBlockRelay,
+	}
+	if !reflect.DeepEqual(counts, wantCounts) {
+		t.Fatalf("mismatched connection types: got %v, want %v", counts,
+			wantCounts)
+	}
+	if numAnchors != 1 {
+		t.Fatalf("mismatched anchor connections: got %d, want 1", numAnchors)
+	}
+
+	// Ensure a disconnected block relay only connection is replaced with a new
+	// block relay only connection.
+	cmgr.Disconnect(blockRelayConn.ID())
+	select {
+	case c := <-connected:
+		if c.Type != ConnTypeBlockRelay {
+			t.Fatalf("replacement connection type: got %v, want %v", c.Type,
+				ConnTypeBlockRelay)
+		}
+		if c.Addr.String() != newAddr.String() {
+			t.Fatalf("replacement connection address: got %v, want %v",
+				c.Addr, newAddr)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timeout waiting for replacement connection")
+	}
+
+	// Ensure clean shutdown of connection manager.
+	shutdown()
+	wg.Wait()
+}
+
 // TestPassAddrAlongDialAddr tests if when using the DialAddr config option,
 // any address object returned by GetNewAddress will be correctly passed along
 // to DialAddr to be used for connecting to a host.
@@ -1023,3 +1103,46 @@ out:
 	shutdown()
 	wg.Wait()
 }
+
+// TestListenersBanned ensures inbound connections from banned addresses are
+// closed without invoking the accept callback.
+func TestListenersBanned(t *testing.T) {
+	receivedConns := make(chan net.Conn)
+	listener := newMockListener("127.0.0.1:8333")
+	cmgr, err := New(&Config{
+		Listeners: []net.Listener{listener},
+		OnAccept: func(conn net.Conn) {
+			receivedConns <- conn
+		},
+		IsBanned: func(addr net.Addr) bool {
+			return addr.String() == "10.0.0.1:10000"
+		},
+		Dial: mockDialer,
+	})
+	if err != nil {
+		t.Fatalf("New error: %v", err)
+	}
+	_, shutdown, wg := runConnMgrAsync(context.Background(), cmgr)
+
+	// Fake a connection from a banned address followed by one from an address
+	// that is not banned.  Since connections are accepted in order, only the
+	// latter is expected to be received.
+	go func() {
+		listener.Connect("10.0.0.1", 10000)
+		listener.Connect("10.0.0.2", 10000)
+	}()
+
+	select {
+	case conn := <-receivedConns:
+		if got := conn.RemoteAddr().String(); got != "10.0.0.2:10000" {
+			t.Fatalf("received unexpected connection from %v", got)
+		}
+
+	case <-time.After(time.Millisecond * 50):
+		t.Fatal("Timeout waiting for expected connection")
+	}
+
+	// Ensure clean shutdown of connection manager.
+	shutdown()
+	wg.Wait()
+}
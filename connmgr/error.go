@@ -53,6 +53,22 @@ const (
 
 	// ErrTorAddrNotSupported indicates the tor address type is not supported.
 	ErrTorAddrNotSupported = ErrorKind("ErrTorAddrNotSupported")
+
+	// ErrTorControlUnsupportedAuth indicates none of the authentication
+	// methods supported by the tor control port can be used.
+	ErrTorControlUnsupportedAuth = ErrorKind("ErrTorControlUnsupportedAuth")
+
+	// ErrTorControlCommandFailed indicates the tor control port replied to a
+	// command with an error.
+	ErrTorControlCommandFailed = ErrorKind("ErrTorControlCommandFailed")
+
+	// ErrTorControlInvalidReply indicates the tor control port replied to a
+	// command in an unexpected format.
+	ErrTorControlInvalidReply = ErrorKind("ErrTorControlInvalidReply")
+
+	// ErrTorControlInvalidKey indicates a hidden service private key is not
+	// of the expected type.
+	ErrTorControlInvalidKey = ErrorKind("ErrTorControlInvalidKey")
 )
 
 // Error satisfies the error interface and prints human-readable errors.
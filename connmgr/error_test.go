@@ -29,6 +29,10 @@ func TestErrorKindStringer(t *testing.T) {
 		{ErrTorTTLExpired, "ErrTorTTLExpired"},
 		{ErrTorCmdNotSupported, "ErrTorCmdNotSupported"},
 		{ErrTorAddrNotSupported, "ErrTorAddrNotSupported"},
+		{ErrTorControlUnsupportedAuth, "ErrTorControlUnsupportedAuth"},
+		{ErrTorControlCommandFailed, "ErrTorControlCommandFailed"},
+		{ErrTorControlInvalidReply, "ErrTorControlInvalidReply"},
+		{ErrTorControlInvalidKey, "ErrTorControlInvalidKey"},
 	}
 
 	for i, test := range tests {
@@ -0,0 +1,298 @@
+// Copyright (c) 2023 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package connmgr
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// torControlOK is the status code Tor replies with to control port
+	// commands that succeed.
+	torControlOK = 250
+
+	// torAuthNull, torAuthHashedPassword, and torAuthCookie are the
+	// authentication methods Tor advertises via the PROTOCOLINFO command
+	// that are supported.
+	torAuthNull           = "NULL"
+	torAuthHashedPassword = "HASHEDPASSWORD"
+	torAuthCookie         = "COOKIE"
+
+	// torControlDefaultTimeout is the timeout applied to control port
+	// commands that are issued with a context that does not have a deadline.
+	torControlDefaultTimeout = time.Minute
+
+	// TorV3KeyType is the key type of Tor v3 hidden services.  The private
+	// keys of Tor v3 hidden services created via the control port are
+	// prefixed with it.
+	TorV3KeyType = "ED25519-V3"
+)
+
+// OnionService describes a hidden service that was created via the Tor
+// control port.
+type OnionService struct {
+	// ServiceID is the address of the hidden service without the .onion
+	// suffix.
+	ServiceID string
+
+	// PrivateKey is the private key of the hidden service in the form
+	// "ED25519-V3:<base64 key>".  It may be provided when creating the
+	// hidden service again in order to keep the same address.
+	PrivateKey string
+}
+
+// TorControl provides a connection to the control port of a Tor instance.  It
+// is used to create hidden services that remain active until the connection
+// is closed.
+type TorControl struct {
+	netConn net.Conn
+	conn    *textproto.Conn
+}
+
+// parseTorReplyArgs parses the space separated arguments of a Tor control port
+// reply line into a map of keys to values.  Values may optionally be quoted in
+// which case they may contain spaces and escape sequences.  Arguments that are
+// not in the form key=value are mapped to an empty value.
+func parseTorReplyArgs(line string) (map[string]string, error) {
+	args := make(map[string]string)
+	for line = strings.TrimSpace(line); line != ""; line = strings.TrimSpace(line) {
+		eq := strings.IndexByte(line, '=')
+		sp := strings.IndexByte(line, ' ')
+		if eq == -1 || (sp != -1 && sp < eq) {
+			if sp == -1 {
+				sp = len(line)
+			}
+			args[line[:sp]] = ""
+			line = line[sp:]
+			continue
+		}
+
+		key, rest := line[:eq], line[eq+1:]
+		if !strings.HasPrefix(rest, `"`) {
+			end := strings.IndexByte(rest, ' ')
+			if end == -1 {
+				end = len(rest)
+			}
+			args[key] = rest[:end]
+			line = rest[end:]
+			continue
+		}
+
+		// Find the closing quote while skipping escaped characters.
+		end := -1
+		for i := 1; i < len(rest); i++ {
+			if rest[i] == '\\' {
+				i++
+				continue
+			}
+			if rest[i] == '"' {
+				end = i
+				break
+			}
+		}
+		if end == -1 {
+			str := fmt.Sprintf("unterminated quoted value for %q", key)
+			return nil, MakeError(ErrTorControlInvalidReply, str)
+		}
+		value, err := strconv.Unquote(rest[:end+1])
+		if err != nil {
+			str := fmt.Sprintf("invalid quoted value for %q: %v", key, err)
+			return nil, MakeError(ErrTorControlInvalidReply, str)
+		}
+		args[key] = value
+		line = rest[end+1:]
+	}
+	return args, nil
+}
+
+// quoteTorString returns the provided string as a quoted string suitable for
+// use as an argument to a Tor control port command.
+func quoteTorString(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+	return `"` + r.Replace(s) + `"`
+}
+
+// command sends the provided command to the control port and returns the
+// lines of the reply without the trailing OK.  The command is subject to the
+// deadline of the provided context or a default timeout when it does not have
+// one.
+func (c *TorControl) command(ctx context.Context, cmd string) ([]string, error) {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(torControlDefaultTimeout)
+	}
+	if err := c.netConn.SetDeadline(deadline); err != nil {
+		return nil, err
+	}
+	defer c.netConn.SetDeadline(time.Time{})
+
+	id, err := c.conn.Cmd("%s", cmd)
+	if err != nil {
+		return nil, err
+	}
+	c.conn.StartResponse(id)
+	defer c.conn.EndResponse(id)
+
+	// Only report the command name in errors since the arguments may contain
+	// secrets.
+	cmdName := cmd
+	if i := strings.IndexByte(cmd, ' '); i != -1 {
+		cmdName = cmd[:i]
+	}
+	_, msg, err := c.conn.ReadResponse(torControlOK)
+	if err != nil {
+		var protoErr *textproto.Error
+		if errors.As(err, &protoErr) {
+			str := fmt.Sprintf("tor control command %s failed: %d %s",
+				cmdName, protoErr.Code, protoErr.Msg)
+			return nil, MakeError(ErrTorControlCommandFailed, str)
+		}
+		return nil, err
+	}
+	lines := strings.Split(msg, "\n")
+	if len(lines) > 0 && lines[len(lines)-1] == "OK" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines, nil
+}
+
+// authenticate authenticates with the control port using the provided
+// password, when specified, or otherwise the best method supported by the Tor
+// instance as reported by the PROTOCOLINFO command.
+func (c *TorControl) authenticate(ctx context.Context, password string) error {
+	lines, err := c.command(ctx, "PROTOCOLINFO 1")
+	if err != nil {
+		return err
+	}
+	methods := make(map[string]struct{})
+	var cookieFile string
+	for _, line := range lines {
+		if !strings.HasPrefix(line, "AUTH ") {
+			continue
+		}
+		args, err := parseTorReplyArgs(line[len("AUTH "):])
+		if err != nil {
+			return err
+		}
+		for _, method := range strings.Split(args["METHODS"], ",") {
+			methods[method] = struct{}{}
+		}
+		cookieFile = args["COOKIEFILE"]
+	}
+
+	var auth string
+	_, nullAuth := methods[torAuthNull]
+	_, passwordAuth := methods[torAuthHashedPassword]
+	_, cookieAuth := methods[torAuthCookie]
+	switch {
+	case password != "" && passwordAuth:
+		auth = "AUTHENTICATE " + quoteTorString(password)
+
+	case nullAuth:
+		auth = "AUTHENTICATE"
+
+	case cookieAuth && cookieFile != "":
+		cookie, err := ioutil.ReadFile(cookieFile)
+		if err != nil {
+			return fmt.Errorf("unable to read tor authentication cookie: %w",
+				err)
+		}
+		auth = "AUTHENTICATE " + hex.EncodeToString(cookie)
+
+	default:
+		supported := make([]string, 0, len(methods))
+		for method := range methods {
+			supported = append(supported, method)
+		}
+		str := fmt.Sprintf("no supported tor control port authentication "+
+			"method is available (password specified: %v, tor methods: %s)",
+			password != "", strings.Join(supported, ","))
+		return MakeError(ErrTorControlUnsupportedAuth, str)
+	}
+
+	_, err = c.command(ctx, auth)
+	return err
+}
+
+// DialTorControl connects to the Tor control port at the provided address and
+// authenticates with it.  The provided password is used for authentication
+// when it is specified and the Tor instance supports password authentication.
+// Otherwise, either no authentication or cookie authentication is used
+// depending on what the Tor instance supports.
+//
+// The returned connection must be closed when it is no longer needed which
+// also removes any hidden services that were created via it.
+func DialTorControl(ctx context.Context, addr, password string) (*TorControl, error) {
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &TorControl{netConn: conn, conn: textproto.NewConn(conn)}
+	if err := c.authenticate(ctx, password); err != nil {
+		c.conn.Close()
+		return nil, err
+	}
+	return c, nil
+}
+
+// AddOnion creates a Tor v3 hidden service that forwards connections to the
+// provided virtual port of the service to the provided target address.
+//
+// A new key is generated when the provided private key is empty.  Otherwise,
+// it must be the private key of a previously created hidden service in the
+// form returned in OnionService.
+//
+// The hidden service remains active until the control port connection is
+// closed.
+func (c *TorControl) AddOnion(ctx context.Context, privateKey string, virtPort uint16, target string) (*OnionService, error) {
+	keySpec := "NEW:" + TorV3KeyType
+	if privateKey != "" {
+		if !strings.HasPrefix(privateKey, TorV3KeyType+":") {
+			str := fmt.Sprintf("hidden service private key is not of type %s",
+				TorV3KeyType)
+			return nil, MakeError(ErrTorControlInvalidKey, str)
+		}
+		keySpec = privateKey
+	}
+
+	cmd := fmt.Sprintf("ADD_ONION %s Port=%d,%s", keySpec, virtPort, target)
+	lines, err := c.command(ctx, cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	service := OnionService{PrivateKey: privateKey}
+	for _, line := range lines {
+		switch {
+		case strings.HasPrefix(line, "ServiceID="):
+			service.ServiceID = line[len("ServiceID="):]
+		case strings.HasPrefix(line, "PrivateKey="):
+			service.PrivateKey = line[len("PrivateKey="):]
+		}
+	}
+	if service.ServiceID == "" || service.PrivateKey == "" {
+		str := "tor control port reply to ADD_ONION is missing the " +
+			"service id or private key"
+		return nil, MakeError(ErrTorControlInvalidReply, str)
+	}
+	return &service, nil
+}
+
+// Close closes the connection to the control port which also removes any
+// hidden services that were created via it.
+func (c *TorControl) Close() error {
+	return c.conn.Close()
+}
@@ -0,0 +1,340 @@
+// Copyright (c) 2023 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package connmgr
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+const (
+	// mockOnionServiceID and mockOnionPrivateKey are the service id and
+	// private key returned by the mock Tor control port for new hidden
+	// services.
+	mockOnionServiceID  = "pg6mmjiyjmcrsslvykfwnntlaru7p5svn6y2ymmju6nubxndf4pscryd"
+	mockOnionPrivateKey = "ED25519-V3:mockprivatekey"
+)
+
+// mockTorControl implements a mock Tor control port that supports the subset
+// of commands needed to authenticate and create hidden services.
+type mockTorControl struct {
+	listener net.Listener
+
+	// methods is the list of authentication methods to advertise.
+	methods string
+
+	// cookieFile and cookie are the path to the authentication cookie and
+	// its expected contents when cookie authentication is advertised.
+	cookieFile string
+	cookie     []byte
+
+	// password is the expected password for password authentication.
+	password string
+
+	// commands houses the commands received from the client.
+	commands chan string
+}
+
+// newMockTorControl returns a mock Tor control port that listens on a random
+// local port and advertises the provided authentication methods.  It must be
+// closed when it is no longer needed.
+func newMockTorControl(t *testing.T, methods string) *mockTorControl {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("unable to listen: %v", err)
+	}
+	m := &mockTorControl{
+		listener: listener,
+		methods:  methods,
+		commands: make(chan string, 10),
+	}
+	go m.serve()
+	return m
+}
+
+// close stops the mock control port from listening.
+func (m *mockTorControl) close() {
+	m.listener.Close()
+}
+
+// addr returns the address the mock control port is listening on.
+func (m *mockTorControl) addr() string {
+	return m.listener.Addr().String()
+}
+
+// serve accepts a single connection and replies to its commands until it is
+// closed.
+func (m *mockTorControl) serve() {
+	netConn, err := m.listener.Accept()
+	if err != nil {
+		return
+	}
+	conn := textproto.NewConn(netConn)
+	defer conn.Close()
+
+	var authenticated bool
+	for {
+		line, err := conn.ReadLine()
+		if err != nil {
+			return
+		}
+		m.commands <- line
+
+		cmd, args := line, ""
+		if i := strings.IndexByte(line, ' '); i != -1 {
+			cmd, args = line[:i], line[i+1:]
+		}
+		switch {
+		case cmd == "PROTOCOLINFO":
+			auth := "250-AUTH METHODS=" + m.methods
+			if m.cookieFile != "" {
+				auth += fmt.Sprintf(" COOKIEFILE=%q", m.cookieFile)
+			}
+			conn.PrintfLine("250-PROTOCOLINFO 1")
+			conn.PrintfLine("%s", auth)
+			conn.PrintfLine(`250-VERSION Tor="0.4.8.9"`)
+			conn.PrintfLine("250 OK")
+
+		case cmd == "AUTHENTICATE":
+			switch {
+			case args == "" && strings.Contains(m.methods, "NULL"):
+				authenticated = true
+			case m.password != "" && args == fmt.Sprintf("%q", m.password):
+				authenticated = true
+			case m.cookie != nil && args == hex.EncodeToString(m.cookie):
+				authenticated = true
+			}
+			if !authenticated {
+				conn.PrintfLine("515 Authentication failed")
+				return
+			}
+			conn.PrintfLine("250 OK")
+
+		case !authenticated:
+			conn.PrintfLine("514 Authentication required.")
+			return
+
+		case cmd == "ADD_ONION":
+			fields := strings.Fields(args)
+			if len(fields) != 2 || !strings.HasPrefix(fields[1], "Port=") {
+				conn.PrintfLine("512 Invalid argument")
+				continue
+			}
+			conn.PrintfLine("250-ServiceID=%s", mockOnionServiceID)
+			if fields[0] == "NEW:ED25519-V3" {
+				conn.PrintfLine("250-PrivateKey=%s", mockOnionPrivateKey)
+			}
+			conn.PrintfLine("250 OK")
+
+		default:
+			conn.PrintfLine("510 Unrecognized command %q", cmd)
+		}
+	}
+}
+
+// TestTorControl ensures authenticating with the Tor control port and creating
+// hidden services works as expected with the supported authentication methods.
+func TestTorControl(t *testing.T) {
+	cookieDir, err := ioutil.TempDir("", "torcontrol")
+	if err != nil {
+		t.Fatalf("unable to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(cookieDir)
+	cookieFile := filepath.Join(cookieDir, "control_auth_cookie")
+	cookie := []byte("0123456789abcdef0123456789abcdef")
+	if err := ioutil.WriteFile(cookieFile, cookie, 0600); err != nil {
+		t.Fatalf("unable to write cookie: %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		methods    string
+		cookie     bool
+		password   string
+		dialPass   string
+		privateKey string
+		wantAuth   string
+		wantErr    error
+	}{{
+		name:     "no authentication",
+		methods:  "NULL",
+		wantAuth: "AUTHENTICATE",
+	}, {
+		name:     "password authentication",
+		methods:  "HASHEDPASSWORD",
+		password: `pass"word`,
+		dialPass: `pass"word`,
+		wantAuth: `AUTHENTICATE "pass\"word"`,
+	}, {
+		name:     "cookie authentication",
+		methods:  "COOKIE,SAFECOOKIE",
+		cookie:   true,
+		wantAuth: "AUTHENTICATE " + hex.EncodeToString(cookie),
+	}, {
+		name:     "cookie authentication preferred without password",
+		methods:  "COOKIE,SAFECOOKIE,HASHEDPASSWORD",
+		cookie:   true,
+		wantAuth: "AUTHENTICATE " + hex.EncodeToString(cookie),
+	}, {
+		name:       "existing key",
+		methods:    "NULL",
+		privateKey: "ED25519-V3:existingkey",
+		wantAuth:   "AUTHENTICATE",
+	}, {
+		name:     "wrong password",
+		methods:  "HASHEDPASSWORD",
+		password: "password",
+		dialPass: "wrong",
+		wantAuth: `AUTHENTICATE "wrong"`,
+		wantErr:  ErrTorControlCommandFailed,
+	}, {
+		name:    "password required but not specified",
+		methods: "HASHEDPASSWORD",
+		wantErr: ErrTorControlUnsupportedAuth,
+	}, {
+		name:    "only safe cookie authentication",
+		methods: "SAFECOOKIE",
+		wantErr: ErrTorControlUnsupportedAuth,
+	}}
+
+	for _, test := range tests {
+		mock := newMockTorControl(t, test.methods)
+		mock.password = test.password
+		if test.cookie {
+			mock.cookieFile = cookieFile
+			mock.cookie = cookie
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		c, err := DialTorControl(ctx, mock.addr(), test.dialPass)
+		cancel()
+		mock.close()
+		if <-mock.commands != "PROTOCOLINFO 1" {
+			t.Fatalf("%q: PROTOCOLINFO command was not sent", test.name)
+		}
+		if test.wantAuth != "" {
+			if got := <-mock.commands; got != test.wantAuth {
+				t.Fatalf("%q: mismatched authentication -- got %q, want %q",
+					test.name, got, test.wantAuth)
+			}
+		}
+		if !errors.Is(err, test.wantErr) {
+			t.Fatalf("%q: mismatched err -- got %v, want %v", test.name, err,
+				test.wantErr)
+		}
+		if err != nil {
+			continue
+		}
+
+		// Create the hidden service and ensure the expected command is sent
+		// and the service details are returned.
+		ctx, cancel = context.WithTimeout(context.Background(), time.Second)
+		service, err := c.AddOnion(ctx, test.privateKey, 9108,
+			"127.0.0.1:19108")
+		cancel()
+		if err != nil {
+			t.Fatalf("%q: unexpected AddOnion error: %v", test.name, err)
+		}
+		keySpec := test.privateKey
+		wantKey := test.privateKey
+		if keySpec == "" {
+			keySpec = "NEW:ED25519-V3"
+			wantKey = mockOnionPrivateKey
+		}
+		wantCmd := fmt.Sprintf("ADD_ONION %s Port=9108,127.0.0.1:19108",
+			keySpec)
+		if got := <-mock.commands; got != wantCmd {
+			t.Fatalf("%q: mismatched command -- got %q, want %q", test.name,
+				got, wantCmd)
+		}
+		want := &OnionService{
+			ServiceID:  mockOnionServiceID,
+			PrivateKey: wantKey,
+		}
+		if !reflect.DeepEqual(service, want) {
+			t.Fatalf("%q: mismatched service -- got %+v, want %+v",
+				test.name, service, want)
+		}
+		c.Close()
+	}
+}
+
+// TestTorControlInvalidKey ensures attempting to create a hidden service with
+// a private key that is not a Tor v3 key is rejected.
+func TestTorControlInvalidKey(t *testing.T) {
+	mock := newMockTorControl(t, "NULL")
+	defer mock.close()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	c, err := DialTorControl(ctx, mock.addr(), "")
+	if err != nil {
+		t.Fatalf("unexpected dial error: %v", err)
+	}
+	defer c.Close()
+
+	_, err = c.AddOnion(ctx, "RSA1024:key", 9108, "127.0.0.1:9108")
+	if !errors.Is(err, ErrTorControlInvalidKey) {
+		t.Fatalf("mismatched err -- got %v, want %v", err,
+			ErrTorControlInvalidKey)
+	}
+}
+
+// TestParseTorReplyArgs ensures the arguments of Tor control port reply lines
+// are parsed as expected.
+func TestParseTorReplyArgs(t *testing.T) {
+	tests := []struct {
+		name    string
+		line    string
+		want    map[string]string
+		wantErr error
+	}{{
+		name: "unquoted values",
+		line: "METHODS=COOKIE,SAFECOOKIE",
+		want: map[string]string{"METHODS": "COOKIE,SAFECOOKIE"},
+	}, {
+		name: "quoted value with spaces and escapes",
+		line: `METHODS=COOKIE COOKIEFILE="/var/lib/my tor/\"cookie\""`,
+		want: map[string]string{
+			"METHODS":    "COOKIE",
+			"COOKIEFILE": `/var/lib/my tor/"cookie"`,
+		},
+	}, {
+		name: "bare keyword",
+		line: "OK",
+		want: map[string]string{"OK": ""},
+	}, {
+		name:    "unterminated quoted value",
+		line:    `COOKIEFILE="/var/lib/tor`,
+		wantErr: ErrTorControlInvalidReply,
+	}}
+
+	for _, test := range tests {
+		got, err := parseTorReplyArgs(test.line)
+		if !errors.Is(err, test.wantErr) {
+			t.Fatalf("%q: mismatched err -- got %v, want %v", test.name, err,
+				test.wantErr)
+		}
+		if err != nil {
+			continue
+		}
+		if !reflect.DeepEqual(got, test.want) {
+			t.Fatalf("%q: mismatched args -- got %v, want %v", test.name, got,
+				test.want)
+		}
+	}
+}
@@ -0,0 +1,201 @@
+// Copyright (c) 2023 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/binary"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/EXCCoin/exccd/chaincfg/chainhash"
+	"github.com/EXCCoin/exccd/database/v3"
+	"github.com/EXCCoin/exccd/database/v3/ffldb"
+	"github.com/EXCCoin/exccd/dcrutil/v4"
+)
+
+// compressBlocksCmd defines the configuration options for the compressblocks
+// command.
+type compressBlocksCmd struct {
+	KeepOld bool `long:"keepold" description:"Keep the original uncompressed block database instead of removing it"`
+}
+
+var (
+	// compressBlocksCfg defines the configuration options for the command.
+	compressBlocksCfg = compressBlocksCmd{}
+)
+
+// blockToCompress houses the hash of a block along with its location in the
+// flat files of the source database so the blocks can be copied in the same
+// order they were originally written.
+type blockToCompress struct {
+	hash       chainhash.Hash
+	fileNum    uint32
+	fileOffset uint32
+}
+
+// copyBlocks copies all of the blocks from the source database to the
+// destination database in the order they were written to the source flat
+// files.
+func (c *dbCopier) copyBlocks() error {
+	// Load the hashes of all blocks along with their locations from the
+	// internal ffldb block index.
+	//
+	// NOTE: This code will only work for ffldb.  Ideally the package using
+	// the database would keep a metadata index of its own.
+	var blocks []blockToCompress
+	err := c.srcDB.View(func(tx database.Tx) error {
+		blockIdx := tx.Metadata().Bucket([]byte(ffldbBlockIdxName))
+		if blockIdx == nil {
+			return fmt.Errorf("block index bucket %q does not exist",
+				ffldbBlockIdxName)
+		}
+		return blockIdx.ForEach(func(k, v []byte) error {
+			// The serialized block location starts with the file
+			// number followed by the file offset.
+			var block blockToCompress
+			copy(block.hash[:], k)
+			block.fileNum = binary.LittleEndian.Uint32(v[0:4])
+			block.fileOffset = binary.LittleEndian.Uint32(v[4:8])
+			blocks = append(blocks, block)
+			return nil
+		})
+	})
+	if err != nil {
+		return err
+	}
+
+	sort.Slice(blocks, func(i, j int) bool {
+		if blocks[i].fileNum != blocks[j].fileNum {
+			return blocks[i].fileNum < blocks[j].fileNum
+		}
+		return blocks[i].fileOffset < blocks[j].fileOffset
+	})
+
+	log.Infof("Compressing %d blocks...", len(blocks))
+	startTime := time.Now()
+	for start := 0; start < len(blocks); start += copyBatchSize {
+		if c.interrupted() {
+			return errCopyInterrupted
+		}
+
+		end := start + copyBatchSize
+		if end > len(blocks) {
+			end = len(blocks)
+		}
+		batch := make([]*dcrutil.Block, 0, end-start)
+		err := c.srcDB.View(func(tx database.Tx) error {
+			for i := start; i < end; i++ {
+				blockBytes, err := tx.FetchBlock(&blocks[i].hash)
+				if err != nil {
+					return err
+				}
+
+				// The block bytes are only valid during the
+				// transaction, so copy them.
+				blockBytes = append([]byte(nil), blockBytes...)
+				block, err := dcrutil.NewBlockFromBytes(blockBytes)
+				if err != nil {
+					return err
+				}
+				batch = append(batch, block)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		err = c.dstDB.Update(func(tx database.Tx) error {
+			for _, block := range batch {
+				if err := tx.StoreBlock(block); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		log.Infof("Compressed %d of %d blocks", end, len(blocks))
+	}
+	log.Infof("Compressed %d blocks in %v", len(blocks),
+		time.Since(startTime))
+	return nil
+}
+
+// Execute is the main entry point for the command.  It's invoked by the parser.
+func (cmd *compressBlocksCmd) Execute(args []string) error {
+	// Setup the global config options and ensure they are valid.
+	if err := setupGlobalConfig(); err != nil {
+		return err
+	}
+	if cfg.DbType != "ffldb" {
+		return fmt.Errorf("block compression is not supported by the %q "+
+			"database type", cfg.DbType)
+	}
+
+	// Load the existing block database.
+	srcDB, err := loadBlockDB()
+	if err != nil {
+		return err
+	}
+	defer srcDB.Close()
+
+	// Create a new database that compresses its blocks next to the
+	// existing one.  Any leftover database from a previously interrupted
+	// run is removed first.
+	dbPath := blockDbPath()
+	newDbPath := dbPath + "_compressed"
+	if err := os.RemoveAll(newDbPath); err != nil {
+		return err
+	}
+	dstDB, err := database.Create(cfg.DbType, newDbPath,
+		activeNetParams.Net, ffldb.BlockCompressionDeflate)
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(newDbPath)
+	defer dstDB.Close()
+
+	// Stop the copy on Ctrl+C.  The original database is left untouched.
+	c := &dbCopier{srcDB: srcDB, dstDB: dstDB, quit: make(chan struct{})}
+	addInterruptHandler(func() {
+		log.Infof("Stopping block compression...")
+		close(c.quit)
+	})
+
+	// Copy the blocks followed by all of the metadata.
+	if err := c.copyBlocks(); err != nil {
+		return err
+	}
+	log.Infof("Copying metadata...")
+	if err := c.copyBucket(nil); err != nil {
+		return err
+	}
+
+	// Replace the existing database with the compressed one.
+	if err := dstDB.Close(); err != nil {
+		return err
+	}
+	if err := srcDB.Close(); err != nil {
+		return err
+	}
+	oldDbPath := dbPath + "_uncompressed"
+	if err := os.Rename(dbPath, oldDbPath); err != nil {
+		return err
+	}
+	if err := os.Rename(newDbPath, dbPath); err != nil {
+		return err
+	}
+	if cmd.KeepOld {
+		log.Infof("The original block database was moved to '%s'",
+			oldDbPath)
+		return nil
+	}
+	log.Infof("Removing the original block database from '%s'", oldDbPath)
+	return os.RemoveAll(oldDbPath)
+}
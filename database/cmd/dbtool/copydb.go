@@ -0,0 +1,168 @@
+// Copyright (c) 2023 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"errors"
+
+	"github.com/EXCCoin/exccd/database/v3"
+)
+
+const (
+	// copyBatchSize is the number of blocks or metadata entries that are
+	// copied per database transaction when copying between databases.
+	copyBatchSize = 1000
+
+	// ffldbBlockIdxName is the name of the bucket ffldb uses internally to
+	// track the location of the blocks in the flat files.
+	ffldbBlockIdxName = "ffldb-blockidx"
+
+	// ffldbWriteLocName is the name of the key ffldb uses internally to
+	// track the current write location of the flat files.
+	ffldbWriteLocName = "ffldb-writeloc"
+)
+
+// errCopyInterrupted is returned when copying between databases is
+// interrupted by the user.
+var errCopyInterrupted = errors.New("database copy interrupted")
+
+// dbCopier houses the state used to copy the contents of an existing database
+// into another one.
+type dbCopier struct {
+	srcDB database.DB
+	dstDB database.DB
+	quit  chan struct{}
+}
+
+// interrupted returns whether or not the user requested an interrupt.
+func (c *dbCopier) interrupted() bool {
+	select {
+	case <-c.quit:
+		return true
+	default:
+		return false
+	}
+}
+
+// bucketAtPath returns the nested bucket identified by the provided path of
+// bucket names starting from the metadata bucket of the passed transaction.
+func bucketAtPath(tx database.Tx, path [][]byte) database.Bucket {
+	bucket := tx.Metadata()
+	for _, name := range path {
+		bucket = bucket.Bucket(name)
+		if bucket == nil {
+			return nil
+		}
+	}
+	return bucket
+}
+
+// isFfldbInternal returns whether or not the provided key in the metadata
+// bucket is used internally by ffldb and therefore must not be copied.
+func isFfldbInternal(path [][]byte, key []byte) bool {
+	if len(path) != 0 {
+		return false
+	}
+	return string(key) == ffldbBlockIdxName ||
+		string(key) == ffldbWriteLocName
+}
+
+// copyBucket copies all key/value pairs and nested buckets of the bucket
+// identified by the provided path from the source database to the destination
+// database.
+func (c *dbCopier) copyBucket(path [][]byte) error {
+	// Copy the key/value pairs in batches so the entire bucket does not
+	// need to fit in memory.
+	type keyValue struct {
+		key   []byte
+		value []byte
+	}
+	var lastKey []byte
+	for {
+		if c.interrupted() {
+			return errCopyInterrupted
+		}
+
+		var batch []keyValue
+		err := c.srcDB.View(func(tx database.Tx) error {
+			bucket := bucketAtPath(tx, path)
+			cursor := bucket.Cursor()
+			ok := cursor.First()
+			if lastKey != nil {
+				ok = cursor.Seek(lastKey)
+				if ok && bytes.Equal(cursor.Key(), lastKey) {
+					ok = cursor.Next()
+				}
+			}
+			for ; ok && len(batch) < copyBatchSize; ok = cursor.Next() {
+				key, value := cursor.Key(), cursor.Value()
+
+				// Nested buckets do not have a value and are
+				// copied separately below.
+				if isFfldbInternal(path, key) ||
+					(value == nil && bucket.Bucket(key) != nil) {
+					continue
+				}
+				batch = append(batch, keyValue{
+					key:   append([]byte(nil), key...),
+					value: append([]byte(nil), value...),
+				})
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		if len(batch) == 0 {
+			break
+		}
+
+		err = c.dstDB.Update(func(tx database.Tx) error {
+			bucket := bucketAtPath(tx, path)
+			for _, kv := range batch {
+				if err := bucket.Put(kv.key, kv.value); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		lastKey = batch[len(batch)-1].key
+	}
+
+	// Create and copy all nested buckets.
+	var nested [][]byte
+	err := c.srcDB.View(func(tx database.Tx) error {
+		return bucketAtPath(tx, path).ForEachBucket(func(k []byte) error {
+			if !isFfldbInternal(path, k) {
+				nested = append(nested, append([]byte(nil), k...))
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return err
+	}
+	for _, name := range nested {
+		err := c.dstDB.Update(func(tx database.Tx) error {
+			_, err := bucketAtPath(tx, path).CreateBucketIfNotExists(name)
+			return err
+		})
+		if err != nil {
+			return err
+		}
+
+		nestedPath := make([][]byte, len(path), len(path)+1)
+		copy(nestedPath, path)
+		if err := c.copyBucket(append(nestedPath, name)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
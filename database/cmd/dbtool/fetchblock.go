@@ -37,8 +37,9 @@ func (cmd *fetchBlockCmd) Execute(args []string) error {
 		return err
 	}
 
-	// Load the block database.
-	db, err := loadBlockDB()
+	// Load a read-only snapshot of the block database so the command works
+	// while the database is in use.
+	db, err := loadBlockDBReadOnly()
 	if err != nil {
 		return err
 	}
@@ -18,23 +18,26 @@ import (
 )
 
 var (
-	exccdHomeDir     = dcrutil.AppDataDir("exccd", false)
+	exccdHomeDir    = dcrutil.AppDataDir("exccd", false)
 	knownDbTypes    = database.SupportedDrivers()
 	activeNetParams = chaincfg.MainNetParams()
 
 	// Default global config.
 	cfg = &config{
-		DataDir: filepath.Join(dcrdHomeDir, "data"),
+		DataDir: filepath.Join(exccdHomeDir, "data"),
 		DbType:  "ffldb",
 	}
 )
 
 // config defines the global configuration options.
 type config struct {
-	DataDir string `short:"b" long:"datadir" description:"Location of the exccd data directory"`
-	DbType  string `long:"dbtype" description:"Database backend to use for the Block Chain"`
-	TestNet bool   `long:"testnet" description:"Use the test network"`
-	SimNet  bool   `long:"simnet" description:"Use the simulation test network"`
+	DataDir   string `short:"b" long:"datadir" description:"Location of the exccd data directory"`
+	BlocksDir string `long:"blocksdir" description:"Location of the exccd block database directory (default: datadir)"`
+	UtxoDir   string `long:"utxodir" description:"Location of the exccd UTXO database directory (default: datadir)"`
+	IndexDir  string `long:"indexdir" description:"Location of the exccd separate index database directory (default: indexes are stored in the block database)"`
+	DbType    string `long:"dbtype" description:"Database backend to use for the Block Chain"`
+	TestNet   bool   `long:"testnet" description:"Use the test network"`
+	SimNet    bool   `long:"simnet" description:"Use the simulation test network"`
 }
 
 // fileExists reports whether the named file or directory exists.
@@ -94,5 +97,20 @@ func setupGlobalConfig() error {
 	// worry about changing names per network and such.
 	cfg.DataDir = filepath.Join(cfg.DataDir, activeNetParams.Name)
 
+	// Namespace the block, UTXO, and index database directories per network
+	// in the same way exccd does when they are specified.
+	cfg.BlocksDir = networkSubDir(cfg.BlocksDir, cfg.DataDir)
+	cfg.UtxoDir = networkSubDir(cfg.UtxoDir, cfg.DataDir)
+	cfg.IndexDir = networkSubDir(cfg.IndexDir, "")
+
 	return nil
 }
+
+// networkSubDir returns the network specific subdirectory of the passed
+// directory, or the passed default directory when no directory is given.
+func networkSubDir(dir, defaultDir string) string {
+	if dir == "" {
+		return defaultDir
+	}
+	return filepath.Join(dir, activeNetParams.Name)
+}
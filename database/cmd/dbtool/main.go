@@ -13,6 +13,7 @@ import (
 	"strings"
 
 	"github.com/EXCCoin/exccd/database/v3"
+	"github.com/EXCCoin/exccd/database/v3/ffldb"
 	"github.com/decred/slog"
 	flags "github.com/jessevdk/go-flags"
 )
@@ -20,6 +21,12 @@ import (
 const (
 	// blockDbNamePrefix is the prefix for the block database.
 	blockDbNamePrefix = "blocks"
+
+	// indexDbNamePrefix is the prefix for the separate index database.
+	indexDbNamePrefix = "indexes"
+
+	// utxoDbName is the name of the UTXO database.
+	utxoDbName = "utxodb"
 )
 
 var (
@@ -27,11 +34,16 @@ var (
 	shutdownChannel = make(chan error)
 )
 
-// loadBlockDB opens the block database and returns a handle to it.
-func loadBlockDB() (database.DB, error) {
+// blockDbPath returns the path to the block database.
+func blockDbPath() string {
 	// The database name is based on the database type.
 	dbName := blockDbNamePrefix + "_" + cfg.DbType
-	dbPath := filepath.Join(cfg.DataDir, dbName)
+	return filepath.Join(cfg.BlocksDir, dbName)
+}
+
+// loadBlockDB opens the block database and returns a handle to it.
+func loadBlockDB() (database.DB, error) {
+	dbPath := blockDbPath()
 
 	log.Infof("Loading block database from '%s'", dbPath)
 	db, err := database.Open(cfg.DbType, dbPath, activeNetParams.Net)
@@ -43,7 +55,7 @@ func loadBlockDB() (database.DB, error) {
 		}
 
 		// Create the db if it does not exist.
-		err = os.MkdirAll(cfg.DataDir, 0700)
+		err = os.MkdirAll(cfg.BlocksDir, 0700)
 		if err != nil {
 			return nil, err
 		}
@@ -57,6 +69,23 @@ func loadBlockDB() (database.DB, error) {
 	return db, nil
 }
 
+// loadBlockDBReadOnly opens a read-only snapshot of the existing block database
+// and returns a handle to it.  Unlike loadBlockDB, it does not require exclusive
+// access to the database, so it may be used while exccd is running.
+func loadBlockDBReadOnly() (database.DB, error) {
+	dbPath := blockDbPath()
+
+	log.Infof("Loading read-only block database from '%s'", dbPath)
+	db, err := database.Open(cfg.DbType, dbPath, activeNetParams.Net,
+		ffldb.BlockCompressionNone, ffldb.AccessReadOnly)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Info("Block database loaded")
+	return db, nil
+}
+
 // realMain is the real main function for the utility.  It is necessary to work
 // around the fact that deferred functions do not run when os.Exit() is called.
 func realMain() error {
@@ -89,6 +118,19 @@ func realMain() error {
 	parser.AddCommand("fetchblockregion",
 		"Fetch the specified block region from the database", "",
 		&blockRegionCfg)
+	parser.AddCommand("compressblocks",
+		"Rewrite all blocks in the database with compression enabled",
+		"Rewrite all blocks in the database with compression enabled.  "+
+			"A compressed copy of the database is created next to "+
+			"the existing one and replaces it once complete, so "+
+			"enough free disk space for the copy is required.",
+		&compressBlocksCfg)
+	parser.AddCommand("relocate",
+		"Move the block, UTXO, and index databases to new directories",
+		"Move the block, UTXO, and index databases to new directories.  "+
+			"The databases are renamed when possible and copied "+
+			"otherwise, such as when moving them to another device.  "+
+			"exccd must not be running.", &relocateCfg)
 
 	// Parse command line and invoke the Execute function for the specified
 	// command.
@@ -0,0 +1,323 @@
+// Copyright (c) 2023 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/EXCCoin/exccd/database/v3"
+)
+
+// indexBucketNames houses the names of the buckets in the metadata of the block
+// database that belong to the optional indexes.  The first entry is the bucket
+// that tracks the index tips.
+var indexBucketNames = [][]byte{
+	[]byte("idxtips"),
+	[]byte("txbyhashidx"),
+	[]byte("idbyhashidx"),
+	[]byte("hashbyididx"),
+	[]byte("txbyaddridx"),
+	[]byte("existsaddridx"),
+	[]byte("spenderidx"),
+	[]byte("addrbalidx"),
+	[]byte("blockstatsidx"),
+	[]byte("ticketidx"),
+	[]byte("coinstatsidx"),
+	[]byte("cfheaderidx"),
+	[]byte("scriptidx"),
+}
+
+// relocateCmd defines the configuration options for the relocate command.
+type relocateCmd struct {
+	NewBlocksDir string `long:"newblocksdir" description:"Directory to move the block database to"`
+	NewUtxoDir   string `long:"newutxodir" description:"Directory to move the UTXO database to"`
+	NewIndexDir  string `long:"newindexdir" description:"Directory to move the indexes to -- They are moved out of the block database into a separate index database when the indexdir option is not set"`
+}
+
+var (
+	// relocateCfg defines the configuration options for the command.
+	relocateCfg = relocateCmd{}
+)
+
+// copyFile copies the regular file at the source path to the destination path
+// while preserving its permissions.
+func copyFile(src, dst string, mode os.FileMode) error {
+	srcFile, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer srcFile.Close()
+
+	dstFile, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_EXCL, mode)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(dstFile, srcFile); err != nil {
+		dstFile.Close()
+		return err
+	}
+	if err := dstFile.Sync(); err != nil {
+		dstFile.Close()
+		return err
+	}
+	return dstFile.Close()
+}
+
+// copyDir recursively copies the directory at the source path to the
+// destination path.
+func copyDir(src, dst string) error {
+	return filepath.Walk(src, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		relPath, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, relPath)
+		if info.IsDir() {
+			return os.MkdirAll(target, 0700)
+		}
+		return copyFile(path, target, info.Mode().Perm())
+	})
+}
+
+// moveDir moves the directory at the source path to the destination path while
+// creating any missing parent directories of the destination.  Directories that
+// can't simply be renamed, such as when moving them to a different device, are
+// copied and the original is removed once the copy is complete.
+func moveDir(src, dst string) error {
+	if !fileExists(src) {
+		return fmt.Errorf("%q does not exist", src)
+	}
+	if fileExists(dst) {
+		return fmt.Errorf("%q already exists", dst)
+	}
+	if err := os.MkdirAll(filepath.Dir(dst), 0700); err != nil {
+		return err
+	}
+
+	log.Infof("Moving '%s' to '%s'", src, dst)
+	if err := os.Rename(src, dst); err == nil {
+		return nil
+	}
+
+	// Copy to a temporary path first so an interrupted copy never leaves a
+	// partial directory at the destination.
+	partialDst := dst + "_partial"
+	if err := os.RemoveAll(partialDst); err != nil {
+		return err
+	}
+	if err := copyDir(src, partialDst); err != nil {
+		return err
+	}
+	if err := os.Rename(partialDst, dst); err != nil {
+		return err
+	}
+	return os.RemoveAll(src)
+}
+
+// deleteBucket removes the top-level metadata bucket with the provided name
+// from the database.  The keys are removed in batches first since removing
+// the entire bucket in a single transaction requires holding all of its
+// entries in memory.
+func deleteBucket(db database.DB, name []byte, quit chan struct{}) error {
+	for {
+		select {
+		case <-quit:
+			return errCopyInterrupted
+		default:
+		}
+
+		var numDeleted int
+		err := db.Update(func(tx database.Tx) error {
+			bucket := tx.Metadata().Bucket(name)
+			if bucket == nil {
+				return nil
+			}
+			cursor := bucket.Cursor()
+			for ok := cursor.First(); ok && numDeleted < copyBatchSize; ok = cursor.Next() {
+				// Nested buckets are removed along with the bucket below.
+				if cursor.Value() == nil && bucket.Bucket(cursor.Key()) != nil {
+					continue
+				}
+				if err := cursor.Delete(); err != nil {
+					return err
+				}
+				numDeleted++
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		if numDeleted == 0 {
+			break
+		}
+	}
+
+	return db.Update(func(tx database.Tx) error {
+		meta := tx.Metadata()
+		if meta.Bucket(name) == nil {
+			return nil
+		}
+		return meta.DeleteBucket(name)
+	})
+}
+
+// extractIndexes moves the optional indexes out of the block database into a
+// new separate index database at the provided path.
+func extractIndexes(dstPath string) error {
+	if fileExists(dstPath) {
+		return fmt.Errorf("%q already exists", dstPath)
+	}
+
+	blockDB, err := loadBlockDB()
+	if err != nil {
+		return err
+	}
+	defer blockDB.Close()
+
+	// Determine which of the index buckets exist in the block database.
+	var names [][]byte
+	err = blockDB.View(func(tx database.Tx) error {
+		meta := tx.Metadata()
+		for _, name := range indexBucketNames {
+			if meta.Bucket(name) != nil {
+				names = append(names, name)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if len(names) == 0 || !bytes.Equal(names[0], indexBucketNames[0]) {
+		log.Infof("The block database does not contain any indexes")
+		return nil
+	}
+
+	// Copy the indexes into a new database at a temporary path first so an
+	// interrupted copy never leaves a partial index database behind.
+	partialPath := dstPath + "_partial"
+	if err := os.RemoveAll(partialPath); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(dstPath), 0700); err != nil {
+		return err
+	}
+	indexDB, err := database.Create(cfg.DbType, partialPath,
+		activeNetParams.Net)
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(partialPath)
+	defer indexDB.Close()
+
+	c := &dbCopier{srcDB: blockDB, dstDB: indexDB, quit: make(chan struct{})}
+	addInterruptHandler(func() {
+		log.Infof("Stopping index relocation...")
+		close(c.quit)
+	})
+	for _, name := range names {
+		log.Infof("Copying the %s bucket...", name)
+		err := indexDB.Update(func(tx database.Tx) error {
+			_, err := tx.Metadata().CreateBucket(name)
+			return err
+		})
+		if err != nil {
+			return err
+		}
+		if err := c.copyBucket([][]byte{name}); err != nil {
+			return err
+		}
+	}
+	if err := indexDB.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(partialPath, dstPath); err != nil {
+		return err
+	}
+
+	// Remove the indexes from the block database now that they live in the
+	// separate database.  The bucket with the index tips is removed first so
+	// the block database no longer reports any indexes even if the removal
+	// of the remaining buckets is interrupted.
+	log.Infof("Removing the indexes from the block database...")
+	for _, name := range names {
+		if err := deleteBucket(blockDB, name, c.quit); err != nil {
+			if errors.Is(err, errCopyInterrupted) {
+				log.Warnf("Index removal interrupted -- the block " +
+					"database may contain unused index data")
+			}
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Execute is the main entry point for the command.  It's invoked by the parser.
+func (cmd *relocateCmd) Execute(args []string) error {
+	// Setup the global config options and ensure they are valid.
+	if err := setupGlobalConfig(); err != nil {
+		return err
+	}
+	if cmd.NewBlocksDir == "" && cmd.NewUtxoDir == "" && cmd.NewIndexDir == "" {
+		return errors.New("at least one of the newblocksdir, newutxodir, " +
+			"or newindexdir options must be specified")
+	}
+
+	// Move the block database and update the global configuration so it is
+	// loaded from the new location when extracting the indexes below.
+	if cmd.NewBlocksDir != "" {
+		newBlocksDir := networkSubDir(cmd.NewBlocksDir, "")
+		srcPath := blockDbPath()
+		dstPath := filepath.Join(newBlocksDir, filepath.Base(srcPath))
+		if srcPath != dstPath {
+			if err := moveDir(srcPath, dstPath); err != nil {
+				return err
+			}
+			cfg.BlocksDir = newBlocksDir
+		}
+	}
+
+	if cmd.NewUtxoDir != "" {
+		srcPath := filepath.Join(cfg.UtxoDir, utxoDbName)
+		dstPath := filepath.Join(networkSubDir(cmd.NewUtxoDir, ""), utxoDbName)
+		if srcPath != dstPath {
+			if err := moveDir(srcPath, dstPath); err != nil {
+				return err
+			}
+		}
+	}
+
+	if cmd.NewIndexDir != "" {
+		indexDbName := indexDbNamePrefix + "_" + cfg.DbType
+		dstPath := filepath.Join(networkSubDir(cmd.NewIndexDir, ""),
+			indexDbName)
+		if cfg.IndexDir == "" {
+			if err := extractIndexes(dstPath); err != nil {
+				return err
+			}
+		} else {
+			srcPath := filepath.Join(cfg.IndexDir, indexDbName)
+			if srcPath != dstPath {
+				if err := moveDir(srcPath, dstPath); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	log.Infof("Relocation complete -- start exccd with the blocksdir, " +
+		"utxodir, and indexdir options that match the new layout")
+	return nil
+}
@@ -6,6 +6,9 @@
 package ffldb
 
 import (
+	"bytes"
+	"compress/bzip2"
+	"encoding/gob"
 	"os"
 	"path/filepath"
 	"testing"
@@ -98,3 +101,83 @@ func BenchmarkBlock(b *testing.B) {
 	// Don't benchmark teardown.
 	b.StopTimer()
 }
+
+// loadRawBlocks loads the serialized blocks contained in the testdata
+// directory and returns a slice of them ordered by height.  Unlike loadBlocks,
+// the blocks are not deserialized.
+func loadRawBlocks(tb testing.TB, dataFile string) [][]byte {
+	fi, err := os.Open(dataFile)
+	if err != nil {
+		tb.Fatalf("failed to open file %v, err %v", dataFile, err)
+	}
+	defer fi.Close()
+
+	var bcBuf bytes.Buffer
+	if _, err := bcBuf.ReadFrom(bzip2.NewReader(fi)); err != nil {
+		tb.Fatalf("failed to read file %v, err %v", dataFile, err)
+	}
+	blockChain := make(map[int64][]byte)
+	if err := gob.NewDecoder(&bcBuf).Decode(&blockChain); err != nil {
+		tb.Fatalf("error decoding test blockchain: %v", err)
+	}
+
+	rawBlocks := make([][]byte, 0, len(blockChain))
+	for i := int64(0); i < int64(len(blockChain)); i++ {
+		rawBlocks = append(rawBlocks, blockChain[i])
+	}
+	return rawBlocks
+}
+
+// BenchmarkWriteBlockCompression benchmarks writing all of the blocks in the
+// test data to the flat files with each supported block compression algorithm.
+// Each operation writes the full set of blocks, so the reported average on-disk
+// size of each block record and the percentage of disk space saved as compared
+// to uncompressed block records do not depend on the number of iterations.
+//
+// Note that the blocks in the test data are small early blocks, so the results
+// are not representative of the savings for recent blocks.
+func BenchmarkWriteBlockCompression(b *testing.B) {
+	rawBlocks := loadRawBlocks(b, blockDataFile)
+	compressions := []BlockCompression{
+		BlockCompressionNone,
+		BlockCompressionDeflate,
+	}
+	for _, compression := range compressions {
+		b.Run(compression.String(), func(b *testing.B) {
+			dbPath := filepath.Join(os.TempDir(), "ffldb-benchcompress")
+			_ = os.RemoveAll(dbPath)
+			if err := os.MkdirAll(dbPath, 0700); err != nil {
+				b.Fatal(err)
+			}
+			defer os.RemoveAll(dbPath)
+			store := newBlockStore(dbPath, blockDataNet, compression)
+
+			var rawBytes, diskBytes uint64
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				rawBytes, diskBytes = 0, 0
+				for _, rawBlock := range rawBlocks {
+					loc, err := store.writeBlock(rawBlock)
+					if err != nil {
+						b.Fatal(err)
+					}
+					rawBytes += uint64(len(rawBlock)) + 12
+					diskBytes += uint64(loc.blockLen)
+				}
+			}
+
+			// Don't benchmark teardown.
+			b.StopTimer()
+			if file := store.writeCursor.curFile.file; file != nil {
+				_ = file.Close()
+			}
+
+			numBlocks := float64(len(rawBlocks))
+			saved := 1 - float64(diskBytes)/float64(rawBytes)
+			b.ReportMetric(numBlocks, "blocks/op")
+			b.ReportMetric(float64(diskBytes)/numBlocks, "diskbytes/block")
+			b.ReportMetric(saved*100, "%saved")
+		})
+	}
+}
@@ -9,6 +9,8 @@
 package ffldb
 
 import (
+	"bytes"
+	"compress/flate"
 	"container/list"
 	"encoding/binary"
 	"fmt"
@@ -45,8 +47,9 @@ const (
 	// maxBlockFileSize is the maximum size for each file used to store
 	// blocks.
 	//
-	// NOTE: The current code uses uint32 for all offsets, so this value
-	// must be less than 2^32 (4 GiB).  This is also why it's a typed
+	// NOTE: The current code uses uint32 for all offsets and reserves the
+	// high bit of block lengths for the compressed block flag, so this
+	// value must be less than 2^31 (2 GiB).  This is also why it's a typed
 	// constant.
 	maxBlockFileSize uint32 = 512 * 1024 * 1024 // 512 MiB
 
@@ -59,8 +62,49 @@ const (
 	//  [4:8]  File offset (4 bytes)
 	//  [8:12] Block length (4 bytes)
 	blockLocSize = 12
+
+	// compressedBlockFlag is the bit that is set in the block length of
+	// both the serialized block location and the block record in the flat
+	// file to indicate the record houses compressed block data.  Records
+	// without the flag are stored as is, which allows files written before
+	// compression support existed to live side by side with new ones.
+	compressedBlockFlag uint32 = 1 << 31
+
+	// compressedHdrSize is the number of bytes that precede the compressed
+	// block data in a compressed block record.  It consists of 1 byte for
+	// the compression type and 4 bytes for the uncompressed block length.
+	compressedHdrSize = 5
+)
+
+// BlockCompression identifies the compression algorithm used to store the
+// serialized block data in the flat files.
+type BlockCompression byte
+
+// These constants define the supported block compression algorithms.
+const (
+	// BlockCompressionNone stores the serialized blocks as is.
+	BlockCompressionNone BlockCompression = 0
+
+	// BlockCompressionDeflate compresses the serialized blocks with the
+	// DEFLATE algorithm as specified by RFC 1951.
+	BlockCompressionDeflate BlockCompression = 1
 )
 
+// blockCompressionStrings is a map of block compression algorithms back to
+// their constant names for pretty printing.
+var blockCompressionStrings = map[BlockCompression]string{
+	BlockCompressionNone:    "none",
+	BlockCompressionDeflate: "deflate",
+}
+
+// String returns the BlockCompression as a human-readable name.
+func (c BlockCompression) String() string {
+	if s := blockCompressionStrings[c]; s != "" {
+		return s
+	}
+	return fmt.Sprintf("Unknown BlockCompression (%d)", byte(c))
+}
+
 var (
 	// castagnoli houses the Castagnoli polynomial used for CRC-32
 	// checksums.
@@ -178,13 +222,28 @@ type blockStore struct {
 	openFileFunc      func(fileNum uint32) (*lockableFile, error)
 	openWriteFileFunc func(fileNum uint32) (filer, error)
 	deleteFileFunc    func(fileNum uint32) error
+
+	// compression is the algorithm used to compress newly written blocks.
+	// Existing blocks are always read according to the format they were
+	// written with regardless of this setting.
+	//
+	// compressBuf and compressor are reused between block writes to avoid
+	// allocations.  They are only accessed from writeBlock which can only
+	// be called during a write transaction, of which there can be only one
+	// at a time.
+	compression BlockCompression
+	compressBuf bytes.Buffer
+	compressor  *flate.Writer
 }
 
-// blockLocation identifies a particular block file and location.
+// blockLocation identifies a particular block file and location.  The block
+// length is the full length of the block record in the flat file and the
+// compressed flag indicates whether the record houses compressed block data.
 type blockLocation struct {
 	blockFileNum uint32
 	fileOffset   uint32
 	blockLen     uint32
+	compressed   bool
 }
 
 // deserializeBlockLoc deserializes the passed serialized block location
@@ -199,10 +258,14 @@ func deserializeBlockLoc(serializedLoc []byte) blockLocation {
 	//  [0:4]  Block file (4 bytes)
 	//  [4:8]  File offset (4 bytes)
 	//  [8:12] Block length (4 bytes)
+	//
+	// The high bit of the block length is the compressed block flag.
+	blockLen := byteOrder.Uint32(serializedLoc[8:12])
 	return blockLocation{
 		blockFileNum: byteOrder.Uint32(serializedLoc[0:4]),
 		fileOffset:   byteOrder.Uint32(serializedLoc[4:8]),
-		blockLen:     byteOrder.Uint32(serializedLoc[8:12]),
+		blockLen:     blockLen &^ compressedBlockFlag,
+		compressed:   blockLen&compressedBlockFlag != 0,
 	}
 }
 
@@ -214,10 +277,16 @@ func serializeBlockLoc(loc blockLocation) []byte {
 	//  [0:4]  Block file (4 bytes)
 	//  [4:8]  File offset (4 bytes)
 	//  [8:12] Block length (4 bytes)
+	//
+	// The high bit of the block length is the compressed block flag.
+	blockLen := loc.blockLen
+	if loc.compressed {
+		blockLen |= compressedBlockFlag
+	}
 	var serializedData [12]byte
 	byteOrder.PutUint32(serializedData[0:4], loc.blockFileNum)
 	byteOrder.PutUint32(serializedData[4:8], loc.fileOffset)
-	byteOrder.PutUint32(serializedData[8:12], loc.blockLen)
+	byteOrder.PutUint32(serializedData[8:12], blockLen)
 	return serializedData[:]
 }
 
@@ -395,21 +464,120 @@ func (s *blockStore) writeData(data []byte, fieldName string) error {
 	return nil
 }
 
+// compressBlock compresses the provided serialized block according to the
+// compression algorithm configured for the store and returns the resulting
+// block record payload along with whether or not it was compressed.  Blocks
+// are stored uncompressed when compression is disabled or it would not result
+// in any savings.
+//
+// Format: <compression type><uncompressed block length><compressed block>
+//
+// NOTE: The returned payload is only valid until the next call.
+func (s *blockStore) compressBlock(rawBlock []byte) ([]byte, bool, error) {
+	if s.compression != BlockCompressionDeflate {
+		return rawBlock, false, nil
+	}
+
+	buf := &s.compressBuf
+	buf.Reset()
+	var hdr [compressedHdrSize]byte
+	hdr[0] = byte(s.compression)
+	byteOrder.PutUint32(hdr[1:], uint32(len(rawBlock)))
+	buf.Write(hdr[:])
+	if s.compressor == nil {
+		compressor, err := flate.NewWriter(buf, flate.DefaultCompression)
+		if err != nil {
+			return nil, false, err
+		}
+		s.compressor = compressor
+	} else {
+		s.compressor.Reset(buf)
+	}
+	if _, err := s.compressor.Write(rawBlock); err != nil {
+		return nil, false, err
+	}
+	if err := s.compressor.Close(); err != nil {
+		return nil, false, err
+	}
+	if buf.Len() >= len(rawBlock) {
+		return rawBlock, false, nil
+	}
+	return buf.Bytes(), true, nil
+}
+
+// decompressBlock decompresses the provided compressed block record payload
+// and returns the serialized block.  The hash is only used to provide nicer
+// error messages.
+//
+// Returns ErrCorruption if the payload is malformed.
+//
+// Format: <compression type><uncompressed block length><compressed block>
+func decompressBlock(hash *chainhash.Hash, payload []byte) ([]byte, error) {
+	if len(payload) < compressedHdrSize {
+		str := fmt.Sprintf("compressed block data for block %s is "+
+			"truncated", hash)
+		return nil, makeDbErr(database.ErrCorruption, str)
+	}
+	compression := BlockCompression(payload[0])
+	if compression != BlockCompressionDeflate {
+		str := fmt.Sprintf("block data for block %s uses unsupported "+
+			"compression %v", hash, compression)
+		return nil, makeDbErr(database.ErrCorruption, str)
+	}
+	blockLen := byteOrder.Uint32(payload[1:compressedHdrSize])
+	if blockLen > wire.MaxBlockPayload {
+		str := fmt.Sprintf("compressed block data for block %s claims "+
+			"an uncompressed length of %d which exceeds the max "+
+			"block payload of %d", hash, blockLen,
+			wire.MaxBlockPayload)
+		return nil, makeDbErr(database.ErrCorruption, str)
+	}
+
+	// Decompress exactly the number of bytes the record claims and ensure
+	// there is no trailing data.
+	r := flate.NewReader(bytes.NewReader(payload[compressedHdrSize:]))
+	defer r.Close()
+	rawBlock := make([]byte, blockLen)
+	if _, err := io.ReadFull(r, rawBlock); err != nil {
+		str := fmt.Sprintf("failed to decompress block data for block "+
+			"%s: %v", hash, err)
+		return nil, makeDbErr(database.ErrCorruption, str)
+	}
+	var extra [1]byte
+	if n, _ := r.Read(extra[:]); n != 0 {
+		str := fmt.Sprintf("compressed block data for block %s "+
+			"exceeds the claimed length of %d", hash, blockLen)
+		return nil, makeDbErr(database.ErrCorruption, str)
+	}
+
+	return rawBlock, nil
+}
+
 // writeBlock appends the specified raw block bytes to the store's write cursor
 // location and increments it accordingly.  When the block would exceed the max
 // file size for the current flat file, this function will close the current
 // file, create the next file, update the write cursor, and write the block to
 // the new file.
 //
+// The block is compressed according to the compression algorithm configured
+// for the store, in which case the compressed block flag is set in the block
+// length.
+//
 // The write cursor will also be advanced the number of bytes actually written
 // in the event of failure.
 //
 // Format: <network><block length><serialized block><checksum>
 func (s *blockStore) writeBlock(rawBlock []byte) (blockLocation, error) {
+	payload, compressed, err := s.compressBlock(rawBlock)
+	if err != nil {
+		str := fmt.Sprintf("failed to compress block: %v", err)
+		return blockLocation{}, makeDbErr(database.ErrDriverSpecific, str)
+	}
+
 	// Compute how many bytes will be written.
 	// 4 bytes each for block network + 4 bytes for block length +
 	// length of raw block + 4 bytes for checksum.
-	blockLen := uint32(len(rawBlock))
+	blockLen := uint32(len(payload))
 	fullLen := blockLen + 12
 
 	// Move to the next block file if adding the new block would exceed the
@@ -472,18 +640,22 @@ func (s *blockStore) writeBlock(rawBlock []byte) (blockLocation, error) {
 	}
 	_, _ = hasher.Write(scratch[:])
 
-	// Block length.
-	byteOrder.PutUint32(scratch[:], blockLen)
+	// Block length along with the compressed block flag when needed.
+	serializedLen := blockLen
+	if compressed {
+		serializedLen |= compressedBlockFlag
+	}
+	byteOrder.PutUint32(scratch[:], serializedLen)
 	if err := s.writeData(scratch[:], "block length"); err != nil {
 		return blockLocation{}, err
 	}
 	_, _ = hasher.Write(scratch[:])
 
 	// Serialized block.
-	if err := s.writeData(rawBlock, "block"); err != nil {
+	if err := s.writeData(payload, "block"); err != nil {
 		return blockLocation{}, err
 	}
-	_, _ = hasher.Write(rawBlock)
+	_, _ = hasher.Write(payload)
 
 	// Castagnoli CRC-32 as a checksum of all the previous.
 	if err := s.writeData(hasher.Sum(nil), "checksum"); err != nil {
@@ -494,6 +666,7 @@ func (s *blockStore) writeBlock(rawBlock []byte) (blockLocation, error) {
 		blockFileNum: wc.curFileNum,
 		fileOffset:   origOffset,
 		blockLen:     fullLen,
+		compressed:   compressed,
 	}
 	return loc, nil
 }
@@ -502,13 +675,13 @@ func (s *blockStore) writeBlock(rawBlock []byte) (blockLocation, error) {
 // It ensures the integrity of the block data by checking that the serialized
 // network matches the current network associated with the block store and
 // comparing the calculated checksum against the one stored in the flat file.
-// This function also automatically handles all file management such as opening
-// and closing files as necessary to stay within the maximum allowed open files
-// limit.
+// Compressed block data is transparently decompressed.  This function also
+// automatically handles all file management such as opening and closing files
+// as necessary to stay within the maximum allowed open files limit.
 //
 // Returns ErrDriverSpecific if the data fails to read for any reason and
 // ErrCorruption if the checksum of the read data doesn't match the checksum
-// read from the file.
+// read from the file or the compressed block data is malformed.
 //
 // Format: <network><block length><serialized block><checksum>
 func (s *blockStore) readBlock(hash *chainhash.Hash, loc blockLocation) ([]byte, error) {
@@ -555,8 +728,15 @@ func (s *blockStore) readBlock(hash *chainhash.Hash, loc blockLocation) ([]byte,
 	}
 
 	// The raw block excludes the network, length of the block, and
-	// checksum.
-	return serializedData[8 : n-4], nil
+	// checksum.  The compressed block flag in the record itself is
+	// authoritative as to whether or not the data needs to be
+	// decompressed.
+	payload := serializedData[8 : n-4]
+	serializedLen := byteOrder.Uint32(serializedData[4:8])
+	if serializedLen&compressedBlockFlag != 0 {
+		return decompressBlock(hash, payload)
+	}
+	return payload, nil
 }
 
 // readBlockRegion reads the specified amount of data at the provided offset for
@@ -566,8 +746,21 @@ func (s *blockStore) readBlock(hash *chainhash.Hash, loc blockLocation) ([]byte,
 // closing files as necessary to stay within the maximum allowed open files
 // limit.
 //
-// Returns ErrDriverSpecific if the data fails to read for any reason.
-func (s *blockStore) readBlockRegion(loc blockLocation, offset, numBytes uint32) ([]byte, error) {
+// Regions of compressed blocks require the entire block to be read and
+// decompressed, so the region bounds are checked against the uncompressed
+// block in that case.  The hash is only used to provide nicer error messages.
+//
+// Returns ErrDriverSpecific if the data fails to read for any reason and
+// ErrBlockRegionInvalid if the region exceeds the bounds of a compressed block.
+func (s *blockStore) readBlockRegion(hash *chainhash.Hash, loc blockLocation, offset, numBytes uint32) ([]byte, error) {
+	if loc.compressed {
+		rawBlock, err := s.readBlock(hash, loc)
+		if err != nil {
+			return nil, err
+		}
+		return sliceBlockRegion(hash, rawBlock, offset, numBytes)
+	}
+
 	// Get the referenced block file handle opening the file as needed.  The
 	// function also handles closing files as needed to avoid going over the
 	// max allowed open files.
@@ -593,6 +786,24 @@ func (s *blockStore) readBlockRegion(loc blockLocation, offset, numBytes uint32)
 	return serializedData, nil
 }
 
+// sliceBlockRegion returns the specified amount of data at the provided offset
+// of the passed serialized block.  The hash is only used to provide nicer error
+// messages.
+//
+// Returns ErrBlockRegionInvalid if the region exceeds the bounds of the block.
+func sliceBlockRegion(hash *chainhash.Hash, rawBlock []byte, offset, numBytes uint32) ([]byte, error) {
+	blockLen := uint32(len(rawBlock))
+	endOffset := offset + numBytes
+	if endOffset < offset || endOffset > blockLen {
+		str := fmt.Sprintf("block %s region offset %d, length %d "+
+			"exceeds block length of %d", hash, offset, numBytes,
+			blockLen)
+		return nil, makeDbErr(database.ErrBlockRegionInvalid, str)
+	}
+
+	return rawBlock[offset:endOffset:endOffset], nil
+}
+
 // syncBlocks performs a file system sync on the flat file associated with the
 // store's current write cursor.  It is safe to call even when there is not a
 // current write file in which case it will have no effect.
@@ -743,8 +954,9 @@ func scanBlockFiles(dbPath string) (int, uint32) {
 }
 
 // newBlockStore returns a new block store with the current block file number
-// and offset set and all fields initialized.
-func newBlockStore(basePath string, network wire.CurrencyNet) *blockStore {
+// and offset set and all fields initialized.  Newly written blocks are
+// compressed with the provided compression algorithm.
+func newBlockStore(basePath string, network wire.CurrencyNet, compression BlockCompression) *blockStore {
 	// Look for the end of the latest block to file to determine what the
 	// write cursor position is from the viewpoint of the block files on
 	// disk.
@@ -758,6 +970,7 @@ func newBlockStore(basePath string, network wire.CurrencyNet) *blockStore {
 		network:          network,
 		basePath:         basePath,
 		maxBlockFileSize: maxBlockFileSize,
+		compression:      compression,
 		openBlockFiles:   make(map[uint32]*lockableFile),
 		openBlocksLRU:    list.New(),
 		fileNumToLRUElem: make(map[uint32]*list.Element),
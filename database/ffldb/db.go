@@ -10,6 +10,7 @@ import (
 	"encoding/binary"
 	"errors"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"runtime"
@@ -19,6 +20,7 @@ import (
 	"github.com/EXCCoin/exccd/chaincfg/chainhash"
 	"github.com/EXCCoin/exccd/database/v3"
 	"github.com/EXCCoin/exccd/database/v3/internal/treap"
+	"github.com/EXCCoin/exccd/database/v3/ldbsnapshot"
 	"github.com/EXCCoin/exccd/wire"
 	"github.com/syndtr/goleveldb/leveldb"
 	"github.com/syndtr/goleveldb/leveldb/comparer"
@@ -1503,9 +1505,12 @@ func (tx *transaction) FetchBlockRegion(region *database.BlockRegion) ([]byte, e
 	}
 	location := deserializeBlockLoc(blockRow)
 
-	// Ensure the region is within the bounds of the block.
+	// Ensure the region is within the bounds of the block.  The bounds of
+	// compressed blocks are checked once they have been decompressed.
 	endOffset := region.Offset + region.Len
-	if endOffset < region.Offset || endOffset > location.blockLen {
+	if endOffset < region.Offset || (!location.compressed &&
+		endOffset > location.blockLen) {
+
 		str := fmt.Sprintf("block %s region offset %d, length %d "+
 			"exceeds block length of %d", region.Hash,
 			region.Offset, region.Len, location.blockLen)
@@ -1513,8 +1518,8 @@ func (tx *transaction) FetchBlockRegion(region *database.BlockRegion) ([]byte, e
 	}
 
 	// Read the region from the appropriate disk block file.
-	regionBytes, err := tx.db.store.readBlockRegion(location, region.Offset,
-		region.Len)
+	regionBytes, err := tx.db.store.readBlockRegion(region.Hash, location,
+		region.Offset, region.Len)
 	if err != nil {
 		return nil, err
 	}
@@ -1600,9 +1605,13 @@ func (tx *transaction) FetchBlockRegions(regions []database.BlockRegion) ([][]by
 		}
 		location := deserializeBlockLoc(blockRow)
 
-		// Ensure the region is within the bounds of the block.
+		// Ensure the region is within the bounds of the block.  The
+		// bounds of compressed blocks are checked once they have been
+		// decompressed.
 		endOffset := region.Offset + region.Len
-		if endOffset < region.Offset || endOffset > location.blockLen {
+		if endOffset < region.Offset || (!location.compressed &&
+			endOffset > location.blockLen) {
+
 			str := fmt.Sprintf("block %s region offset %d, length "+
 				"%d exceeds block length of %d", region.Hash,
 				region.Offset, region.Len, location.blockLen)
@@ -1614,12 +1623,35 @@ func (tx *transaction) FetchBlockRegions(regions []database.BlockRegion) ([][]by
 	sort.Sort(bulkFetchDataSorter(fetchList))
 
 	// Read all of the regions in the fetch list and set the results.
+	//
+	// Regions of compressed blocks require the entire block to be
+	// decompressed, so keep the most recently decompressed block around
+	// since the sorting above groups the regions of each block together.
+	var lastBlock []byte
+	var lastLoc *blockLocation
 	for i := range fetchList {
 		fetchData := &fetchList[i]
 		ri := fetchData.replyIndex
 		region := &regions[ri]
 		location := fetchData.blockLocation
-		regionBytes, err := tx.db.store.readBlockRegion(*location,
+		if !location.compressed {
+			regionBytes, err := tx.db.store.readBlockRegion(region.Hash,
+				*location, region.Offset, region.Len)
+			if err != nil {
+				return nil, err
+			}
+			blockRegions[ri] = regionBytes
+			continue
+		}
+
+		if lastLoc == nil || *lastLoc != *location {
+			rawBlock, err := tx.db.store.readBlock(region.Hash, *location)
+			if err != nil {
+				return nil, err
+			}
+			lastBlock, lastLoc = rawBlock, location
+		}
+		regionBytes, err := sliceBlockRegion(region.Hash, lastBlock,
 			region.Offset, region.Len)
 		if err != nil {
 			return nil, err
@@ -1791,6 +1823,7 @@ type db struct {
 	writeLock sync.Mutex   // Limit to one write transaction at a time.
 	closeLock sync.RWMutex // Make database close block while txns active.
 	closed    bool         // Is the database closed?
+	readOnly  bool         // Is the database open in read-only mode?
 	store     *blockStore  // Handles read/writing blocks to flat files.
 	cache     *dbCache     // Cache layer which wraps underlying leveldb DB.
 }
@@ -1813,6 +1846,14 @@ func (db *db) Type() string {
 // which is used by the managed transaction code while the database method
 // returns the interface.
 func (db *db) begin(writable bool) (*transaction, error) {
+	// Writable transactions are not allowed when the database is open in
+	// read-only mode.
+	if writable && db.readOnly {
+		str := "cannot begin a writable transaction on a database that is " +
+			"open in read-only mode"
+		return nil, makeDbErr(database.ErrTxNotWritable, str)
+	}
+
 	// Whenever a new writable transaction is started, grab the write lock
 	// to ensure only a single write transaction can be active at the same
 	// time.  This lock will not be released until the transaction is
@@ -2064,7 +2105,16 @@ func initDB(ldb *leveldb.DB) error {
 
 // openDB opens the database at the provided path.  database.ErrDbDoesNotExist
 // is returned if the database doesn't exist and the create flag is not set.
-func openDB(dbPath string, network wire.CurrencyNet, create bool) (database.DB, error) {
+// Blocks written to the database are compressed with the provided compression
+// algorithm.
+func openDB(dbPath string, network wire.CurrencyNet, compression BlockCompression, accessMode AccessMode, create bool) (database.DB, error) {
+	// A database can't be created in read-only mode.
+	readOnly := accessMode == AccessReadOnly
+	if create && readOnly {
+		str := "cannot create a database in read-only mode"
+		return nil, makeDbErr(database.ErrDriverSpecific, str)
+	}
+
 	// Error if the database doesn't exist and the create flag is not set.
 	metadataDbPath := filepath.Join(dbPath, metadataDbName)
 	dbExists := fileExists(metadataDbPath)
@@ -2087,16 +2137,30 @@ func openDB(dbPath string, network wire.CurrencyNet, create bool) (database.DB,
 		_ = os.MkdirAll(dbPath, 0700)
 	}
 
-	// Open the metadata database (will create it if needed).
+	// Open the metadata database (will create it if needed).  A consistent
+	// snapshot of it is opened without taking the exclusive lock in read-only
+	// mode so the database may be in use by another process.
 	opts := opt.Options{
 		ErrorIfExist: create,
 		Strict:       opt.DefaultStrict,
 		Compression:  opt.NoCompression,
 		Filter:       filter.NewBloomFilter(10),
 	}
-	ldb, err := leveldb.OpenFile(metadataDbPath, &opts)
-	if err != nil {
-		return nil, convertErr(err.Error(), err)
+	var ldb *leveldb.DB
+	var ldbCloser io.Closer
+	if readOnly {
+		snap, err := ldbsnapshot.OpenFile(metadataDbPath, &opts)
+		if err != nil {
+			return nil, convertErr(err.Error(), err)
+		}
+		ldb, ldbCloser = snap.DB, snap
+	} else {
+		var err error
+		ldb, err = leveldb.OpenFile(metadataDbPath, &opts)
+		if err != nil {
+			return nil, convertErr(err.Error(), err)
+		}
+		ldbCloser = ldb
 	}
 
 	// Create the block store which includes scanning the existing flat
@@ -2104,9 +2168,10 @@ func openDB(dbPath string, network wire.CurrencyNet, create bool) (database.DB,
 	// according to the data that is actually on disk.  Also create the
 	// database cache which wraps the underlying leveldb database to provide
 	// write caching.
-	store := newBlockStore(dbPath, network)
-	cache := newDbCache(ldb, store, defaultCacheSize, defaultFlushSecs)
-	pdb := &db{store: store, cache: cache}
+	store := newBlockStore(dbPath, network, compression)
+	cache := newDbCache(ldb, ldbCloser, store, defaultCacheSize,
+		defaultFlushSecs)
+	pdb := &db{store: store, cache: cache, readOnly: readOnly}
 
 	// Perform any reconciliation needed between the block and metadata as
 	// well as database initialization, if needed.
@@ -8,6 +8,7 @@ package ffldb
 import (
 	"bytes"
 	"fmt"
+	"io"
 	"sync"
 	"time"
 
@@ -345,6 +346,10 @@ type dbCache struct {
 	// ldb is the underlying leveldb DB for metadata.
 	ldb *leveldb.DB
 
+	// ldbCloser closes the underlying leveldb DB along with any resources it
+	// owns such as the storage of a read-only snapshot.
+	ldbCloser io.Closer
+
 	// store is used to sync blocks to flat files.
 	store *blockStore
 
@@ -628,12 +633,12 @@ func (c *dbCache) Close() error {
 		// Even if there is an error while flushing, attempt to close
 		// the underlying database.  The error is ignored since it would
 		// mask the flush error.
-		_ = c.ldb.Close()
+		_ = c.ldbCloser.Close()
 		return err
 	}
 
 	// Close the underlying leveldb database.
-	if err := c.ldb.Close(); err != nil {
+	if err := c.ldbCloser.Close(); err != nil {
 		str := "failed to close underlying leveldb database"
 		return convertErr(str, err)
 	}
@@ -642,12 +647,13 @@ func (c *dbCache) Close() error {
 }
 
 // newDbCache returns a new database cache instance backed by the provided
-// leveldb instance.  The cache will be flushed to leveldb when the max size
+// leveldb instance which is closed via the provided closer.  The cache will be flushed to leveldb when the max size
 // exceeds the provided value or it has been longer than the provided interval
 // since the last flush.
-func newDbCache(ldb *leveldb.DB, store *blockStore, maxSize uint64, flushIntervalSecs uint32) *dbCache {
+func newDbCache(ldb *leveldb.DB, ldbCloser io.Closer, store *blockStore, maxSize uint64, flushIntervalSecs uint32) *dbCache {
 	return &dbCache{
 		ldb:           ldb,
+		ldbCloser:     ldbCloser,
 		store:         store,
 		maxSize:       maxSize,
 		flushInterval: time.Second * time.Duration(flushIntervalSecs),
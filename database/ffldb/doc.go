@@ -26,5 +26,27 @@ database path as a string and the block network:
 	if err != nil {
 		// Handle error
 	}
+
+An optional third parameter specifies the compression algorithm to use for
+blocks written to the flat files.  Blocks that were previously written with a
+different algorithm, or without compression, remain readable:
+
+	db, err := database.Open("ffldb", "path/to/database", wire.MainNet,
+		ffldb.BlockCompressionDeflate)
+	if err != nil {
+		// Handle error
+	}
+
+An optional fourth parameter specifies the access mode.  A database opened with
+AccessReadOnly provides a consistent snapshot of the data that was flushed to
+disk at the time it was opened.  It does not require exclusive access to the
+database, so it may be used by tools while another process has the database
+open, and all attempts to start a writable transaction fail:
+
+	db, err := database.Open("ffldb", "path/to/database", wire.MainNet,
+		ffldb.BlockCompressionNone, ffldb.AccessReadOnly)
+	if err != nil {
+		// Handle error
+	}
 */
 package ffldb
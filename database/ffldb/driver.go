@@ -19,49 +19,116 @@ const (
 	dbType = "ffldb"
 )
 
-// parseArgs parses the arguments from the database Open/Create methods.
-func parseArgs(funcName string, args ...interface{}) (string, wire.CurrencyNet, error) {
-	if len(args) != 2 {
-		return "", 0, fmt.Errorf("invalid arguments to %s.%s -- "+
-			"expected database path and block network", dbType,
-			funcName)
+// AccessMode identifies whether a database is opened for reading and writing
+// or for reading only.
+type AccessMode byte
+
+// These constants define the supported database access modes.
+const (
+	// AccessReadWrite opens the database for reading and writing.  This
+	// requires exclusive access to the database.
+	AccessReadWrite AccessMode = 0
+
+	// AccessReadOnly opens a consistent snapshot of the database for reading
+	// only.  It does not require exclusive access, so the database may be
+	// opened this way while another process is using it.  Modifications made
+	// to the database after it is opened are not visible and all attempts
+	// to start a writable transaction fail.
+	AccessReadOnly AccessMode = 1
+)
+
+// accessModeStrings is a map of access modes back to their constant names for
+// pretty printing.
+var accessModeStrings = map[AccessMode]string{
+	AccessReadWrite: "read-write",
+	AccessReadOnly:  "read-only",
+}
+
+// String returns the AccessMode as a human-readable name.
+func (m AccessMode) String() string {
+	if s := accessModeStrings[m]; s != "" {
+		return s
+	}
+	return fmt.Sprintf("Unknown AccessMode (%d)", byte(m))
+}
+
+// parseArgs parses the arguments from the database Open/Create methods.  The
+// block compression argument is optional and defaults to no compression.  The
+// access mode argument is also optional and defaults to read-write access.
+func parseArgs(funcName string, args ...interface{}) (string, wire.CurrencyNet, BlockCompression, AccessMode, error) {
+	if len(args) < 2 || len(args) > 4 {
+		return "", 0, 0, 0, fmt.Errorf("invalid arguments to %s.%s -- "+
+			"expected database path, block network, and optional "+
+			"block compression and access mode", dbType, funcName)
 	}
 
 	dbPath, ok := args[0].(string)
 	if !ok {
-		return "", 0, fmt.Errorf("first argument to %s.%s is invalid -- "+
-			"expected database path string", dbType, funcName)
+		return "", 0, 0, 0, fmt.Errorf("first argument to %s.%s is "+
+			"invalid -- expected database path string", dbType,
+			funcName)
 	}
 
 	network, ok := args[1].(wire.CurrencyNet)
 	if !ok {
-		return "", 0, fmt.Errorf("second argument to %s.%s is invalid -- "+
-			"expected block network", dbType, funcName)
+		return "", 0, 0, 0, fmt.Errorf("second argument to %s.%s is "+
+			"invalid -- expected block network", dbType, funcName)
+	}
+
+	compression := BlockCompressionNone
+	if len(args) > 2 {
+		compression, ok = args[2].(BlockCompression)
+		if !ok {
+			return "", 0, 0, 0, fmt.Errorf("third argument to %s.%s is "+
+				"invalid -- expected block compression", dbType,
+				funcName)
+		}
+		if _, ok := blockCompressionStrings[compression]; !ok {
+			return "", 0, 0, 0, fmt.Errorf("third argument to %s.%s is "+
+				"invalid -- unsupported block compression %v",
+				dbType, funcName, compression)
+		}
+	}
+
+	accessMode := AccessReadWrite
+	if len(args) > 3 {
+		accessMode, ok = args[3].(AccessMode)
+		if !ok {
+			return "", 0, 0, 0, fmt.Errorf("fourth argument to %s.%s "+
+				"is invalid -- expected access mode", dbType, funcName)
+		}
+		if _, ok := accessModeStrings[accessMode]; !ok {
+			return "", 0, 0, 0, fmt.Errorf("fourth argument to %s.%s "+
+				"is invalid -- unsupported access mode %v", dbType,
+				funcName, accessMode)
+		}
 	}
 
-	return dbPath, network, nil
+	return dbPath, network, compression, accessMode, nil
 }
 
 // openDBDriver is the callback provided during driver registration that opens
 // an existing database for use.
 func openDBDriver(args ...interface{}) (database.DB, error) {
-	dbPath, network, err := parseArgs("Open", args...)
+	dbPath, network, compression, accessMode, err := parseArgs("Open",
+		args...)
 	if err != nil {
 		return nil, err
 	}
 
-	return openDB(dbPath, network, false)
+	return openDB(dbPath, network, compression, accessMode, false)
 }
 
 // createDBDriver is the callback provided during driver registration that
 // creates, initializes, and opens a database for use.
 func createDBDriver(args ...interface{}) (database.DB, error) {
-	dbPath, network, err := parseArgs("Create", args...)
+	dbPath, network, compression, accessMode, err := parseArgs("Create",
+		args...)
 	if err != nil {
 		return nil, err
 	}
 
-	return openDB(dbPath, network, true)
+	return openDB(dbPath, network, compression, accessMode, true)
 }
 
 // useLogger is the callback provided during driver registration that sets the
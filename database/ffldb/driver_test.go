@@ -38,8 +38,9 @@ func TestCreateOpenFail(t *testing.T) {
 	// Ensure that attempting to open a database with the wrong number of
 	// parameters returns the expected error.
 	wantErr := fmt.Errorf("invalid arguments to %s.Open -- expected "+
-		"database path and block network", dbType)
-	_, err = database.Open(dbType, 1, 2, 3)
+		"database path, block network, and optional block compression "+
+		"and access mode", dbType)
+	_, err = database.Open(dbType, 1, 2, 3, 4, 5)
 	if err.Error() != wantErr.Error() {
 		t.Errorf("Open: did not receive expected error - got %v, "+
 			"want %v", err, wantErr)
@@ -71,8 +72,9 @@ func TestCreateOpenFail(t *testing.T) {
 	// Ensure that attempting to create a database with the wrong number of
 	// parameters returns the expected error.
 	wantErr = fmt.Errorf("invalid arguments to %s.Create -- expected "+
-		"database path and block network", dbType)
-	_, err = database.Create(dbType, 1, 2, 3)
+		"database path, block network, and optional block compression "+
+		"and access mode", dbType)
+	_, err = database.Create(dbType, 1, 2, 3, 4, 5)
 	if err.Error() != wantErr.Error() {
 		t.Errorf("Create: did not receive expected error - got %v, "+
 			"want %v", err, wantErr)
@@ -101,6 +103,63 @@ func TestCreateOpenFail(t *testing.T) {
 		return
 	}
 
+	// Ensure that attempting to create a database with an invalid type for
+	// the third parameter returns the expected error.
+	wantErr = fmt.Errorf("third argument to %s.Create is invalid -- "+
+		"expected block compression", dbType)
+	_, err = database.Create(dbType, "noexist", blockDataNet, "invalid")
+	if err.Error() != wantErr.Error() {
+		t.Errorf("Create: did not receive expected error - got %v, "+
+			"want %v", err, wantErr)
+		return
+	}
+
+	// Ensure that attempting to create a database with an unsupported
+	// block compression returns the expected error.
+	wantErr = fmt.Errorf("third argument to %s.Create is invalid -- "+
+		"unsupported block compression %v", dbType,
+		ffldb.BlockCompression(255))
+	_, err = database.Create(dbType, "noexist", blockDataNet,
+		ffldb.BlockCompression(255))
+	if err.Error() != wantErr.Error() {
+		t.Errorf("Create: did not receive expected error - got %v, "+
+			"want %v", err, wantErr)
+		return
+	}
+
+	// Ensure that attempting to create a database with an invalid type for
+	// the fourth parameter returns the expected error.
+	wantErr = fmt.Errorf("fourth argument to %s.Create is invalid -- "+
+		"expected access mode", dbType)
+	_, err = database.Create(dbType, "noexist", blockDataNet,
+		ffldb.BlockCompressionNone, "invalid")
+	if err.Error() != wantErr.Error() {
+		t.Errorf("Create: did not receive expected error - got %v, "+
+			"want %v", err, wantErr)
+		return
+	}
+
+	// Ensure that attempting to create a database with an unsupported
+	// access mode returns the expected error.
+	wantErr = fmt.Errorf("fourth argument to %s.Create is invalid -- "+
+		"unsupported access mode %v", dbType, ffldb.AccessMode(255))
+	_, err = database.Create(dbType, "noexist", blockDataNet,
+		ffldb.BlockCompressionNone, ffldb.AccessMode(255))
+	if err.Error() != wantErr.Error() {
+		t.Errorf("Create: did not receive expected error - got %v, "+
+			"want %v", err, wantErr)
+		return
+	}
+
+	// Ensure that attempting to create a database in read-only mode returns
+	// the expected error.
+	wantErrKind = database.ErrDriverSpecific
+	_, err = database.Create(dbType, "noexist", blockDataNet,
+		ffldb.BlockCompressionNone, ffldb.AccessReadOnly)
+	if !checkDbError(t, "Create", err, wantErrKind) {
+		return
+	}
+
 	// Ensure operations against a closed database return the expected
 	// error.
 	dbPath := filepath.Join(os.TempDir(), "ffldb-createfail-v2")
@@ -256,6 +315,112 @@ func TestPersistence(t *testing.T) {
 	}
 }
 
+// TestReadOnly ensures that a database which is open by another instance can be
+// opened in read-only mode, that it provides the data that was flushed to disk
+// as of the time it was opened, and that it can't be modified.
+func TestReadOnly(t *testing.T) {
+	t.Parallel()
+
+	// Create a new database to run tests against.
+	dbPath := filepath.Join(t.TempDir(), "ffldb-readonlytest")
+	db, err := database.Create(dbType, dbPath, blockDataNet)
+	if err != nil {
+		t.Fatalf("Failed to create test database (%s) %v", dbType, err)
+	}
+	defer db.Close()
+
+	// Store a value and a block and flush them to disk so they are visible
+	// to read-only instances.
+	bucket1Key := []byte("bucket1")
+	key1, value1 := []byte("key1"), []byte("foo1")
+	key2, value2 := []byte("key2"), []byte("foo2")
+	mainNetParams := chaincfg.MainNetParams()
+	genesisBlock := dcrutil.NewBlock(mainNetParams.GenesisBlock)
+	genesisHash := &mainNetParams.GenesisHash
+	err = db.Update(func(tx database.Tx) error {
+		bucket1, err := tx.Metadata().CreateBucket(bucket1Key)
+		if err != nil {
+			return err
+		}
+		if err := bucket1.Put(key1, value1); err != nil {
+			return err
+		}
+		return tx.StoreBlock(genesisBlock)
+	})
+	if err != nil {
+		t.Fatalf("Update: unexpected error: %v", err)
+	}
+	if err := db.Flush(); err != nil {
+		t.Fatalf("Flush: unexpected error: %v", err)
+	}
+
+	// Open the database in read-only mode while it is still open and ensure
+	// the stored value and block are available.
+	roDB, err := database.Open(dbType, dbPath, blockDataNet,
+		ffldb.BlockCompressionNone, ffldb.AccessReadOnly)
+	if err != nil {
+		t.Fatalf("Failed to open database in read-only mode: %v", err)
+	}
+	defer roDB.Close()
+	err = roDB.View(func(tx database.Tx) error {
+		bucket1 := tx.Metadata().Bucket(bucket1Key)
+		if bucket1 == nil {
+			return fmt.Errorf("bucket1: unexpected nil bucket")
+		}
+		if gotVal := bucket1.Get(key1); !reflect.DeepEqual(gotVal, value1) {
+			return fmt.Errorf("Get: unexpected value - got %s, want %s",
+				gotVal, value1)
+		}
+		genesisBlockBytes, _ := genesisBlock.Bytes()
+		gotBytes, err := tx.FetchBlock(genesisHash)
+		if err != nil {
+			return fmt.Errorf("FetchBlock: unexpected error: %w", err)
+		}
+		if !reflect.DeepEqual(gotBytes, genesisBlockBytes) {
+			return fmt.Errorf("FetchBlock: stored block mismatch")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("View: unexpected error: %v", err)
+	}
+
+	// Ensure writable transactions are rejected.
+	wantErrKind := database.ErrTxNotWritable
+	err = roDB.Update(func(tx database.Tx) error {
+		return nil
+	})
+	if !checkDbError(t, "Update", err, wantErrKind) {
+		return
+	}
+	_, err = roDB.Begin(true)
+	if !checkDbError(t, "Begin(true)", err, wantErrKind) {
+		return
+	}
+
+	// Modify the database through the original instance and ensure the
+	// modifications are not visible to the read-only instance.
+	err = db.Update(func(tx database.Tx) error {
+		return tx.Metadata().Bucket(bucket1Key).Put(key2, value2)
+	})
+	if err != nil {
+		t.Fatalf("Update: unexpected error: %v", err)
+	}
+	if err := db.Flush(); err != nil {
+		t.Fatalf("Flush: unexpected error: %v", err)
+	}
+	err = roDB.View(func(tx database.Tx) error {
+		if gotVal := tx.Metadata().Bucket(bucket1Key).Get(key2); gotVal != nil {
+			return fmt.Errorf("Get: unexpected value %s for key added "+
+				"after open", gotVal)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("View: unexpected error: %v", err)
+	}
+}
+
 // TestInterface performs all interfaces tests for this database driver.
 func TestInterface(t *testing.T) {
 	t.Parallel()
@@ -83,9 +83,14 @@ func reconcileDB(pdb *db, create bool) (database.DB, error) {
 	// the middle of being written.  Since the metadata isn't updated until
 	// after the block data is written, this is effectively just a rollback
 	// to the known good point before the unclean shutdown.
+	//
+	// Note that the block files are never modified in read-only mode.  It is
+	// expected for them to contain additional data in that case since the
+	// metadata is a snapshot and the database may be in use by another
+	// process that continues to write blocks.
 	wc := pdb.store.writeCursor
-	if wc.curFileNum > curFileNum || (wc.curFileNum == curFileNum &&
-		wc.curOffset > curOffset) {
+	if !pdb.readOnly && (wc.curFileNum > curFileNum ||
+		(wc.curFileNum == curFileNum && wc.curOffset > curOffset)) {
 
 		log.Info("Detected unclean shutdown - Repairing...")
 		log.Debugf("Metadata claims file %d, offset %d. Block data is "+
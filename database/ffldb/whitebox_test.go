@@ -16,10 +16,12 @@ import (
 	"errors"
 	"fmt"
 	"hash/crc32"
+	"math/rand"
 	"os"
 	"path/filepath"
 	"testing"
 
+	"github.com/EXCCoin/exccd/chaincfg/chainhash"
 	"github.com/EXCCoin/exccd/database/v3"
 	"github.com/EXCCoin/exccd/dcrutil/v4"
 	"github.com/EXCCoin/exccd/wire"
@@ -150,7 +152,7 @@ func TestCornerCases(t *testing.T) {
 	// directory is needed.
 	testName := "openDB: fail due to file at target location"
 	wantErrKind := database.ErrDriverSpecific
-	idb, err := openDB(dbPath, blockDataNet, true)
+	idb, err := openDB(dbPath, blockDataNet, BlockCompressionNone, AccessReadWrite, true)
 	if !checkDbError(t, testName, err, wantErrKind) {
 		if err == nil {
 			idb.Close()
@@ -162,7 +164,7 @@ func TestCornerCases(t *testing.T) {
 	// Remove the file and create the database to run tests against.  It
 	// should be successful this time.
 	_ = os.RemoveAll(dbPath)
-	idb, err = openDB(dbPath, blockDataNet, true)
+	idb, err = openDB(dbPath, blockDataNet, BlockCompressionNone, AccessReadWrite, true)
 	if err != nil {
 		t.Errorf("openDB: unexpected error: %v", err)
 		return
@@ -423,7 +425,7 @@ func testBlockFileErrors(tc *testContext) bool {
 		return false
 	}
 	testName = "readBlockRegion invalid file number"
-	_, err = store.readBlockRegion(invalidLoc, 0, 80)
+	_, err = store.readBlockRegion(block0Hash, invalidLoc, 0, 80)
 	if !checkDbError(tc.t, testName, err, database.ErrDriverSpecific) {
 		return false
 	}
@@ -674,3 +676,132 @@ func TestFailureScenarios(t *testing.T) {
 	// Test various corruption scenarios.
 	testCorruption(tc)
 }
+
+// TestCompressedBlocks ensures blocks written with compression enabled are
+// flagged accordingly, can be read back in full and by region, and remain
+// readable alongside uncompressed blocks regardless of the compression
+// configured for the store reading them.
+func TestCompressedBlocks(t *testing.T) {
+	t.Parallel()
+
+	// Create a new database with block compression enabled to run tests
+	// against.
+	dbPath := filepath.Join(os.TempDir(), "ffldb-compressedblocks")
+	_ = os.RemoveAll(dbPath)
+	idb, err := openDB(dbPath, blockDataNet, BlockCompressionDeflate, AccessReadWrite, true)
+	if err != nil {
+		t.Fatalf("openDB: unexpected error: %v", err)
+	}
+	defer os.RemoveAll(dbPath)
+	defer idb.Close()
+	store := idb.(*db).store
+
+	// Create a highly compressible block along with one that can't be
+	// compressed at all.
+	compressible := bytes.Repeat([]byte{0x01, 0x02, 0x03, 0x04}, 1024)
+	incompressible := make([]byte, 4096)
+	rng := rand.New(rand.NewSource(1))
+	if _, err := rng.Read(incompressible); err != nil {
+		t.Fatalf("failed to generate random block data: %v", err)
+	}
+
+	tests := []struct {
+		name           string
+		rawBlock       []byte
+		wantCompressed bool
+	}{{
+		name:           "compressible block",
+		rawBlock:       compressible,
+		wantCompressed: true,
+	}, {
+		name:           "incompressible block",
+		rawBlock:       incompressible,
+		wantCompressed: false,
+	}}
+
+	hash := &chainhash.Hash{}
+	locs := make([]blockLocation, 0, len(tests))
+	for _, test := range tests {
+		loc, err := store.writeBlock(test.rawBlock)
+		if err != nil {
+			t.Fatalf("%s: writeBlock: unexpected error: %v", test.name,
+				err)
+		}
+		if loc.compressed != test.wantCompressed {
+			t.Fatalf("%s: unexpected compressed flag - got %v, want %v",
+				test.name, loc.compressed, test.wantCompressed)
+		}
+		if test.wantCompressed && loc.blockLen >= uint32(len(test.rawBlock)) {
+			t.Fatalf("%s: block was not compressed - record length %d, "+
+				"block length %d", test.name, loc.blockLen,
+				len(test.rawBlock))
+		}
+
+		// Ensure the location survives a serialization round trip.
+		gotLoc := deserializeBlockLoc(serializeBlockLoc(loc))
+		if gotLoc != loc {
+			t.Fatalf("%s: mismatched location - got %+v, want %+v",
+				test.name, gotLoc, loc)
+		}
+		locs = append(locs, loc)
+	}
+
+	// Ensure the blocks and regions of them can be read back by the store
+	// that wrote them as well as by a store that does not compress blocks.
+	uncompressedStore := newBlockStore(dbPath, blockDataNet,
+		BlockCompressionNone)
+	for _, s := range []*blockStore{store, uncompressedStore} {
+		for i, test := range tests {
+			gotBlock, err := s.readBlock(hash, locs[i])
+			if err != nil {
+				t.Fatalf("%s: readBlock: unexpected error: %v",
+					test.name, err)
+			}
+			if !bytes.Equal(gotBlock, test.rawBlock) {
+				t.Fatalf("%s: mismatched block data", test.name)
+			}
+
+			gotRegion, err := s.readBlockRegion(hash, locs[i], 100, 200)
+			if err != nil {
+				t.Fatalf("%s: readBlockRegion: unexpected error: %v",
+					test.name, err)
+			}
+			if !bytes.Equal(gotRegion, test.rawBlock[100:300]) {
+				t.Fatalf("%s: mismatched block region data",
+					test.name)
+			}
+		}
+	}
+
+	// Ensure a region that exceeds the bounds of a compressed block is
+	// rejected.
+	testName := "readBlockRegion: out of bounds compressed block region"
+	_, err = store.readBlockRegion(hash, locs[0], uint32(len(compressible)-1),
+		2)
+	if !checkDbError(t, testName, err, database.ErrBlockRegionInvalid) {
+		return
+	}
+
+	// Ensure malformed compressed data is detected.
+	testName = "decompressBlock: truncated header"
+	_, err = decompressBlock(hash, []byte{byte(BlockCompressionDeflate)})
+	if !checkDbError(t, testName, err, database.ErrCorruption) {
+		return
+	}
+	testName = "decompressBlock: unsupported compression"
+	_, err = decompressBlock(hash, []byte{0xff, 0x00, 0x00, 0x00, 0x00})
+	if !checkDbError(t, testName, err, database.ErrCorruption) {
+		return
+	}
+	testName = "decompressBlock: length mismatch"
+	payload, _, err := store.compressBlock(compressible)
+	if err != nil {
+		t.Fatalf("compressBlock: unexpected error: %v", err)
+	}
+	payload = append([]byte(nil), payload...)
+	byteOrder.PutUint32(payload[1:compressedHdrSize], 10)
+	_, err = decompressBlock(hash, payload)
+	if !checkDbError(t, testName, err, database.ErrCorruption) {
+		return
+	}
+}
@@ -0,0 +1,20 @@
+// Copyright (c) 2023 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+/*
+Package ldbsnapshot provides read-only access to a point-in-time snapshot of a
+leveldb database that may be open by another process.
+
+Leveldb takes an exclusive lock on its database directory, so a database that is
+in use by a running process can't be opened by analysis tools.  This package
+provides a storage implementation that opens the files that make up the
+database without taking the lock and only exposes the data they contained at
+the time the snapshot was taken.  Since leveldb only appends to its manifest and
+journal files and never modifies its table files, the snapshot remains
+consistent even while the other process continues to modify the database.
+
+Modifications made after the snapshot was taken are not visible through it, and
+all attempts to write to the database opened from the snapshot fail.
+*/
+package ldbsnapshot
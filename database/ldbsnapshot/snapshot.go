@@ -0,0 +1,462 @@
+// Copyright (c) 2023 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package ldbsnapshot
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/syndtr/goleveldb/leveldb"
+	"github.com/syndtr/goleveldb/leveldb/opt"
+	"github.com/syndtr/goleveldb/leveldb/storage"
+)
+
+const (
+	// maxSnapshotAttempts is the maximum number of times to attempt taking a
+	// consistent snapshot of a database that is concurrently being modified
+	// before giving up.
+	maxSnapshotAttempts = 20
+
+	// snapshotRetryDelay is the amount of time to wait before retrying to
+	// take a snapshot after the database was modified while taking it.
+	snapshotRetryDelay = 50 * time.Millisecond
+
+	// currentFileName is the name of the file leveldb uses to identify the
+	// active manifest.
+	currentFileName = "CURRENT"
+)
+
+var (
+	// ErrReadOnly is returned when attempting to modify a snapshot.
+	ErrReadOnly = errors.New("ldbsnapshot: snapshot is read-only")
+
+	// errSnapshotRace is returned when the database was modified while a
+	// snapshot was being taken and hence the snapshot might not be
+	// consistent.
+	errSnapshotRace = errors.New("ldbsnapshot: database modified while " +
+		"taking snapshot")
+)
+
+// snapshotFile houses an open database file along with its size at the time
+// the snapshot was taken.
+type snapshotFile struct {
+	file *os.File
+	size int64
+}
+
+// snapshotReader provides read access to the portion of a file that is part
+// of a snapshot.  It implements the storage.Reader interface.
+type snapshotReader struct {
+	*io.SectionReader
+}
+
+// Close does nothing since the underlying file is owned by the storage.
+//
+// This is part of the storage.Reader interface.
+func (r snapshotReader) Close() error {
+	return nil
+}
+
+// nopLocker implements the storage.Locker interface without doing anything
+// since a snapshot does not need to be locked.
+type nopLocker struct{}
+
+// Unlock does nothing.
+//
+// This is part of the storage.Locker interface.
+func (nopLocker) Unlock() {}
+
+// snapshotStorage implements the storage.Storage interface for a read-only
+// point-in-time view of a leveldb database directory that may be concurrently
+// modified by another process.
+//
+// The view is made consistent by opening all of the files that make up the
+// database at the time the snapshot is taken and limiting reads to the size
+// the files had at that time.  Leveldb only ever appends to the manifest and
+// journal files and never modifies table files after creating them, so the
+// open files continue to provide the data as of the snapshot even when the
+// other process later removes them.
+type snapshotStorage struct {
+	mtx    sync.Mutex
+	closed bool
+	meta   storage.FileDesc
+	files  map[storage.FileDesc]*snapshotFile
+}
+
+// Ensure snapshotStorage implements the storage.Storage interface.
+var _ storage.Storage = (*snapshotStorage)(nil)
+
+// parseFileName returns the leveldb file descriptor for the provided file name
+// and whether or not the name identifies a manifest, journal, or table file.
+func parseFileName(name string) (storage.FileDesc, bool) {
+	var fd storage.FileDesc
+	var tail string
+	if _, err := fmt.Sscanf(name, "%d.%s", &fd.Num, &tail); err == nil {
+		switch tail {
+		case "log":
+			fd.Type = storage.TypeJournal
+		case "ldb", "sst":
+			fd.Type = storage.TypeTable
+		default:
+			return fd, false
+		}
+		return fd, true
+	}
+	if n, _ := fmt.Sscanf(name, "MANIFEST-%d%s", &fd.Num, &tail); n == 1 {
+		fd.Type = storage.TypeManifest
+		return fd, true
+	}
+	return fd, false
+}
+
+// readCurrent returns the file descriptor of the active manifest of the
+// database at the provided path.
+func readCurrent(path string) (storage.FileDesc, error) {
+	b, err := ioutil.ReadFile(filepath.Join(path, currentFileName))
+	if err != nil {
+		return storage.FileDesc{}, err
+	}
+	name := strings.TrimSuffix(string(b), "\n")
+	fd, ok := parseFileName(name)
+	if !ok || fd.Type != storage.TypeManifest {
+		return storage.FileDesc{}, fmt.Errorf("ldbsnapshot: invalid "+
+			"manifest name %q in %s", name, currentFileName)
+	}
+	return fd, nil
+}
+
+// listFiles returns the names of the files in the directory at the provided
+// path mapped to their leveldb file descriptors.
+func listFiles(path string) (map[storage.FileDesc]string, error) {
+	dir, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	names, err := dir.Readdirnames(0)
+	dir.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	files := make(map[storage.FileDesc]string, len(names))
+	for _, name := range names {
+		if fd, ok := parseFileName(name); ok {
+			files[fd] = name
+		}
+	}
+	return files, nil
+}
+
+// fileSize returns the current size of the file at the provided path.
+func fileSize(path string) (int64, error) {
+	fi, err := os.Stat(path)
+	if err != nil {
+		return 0, err
+	}
+	return fi.Size(), nil
+}
+
+// takeSnapshot attempts to take a consistent snapshot of the leveldb database
+// at the provided path.  It returns errSnapshotRace when the database was
+// modified in a way that prevents the snapshot from being consistent.
+func takeSnapshot(path string) (*snapshotStorage, error) {
+	// Open the active manifest first.  Any files it references either exist
+	// now or are created later in which case they are detected below.
+	meta, err := readCurrent(path)
+	if err != nil {
+		return nil, err
+	}
+	s := &snapshotStorage{
+		meta:  meta,
+		files: make(map[storage.FileDesc]*snapshotFile),
+	}
+	addFile := func(fd storage.FileDesc, name string) error {
+		f, err := os.Open(filepath.Join(path, name))
+		if err != nil {
+			return err
+		}
+		fi, err := f.Stat()
+		if err != nil {
+			f.Close()
+			return err
+		}
+		s.files[fd] = &snapshotFile{file: f, size: fi.Size()}
+		return nil
+	}
+	manifestName := fmt.Sprintf("MANIFEST-%06d", meta.Num)
+	if err := addFile(meta, manifestName); err != nil {
+		if os.IsNotExist(err) {
+			return nil, errSnapshotRace
+		}
+		return nil, err
+	}
+
+	// Open all of the journal and table files.  Files that are removed
+	// between listing and opening them are no longer part of the database.
+	names, err := listFiles(path)
+	if err != nil {
+		s.Close()
+		return nil, err
+	}
+	var lastJournal storage.FileDesc
+	for fd, name := range names {
+		if fd.Type == storage.TypeManifest {
+			continue
+		}
+		if err := addFile(fd, name); err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			s.Close()
+			return nil, err
+		}
+		if fd.Type == storage.TypeJournal && fd.Num > lastJournal.Num {
+			lastJournal = fd
+		}
+	}
+
+	// The snapshot is only consistent when the database was not modified
+	// while it was being taken other than appending to the most recent
+	// journal.  In other words, the active manifest must be unchanged, no
+	// new files may have been created, and none of the older journals may
+	// have grown.
+	isConsistent := func() (bool, error) {
+		curMeta, err := readCurrent(path)
+		if err != nil {
+			return false, err
+		}
+		if curMeta != meta {
+			return false, nil
+		}
+		curNames, err := listFiles(path)
+		if err != nil {
+			return false, err
+		}
+		for fd, name := range curNames {
+			file, ok := s.files[fd]
+			switch {
+			case fd.Type == storage.TypeManifest && fd != meta:
+				// Manifests other than the active one are not
+				// part of the snapshot.
+				continue
+			case !ok:
+				return false, nil
+			case fd.Type == storage.TypeTable || fd == lastJournal:
+				continue
+			}
+
+			size, err := fileSize(filepath.Join(path, name))
+			if err != nil {
+				if os.IsNotExist(err) {
+					continue
+				}
+				return false, err
+			}
+			if size != file.size {
+				return false, nil
+			}
+		}
+		return true, nil
+	}
+	ok, err := isConsistent()
+	if err != nil || !ok {
+		s.Close()
+		if err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+		return nil, errSnapshotRace
+	}
+
+	return s, nil
+}
+
+// Lock returns a locker that does nothing since the snapshot is read-only and
+// therefore does not need to prevent concurrent access.
+//
+// This is part of the storage.Storage interface.
+func (s *snapshotStorage) Lock() (storage.Locker, error) {
+	return nopLocker{}, nil
+}
+
+// Log does nothing since the snapshot is read-only.
+//
+// This is part of the storage.Storage interface.
+func (s *snapshotStorage) Log(str string) {}
+
+// SetMeta always returns ErrReadOnly since the snapshot is read-only.
+//
+// This is part of the storage.Storage interface.
+func (s *snapshotStorage) SetMeta(fd storage.FileDesc) error {
+	return ErrReadOnly
+}
+
+// GetMeta returns the file descriptor of the manifest that was active when the
+// snapshot was taken.
+//
+// This is part of the storage.Storage interface.
+func (s *snapshotStorage) GetMeta() (storage.FileDesc, error) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	if s.closed {
+		return storage.FileDesc{}, storage.ErrClosed
+	}
+	return s.meta, nil
+}
+
+// List returns the file descriptors of the files in the snapshot that match
+// the given file types.
+//
+// This is part of the storage.Storage interface.
+func (s *snapshotStorage) List(ft storage.FileType) ([]storage.FileDesc, error) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	if s.closed {
+		return nil, storage.ErrClosed
+	}
+	var fds []storage.FileDesc
+	for fd := range s.files {
+		if fd.Type&ft != 0 {
+			fds = append(fds, fd)
+		}
+	}
+	return fds, nil
+}
+
+// Open returns a reader for the contents of the provided file as of the time
+// the snapshot was taken.
+//
+// This is part of the storage.Storage interface.
+func (s *snapshotStorage) Open(fd storage.FileDesc) (storage.Reader, error) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	if s.closed {
+		return nil, storage.ErrClosed
+	}
+	file, ok := s.files[fd]
+	if !ok {
+		return nil, os.ErrNotExist
+	}
+	return snapshotReader{io.NewSectionReader(file.file, 0, file.size)}, nil
+}
+
+// Create always returns ErrReadOnly since the snapshot is read-only.
+//
+// This is part of the storage.Storage interface.
+func (s *snapshotStorage) Create(fd storage.FileDesc) (storage.Writer, error) {
+	return nil, ErrReadOnly
+}
+
+// Remove always returns ErrReadOnly since the snapshot is read-only.
+//
+// This is part of the storage.Storage interface.
+func (s *snapshotStorage) Remove(fd storage.FileDesc) error {
+	return ErrReadOnly
+}
+
+// Rename always returns ErrReadOnly since the snapshot is read-only.
+//
+// This is part of the storage.Storage interface.
+func (s *snapshotStorage) Rename(oldfd, newfd storage.FileDesc) error {
+	return ErrReadOnly
+}
+
+// Close closes all of the files that are part of the snapshot.  It is safe to
+// call Close multiple times.
+//
+// This is part of the storage.Storage interface.
+func (s *snapshotStorage) Close() error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	if s.closed {
+		return nil
+	}
+	s.closed = true
+	for _, file := range s.files {
+		file.file.Close()
+	}
+	s.files = nil
+	return nil
+}
+
+// DB is a read-only point-in-time snapshot of a leveldb database.  It embeds
+// the leveldb database opened from the snapshot and additionally owns the
+// snapshot storage, which leveldb does not close when the database is opened
+// from a caller-provided storage.
+type DB struct {
+	*leveldb.DB
+	stor storage.Storage
+}
+
+// Close closes the database opened from the snapshot followed by the snapshot
+// storage which releases the files it holds open.
+func (db *DB) Close() error {
+	err := db.DB.Close()
+	if serr := db.stor.Close(); err == nil {
+		err = serr
+	}
+	return err
+}
+
+// OpenFile opens a read-only point-in-time snapshot of the leveldb database at
+// the provided path.  Unlike opening the database directly, it does not take
+// the lock on the database, so it may be used while another process has the
+// database open and is modifying it.  Those modifications are not visible
+// through the returned database.
+//
+// The provided options are modified to open the database in read-only mode.
+// They also tolerate a partially written record at the end of the manifest and
+// journals since the snapshot might be taken while the other process is
+// writing them.  It returns os.ErrNotExist when the database does not exist.
+//
+// The returned database must be closed by the caller to release the files held
+// open by the snapshot.
+func OpenFile(path string, o *opt.Options) (*DB, error) {
+	var opts opt.Options
+	if o != nil {
+		opts = *o
+	}
+	opts.ReadOnly = true
+	opts.ErrorIfMissing = true
+	opts.Strict &^= opt.StrictManifest | opt.StrictJournal |
+		opt.StrictJournalChecksum
+
+	var lastErr error
+	for attempt := 0; attempt < maxSnapshotAttempts; attempt++ {
+		if attempt > 0 {
+			time.Sleep(snapshotRetryDelay)
+		}
+
+		stor, err := takeSnapshot(path)
+		if err != nil {
+			if errors.Is(err, errSnapshotRace) {
+				lastErr = err
+				continue
+			}
+			return nil, err
+		}
+
+		// Opening the database might still fail if the other process
+		// happened to remove a file referenced by the snapshot before it
+		// was opened, so retry in that case as well.
+		db, err := leveldb.Open(stor, &opts)
+		if err != nil {
+			stor.Close()
+			lastErr = err
+			continue
+		}
+		return &DB{DB: db, stor: stor}, nil
+	}
+
+	return nil, fmt.Errorf("ldbsnapshot: unable to take a consistent "+
+		"snapshot of %s after %d attempts: %v", path, maxSnapshotAttempts,
+		lastErr)
+}
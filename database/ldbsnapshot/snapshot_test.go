@@ -0,0 +1,198 @@
+// Copyright (c) 2023 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package ldbsnapshot
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/syndtr/goleveldb/leveldb"
+	"github.com/syndtr/goleveldb/leveldb/opt"
+	"github.com/syndtr/goleveldb/leveldb/util"
+)
+
+// TestOpenFile ensures a snapshot of a database that is open and being
+// modified by another handle can be opened, that it provides the data as of
+// the time it was taken, and that it can't be modified.
+func TestOpenFile(t *testing.T) {
+	t.Parallel()
+
+	dbPath := filepath.Join(t.TempDir(), "db")
+	opts := opt.Options{WriteBuffer: 4096}
+	db, err := leveldb.OpenFile(dbPath, &opts)
+	if err != nil {
+		t.Fatalf("failed to create database: %v", err)
+	}
+	defer db.Close()
+
+	// Write enough entries to ensure some of them are flushed to tables while
+	// others remain in the journal.
+	const numEntries = 2000
+	key := func(i int) []byte { return []byte(fmt.Sprintf("key%05d", i)) }
+	value := bytes.Repeat([]byte{0x01}, 32)
+	for i := 0; i < numEntries; i++ {
+		if err := db.Put(key(i), value, nil); err != nil {
+			t.Fatalf("failed to put entry %d: %v", i, err)
+		}
+	}
+
+	// Ensure opening the database directly fails while it is locked, but
+	// opening a snapshot of it succeeds.
+	if _, err := leveldb.OpenFile(dbPath, &opt.Options{ReadOnly: true}); err == nil {
+		t.Fatal("opening locked database directly did not fail")
+	}
+	snap, err := OpenFile(dbPath, nil)
+	if err != nil {
+		t.Fatalf("failed to open snapshot: %v", err)
+	}
+	defer snap.Close()
+
+	// Modify the database after the snapshot was taken and ensure the
+	// modifications are not visible through the snapshot.
+	if err := db.Delete(key(0), nil); err != nil {
+		t.Fatalf("failed to delete entry: %v", err)
+	}
+	if err := db.Put(key(numEntries), value, nil); err != nil {
+		t.Fatalf("failed to put entry: %v", err)
+	}
+	if err := db.CompactRange(util.Range{}); err != nil {
+		t.Fatalf("failed to compact database: %v", err)
+	}
+	for i := 0; i < numEntries; i++ {
+		got, err := snap.Get(key(i), nil)
+		if err != nil {
+			t.Fatalf("failed to get entry %d from snapshot: %v", i, err)
+		}
+		if !bytes.Equal(got, value) {
+			t.Fatalf("unexpected value for entry %d: got %x, want %x", i,
+				got, value)
+		}
+	}
+	_, err = snap.Get(key(numEntries), nil)
+	if !errors.Is(err, leveldb.ErrNotFound) {
+		t.Fatalf("unexpected error for entry added after snapshot: got %v, "+
+			"want %v", err, leveldb.ErrNotFound)
+	}
+
+	// Ensure the snapshot can't be modified.
+	if err := snap.Put(key(0), value, nil); err == nil {
+		t.Fatal("modifying snapshot did not fail")
+	}
+}
+
+// numOpenFiles returns the number of file descriptors currently open by the
+// process.  The test is skipped when the platform does not provide a way to
+// count them.
+func numOpenFiles(t *testing.T) int {
+	t.Helper()
+
+	entries, err := os.ReadDir("/proc/self/fd")
+	if err != nil {
+		t.Skipf("unable to count open file descriptors: %v", err)
+	}
+	return len(entries)
+}
+
+// TestOpenFileReleasesFiles ensures closing a snapshot releases all of the
+// files it holds open so repeatedly opening and closing snapshots does not leak
+// file descriptors.
+func TestOpenFileReleasesFiles(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "db")
+	db, err := leveldb.OpenFile(dbPath, &opt.Options{WriteBuffer: 4096})
+	if err != nil {
+		t.Fatalf("failed to create database: %v", err)
+	}
+	defer db.Close()
+
+	// Write enough entries to ensure the snapshot consists of tables in
+	// addition to the manifest and journal.
+	value := bytes.Repeat([]byte{0x01}, 32)
+	for i := 0; i < 2000; i++ {
+		key := []byte(fmt.Sprintf("key%05d", i))
+		if err := db.Put(key, value, nil); err != nil {
+			t.Fatalf("failed to put entry %d: %v", i, err)
+		}
+	}
+
+	// Open and close a snapshot once before taking the baseline count so
+	// that any descriptors that are opened lazily by the runtime are already
+	// accounted for.
+	openAndClose := func() {
+		snap, err := OpenFile(dbPath, nil)
+		if err != nil {
+			t.Fatalf("failed to open snapshot: %v", err)
+		}
+		if _, err := snap.Get([]byte("key00000"), nil); err != nil {
+			t.Fatalf("failed to get entry from snapshot: %v", err)
+		}
+		if err := snap.Close(); err != nil {
+			t.Fatalf("failed to close snapshot: %v", err)
+		}
+	}
+	openAndClose()
+	before := numOpenFiles(t)
+
+	const numOpens = 50
+	for i := 0; i < numOpens; i++ {
+		openAndClose()
+	}
+	if after := numOpenFiles(t); after > before {
+		t.Fatalf("open file descriptors grew from %d to %d after opening "+
+			"and closing %d snapshots", before, after, numOpens)
+	}
+}
+
+// TestOpenFileNotExist ensures opening a snapshot of a database that does not
+// exist returns an error that indicates so.
+func TestOpenFileNotExist(t *testing.T) {
+	t.Parallel()
+
+	dbPath := filepath.Join(t.TempDir(), "db")
+	_, err := OpenFile(dbPath, nil)
+	if !os.IsNotExist(err) {
+		t.Fatalf("unexpected error: got %v, want not exist error", err)
+	}
+}
+
+// TestParseFileName ensures leveldb file names are parsed as expected.
+func TestParseFileName(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		ok   bool
+		typ  string
+		num  int64
+	}{
+		{name: "000005.log", ok: true, typ: "journal", num: 5},
+		{name: "000012.ldb", ok: true, typ: "table", num: 12},
+		{name: "000012.sst", ok: true, typ: "table", num: 12},
+		{name: "MANIFEST-000002", ok: true, typ: "manifest", num: 2},
+		{name: "000003.tmp", ok: false},
+		{name: "CURRENT", ok: false},
+		{name: "LOCK", ok: false},
+		{name: "LOG", ok: false},
+	}
+
+	for _, test := range tests {
+		fd, ok := parseFileName(test.name)
+		if ok != test.ok {
+			t.Errorf("%q: unexpected ok -- got %v, want %v", test.name, ok,
+				test.ok)
+			continue
+		}
+		if !ok {
+			continue
+		}
+		if fd.Type.String() != test.typ || fd.Num != test.num {
+			t.Errorf("%q: unexpected file desc -- got %v %d, want %v %d",
+				test.name, fd.Type, fd.Num, test.typ, test.num)
+		}
+	}
+}
@@ -131,6 +131,15 @@ func dcrdMain() error {
 		return nil
 	}
 
+	// Ensure the databases are found where they are expected when they have
+	// been configured to reside in separate directories.
+	if cfg.DbType != "memdb" {
+		if err := checkDataLayout(); err != nil {
+			dcrdLog.Errorf("%v", err)
+			return err
+		}
+	}
+
 	// Load the block database.
 	lifetimeNotifier.notifyStartupEvent(lifetimeEventDBOpen)
 	db, err := loadBlockDB(cfg.params.Params)
@@ -151,7 +160,7 @@ func dcrdMain() error {
 	}
 
 	// Load the UTXO database.
-	utxoDb, err := blockchain.LoadUtxoDB(ctx, cfg.params.Params, cfg.DataDir)
+	utxoDb, err := blockchain.LoadUtxoDB(ctx, cfg.params.Params, cfg.UtxoDir)
 	if err != nil {
 		dcrdLog.Errorf("%v", err)
 		return err
@@ -167,12 +176,32 @@ func dcrdMain() error {
 		return nil
 	}
 
+	// Load the database that houses the optional indexes.  This is the block
+	// database unless a separate index directory is configured.
+	indexDb, err := loadIndexDB(cfg.params.Params, db)
+	if err != nil {
+		dcrdLog.Errorf("%v", err)
+		return err
+	}
+	if indexDb != db {
+		defer func() {
+			// Ensure the database is sync'd and closed on shutdown.
+			dcrdLog.Infof("Gracefully shutting down the index database...")
+			indexDb.Close()
+		}()
+	}
+
+	// Return now if a shutdown signal was triggered.
+	if shutdownRequested(ctx) {
+		return nil
+	}
+
 	// Drop indexes and exit if requested.
 	//
 	// NOTE: The order is important here because dropping the tx index also
 	// drops the address index since it relies on it.
 	if cfg.DropAddrIndex {
-		if err := indexers.DropAddrIndex(ctx, db); err != nil {
+		if err := indexers.DropAddrIndex(ctx, indexDb); err != nil {
 			dcrdLog.Errorf("%v", err)
 			return err
 		}
@@ -180,7 +209,7 @@ func dcrdMain() error {
 		return nil
 	}
 	if cfg.DropTxIndex {
-		if err := indexers.DropTxIndex(ctx, db); err != nil {
+		if err := indexers.DropTxIndex(ctx, indexDb); err != nil {
 			dcrdLog.Errorf("%v", err)
 			return err
 		}
@@ -188,7 +217,63 @@ func dcrdMain() error {
 		return nil
 	}
 	if cfg.DropExistsAddrIndex {
-		if err := indexers.DropExistsAddrIndex(ctx, db); err != nil {
+		if err := indexers.DropExistsAddrIndex(ctx, indexDb); err != nil {
+			dcrdLog.Errorf("%v", err)
+			return err
+		}
+
+		return nil
+	}
+	if cfg.DropSpenderIndex {
+		if err := indexers.DropSpenderIndex(ctx, indexDb); err != nil {
+			dcrdLog.Errorf("%v", err)
+			return err
+		}
+
+		return nil
+	}
+	if cfg.DropAddrBalanceIndex {
+		if err := indexers.DropAddrBalanceIndex(ctx, indexDb); err != nil {
+			dcrdLog.Errorf("%v", err)
+			return err
+		}
+
+		return nil
+	}
+	if cfg.DropBlockStatsIndex {
+		if err := indexers.DropBlockStatsIndex(ctx, indexDb); err != nil {
+			dcrdLog.Errorf("%v", err)
+			return err
+		}
+
+		return nil
+	}
+	if cfg.DropTicketIndex {
+		if err := indexers.DropTicketIndex(ctx, indexDb); err != nil {
+			dcrdLog.Errorf("%v", err)
+			return err
+		}
+
+		return nil
+	}
+	if cfg.DropCoinStatsIndex {
+		if err := indexers.DropCoinStatsIndex(ctx, indexDb); err != nil {
+			dcrdLog.Errorf("%v", err)
+			return err
+		}
+
+		return nil
+	}
+	if cfg.DropCFHeaderIndex {
+		if err := indexers.DropCFHeaderIndex(ctx, indexDb); err != nil {
+			dcrdLog.Errorf("%v", err)
+			return err
+		}
+
+		return nil
+	}
+	if cfg.DropScriptIndex {
+		if err := indexers.DropScriptIndex(ctx, indexDb); err != nil {
 			dcrdLog.Errorf("%v", err)
 			return err
 		}
@@ -204,8 +289,8 @@ func dcrdMain() error {
 
 	// Create server.
 	lifetimeNotifier.notifyStartupEvent(lifetimeEventP2PServer)
-	svr, err := newServer(ctx, cfg.Listeners, db, utxoDb, cfg.params.Params,
-		cfg.DataDir)
+	svr, err := newServer(ctx, cfg.Listeners, db, utxoDb, indexDb,
+		cfg.params.Params, cfg.DataDir)
 	if err != nil {
 		dcrdLog.Errorf("Unable to start server: %v", err)
 		return err
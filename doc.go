@@ -28,10 +28,25 @@ Application Options:
 	-A, --appdata=               Path to application home directory
 	-C, --configfile=            Path to configuration file
 	-b, --datadir=               Directory to store data
+	    --blocksdir=             Directory to store the block database
+	                             (default: datadir)
+	    --utxodir=               Directory to store the UTXO database
+	                             (default: datadir)
+	    --indexdir=              Directory to store the optional indexes in a
+	                             database separate from the block database
+	                             (default: store them in the block database)
 	    --logdir=                Directory to log output
 	    --nofilelogging=         Disable file logging
 	    --dbtype=                Database backend to use for the block chain
 	                             (default: ffldb)
+	    --compressblocks         Compress blocks written to the block database
+	                             to reduce disk usage -- NOTE: Existing blocks
+	                             are not affected -- Use the dbtool
+	                             compressblocks command to compress them
+	    --reindexchainstate      Rebuild the UTXO set, spend journal, and ticket
+	                             database from the blocks already stored in the
+	                             block database on start up -- NOTE: This can
+	                             take a long time
 	    --profile=               Enable HTTP profiling on given [addr:]port --
 	                             NOTE: port must be between 1024 and 65536
 	    --cpuprofile=            Write CPU profile to the specified file
@@ -49,6 +64,9 @@ Application Options:
 	                             verification cache (default: 100000)
 	    --utxocachemaxsize=      The maximum size in MiB of the utxo cache
 	                             (default: 150, minimum: 25, maximum: 32768)
+	    --utxocachememlimit=     Flush the utxo cache when the memory in use by
+	                             the process reaches the specified number of MiB
+	                             -- 0 disables
 	    --norpc                  Disable built-in RPC server -- NOTE: The RPC
 	                             server is disabled by default if no
 	                             rpcuser/rpcpass or rpclimituser/rpclimitpass is
@@ -85,6 +103,10 @@ Application Options:
 	    --noonion                Disable connecting to tor hidden services
 	    --torisolation           Enable Tor stream isolation by randomizing user
 	                             credentials for each connection
+	    --torcontrol=            Tor control port used to automatically create a
+	                             hidden service for the P2P listener (eg.
+	                             127.0.0.1:9051)
+	    --torcontrolpass=        Password for the Tor control port
 	-a, --addpeer=               Add a peer to connect with at startup
 	    --connect=               Connect only to the specified peers at startup
 	    --nolisten               Disable listening for incoming connections --
@@ -104,6 +126,17 @@ Application Options:
 	    --peeridletimeout        The duration of inactivity before a peer is timed
 	                             out. Valid time units are {s,m,h}. Minimum 15
 	                             seconds (default: 2m0s)
+	    --nov2transport          Disable the encrypted and authenticated v2 P2P
+	                             transport
+	    --maxuploadtarget=       Try to keep the data sent to peers under the
+	                             target in MiB per 24 hours by no longer serving
+	                             historical blocks to peers that are not
+	                             whitelisted once it is reached -- 0 to disable
+	                             (default: 0)
+	    --asmap=                 Path to a file that maps IP addresses to the
+	                             autonomous system that announces them in order
+	                             to diversify peers by AS rather than network
+	                             prefix
 	    --noseeders              Disable seeding for peer discovery
 	    --nodnsseed              DEPRECATED: use --noseeders
 	    --externalip=            Add an ip to the list of local addresses we claim
@@ -183,6 +216,40 @@ Application Options:
 	                             whether or not an address has even been used
 	    --dropexistsaddrindex    Deletes the exists address index from the
 	                             database on start up and then exits
+	    --spenderindex           Maintain a full spent output index which makes
+	                             the getspendingtx RPC available
+	    --dropspenderindex       Deletes the spent output index from the database
+	                             on start up and then exits
+	    --addrbalanceindex       Maintain a full address balance and unspent
+	                             output index which makes the getaddressbalance,
+	                             getaddressutxos, and getaddressdeltas RPCs
+	                             available
+	    --dropaddrbalanceindex   Deletes the address balance index from the
+	                             database on start up and then exits
+	    --blockstatsindex        Maintain a full block statistics index which
+	                             makes the getblockstats RPC available
+	    --dropblockstatsindex    Deletes the block statistics index from the
+	                             database on start up and then exits
+	    --ticketindex            Maintain a full ticket lifecycle index which
+	                             makes the getticketinfo and getaddresstickets
+	                             RPCs available
+	    --dropticketindex        Deletes the ticket index from the database on
+	                             start up and then exits
+	    --coinstatsindex         Maintain a full coin statistics index which
+	                             makes the gettxoutsetinfo RPC respond instantly
+	                             and for any block height
+	    --dropcoinstatsindex     Deletes the coin statistics index from the
+	                             database on start up and then exits
+	    --cfheaderindex          Maintain a full filter header index which makes
+	                             the getcfilterheaders RPC available
+	    --dropcfheaderindex      Deletes the filter header index from the
+	                             database on start up and then exits
+	    --scriptindex            Maintain a full index of the outputs with
+	                             scripts that do not decode to addresses,
+	                             including null data outputs, which makes the
+	                             searchscripts RPC available
+	    --dropscriptindex        Deletes the script index from the database on
+	                             start up and then exits
 	    --piperx=                File descriptor of read end pipe to enable parent
 	                             -> child process communication
 	    --pipetx=                File descriptor of write end pipe to enable
@@ -32,6 +32,7 @@ require (
 	github.com/EXCCoin/exccd/txscript/v4 v4.0.0-20231114084634-503e41f75524
 	github.com/EXCCoin/exccd/wire v0.0.0-20231114084634-503e41f75524
 	github.com/davecgh/go-spew v1.1.1
+	github.com/dchest/siphash v1.2.3
 	github.com/decred/go-socks v1.1.0
 	github.com/decred/slog v1.2.0
 	github.com/gorilla/websocket v1.5.1
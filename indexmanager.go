@@ -0,0 +1,470 @@
+// Copyright (c) 2023 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/EXCCoin/exccd/blockchain/v4/indexers"
+	"github.com/EXCCoin/exccd/database/v3"
+	"github.com/EXCCoin/exccd/internal/rpcserver"
+)
+
+// The names of the optional indexes that can be enabled and disabled while the
+// server is running.
+const (
+	txIndexName         = "txindex"
+	addrIndexName       = "addrindex"
+	existsAddrIndexName = "existsaddrindex"
+)
+
+// indexSync houses the state of an in-progress sync of a runtime enabled index
+// along with its dependent.
+type indexSync struct {
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// managedIndex houses an enabled optional index along with the sync that is
+// catching it up to the main chain, if any.
+type managedIndex struct {
+	indexer indexers.Indexer
+	sync    *indexSync
+}
+
+// indexManager provides the ability to enable and disable optional indexes
+// while the server is running and implements the rpcserver.IndexManager
+// interface.
+//
+// Enabled indexes are subscribed to the index subscriber and caught up to the
+// main chain in the background.  They are only made available to the RPC
+// server and mempool once they are synced.  Disabled indexes are unsubscribed
+// and then removed from the database in the background.
+type indexManager struct {
+	ctx        context.Context
+	server     *server
+	db         database.DB
+	queryer    indexers.ChainQueryer
+	subscriber *indexers.IndexSubscriber
+	wg         sync.WaitGroup
+
+	mtx      sync.Mutex
+	indexes  map[string]*managedIndex
+	dropping map[string]indexers.Indexer
+}
+
+// Ensure indexManager implements the rpcserver.IndexManager interface.
+var _ rpcserver.IndexManager = (*indexManager)(nil)
+
+// newIndexManager returns a new index manager for the provided server which
+// tracks the optional indexes the server was started with.
+func newIndexManager(ctx context.Context, s *server, db database.DB, queryer indexers.ChainQueryer) *indexManager {
+	m := &indexManager{
+		ctx:        ctx,
+		server:     s,
+		db:         db,
+		queryer:    queryer,
+		subscriber: s.indexSubscriber,
+		indexes:    make(map[string]*managedIndex),
+		dropping:   make(map[string]indexers.Indexer),
+	}
+	if s.txIndex != nil {
+		m.indexes[txIndexName] = &managedIndex{indexer: s.txIndex}
+	}
+	if s.addrIndex != nil {
+		m.indexes[addrIndexName] = &managedIndex{indexer: s.addrIndex}
+	}
+	if s.existsAddrIndex != nil {
+		m.indexes[existsAddrIndexName] = &managedIndex{indexer: s.existsAddrIndex}
+	}
+	return m
+}
+
+// checkName returns an error when the provided name is not a managed index or
+// the index is still being removed from the database.
+//
+// This function MUST be called with the manager lock held.
+func (m *indexManager) checkName(name string) error {
+	switch name {
+	case txIndexName, addrIndexName, existsAddrIndexName:
+	default:
+		return fmt.Errorf("unknown index %q", name)
+	}
+	if _, ok := m.dropping[name]; ok {
+		return fmt.Errorf("%s is still being removed", name)
+	}
+	return nil
+}
+
+// publish makes the provided synced index available to the RPC server and
+// mempool.  A nil indexer removes the named index from them.
+//
+// This function MUST be called with the manager lock held.
+func (m *indexManager) publish(name string, indexer indexers.Indexer) {
+	s := m.server
+	switch name {
+	case txIndexName:
+		if s.rpcServer == nil {
+			return
+		}
+		if txIndex, ok := indexer.(*indexers.TxIndex); ok {
+			s.rpcServer.SetTxIndexer(txIndex)
+			return
+		}
+		s.rpcServer.SetTxIndexer(nil)
+
+	case addrIndexName:
+		addrIndex, _ := indexer.(*indexers.AddrIndex)
+		s.txMemPool.SetAddrIndex(addrIndex)
+		if s.rpcServer == nil {
+			return
+		}
+		if addrIndex != nil {
+			s.rpcServer.SetAddrIndexer(addrIndex)
+			return
+		}
+		s.rpcServer.SetAddrIndexer(nil)
+
+	case existsAddrIndexName:
+		existsAddrIndex, _ := indexer.(*indexers.ExistsAddrIndex)
+		s.txMemPool.SetExistsAddrIndex(existsAddrIndex)
+		if s.rpcServer == nil {
+			return
+		}
+		if existsAddrIndex != nil {
+			s.rpcServer.SetExistsAddresser(existsAddrIndex)
+			return
+		}
+		s.rpcServer.SetExistsAddresser(nil)
+	}
+}
+
+// startSync catches up the named index along with its dependent in the
+// background and publishes them once they are synced.
+//
+// This function MUST be called with the manager lock held.
+func (m *indexManager) startSync(name string) {
+	idx := m.indexes[name]
+	ctx, cancel := context.WithCancel(m.ctx)
+	idxSync := &indexSync{cancel: cancel, done: make(chan struct{})}
+	idx.sync = idxSync
+	if name == txIndexName {
+		if addrIdx, ok := m.indexes[addrIndexName]; ok {
+			addrIdx.sync = idxSync
+		}
+	}
+
+	m.wg.Add(1)
+	go func() {
+		defer m.wg.Done()
+		err := m.subscriber.SyncIndex(ctx, m.queryer, idx.indexer)
+		interrupted := ctx.Err() != nil
+		close(idxSync.done)
+		cancel()
+		if err != nil {
+			if !interrupted {
+				indxLog.Errorf("Unable to sync %s: %v", name, err)
+			}
+			return
+		}
+
+		// Publish the synced indexes unless the sync was stopped in the mean
+		// time.
+		m.mtx.Lock()
+		for name, idx := range m.indexes {
+			if idx.sync == idxSync {
+				idx.sync = nil
+				m.publish(name, idx.indexer)
+			}
+		}
+		m.mtx.Unlock()
+	}()
+}
+
+// stopSync stops the sync of the named index, if any, and waits for it to
+// finish.  It returns the name of the index the sync was started for, which
+// is the prerequisite of the named index when they are synced together, or an
+// empty string when there was no sync in progress.
+//
+// This function MUST be called with the manager lock held.
+func (m *indexManager) stopSync(name string) string {
+	idx, ok := m.indexes[name]
+	if !ok || idx.sync == nil {
+		return ""
+	}
+
+	idxSync := idx.sync
+	idxSync.cancel()
+	<-idxSync.done
+
+	var root string
+	for name, idx := range m.indexes {
+		if idx.sync == idxSync {
+			idx.sync = nil
+			if root == "" || name == txIndexName {
+				root = name
+			}
+		}
+	}
+	return root
+}
+
+// resumeSync restarts the sync of the named index along with its dependent when
+// they are still catching up to the main chain.  Indexes that finished syncing
+// before their sync was stopped are published instead.
+//
+// This function MUST be called with the manager lock held.
+func (m *indexManager) resumeSync(name string) {
+	names := []string{name}
+	if name == txIndexName {
+		names = append(names, addrIndexName)
+	}
+	for _, name := range names {
+		idx, ok := m.indexes[name]
+		if !ok {
+			return
+		}
+		if idx.indexer.IndexSubscription().IsCatchingUp() {
+			m.startSync(name)
+			return
+		}
+		m.publish(name, idx.indexer)
+	}
+}
+
+// EnableIndex subscribes the named optional index for updates and starts
+// catching it up to the main chain in the background.  Enabling the address
+// index also enables the transaction index since it depends on it.
+//
+// This is part of the rpcserver.IndexManager interface.
+func (m *indexManager) EnableIndex(name string) error {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	if m.ctx.Err() != nil {
+		return errors.New("server is shutting down")
+	}
+	if err := m.checkName(name); err != nil {
+		return err
+	}
+	if _, ok := m.indexes[name]; ok {
+		return fmt.Errorf("%s is already enabled", name)
+	}
+
+	var err error
+	switch name {
+	case txIndexName:
+		var txIndex *indexers.TxIndex
+		txIndex, err = indexers.NewTxIndex(m.subscriber, m.db, m.queryer)
+		if err != nil {
+			return err
+		}
+		m.indexes[name] = &managedIndex{indexer: txIndex}
+		m.startSync(name)
+
+	case addrIndexName:
+		// Enable the transaction index if needed since the address index
+		// requires it.
+		if err := m.checkName(txIndexName); err != nil {
+			return err
+		}
+		txIdx, ok := m.indexes[txIndexName]
+		if !ok {
+			indxLog.Infof("Transaction index enabled because it is " +
+				"required by the address index")
+			txIndex, err := indexers.NewTxIndex(m.subscriber, m.db, m.queryer)
+			if err != nil {
+				return err
+			}
+			txIdx = &managedIndex{indexer: txIndex}
+			m.indexes[txIndexName] = txIdx
+		}
+
+		// The address index is synced along with the transaction index when
+		// it is not synced yet, so stop any sync that is in progress in order
+		// to restart it with the address index included.  Progress is stored
+		// in the database, so the restarted sync resumes where the previous
+		// one stopped.
+		m.stopSync(txIndexName)
+
+		var addrIndex *indexers.AddrIndex
+		addrIndex, err = indexers.NewAddrIndex(m.subscriber, m.db, m.queryer)
+		if err != nil {
+			m.resumeSync(txIndexName)
+			return err
+		}
+		m.indexes[name] = &managedIndex{indexer: addrIndex}
+		m.resumeSync(txIndexName)
+
+	case existsAddrIndexName:
+		var existsAddrIndex *indexers.ExistsAddrIndex
+		existsAddrIndex, err = indexers.NewExistsAddrIndex(m.subscriber, m.db,
+			m.queryer)
+		if err != nil {
+			return err
+		}
+		m.indexes[name] = &managedIndex{indexer: existsAddrIndex}
+		m.startSync(name)
+	}
+
+	indxLog.Infof("Enabled %s, catching up in the background", name)
+	return nil
+}
+
+// DisableIndex stops updating the named optional index and removes it from the
+// database in the background.  Disabling the transaction index also disables
+// the address index since it depends on it.
+//
+// This is part of the rpcserver.IndexManager interface.
+func (m *indexManager) DisableIndex(name string) error {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	if m.ctx.Err() != nil {
+		return errors.New("server is shutting down")
+	}
+	if err := m.checkName(name); err != nil {
+		return err
+	}
+	idx, ok := m.indexes[name]
+	if !ok {
+		return fmt.Errorf("%s is not enabled", name)
+	}
+
+	// Determine the indexes that are disabled.  The transaction index takes
+	// the address index with it since the address index depends on it.
+	names := []string{name}
+	if name == txIndexName {
+		if _, ok := m.dropping[addrIndexName]; ok {
+			return fmt.Errorf("%s is still being removed", addrIndexName)
+		}
+		if _, ok := m.indexes[addrIndexName]; ok {
+			names = append(names, addrIndexName)
+		}
+	}
+
+	// Remove the indexes from the RPC server and mempool and stop any sync
+	// that is in progress before removing their subscription so they are no
+	// longer updated.
+	var syncRoot string
+	for _, name := range names {
+		if root := m.stopSync(name); root != "" {
+			syncRoot = root
+		}
+		m.publish(name, nil)
+	}
+	err := m.subscriber.Unsubscribe(m.ctx, idx.indexer)
+	if err != nil {
+		m.resumeSync(names[0])
+		return err
+	}
+	for _, name := range names {
+		m.dropping[name] = m.indexes[name].indexer
+		delete(m.indexes, name)
+	}
+
+	// Resume syncing the transaction index when it was being synced along
+	// with the disabled address index.
+	if syncRoot == txIndexName && name != txIndexName {
+		m.resumeSync(txIndexName)
+	}
+
+	var dropIndex func(context.Context, database.DB) error
+	switch name {
+	case txIndexName:
+		dropIndex = indexers.DropTxIndex
+	case addrIndexName:
+		dropIndex = indexers.DropAddrIndex
+	case existsAddrIndexName:
+		dropIndex = indexers.DropExistsAddrIndex
+	}
+
+	m.wg.Add(1)
+	go func() {
+		defer m.wg.Done()
+		err := dropIndex(m.ctx, m.db)
+		if err != nil {
+			if errors.Is(err, indexers.ErrInterruptRequested) {
+				indxLog.Warnf("Removal of %s was interrupted and will be "+
+					"finished the next time it is enabled", name)
+			} else {
+				indxLog.Errorf("Unable to remove %s: %v", name, err)
+			}
+		}
+
+		m.mtx.Lock()
+		for _, name := range names {
+			delete(m.dropping, name)
+		}
+		m.mtx.Unlock()
+	}()
+
+	indxLog.Infof("Disabled %s, removing it in the background", name)
+	return nil
+}
+
+// IndexInfo returns the status of the enabled optional indexes along with the
+// indexes that are still being removed from the database.
+//
+// This is part of the rpcserver.IndexManager interface.
+func (m *indexManager) IndexInfo() ([]rpcserver.IndexInfo, error) {
+	type indexEntry struct {
+		name     string
+		indexer  indexers.Indexer
+		dropping bool
+	}
+
+	m.mtx.Lock()
+	entries := make([]indexEntry, 0, len(m.indexes)+len(m.dropping))
+	for name, idx := range m.indexes {
+		entries = append(entries, indexEntry{name: name, indexer: idx.indexer})
+	}
+	for name, indexer := range m.dropping {
+		entries = append(entries, indexEntry{name, indexer, true})
+	}
+	m.mtx.Unlock()
+
+	// Calculating the disk usage requires iterating the index, so it is done
+	// without holding the lock.
+	best := m.server.chain.BestSnapshot()
+	infos := make([]rpcserver.IndexInfo, 0, len(entries))
+	for _, entry := range entries {
+		diskUsage, err := indexers.IndexDiskUsage(entry.indexer)
+		if err != nil {
+			return nil, err
+		}
+		info := rpcserver.IndexInfo{
+			Name:      entry.name,
+			Dropping:  entry.dropping,
+			DiskUsage: diskUsage,
+		}
+		if !entry.dropping {
+			height, hash, err := entry.indexer.Tip()
+			if err != nil {
+				return nil, err
+			}
+			sub := entry.indexer.IndexSubscription()
+			info.Height = height
+			info.Hash = *hash
+			info.Synced = sub != nil && !sub.IsCatchingUp() &&
+				*hash == best.Hash
+		}
+		infos = append(infos, info)
+	}
+	sort.Slice(infos, func(i, j int) bool {
+		return infos[i].Name < infos[j].Name
+	})
+	return infos, nil
+}
+
+// Wait blocks until all background index syncs and removals have finished.
+// They are stopped once the context the manager was created with is canceled.
+func (m *indexManager) Wait() {
+	m.wg.Wait()
+}
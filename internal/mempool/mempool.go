@@ -137,6 +137,12 @@ type Config struct {
 	// This can be nil if the address index is not enabled.
 	ExistsAddrIndex *indexers.ExistsAddrIndex
 
+	// AddrBalanceIndex defines the optional address balance index instance
+	// to use for tracking the deltas made by the unconfirmed transactions in
+	// the memory pool.  This can be nil if the address balance index is not
+	// enabled.
+	AddrBalanceIndex *indexers.AddrBalanceIndex
+
 	// AddTxToFeeEstimation defines an optional function to be called whenever a
 	// new transaction is added to the mempool, which can be used to track fees
 	// for the purposes of smart fee estimation.
@@ -860,6 +866,9 @@ func (mp *TxPool) removeTransaction(tx *dcrutil.Tx, removeRedeemers,
 		if mp.cfg.AddrIndex != nil {
 			mp.cfg.AddrIndex.RemoveUnconfirmedTx(txHash)
 		}
+		if mp.cfg.AddrBalanceIndex != nil {
+			mp.cfg.AddrBalanceIndex.RemoveUnconfirmedTx(txHash)
+		}
 
 		// Mark the referenced outpoints as unspent by the pool.
 		for _, txIn := range txDesc.Tx.MsgTx().TxIn {
@@ -980,6 +989,9 @@ func (mp *TxPool) addTransaction(utxoView *blockchain.UtxoViewpoint,
 	if mp.cfg.ExistsAddrIndex != nil {
 		mp.cfg.ExistsAddrIndex.AddUnconfirmedTx(msgTx, isTreasuryEnabled)
 	}
+	if mp.cfg.AddrBalanceIndex != nil {
+		mp.cfg.AddrBalanceIndex.AddUnconfirmedTx(tx, utxoView)
+	}
 
 	// Inform the associated fee estimator that a new transaction has been added
 	// to the mempool.
@@ -2321,6 +2333,31 @@ func (mp *TxPool) LastUpdated() time.Time {
 	return time.Unix(atomic.LoadInt64(&mp.lastUpdated), 0)
 }
 
+// SetAddrIndex sets the optional address index instance used to index the
+// unconfirmed transactions in the pool.  It may be nil to stop indexing them.
+//
+// Only transactions added to the pool after the index is set are indexed.
+//
+// This function is safe for concurrent access.
+func (mp *TxPool) SetAddrIndex(addrIndex *indexers.AddrIndex) {
+	mp.mtx.Lock()
+	mp.cfg.AddrIndex = addrIndex
+	mp.mtx.Unlock()
+}
+
+// SetExistsAddrIndex sets the optional exists address index instance used to
+// index the unconfirmed transactions in the pool.  It may be nil to stop
+// indexing them.
+//
+// Only transactions added to the pool after the index is set are indexed.
+//
+// This function is safe for concurrent access.
+func (mp *TxPool) SetExistsAddrIndex(existsAddrIndex *indexers.ExistsAddrIndex) {
+	mp.mtx.Lock()
+	mp.cfg.ExistsAddrIndex = existsAddrIndex
+	mp.mtx.Unlock()
+}
+
 // MiningView returns a slice of mining descriptors for all the transactions
 // in the pool in addition to a snapshot of the current pool's transaction
 // relationships.
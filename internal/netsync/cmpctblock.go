@@ -0,0 +1,292 @@
+// Copyright (c) 2023 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package netsync
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"github.com/EXCCoin/exccd/blockchain/stake/v4"
+	"github.com/EXCCoin/exccd/blockchain/standalone/v2"
+	"github.com/EXCCoin/exccd/chaincfg/chainhash"
+	"github.com/EXCCoin/exccd/wire"
+	"github.com/dchest/siphash"
+)
+
+const (
+	// maxCmpctHBPeers is the maximum number of peers that are asked to
+	// announce new blocks via compact blocks without first announcing them
+	// via headers (high-bandwidth mode).
+	maxCmpctHBPeers = 3
+
+	// cmpctShortIDMask is the mask used to truncate the siphash output to the
+	// number of bytes a short transaction id occupies on the wire.
+	cmpctShortIDMask = 1<<(wire.CmpctBlockShortIDSize*8) - 1
+)
+
+// cmpctShortIDKeys returns the siphash keys used to calculate the short
+// transaction ids of a compact block for the provided header and nonce.  The
+// keys are the first two little-endian uint64s of the hash of the serialized
+// header followed by the little-endian nonce.
+func cmpctShortIDKeys(header *wire.BlockHeader, nonce uint64) (uint64, uint64) {
+	headerBytes, err := header.Bytes()
+	if err != nil {
+		// Serializing a header to memory can't fail.
+		panic(fmt.Sprintf("unable to serialize block header: %v", err))
+	}
+	var buf [wire.MaxBlockHeaderPayload + 8]byte
+	n := copy(buf[:], headerBytes)
+	binary.LittleEndian.PutUint64(buf[n:], nonce)
+	hash := chainhash.HashH(buf[:n+8])
+	return binary.LittleEndian.Uint64(hash[0:8]),
+		binary.LittleEndian.Uint64(hash[8:16])
+}
+
+// cmpctShortID returns the short transaction id for the provided full
+// transaction hash (including the witness) using the provided siphash keys.
+func cmpctShortID(k0, k1 uint64, txHash *chainhash.Hash) uint64 {
+	return siphash.Hash(k0, k1, txHash[:]) & cmpctShortIDMask
+}
+
+// cmpctPrefillStakeTx returns whether or not the provided stake transaction
+// should be sent in full in a compact block as opposed to by its short id.
+// Treasurybases and revocations are never relayed on their own (revocations
+// are created automatically once the automatic ticket revocations agenda is
+// active), so they are not expected to be in the mempool of the receiver.
+func cmpctPrefillStakeTx(tx *wire.MsgTx) bool {
+	return standalone.IsTreasuryBase(tx) || stake.IsSSRtx(tx, false)
+}
+
+// NewCmpctBlock returns a compact block for the provided block that uses the
+// provided nonce to calculate the short transaction ids.
+//
+// The coinbase of the regular transaction tree along with the treasurybase and
+// revocations in the stake tree are prefilled since the receiver is not
+// expected to have them.  All other transactions, including votes and tickets,
+// are referenced by their short ids.
+func NewCmpctBlock(block *wire.MsgBlock, nonce uint64) *wire.MsgCmpctBlock {
+	msg := wire.NewMsgCmpctBlock(&block.Header, nonce)
+	k0, k1 := cmpctShortIDKeys(&block.Header, nonce)
+
+	msg.ShortIDs = make([]uint64, 0, len(block.Transactions))
+	for i, tx := range block.Transactions {
+		if i == 0 {
+			msg.PrefilledTxns = append(msg.PrefilledTxns, wire.PrefilledTx{
+				Index: 0,
+				Tx:    tx,
+			})
+			continue
+		}
+		txHash := tx.TxHashFull()
+		msg.ShortIDs = append(msg.ShortIDs, cmpctShortID(k0, k1, &txHash))
+	}
+
+	msg.STxShortIDs = make([]uint64, 0, len(block.STransactions))
+	for i, tx := range block.STransactions {
+		if cmpctPrefillStakeTx(tx) {
+			msg.PrefilledSTxns = append(msg.PrefilledSTxns, wire.PrefilledTx{
+				Index: uint32(i),
+				Tx:    tx,
+			})
+			continue
+		}
+		txHash := tx.TxHashFull()
+		msg.STxShortIDs = append(msg.STxShortIDs, cmpctShortID(k0, k1, &txHash))
+	}
+
+	return msg
+}
+
+// cmpctTxSlot identifies the position of a transaction in one of the
+// transaction trees of a block that is being reconstructed.
+type cmpctTxSlot struct {
+	stake bool
+	index uint32
+}
+
+// cmpctBlockState houses the state used to reconstruct a block from a compact
+// block along with the transactions available locally and any missing
+// transactions that are later provided by a blocktxn message.
+type cmpctBlockState struct {
+	hash   chainhash.Hash
+	header wire.BlockHeader
+	txns   []*wire.MsgTx
+	stxns  []*wire.MsgTx
+
+	// missing and sMissing are the indexes of the transactions in the regular
+	// and stake trees, respectively, that could not be found locally and must
+	// be requested from the peer.
+	missing  []uint32
+	sMissing []uint32
+}
+
+// newCmpctBlockState attempts to reconstruct the block described by the
+// provided compact block using its prefilled transactions along with the
+// provided candidate transactions, which are typically the contents of the
+// mempool.  Any transactions that are not available, or whose short ids match
+// more than one candidate, are recorded as missing.
+//
+// An error is returned when the compact block contains duplicate short ids
+// since that means the block can't be unambiguously reconstructed.
+func newCmpctBlockState(msg *wire.MsgCmpctBlock, candidates []*wire.MsgTx) (*cmpctBlockState, error) {
+	state := &cmpctBlockState{
+		hash:   msg.Header.BlockHash(),
+		header: msg.Header,
+		txns:   make([]*wire.MsgTx, msg.NumTransactions()),
+		stxns:  make([]*wire.MsgTx, msg.NumSTransactions()),
+	}
+
+	// Place the prefilled transactions in their respective positions and
+	// assign the short ids to the remaining positions in order.
+	slots := make(map[uint64]cmpctTxSlot, len(msg.ShortIDs)+
+		len(msg.STxShortIDs))
+	assign := func(txns []*wire.MsgTx, prefilled []wire.PrefilledTx,
+		shortIDs []uint64, stake bool) error {
+
+		for _, ptx := range prefilled {
+			txns[ptx.Index] = ptx.Tx
+		}
+		var next int
+		for i := range txns {
+			if txns[i] != nil {
+				continue
+			}
+			shortID := shortIDs[next]
+			next++
+			if _, ok := slots[shortID]; ok {
+				return fmt.Errorf("duplicate short id %x in compact block %v",
+					shortID, state.hash)
+			}
+			slots[shortID] = cmpctTxSlot{stake: stake, index: uint32(i)}
+		}
+		return nil
+	}
+	err := assign(state.txns, msg.PrefilledTxns, msg.ShortIDs, false)
+	if err != nil {
+		return nil, err
+	}
+	err = assign(state.stxns, msg.PrefilledSTxns, msg.STxShortIDs, true)
+	if err != nil {
+		return nil, err
+	}
+
+	// Fill in the transactions from the candidates while keeping track of any
+	// positions that are matched by more than one candidate since it is not
+	// possible to know which one is correct in that case.
+	var collisions map[cmpctTxSlot]struct{}
+	k0, k1 := cmpctShortIDKeys(&msg.Header, msg.Nonce)
+	for _, tx := range candidates {
+		txHash := tx.TxHashFull()
+		slot, ok := slots[cmpctShortID(k0, k1, &txHash)]
+		if !ok {
+			continue
+		}
+		txns := state.txns
+		if slot.stake {
+			txns = state.stxns
+		}
+		if txns[slot.index] != nil {
+			if collisions == nil {
+				collisions = make(map[cmpctTxSlot]struct{})
+			}
+			collisions[slot] = struct{}{}
+		}
+		txns[slot.index] = tx
+	}
+	for slot := range collisions {
+		if slot.stake {
+			state.stxns[slot.index] = nil
+			continue
+		}
+		state.txns[slot.index] = nil
+	}
+
+	// Note which transactions are still missing.
+	for i, tx := range state.txns {
+		if tx == nil {
+			state.missing = append(state.missing, uint32(i))
+		}
+	}
+	for i, tx := range state.stxns {
+		if tx == nil {
+			state.sMissing = append(state.sMissing, uint32(i))
+		}
+	}
+
+	return state, nil
+}
+
+// isComplete returns whether or not all of the transactions of the block being
+// reconstructed are available.
+func (state *cmpctBlockState) isComplete() bool {
+	return len(state.missing) == 0 && len(state.sMissing) == 0
+}
+
+// getBlockTxnMsg returns a getblocktxn message that requests all of the
+// missing transactions.
+func (state *cmpctBlockState) getBlockTxnMsg() *wire.MsgGetBlockTxn {
+	msg := wire.NewMsgGetBlockTxn(&state.hash)
+	msg.Indexes = state.missing
+	msg.STxIndexes = state.sMissing
+	return msg
+}
+
+// fill populates the missing transactions with those in the provided blocktxn
+// message.  An error is returned when the message does not provide exactly the
+// missing transactions.
+func (state *cmpctBlockState) fill(msg *wire.MsgBlockTxn) error {
+	if msg.BlockHash != state.hash {
+		return fmt.Errorf("blocktxn message for block %v does not match "+
+			"pending compact block %v", msg.BlockHash, state.hash)
+	}
+	if len(msg.Transactions) != len(state.missing) ||
+		len(msg.STransactions) != len(state.sMissing) {
+
+		return fmt.Errorf("blocktxn message for block %v provides %d "+
+			"regular and %d stake transactions instead of the requested "+
+			"%d and %d", state.hash, len(msg.Transactions),
+			len(msg.STransactions), len(state.missing), len(state.sMissing))
+	}
+
+	for i, idx := range state.missing {
+		state.txns[idx] = msg.Transactions[i]
+	}
+	for i, idx := range state.sMissing {
+		state.stxns[idx] = msg.STransactions[i]
+	}
+	state.missing = nil
+	state.sMissing = nil
+	return nil
+}
+
+// block returns the reconstructed block.  It must only be called once the
+// state is complete.
+func (state *cmpctBlockState) block() *wire.MsgBlock {
+	return &wire.MsgBlock{
+		Header:        state.header,
+		Transactions:  state.txns,
+		STransactions: state.stxns,
+	}
+}
+
+// cmpctMerkleRootsMatch returns whether or not the transactions in the provided
+// block commit to the merkle roots in its header either via the combined
+// merkle root used once the header commitments agenda is active or the
+// separate regular and stake tree merkle roots used prior to that.
+//
+// It is used to detect reconstructed blocks that contain the wrong
+// transactions due to short id collisions without having to fully validate
+// them.
+func cmpctMerkleRootsMatch(block *wire.MsgBlock) bool {
+	header := &block.Header
+	combined := standalone.CalcCombinedTxTreeMerkleRoot(block.Transactions,
+		block.STransactions)
+	if header.MerkleRoot == combined {
+		return true
+	}
+	return header.MerkleRoot == standalone.CalcTxTreeMerkleRoot(
+		block.Transactions) && header.StakeRoot ==
+		standalone.CalcTxTreeMerkleRoot(block.STransactions)
+}
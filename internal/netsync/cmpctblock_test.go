@@ -0,0 +1,466 @@
+// Copyright (c) 2023 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package netsync
+
+import (
+	"bytes"
+	"net"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/EXCCoin/exccd/blockchain/standalone/v2"
+	"github.com/EXCCoin/exccd/chaincfg/chainhash"
+	peerpkg "github.com/EXCCoin/exccd/peer/v3"
+	"github.com/EXCCoin/exccd/wire"
+)
+
+// cmpctTestTx returns a unique transaction for use in the compact block tests
+// based on the provided id.
+func cmpctTestTx(id uint32) *wire.MsgTx {
+	tx := wire.NewMsgTx()
+	tx.AddTxIn(wire.NewTxIn(&wire.OutPoint{
+		Hash:  chainhash.Hash{0x01},
+		Index: id,
+	}, 100000, []byte{0x51}))
+	tx.AddTxOut(wire.NewTxOut(int64(id), []byte{0x51}))
+	return tx
+}
+
+// cmpctTestTreasuryBase returns a minimal treasurybase for use in the compact
+// block tests.
+func cmpctTestTreasuryBase() *wire.MsgTx {
+	tx := wire.NewMsgTx()
+	tx.Version = wire.TxVersionTreasury
+	tx.AddTxIn(wire.NewTxIn(wire.NewOutPoint(&chainhash.Hash{},
+		wire.MaxPrevOutIndex, wire.TxTreeRegular), 0, nil))
+	tx.AddTxOut(wire.NewTxOut(0, []byte{0xc1}))
+	tx.AddTxOut(wire.NewTxOut(0, append([]byte{0x6a, 0x0c},
+		make([]byte, 12)...)))
+	return tx
+}
+
+// cmpctTestBlock returns a block for use in the compact block tests that has a
+// coinbase and three additional transactions in the regular tree along with a
+// treasurybase and two additional transactions in the stake tree.  The header
+// commits to the transactions via the combined merkle root.
+func cmpctTestBlock() *wire.MsgBlock {
+	block := &wire.MsgBlock{
+		Header: wire.BlockHeader{
+			Version: 9,
+			Height:  1000,
+			Voters:  2,
+		},
+		Transactions: []*wire.MsgTx{cmpctTestTx(0), cmpctTestTx(1),
+			cmpctTestTx(2), cmpctTestTx(3)},
+		STransactions: []*wire.MsgTx{cmpctTestTreasuryBase(),
+			cmpctTestTx(10), cmpctTestTx(11)},
+	}
+	block.Header.MerkleRoot = standalone.CalcCombinedTxTreeMerkleRoot(
+		block.Transactions, block.STransactions)
+	return block
+}
+
+// cmpctWireRoundTrip encodes the provided message to the wire and decodes it
+// into the provided target message in order to simulate sending it to a peer.
+func cmpctWireRoundTrip(t *testing.T, msg, target wire.Message) {
+	t.Helper()
+
+	var buf bytes.Buffer
+	if err := msg.BtcEncode(&buf, wire.ProtocolVersion); err != nil {
+		t.Fatalf("unexpected error encoding %s: %v", msg.Command(), err)
+	}
+	if err := target.BtcDecode(&buf, wire.ProtocolVersion); err != nil {
+		t.Fatalf("unexpected error decoding %s: %v", msg.Command(), err)
+	}
+}
+
+// cmpctSameBlock returns whether or not the provided blocks have the same
+// header and transactions.
+func cmpctSameBlock(a, b *wire.MsgBlock) bool {
+	sameTxns := func(a, b []*wire.MsgTx) bool {
+		if len(a) != len(b) {
+			return false
+		}
+		for i := range a {
+			if a[i].TxHashFull() != b[i].TxHashFull() {
+				return false
+			}
+		}
+		return true
+	}
+	return a.BlockHash() == b.BlockHash() &&
+		sameTxns(a.Transactions, b.Transactions) &&
+		sameTxns(a.STransactions, b.STransactions)
+}
+
+// TestNewCmpctBlock ensures that compact blocks created from a block prefill
+// the expected transactions and use short ids for the others.
+func TestNewCmpctBlock(t *testing.T) {
+	block := cmpctTestBlock()
+	msg := NewCmpctBlock(block, 0x0102030405060708)
+
+	// Ensure only the coinbase is prefilled in the regular tree and only the
+	// treasurybase is prefilled in the stake tree.
+	wantPrefilled := []wire.PrefilledTx{{Index: 0, Tx: block.Transactions[0]}}
+	if !reflect.DeepEqual(msg.PrefilledTxns, wantPrefilled) {
+		t.Fatalf("unexpected prefilled regular transactions: %v",
+			msg.PrefilledTxns)
+	}
+	wantSPrefilled := []wire.PrefilledTx{{Index: 0, Tx: block.STransactions[0]}}
+	if !reflect.DeepEqual(msg.PrefilledSTxns, wantSPrefilled) {
+		t.Fatalf("unexpected prefilled stake transactions: %v",
+			msg.PrefilledSTxns)
+	}
+
+	// Ensure the short ids are calculated from the header and nonce.
+	k0, k1 := cmpctShortIDKeys(&block.Header, msg.Nonce)
+	wantShortIDs := make([]uint64, 0, 3)
+	for _, tx := range block.Transactions[1:] {
+		txHash := tx.TxHashFull()
+		wantShortIDs = append(wantShortIDs, cmpctShortID(k0, k1, &txHash))
+	}
+	if !reflect.DeepEqual(msg.ShortIDs, wantShortIDs) {
+		t.Fatalf("unexpected short ids: got %x, want %x", msg.ShortIDs,
+			wantShortIDs)
+	}
+	for _, shortID := range append(msg.ShortIDs, msg.STxShortIDs...) {
+		if shortID > cmpctShortIDMask {
+			t.Fatalf("short id %x exceeds the max allowed", shortID)
+		}
+	}
+	if len(msg.STxShortIDs) != 2 {
+		t.Fatalf("unexpected number of stake short ids: got %d, want 2",
+			len(msg.STxShortIDs))
+	}
+
+	// Ensure a different nonce results in different short ids.
+	otherMsg := NewCmpctBlock(block, 1)
+	if reflect.DeepEqual(msg.ShortIDs, otherMsg.ShortIDs) {
+		t.Fatal("short ids do not depend on the nonce")
+	}
+}
+
+// TestCmpctBlockReconstruction ensures blocks are reconstructed from compact
+// blocks and candidate transactions as expected, including requesting and
+// filling in missing transactions.
+func TestCmpctBlockReconstruction(t *testing.T) {
+	block := cmpctTestBlock()
+	blockHash := block.BlockHash()
+
+	// Simulate receiving the compact block from a peer.
+	var msg wire.MsgCmpctBlock
+	cmpctWireRoundTrip(t, NewCmpctBlock(block, 12345), &msg)
+
+	// Ensure the block is fully reconstructed when all of the transactions
+	// are available.
+	allTxns := []*wire.MsgTx{cmpctTestTx(100), block.Transactions[3],
+		block.STransactions[2], block.Transactions[1], block.Transactions[2],
+		block.STransactions[1]}
+	state, err := newCmpctBlockState(&msg, allTxns)
+	if err != nil {
+		t.Fatalf("unexpected error reconstructing block: %v", err)
+	}
+	if !state.isComplete() {
+		t.Fatalf("block not complete: missing %v, stake missing %v",
+			state.missing, state.sMissing)
+	}
+	if !cmpctSameBlock(state.block(), block) {
+		t.Fatal("reconstructed block does not match original")
+	}
+	if !cmpctMerkleRootsMatch(state.block()) {
+		t.Fatal("reconstructed block does not match merkle root")
+	}
+
+	// Ensure transactions that are not available are reported as missing.
+	someTxns := []*wire.MsgTx{block.Transactions[1], block.Transactions[3],
+		block.STransactions[1]}
+	state, err = newCmpctBlockState(&msg, someTxns)
+	if err != nil {
+		t.Fatalf("unexpected error reconstructing block: %v", err)
+	}
+	if state.isComplete() {
+		t.Fatal("block unexpectedly complete")
+	}
+	if !reflect.DeepEqual(state.missing, []uint32{2}) {
+		t.Fatalf("unexpected missing regular txns: %v", state.missing)
+	}
+	if !reflect.DeepEqual(state.sMissing, []uint32{2}) {
+		t.Fatalf("unexpected missing stake txns: %v", state.sMissing)
+	}
+
+	// Simulate requesting the missing transactions from the peer and the
+	// peer responding with them.
+	var getBlockTxn wire.MsgGetBlockTxn
+	cmpctWireRoundTrip(t, state.getBlockTxnMsg(), &getBlockTxn)
+	if getBlockTxn.BlockHash != blockHash {
+		t.Fatalf("getblocktxn for wrong block: %v", getBlockTxn.BlockHash)
+	}
+	blockTxn := wire.NewMsgBlockTxn(&blockHash)
+	for _, idx := range getBlockTxn.Indexes {
+		blockTxn.Transactions = append(blockTxn.Transactions,
+			block.Transactions[idx])
+	}
+	for _, idx := range getBlockTxn.STxIndexes {
+		blockTxn.STransactions = append(blockTxn.STransactions,
+			block.STransactions[idx])
+	}
+	var recvBlockTxn wire.MsgBlockTxn
+	cmpctWireRoundTrip(t, blockTxn, &recvBlockTxn)
+
+	// Ensure providing the wrong number of transactions is rejected without
+	// modifying the state.
+	badBlockTxn := *blockTxn
+	badBlockTxn.STransactions = nil
+	if err := state.fill(&badBlockTxn); err == nil {
+		t.Fatal("fill did not reject the wrong number of transactions")
+	}
+	badBlockTxn = *blockTxn
+	badBlockTxn.BlockHash = chainhash.Hash{0x01}
+	if err := state.fill(&badBlockTxn); err == nil {
+		t.Fatal("fill did not reject transactions for another block")
+	}
+
+	// Ensure the block is complete and matches once the transactions are
+	// filled in.
+	if err := state.fill(&recvBlockTxn); err != nil {
+		t.Fatalf("unexpected error filling transactions: %v", err)
+	}
+	if !state.isComplete() {
+		t.Fatal("block not complete after filling missing transactions")
+	}
+	reconstructed := state.block()
+	if !cmpctSameBlock(reconstructed, block) ||
+		!cmpctMerkleRootsMatch(reconstructed) {
+
+		t.Fatal("reconstructed block does not match original")
+	}
+
+	// Ensure a block with the wrong transactions, such as would happen due to
+	// a short id collision, does not match the merkle roots.
+	reconstructed.Transactions[1] = cmpctTestTx(200)
+	if cmpctMerkleRootsMatch(reconstructed) {
+		t.Fatal("block with wrong transactions matches merkle root")
+	}
+}
+
+// TestCmpctBlockLegacyMerkleRoots ensures reconstructed blocks that commit to
+// their transactions via the separate regular and stake tree merkle roots used
+// prior to the header commitments agenda are matched as expected.
+func TestCmpctBlockLegacyMerkleRoots(t *testing.T) {
+	block := cmpctTestBlock()
+	block.Header.MerkleRoot = standalone.CalcTxTreeMerkleRoot(
+		block.Transactions)
+	block.Header.StakeRoot = standalone.CalcTxTreeMerkleRoot(
+		block.STransactions)
+	if !cmpctMerkleRootsMatch(block) {
+		t.Fatal("block does not match legacy merkle roots")
+	}
+
+	block.STransactions[1] = cmpctTestTx(200)
+	if cmpctMerkleRootsMatch(block) {
+		t.Fatal("block with wrong stake transactions matches merkle roots")
+	}
+}
+
+// TestCmpctBlockDuplicateShortIDs ensures compact blocks with duplicate short
+// ids are rejected since they can't be unambiguously reconstructed.
+func TestCmpctBlockDuplicateShortIDs(t *testing.T) {
+	block := cmpctTestBlock()
+	msg := NewCmpctBlock(block, 1)
+	msg.STxShortIDs[1] = msg.ShortIDs[0]
+	if _, err := newCmpctBlockState(msg, nil); err == nil {
+		t.Fatal("compact block with duplicate short ids was not rejected")
+	}
+}
+
+// cmpctTestConn is a net.Conn that reports TCP addresses so it can be used to
+// connect simulated peers.
+type cmpctTestConn struct {
+	net.Conn
+	laddr, raddr net.Addr
+}
+
+// LocalAddr returns the local address for the connection.
+func (c *cmpctTestConn) LocalAddr() net.Addr { return c.laddr }
+
+// RemoteAddr returns the remote address for the connection.
+func (c *cmpctTestConn) RemoteAddr() net.Addr { return c.raddr }
+
+// cmpctTestPeer houses a local peer as used by the sync manager that is
+// connected to a simulated remote peer which speaks the wire protocol directly
+// along with channels that receive the sendcmpct messages each side receives.
+type cmpctTestPeer struct {
+	local      *peerpkg.Peer
+	remoteConn net.Conn
+	localRecv  chan *wire.MsgSendCmpct
+	remoteRecv chan *wire.MsgSendCmpct
+}
+
+// newCmpctTestPeer returns a local peer that is connected to a simulated
+// remote peer and has completed the initial protocol negotiation.
+func newCmpctTestPeer(t *testing.T, id byte) *cmpctTestPeer {
+	t.Helper()
+
+	verAck := make(chan struct{}, 1)
+	p := &cmpctTestPeer{
+		localRecv:  make(chan *wire.MsgSendCmpct, 10),
+		remoteRecv: make(chan *wire.MsgSendCmpct, 10),
+	}
+	p.local = peerpkg.NewInboundPeer(&peerpkg.Config{
+		Listeners: peerpkg.MessageListeners{
+			OnVerAck: func(_ *peerpkg.Peer, _ *wire.MsgVerAck) {
+				verAck <- struct{}{}
+			},
+			OnSendCmpct: func(_ *peerpkg.Peer, msg *wire.MsgSendCmpct) {
+				p.localRecv <- msg
+			},
+		},
+		UserAgentName:    "peer",
+		UserAgentVersion: "1.0",
+		Net:              wire.SimNet,
+	})
+
+	localAddr := &net.TCPAddr{IP: net.IPv4(10, 0, 0, 1), Port: 18555}
+	remoteAddr := &net.TCPAddr{IP: net.IPv4(10, 0, 1, id), Port: 18555}
+	localConn, remoteConn := net.Pipe()
+	p.remoteConn = remoteConn
+	p.local.AssociateConnection(&cmpctTestConn{localConn, localAddr,
+		remoteAddr})
+	t.Cleanup(func() {
+		p.local.Disconnect()
+		remoteConn.Close()
+	})
+
+	// Simulate the remote peer by sending a version message, acknowledging
+	// the version message from the local peer, and notifying the test about
+	// any received sendcmpct messages.
+	go func() {
+		for {
+			msg, _, err := wire.ReadMessage(remoteConn, wire.ProtocolVersion,
+				wire.SimNet)
+			if err != nil {
+				return
+			}
+			switch msg := msg.(type) {
+			case *wire.MsgVersion:
+				p.sendToLocal(t, wire.NewMsgVerAck())
+			case *wire.MsgSendCmpct:
+				p.remoteRecv <- msg
+			}
+		}
+	}()
+	you := wire.NewNetAddressIPPort(localAddr.IP, uint16(localAddr.Port), 0)
+	me := wire.NewNetAddressIPPort(remoteAddr.IP, uint16(remoteAddr.Port), 0)
+	p.sendToLocal(t, wire.NewMsgVersion(me, you, uint64(id), 0))
+
+	select {
+	case <-verAck:
+	case <-time.After(time.Second):
+		t.Fatal("verack timeout")
+	}
+	return p
+}
+
+// sendToLocal sends the provided message from the simulated remote peer to
+// the local peer.
+func (p *cmpctTestPeer) sendToLocal(t *testing.T, msg wire.Message) {
+	err := wire.WriteMessage(p.remoteConn, msg, wire.ProtocolVersion,
+		wire.SimNet)
+	if err != nil {
+		t.Errorf("unexpected error sending %s: %v", msg.Command(), err)
+	}
+}
+
+// announceSupport has the remote peer announce support for compact blocks and
+// waits for the local peer to receive it.
+func (p *cmpctTestPeer) announceSupport(t *testing.T) {
+	t.Helper()
+
+	p.sendToLocal(t, wire.NewMsgSendCmpct(false,
+		wire.CmpctBlockEncodingVersion))
+	select {
+	case <-p.localRecv:
+	case <-time.After(time.Second):
+		t.Fatal("sendcmpct timeout")
+	}
+}
+
+// expectSendCmpct ensures the remote peer receives a sendcmpct message with
+// the provided high-bandwidth mode.
+func (p *cmpctTestPeer) expectSendCmpct(t *testing.T, wantHB bool) {
+	t.Helper()
+
+	select {
+	case msg := <-p.remoteRecv:
+		if msg.AnnounceUsingCmpctBlock != wantHB {
+			t.Fatalf("unexpected high-bandwidth mode: got %v, want %v",
+				msg.AnnounceUsingCmpctBlock, wantHB)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("sendcmpct timeout")
+	}
+}
+
+// expectNoSendCmpct ensures the remote peer does not receive a sendcmpct
+// message.
+func (p *cmpctTestPeer) expectNoSendCmpct(t *testing.T) {
+	t.Helper()
+
+	select {
+	case msg := <-p.remoteRecv:
+		t.Fatalf("unexpected sendcmpct message: %v", msg)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+// TestCmpctHighBandwidthPeers ensures the peers that are asked to announce new
+// blocks via compact blocks in high-bandwidth mode are selected as expected
+// using simulated peers.
+func TestCmpctHighBandwidthPeers(t *testing.T) {
+	var m SyncManager
+	peers := make([]*syncMgrPeer, maxCmpctHBPeers+2)
+	testPeers := make([]*cmpctTestPeer, len(peers))
+	for i := range peers {
+		testPeers[i] = newCmpctTestPeer(t, byte(i+1))
+		peers[i] = &syncMgrPeer{Peer: testPeers[i].local}
+	}
+
+	// Ensure peers that do not support compact blocks are never selected.
+	unsupported := len(peers) - 1
+	m.maybeUpdateCmpctHBPeers(peers[unsupported])
+	testPeers[unsupported].expectNoSendCmpct(t)
+	if len(m.cmpctHBPeers) != 0 {
+		t.Fatal("peer without compact block support was selected")
+	}
+
+	// Ensure the first peers to provide blocks are selected up to the max.
+	for i := 0; i < maxCmpctHBPeers; i++ {
+		testPeers[i].announceSupport(t)
+		m.maybeUpdateCmpctHBPeers(peers[i])
+		testPeers[i].expectSendCmpct(t, true)
+	}
+
+	// Ensure a peer that is already selected is moved to the most recent
+	// position without being asked again.
+	m.maybeUpdateCmpctHBPeers(peers[0])
+	testPeers[0].expectNoSendCmpct(t)
+	wantPeers := []*syncMgrPeer{peers[1], peers[2], peers[0]}
+	if !reflect.DeepEqual(m.cmpctHBPeers, wantPeers) {
+		t.Fatalf("unexpected high-bandwidth peers: %v", m.cmpctHBPeers)
+	}
+
+	// Ensure a new peer replaces the least recent one once the max is
+	// reached.
+	newPeer := maxCmpctHBPeers
+	testPeers[newPeer].announceSupport(t)
+	m.maybeUpdateCmpctHBPeers(peers[newPeer])
+	testPeers[1].expectSendCmpct(t, false)
+	testPeers[newPeer].expectSendCmpct(t, true)
+	wantPeers = []*syncMgrPeer{peers[2], peers[0], peers[newPeer]}
+	if !reflect.DeepEqual(m.cmpctHBPeers, wantPeers) {
+		t.Fatalf("unexpected high-bandwidth peers: %v", m.cmpctHBPeers)
+	}
+}
@@ -0,0 +1,367 @@
+// Copyright (c) 2023 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package netsync
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/EXCCoin/exccd/blockchain/standalone/v2"
+	"github.com/EXCCoin/exccd/blockchain/v4"
+	"github.com/EXCCoin/exccd/chaincfg/chainhash"
+	"github.com/EXCCoin/exccd/chaincfg/v3"
+	"github.com/EXCCoin/exccd/wire"
+	"github.com/dchest/siphash"
+)
+
+const (
+	// presyncCommitmentPeriod is the interval of header heights at which a
+	// commitment to the header is stored while presyncing headers.
+	presyncCommitmentPeriod = 600
+
+	// presyncRedownloadBufferSize is the number of redownloaded headers that
+	// are held back from processing until enough of the commitments that
+	// follow them have been verified.  An attacker that attempts to provide a
+	// different chain during the redownload only has a 50% chance of matching
+	// each commitment, so buffering this many headers ensures there is roughly
+	// a one in ten million chance that any headers from such a chain are
+	// processed.
+	presyncRedownloadBufferSize = 23 * presyncCommitmentPeriod
+
+	// maxBlocksPerSecond is the maximum average number of blocks per second a
+	// chain is able to have.  It is a consequence of the consensus rule that
+	// requires the timestamp of each block to be after the median timestamp
+	// of the previous 11 blocks which means the timestamp must increase at
+	// least once every 6 blocks.
+	maxBlocksPerSecond = 6
+)
+
+var (
+	// errPresyncLowWork indicates the chain a peer provided while presyncing
+	// headers ended without reaching the minimum chain work.
+	errPresyncLowWork = errors.New("chain has too little cumulative work")
+
+	// errPresyncNotConnected indicates a peer provided a header that does not
+	// connect to the previous header while presyncing headers.
+	errPresyncNotConnected = errors.New("header does not connect to the " +
+		"previous header")
+)
+
+// presyncPhase identifies the phase of a headers presync.
+type presyncPhase uint8
+
+const (
+	// presyncPhaseCollect is the phase in which the headers are validated
+	// and their cumulative work is tracked without storing them.  Only a
+	// commitment to every presyncCommitmentPeriod header is stored.
+	presyncPhaseCollect presyncPhase = iota
+
+	// presyncPhaseRedownload is the phase that starts once the collected
+	// headers reach the minimum chain work and in which the same headers are
+	// downloaded again and verified against the stored commitments before
+	// they are released for processing.
+	presyncPhaseRedownload
+
+	// presyncPhaseDone is the phase in which all redownloaded headers have
+	// been released for processing.
+	presyncPhaseDone
+)
+
+// String returns the phase as a human-readable name.
+func (p presyncPhase) String() string {
+	switch p {
+	case presyncPhaseCollect:
+		return "collect"
+	case presyncPhaseRedownload:
+		return "redownload"
+	case presyncPhaseDone:
+		return "done"
+	}
+	return fmt.Sprintf("unknown phase (%d)", uint8(p))
+}
+
+// presyncTip houses the hash and height of the most recent header that was
+// received from a peer in a given presync phase along with the cumulative work
+// of the chain it commits to.
+type presyncTip struct {
+	hash   chainhash.Hash
+	height uint32
+	work   *big.Int
+}
+
+// headersPresyncState houses the state used to presync the headers of a chain
+// provided by a peer that builds on a known header, but does not yet have
+// enough cumulative work to be worth storing.
+//
+// Accepting low-work headers would otherwise allow peers to cheaply exhaust
+// memory by providing long chains that fork from early in the chain history
+// where the difficulty is low.  Instead, the headers are first downloaded
+// without being stored while only keeping track of their cumulative work and a
+// compact commitment to a subset of them.  Once the chain reaches the minimum
+// chain work, the headers are downloaded again, verified against the
+// commitments, and released for processing.
+//
+// The commitments are a single bit from a salted hash of every
+// presyncCommitmentPeriod header starting from a random offset.  The salt and
+// offset are unknown to the peer, so it is unable to provide a different
+// low-work chain during the redownload without being detected with high
+// probability.
+type headersPresyncState struct {
+	chainParams *chaincfg.Params
+	minWork     *big.Int
+
+	// phase is the current phase of the presync.
+	phase presyncPhase
+
+	// commitKey0 and commitKey1 are the random siphash keys used to calculate
+	// the commitments, while commitOffset is the random offset of the header
+	// heights the commitments are made to.
+	commitKey0   uint64
+	commitKey1   uint64
+	commitOffset uint32
+
+	// commitments houses the bits of all of the commitments that have been
+	// made so far.  numCommitments is the total number of commitments while
+	// nextCommitment is the index of the next commitment to verify during
+	// the redownload.  maxCommitments is the maximum number of commitments a
+	// valid chain is able to require.
+	commitments    []uint64
+	numCommitments uint64
+	nextCommitment uint64
+	maxCommitments uint64
+
+	// start is the known header the chain builds on.
+	start presyncTip
+
+	// collectTip is the most recent header received during the collect phase
+	// while redownloadTip is the most recent one received during the
+	// redownload.
+	collectTip    presyncTip
+	redownloadTip presyncTip
+
+	// buffered houses the redownloaded headers that have not yet been
+	// released for processing.
+	buffered []*wire.BlockHeader
+}
+
+// newHeadersPresyncState returns a new headers presync state for a chain that
+// builds on the provided known header which has the given cumulative work.
+// The chain must reach the provided minimum work for any headers to be
+// released.
+func newHeadersPresyncState(chainParams *chaincfg.Params, minWork *big.Int, startHeader *wire.BlockHeader, startWork *big.Int, now time.Time) (*headersPresyncState, error) {
+	// Choose the salt and offset of the commitments randomly so they are not
+	// known to the peer.
+	var keys [3]uint64
+	for i := range keys {
+		var err error
+		keys[i], err = wire.RandomUint64()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Limit the number of commitments to the maximum number of blocks a valid
+	// chain is able to have between the start header and the maximum allowed
+	// timestamp in the future.
+	maxTime := now.Add(blockchain.MaxTimeOffsetSeconds * time.Second)
+	var maxCommitments uint64
+	if secs := maxTime.Unix() - startHeader.Timestamp.Unix(); secs > 0 {
+		maxCommitments = uint64(secs) * maxBlocksPerSecond /
+			presyncCommitmentPeriod
+	}
+
+	startHash := startHeader.BlockHash()
+	newTip := func() presyncTip {
+		return presyncTip{
+			hash:   startHash,
+			height: startHeader.Height,
+			work:   new(big.Int).Set(startWork),
+		}
+	}
+	return &headersPresyncState{
+		chainParams:    chainParams,
+		minWork:        minWork,
+		phase:          presyncPhaseCollect,
+		commitKey0:     keys[0],
+		commitKey1:     keys[1],
+		commitOffset:   uint32(keys[2] % presyncCommitmentPeriod),
+		maxCommitments: maxCommitments,
+		start:          newTip(),
+		collectTip:     newTip(),
+		redownloadTip:  newTip(),
+	}, nil
+}
+
+// commitment returns the commitment bit for the provided header hash.
+func (s *headersPresyncState) commitment(hash *chainhash.Hash) bool {
+	return siphash.Hash(s.commitKey0, s.commitKey1, hash[:])&1 == 1
+}
+
+// isCommitmentHeight returns whether or not a commitment is made to the header
+// at the provided height.
+func (s *headersPresyncState) isCommitmentHeight(height uint32) bool {
+	return height%presyncCommitmentPeriod == s.commitOffset
+}
+
+// addCommitment stores the commitment to the provided header hash.
+func (s *headersPresyncState) addCommitment(hash *chainhash.Hash) {
+	word, bit := s.numCommitments/64, s.numCommitments%64
+	if word == uint64(len(s.commitments)) {
+		s.commitments = append(s.commitments, 0)
+	}
+	if s.commitment(hash) {
+		s.commitments[word] |= 1 << bit
+	}
+	s.numCommitments++
+}
+
+// verifyCommitment returns whether or not the provided header hash matches the
+// next stored commitment and advances to the following one.
+func (s *headersPresyncState) verifyCommitment(hash *chainhash.Hash) bool {
+	if s.nextCommitment >= s.numCommitments {
+		return false
+	}
+	word, bit := s.nextCommitment/64, s.nextCommitment%64
+	s.nextCommitment++
+	want := s.commitments[word]&(1<<bit) != 0
+	return s.commitment(hash) == want
+}
+
+// tip returns the most recent header received in the current phase.
+func (s *headersPresyncState) tip() *presyncTip {
+	if s.phase == presyncPhaseCollect {
+		return &s.collectTip
+	}
+	return &s.redownloadTip
+}
+
+// connects returns whether or not the provided header connects to the most
+// recent header received in the current phase.
+func (s *headersPresyncState) connects(header *wire.BlockHeader) bool {
+	tip := s.tip()
+	return header.PrevBlock == tip.hash && header.Height == tip.height+1
+}
+
+// locatorHash returns the hash of the header that more headers should be
+// requested after.
+func (s *headersPresyncState) locatorHash() *chainhash.Hash {
+	return &s.tip().hash
+}
+
+// collectHeaders validates the provided headers that were received during the
+// collect phase and tracks their cumulative work and commitments.  It moves to
+// the redownload phase once the minimum chain work is reached.
+func (s *headersPresyncState) collectHeaders(headers []*wire.BlockHeader, moreAvailable bool) error {
+	tip := &s.collectTip
+	for _, header := range headers {
+		if !s.connects(header) {
+			return errPresyncNotConnected
+		}
+
+		// Ensure the header has a valid proof of work for its claimed
+		// difficulty since that is what its work is based on.
+		err := standalone.CheckProofOfWork(header, header.Bits, s.chainParams)
+		if err != nil {
+			return err
+		}
+
+		tip.hash = header.BlockHash()
+		tip.height = header.Height
+		tip.work.Add(tip.work, standalone.CalcWork(header.Bits))
+
+		if s.isCommitmentHeight(header.Height) {
+			if s.numCommitments >= s.maxCommitments {
+				return fmt.Errorf("chain exceeds the maximum possible "+
+					"number of headers as of height %d", header.Height)
+			}
+			s.addCommitment(&tip.hash)
+		}
+
+		// Start redownloading the headers once the chain has reached the
+		// minimum work.
+		if tip.work.Cmp(s.minWork) >= 0 {
+			s.phase = presyncPhaseRedownload
+			return nil
+		}
+	}
+
+	if !moreAvailable {
+		return errPresyncLowWork
+	}
+	return nil
+}
+
+// redownloadHeaders verifies the provided headers that were received during
+// the redownload phase against the stored commitments and returns the ones
+// that are ready to be processed.  All remaining headers are released once the
+// minimum chain work is reached.
+func (s *headersPresyncState) redownloadHeaders(headers []*wire.BlockHeader, moreAvailable bool) ([]*wire.BlockHeader, error) {
+	tip := &s.redownloadTip
+	for i, header := range headers {
+		if !s.connects(header) {
+			return nil, errPresyncNotConnected
+		}
+
+		tip.hash = header.BlockHash()
+		tip.height = header.Height
+		tip.work.Add(tip.work, standalone.CalcWork(header.Bits))
+
+		if s.isCommitmentHeight(header.Height) &&
+			!s.verifyCommitment(&tip.hash) {
+
+			return nil, fmt.Errorf("redownloaded header %s (height %d) does "+
+				"not match the presynced chain", tip.hash, header.Height)
+		}
+		s.buffered = append(s.buffered, header)
+
+		// Release all of the buffered headers along with any that follow
+		// them once the chain has reached the minimum work since the
+		// remaining headers are no longer subject to the presync.
+		if tip.work.Cmp(s.minWork) >= 0 {
+			s.phase = presyncPhaseDone
+			ready := append(s.buffered, headers[i+1:]...)
+			s.buffered = nil
+			s.commitments = nil
+			return ready, nil
+		}
+	}
+
+	// The peer already provided a chain that reached the minimum work during
+	// the collect phase, so it must provide the same chain again.
+	if !moreAvailable {
+		return nil, errPresyncLowWork
+	}
+
+	// Release the headers that are followed by enough verified commitments.
+	if len(s.buffered) <= presyncRedownloadBufferSize {
+		return nil, nil
+	}
+	numReady := len(s.buffered) - presyncRedownloadBufferSize
+	ready := make([]*wire.BlockHeader, numReady)
+	copy(ready, s.buffered)
+	s.buffered = append(s.buffered[:0], s.buffered[numReady:]...)
+	return ready, nil
+}
+
+// processHeaders processes the provided headers received from the peer
+// according to the current phase and returns any headers that are ready to be
+// processed by the chain since they are now known to be part of a chain with
+// at least the minimum work.  The provided flag indicates whether or not the
+// peer is expected to have more headers after the provided ones.
+//
+// An error is returned when the headers do not connect, have invalid proof of
+// work, do not match the commitments, or the chain ends without reaching the
+// minimum work.
+func (s *headersPresyncState) processHeaders(headers []*wire.BlockHeader, moreAvailable bool) ([]*wire.BlockHeader, error) {
+	switch s.phase {
+	case presyncPhaseCollect:
+		return nil, s.collectHeaders(headers, moreAvailable)
+	case presyncPhaseRedownload:
+		return s.redownloadHeaders(headers, moreAvailable)
+	}
+	return nil, fmt.Errorf("unable to process headers in phase %v", s.phase)
+}
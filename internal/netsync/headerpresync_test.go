@@ -0,0 +1,321 @@
+// Copyright (c) 2023 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package netsync
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/EXCCoin/exccd/blockchain/standalone/v2"
+	"github.com/EXCCoin/exccd/blockchain/v4"
+	"github.com/EXCCoin/exccd/chaincfg/v3"
+	"github.com/EXCCoin/exccd/database/v3"
+	_ "github.com/EXCCoin/exccd/database/v3/ffldb"
+	peerpkg "github.com/EXCCoin/exccd/peer/v3"
+	"github.com/EXCCoin/exccd/wire"
+	"github.com/syndtr/goleveldb/leveldb"
+)
+
+// presyncTestChain returns a chain of the provided number of headers that
+// builds on the provided header and have valid proof of work per the provided
+// network parameters.  The salt is used to create distinct chains.
+func presyncTestChain(t *testing.T, params *chaincfg.Params, start *wire.BlockHeader, numHeaders int, salt uint32) []*wire.BlockHeader {
+	t.Helper()
+
+	headers := make([]*wire.BlockHeader, 0, numHeaders)
+	prev := start
+	for i := 0; i < numHeaders; i++ {
+		header := &wire.BlockHeader{
+			Version:      prev.Version,
+			PrevBlock:    prev.BlockHash(),
+			Bits:         params.PowLimitBits,
+			Height:       prev.Height + 1,
+			Timestamp:    prev.Timestamp.Add(params.TargetTimePerBlock),
+			ExtraData:    [32]byte{byte(salt), byte(salt >> 8)},
+			StakeVersion: prev.StakeVersion,
+		}
+		for {
+			err := standalone.CheckProofOfWork(header, header.Bits, params)
+			if err == nil {
+				break
+			}
+			header.Nonce++
+		}
+		headers = append(headers, header)
+		prev = header
+	}
+	return headers
+}
+
+// chainWorkAt returns the cumulative work of the provided start work plus the
+// work of the provided headers.
+func chainWorkAt(startWork *big.Int, headers []*wire.BlockHeader) *big.Int {
+	work := new(big.Int).Set(startWork)
+	for _, header := range headers {
+		work.Add(work, standalone.CalcWork(header.Bits))
+	}
+	return work
+}
+
+// TestHeadersPresync ensures the headers presync only releases headers once a
+// chain with the minimum work has been provided twice and that peers providing
+// low-work or different chains are detected.
+func TestHeadersPresync(t *testing.T) {
+	params := chaincfg.RegNetParams()
+	start := &params.GenesisBlock.Header
+	startWork := standalone.CalcWork(start.Bits)
+	now := start.Timestamp.Add(time.Hour)
+
+	const numHeaders = 30
+	headers := presyncTestChain(t, params, start, numHeaders, 0)
+	minWork := chainWorkAt(startWork, headers[:25])
+
+	// newState returns a presync state that commits to the header at height 5.
+	newState := func() *headersPresyncState {
+		t.Helper()
+		state, err := newHeadersPresyncState(params, minWork, start,
+			startWork, now)
+		if err != nil {
+			t.Fatalf("unexpected error creating presync state: %v", err)
+		}
+		state.commitOffset = 5
+		return state
+	}
+
+	// Ensure a chain that reaches the minimum work is only released after it
+	// has been redownloaded.
+	state := newState()
+	ready, err := state.processHeaders(headers[:10], true)
+	if err != nil || len(ready) != 0 {
+		t.Fatalf("collect: unexpected result -- ready %d, err %v", len(ready),
+			err)
+	}
+	if state.phase != presyncPhaseCollect {
+		t.Fatalf("collect: unexpected phase %v", state.phase)
+	}
+	if got := state.locatorHash(); *got != headers[9].BlockHash() {
+		t.Fatalf("collect: mismatched locator hash %v", got)
+	}
+	ready, err = state.processHeaders(headers[10:], true)
+	if err != nil || len(ready) != 0 {
+		t.Fatalf("collect: unexpected result -- ready %d, err %v", len(ready),
+			err)
+	}
+	if state.phase != presyncPhaseRedownload {
+		t.Fatalf("collect: unexpected phase %v", state.phase)
+	}
+	if state.numCommitments != 1 {
+		t.Fatalf("collect: unexpected number of commitments %d",
+			state.numCommitments)
+	}
+	if got := state.locatorHash(); *got != start.BlockHash() {
+		t.Fatalf("redownload: mismatched locator hash %v", got)
+	}
+
+	// Ensure headers that do not connect are rejected.
+	_, err = state.processHeaders(headers[1:], true)
+	if !errors.Is(err, errPresyncNotConnected) {
+		t.Fatalf("redownload: unexpected error %v", err)
+	}
+
+	// Ensure all of the headers, including those after the one that reaches
+	// the minimum work, are released once the chain is redownloaded.
+	state = newState()
+	if _, err := state.processHeaders(headers, true); err != nil {
+		t.Fatalf("collect: unexpected error %v", err)
+	}
+	ready, err = state.processHeaders(headers[:20], true)
+	if err != nil || len(ready) != 0 {
+		t.Fatalf("redownload: unexpected result -- ready %d, err %v",
+			len(ready), err)
+	}
+	ready, err = state.processHeaders(headers[20:], false)
+	if err != nil {
+		t.Fatalf("redownload: unexpected error %v", err)
+	}
+	if state.phase != presyncPhaseDone {
+		t.Fatalf("redownload: unexpected phase %v", state.phase)
+	}
+	if len(ready) != numHeaders {
+		t.Fatalf("redownload: released %d headers, want %d", len(ready),
+			numHeaders)
+	}
+	for i, header := range ready {
+		if header != headers[i] {
+			t.Fatalf("redownload: mismatched released header %d", i)
+		}
+	}
+
+	// Ensure a chain that ends before reaching the minimum work is rejected.
+	state = newState()
+	_, err = state.processHeaders(headers[:20], false)
+	if !errors.Is(err, errPresyncLowWork) {
+		t.Fatalf("low work: unexpected error %v", err)
+	}
+
+	// Ensure a different chain provided during the redownload is rejected
+	// when it does not match the commitment.  Choose the salt of the other
+	// chain such that its commitment differs.
+	state = newState()
+	if _, err := state.processHeaders(headers, true); err != nil {
+		t.Fatalf("collect: unexpected error %v", err)
+	}
+	committedHash := headers[4].BlockHash()
+	wantBit := state.commitment(&committedHash)
+	for salt := uint32(1); ; salt++ {
+		other := presyncTestChain(t, params, start, 5, salt)
+		otherHash := other[4].BlockHash()
+		if state.commitment(&otherHash) == wantBit {
+			continue
+		}
+		_, err = state.processHeaders(other, true)
+		if err == nil {
+			t.Fatal("different chain: did not receive expected error")
+		}
+		break
+	}
+
+	// Ensure a chain with invalid proof of work is rejected.
+	state = newState()
+	badHeader := *headers[0]
+	for {
+		err := standalone.CheckProofOfWork(&badHeader, badHeader.Bits, params)
+		if err != nil {
+			break
+		}
+		badHeader.Nonce++
+	}
+	_, err = state.processHeaders([]*wire.BlockHeader{&badHeader}, true)
+	if err == nil {
+		t.Fatal("bad pow: did not receive expected error")
+	}
+
+	// Ensure a chain with more headers than possible is rejected.
+	state = newState()
+	state.maxCommitments = 0
+	_, err = state.processHeaders(headers, true)
+	if err == nil {
+		t.Fatal("max commitments: did not receive expected error")
+	}
+}
+
+// newPresyncTestChain returns a chain instance for the provided network
+// parameters that is backed by temporary databases.
+func newPresyncTestChain(t *testing.T, params *chaincfg.Params) *blockchain.BlockChain {
+	t.Helper()
+
+	dbPath := t.TempDir()
+	db, err := database.Create("ffldb", dbPath, params.Net)
+	if err != nil {
+		t.Fatalf("unable to create database: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	utxoDb, err := leveldb.OpenFile(t.TempDir(), nil)
+	if err != nil {
+		t.Fatalf("unable to create utxo database: %v", err)
+	}
+	t.Cleanup(func() { utxoDb.Close() })
+
+	utxoBackend := blockchain.NewLevelDbUtxoBackend(utxoDb)
+	chain, err := blockchain.New(context.Background(), &blockchain.Config{
+		DB:          db,
+		UtxoBackend: utxoBackend,
+		ChainParams: params,
+		TimeSource:  blockchain.NewMedianTime(),
+		UtxoCache: blockchain.NewUtxoCache(&blockchain.UtxoCacheConfig{
+			Backend:      utxoBackend,
+			FlushBlockDB: func() error { return nil },
+			MaxSize:      10 * 1024 * 1024,
+		}),
+	})
+	if err != nil {
+		t.Fatalf("unable to create chain: %v", err)
+	}
+	return chain
+}
+
+// TestLowWorkHeadersDisconnect ensures outbound peers that reach their best
+// known header with a chain that has less than the minimum known chain work
+// are disconnected while the initial sync is underway and that inbound peers
+// in the same situation, such as those that are behind, are tolerated without
+// the headers being processed.
+func TestLowWorkHeadersDisconnect(t *testing.T) {
+	params := *chaincfg.RegNetParams()
+	params.MinKnownChainWork = new(big.Int).Lsh(big.NewInt(1), 200)
+	m := New(&Config{
+		ChainParams: &params,
+		Chain:       newPresyncTestChain(t, &params),
+	})
+
+	// A headers message with fewer than the max number of headers means the
+	// peer has reached its best known header.
+	genesis := &params.GenesisBlock.Header
+	headers := presyncTestChain(t, &params, genesis, 5, 0)
+	msg := wire.NewMsgHeaders()
+	for _, header := range headers {
+		if err := msg.AddBlockHeader(header); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// isDisconnected returns whether or not the provided peer was
+	// disconnected.
+	isDisconnected := func(p *peerpkg.Peer) bool {
+		done := make(chan struct{})
+		go func() {
+			p.WaitForDisconnect()
+			close(done)
+		}()
+		select {
+		case <-done:
+			return true
+		case <-time.After(50 * time.Millisecond):
+			return false
+		}
+	}
+
+	peerCfg := &peerpkg.Config{Net: params.Net}
+	outbound, err := peerpkg.NewOutboundPeer(peerCfg, "10.0.0.1:18655")
+	if err != nil {
+		t.Fatal(err)
+	}
+	inbound := peerpkg.NewInboundPeer(peerCfg)
+	tests := []struct {
+		name             string
+		peer             *peerpkg.Peer
+		wantDisconnected bool
+	}{{
+		name:             "outbound peer",
+		peer:             outbound,
+		wantDisconnected: true,
+	}, {
+		name:             "inbound peer",
+		peer:             inbound,
+		wantDisconnected: false,
+	}}
+	for _, test := range tests {
+		m.peers[test.peer] = &syncMgrPeer{Peer: test.peer}
+		m.handleHeadersMsg(&headersMsg{headers: msg, peer: test.peer})
+		if got := isDisconnected(test.peer); got != test.wantDisconnected {
+			t.Fatalf("%s: unexpected disconnected state -- got %v, want %v",
+				test.name, got, test.wantDisconnected)
+		}
+		if m.peers[test.peer].presync != nil {
+			t.Fatalf("%s: unexpected headers presync", test.name)
+		}
+
+		// Ensure the low-work headers were not processed.
+		for _, header := range headers {
+			hash := header.BlockHash()
+			if m.cfg.Chain.HaveHeader(&hash) {
+				t.Fatalf("%s: low-work header %s was processed", test.name,
+					hash)
+			}
+		}
+	}
+}
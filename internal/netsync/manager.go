@@ -10,11 +10,13 @@ import (
 	"errors"
 	"fmt"
 	"math"
+	"math/big"
 	"runtime/debug"
 	"sync"
 	"time"
 
 	"github.com/EXCCoin/exccd/blockchain/stake/v4"
+	"github.com/EXCCoin/exccd/blockchain/standalone/v2"
 	"github.com/EXCCoin/exccd/blockchain/v4"
 	"github.com/EXCCoin/exccd/chaincfg/chainhash"
 	"github.com/EXCCoin/exccd/chaincfg/v3"
@@ -125,6 +127,22 @@ type notFoundMsg struct {
 	peer     *peerpkg.Peer
 }
 
+// cmpctBlockMsg packages a Decred cmpctblock message and the peer it came from
+// together so the event handler has access to that information.
+type cmpctBlockMsg struct {
+	cmpctBlock *wire.MsgCmpctBlock
+	peer       *peerpkg.Peer
+	reply      chan struct{}
+}
+
+// blockTxnMsg packages a Decred blocktxn message and the peer it came from
+// together so the event handler has access to that information.
+type blockTxnMsg struct {
+	blockTxn *wire.MsgBlockTxn
+	peer     *peerpkg.Peer
+	reply    chan struct{}
+}
+
 // donePeerMsg signifies a newly disconnected peer to the event handler.
 type donePeerMsg struct {
 	peer *peerpkg.Peer
@@ -199,6 +217,16 @@ type syncMgrPeer struct {
 	numConsecutiveOrphanHeaders int32
 
 	lastAnnouncedBlock *chainhash.Hash
+
+	// pendingCmpctBlock houses the state of the block that is currently being
+	// reconstructed from a compact block sent by the peer while waiting for
+	// the peer to provide the missing transactions.
+	pendingCmpctBlock *cmpctBlockState
+
+	// presync houses the state of the headers presync with the peer when it
+	// is providing a chain that does not yet have enough cumulative work to
+	// be processed.
+	presync *headersPresyncState
 }
 
 // headerSyncState houses the state used to track the header sync progress and
@@ -280,6 +308,11 @@ type SyncManager struct {
 	msgChan         chan interface{}
 	peers           map[*peerpkg.Peer]*syncMgrPeer
 
+	// cmpctHBPeers houses the peers that have been asked to announce new
+	// blocks via compact blocks in high-bandwidth mode ordered from the least
+	// to the most recent one to provide a new block.
+	cmpctHBPeers []*syncMgrPeer
+
 	// hdrSyncState houses the state used to track the initial header sync
 	// process and related stall handling.
 	hdrSyncState headerSyncState
@@ -615,6 +648,14 @@ func (m *SyncManager) handleNewPeerMsg(ctx context.Context, peer *peerpkg.Peer)
 		requestedBlocks: make(map[chainhash.Hash]struct{}),
 	}
 
+	// Let peers that support compact blocks know they may be requested.  The
+	// peer is only asked to announce new blocks via compact blocks once it
+	// has proven to be one of the first to provide them.
+	if peer.ProtocolVersion() >= wire.CompactBlocksVersion {
+		peer.QueueMessage(wire.NewMsgSendCmpct(false,
+			wire.CmpctBlockEncodingVersion), nil)
+	}
+
 	// Start syncing by choosing the best candidate if needed.
 	if isSyncCandidate && m.syncPeer == nil {
 		m.startSync()
@@ -641,6 +682,14 @@ func (m *SyncManager) handleDonePeerMsg(p *peerpkg.Peer) {
 	// Remove the peer from the list of candidate peers.
 	delete(m.peers, p)
 
+	// Remove the peer from the high-bandwidth compact block peers.
+	for i, hbPeer := range m.cmpctHBPeers {
+		if hbPeer == peer {
+			m.cmpctHBPeers = append(m.cmpctHBPeers[:i], m.cmpctHBPeers[i+1:]...)
+			break
+		}
+	}
+
 	// Remove requested transactions from the global map so that they will
 	// be fetched from elsewhere.
 	for txHash := range peer.requestedTxns {
@@ -876,6 +925,13 @@ func (m *SyncManager) handleBlockMsg(bmsg *blockMsg) {
 		m.rejectedTxns.Reset()
 	}
 
+	// Ask the peer to announce new blocks via compact blocks when it provided
+	// a block that extended the main chain while the chain was current since
+	// that indicates it is among the first peers to learn about new blocks.
+	if onMainChain && wasChainCurrent {
+		m.maybeUpdateCmpctHBPeers(peer)
+	}
+
 	// Update the latest block height for the peer to avoid stale heights when
 	// looking for future potential sync node candidacy.
 	//
@@ -907,6 +963,221 @@ func (m *SyncManager) handleBlockMsg(bmsg *blockMsg) {
 	}
 }
 
+// maybeUpdateCmpctHBPeers adds the provided peer as the most recent one to
+// provide a new block to the peers that are asked to announce new blocks via
+// compact blocks in high-bandwidth mode when it supports compact blocks.  The
+// least recent peer is asked to stop doing so when there are already the
+// maximum number of high-bandwidth peers.
+func (m *SyncManager) maybeUpdateCmpctHBPeers(peer *syncMgrPeer) {
+	if !peer.SupportsCmpctBlocks() {
+		return
+	}
+
+	// Move the peer to the end of the list when it is already a high-bandwidth
+	// peer.
+	hbPeers := m.cmpctHBPeers
+	for i, hbPeer := range hbPeers {
+		if hbPeer == peer {
+			copy(hbPeers[i:], hbPeers[i+1:])
+			hbPeers[len(hbPeers)-1] = peer
+			return
+		}
+	}
+
+	// Demote the least recent peer when the maximum number of high-bandwidth
+	// peers has been reached.
+	if len(hbPeers) >= maxCmpctHBPeers {
+		oldest := hbPeers[0]
+		log.Debugf("Removing %s as a high-bandwidth compact block peer",
+			oldest)
+		oldest.QueueMessage(wire.NewMsgSendCmpct(false,
+			wire.CmpctBlockEncodingVersion), nil)
+		copy(hbPeers, hbPeers[1:])
+		hbPeers = hbPeers[:len(hbPeers)-1]
+	}
+
+	log.Debugf("Selecting %s as a high-bandwidth compact block peer", peer)
+	peer.QueueMessage(wire.NewMsgSendCmpct(true,
+		wire.CmpctBlockEncodingVersion), nil)
+	m.cmpctHBPeers = append(hbPeers, peer)
+}
+
+// requestFullBlock requests the full block for the provided hash from the
+// peer.  It is used when a block could not be reconstructed from a compact
+// block.  The block must already be tracked in the request maps.
+func requestFullBlock(peer *syncMgrPeer, hash *chainhash.Hash) {
+	gdmsg := wire.NewMsgGetDataSizeHint(1)
+	gdmsg.AddInvVect(wire.NewInvVect(wire.InvTypeBlock, hash))
+	peer.QueueMessage(gdmsg, nil)
+}
+
+// processCmpctBlockState processes the fully reconstructed block in the
+// provided compact block state as if it had been received from the peer
+// directly.  The full block is requested instead when the reconstructed
+// transactions do not commit to the merkle roots in the header which can
+// happen due to short id collisions.
+func (m *SyncManager) processCmpctBlockState(peer *syncMgrPeer, state *cmpctBlockState) {
+	block := state.block()
+	if !cmpctMerkleRootsMatch(block) {
+		log.Debugf("Reconstructed compact block %v from %s does not match "+
+			"the merkle root -- requesting full block", state.hash, peer)
+		requestFullBlock(peer, &state.hash)
+		return
+	}
+
+	m.handleBlockMsg(&blockMsg{block: dcrutil.NewBlock(block), peer: peer.Peer})
+}
+
+// handleCmpctBlockMsg handles cmpctblock messages from all peers.  This entails
+// processing the header of the block, attempting to reconstruct the block from
+// the transactions in the mempool, and requesting any transactions that are
+// missing.
+func (m *SyncManager) handleCmpctBlockMsg(cmsg *cmpctBlockMsg) {
+	peer := lookupPeer(cmsg.peer, m.peers)
+	if peer == nil {
+		return
+	}
+
+	// Add the block to the cache of known inventory for the peer.  This helps
+	// avoid announcing the block to the peer that it is already known to have.
+	msg := cmsg.cmpctBlock
+	header := &msg.Header
+	blockHash := header.BlockHash()
+	peer.AddKnownInventory(wire.NewInvVect(wire.InvTypeBlock, &blockHash))
+
+	// Nothing more to do when the block is already known.
+	chain := m.cfg.Chain
+	_, requestedFromPeer := peer.requestedBlocks[blockHash]
+	if chain.HaveBlock(&blockHash) {
+		if requestedFromPeer {
+			delete(peer.requestedBlocks, blockHash)
+			delete(m.requestedBlocks, blockHash)
+		}
+		return
+	}
+
+	// Compact blocks are only requested and announced once the chain is
+	// current, so ignore any unsolicited ones prior to that point.
+	if !requestedFromPeer && !m.IsCurrent() {
+		return
+	}
+
+	// Request any missing headers starting from the best known header when the
+	// block does not connect to any known headers.  The block will be
+	// downloaded once the headers are processed.
+	if !chain.HaveHeader(&header.PrevBlock) {
+		if requestedFromPeer {
+			delete(peer.requestedBlocks, blockHash)
+			delete(m.requestedBlocks, blockHash)
+		}
+
+		log.Debugf("Requesting missing parents for compact block %s (height "+
+			"%d) received from peer %s", blockHash, header.Height, peer)
+		bestHeaderHash, _ := chain.BestHeader()
+		blkLocator := chain.BlockLocatorFromHash(&bestHeaderHash)
+		locator := chainBlockLocatorToHashes(blkLocator)
+		peer.PushGetHeadersMsg(locator, &zeroHash)
+		return
+	}
+
+	// Process the header.  Note that this is a no-op when the header is
+	// already known.
+	if err := chain.ProcessBlockHeader(header); err != nil {
+		log.Debugf("Failed to process compact block header %s from peer %s: "+
+			"%v -- disconnecting", blockHash, peer, err)
+		peer.Disconnect()
+		return
+	}
+
+	// Update the last announced block and height for the peer along with the
+	// sync height when it is exceeded.
+	blockHeight := int64(header.Height)
+	peer.lastAnnouncedBlock = &blockHash
+	peer.UpdateLastBlockHeight(blockHeight)
+	m.syncHeightMtx.Lock()
+	if blockHeight > m.syncHeight {
+		m.syncHeight = blockHeight
+	}
+	m.syncHeightMtx.Unlock()
+
+	// Nothing more to do when the block was already requested from another
+	// peer since it will be provided by that peer.
+	if _, ok := m.requestedBlocks[blockHash]; ok && !requestedFromPeer {
+		return
+	}
+
+	// Abandon any other block that is currently being reconstructed from a
+	// compact block sent by the peer so it will be requested again as needed.
+	if pending := peer.pendingCmpctBlock; pending != nil {
+		delete(peer.requestedBlocks, pending.hash)
+		delete(m.requestedBlocks, pending.hash)
+		peer.pendingCmpctBlock = nil
+	}
+
+	// Track the block as requested from the peer so that it is not requested
+	// from other peers and the reconstructed block, or the full block when
+	// the reconstruction fails, is accepted.
+	limitAdd(m.requestedBlocks, blockHash, maxRequestedBlocks)
+	limitAdd(peer.requestedBlocks, blockHash, maxRequestedBlocks)
+
+	// Attempt to reconstruct the block from the transactions in the mempool,
+	// which includes the votes, and fall back to requesting the full block
+	// when it is not possible.
+	txDescs := m.cfg.TxMemPool.TxDescs()
+	candidates := make([]*wire.MsgTx, 0, len(txDescs))
+	for _, txDesc := range txDescs {
+		candidates = append(candidates, txDesc.Tx.MsgTx())
+	}
+	state, err := newCmpctBlockState(msg, candidates)
+	if err != nil {
+		log.Debugf("Unable to reconstruct compact block from %s: %v -- "+
+			"requesting full block", peer, err)
+		requestFullBlock(peer, &blockHash)
+		return
+	}
+
+	// Request any missing transactions from the peer.
+	if !state.isComplete() {
+		log.Debugf("Requesting %d regular and %d stake transactions missing "+
+			"from compact block %v from %s", len(state.missing),
+			len(state.sMissing), blockHash, peer)
+		peer.pendingCmpctBlock = state
+		peer.QueueMessage(state.getBlockTxnMsg(), nil)
+		return
+	}
+
+	m.processCmpctBlockState(peer, state)
+}
+
+// handleBlockTxnMsg handles blocktxn messages from all peers.  This entails
+// completing the reconstruction of the block from the compact block that is
+// pending for the peer.
+func (m *SyncManager) handleBlockTxnMsg(bmsg *blockTxnMsg) {
+	peer := lookupPeer(bmsg.peer, m.peers)
+	if peer == nil {
+		return
+	}
+
+	// The remote peer is misbehaving when the transactions were not requested.
+	msg := bmsg.blockTxn
+	state := peer.pendingCmpctBlock
+	if state == nil || state.hash != msg.BlockHash {
+		log.Warnf("Got unrequested blocktxn for block %v from %s -- "+
+			"disconnecting", msg.BlockHash, peer)
+		peer.Disconnect()
+		return
+	}
+	peer.pendingCmpctBlock = nil
+
+	if err := state.fill(msg); err != nil {
+		log.Warnf("Invalid blocktxn from %s: %v -- disconnecting", peer, err)
+		peer.Disconnect()
+		return
+	}
+
+	m.processCmpctBlockState(peer, state)
+}
+
 // guessHeaderSyncProgress returns a percentage that is a guess of the progress
 // of the header sync progress for the given currently best known header based
 // on an algorithm that considers the total number of expected headers based on
@@ -966,6 +1237,100 @@ func (m *SyncManager) headerSyncProgress() float64 {
 	return math.Min(float64(header.Height)/float64(syncHeight), 1.0) * 100
 }
 
+// startHeadersPresync starts a headers presync with the peer for the chain that
+// builds on the provided known header.
+func (m *SyncManager) startHeadersPresync(peer *syncMgrPeer, startHash *chainhash.Hash) error {
+	chain := m.cfg.Chain
+	startHeader, err := chain.HeaderByHash(startHash)
+	if err != nil {
+		return err
+	}
+	startWork, err := chain.ChainWork(startHash)
+	if err != nil {
+		return err
+	}
+	presync, err := newHeadersPresyncState(m.cfg.ChainParams,
+		m.cfg.ChainParams.MinKnownChainWork, &startHeader, startWork,
+		m.cfg.TimeSource.AdjustedTime())
+	if err != nil {
+		return err
+	}
+
+	log.Debugf("Starting headers presync with peer %s from height %d",
+		peer, startHeader.Height)
+	peer.presync = presync
+	return nil
+}
+
+// handlePresyncHeaders processes the provided headers received from a peer that
+// is undergoing a headers presync.  Headers the presync releases before it is
+// done are processed immediately, while the final headers it releases once the
+// chain reaches the minimum chain work are returned so they go through the
+// normal header handling.  More headers are requested from the peer when the
+// presync is not done.
+//
+// The peer is disconnected when the presync fails.
+func (m *SyncManager) handlePresyncHeaders(peer *syncMgrPeer, headers []*wire.BlockHeader, moreAvailable bool) []*wire.BlockHeader {
+	presync := peer.presync
+	prevPhase := presync.phase
+	ready, err := presync.processHeaders(headers, moreAvailable)
+	if err != nil {
+		log.Debugf("Headers presync with peer %s failed: %v -- "+
+			"disconnecting", peer, err)
+		peer.presync = nil
+		peer.Disconnect()
+		return nil
+	}
+
+	// Reset the header sync progress stall timeout when the headers are not
+	// already synced since the presync is making progress.
+	if peer == m.syncPeer && !m.hdrSyncState.headersSynced {
+		m.hdrSyncState.resetStallTimeout()
+	}
+
+	if presync.phase == presyncPhaseDone {
+		log.Infof("Headers presync with peer %s complete at height %d", peer,
+			presync.redownloadTip.height)
+		peer.presync = nil
+		return ready
+	}
+	if prevPhase != presync.phase {
+		log.Infof("Headers presync with peer %s reached the minimum chain "+
+			"work at height %d -- redownloading headers", peer,
+			presync.collectTip.height)
+	} else {
+		tip := presync.tip()
+		log.Debugf("Headers presync with peer %s in %v phase at height %d",
+			peer, presync.phase, tip.height)
+	}
+
+	// Process the headers that are now known to be part of the chain that
+	// has enough cumulative work.
+	chain := m.cfg.Chain
+	for _, header := range ready {
+		err := chain.ProcessBlockHeader(header)
+		if err != nil {
+			log.Debugf("Failed to process block header %s from peer %s: %v "+
+				"-- disconnecting", header.BlockHash(), peer, err)
+			peer.presync = nil
+			peer.Disconnect()
+			return nil
+		}
+	}
+
+	// Request more headers starting after the most recent header received in
+	// the current phase.  Note that the headers received during the presync
+	// are not known to the chain, so the hash is added to the locator for the
+	// known header the chain builds on.
+	blkLocator := chain.BlockLocatorFromHash(&presync.start.hash)
+	locator := chainBlockLocatorToHashes(blkLocator)
+	if hash := presync.locatorHash(); *hash != presync.start.hash {
+		locator = append([]chainhash.Hash{*hash}, locator...)
+	}
+	peer.PushGetHeadersMsg(locator, &zeroHash)
+	return nil
+}
+
 // handleHeadersMsg handles headers messages from all peers.
 func (m *SyncManager) handleHeadersMsg(hmsg *headersMsg) {
 	peer := lookupPeer(hmsg.peer, m.peers)
@@ -981,6 +1346,27 @@ func (m *SyncManager) handleHeadersMsg(hmsg *headersMsg) {
 		return
 	}
 
+	// A peer only sends fewer than the maximum number of headers per message
+	// when it has reached its best known header.
+	receivedMaxHeaders := numHeaders == wire.MaxBlockHeadersPerMsg
+
+	// Headers from peers that are undergoing a headers presync are handled by
+	// the presync until the chain they build reaches the minimum chain work,
+	// at which point the final headers it releases are handled normally.
+	//
+	// Note that headers which do not connect to the most recent header of the
+	// presync, such as block announcements, are ignored during the presync.
+	if peer.presync != nil {
+		if !peer.presync.connects(headers[0]) {
+			return
+		}
+		headers = m.handlePresyncHeaders(peer, headers, receivedMaxHeaders)
+		numHeaders = len(headers)
+		if numHeaders == 0 {
+			return
+		}
+	}
+
 	// Handle the case where the first header does not connect to any known
 	// headers specially.
 	chain := m.cfg.Chain
@@ -1047,6 +1433,54 @@ func (m *SyncManager) handleHeadersMsg(hmsg *headersMsg) {
 		headerHashes = append(headerHashes, header.BlockHash())
 	}
 
+	// Avoid processing headers for chains that have less cumulative work than
+	// the minimum value already known to have been achieved on the network a
+	// priori since they would otherwise allow peers to cheaply exhaust memory
+	// by providing long low-work chains.  Instead, start a headers presync
+	// that only processes the headers once the chain is proven to reach the
+	// minimum when the peer has more headers.
+	//
+	// Otherwise, the peer has reached its best known header, so disconnect
+	// outbound peers while the initial sync is still underway and ignore the
+	// headers from the remaining peers, such as inbound peers that are still
+	// syncing themselves.
+	minKnownWork := m.cfg.ChainParams.MinKnownChainWork
+	if minKnownWork != nil {
+		workSum, err := chain.ChainWork(&firstHeader.PrevBlock)
+		if err != nil {
+			log.Errorf("Unable to determine work for header %s: %v",
+				firstHeader.PrevBlock, err)
+			return
+		}
+		workSum = new(big.Int).Set(workSum)
+		for _, header := range headers {
+			workSum.Add(workSum, standalone.CalcWork(header.Bits))
+		}
+		if workSum.Cmp(minKnownWork) < 0 {
+			if !receivedMaxHeaders {
+				if !chain.IsCurrent() && !peer.Inbound() {
+					log.Debugf("Best known chain for peer %s has too little "+
+						"cumulative work -- disconnecting", peer)
+					peer.Disconnect()
+					return
+				}
+
+				log.Debugf("Ignoring headers with too little cumulative "+
+					"work from peer %s", peer)
+				return
+			}
+
+			err := m.startHeadersPresync(peer, &firstHeader.PrevBlock)
+			if err != nil {
+				log.Errorf("Unable to start headers presync with peer %s: %v",
+					peer, err)
+				return
+			}
+			m.handlePresyncHeaders(peer, headers, receivedMaxHeaders)
+			return
+		}
+	}
+
 	// Save the current best known header height prior to processing the headers
 	// so the code later is able to determine if any new useful headers were
 	// provided.
@@ -1111,26 +1545,6 @@ func (m *SyncManager) handleHeadersMsg(hmsg *headersMsg) {
 		m.syncHeightMtx.Unlock()
 	}
 
-	// Disconnect outbound peers that have less cumulative work than the minimum
-	// value already known to have been achieved on the network a priori while
-	// the initial sync is still underway.  This is determined by noting that a
-	// peer only sends fewer than the maximum number of headers per message when
-	// it has reached its best known header.
-	isChainCurrent := chain.IsCurrent()
-	receivedMaxHeaders := len(headers) == wire.MaxBlockHeadersPerMsg
-	if !isChainCurrent && !peer.Inbound() && !receivedMaxHeaders {
-		minKnownWork := m.cfg.ChainParams.MinKnownChainWork
-		if minKnownWork != nil {
-			workSum, err := chain.ChainWork(finalReceivedHash)
-			if err == nil && workSum.Cmp(minKnownWork) < 0 {
-				log.Debugf("Best known chain for peer %s has too little "+
-					"cumulative work -- disconnecting", peer)
-				peer.Disconnect()
-				return
-			}
-		}
-	}
-
 	// Request more headers when the peer announced the maximum number of
 	// headers that can be sent in a single message since it probably has more.
 	if receivedMaxHeaders {
@@ -1144,6 +1558,7 @@ func (m *SyncManager) handleHeadersMsg(hmsg *headersMsg) {
 
 	// Consider the headers synced once the sync peer sends a message with a
 	// final header that is within a few blocks of the sync height.
+	isChainCurrent := chain.IsCurrent()
 	if !headersSynced && peer == m.syncPeer {
 		const syncHeightFetchOffset = 6
 		if int64(finalHeader.Height)+syncHeightFetchOffset > syncHeight {
@@ -1189,7 +1604,14 @@ func (m *SyncManager) handleHeadersMsg(hmsg *headersMsg) {
 				continue
 			}
 
-			iv := wire.NewInvVect(wire.InvTypeBlock, hash)
+			//
+			// Prefer compact blocks when the peer supports them since the
+			// transactions are likely already available in the mempool.
+			invType := wire.InvTypeBlock
+			if peer.SupportsCmpctBlocks() {
+				invType = wire.InvTypeCmpctBlock
+			}
+			iv := wire.NewInvVect(invType, hash)
 			limitAdd(m.requestedBlocks, *hash, maxRequestedBlocks)
 			limitAdd(peer.requestedBlocks, *hash, maxRequestedBlocks)
 			gdmsg.AddInvVect(iv)
@@ -1217,11 +1639,15 @@ func (m *SyncManager) handleNotFoundMsg(nfmsg *notFoundMsg) {
 		// verify the hash was actually announced by the peer
 		// before deleting from the global requested maps.
 		switch inv.Type {
-		case wire.InvTypeBlock:
+		case wire.InvTypeBlock, wire.InvTypeCmpctBlock:
 			if _, exists := peer.requestedBlocks[inv.Hash]; exists {
 				delete(peer.requestedBlocks, inv.Hash)
 				delete(m.requestedBlocks, inv.Hash)
 			}
+			pending := peer.pendingCmpctBlock
+			if pending != nil && pending.hash == inv.Hash {
+				peer.pendingCmpctBlock = nil
+			}
 		case wire.InvTypeTx:
 			if _, exists := peer.requestedTxns[inv.Hash]; exists {
 				delete(peer.requestedTxns, inv.Hash)
@@ -1401,6 +1827,20 @@ out:
 				case <-ctx.Done():
 				}
 
+			case *cmpctBlockMsg:
+				m.handleCmpctBlockMsg(msg)
+				select {
+				case msg.reply <- struct{}{}:
+				case <-ctx.Done():
+				}
+
+			case *blockTxnMsg:
+				m.handleBlockTxnMsg(msg)
+				select {
+				case msg.reply <- struct{}{}:
+				case <-ctx.Done():
+				}
+
 			case *invMsg:
 				m.handleInvMsg(msg)
 
@@ -1503,6 +1943,26 @@ func (m *SyncManager) QueueBlock(block *dcrutil.Block, peer *peerpkg.Peer, done
 	}
 }
 
+// QueueCmpctBlock adds the passed cmpctblock message and peer to the event
+// handling queue.
+func (m *SyncManager) QueueCmpctBlock(cmpctBlock *wire.MsgCmpctBlock, peer *peerpkg.Peer, done chan struct{}) {
+	select {
+	case m.msgChan <- &cmpctBlockMsg{cmpctBlock: cmpctBlock, peer: peer, reply: done}:
+	case <-m.quit:
+		done <- struct{}{}
+	}
+}
+
+// QueueBlockTxn adds the passed blocktxn message and peer to the event
+// handling queue.
+func (m *SyncManager) QueueBlockTxn(blockTxn *wire.MsgBlockTxn, peer *peerpkg.Peer, done chan struct{}) {
+	select {
+	case m.msgChan <- &blockTxnMsg{blockTxn: blockTxn, peer: peer, reply: done}:
+	case <-m.quit:
+		done <- struct{}{}
+	}
+}
+
 // QueueInv adds the passed inv message and peer to the event handling queue.
 func (m *SyncManager) QueueInv(inv *wire.MsgInv, peer *peerpkg.Peer) {
 	select {
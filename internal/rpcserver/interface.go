@@ -20,6 +20,7 @@ import (
 	"github.com/EXCCoin/exccd/gcs/v3"
 	"github.com/EXCCoin/exccd/internal/mempool"
 	"github.com/EXCCoin/exccd/internal/mining"
+	"github.com/EXCCoin/exccd/internal/staging/banmanager"
 	"github.com/EXCCoin/exccd/peer/v3"
 	"github.com/EXCCoin/exccd/rpc/jsonrpc/types/v3"
 	"github.com/EXCCoin/exccd/txscript/v4/stdaddr"
@@ -60,6 +61,57 @@ type Peer interface {
 	// BanScore returns the current integer value that represents how close
 	// the peer is to being banned.
 	BanScore() uint32
+
+	// ConnType returns a human-readable description of the type of the
+	// connection to the peer such as inbound or block-relay-only.
+	ConnType() string
+
+	// MappedAS returns the autonomous system number the address of the peer
+	// is mapped to by the AS map in use or 0 when it is not mapped.
+	MappedAS() uint32
+
+	// MsgStats returns the number of messages and bytes that have been sent
+	// to and received from the peer per message type.
+	MsgStats() *MsgStats
+}
+
+// MsgStat houses the number of messages of a given type that have been sent or
+// received along with their total size in bytes.
+type MsgStat struct {
+	Msgs  uint64
+	Bytes uint64
+}
+
+// MsgStats houses the number of messages and bytes that have been sent and
+// received keyed by message type.
+type MsgStats struct {
+	Sent map[string]MsgStat
+	Recv map[string]MsgStat
+}
+
+// UploadTarget describes the state of the target for the maximum number of
+// bytes to send to peers per cycle.
+type UploadTarget struct {
+	// Timeframe is the duration of each cycle.
+	Timeframe time.Duration
+
+	// Target is the maximum number of bytes to send per cycle.  It is zero
+	// when there is no target.
+	Target uint64
+
+	// TargetReached indicates the number of bytes sent during the current
+	// cycle has reached the target.
+	TargetReached bool
+
+	// ServeHistoricalBlocks indicates historical blocks are still served to
+	// peers that are not whitelisted.
+	ServeHistoricalBlocks bool
+
+	// BytesLeftInCycle and TimeLeftInCycle are the number of bytes that may
+	// still be sent and the time that remains in the current cycle,
+	// respectively.  They are zero when there is no target.
+	BytesLeftInCycle uint64
+	TimeLeftInCycle  time.Duration
 }
 
 // AddrManager represents an address manager for use with the RPC server.
@@ -111,6 +163,14 @@ type ConnManager interface {
 	// network for all peers.
 	NetTotals() (uint64, uint64)
 
+	// MsgStats returns the number of messages and bytes that have been sent
+	// and received across the network for all peers per message type.
+	MsgStats() *MsgStats
+
+	// UploadTarget returns the state of the target for the maximum number of
+	// bytes to send to peers per cycle.
+	UploadTarget() *UploadTarget
+
 	// ConnectedPeers returns an array consisting of all connected peers.
 	ConnectedPeers() []Peer
 
@@ -136,6 +196,21 @@ type ConnManager interface {
 
 	// Lookup defines the DNS lookup function to be used.
 	Lookup(host string) ([]net.IP, error)
+
+	// BanSubnet bans the provided subnet until the provided time and
+	// disconnects any connected peers within it.  The ban is persisted so it
+	// survives restarts.
+	BanSubnet(subnet *net.IPNet, until time.Time) error
+
+	// UnbanSubnet removes the ban for the provided subnet.  Attempting to
+	// remove a ban for a subnet that is not banned will return an error.
+	UnbanSubnet(subnet *net.IPNet) error
+
+	// BannedSubnets returns all subnets that are currently banned.
+	BannedSubnets() ([]banmanager.BanEntry, error)
+
+	// ClearBans removes all bans.
+	ClearBans() error
 }
 
 // SyncManager represents a sync manager for use with the RPC server.
@@ -384,6 +459,10 @@ type Chain interface {
 	// parent of the current tip.
 	TipGeneration() ([]chainhash.Hash, error)
 
+	// UtxoCacheInfo returns information about the state and performance of the
+	// utxo cache.
+	UtxoCacheInfo() *blockchain.UtxoCacheInfo
+
 	// IsTreasuryAgendaActive returns whether or not the treasury agenda vote, as
 	// defined in DCP0006, has passed and is now active for the block AFTER the
 	// given block.
@@ -443,6 +522,47 @@ type FeeEstimator interface {
 	EstimateFee(targetConfs int32) (dcrutil.Amount, error)
 }
 
+// IndexInfo describes the state of an optional index.
+type IndexInfo struct {
+	// Name is the name of the index as accepted by IndexManager.
+	Name string
+
+	// Height and Hash identify the block the index is synced to.  They are
+	// not set for indexes that are being dropped.
+	Height int64
+	Hash   chainhash.Hash
+
+	// Synced indicates the index has caught up to the main chain and is
+	// receiving updates for new blocks.
+	Synced bool
+
+	// Dropping indicates the index was disabled and is being dropped.
+	Dropping bool
+
+	// DiskUsage is the number of bytes the keys and values of the entries of
+	// the index occupy in the database.
+	DiskUsage int64
+}
+
+// IndexManager provides an interface for enabling and disabling optional
+// indexes while the server is running and querying their state.
+//
+// The interface contract requires that all of these methods are safe for
+// concurrent access.
+type IndexManager interface {
+	// EnableIndex enables the optional index with the provided name.  The
+	// index catches up to the main chain in the background.
+	EnableIndex(name string) error
+
+	// DisableIndex disables the optional index with the provided name and
+	// drops it from the database in the background.
+	DisableIndex(name string) error
+
+	// IndexInfo returns the state of the optional indexes that are enabled or
+	// being dropped, ordered by name.
+	IndexInfo() ([]IndexInfo, error)
+}
+
 // LogManager represents a log manager for use with the RPC server.
 //
 // The interface contract does NOT require that these methods are safe for
@@ -661,6 +781,175 @@ type TxIndexer interface {
 	Entry(hash *chainhash.Hash) (*indexers.TxIndexEntry, error)
 }
 
+// SpenderIndexer provides an interface for retrieving the transaction that
+// spends a given output.
+//
+// The interface contract requires that all of these methods are safe for
+// concurrent access.
+type SpenderIndexer interface {
+	// Name returns the human-readable name of the index.
+	Name() string
+
+	// Tip returns the current index tip.
+	Tip() (int64, *chainhash.Hash, error)
+
+	// WaitForSync subscribes clients for the next index sync update.
+	WaitForSync() chan bool
+
+	// Entry returns details about the transaction that spends the provided
+	// outpoint from the spender index.  When the outpoint has not been spent in
+	// the main chain, nil must be returned for both the entry and the error.
+	Entry(outpoint *wire.OutPoint) (*indexers.SpenderIndexEntry, error)
+}
+
+// AddrBalanceIndexer provides an interface for retrieving the balances,
+// unspent outputs, and deltas of addresses.
+//
+// The interface contract requires that all of these methods are safe for
+// concurrent access.
+type AddrBalanceIndexer interface {
+	// Name returns the human-readable name of the index.
+	Name() string
+
+	// Tip returns the current index tip.
+	Tip() (int64, *chainhash.Hash, error)
+
+	// WaitForSync subscribes clients for the next index sync update.
+	WaitForSync() chan bool
+
+	// Balance returns the confirmed totals of the provided address.
+	Balance(addr stdaddr.Address) (*indexers.AddrBalance, error)
+
+	// Utxos returns the confirmed unspent outputs paid to the provided
+	// address according to the specified number to skip and number
+	// requested.  It also returns the number actually skipped.
+	Utxos(addr stdaddr.Address, numToSkip, numRequested uint32) ([]indexers.AddrUtxo, uint32, error)
+
+	// Deltas returns the confirmed credits and debits of the provided address
+	// according to the specified number to skip and number requested.  It
+	// also returns the number actually skipped.
+	Deltas(addr stdaddr.Address, numToSkip, numRequested uint32) ([]indexers.AddrDelta, uint32, error)
+
+	// UnconfirmedDeltas returns the credits and debits of the provided address
+	// made by transactions in the memory pool.
+	UnconfirmedDeltas(addr stdaddr.Address) []indexers.AddrDelta
+}
+
+// BlockStatsIndexer provides an interface for retrieving statistics about the
+// transactions in blocks.
+//
+// The interface contract requires that all of these methods are safe for
+// concurrent access.
+type BlockStatsIndexer interface {
+	// Name returns the human-readable name of the index.
+	Name() string
+
+	// Stats returns the statistics for the block with the provided hash.  When
+	// there is no entry for the block, nil must be returned for both the
+	// statistics and the error.
+	Stats(hash *chainhash.Hash) (*indexers.BlockStats, error)
+}
+
+// CoinStatsIndexer provides an interface for retrieving statistics about the
+// unspent transaction output set as of blocks in the main chain.
+//
+// The interface contract requires that all of these methods are safe for
+// concurrent access.
+type CoinStatsIndexer interface {
+	// Name returns the human-readable name of the index.
+	Name() string
+
+	// Tip returns the current index tip.
+	Tip() (int64, *chainhash.Hash, error)
+
+	// WaitForSync subscribes clients for the next index sync update.
+	WaitForSync() chan bool
+
+	// Stats returns the statistics of the unspent transaction output set as
+	// of the block with the provided hash.  When there is no entry for the
+	// block, nil must be returned for both the statistics and the error.
+	Stats(hash *chainhash.Hash) (*indexers.CoinStats, error)
+}
+
+// CFHeaderIndexer provides an interface for retrieving the filter hashes and
+// filter headers of blocks in the main chain.
+//
+// The interface contract requires that all of these methods are safe for
+// concurrent access.
+type CFHeaderIndexer interface {
+	// Name returns the human-readable name of the index.
+	Name() string
+
+	// Tip returns the current index tip.
+	Tip() (int64, *chainhash.Hash, error)
+
+	// WaitForSync subscribes clients for the next index sync update.
+	WaitForSync() chan bool
+
+	// Entry returns the filter hash and filter header for the block with the
+	// provided hash.  When there is no entry for the block, nil must be
+	// returned for both the entry and the error.
+	Entry(hash *chainhash.Hash) (*indexers.CFHeaderEntry, error)
+}
+
+// ScriptIndexer provides an interface for retrieving the outputs with scripts
+// that do not decode to addresses by script and the null data outputs by the
+// data they carry.
+//
+// The interface contract requires that all of these methods are safe for
+// concurrent access.
+type ScriptIndexer interface {
+	// Name returns the human-readable name of the index.
+	Name() string
+
+	// Tip returns the current index tip.
+	Tip() (int64, *chainhash.Hash, error)
+
+	// WaitForSync subscribes clients for the next index sync update.
+	WaitForSync() chan bool
+
+	// OutputsByScript returns the outputs with the provided script after
+	// skipping the requested number of them along with the number of outputs
+	// that were skipped.
+	OutputsByScript(script []byte, numToSkip, numRequested uint32) ([]indexers.ScriptOutput, uint32, error)
+
+	// OutputsByScriptHash returns the outputs with a script that has the
+	// provided hash after skipping the requested number of them along with
+	// the number of outputs that were skipped.
+	OutputsByScriptHash(scriptHash *chainhash.Hash, numToSkip, numRequested uint32) ([]indexers.ScriptOutput, uint32, error)
+
+	// OutputsByNullDataPrefix returns the null data outputs that carry data
+	// beginning with the provided prefix after skipping the requested number
+	// of them along with the number of outputs that were skipped.
+	OutputsByNullDataPrefix(prefix []byte, numToSkip, numRequested uint32) ([]indexers.ScriptOutput, uint32, error)
+}
+
+// TicketIndexer provides an interface for retrieving the lifecycle of tickets.
+//
+// The interface contract requires that all of these methods are safe for
+// concurrent access.
+type TicketIndexer interface {
+	// Name returns the human-readable name of the index.
+	Name() string
+
+	// Tip returns the current index tip.
+	Tip() (int64, *chainhash.Hash, error)
+
+	// WaitForSync subscribes clients for the next index sync update.
+	WaitForSync() chan bool
+
+	// Entry returns the lifecycle details of the provided ticket from the
+	// ticket index.  When the ticket has not been purchased in the main chain,
+	// nil must be returned for both the entry and the error.
+	Entry(hash *chainhash.Hash) (*indexers.TicketEntry, error)
+
+	// TicketsForAddress returns the lifecycle details of the tickets
+	// associated with the provided address according to the specified number
+	// to skip and number requested.  It also returns the number actually
+	// skipped.
+	TicketsForAddress(addr stdaddr.Address, numToSkip, numRequested uint32) ([]indexers.TicketEntry, uint32, error)
+}
+
 // NtfnManager provides an interface for processing and sending chain
 // notifications.
 //
@@ -668,8 +957,8 @@ type TxIndexer interface {
 // concurrent access.
 type NtfnManager interface {
 	// NotifyBlockConnected passes a block newly-connected to the manager
-	// for processing.
-	NotifyBlockConnected(block *dcrutil.Block)
+	// for processing along with the scripts of the outputs it spends.
+	NotifyBlockConnected(block *dcrutil.Block, prevScripts indexers.PrevScripter)
 
 	// NotifyBlockDisconnected passes a block disconnected to the manager
 	// for processing.
@@ -41,6 +41,7 @@ import (
 	"github.com/EXCCoin/exccd/blockchain/stake/v4"
 	"github.com/EXCCoin/exccd/blockchain/standalone/v2"
 	"github.com/EXCCoin/exccd/blockchain/v4"
+	"github.com/EXCCoin/exccd/blockchain/v4/indexers"
 	"github.com/EXCCoin/exccd/chaincfg/chainhash"
 	"github.com/EXCCoin/exccd/chaincfg/v3"
 	"github.com/EXCCoin/exccd/database/v3"
@@ -49,6 +50,7 @@ import (
 	"github.com/EXCCoin/exccd/dcrutil/v4"
 	"github.com/EXCCoin/exccd/internal/mempool"
 	"github.com/EXCCoin/exccd/internal/mining"
+	"github.com/EXCCoin/exccd/internal/staging/banmanager"
 	"github.com/EXCCoin/exccd/internal/version"
 	"github.com/EXCCoin/exccd/rpc/jsonrpc/types/v3"
 	"github.com/EXCCoin/exccd/txscript/v4"
@@ -108,6 +110,14 @@ const (
 	// syncWait is the maximum time in seconds to wait for an index
 	// to sync with the main chain.
 	syncWait = time.Second * 3
+
+	// maxCFiltersPerRange is the maximum number of filters that may be
+	// requested at once with the getcfilterv2range RPC.
+	maxCFiltersPerRange = 1000
+
+	// maxCFHeadersPerRange is the maximum number of filter headers that may be
+	// requested at once with the getcfilterheaders RPC.
+	maxCFHeadersPerRange = 2000
 )
 
 var (
@@ -157,11 +167,14 @@ var rpcHandlers map[types.Method]commandHandler
 var rpcHandlersBeforeInit = map[types.Method]commandHandler{
 	"addnode":               handleAddNode,
 	"createrawsstx":         handleCreateRawSStx,
+	"clearbanned":           handleClearBanned,
 	"createrawssrtx":        handleCreateRawSSRtx,
 	"createrawtransaction":  handleCreateRawTransaction,
 	"debuglevel":            handleDebugLevel,
 	"decoderawtransaction":  handleDecodeRawTransaction,
 	"decodescript":          handleDecodeScript,
+	"disableindex":          handleDisableIndex,
+	"enableindex":           handleEnableIndex,
 	"estimatefee":           handleEstimateFee,
 	"estimatesmartfee":      handleEstimateSmartFee,
 	"estimatestakediff":     handleEstimateStakeDiff,
@@ -174,6 +187,10 @@ var rpcHandlersBeforeInit = map[types.Method]commandHandler{
 	"existsmissedtickets":   handleExistsMissedTickets,
 	"generate":              handleGenerate,
 	"getaddednodeinfo":      handleGetAddedNodeInfo,
+	"getaddressbalance":     handleGetAddressBalance,
+	"getaddressdeltas":      handleGetAddressDeltas,
+	"getaddresstickets":     handleGetAddressTickets,
+	"getaddressutxos":       handleGetAddressUtxos,
 	"getbestblock":          handleGetBestBlock,
 	"getbestblockhash":      handleGetBestBlockHash,
 	"getblock":              handleGetBlock,
@@ -181,8 +198,11 @@ var rpcHandlersBeforeInit = map[types.Method]commandHandler{
 	"getblockcount":         handleGetBlockCount,
 	"getblockhash":          handleGetBlockHash,
 	"getblockheader":        handleGetBlockHeader,
+	"getblockstats":         handleGetBlockStats,
 	"getblocksubsidy":       handleGetBlockSubsidy,
+	"getcfilterheaders":     handleGetCFilterHeaders,
 	"getcfilterv2":          handleGetCFilterV2,
+	"getcfilterv2range":     handleGetCFilterV2Range,
 	"getchaintips":          handleGetChainTips,
 	"getcoinsupply":         handleGetCoinSupply,
 	"getconnectioncount":    handleGetConnectionCount,
@@ -191,25 +211,31 @@ var rpcHandlersBeforeInit = map[types.Method]commandHandler{
 	"getgenerate":           handleGetGenerate,
 	"gethashespersec":       handleGetHashesPerSec,
 	"getheaders":            handleGetHeaders,
+	"getindexinfo":          handleGetIndexInfo,
 	"getinfo":               handleGetInfo,
 	"getmempoolinfo":        handleGetMempoolInfo,
 	"getmininginfo":         handleGetMiningInfo,
+	"getnetmsgstats":        handleGetNetMsgStats,
 	"getnettotals":          handleGetNetTotals,
 	"getnetworkhashps":      handleGetNetworkHashPS,
 	"getnetworkinfo":        handleGetNetworkInfo,
 	"getpeerinfo":           handleGetPeerInfo,
 	"getrawmempool":         handleGetRawMempool,
 	"getrawtransaction":     handleGetRawTransaction,
+	"getspendingtx":         handleGetSpendingTx,
 	"getstakedifficulty":    handleGetStakeDifficulty,
 	"getstakeversioninfo":   handleGetStakeVersionInfo,
 	"getstakeversions":      handleGetStakeVersions,
+	"getticketinfo":         handleGetTicketInfo,
 	"getticketpoolvalue":    handleGetTicketPoolValue,
 	"getvoteinfo":           handleGetVoteInfo,
 	"gettxout":              handleGetTxOut,
 	"gettxoutsetinfo":       handleGetTxOutSetInfo,
+	"getutxocacheinfo":      handleGetUtxoCacheInfo,
 	"getwork":               handleGetWork,
 	"help":                  handleHelp,
 	"invalidateblock":       handleInvalidateBlock,
+	"listbanned":            handleListBanned,
 	"livetickets":           handleLiveTickets,
 	"missedtickets":         handleMissedTickets,
 	"node":                  handleNode,
@@ -217,7 +243,9 @@ var rpcHandlersBeforeInit = map[types.Method]commandHandler{
 	"reconsiderblock":       handleReconsiderBlock,
 	"regentemplate":         handleRegenTemplate,
 	"searchrawtransactions": handleSearchRawTransactions,
+	"searchscripts":         handleSearchScripts,
 	"sendrawtransaction":    handleSendRawTransaction,
+	"setban":                handleSetBan,
 	"setgenerate":           handleSetGenerate,
 	"stop":                  handleStop,
 	"submitblock":           handleSubmitBlock,
@@ -351,6 +379,10 @@ var rpcLimited = map[string]struct{}{
 	"existslivetickets":     {},
 	"existsmempooltxs":      {},
 	"existsmissedtickets":   {},
+	"getaddressbalance":     {},
+	"getaddressdeltas":      {},
+	"getaddresstickets":     {},
+	"getaddressutxos":       {},
 	"getbestblock":          {},
 	"getbestblockhash":      {},
 	"getblock":              {},
@@ -358,28 +390,35 @@ var rpcLimited = map[string]struct{}{
 	"getblockcount":         {},
 	"getblockhash":          {},
 	"getblockheader":        {},
+	"getblockstats":         {},
 	"getblocksubsidy":       {},
+	"getcfilterheaders":     {},
 	"getcfilterv2":          {},
+	"getcfilterv2range":     {},
 	"getchaintips":          {},
 	"getcoinsupply":         {},
 	"getcurrentnet":         {},
 	"getdifficulty":         {},
 	"getheaders":            {},
 	"getinfo":               {},
+	"getnetmsgstats":        {},
 	"getnettotals":          {},
 	"getnetworkhashps":      {},
 	"getnetworkinfo":        {},
 	"getrawmempool":         {},
+	"getspendingtx":         {},
 	"getstakedifficulty":    {},
 	"getstakeversioninfo":   {},
 	"getstakeversions":      {},
 	"getrawtransaction":     {},
+	"getticketinfo":         {},
 	"gettxout":              {},
 	"getvoteinfo":           {},
 	"livetickets":           {},
 	"missedtickets":         {},
 	"regentemplate":         {},
 	"searchrawtransactions": {},
+	"searchscripts":         {},
 	"sendrawtransaction":    {},
 	"submitblock":           {},
 	"ticketfeeinfo":         {},
@@ -941,6 +980,15 @@ func handleCreateRawSStx(_ context.Context, s *Server, cmd interface{}) (interfa
 	return mtxHex, nil
 }
 
+// handleClearBanned implements the clearbanned command.
+func handleClearBanned(_ context.Context, s *Server, cmd interface{}) (interface{}, error) {
+	if err := s.cfg.ConnMgr.ClearBans(); err != nil {
+		return nil, rpcInternalError(err.Error(), "Failed to clear bans")
+	}
+
+	return nil, nil
+}
+
 // handleCreateRawSSRtx handles createrawssrtx commands.
 func handleCreateRawSSRtx(_ context.Context, s *Server, cmd interface{}) (interface{}, error) {
 	c := cmd.(*types.CreateRawSSRtxCmd)
@@ -1390,6 +1438,48 @@ func handleDecodeScript(_ context.Context, s *Server, cmd interface{}) (interfac
 	return reply, nil
 }
 
+// indexManager returns the index manager or an error suitable for returning
+// to the caller when it is not available.
+func (s *Server) indexManager() (IndexManager, error) {
+	if s.cfg.IndexManager == nil {
+		return nil, rpcInternalError("Index management is not available",
+			"Configuration")
+	}
+	return s.cfg.IndexManager, nil
+}
+
+// handleDisableIndex implements the disableindex command.
+func handleDisableIndex(_ context.Context, s *Server, cmd interface{}) (interface{}, error) {
+	c := cmd.(*types.DisableIndexCmd)
+	indexMgr, err := s.indexManager()
+	if err != nil {
+		return nil, err
+	}
+
+	err = indexMgr.DisableIndex(c.Name)
+	if err != nil {
+		return nil, rpcInvalidError("Unable to disable index %q: %v", c.Name,
+			err)
+	}
+	return nil, nil
+}
+
+// handleEnableIndex implements the enableindex command.
+func handleEnableIndex(_ context.Context, s *Server, cmd interface{}) (interface{}, error) {
+	c := cmd.(*types.EnableIndexCmd)
+	indexMgr, err := s.indexManager()
+	if err != nil {
+		return nil, err
+	}
+
+	err = indexMgr.EnableIndex(c.Name)
+	if err != nil {
+		return nil, rpcInvalidError("Unable to enable index %q: %v", c.Name,
+			err)
+	}
+	return nil, nil
+}
+
 // handleEstimateFee implements the estimatefee command.
 // TODO this is a very basic implementation.  It should be
 // modified to match the bitcoin-core one.
@@ -1497,7 +1587,8 @@ func handleEstimateStakeDiff(_ context.Context, s *Server, cmd interface{}) (int
 
 // handleExistsAddress implements the existsaddress command.
 func handleExistsAddress(_ context.Context, s *Server, cmd interface{}) (interface{}, error) {
-	if s.cfg.ExistsAddresser == nil {
+	existsAddrIndex := s.existsAddresser()
+	if existsAddrIndex == nil {
 		return nil, rpcInternalError("Exists address index disabled",
 			"Configuration")
 	}
@@ -1513,7 +1604,6 @@ func handleExistsAddress(_ context.Context, s *Server, cmd interface{}) (interfa
 	}
 
 	// Ensure the exists address index is synced.
-	existsAddrIndex := s.cfg.ExistsAddresser
 	tHeight, tHash, err := existsAddrIndex.Tip()
 	if err != nil {
 		return nil, rpcInternalError(err.Error(), "Tip")
@@ -1552,7 +1642,8 @@ sync:
 // TODO: Add an upper bound to the number of addresses that can be checked.
 // This will come with a major RPC version bump.
 func handleExistsAddresses(_ context.Context, s *Server, cmd interface{}) (interface{}, error) {
-	if s.cfg.ExistsAddresser == nil {
+	existsAddrIndex := s.existsAddresser()
+	if existsAddrIndex == nil {
 		return nil, rpcInternalError("Exists address index disabled",
 			"Configuration")
 	}
@@ -1570,7 +1661,6 @@ func handleExistsAddresses(_ context.Context, s *Server, cmd interface{}) (inter
 	}
 
 	// Ensure the exists address index is synced.
-	existsAddrIndex := s.cfg.ExistsAddresser
 	tHeight, tHash, err := existsAddrIndex.Tip()
 	if err != nil {
 		return nil, rpcInternalError(err.Error(), "Tip")
@@ -1892,6 +1982,259 @@ func handleGetAddedNodeInfo(_ context.Context, s *Server, cmd interface{}) (inte
 	return results, nil
 }
 
+// syncedAddrBalanceIndexer returns the address balance indexer once it is
+// synced with the main chain.  An error suitable for returning to the caller
+// is returned when the index is not enabled or is not synced.
+func (s *Server) syncedAddrBalanceIndexer() (AddrBalanceIndexer, error) {
+	addrBalIndex := s.cfg.AddrBalanceIndexer
+	if addrBalIndex == nil {
+		return nil, rpcInternalError("The address balance index must be "+
+			"enabled (specify --addrbalanceindex)", "Configuration")
+	}
+
+	// Ensure the address balance index is synced.
+	tHeight, tHash, err := addrBalIndex.Tip()
+	if err != nil {
+		return nil, rpcInternalError(err.Error(), "Tip")
+	}
+
+	chain := s.cfg.Chain
+
+	// Return an out-of-sync error if index is lagging a
+	// maximum reorg depth (6) blocks or more from the chain tip.
+	if chain.BestSnapshot().Height > (tHeight + 5) {
+		msg := fmt.Sprintf("%s: index not synced", addrBalIndex.Name())
+		return nil, rpcInternalError(msg, "Sync")
+	}
+
+sync:
+	for !chain.BestSnapshot().Hash.IsEqual(tHash) {
+		select {
+		case <-time.After(syncWait):
+			msg := fmt.Sprintf("%s: index not synced", addrBalIndex.Name())
+			return nil, rpcInternalError(msg, "Sync")
+		case <-addrBalIndex.WaitForSync():
+			break sync
+		}
+	}
+
+	return addrBalIndex, nil
+}
+
+// addrBalanceIndexError converts an error returned by the address balance
+// indexer to an error suitable for returning to the caller.
+func addrBalanceIndexError(err error, addr string, context string) error {
+	if errors.Is(err, indexers.ErrUnsupportedAddressType) {
+		return rpcInvalidError("Address %s is not supported by the address "+
+			"balance index", addr)
+	}
+	return rpcInternalError(err.Error(), context)
+}
+
+// addrBalancePagination returns the number of entries to skip and the number
+// of entries requested from the provided optional parameters of the address
+// balance index RPCs.
+func addrBalancePagination(skip, count *int) (uint32, uint32) {
+	numRequested := 100
+	if count != nil {
+		numRequested = *count
+		const maxCount = 10000
+		if numRequested < 0 {
+			numRequested = 1
+		} else if numRequested > maxCount {
+			numRequested = maxCount
+		}
+	}
+
+	var numToSkip int
+	if skip != nil && *skip > 0 {
+		numToSkip = *skip
+	}
+	return uint32(numToSkip), uint32(numRequested)
+}
+
+// handleGetAddressBalance implements the getaddressbalance command.
+func handleGetAddressBalance(_ context.Context, s *Server, cmd interface{}) (interface{}, error) {
+	c := cmd.(*types.GetAddressBalanceCmd)
+
+	// Attempt to decode the supplied address.  This also ensures the network
+	// encoded with the address matches the network the server is currently on.
+	addr, err := stdaddr.DecodeAddress(c.Address, s.cfg.ChainParams)
+	if err != nil {
+		return nil, rpcAddressKeyError("Could not decode address: %v", err)
+	}
+
+	addrBalIndex, err := s.syncedAddrBalanceIndexer()
+	if err != nil {
+		return nil, err
+	}
+
+	balance, err := addrBalIndex.Balance(addr)
+	if err != nil {
+		return nil, addrBalanceIndexError(err, c.Address,
+			"Failed to retrieve address balance")
+	}
+
+	// Sum the deltas made by transactions in the memory pool.
+	var unconfirmed int64
+	for _, delta := range addrBalIndex.UnconfirmedDeltas(addr) {
+		unconfirmed += delta.Amount
+	}
+
+	return &types.GetAddressBalanceResult{
+		Address:     c.Address,
+		Balance:     dcrutil.Amount(balance.Balance()).ToCoin(),
+		Received:    dcrutil.Amount(balance.Received).ToCoin(),
+		Sent:        dcrutil.Amount(balance.Sent).ToCoin(),
+		Unconfirmed: dcrutil.Amount(unconfirmed).ToCoin(),
+		NumUtxos:    balance.NumUtxos,
+	}, nil
+}
+
+// handleGetAddressDeltas implements the getaddressdeltas command.
+func handleGetAddressDeltas(_ context.Context, s *Server, cmd interface{}) (interface{}, error) {
+	c := cmd.(*types.GetAddressDeltasCmd)
+
+	// Attempt to decode the supplied address.  This also ensures the network
+	// encoded with the address matches the network the server is currently on.
+	addr, err := stdaddr.DecodeAddress(c.Address, s.cfg.ChainParams)
+	if err != nil {
+		return nil, rpcAddressKeyError("Could not decode address: %v", err)
+	}
+
+	addrBalIndex, err := s.syncedAddrBalanceIndexer()
+	if err != nil {
+		return nil, err
+	}
+
+	numToSkip, numRequested := addrBalancePagination(c.Skip, c.Count)
+	deltas, numSkipped, err := addrBalIndex.Deltas(addr, numToSkip,
+		numRequested)
+	if err != nil {
+		return nil, addrBalanceIndexError(err, c.Address,
+			"Failed to retrieve address deltas")
+	}
+
+	best := s.cfg.Chain.BestSnapshot()
+	results := make([]types.AddressDeltaResult, 0, len(deltas))
+	for i := range deltas {
+		delta := &deltas[i]
+		results = append(results, types.AddressDeltaResult{
+			Txid:          delta.TxHash.String(),
+			Index:         delta.Index,
+			IsInput:       delta.IsInput,
+			Amount:        dcrutil.Amount(delta.Amount).ToCoin(),
+			Height:        delta.BlockHeight,
+			Confirmations: 1 + best.Height - delta.BlockHeight,
+		})
+	}
+
+	// Add the deltas made by transactions in the memory pool after the
+	// confirmed deltas as needed depending on the requested counts.
+	includeMempool := c.IncludeMempool != nil && *c.IncludeMempool
+	if includeMempool && uint32(len(results)) < numRequested {
+		mpDeltas := addrBalIndex.UnconfirmedDeltas(addr)
+		mpToSkip := numToSkip - numSkipped
+		if mpToSkip > uint32(len(mpDeltas)) {
+			mpToSkip = uint32(len(mpDeltas))
+		}
+		mpDeltas = mpDeltas[mpToSkip:]
+		if remaining := numRequested - uint32(len(results)); uint32(len(mpDeltas)) > remaining {
+			mpDeltas = mpDeltas[:remaining]
+		}
+		for i := range mpDeltas {
+			delta := &mpDeltas[i]
+			results = append(results, types.AddressDeltaResult{
+				Txid:    delta.TxHash.String(),
+				Index:   delta.Index,
+				IsInput: delta.IsInput,
+				Amount:  dcrutil.Amount(delta.Amount).ToCoin(),
+			})
+		}
+	}
+
+	return results, nil
+}
+
+// handleGetAddressUtxos implements the getaddressutxos command.
+func handleGetAddressUtxos(_ context.Context, s *Server, cmd interface{}) (interface{}, error) {
+	c := cmd.(*types.GetAddressUtxosCmd)
+
+	// Attempt to decode the supplied address.  This also ensures the network
+	// encoded with the address matches the network the server is currently on.
+	addr, err := stdaddr.DecodeAddress(c.Address, s.cfg.ChainParams)
+	if err != nil {
+		return nil, rpcAddressKeyError("Could not decode address: %v", err)
+	}
+
+	addrBalIndex, err := s.syncedAddrBalanceIndexer()
+	if err != nil {
+		return nil, err
+	}
+
+	numToSkip, numRequested := addrBalancePagination(c.Skip, c.Count)
+	utxos, _, err := addrBalIndex.Utxos(addr, numToSkip, numRequested)
+	if err != nil {
+		return nil, addrBalanceIndexError(err, c.Address,
+			"Failed to retrieve address unspent outputs")
+	}
+
+	best := s.cfg.Chain.BestSnapshot()
+	results := make([]types.AddressUtxoResult, 0, len(utxos))
+	for i := range utxos {
+		utxo := &utxos[i]
+		results = append(results, types.AddressUtxoResult{
+			Txid:          utxo.OutPoint.Hash.String(),
+			Vout:          utxo.OutPoint.Index,
+			Tree:          utxo.OutPoint.Tree,
+			Amount:        dcrutil.Amount(utxo.Amount).ToCoin(),
+			Height:        utxo.BlockHeight,
+			Confirmations: 1 + best.Height - utxo.BlockHeight,
+		})
+	}
+	return results, nil
+}
+
+// handleGetAddressTickets implements the getaddresstickets command.
+func handleGetAddressTickets(_ context.Context, s *Server, cmd interface{}) (interface{}, error) {
+	c := cmd.(*types.GetAddressTicketsCmd)
+
+	// Attempt to decode the supplied address.  This also ensures the network
+	// encoded with the address matches the network the server is currently on.
+	addr, err := stdaddr.DecodeAddress(c.Address, s.cfg.ChainParams)
+	if err != nil {
+		return nil, rpcAddressKeyError("Could not decode address: %v", err)
+	}
+
+	ticketIndex, err := s.syncedTicketIndexer()
+	if err != nil {
+		return nil, err
+	}
+
+	numToSkip, numRequested := addrBalancePagination(c.Skip, c.Count)
+	entries, _, err := ticketIndex.TicketsForAddress(addr, numToSkip,
+		numRequested)
+	if err != nil {
+		if errors.Is(err, indexers.ErrUnsupportedAddressType) {
+			return nil, rpcInvalidError("Address %s is not supported by the "+
+				"ticket index", c.Address)
+		}
+		return nil, rpcInternalError(err.Error(),
+			"Failed to retrieve address tickets")
+	}
+
+	bestHeight := s.cfg.Chain.BestSnapshot().Height
+	results := make([]types.GetTicketInfoResult, 0, len(entries))
+	for i := range entries {
+		result, err := s.ticketInfoResult(&entries[i], bestHeight)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, *result)
+	}
+	return results, nil
+}
+
 // handleGetBestBlock implements the getbestblock command.
 func handleGetBestBlock(_ context.Context, s *Server, cmd interface{}) (interface{}, error) {
 	// All other "get block" commands give either the height, the hash, or
@@ -2293,6 +2636,136 @@ func handleGetBlockHeader(_ context.Context, s *Server, cmd interface{}) (interf
 	return blockHeaderReply, nil
 }
 
+// selectBlockStats returns only the requested fields of the provided block
+// statistics result keyed by their JSON names.
+func selectBlockStats(result *types.GetBlockStatsResult, fields []string) (map[string]json.RawMessage, error) {
+	marshalled, err := json.Marshal(result)
+	if err != nil {
+		return nil, rpcInternalError(err.Error(), "Marshal")
+	}
+	var all map[string]json.RawMessage
+	if err := json.Unmarshal(marshalled, &all); err != nil {
+		return nil, rpcInternalError(err.Error(), "Unmarshal")
+	}
+
+	selected := make(map[string]json.RawMessage, len(fields))
+	for _, field := range fields {
+		value, ok := all[field]
+		if !ok {
+			return nil, rpcInvalidError("Invalid selected statistic %q", field)
+		}
+		selected[field] = value
+	}
+	return selected, nil
+}
+
+// mainChainHashByHashOrHeight returns the hash of the main chain block
+// identified by the provided hash or height.  The block is looked up by height
+// when a height is provided and by hash otherwise.  An error suitable for
+// returning to the caller is returned when there is no such block in the main
+// chain.
+func mainChainHashByHashOrHeight(chain Chain, hashOrHeight types.HashOrHeight) (*chainhash.Hash, error) {
+	str := string(hashOrHeight)
+	if height, err := strconv.ParseInt(str, 10, 64); err == nil {
+		hash, err := chain.BlockHashByHeight(height)
+		if err != nil {
+			return nil, &dcrjson.RPCError{
+				Code: dcrjson.ErrRPCOutOfRange,
+				Message: fmt.Sprintf("Block number out of range: %v",
+					height),
+			}
+		}
+		return hash, nil
+	}
+
+	hash, err := chainhash.NewHashFromStr(str)
+	if err != nil {
+		return nil, rpcDecodeHexError(str)
+	}
+	if !chain.MainChainHasBlock(hash) {
+		return nil, rpcBlockNotFoundError(*hash)
+	}
+	return hash, nil
+}
+
+// handleGetBlockStats implements the getblockstats command.
+func handleGetBlockStats(_ context.Context, s *Server, cmd interface{}) (interface{}, error) {
+	blockStatsIndex := s.cfg.BlockStatsIndexer
+	if blockStatsIndex == nil {
+		return nil, rpcInternalError("The block statistics index must be "+
+			"enabled (specify --blockstatsindex)", "Configuration")
+	}
+
+	c := cmd.(*types.GetBlockStatsCmd)
+
+	// Only blocks in the main chain are indexed.
+	chain := s.cfg.Chain
+	hash, err := mainChainHashByHashOrHeight(chain, c.HashOrHeight)
+	if err != nil {
+		return nil, err
+	}
+	header, err := chain.HeaderByHash(hash)
+	if err != nil {
+		return nil, rpcBlockNotFoundError(*hash)
+	}
+
+	// The statistics for blocks in the main chain are only missing when the
+	// index has not caught up to the block yet.
+	stats, err := blockStatsIndex.Stats(hash)
+	if err != nil {
+		context := "Failed to retrieve block statistics"
+		return nil, rpcInternalError(err.Error(), context)
+	}
+	if stats == nil {
+		msg := fmt.Sprintf("%s: index not synced", blockStatsIndex.Name())
+		return nil, rpcInternalError(msg, "Sync")
+	}
+
+	feeRates := make([]float64, 0, len(stats.FeeRates))
+	for _, feeRate := range stats.FeeRates {
+		feeRates = append(feeRates, dcrutil.Amount(feeRate).ToCoin())
+	}
+	result := types.GetBlockStatsResult{
+		BlockHash:          hash.String(),
+		FeeRatePercentiles: feeRates,
+		Height:             int64(header.Height),
+		Ins:                int64(stats.NumInputs),
+		MaxFee:             dcrutil.Amount(stats.MaxFee).ToCoin(),
+		MaxFeeRate:         dcrutil.Amount(stats.MaxFeeRate).ToCoin(),
+		MaxTxSize:          int64(stats.MaxTxSize),
+		MedianFee:          dcrutil.Amount(stats.MedianFee).ToCoin(),
+		MedianTxSize:       int64(stats.MedianTxSize),
+		MinFee:             dcrutil.Amount(stats.MinFee).ToCoin(),
+		MinFeeRate:         dcrutil.Amount(stats.MinFeeRate).ToCoin(),
+		MinTxSize:          int64(stats.MinTxSize),
+		Outs:               int64(stats.NumOutputs),
+		Revocations:        int64(stats.NumRevocations),
+		Size:               int64(stats.Size),
+		STxs:               int64(stats.NumStakeTxns),
+		Tickets:            int64(stats.NumTickets),
+		Time:               header.Timestamp.Unix(),
+		TotalFee:           dcrutil.Amount(stats.TotalFee).ToCoin(),
+		TotalOut:           dcrutil.Amount(stats.TotalOut).ToCoin(),
+		TotalSize:          int64(stats.TotalTxSize),
+		Txs:                int64(stats.NumTxns),
+		UtxoIncrease:       int64(stats.UtxoIncrease),
+		Votes:              int64(stats.NumVotes),
+	}
+	if stats.NumFeeTxns > 0 {
+		numFeeTxns := int64(stats.NumFeeTxns)
+		totalSize := int64(stats.TotalTxSize)
+		result.AvgFee = dcrutil.Amount(stats.TotalFee / numFeeTxns).ToCoin()
+		result.AvgFeeRate = dcrutil.Amount(stats.TotalFee * 1000 /
+			totalSize).ToCoin()
+		result.AvgTxSize = totalSize / numFeeTxns
+	}
+
+	if c.Fields == nil || len(*c.Fields) == 0 {
+		return result, nil
+	}
+	return selectBlockStats(&result, *c.Fields)
+}
+
 // handleGetBlockSubsidy implements the getblocksubsidy command.
 func handleGetBlockSubsidy(_ context.Context, s *Server, cmd interface{}) (interface{}, error) {
 	c := cmd.(*types.GetBlockSubsidyCmd)
@@ -2447,62 +2920,279 @@ func handleGetCFilterV2(_ context.Context, s *Server, cmd interface{}) (interfac
 		return nil, rpcInternalError(err.Error(), context)
 	}
 
-	// NOTE: When more header commitments are added, this will need to load the
-	// inclusion proof for the filter from the database.  However, since there
-	// is only currently a single commitment, there is only a single leaf in the
-	// commitment merkle tree, and hence the proof hashes will always be empty
-	// given there are no siblings.  Adding an additional header commitment will
-	// require a consensus vote anyway and this can be updated at that time.
 	result := &types.GetCFilterV2Result{
 		BlockHash:   c.BlockHash,
 		Data:        hex.EncodeToString(filter.Bytes()),
 		ProofIndex:  blockchain.HeaderCmtFilterIndex,
-		ProofHashes: nil,
+		ProofHashes: filterInclusionProof(filter.Hash()),
 	}
 	return result, nil
 }
 
-// handleGetInfo implements the getinfo command. We only return the fields
-// that are not related to wallet functionality.
-func handleGetInfo(_ context.Context, s *Server, cmd interface{}) (interface{}, error) {
-	best := s.cfg.Chain.BestSnapshot()
-	ret := &types.InfoChainResult{
-		Version: int32(1000000*version.Major + 10000*version.Minor +
-			100*version.Patch),
-		ProtocolVersion: int32(s.cfg.MaxProtocolVersion),
-		Blocks:          best.Height,
-		TimeOffset:      int64(s.cfg.TimeSource.Offset().Seconds()),
-		Connections:     s.cfg.ConnMgr.ConnectedCount(),
-		Proxy:           s.cfg.Proxy,
-		Difficulty:      getDifficultyRatio(best.Bits, s.cfg.ChainParams),
-		TestNet:         s.cfg.TestNet,
-		RelayFee:        s.cfg.MinRelayTxFee.ToCoin(),
-		AddrIndex:       s.cfg.AddrIndexer != nil,
-		TxIndex:         s.cfg.TxIndexer != nil,
+// filterInclusionProof returns the hashes needed to prove the version 2 filter
+// with the provided hash is committed to by the header commitment root of its
+// block.
+//
+// NOTE: When more header commitments are added, the additional commitments
+// will need to be loaded and included as leaves.  However, since there is only
+// currently a single commitment, there is only a single leaf in the commitment
+// merkle tree, and hence the proof hashes will always be empty given there are
+// no siblings.  Adding an additional header commitment will require a
+// consensus vote anyway and this can be updated at that time.
+func filterInclusionProof(filterHash chainhash.Hash) []string {
+	leaves := []chainhash.Hash{blockchain.HeaderCmtFilterIndex: filterHash}
+	proof := standalone.GenerateInclusionProof(leaves,
+		blockchain.HeaderCmtFilterIndex)
+	var proofHashes []string
+	for i := range proof {
+		proofHashes = append(proofHashes, proof[i].String())
+	}
+	return proofHashes
+}
+
+// filterRangeHashes returns the hashes of the main chain blocks in the range
+// of the provided number of blocks starting at the provided height.  The range
+// is limited to the current best block.  An error suitable for returning to
+// the caller is returned when the count is not in the range [1, maxCount] or
+// the start height is not in the main chain.
+func filterRangeHashes(chain Chain, startHeight int64, count, maxCount uint32) ([]chainhash.Hash, error) {
+	if count == 0 || count > maxCount {
+		return nil, rpcInvalidError("Count must be between 1 and %d",
+			maxCount)
+	}
+	if startHeight < 0 || startHeight > chain.BestSnapshot().Height {
+		return nil, &dcrjson.RPCError{
+			Code: dcrjson.ErrRPCOutOfRange,
+			Message: fmt.Sprintf("Block number out of range: %v",
+				startHeight),
+		}
 	}
 
-	return ret, nil
+	hashes, err := chain.HeightRange(startHeight, startHeight+int64(count))
+	if err != nil {
+		context := "Failed to fetch block hashes"
+		return nil, rpcInternalError(err.Error(), context)
+	}
+	return hashes, nil
 }
 
-// handleGetMempoolInfo implements the getmempoolinfo command.
-func handleGetMempoolInfo(_ context.Context, s *Server, cmd interface{}) (interface{}, error) {
-	mempoolTxns := s.cfg.TxMempooler.TxDescs()
+// handleGetCFilterV2Range implements the getcfilterv2range command.
+func handleGetCFilterV2Range(_ context.Context, s *Server, cmd interface{}) (interface{}, error) {
+	c := cmd.(*types.GetCFilterV2RangeCmd)
+	hashes, err := filterRangeHashes(s.cfg.Chain, c.StartHeight, c.Count,
+		maxCFiltersPerRange)
+	if err != nil {
+		return nil, err
+	}
 
-	var numBytes int64
-	for _, txD := range mempoolTxns {
-		numBytes += int64(txD.Tx.MsgTx().SerializeSize())
+	results := make([]types.GetCFilterV2Result, 0, len(hashes))
+	for i := range hashes {
+		hash := &hashes[i]
+		filter, err := s.cfg.FiltererV2.FilterByBlockHash(hash)
+		if err != nil {
+			if errors.Is(err, blockchain.ErrNoFilter) {
+				return nil, rpcBlockNotFoundError(*hash)
+			}
+
+			context := fmt.Sprintf("Failed to load filter for block %s", hash)
+			return nil, rpcInternalError(err.Error(), context)
+		}
+
+		results = append(results, types.GetCFilterV2Result{
+			BlockHash:   hash.String(),
+			Data:        hex.EncodeToString(filter.Bytes()),
+			ProofIndex:  blockchain.HeaderCmtFilterIndex,
+			ProofHashes: filterInclusionProof(filter.Hash()),
+		})
 	}
+	return results, nil
+}
 
-	ret := &types.GetMempoolInfoResult{
-		Size:  int64(len(mempoolTxns)),
-		Bytes: numBytes,
+// syncedCFHeaderIndexer returns the filter header indexer once it is synced
+// with the main chain.  An error suitable for returning to the caller is
+// returned when the index is not enabled or is not synced.
+func (s *Server) syncedCFHeaderIndexer() (CFHeaderIndexer, error) {
+	cfHeaderIndex := s.cfg.CFHeaderIndexer
+	if cfHeaderIndex == nil {
+		return nil, rpcInternalError("The filter header index must be "+
+			"enabled (specify --cfheaderindex)", "Configuration")
 	}
 
-	return ret, nil
-}
+	// Ensure the filter header index is synced.
+	tHeight, tHash, err := cfHeaderIndex.Tip()
+	if err != nil {
+		return nil, rpcInternalError(err.Error(), "Tip")
+	}
 
-// handleGetMiningInfo implements the getmininginfo command. We only return the
-// fields that are not related to wallet functionality.
+	chain := s.cfg.Chain
+
+	// Return an out-of-sync error if index is lagging a
+	// maximum reorg depth (6) blocks or more from the chain tip.
+	if chain.BestSnapshot().Height > (tHeight + 5) {
+		msg := fmt.Sprintf("%s: index not synced", cfHeaderIndex.Name())
+		return nil, rpcInternalError(msg, "Sync")
+	}
+
+sync:
+	for !chain.BestSnapshot().Hash.IsEqual(tHash) {
+		select {
+		case <-time.After(syncWait):
+			msg := fmt.Sprintf("%s: index not synced", cfHeaderIndex.Name())
+			return nil, rpcInternalError(msg, "Sync")
+		case <-cfHeaderIndex.WaitForSync():
+			break sync
+		}
+	}
+
+	return cfHeaderIndex, nil
+}
+
+// handleGetCFilterHeaders implements the getcfilterheaders command.
+func handleGetCFilterHeaders(_ context.Context, s *Server, cmd interface{}) (interface{}, error) {
+	c := cmd.(*types.GetCFilterHeadersCmd)
+	cfHeaderIndex, err := s.syncedCFHeaderIndexer()
+	if err != nil {
+		return nil, err
+	}
+
+	chain := s.cfg.Chain
+	hashes, err := filterRangeHashes(chain, c.StartHeight, c.Count,
+		maxCFHeadersPerRange)
+	if err != nil {
+		return nil, err
+	}
+
+	// fetchEntry loads the filter header index entry for the provided block.
+	// The entries for blocks in the main chain are only missing when the
+	// index has not caught up to a reorganization yet.
+	fetchEntry := func(hash *chainhash.Hash) (*indexers.CFHeaderEntry, error) {
+		entry, err := cfHeaderIndex.Entry(hash)
+		if err != nil {
+			context := fmt.Sprintf("Failed to load filter header for "+
+				"block %s", hash)
+			return nil, rpcInternalError(err.Error(), context)
+		}
+		if entry == nil {
+			msg := fmt.Sprintf("%s: index not synced", cfHeaderIndex.Name())
+			return nil, rpcInternalError(msg, "Sync")
+		}
+		return entry, nil
+	}
+
+	// The previous filter header of the genesis block is all zeros.
+	var prevHeader chainhash.Hash
+	if c.StartHeight > 0 {
+		prevHash, err := chain.BlockHashByHeight(c.StartHeight - 1)
+		if err != nil {
+			context := "Failed to fetch block hash"
+			return nil, rpcInternalError(err.Error(), context)
+		}
+		prevEntry, err := fetchEntry(prevHash)
+		if err != nil {
+			return nil, err
+		}
+		prevHeader = prevEntry.Header
+	}
+
+	headers := make([]types.CFilterHeaderResult, 0, len(hashes))
+	for i := range hashes {
+		hash := &hashes[i]
+		entry, err := fetchEntry(hash)
+		if err != nil {
+			return nil, err
+		}
+
+		headers = append(headers, types.CFilterHeaderResult{
+			BlockHash:   hash.String(),
+			FilterHash:  entry.FilterHash.String(),
+			Header:      entry.Header.String(),
+			ProofIndex:  blockchain.HeaderCmtFilterIndex,
+			ProofHashes: filterInclusionProof(entry.FilterHash),
+		})
+	}
+
+	return &types.GetCFilterHeadersResult{
+		PrevHeader: prevHeader.String(),
+		Headers:    headers,
+	}, nil
+}
+
+// handleGetIndexInfo implements the getindexinfo command.
+func handleGetIndexInfo(_ context.Context, s *Server, cmd interface{}) (interface{}, error) {
+	c := cmd.(*types.GetIndexInfoCmd)
+	indexMgr, err := s.indexManager()
+	if err != nil {
+		return nil, err
+	}
+
+	infos, err := indexMgr.IndexInfo()
+	if err != nil {
+		return nil, rpcInternalError(err.Error(), "Failed to fetch index info")
+	}
+
+	result := make(map[string]types.IndexInfoResult, len(infos))
+	for i := range infos {
+		info := &infos[i]
+		if c.Name != nil && *c.Name != info.Name {
+			continue
+		}
+
+		var hash string
+		if !info.Dropping {
+			hash = info.Hash.String()
+		}
+		result[info.Name] = types.IndexInfoResult{
+			Synced:          info.Synced,
+			Dropping:        info.Dropping,
+			BestBlockHeight: info.Height,
+			BestBlockHash:   hash,
+			DiskUsage:       info.DiskUsage,
+		}
+	}
+	return result, nil
+}
+
+// handleGetInfo implements the getinfo command. We only return the fields
+// that are not related to wallet functionality.
+func handleGetInfo(_ context.Context, s *Server, cmd interface{}) (interface{}, error) {
+	best := s.cfg.Chain.BestSnapshot()
+	ret := &types.InfoChainResult{
+		Version: int32(1000000*version.Major + 10000*version.Minor +
+			100*version.Patch),
+		ProtocolVersion: int32(s.cfg.MaxProtocolVersion),
+		Blocks:          best.Height,
+		TimeOffset:      int64(s.cfg.TimeSource.Offset().Seconds()),
+		Connections:     s.cfg.ConnMgr.ConnectedCount(),
+		Proxy:           s.cfg.Proxy,
+		Difficulty:      getDifficultyRatio(best.Bits, s.cfg.ChainParams),
+		TestNet:         s.cfg.TestNet,
+		RelayFee:        s.cfg.MinRelayTxFee.ToCoin(),
+		AddrIndex:       s.addrIndexer() != nil,
+		TxIndex:         s.txIndexer() != nil,
+	}
+
+	return ret, nil
+}
+
+// handleGetMempoolInfo implements the getmempoolinfo command.
+func handleGetMempoolInfo(_ context.Context, s *Server, cmd interface{}) (interface{}, error) {
+	mempoolTxns := s.cfg.TxMempooler.TxDescs()
+
+	var numBytes int64
+	for _, txD := range mempoolTxns {
+		numBytes += int64(txD.Tx.MsgTx().SerializeSize())
+	}
+
+	ret := &types.GetMempoolInfoResult{
+		Size:  int64(len(mempoolTxns)),
+		Bytes: numBytes,
+	}
+
+	return ret, nil
+}
+
+// handleGetMiningInfo implements the getmininginfo command. We only return the
+// fields that are not related to wallet functionality.
 func handleGetMiningInfo(ctx context.Context, s *Server, _ interface{}) (interface{}, error) {
 	// Create a default getnetworkhashps command to use defaults and make
 	// use of the existing getnetworkhashps handler.
@@ -2535,13 +3225,45 @@ func handleGetMiningInfo(ctx context.Context, s *Server, _ interface{}) (interfa
 	return &result, nil
 }
 
+// netMsgStatsResult converts the provided message statistics keyed by message
+// type to the form returned by the RPC server.
+func netMsgStatsResult(stats map[string]MsgStat) map[string]types.NetMsgStat {
+	result := make(map[string]types.NetMsgStat, len(stats))
+	for msgType, stat := range stats {
+		result[msgType] = types.NetMsgStat{
+			Msgs:  stat.Msgs,
+			Bytes: stat.Bytes,
+		}
+	}
+	return result
+}
+
+// handleGetNetMsgStats implements the getnetmsgstats command.
+func handleGetNetMsgStats(_ context.Context, s *Server, _ interface{}) (interface{}, error) {
+	stats := s.cfg.ConnMgr.MsgStats()
+	reply := &types.GetNetMsgStatsResult{
+		Sent: netMsgStatsResult(stats.Sent),
+		Recv: netMsgStatsResult(stats.Recv),
+	}
+	return reply, nil
+}
+
 // handleGetNetTotals implements the getnettotals command.
 func handleGetNetTotals(_ context.Context, s *Server, cmd interface{}) (interface{}, error) {
 	totalBytesRecv, totalBytesSent := s.cfg.ConnMgr.NetTotals()
+	uploadTarget := s.cfg.ConnMgr.UploadTarget()
 	reply := &types.GetNetTotalsResult{
 		TotalBytesRecv: totalBytesRecv,
 		TotalBytesSent: totalBytesSent,
 		TimeMillis:     s.cfg.Clock.Now().UTC().UnixNano() / int64(time.Millisecond),
+		UploadTarget: types.UploadTargetResult{
+			TimeFrame:             int64(uploadTarget.Timeframe / time.Second),
+			Target:                uploadTarget.Target,
+			TargetReached:         uploadTarget.TargetReached,
+			ServeHistoricalBlocks: uploadTarget.ServeHistoricalBlocks,
+			BytesLeftInCycle:      uploadTarget.BytesLeftInCycle,
+			TimeLeftInCycle:       int64(uploadTarget.TimeLeftInCycle / time.Second),
+		},
 	}
 	return reply, nil
 }
@@ -2678,6 +3400,7 @@ func handleGetPeerInfo(_ context.Context, s *Server, cmd interface{}) (interface
 	infos := make([]*types.GetPeerInfoResult, 0, len(peers))
 	for _, p := range peers {
 		statsSnap := p.StatsSnapshot()
+		msgStats := p.MsgStats()
 		var addrLocalStr string
 		if addrLocal := p.LocalAddr(); addrLocal != nil {
 			addrLocalStr = addrLocal.String()
@@ -2698,10 +3421,14 @@ func handleGetPeerInfo(_ context.Context, s *Server, cmd interface{}) (interface
 			Version:        statsSnap.Version,
 			SubVer:         statsSnap.UserAgent,
 			Inbound:        statsSnap.Inbound,
+			ConnType:       p.ConnType(),
+			MappedAS:       p.MappedAS(),
 			StartingHeight: statsSnap.StartingHeight,
 			CurrentHeight:  statsSnap.LastBlock,
 			BanScore:       int32(p.BanScore()),
 			SyncNode:       p.ID() == syncPeerID,
+			SentPerMsg:     netMsgStatsResult(msgStats.Sent),
+			RecvPerMsg:     netMsgStatsResult(msgStats.Recv),
 		}
 		if p.LastPingNonce() != 0 {
 			wait := float64(s.cfg.Clock.Since(statsSnap.LastPingTime).Nanoseconds())
@@ -2819,7 +3546,7 @@ func handleGetRawTransaction(_ context.Context, s *Server, cmd interface{}) (int
 	var blkHeight int64
 	var blkIndex uint32
 	chain := s.cfg.Chain
-	txIndex := s.cfg.TxIndexer
+	txIndex := s.txIndexer()
 	tx, err := s.cfg.TxMempooler.FetchTransaction(txHash)
 	if err != nil {
 		if txIndex == nil {
@@ -2965,9 +3692,114 @@ func handleGetRawTransaction(_ context.Context, s *Server, cmd interface{}) (int
 	if err != nil {
 		return nil, err
 	}
+
+	// Include the spender of each output of mined transactions when the
+	// spender index is enabled.
+	if blkHash != nil && s.cfg.SpenderIndexer != nil {
+		err := s.addVoutSpenders(txHash, rawTxn.Vout)
+		if err != nil {
+			context := "Failed to retrieve output spenders"
+			return nil, rpcInternalError(err.Error(), context)
+		}
+	}
 	return *rawTxn, nil
 }
 
+// spendingTxResult returns the result that describes the transaction that
+// spends the provided outpoint according to the spender index.  Nil is
+// returned when the outpoint has not been spent in the main chain.
+func (s *Server) spendingTxResult(outpoint *wire.OutPoint) (*types.GetSpendingTxResult, error) {
+	entry, err := s.cfg.SpenderIndexer.Entry(outpoint)
+	if err != nil || entry == nil {
+		return nil, err
+	}
+
+	blockHash, err := s.cfg.Chain.BlockHashByHeight(entry.BlockHeight)
+	if err != nil {
+		return nil, err
+	}
+
+	return &types.GetSpendingTxResult{
+		Txid:        entry.SpenderHash.String(),
+		Vin:         entry.InputIndex,
+		BlockHash:   blockHash.String(),
+		BlockHeight: entry.BlockHeight,
+	}, nil
+}
+
+// addVoutSpenders sets the spender of each of the provided outputs of the
+// transaction with the given hash that has been spent in the main chain
+// according to the spender index.
+//
+// This must only be called when the spender index is enabled.
+func (s *Server) addVoutSpenders(txHash *chainhash.Hash, vouts []types.Vout) error {
+	for i := range vouts {
+		outpoint := wire.OutPoint{Hash: *txHash, Index: vouts[i].N}
+		spentBy, err := s.spendingTxResult(&outpoint)
+		if err != nil {
+			return err
+		}
+		vouts[i].SpentBy = spentBy
+	}
+	return nil
+}
+
+// handleGetSpendingTx implements the getspendingtx command.
+func handleGetSpendingTx(_ context.Context, s *Server, cmd interface{}) (interface{}, error) {
+	spenderIndex := s.cfg.SpenderIndexer
+	if spenderIndex == nil {
+		return nil, rpcInternalError("The spender index must be enabled "+
+			"(specify --spenderindex)", "Configuration")
+	}
+
+	c := cmd.(*types.GetSpendingTxCmd)
+
+	// Convert the provided transaction hash hex to a Hash.
+	txHash, err := chainhash.NewHashFromStr(c.Txid)
+	if err != nil {
+		return nil, rpcDecodeHexError(c.Txid)
+	}
+
+	// Ensure the spender index is synced.
+	tHeight, tHash, err := spenderIndex.Tip()
+	if err != nil {
+		return nil, rpcInternalError(err.Error(), "Tip")
+	}
+
+	chain := s.cfg.Chain
+
+	// Return an out-of-sync error if index is lagging a
+	// maximum reorg depth (6) blocks or more from the chain tip.
+	if chain.BestSnapshot().Height > (tHeight + 5) {
+		msg := fmt.Sprintf("%s: index not synced", spenderIndex.Name())
+		return nil, rpcInternalError(msg, "Sync")
+	}
+
+sync:
+	for !chain.BestSnapshot().Hash.IsEqual(tHash) {
+		select {
+		case <-time.After(syncWait):
+			msg := fmt.Sprintf("%s: index not synced", spenderIndex.Name())
+			return nil, rpcInternalError(msg, "Sync")
+		case <-spenderIndex.WaitForSync():
+			break sync
+		}
+	}
+
+	// Look up the spender of the output.  Nothing is returned when the output
+	// has not been spent in the main chain.
+	outpoint := wire.OutPoint{Hash: *txHash, Index: c.Vout}
+	result, err := s.spendingTxResult(&outpoint)
+	if err != nil {
+		context := "Failed to retrieve spending transaction"
+		return nil, rpcInternalError(err.Error(), context)
+	}
+	if result == nil {
+		return nil, nil
+	}
+	return result, nil
+}
+
 // handleGetStakeDifficulty implements the getstakedifficulty command.
 func handleGetStakeDifficulty(_ context.Context, s *Server, cmd interface{}) (interface{}, error) {
 	chain := s.cfg.Chain
@@ -3027,103 +3859,285 @@ func handleGetStakeVersionInfo(_ context.Context, s *Server, cmd interface{}) (i
 		}
 	}
 
-	// Assemble JSON result.
-	result := types.GetStakeVersionInfoResult{
-		CurrentHeight: snapshot.Height,
-		Hash:          snapshot.Hash.String(),
-		Intervals:     make([]types.VersionInterval, 0, count),
+	// Assemble JSON result.
+	result := types.GetStakeVersionInfoResult{
+		CurrentHeight: snapshot.Height,
+		Hash:          snapshot.Hash.String(),
+		Intervals:     make([]types.VersionInterval, 0, count),
+	}
+
+	startHeight := snapshot.Height
+	endHeight := chain.CalcWantHeight(interval, snapshot.Height) + 1
+	hash := &snapshot.Hash
+	adjust := int32(1) // We are off by one on the initial iteration.
+	for i := int32(0); i < count; i++ {
+		numBlocks := int32(startHeight - endHeight)
+		if numBlocks <= 0 {
+			// Just return what we got.
+			break
+		}
+		sv, err := chain.GetStakeVersions(hash, numBlocks+adjust)
+		if err != nil {
+			context := fmt.Sprintf("Failed to get stake versions starting "+
+				"from hash %v", hash)
+			return nil, rpcInternalError(err.Error(), context)
+		}
+
+		posVersions := make(map[int]int)
+		voteVersions := make(map[int]int)
+		for _, v := range sv {
+			posVersions[int(v.StakeVersion)]++
+			for _, vote := range v.Votes {
+				voteVersions[int(vote.Version)]++
+			}
+		}
+		versionInterval := types.VersionInterval{
+			StartHeight:  endHeight,
+			EndHeight:    startHeight,
+			PoSVersions:  convertVersionMap(posVersions),
+			VoteVersions: convertVersionMap(voteVersions),
+		}
+		result.Intervals = append(result.Intervals, versionInterval)
+
+		// Adjust interval.
+		endHeight -= interval
+		startHeight = endHeight + interval
+		adjust = 0
+
+		// Get prior block hash.
+		hash, err = chain.BlockHashByHeight(startHeight - 1)
+		if err != nil {
+			context := fmt.Sprintf("Failed to get block hash for height %d",
+				startHeight-1)
+			return nil, rpcInternalError(err.Error(), context)
+		}
+	}
+
+	return result, nil
+}
+
+// handleGetStakeVersions implements the getstakeversions command.
+func handleGetStakeVersions(_ context.Context, s *Server, cmd interface{}) (interface{}, error) {
+	c := cmd.(*types.GetStakeVersionsCmd)
+
+	hash, err := chainhash.NewHashFromStr(c.Hash)
+	if err != nil {
+		return nil, rpcDecodeHexError(c.Hash)
+	}
+	if c.Count <= 0 {
+		return nil, rpcInvalidError("Invalid parameter, count must " +
+			"be > 0")
+	}
+
+	sv, err := s.cfg.Chain.GetStakeVersions(hash, c.Count)
+	if err != nil {
+		return nil, rpcInternalError(err.Error(),
+			"Could not obtain stake versions")
+	}
+
+	result := types.GetStakeVersionsResult{
+		StakeVersions: make([]types.StakeVersions, 0, len(sv)),
+	}
+	for _, v := range sv {
+		nsv := types.StakeVersions{
+			Hash:         v.Hash.String(),
+			Height:       v.Height,
+			BlockVersion: v.BlockVersion,
+			StakeVersion: v.StakeVersion,
+			Votes: make([]types.VersionBits, 0,
+				len(v.Votes)),
+		}
+		for _, vote := range v.Votes {
+			nsv.Votes = append(nsv.Votes,
+				types.VersionBits{Version: vote.Version, Bits: vote.Bits})
+		}
+
+		result.StakeVersions = append(result.StakeVersions, nsv)
+	}
+
+	return result, nil
+}
+
+// syncedTicketIndexer returns the ticket indexer once it is synced with the
+// main chain.  An error suitable for returning to the caller is returned when
+// the index is not enabled or is not synced.
+func (s *Server) syncedTicketIndexer() (TicketIndexer, error) {
+	ticketIndex := s.cfg.TicketIndexer
+	if ticketIndex == nil {
+		return nil, rpcInternalError("The ticket index must be enabled "+
+			"(specify --ticketindex)", "Configuration")
+	}
+
+	// Ensure the ticket index is synced.
+	tHeight, tHash, err := ticketIndex.Tip()
+	if err != nil {
+		return nil, rpcInternalError(err.Error(), "Tip")
+	}
+
+	chain := s.cfg.Chain
+
+	// Return an out-of-sync error if index is lagging a
+	// maximum reorg depth (6) blocks or more from the chain tip.
+	if chain.BestSnapshot().Height > (tHeight + 5) {
+		msg := fmt.Sprintf("%s: index not synced", ticketIndex.Name())
+		return nil, rpcInternalError(msg, "Sync")
+	}
+
+sync:
+	for !chain.BestSnapshot().Hash.IsEqual(tHash) {
+		select {
+		case <-time.After(syncWait):
+			msg := fmt.Sprintf("%s: index not synced", ticketIndex.Name())
+			return nil, rpcInternalError(msg, "Sync")
+		case <-ticketIndex.WaitForSync():
+			break sync
+		}
+	}
+
+	return ticketIndex, nil
+}
+
+// syncedCoinStatsIndexer returns the coin statistics indexer once it is synced
+// with the main chain.  An error suitable for returning to the caller is
+// returned when the index is not enabled or is not synced.
+func (s *Server) syncedCoinStatsIndexer() (CoinStatsIndexer, error) {
+	coinStatsIndex := s.cfg.CoinStatsIndexer
+	if coinStatsIndex == nil {
+		return nil, rpcInternalError("The coin statistics index must be "+
+			"enabled (specify --coinstatsindex)", "Configuration")
+	}
+
+	// Ensure the coin statistics index is synced.
+	tHeight, tHash, err := coinStatsIndex.Tip()
+	if err != nil {
+		return nil, rpcInternalError(err.Error(), "Tip")
+	}
+
+	chain := s.cfg.Chain
+
+	// Return an out-of-sync error if index is lagging a
+	// maximum reorg depth (6) blocks or more from the chain tip.
+	if chain.BestSnapshot().Height > (tHeight + 5) {
+		msg := fmt.Sprintf("%s: index not synced", coinStatsIndex.Name())
+		return nil, rpcInternalError(msg, "Sync")
+	}
+
+sync:
+	for !chain.BestSnapshot().Hash.IsEqual(tHash) {
+		select {
+		case <-time.After(syncWait):
+			msg := fmt.Sprintf("%s: index not synced", coinStatsIndex.Name())
+			return nil, rpcInternalError(msg, "Sync")
+		case <-coinStatsIndex.WaitForSync():
+			break sync
+		}
+	}
+
+	return coinStatsIndex, nil
+}
+
+// ticketStatus returns the status of the ticket described by the provided
+// ticket index entry as of the provided best chain height.
+func ticketStatus(entry *indexers.TicketEntry, bestHeight int64, params *chaincfg.Params) string {
+	switch {
+	case entry.VoteHeight != 0:
+		return "voted"
+	case entry.RevocationHeight != 0:
+		return "revoked"
+	case entry.ExpiredHeight != 0:
+		return "expired"
+	case entry.MissedHeight != 0:
+		return "missed"
+	case bestHeight < entry.PurchaseHeight+int64(params.TicketMaturity):
+		return "immature"
 	}
+	return "live"
+}
 
-	startHeight := snapshot.Height
-	endHeight := chain.CalcWantHeight(interval, snapshot.Height) + 1
-	hash := &snapshot.Hash
-	adjust := int32(1) // We are off by one on the initial iteration.
-	for i := int32(0); i < count; i++ {
-		numBlocks := int32(startHeight - endHeight)
-		if numBlocks <= 0 {
-			// Just return what we got.
-			break
-		}
-		sv, err := chain.GetStakeVersions(hash, numBlocks+adjust)
+// ticketInfoResult returns the result that describes the lifecycle of the
+// ticket in the provided ticket index entry as of the provided best chain
+// height.
+func (s *Server) ticketInfoResult(entry *indexers.TicketEntry, bestHeight int64) (*types.GetTicketInfoResult, error) {
+	chain := s.cfg.Chain
+	blockHashAt := func(height int64) (string, error) {
+		hash, err := chain.BlockHashByHeight(height)
 		if err != nil {
-			context := fmt.Sprintf("Failed to get stake versions starting "+
-				"from hash %v", hash)
-			return nil, rpcInternalError(err.Error(), context)
+			context := "Failed to retrieve block hash"
+			return "", rpcInternalError(err.Error(), context)
 		}
+		return hash.String(), nil
+	}
 
-		posVersions := make(map[int]int)
-		voteVersions := make(map[int]int)
-		for _, v := range sv {
-			posVersions[int(v.StakeVersion)]++
-			for _, vote := range v.Votes {
-				voteVersions[int(vote.Version)]++
-			}
+	params := s.cfg.ChainParams
+	maturityHeight := entry.PurchaseHeight + int64(params.TicketMaturity)
+	result := &types.GetTicketInfoResult{
+		Hash:           entry.Hash.String(),
+		Status:         ticketStatus(entry, bestHeight, params),
+		Price:          dcrutil.Amount(entry.Price).ToCoin(),
+		PurchaseHeight: entry.PurchaseHeight,
+		MaturityHeight: maturityHeight,
+		ExpiryHeight:   maturityHeight + int64(params.TicketExpiry),
+		MissedHeight:   entry.MissedHeight,
+		ExpiredHeight:  entry.ExpiredHeight,
+	}
+	var err error
+	result.PurchaseBlock, err = blockHashAt(entry.PurchaseHeight)
+	if err != nil {
+		return nil, err
+	}
+	if entry.VoteHeight != 0 {
+		blockHash, err := blockHashAt(entry.VoteHeight)
+		if err != nil {
+			return nil, err
 		}
-		versionInterval := types.VersionInterval{
-			StartHeight:  endHeight,
-			EndHeight:    startHeight,
-			PoSVersions:  convertVersionMap(posVersions),
-			VoteVersions: convertVersionMap(voteVersions),
+		result.Vote = &types.TicketVoteResult{
+			Txid:        entry.VoteHash.String(),
+			BlockHash:   blockHash,
+			BlockHeight: entry.VoteHeight,
+			Version:     entry.VoteVersion,
+			VoteBits:    entry.VoteBits,
 		}
-		result.Intervals = append(result.Intervals, versionInterval)
-
-		// Adjust interval.
-		endHeight -= interval
-		startHeight = endHeight + interval
-		adjust = 0
-
-		// Get prior block hash.
-		hash, err = chain.BlockHashByHeight(startHeight - 1)
+	}
+	if entry.RevocationHeight != 0 {
+		blockHash, err := blockHashAt(entry.RevocationHeight)
 		if err != nil {
-			context := fmt.Sprintf("Failed to get block hash for height %d",
-				startHeight-1)
-			return nil, rpcInternalError(err.Error(), context)
+			return nil, err
+		}
+		result.Revocation = &types.TicketRevocationResult{
+			Txid:        entry.RevocationHash.String(),
+			BlockHash:   blockHash,
+			BlockHeight: entry.RevocationHeight,
 		}
 	}
-
 	return result, nil
 }
 
-// handleGetStakeVersions implements the getstakeversions command.
-func handleGetStakeVersions(_ context.Context, s *Server, cmd interface{}) (interface{}, error) {
-	c := cmd.(*types.GetStakeVersionsCmd)
+// handleGetTicketInfo implements the getticketinfo command.
+func handleGetTicketInfo(_ context.Context, s *Server, cmd interface{}) (interface{}, error) {
+	c := cmd.(*types.GetTicketInfoCmd)
 
-	hash, err := chainhash.NewHashFromStr(c.Hash)
+	// Convert the provided ticket hash hex to a Hash.
+	ticketHash, err := chainhash.NewHashFromStr(c.Hash)
 	if err != nil {
 		return nil, rpcDecodeHexError(c.Hash)
 	}
-	if c.Count <= 0 {
-		return nil, rpcInvalidError("Invalid parameter, count must " +
-			"be > 0")
-	}
 
-	sv, err := s.cfg.Chain.GetStakeVersions(hash, c.Count)
+	ticketIndex, err := s.syncedTicketIndexer()
 	if err != nil {
-		return nil, rpcInternalError(err.Error(),
-			"Could not obtain stake versions")
+		return nil, err
 	}
 
-	result := types.GetStakeVersionsResult{
-		StakeVersions: make([]types.StakeVersions, 0, len(sv)),
+	entry, err := ticketIndex.Entry(ticketHash)
+	if err != nil {
+		context := "Failed to retrieve ticket"
+		return nil, rpcInternalError(err.Error(), context)
 	}
-	for _, v := range sv {
-		nsv := types.StakeVersions{
-			Hash:         v.Hash.String(),
-			Height:       v.Height,
-			BlockVersion: v.BlockVersion,
-			StakeVersion: v.StakeVersion,
-			Votes: make([]types.VersionBits, 0,
-				len(v.Votes)),
-		}
-		for _, vote := range v.Votes {
-			nsv.Votes = append(nsv.Votes,
-				types.VersionBits{Version: vote.Version, Bits: vote.Bits})
-		}
-
-		result.StakeVersions = append(result.StakeVersions, nsv)
+	if entry == nil {
+		return nil, rpcNoTxInfoError(ticketHash)
 	}
 
-	return result, nil
+	bestHeight := s.cfg.Chain.BestSnapshot().Height
+	return s.ticketInfoResult(entry, bestHeight)
 }
 
 // handleGetTicketPoolValue implements the getticketpoolvalue command.
@@ -3397,20 +4411,110 @@ func handleGetTxOut(_ context.Context, s *Server, cmd interface{}) (interface{},
 
 // handleGetTxOutSetInfo returns statistics on the current unspent transaction output set.
 func handleGetTxOutSetInfo(_ context.Context, s *Server, cmd interface{}) (interface{}, error) {
-	best := s.cfg.Chain.BestSnapshot()
-	stats, err := s.cfg.Chain.FetchUtxoStats()
+	c := cmd.(*types.GetTxOutSetInfoCmd)
+
+	// Calculate the statistics from the entire unspent output set as of the
+	// current best block when the coin statistics index is either not enabled
+	// or not requested to be used.
+	useIndex := c.UseIndex == nil || *c.UseIndex
+	if c.HashOrHeight == nil && (!useIndex || s.cfg.CoinStatsIndexer == nil) {
+		best := s.cfg.Chain.BestSnapshot()
+		stats, err := s.cfg.Chain.FetchUtxoStats()
+		if err != nil {
+			return nil, err
+		}
+
+		return types.GetTxOutSetInfoResult{
+			Height:         best.Height,
+			BestBlock:      best.Hash.String(),
+			Transactions:   stats.Transactions,
+			TxOuts:         stats.Utxos,
+			DiskSize:       stats.Size,
+			TotalAmount:    stats.Total,
+			SerializedHash: stats.SerializedHash.String(),
+		}, nil
+	}
+
+	// The statistics as of blocks other than the current best block are only
+	// available from the coin statistics index.
+	if !useIndex {
+		return nil, rpcInvalidError("Statistics for a specific block " +
+			"require the coin statistics index to be used")
+	}
+	coinStatsIndex, err := s.syncedCoinStatsIndexer()
 	if err != nil {
 		return nil, err
 	}
 
+	var height int64
+	var hash *chainhash.Hash
+	if c.HashOrHeight == nil {
+		height, hash, err = coinStatsIndex.Tip()
+		if err != nil {
+			return nil, rpcInternalError(err.Error(), "Tip")
+		}
+	} else {
+		chain := s.cfg.Chain
+		hash, err = mainChainHashByHashOrHeight(chain, *c.HashOrHeight)
+		if err != nil {
+			return nil, err
+		}
+		header, err := chain.HeaderByHash(hash)
+		if err != nil {
+			return nil, rpcBlockNotFoundError(*hash)
+		}
+		height = int64(header.Height)
+	}
+
+	// The statistics for blocks in the main chain are only missing when the
+	// index has not caught up to the block yet.
+	stats, err := coinStatsIndex.Stats(hash)
+	if err != nil {
+		context := "Failed to retrieve coin statistics"
+		return nil, rpcInternalError(err.Error(), context)
+	}
+	if stats == nil {
+		msg := fmt.Sprintf("%s: index not synced", coinStatsIndex.Name())
+		return nil, rpcInternalError(msg, "Sync")
+	}
+
 	return types.GetTxOutSetInfoResult{
-		Height:         best.Height,
-		BestBlock:      best.Hash.String(),
-		Transactions:   stats.Transactions,
-		TxOuts:         stats.Utxos,
-		DiskSize:       stats.Size,
-		TotalAmount:    stats.Total,
-		SerializedHash: stats.SerializedHash.String(),
+		Height:      height,
+		BestBlock:   hash.String(),
+		TxOuts:      stats.Utxos,
+		SetHash:     stats.SetHash.String(),
+		BogoSize:    stats.Size,
+		TotalAmount: stats.TotalAmount,
+	}, nil
+}
+
+// handleGetUtxoCacheInfo implements the getutxocacheinfo command.
+func handleGetUtxoCacheInfo(_ context.Context, s *Server, _ interface{}) (interface{}, error) {
+	info := s.cfg.Chain.UtxoCacheInfo()
+
+	var lastFlushTime int64
+	if !info.LastFlushTime.IsZero() {
+		lastFlushTime = info.LastFlushTime.Unix()
+	}
+
+	return types.GetUtxoCacheInfoResult{
+		MaxSize:            info.MaxSize,
+		Size:               info.Size,
+		Entries:            info.Entries,
+		DirtyEntries:       info.DirtyEntries,
+		DirtySize:          info.DirtySize,
+		Hits:               info.Hits,
+		Misses:             info.Misses,
+		HitRatio:           info.HitRatio,
+		Prefetched:         info.Prefetched,
+		MemoryLimit:        info.MemoryLimit,
+		MemoryInUse:        info.MemoryInUse,
+		Flushes:            info.Flushes,
+		LastFlushHash:      info.LastFlushHash.String(),
+		LastFlushTime:      lastFlushTime,
+		LastFlushReason:    info.LastFlushReason,
+		LastFlushDuration:  info.LastFlushDuration.Milliseconds(),
+		TotalFlushDuration: info.TotalFlushDuration.Milliseconds(),
 	}, nil
 }
 
@@ -3767,6 +4871,24 @@ func handleInvalidateBlock(_ context.Context, s *Server, cmd interface{}) (inter
 	return nil, nil
 }
 
+// handleListBanned implements the listbanned command.
+func handleListBanned(_ context.Context, s *Server, cmd interface{}) (interface{}, error) {
+	bans, err := s.cfg.ConnMgr.BannedSubnets()
+	if err != nil {
+		return nil, rpcInternalError(err.Error(), "Failed to list bans")
+	}
+
+	result := make([]types.ListBannedResult, 0, len(bans))
+	for _, ban := range bans {
+		result = append(result, types.ListBannedResult{
+			Address:     ban.Subnet.String(),
+			BanCreated:  ban.Created.Unix(),
+			BannedUntil: ban.Expires.Unix(),
+		})
+	}
+	return result, nil
+}
+
 // handleLiveTickets implements the livetickets command.
 func handleLiveTickets(_ context.Context, s *Server, cmd interface{}) (interface{}, error) {
 	lt, err := s.cfg.Chain.LiveTickets()
@@ -3918,7 +5040,12 @@ func fetchInputTxos(s *Server, tx *wire.MsgTx, isTreasuryEnabled bool) (map[wire
 		}
 
 		// Ensure the tx index is synced.
-		txIndex := s.cfg.TxIndexer
+		txIndex := s.txIndexer()
+		if txIndex == nil {
+			return nil, rpcInternalError("The transaction index "+
+				"must be enabled to query the blockchain "+
+				"(specify --txindex)", "Configuration")
+		}
 		tHeight, tHash, err := txIndex.Tip()
 		if err != nil {
 			return nil, rpcInternalError(err.Error(), "Tip")
@@ -4153,10 +5280,10 @@ func createVinListPrevOut(s *Server, mtx *wire.MsgTx,
 // fetchMempoolTxnsForAddress queries the address index for all unconfirmed
 // transactions that involve the provided address.  The results will be limited
 // by the number to skip and the number requested.
-func fetchMempoolTxnsForAddress(s *Server, addr stdaddr.Address, numToSkip, numRequested uint32) ([]*dcrutil.Tx, uint32) {
+func fetchMempoolTxnsForAddress(addrIndex AddrIndexer, addr stdaddr.Address, numToSkip, numRequested uint32) ([]*dcrutil.Tx, uint32) {
 	// There are no entries to return when there are less available than
 	// the number being skipped.
-	mpTxns := s.cfg.AddrIndexer.UnconfirmedTxnsForAddress(addr)
+	mpTxns := addrIndex.UnconfirmedTxnsForAddress(addr)
 	numAvailable := uint32(len(mpTxns))
 	if numToSkip > numAvailable {
 		return nil, numAvailable
@@ -4174,7 +5301,8 @@ func fetchMempoolTxnsForAddress(s *Server, addr stdaddr.Address, numToSkip, numR
 // handleSearchRawTransactions implements the searchrawtransactions command.
 func handleSearchRawTransactions(_ context.Context, s *Server, cmd interface{}) (interface{}, error) {
 	// Respond with an error if the address index is not enabled.
-	if s.cfg.AddrIndexer == nil {
+	addrIndex := s.addrIndexer()
+	if addrIndex == nil {
 		return nil, rpcInternalError("Address index must be "+
 			"enabled (--addrindex)", "Configuration")
 	}
@@ -4191,7 +5319,7 @@ func handleSearchRawTransactions(_ context.Context, s *Server, cmd interface{})
 	// transaction index.  Currently the address index relies on the
 	// transaction index, so this check is redundant, but it's better to be
 	// safe in case the address index is ever changed to not rely on it.
-	if vinExtra && s.cfg.TxIndexer == nil {
+	if vinExtra && s.txIndexer() == nil {
 		return nil, rpcInternalError("Transaction index must be "+
 			"enabled (--txindex)", "Configuration")
 	}
@@ -4249,7 +5377,7 @@ func handleSearchRawTransactions(_ context.Context, s *Server, cmd interface{})
 		// Transactions in the mempool are not in a block yet, so the block and
 		// block index fields in the retrieved transaction struct are left
 		// unset.
-		mpTxns, mpSkipped := fetchMempoolTxnsForAddress(s, addr,
+		mpTxns, mpSkipped := fetchMempoolTxnsForAddress(addrIndex, addr,
 			uint32(numToSkip), uint32(numRequested))
 		numSkipped += mpSkipped
 		for _, tx := range mpTxns {
@@ -4261,7 +5389,6 @@ func handleSearchRawTransactions(_ context.Context, s *Server, cmd interface{})
 	// are needed.
 	if len(addressTxns) < numRequested {
 		// Ensure the adddr index is synced.
-		addrIndex := s.cfg.AddrIndexer
 		tHeight, tHash, err := addrIndex.Tip()
 		if err != nil {
 			return nil, rpcInternalError(err.Error(), "Tip")
@@ -4334,7 +5461,7 @@ func handleSearchRawTransactions(_ context.Context, s *Server, cmd interface{})
 	if !reverse && len(addressTxns) < numRequested {
 		// Transactions in the mempool are not in a block yet, so the block
 		// field in the retrieved transaction struct is left nil.
-		mpTxns, mpSkipped := fetchMempoolTxnsForAddress(s, addr,
+		mpTxns, mpSkipped := fetchMempoolTxnsForAddress(addrIndex, addr,
 			uint32(numToSkip)-numSkipped, uint32(numRequested-
 				len(addressTxns)))
 		numSkipped += mpSkipped
@@ -4495,6 +5622,120 @@ func handleSearchRawTransactions(_ context.Context, s *Server, cmd interface{})
 	return srtList, nil
 }
 
+// syncedScriptIndexer returns the script indexer once it is synced with the
+// main chain.  An error suitable for returning to the caller is returned when
+// the index is not enabled or is not synced.
+func (s *Server) syncedScriptIndexer() (ScriptIndexer, error) {
+	scriptIndex := s.cfg.ScriptIndexer
+	if scriptIndex == nil {
+		return nil, rpcInternalError("The script index must be "+
+			"enabled (specify --scriptindex)", "Configuration")
+	}
+
+	// Ensure the script index is synced.
+	tHeight, tHash, err := scriptIndex.Tip()
+	if err != nil {
+		return nil, rpcInternalError(err.Error(), "Tip")
+	}
+
+	chain := s.cfg.Chain
+
+	// Return an out-of-sync error if index is lagging a
+	// maximum reorg depth (6) blocks or more from the chain tip.
+	if chain.BestSnapshot().Height > (tHeight + 5) {
+		msg := fmt.Sprintf("%s: index not synced", scriptIndex.Name())
+		return nil, rpcInternalError(msg, "Sync")
+	}
+
+sync:
+	for !chain.BestSnapshot().Hash.IsEqual(tHash) {
+		select {
+		case <-time.After(syncWait):
+			msg := fmt.Sprintf("%s: index not synced", scriptIndex.Name())
+			return nil, rpcInternalError(msg, "Sync")
+		case <-scriptIndex.WaitForSync():
+			break sync
+		}
+	}
+
+	return scriptIndex, nil
+}
+
+// handleSearchScripts implements the searchscripts command.
+func handleSearchScripts(_ context.Context, s *Server, cmd interface{}) (interface{}, error) {
+	c := cmd.(*types.SearchScriptsCmd)
+
+	scriptIndex, err := s.syncedScriptIndexer()
+	if err != nil {
+		return nil, err
+	}
+
+	mode := types.SearchScriptsScript
+	if c.Mode != nil {
+		mode = *c.Mode
+	}
+	numToSkip, numRequested := addrBalancePagination(c.Skip, c.Count)
+	var outputs []indexers.ScriptOutput
+	switch mode {
+	case types.SearchScriptsScript, types.SearchScriptsNullData:
+		data, err := hex.DecodeString(c.Data)
+		if err != nil {
+			return nil, rpcDecodeHexError(c.Data)
+		}
+		if mode == types.SearchScriptsScript {
+			outputs, _, err = scriptIndex.OutputsByScript(data, numToSkip,
+				numRequested)
+		} else {
+			outputs, _, err = scriptIndex.OutputsByNullDataPrefix(data,
+				numToSkip, numRequested)
+		}
+		if err != nil {
+			return nil, rpcInternalError(err.Error(),
+				"Failed to retrieve script outputs")
+		}
+
+	case types.SearchScriptsHash:
+		scriptHash, err := chainhash.NewHashFromStr(c.Data)
+		if err != nil {
+			return nil, rpcDecodeHexError(c.Data)
+		}
+		outputs, _, err = scriptIndex.OutputsByScriptHash(scriptHash,
+			numToSkip, numRequested)
+		if err != nil {
+			return nil, rpcInternalError(err.Error(),
+				"Failed to retrieve script outputs")
+		}
+
+	default:
+		return nil, rpcInvalidError("Invalid mode %q -- must be one of %q, "+
+			"%q, or %q", mode, types.SearchScriptsScript,
+			types.SearchScriptsHash, types.SearchScriptsNullData)
+	}
+
+	chain := s.cfg.Chain
+	results := make([]types.SearchScriptsResult, 0, len(outputs))
+	for i := range outputs {
+		output := &outputs[i]
+		blockHash, err := chain.BlockHashByHeight(output.BlockHeight)
+		if err != nil {
+			context := "Failed to fetch block hash"
+			return nil, rpcInternalError(err.Error(), context)
+		}
+
+		results = append(results, types.SearchScriptsResult{
+			TxID:          output.OutPoint.Hash.String(),
+			Vout:          output.OutPoint.Index,
+			Tree:          output.OutPoint.Tree,
+			BlockHash:     blockHash.String(),
+			BlockHeight:   output.BlockHeight,
+			Value:         dcrutil.Amount(output.Amount).ToCoin(),
+			ScriptVersion: output.ScriptVersion,
+			Script:        hex.EncodeToString(output.Script),
+		})
+	}
+	return results, nil
+}
+
 // handleSendRawTransaction implements the sendrawtransaction command.
 func handleSendRawTransaction(_ context.Context, s *Server, cmd interface{}) (interface{}, error) {
 	c := cmd.(*types.SendRawTransactionCmd)
@@ -4593,6 +5834,51 @@ func handleSendRawTransaction(_ context.Context, s *Server, cmd interface{}) (in
 	return tx.Hash().String(), nil
 }
 
+// handleSetBan implements the setban command.
+func handleSetBan(_ context.Context, s *Server, cmd interface{}) (interface{}, error) {
+	c := cmd.(*types.SetBanCmd)
+
+	subnet, err := banmanager.ParseSubnet(c.Subnet)
+	if err != nil {
+		return nil, rpcInvalidError("%v", err)
+	}
+
+	connMgr := s.cfg.ConnMgr
+	switch c.SubCmd {
+	case types.SBAdd:
+		duration := s.cfg.BanDuration
+		if c.Duration != nil {
+			if *c.Duration <= 0 {
+				return nil, rpcInvalidError("Ban duration must be "+
+					"positive -- got %d", *c.Duration)
+			}
+			duration = time.Duration(*c.Duration) * time.Second
+		}
+		until := s.cfg.Clock.Now().Add(duration)
+		if err := connMgr.BanSubnet(subnet, until); err != nil {
+			context := fmt.Sprintf("Failed to ban subnet %s", subnet)
+			return nil, rpcInternalError(err.Error(), context)
+		}
+
+	case types.SBRemove:
+		err := connMgr.UnbanSubnet(subnet)
+		if errors.Is(err, banmanager.ErrNotBanned) {
+			return nil, rpcInvalidError("%v: %v", c.SubCmd, err)
+		}
+		if err != nil {
+			context := fmt.Sprintf("Failed to remove ban for subnet %s",
+				subnet)
+			return nil, rpcInternalError(err.Error(), context)
+		}
+
+	default:
+		return nil, rpcInvalidError("%v: invalid subcommand for setban",
+			c.SubCmd)
+	}
+
+	return nil, nil
+}
+
 // handleSetGenerate implements the setgenerate command.
 func handleSetGenerate(_ context.Context, s *Server, cmd interface{}) (interface{}, error) {
 	c := cmd.(*types.SetGenerateCmd)
@@ -5339,6 +6625,73 @@ type Server struct {
 	workState              *workState
 	helpCacher             RPCHelpCacher
 	requestProcessShutdown chan struct{}
+
+	// indexMtx protects the indexers in the config that may be changed while
+	// the server is running.
+	indexMtx sync.RWMutex
+}
+
+// txIndexer returns the transaction indexer or nil when the transaction index
+// is not enabled.
+//
+// This function is safe for concurrent access.
+func (s *Server) txIndexer() TxIndexer {
+	s.indexMtx.RLock()
+	txIndex := s.cfg.TxIndexer
+	s.indexMtx.RUnlock()
+	return txIndex
+}
+
+// addrIndexer returns the address indexer or nil when the address index is not
+// enabled.
+//
+// This function is safe for concurrent access.
+func (s *Server) addrIndexer() AddrIndexer {
+	s.indexMtx.RLock()
+	addrIndex := s.cfg.AddrIndexer
+	s.indexMtx.RUnlock()
+	return addrIndex
+}
+
+// existsAddresser returns the exists address indexer or nil when the exists
+// address index is not enabled.
+//
+// This function is safe for concurrent access.
+func (s *Server) existsAddresser() ExistsAddresser {
+	s.indexMtx.RLock()
+	existsAddrIndex := s.cfg.ExistsAddresser
+	s.indexMtx.RUnlock()
+	return existsAddrIndex
+}
+
+// SetTxIndexer sets the transaction indexer the server uses.  It may be nil
+// to indicate the transaction index is not enabled.
+//
+// This function is safe for concurrent access.
+func (s *Server) SetTxIndexer(txIndex TxIndexer) {
+	s.indexMtx.Lock()
+	s.cfg.TxIndexer = txIndex
+	s.indexMtx.Unlock()
+}
+
+// SetAddrIndexer sets the address indexer the server uses.  It may be nil to
+// indicate the address index is not enabled.
+//
+// This function is safe for concurrent access.
+func (s *Server) SetAddrIndexer(addrIndex AddrIndexer) {
+	s.indexMtx.Lock()
+	s.cfg.AddrIndexer = addrIndex
+	s.indexMtx.Unlock()
+}
+
+// SetExistsAddresser sets the exists address indexer the server uses.  It may
+// be nil to indicate the exists address index is not enabled.
+//
+// This function is safe for concurrent access.
+func (s *Server) SetExistsAddresser(existsAddrIndex ExistsAddresser) {
+	s.indexMtx.Lock()
+	s.cfg.ExistsAddresser = existsAddrIndex
+	s.indexMtx.Unlock()
 }
 
 // isTreasuryAgendaActive returns if the treasury agenda is active or not for
@@ -5473,9 +6826,10 @@ func (s *Server) NotifyNewTickets(tnd *blockchain.TicketNotificationsData) {
 }
 
 // NotifyBlockConnected notifies websocket clients that have registered for
-// block updates when a block is connected to the main chain.
-func (s *Server) NotifyBlockConnected(block *dcrutil.Block) {
-	s.ntfnMgr.NotifyBlockConnected(block)
+// block or address updates when a block is connected to the main chain.  The
+// previous scripts provide the scripts of the outputs spent by the block.
+func (s *Server) NotifyBlockConnected(block *dcrutil.Block, prevScripts indexers.PrevScripter) {
+	s.ntfnMgr.NotifyBlockConnected(block, prevScripts)
 }
 
 // NotifySpentAndMissedTickets notifies websocket clients that have registered
@@ -6218,6 +7572,38 @@ type Config struct {
 	// AddrIndexer defines the optional address indexer for the RPC server to use.
 	AddrIndexer AddrIndexer
 
+	// SpenderIndexer defines the optional spender indexer for the RPC server to
+	// use.
+	SpenderIndexer SpenderIndexer
+
+	// AddrBalanceIndexer defines the optional address balance indexer for the
+	// RPC server to use.
+	AddrBalanceIndexer AddrBalanceIndexer
+
+	// BlockStatsIndexer defines the optional block statistics indexer for the
+	// RPC server to use.
+	BlockStatsIndexer BlockStatsIndexer
+
+	// TicketIndexer defines the optional ticket indexer for the RPC server to
+	// use.
+	TicketIndexer TicketIndexer
+
+	// CoinStatsIndexer defines the optional coin statistics indexer for the
+	// RPC server to use.
+	CoinStatsIndexer CoinStatsIndexer
+
+	// CFHeaderIndexer defines the optional filter header indexer for the RPC
+	// server to use.
+	CFHeaderIndexer CFHeaderIndexer
+
+	// ScriptIndexer defines the optional script indexer for the RPC server to
+	// use.
+	ScriptIndexer ScriptIndexer
+
+	// IndexManager defines the index manager for the RPC server to use to
+	// enable and disable optional indexes while it is running.
+	IndexManager IndexManager
+
 	// NetInfo defines a slice of the available networks.
 	NetInfo []types.NetworksResult
 
@@ -6228,6 +7614,10 @@ type Config struct {
 	// Proxy defines the proxy that is being used for connections.
 	Proxy string
 
+	// BanDuration defines how long subnets banned via the setban command stay
+	// banned when no duration is specified.
+	BanDuration time.Duration
+
 	// These fields define the username and password for RPC connections and
 	// limited RPC connections.
 	RPCUser      string
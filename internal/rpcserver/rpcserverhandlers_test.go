@@ -10,6 +10,7 @@ import (
 	"context"
 	"encoding/gob"
 	"encoding/hex"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"math"
@@ -38,6 +39,7 @@ import (
 	"github.com/EXCCoin/exccd/gcs/v3/blockcf2"
 	"github.com/EXCCoin/exccd/internal/mempool"
 	"github.com/EXCCoin/exccd/internal/mining"
+	"github.com/EXCCoin/exccd/internal/staging/banmanager"
 	"github.com/EXCCoin/exccd/internal/version"
 	"github.com/EXCCoin/exccd/peer/v3"
 	"github.com/EXCCoin/exccd/rpc/jsonrpc/types/v3"
@@ -196,6 +198,7 @@ type testRPCChain struct {
 	ticketsWithAddress            []chainhash.Hash
 	ticketsWithAddressErr         error
 	tipGeneration                 []chainhash.Hash
+	utxoCacheInfo                 *blockchain.UtxoCacheInfo
 	tspendVotes                   tspendVotes
 	treasuryActive                bool
 	treasuryActiveErr             error
@@ -418,6 +421,11 @@ func (c *testRPCChain) TipGeneration() ([]chainhash.Hash, error) {
 	return c.tipGeneration, nil
 }
 
+// UtxoCacheInfo returns a mocked blockchain.UtxoCacheInfo.
+func (c *testRPCChain) UtxoCacheInfo() *blockchain.UtxoCacheInfo {
+	return c.utxoCacheInfo
+}
+
 // IsTreasuryAgendaActive returns a mocked bool representing whether or not the
 // treasury agenda is active.
 func (c *testRPCChain) IsTreasuryAgendaActive(*chainhash.Hash) (bool, error) {
@@ -457,6 +465,9 @@ type testPeer struct {
 	lastPingNonce     uint64
 	isTxRelayDisabled bool
 	banScore          uint32
+	connType          string
+	mappedAS          uint32
+	msgStats          *MsgStats
 	statsSnapshot     *peer.StatsSnap
 }
 
@@ -509,6 +520,27 @@ func (p *testPeer) BanScore() uint32 {
 	return p.banScore
 }
 
+// ConnType returns a mocked description of the type of the connection to the
+// peer.
+func (p *testPeer) ConnType() string {
+	return p.connType
+}
+
+// MappedAS returns a mocked autonomous system number the address of the peer
+// is mapped to.
+func (p *testPeer) MappedAS() uint32 {
+	return p.mappedAS
+}
+
+// MsgStats returns mocked numbers of messages and bytes sent to and received
+// from the peer per message type.
+func (p *testPeer) MsgStats() *MsgStats {
+	if p.msgStats == nil {
+		return &MsgStats{}
+	}
+	return p.msgStats
+}
+
 // testAddrManager provides a mock address manager by implementing the
 // AddrManager interface.
 type testAddrManager struct {
@@ -693,6 +725,279 @@ func (t *testTxIndexer) Entry(hash *chainhash.Hash) (*indexers.TxIndexEntry, err
 	return t.entry(hash)
 }
 
+// testSpenderIndexer provides a mock spender indexer by implementing the
+// SpenderIndexer interface.
+type testSpenderIndexer struct {
+	entry        *indexers.SpenderIndexEntry
+	entryErr     error
+	tipHeight    int64
+	tipHash      *chainhash.Hash
+	tipErr       error
+	signalOnWait bool
+}
+
+// Name returns the human-readable name of the index.
+func (s *testSpenderIndexer) Name() string {
+	return "testSpenderIndexer"
+}
+
+// Tip returns the current index tip.
+func (s *testSpenderIndexer) Tip() (int64, *chainhash.Hash, error) {
+	return s.tipHeight, s.tipHash, s.tipErr
+}
+
+// WaitForSync subscribes clients for the next index sync update.
+func (s *testSpenderIndexer) WaitForSync() chan bool {
+	c := make(chan bool)
+	if s.signalOnWait {
+		close(c)
+	}
+	return c
+}
+
+// Entry returns mocked details about the transaction that spends the provided
+// outpoint from the spender index.
+func (s *testSpenderIndexer) Entry(outpoint *wire.OutPoint) (*indexers.SpenderIndexEntry, error) {
+	return s.entry, s.entryErr
+}
+
+// testAddrBalanceIndexer provides a mock address balance indexer by
+// implementing the AddrBalanceIndexer interface.
+type testAddrBalanceIndexer struct {
+	balance           *indexers.AddrBalance
+	balanceErr        error
+	utxos             []indexers.AddrUtxo
+	utxosErr          error
+	deltas            []indexers.AddrDelta
+	deltasSkipped     uint32
+	deltasErr         error
+	unconfirmedDeltas []indexers.AddrDelta
+	tipHeight         int64
+	tipHash           *chainhash.Hash
+	tipErr            error
+	signalOnWait      bool
+}
+
+// Name returns the human-readable name of the index.
+func (a *testAddrBalanceIndexer) Name() string {
+	return "testAddrBalanceIndexer"
+}
+
+// Tip returns the current index tip.
+func (a *testAddrBalanceIndexer) Tip() (int64, *chainhash.Hash, error) {
+	return a.tipHeight, a.tipHash, a.tipErr
+}
+
+// WaitForSync subscribes clients for the next index sync update.
+func (a *testAddrBalanceIndexer) WaitForSync() chan bool {
+	c := make(chan bool)
+	if a.signalOnWait {
+		close(c)
+	}
+	return c
+}
+
+// Balance returns the mocked confirmed totals of the provided address.
+func (a *testAddrBalanceIndexer) Balance(addr stdaddr.Address) (*indexers.AddrBalance, error) {
+	return a.balance, a.balanceErr
+}
+
+// Utxos returns the mocked confirmed unspent outputs paid to the provided
+// address.
+func (a *testAddrBalanceIndexer) Utxos(addr stdaddr.Address, numToSkip, numRequested uint32) ([]indexers.AddrUtxo, uint32, error) {
+	return a.utxos, 0, a.utxosErr
+}
+
+// Deltas returns the mocked confirmed credits and debits of the provided
+// address.
+func (a *testAddrBalanceIndexer) Deltas(addr stdaddr.Address, numToSkip, numRequested uint32) ([]indexers.AddrDelta, uint32, error) {
+	return a.deltas, a.deltasSkipped, a.deltasErr
+}
+
+// UnconfirmedDeltas returns the mocked credits and debits of the provided
+// address made by transactions in the memory pool.
+func (a *testAddrBalanceIndexer) UnconfirmedDeltas(addr stdaddr.Address) []indexers.AddrDelta {
+	return a.unconfirmedDeltas
+}
+
+// testBlockStatsIndexer provides a mock block statistics indexer by
+// implementing the BlockStatsIndexer interface.
+type testBlockStatsIndexer struct {
+	stats    *indexers.BlockStats
+	statsErr error
+}
+
+// Name returns the human-readable name of the index.
+func (b *testBlockStatsIndexer) Name() string {
+	return "testBlockStatsIndexer"
+}
+
+// Stats returns the mocked statistics for the block with the provided hash.
+func (b *testBlockStatsIndexer) Stats(hash *chainhash.Hash) (*indexers.BlockStats, error) {
+	return b.stats, b.statsErr
+}
+
+// testTicketIndexer provides a mock ticket indexer by implementing the
+// TicketIndexer interface.
+type testTicketIndexer struct {
+	entry        *indexers.TicketEntry
+	entryErr     error
+	tickets      []indexers.TicketEntry
+	ticketsErr   error
+	tipHeight    int64
+	tipHash      *chainhash.Hash
+	tipErr       error
+	signalOnWait bool
+}
+
+// Name returns the human-readable name of the index.
+func (ti *testTicketIndexer) Name() string {
+	return "testTicketIndexer"
+}
+
+// Tip returns the current index tip.
+func (ti *testTicketIndexer) Tip() (int64, *chainhash.Hash, error) {
+	return ti.tipHeight, ti.tipHash, ti.tipErr
+}
+
+// WaitForSync subscribes clients for the next index sync update.
+func (ti *testTicketIndexer) WaitForSync() chan bool {
+	c := make(chan bool)
+	if ti.signalOnWait {
+		close(c)
+	}
+	return c
+}
+
+// Entry returns the mocked lifecycle details of the provided ticket.
+func (ti *testTicketIndexer) Entry(hash *chainhash.Hash) (*indexers.TicketEntry, error) {
+	return ti.entry, ti.entryErr
+}
+
+// TicketsForAddress returns the mocked lifecycle details of the tickets
+// associated with the provided address.
+func (ti *testTicketIndexer) TicketsForAddress(addr stdaddr.Address, numToSkip, numRequested uint32) ([]indexers.TicketEntry, uint32, error) {
+	return ti.tickets, 0, ti.ticketsErr
+}
+
+// testCoinStatsIndexer provides a mock coin statistics indexer by implementing
+// the CoinStatsIndexer interface.
+type testCoinStatsIndexer struct {
+	stats        *indexers.CoinStats
+	statsErr     error
+	tipHeight    int64
+	tipHash      *chainhash.Hash
+	tipErr       error
+	signalOnWait bool
+}
+
+// Name returns the human-readable name of the index.
+func (c *testCoinStatsIndexer) Name() string {
+	return "testCoinStatsIndexer"
+}
+
+// Tip returns the current index tip.
+func (c *testCoinStatsIndexer) Tip() (int64, *chainhash.Hash, error) {
+	return c.tipHeight, c.tipHash, c.tipErr
+}
+
+// WaitForSync subscribes clients for the next index sync update.
+func (c *testCoinStatsIndexer) WaitForSync() chan bool {
+	ch := make(chan bool)
+	if c.signalOnWait {
+		close(ch)
+	}
+	return ch
+}
+
+// Stats returns the mocked unspent transaction output set statistics for the
+// block with the provided hash.
+func (c *testCoinStatsIndexer) Stats(hash *chainhash.Hash) (*indexers.CoinStats, error) {
+	return c.stats, c.statsErr
+}
+
+// testCFHeaderIndexer provides a mock filter header indexer by implementing
+// the CFHeaderIndexer interface.
+type testCFHeaderIndexer struct {
+	entry        *indexers.CFHeaderEntry
+	entryErr     error
+	tipHeight    int64
+	tipHash      *chainhash.Hash
+	tipErr       error
+	signalOnWait bool
+}
+
+// Name returns the human-readable name of the index.
+func (c *testCFHeaderIndexer) Name() string {
+	return "testCFHeaderIndexer"
+}
+
+// Tip returns the current index tip.
+func (c *testCFHeaderIndexer) Tip() (int64, *chainhash.Hash, error) {
+	return c.tipHeight, c.tipHash, c.tipErr
+}
+
+// WaitForSync subscribes clients for the next index sync update.
+func (c *testCFHeaderIndexer) WaitForSync() chan bool {
+	ch := make(chan bool)
+	if c.signalOnWait {
+		close(ch)
+	}
+	return ch
+}
+
+// Entry returns the mocked filter hash and filter header for the block with
+// the provided hash.
+func (c *testCFHeaderIndexer) Entry(hash *chainhash.Hash) (*indexers.CFHeaderEntry, error) {
+	return c.entry, c.entryErr
+}
+
+// testScriptIndexer provides a mock script indexer by implementing the
+// ScriptIndexer interface.
+type testScriptIndexer struct {
+	outputs      []indexers.ScriptOutput
+	outputsErr   error
+	tipHeight    int64
+	tipHash      *chainhash.Hash
+	tipErr       error
+	signalOnWait bool
+}
+
+// Name returns the human-readable name of the index.
+func (s *testScriptIndexer) Name() string {
+	return "testScriptIndexer"
+}
+
+// Tip returns the current index tip.
+func (s *testScriptIndexer) Tip() (int64, *chainhash.Hash, error) {
+	return s.tipHeight, s.tipHash, s.tipErr
+}
+
+// WaitForSync subscribes clients for the next index sync update.
+func (s *testScriptIndexer) WaitForSync() chan bool {
+	ch := make(chan bool)
+	if s.signalOnWait {
+		close(ch)
+	}
+	return ch
+}
+
+// OutputsByScript returns the mocked outputs for the provided script.
+func (s *testScriptIndexer) OutputsByScript(script []byte, numToSkip, numRequested uint32) ([]indexers.ScriptOutput, uint32, error) {
+	return s.outputs, 0, s.outputsErr
+}
+
+// OutputsByScriptHash returns the mocked outputs for the provided script hash.
+func (s *testScriptIndexer) OutputsByScriptHash(scriptHash *chainhash.Hash, numToSkip, numRequested uint32) ([]indexers.ScriptOutput, uint32, error) {
+	return s.outputs, 0, s.outputsErr
+}
+
+// OutputsByNullDataPrefix returns the mocked outputs for the provided null data
+// prefix.
+func (s *testScriptIndexer) OutputsByNullDataPrefix(prefix []byte, numToSkip, numRequested uint32) ([]indexers.ScriptOutput, uint32, error) {
+	return s.outputs, 0, s.outputsErr
+}
+
 // testDB provides a mock database by implementing the database.DB interface.
 type testDB struct {
 	dbType   string
@@ -841,10 +1146,17 @@ type testConnManager struct {
 	connectedCount      int32
 	netTotalReceived    uint64
 	netTotalSent        uint64
+	msgStats            *MsgStats
+	uploadTarget        *UploadTarget
 	connectedPeers      []Peer
 	persistentPeers     []Peer
 	addedNodeInfo       []Peer
 	lookup              func(host string) ([]net.IP, error)
+	banSubnetErr        error
+	unbanSubnetErr      error
+	bannedSubnets       []banmanager.BanEntry
+	bannedSubnetsErr    error
+	clearBansErr        error
 }
 
 // Connect provides a mock implementation for adding the provided address as a
@@ -888,6 +1200,17 @@ func (c *testConnManager) NetTotals() (uint64, uint64) {
 	return c.netTotalReceived, c.netTotalSent
 }
 
+// MsgStats returns mocked numbers of messages and bytes sent and received
+// across the network for all peers per message type.
+func (c *testConnManager) MsgStats() *MsgStats {
+	return c.msgStats
+}
+
+// UploadTarget returns a mocked state of the upload target.
+func (c *testConnManager) UploadTarget() *UploadTarget {
+	return c.uploadTarget
+}
+
 // ConnectedPeers returns a mocked slice of all connected peers.
 func (c *testConnManager) ConnectedPeers() []Peer {
 	return c.connectedPeers
@@ -921,6 +1244,29 @@ func (c *testConnManager) Lookup(host string) ([]net.IP, error) {
 	return c.lookup(host)
 }
 
+// BanSubnet provides a mock implementation for banning the provided subnet
+// until the provided time.
+func (c *testConnManager) BanSubnet(subnet *net.IPNet, until time.Time) error {
+	return c.banSubnetErr
+}
+
+// UnbanSubnet provides a mock implementation for removing the ban for the
+// provided subnet.
+func (c *testConnManager) UnbanSubnet(subnet *net.IPNet) error {
+	return c.unbanSubnetErr
+}
+
+// BannedSubnets provides a mock implementation for returning all subnets that
+// are currently banned.
+func (c *testConnManager) BannedSubnets() ([]banmanager.BanEntry, error) {
+	return c.bannedSubnets, c.bannedSubnetsErr
+}
+
+// ClearBans provides a mock implementation for removing all bans.
+func (c *testConnManager) ClearBans() error {
+	return c.clearBansErr
+}
+
 // testCPUMiner provides a mock CPU miner by implementing the CPUMiner
 // interface.
 type testCPUMiner struct {
@@ -1020,6 +1366,30 @@ func (l *testLogManager) ParseAndSetDebugLevels(debugLevel string) error {
 	return l.parseAndSetDebugLevelsErr
 }
 
+// testIndexManager provides a mock index manager by implementing the
+// IndexManager interface.
+type testIndexManager struct {
+	enableIndexErr  error
+	disableIndexErr error
+	indexInfo       []IndexInfo
+	indexInfoErr    error
+}
+
+// EnableIndex returns a mocked error for enabling the named index.
+func (m *testIndexManager) EnableIndex(name string) error {
+	return m.enableIndexErr
+}
+
+// DisableIndex returns a mocked error for disabling the named index.
+func (m *testIndexManager) DisableIndex(name string) error {
+	return m.disableIndexErr
+}
+
+// IndexInfo returns the mocked status of the optional indexes.
+func (m *testIndexManager) IndexInfo() ([]IndexInfo, error) {
+	return m.indexInfo, m.indexInfoErr
+}
+
 // testSanityChecker provides a mock implementation that checks the sanity
 // state of a block.
 type testSanityChecker struct {
@@ -1180,8 +1550,9 @@ type testNtfnManager struct {
 }
 
 // NotifyBlockConnected passes a block newly-connected to the manager
-// for processing.
-func (mgr *testNtfnManager) NotifyBlockConnected(block *dcrutil.Block) {}
+// for processing along with the scripts of the outputs it spends.
+func (mgr *testNtfnManager) NotifyBlockConnected(block *dcrutil.Block, prevScripts indexers.PrevScripter) {
+}
 
 // NotifyBlockDisconnected passes a block disconnected to the manager
 // for processing.
@@ -1418,10 +1789,18 @@ type rpcTest struct {
 	setAddrIndexerNil     bool
 	mockTxIndexer         *testTxIndexer
 	setTxIndexerNil       bool
+	mockSpenderIndexer    *testSpenderIndexer
+	mockAddrBalIndexer    *testAddrBalanceIndexer
+	mockBlockStatsIndexer *testBlockStatsIndexer
+	mockTicketIndexer     *testTicketIndexer
+	mockCoinStatsIndexer  *testCoinStatsIndexer
+	mockCFHeaderIndexer   *testCFHeaderIndexer
+	mockScriptIndexer     *testScriptIndexer
 	mockDB                *testDB
 	mockConnManager       *testConnManager
 	mockClock             *testClock
 	mockLogManager        *testLogManager
+	mockIndexManager      *testIndexManager
 	mockFiltererV2        *testFiltererV2
 	mockTxMempooler       *testTxMempooler
 	mockMiningAddrs       []stdaddr.Address
@@ -1674,6 +2053,145 @@ func defaultMockTxIndexer() *testTxIndexer {
 	}
 }
 
+// defaultMockSpenderIndexer provides a default mock spender indexer to be
+// used throughout the tests. Tests can override these defaults by calling
+// defaultMockSpenderIndexer, updating fields as necessary on the returned
+// *testSpenderIndexer, and then setting rpcTest.mockSpenderIndexer as that
+// *testSpenderIndexer.
+func defaultMockSpenderIndexer() *testSpenderIndexer {
+	bestHash := block616802.Header.BlockHash()
+	return &testSpenderIndexer{
+		tipHeight:    int64(block616802.Header.Height),
+		tipHash:      &bestHash,
+		signalOnWait: true,
+	}
+}
+
+// defaultMockAddrBalanceIndexer provides a default mock address balance
+// indexer to be used throughout the tests. Tests can override these defaults by
+// calling defaultMockAddrBalanceIndexer, updating fields as necessary on the
+// returned *testAddrBalanceIndexer, and then setting rpcTest.mockAddrBalIndexer
+// as that *testAddrBalanceIndexer.
+func defaultMockAddrBalanceIndexer() *testAddrBalanceIndexer {
+	bestHash := block616802.Header.BlockHash()
+	return &testAddrBalanceIndexer{
+		balance:      new(indexers.AddrBalance),
+		tipHeight:    int64(block616802.Header.Height),
+		tipHash:      &bestHash,
+		signalOnWait: true,
+	}
+}
+
+// defaultMockBlockStatsIndexer provides a default mock block statistics indexer
+// to be used throughout the tests. Tests can override these defaults by calling
+// defaultMockBlockStatsIndexer, updating fields as necessary on the returned
+// *testBlockStatsIndexer, and then setting rpcTest.mockBlockStatsIndexer as
+// that *testBlockStatsIndexer.
+func defaultMockBlockStatsIndexer() *testBlockStatsIndexer {
+	return &testBlockStatsIndexer{
+		stats: &indexers.BlockStats{
+			Size:         2000,
+			NumTxns:      3,
+			NumStakeTxns: 6,
+			NumVotes:     5,
+			NumTickets:   1,
+			NumInputs:    4,
+			NumOutputs:   20,
+			UtxoIncrease: 9,
+			NumFeeTxns:   3,
+			TotalOut:     900000000,
+			TotalFee:     30000,
+			MinFee:       5000,
+			MaxFee:       15000,
+			MedianFee:    10000,
+			MinFeeRate:   20000,
+			MaxFeeRate:   40000,
+			FeeRates:     [5]int64{20000, 20000, 30000, 40000, 40000},
+			TotalTxSize:  900,
+			MinTxSize:    250,
+			MaxTxSize:    375,
+			MedianTxSize: 275,
+		},
+	}
+}
+
+// defaultMockTicketIndexer provides a default mock ticket indexer to be used
+// throughout the tests. Tests can override these defaults by calling
+// defaultMockTicketIndexer, updating fields as necessary on the returned
+// *testTicketIndexer, and then setting rpcTest.mockTicketIndexer as that
+// *testTicketIndexer.
+func defaultMockTicketIndexer() *testTicketIndexer {
+	bestHash := block616802.Header.BlockHash()
+	return &testTicketIndexer{
+		tipHeight:    int64(block616802.Header.Height),
+		tipHash:      &bestHash,
+		signalOnWait: true,
+	}
+}
+
+// defaultMockCoinStatsIndexer provides a default mock coin statistics indexer
+// to be used throughout the tests. Tests can override these defaults by calling
+// defaultMockCoinStatsIndexer, updating fields as necessary on the returned
+// *testCoinStatsIndexer, and then setting rpcTest.mockCoinStatsIndexer as that
+// *testCoinStatsIndexer.
+func defaultMockCoinStatsIndexer() *testCoinStatsIndexer {
+	bestHash := block616802.Header.BlockHash()
+	return &testCoinStatsIndexer{
+		stats: &indexers.CoinStats{
+			Utxos:       1593879,
+			TotalAmount: 1154067750680149,
+			Size:        149425208,
+			SetHash:     *mustParseHash("3ac6c9e0d5d0f9d8c3e8a9d2b2c4f2a3a1b9c5d7e1f3a5b7c9d1e3f5a7b9c1d3"),
+		},
+		tipHeight:    int64(block616802.Header.Height),
+		tipHash:      &bestHash,
+		signalOnWait: true,
+	}
+}
+
+// defaultMockCFHeaderIndexer provides a default mock filter header indexer to
+// be used throughout the tests. Tests can override these defaults by calling
+// defaultMockCFHeaderIndexer, updating fields as necessary on the returned
+// *testCFHeaderIndexer, and then setting rpcTest.mockCFHeaderIndexer as that
+// *testCFHeaderIndexer.
+func defaultMockCFHeaderIndexer() *testCFHeaderIndexer {
+	bestHash := block616802.Header.BlockHash()
+	return &testCFHeaderIndexer{
+		entry: &indexers.CFHeaderEntry{
+			FilterHash: defaultMockFiltererV2().filterByBlockHash.Hash(),
+			Header:     *mustParseHash("5e0e2f1c7b4a3d6e8f9a0b1c2d3e4f5a6b7c8d9e0f1a2b3c4d5e6f7a8b9c0d1e"),
+		},
+		tipHeight:    int64(block616802.Header.Height),
+		tipHash:      &bestHash,
+		signalOnWait: true,
+	}
+}
+
+// defaultMockScriptIndexer provides a default mock script indexer to be used
+// throughout the tests. Tests can override these defaults by calling
+// defaultMockScriptIndexer, updating fields as necessary on the returned
+// *testScriptIndexer, and then setting rpcTest.mockScriptIndexer as that
+// *testScriptIndexer.
+func defaultMockScriptIndexer() *testScriptIndexer {
+	bestHash := block616802.Header.BlockHash()
+	return &testScriptIndexer{
+		outputs: []indexers.ScriptOutput{{
+			OutPoint: wire.OutPoint{
+				Hash:  *mustParseHash("4bc7b6ae3a9b3cf7c2ab2a6bbd43dca1e18ed2ae4ea90bb9b2e8d0d1c6f9b1a0"),
+				Index: 1,
+				Tree:  wire.TxTreeRegular,
+			},
+			BlockHeight:   int64(block616802.Header.Height),
+			Amount:        0,
+			ScriptVersion: 0,
+			Script:        hexToBytes("6a0a0102030405060708090a"),
+		}},
+		tipHeight:    int64(block616802.Header.Height),
+		tipHash:      &bestHash,
+		signalOnWait: true,
+	}
+}
+
 // defaultMockDB provides a default mock database to be used throughout the
 // tests. Tests can override these defaults by calling defaultMockDB, updating
 // fields as necessary on the returned *testDB, and then setting rpcTest.mockDB
@@ -1739,6 +2257,20 @@ func defaultMockConnManager() *testConnManager {
 		connectedCount:   4,
 		netTotalReceived: 9598159,
 		netTotalSent:     4783802,
+		msgStats: &MsgStats{
+			Sent: map[string]MsgStat{
+				"block": {Msgs: 12, Bytes: 4712301},
+				"inv":   {Msgs: 73, Bytes: 71501},
+			},
+			Recv: map[string]MsgStat{
+				"getdata": {Msgs: 12, Bytes: 3560},
+				"inv":     {Msgs: 85, Bytes: 9594599},
+			},
+		},
+		uploadTarget: &UploadTarget{
+			Timeframe:             24 * time.Hour,
+			ServeHistoricalBlocks: true,
+		},
 		connectedPeers: []Peer{
 			testPeer1,
 			testPeer2,
@@ -1786,6 +2318,31 @@ func defaultMockLogManager() *testLogManager {
 	}
 }
 
+// defaultMockIndexManager provides a default mock index manager to be used
+// throughout the tests. Tests can override these defaults by calling
+// defaultMockIndexManager, updating fields as necessary on the returned
+// *testIndexManager, and then setting rpcTest.mockIndexManager as that
+// *testIndexManager.
+func defaultMockIndexManager() *testIndexManager {
+	return &testIndexManager{
+		indexInfo: []IndexInfo{{
+			Name:      "addrindex",
+			Height:    int64(block616802.Header.Height),
+			Hash:      block616802.Header.BlockHash(),
+			Synced:    true,
+			DiskUsage: 4096,
+		}, {
+			Name:      "txindex",
+			Height:    int64(block616802.Header.Height - 1),
+			Hash:      block616802.Header.PrevBlock,
+			DiskUsage: 2048,
+		}, {
+			Name:     "existsaddrindex",
+			Dropping: true,
+		}},
+	}
+}
+
 // defaultMockFiltererV2 provides a default mock V2 filterer to be used
 // throughout the tests. Tests can override these defaults by calling
 // defaultMockFiltererV2, updating fields as necessary on the returned
@@ -2230,6 +2787,28 @@ func TestHandleCreateRawSStx(t *testing.T) {
 	}})
 }
 
+func TestHandleClearBanned(t *testing.T) {
+	t.Parallel()
+
+	testRPCServerHandler(t, []rpcTest{{
+		name:    "handleClearBanned: ok",
+		handler: handleClearBanned,
+		cmd:     &types.ClearBannedCmd{},
+		result:  nil,
+	}, {
+		name:    "handleClearBanned: failed to persist",
+		handler: handleClearBanned,
+		cmd:     &types.ClearBannedCmd{},
+		mockConnManager: func() *testConnManager {
+			connManager := defaultMockConnManager()
+			connManager.clearBansErr = errors.New("unable to write file")
+			return connManager
+		}(),
+		wantErr: true,
+		errCode: dcrjson.ErrRPCInternal.Code,
+	}})
+}
+
 func TestHandleCreateRawSSRtx(t *testing.T) {
 	t.Parallel()
 
@@ -2934,20 +3513,78 @@ func TestHandleDecodeScript(t *testing.T) {
 	}})
 }
 
-func TestHandleEstimateFee(t *testing.T) {
+func TestHandleDisableIndex(t *testing.T) {
 	t.Parallel()
 
 	testRPCServerHandler(t, []rpcTest{{
-		name:    "handleEstimateFee: ok",
-		handler: handleEstimateFee,
-		cmd:     &types.EstimateFeeCmd{},
-		result:  float64(0.0001),
-	}})
-}
-
-func TestHandleEstimateSmartFee(t *testing.T) {
-	t.Parallel()
-
+		name:             "handleDisableIndex: ok",
+		handler:          handleDisableIndex,
+		cmd:              &types.DisableIndexCmd{Name: "txindex"},
+		mockIndexManager: defaultMockIndexManager(),
+		result:           nil,
+	}, {
+		name:    "handleDisableIndex: disable error",
+		handler: handleDisableIndex,
+		cmd:     &types.DisableIndexCmd{Name: "txindex"},
+		mockIndexManager: func() *testIndexManager {
+			indexMgr := defaultMockIndexManager()
+			indexMgr.disableIndexErr = errors.New("index is not enabled")
+			return indexMgr
+		}(),
+		wantErr: true,
+		errCode: dcrjson.ErrRPCInvalidParameter,
+	}, {
+		name:    "handleDisableIndex: index management not available",
+		handler: handleDisableIndex,
+		cmd:     &types.DisableIndexCmd{Name: "txindex"},
+		wantErr: true,
+		errCode: dcrjson.ErrRPCInternal.Code,
+	}})
+}
+
+func TestHandleEnableIndex(t *testing.T) {
+	t.Parallel()
+
+	testRPCServerHandler(t, []rpcTest{{
+		name:             "handleEnableIndex: ok",
+		handler:          handleEnableIndex,
+		cmd:              &types.EnableIndexCmd{Name: "addrindex"},
+		mockIndexManager: defaultMockIndexManager(),
+		result:           nil,
+	}, {
+		name:    "handleEnableIndex: enable error",
+		handler: handleEnableIndex,
+		cmd:     &types.EnableIndexCmd{Name: "unknownindex"},
+		mockIndexManager: func() *testIndexManager {
+			indexMgr := defaultMockIndexManager()
+			indexMgr.enableIndexErr = errors.New("unknown index")
+			return indexMgr
+		}(),
+		wantErr: true,
+		errCode: dcrjson.ErrRPCInvalidParameter,
+	}, {
+		name:    "handleEnableIndex: index management not available",
+		handler: handleEnableIndex,
+		cmd:     &types.EnableIndexCmd{Name: "addrindex"},
+		wantErr: true,
+		errCode: dcrjson.ErrRPCInternal.Code,
+	}})
+}
+
+func TestHandleEstimateFee(t *testing.T) {
+	t.Parallel()
+
+	testRPCServerHandler(t, []rpcTest{{
+		name:    "handleEstimateFee: ok",
+		handler: handleEstimateFee,
+		cmd:     &types.EstimateFeeCmd{},
+		result:  float64(0.0001),
+	}})
+}
+
+func TestHandleEstimateSmartFee(t *testing.T) {
+	t.Parallel()
+
 	conservative := types.EstimateSmartFeeConservative
 	economical := types.EstimateSmartFeeEconomical
 	validFeeEstimator := defaultMockFeeEstimator()
@@ -3810,6 +4447,362 @@ func TestHandleGetAddedNodeInfo(t *testing.T) {
 	}})
 }
 
+func TestHandleGetAddressBalance(t *testing.T) {
+	t.Parallel()
+
+	addr := "22tsq4PQ8GNptB5pmk7Ej8JDhPphuq3rFyLB"
+	testRPCServerHandler(t, []rpcTest{{
+		name:    "handleGetAddressBalance: address balance index disabled",
+		handler: handleGetAddressBalance,
+		cmd: &types.GetAddressBalanceCmd{
+			Address: addr,
+		},
+		wantErr: true,
+		errCode: dcrjson.ErrRPCInternal.Code,
+	}, {
+		name:               "handleGetAddressBalance: bad address",
+		handler:            handleGetAddressBalance,
+		mockAddrBalIndexer: defaultMockAddrBalanceIndexer(),
+		cmd: &types.GetAddressBalanceCmd{
+			Address: "bad address",
+		},
+		wantErr: true,
+		errCode: dcrjson.ErrRPCInvalidAddressOrKey,
+	}, {
+		name:    "handleGetAddressBalance: index not synced",
+		handler: handleGetAddressBalance,
+		mockAddrBalIndexer: func() *testAddrBalanceIndexer {
+			idx := defaultMockAddrBalanceIndexer()
+			idx.tipHeight -= 6
+			return idx
+		}(),
+		cmd: &types.GetAddressBalanceCmd{
+			Address: addr,
+		},
+		wantErr: true,
+		errCode: dcrjson.ErrRPCInternal.Code,
+	}, {
+		name:    "handleGetAddressBalance: unsupported address type",
+		handler: handleGetAddressBalance,
+		mockAddrBalIndexer: func() *testAddrBalanceIndexer {
+			idx := defaultMockAddrBalanceIndexer()
+			idx.balanceErr = indexers.ErrUnsupportedAddressType
+			return idx
+		}(),
+		cmd: &types.GetAddressBalanceCmd{
+			Address: addr,
+		},
+		wantErr: true,
+		errCode: dcrjson.ErrRPCInvalidParameter,
+	}, {
+		name:    "handleGetAddressBalance: ok",
+		handler: handleGetAddressBalance,
+		mockAddrBalIndexer: func() *testAddrBalanceIndexer {
+			idx := defaultMockAddrBalanceIndexer()
+			idx.balance = &indexers.AddrBalance{
+				Received: 500000000,
+				Sent:     200000000,
+				NumUtxos: 2,
+			}
+			idx.unconfirmedDeltas = []indexers.AddrDelta{
+				{IsInput: true, Amount: -100000000},
+				{Index: 1, Amount: 50000000},
+			}
+			return idx
+		}(),
+		cmd: &types.GetAddressBalanceCmd{
+			Address: addr,
+		},
+		result: &types.GetAddressBalanceResult{
+			Address:     addr,
+			Balance:     3,
+			Received:    5,
+			Sent:        2,
+			Unconfirmed: -0.5,
+			NumUtxos:    2,
+		},
+	}})
+}
+
+func TestHandleGetAddressDeltas(t *testing.T) {
+	t.Parallel()
+
+	addr := "22tsq4PQ8GNptB5pmk7Ej8JDhPphuq3rFyLB"
+	bestHeight := int64(block616802.Header.Height)
+	txHash := block616802.Transactions[1].TxHash()
+	deltas := []indexers.AddrDelta{{
+		TxHash:      txHash,
+		Index:       1,
+		Amount:      500000000,
+		BlockHeight: bestHeight - 1,
+	}, {
+		TxHash:      txHash,
+		IsInput:     true,
+		Amount:      -200000000,
+		BlockHeight: bestHeight,
+	}}
+	mpDeltas := []indexers.AddrDelta{{
+		TxHash: txHash,
+		Index:  0,
+		Amount: 100000000,
+	}, {
+		TxHash: txHash,
+		Index:  2,
+		Amount: 200000000,
+	}}
+	wantConfirmed := []types.AddressDeltaResult{{
+		Txid:          txHash.String(),
+		Index:         1,
+		Amount:        5,
+		Height:        bestHeight - 1,
+		Confirmations: 2,
+	}, {
+		Txid:          txHash.String(),
+		IsInput:       true,
+		Amount:        -2,
+		Height:        bestHeight,
+		Confirmations: 1,
+	}}
+	testRPCServerHandler(t, []rpcTest{{
+		name:    "handleGetAddressDeltas: address balance index disabled",
+		handler: handleGetAddressDeltas,
+		cmd: &types.GetAddressDeltasCmd{
+			Address: addr,
+		},
+		wantErr: true,
+		errCode: dcrjson.ErrRPCInternal.Code,
+	}, {
+		name:    "handleGetAddressDeltas: unable to fetch deltas",
+		handler: handleGetAddressDeltas,
+		mockAddrBalIndexer: func() *testAddrBalanceIndexer {
+			idx := defaultMockAddrBalanceIndexer()
+			idx.deltasErr = errors.New("unable to fetch deltas")
+			return idx
+		}(),
+		cmd: &types.GetAddressDeltasCmd{
+			Address: addr,
+		},
+		wantErr: true,
+		errCode: dcrjson.ErrRPCInternal.Code,
+	}, {
+		name:    "handleGetAddressDeltas: ok without mempool",
+		handler: handleGetAddressDeltas,
+		mockAddrBalIndexer: func() *testAddrBalanceIndexer {
+			idx := defaultMockAddrBalanceIndexer()
+			idx.deltas = deltas
+			idx.unconfirmedDeltas = mpDeltas
+			return idx
+		}(),
+		cmd: &types.GetAddressDeltasCmd{
+			Address: addr,
+		},
+		result: wantConfirmed,
+	}, {
+		name:    "handleGetAddressDeltas: ok with mempool",
+		handler: handleGetAddressDeltas,
+		mockAddrBalIndexer: func() *testAddrBalanceIndexer {
+			idx := defaultMockAddrBalanceIndexer()
+			idx.deltas = deltas
+			idx.unconfirmedDeltas = mpDeltas
+			return idx
+		}(),
+		cmd: &types.GetAddressDeltasCmd{
+			Address:        addr,
+			Count:          dcrjson.Int(3),
+			IncludeMempool: dcrjson.Bool(true),
+		},
+		result: append(wantConfirmed[:2:2], types.AddressDeltaResult{
+			Txid:   txHash.String(),
+			Amount: 1,
+		}),
+	}, {
+		name:    "handleGetAddressDeltas: skip into mempool",
+		handler: handleGetAddressDeltas,
+		mockAddrBalIndexer: func() *testAddrBalanceIndexer {
+			idx := defaultMockAddrBalanceIndexer()
+			idx.deltasSkipped = 2
+			idx.unconfirmedDeltas = mpDeltas
+			return idx
+		}(),
+		cmd: &types.GetAddressDeltasCmd{
+			Address:        addr,
+			Skip:           dcrjson.Int(3),
+			IncludeMempool: dcrjson.Bool(true),
+		},
+		result: []types.AddressDeltaResult{{
+			Txid:   txHash.String(),
+			Index:  2,
+			Amount: 2,
+		}},
+	}})
+}
+
+func TestHandleGetAddressUtxos(t *testing.T) {
+	t.Parallel()
+
+	addr := "22tsq4PQ8GNptB5pmk7Ej8JDhPphuq3rFyLB"
+	bestHeight := int64(block616802.Header.Height)
+	txHash := block616802.Transactions[1].TxHash()
+	testRPCServerHandler(t, []rpcTest{{
+		name:    "handleGetAddressUtxos: address balance index disabled",
+		handler: handleGetAddressUtxos,
+		cmd: &types.GetAddressUtxosCmd{
+			Address: addr,
+		},
+		wantErr: true,
+		errCode: dcrjson.ErrRPCInternal.Code,
+	}, {
+		name:    "handleGetAddressUtxos: unable to fetch index tip",
+		handler: handleGetAddressUtxos,
+		mockAddrBalIndexer: func() *testAddrBalanceIndexer {
+			idx := defaultMockAddrBalanceIndexer()
+			idx.tipErr = errors.New("unable to fetch index tip")
+			return idx
+		}(),
+		cmd: &types.GetAddressUtxosCmd{
+			Address: addr,
+		},
+		wantErr: true,
+		errCode: dcrjson.ErrRPCInternal.Code,
+	}, {
+		name:    "handleGetAddressUtxos: unable to fetch utxos",
+		handler: handleGetAddressUtxos,
+		mockAddrBalIndexer: func() *testAddrBalanceIndexer {
+			idx := defaultMockAddrBalanceIndexer()
+			idx.utxosErr = errors.New("unable to fetch utxos")
+			return idx
+		}(),
+		cmd: &types.GetAddressUtxosCmd{
+			Address: addr,
+		},
+		wantErr: true,
+		errCode: dcrjson.ErrRPCInternal.Code,
+	}, {
+		name:               "handleGetAddressUtxos: no utxos",
+		handler:            handleGetAddressUtxos,
+		mockAddrBalIndexer: defaultMockAddrBalanceIndexer(),
+		cmd: &types.GetAddressUtxosCmd{
+			Address: addr,
+		},
+		result: []types.AddressUtxoResult{},
+	}, {
+		name:    "handleGetAddressUtxos: ok",
+		handler: handleGetAddressUtxos,
+		mockAddrBalIndexer: func() *testAddrBalanceIndexer {
+			idx := defaultMockAddrBalanceIndexer()
+			idx.utxos = []indexers.AddrUtxo{{
+				OutPoint: wire.OutPoint{
+					Hash:  txHash,
+					Index: 1,
+					Tree:  wire.TxTreeStake,
+				},
+				Amount:      150000000,
+				BlockHeight: bestHeight - 9,
+			}}
+			return idx
+		}(),
+		cmd: &types.GetAddressUtxosCmd{
+			Address: addr,
+		},
+		result: []types.AddressUtxoResult{{
+			Txid:          txHash.String(),
+			Vout:          1,
+			Tree:          wire.TxTreeStake,
+			Amount:        1.5,
+			Height:        bestHeight - 9,
+			Confirmations: 10,
+		}},
+	}})
+}
+
+func TestHandleGetAddressTickets(t *testing.T) {
+	t.Parallel()
+
+	addr := "22tsq4PQ8GNptB5pmk7Ej8JDhPphuq3rFyLB"
+	params := defaultChainParams
+	bestHeight := int64(block616802.Header.Height)
+	blkHash := block616802.BlockHash()
+	ticketHash := block616802.STransactions[1].TxHash()
+	purchaseHeight := bestHeight - 5
+	maturityHeight := purchaseHeight + int64(params.TicketMaturity)
+	testRPCServerHandler(t, []rpcTest{{
+		name:    "handleGetAddressTickets: invalid address",
+		handler: handleGetAddressTickets,
+		cmd: &types.GetAddressTicketsCmd{
+			Address: "invalid",
+		},
+		mockTicketIndexer: defaultMockTicketIndexer(),
+		wantErr:           true,
+		errCode:           dcrjson.ErrRPCInvalidAddressOrKey,
+	}, {
+		name:    "handleGetAddressTickets: ticket index disabled",
+		handler: handleGetAddressTickets,
+		cmd: &types.GetAddressTicketsCmd{
+			Address: addr,
+		},
+		wantErr: true,
+		errCode: dcrjson.ErrRPCInternal.Code,
+	}, {
+		name:    "handleGetAddressTickets: unsupported address type",
+		handler: handleGetAddressTickets,
+		mockTicketIndexer: func() *testTicketIndexer {
+			idx := defaultMockTicketIndexer()
+			idx.ticketsErr = indexers.ErrUnsupportedAddressType
+			return idx
+		}(),
+		cmd: &types.GetAddressTicketsCmd{
+			Address: addr,
+		},
+		wantErr: true,
+		errCode: dcrjson.ErrRPCInvalidParameter,
+	}, {
+		name:    "handleGetAddressTickets: unable to fetch tickets",
+		handler: handleGetAddressTickets,
+		mockTicketIndexer: func() *testTicketIndexer {
+			idx := defaultMockTicketIndexer()
+			idx.ticketsErr = errors.New("unable to fetch tickets")
+			return idx
+		}(),
+		cmd: &types.GetAddressTicketsCmd{
+			Address: addr,
+		},
+		wantErr: true,
+		errCode: dcrjson.ErrRPCInternal.Code,
+	}, {
+		name:              "handleGetAddressTickets: no tickets",
+		handler:           handleGetAddressTickets,
+		mockTicketIndexer: defaultMockTicketIndexer(),
+		cmd: &types.GetAddressTicketsCmd{
+			Address: addr,
+		},
+		result: []types.GetTicketInfoResult{},
+	}, {
+		name:    "handleGetAddressTickets: ok",
+		handler: handleGetAddressTickets,
+		mockTicketIndexer: func() *testTicketIndexer {
+			idx := defaultMockTicketIndexer()
+			idx.tickets = []indexers.TicketEntry{{
+				Hash:           ticketHash,
+				PurchaseHeight: purchaseHeight,
+				Price:          14428162590,
+			}}
+			return idx
+		}(),
+		cmd: &types.GetAddressTicketsCmd{
+			Address: addr,
+		},
+		result: []types.GetTicketInfoResult{{
+			Hash:           ticketHash.String(),
+			Status:         "immature",
+			Price:          144.2816259,
+			PurchaseHeight: purchaseHeight,
+			PurchaseBlock:  blkHash.String(),
+			MaturityHeight: maturityHeight,
+			ExpiryHeight:   maturityHeight + int64(params.TicketExpiry),
+		}},
+	}})
+}
+
 func TestHandleGetBestBlock(t *testing.T) {
 	t.Parallel()
 
@@ -4444,6 +5437,152 @@ func TestHandleGetBlockHeader(t *testing.T) {
 	}})
 }
 
+func TestHandleGetBlockStats(t *testing.T) {
+	t.Parallel()
+
+	blkHash := block616802.Header.BlockHash()
+	blkHeight := int64(block616802.Header.Height)
+	wantResult := types.GetBlockStatsResult{
+		AvgFee:             0.0001,
+		AvgFeeRate:         0.00033333,
+		AvgTxSize:          300,
+		BlockHash:          blkHash.String(),
+		FeeRatePercentiles: []float64{0.0002, 0.0002, 0.0003, 0.0004, 0.0004},
+		Height:             blkHeight,
+		Ins:                4,
+		MaxFee:             0.00015,
+		MaxFeeRate:         0.0004,
+		MaxTxSize:          375,
+		MedianFee:          0.0001,
+		MedianTxSize:       275,
+		MinFee:             0.00005,
+		MinFeeRate:         0.0002,
+		MinTxSize:          250,
+		Outs:               20,
+		Revocations:        0,
+		Size:               2000,
+		STxs:               6,
+		Tickets:            1,
+		Time:               block616802.Header.Timestamp.Unix(),
+		TotalFee:           0.0003,
+		TotalOut:           9,
+		TotalSize:          900,
+		Txs:                3,
+		UtxoIncrease:       9,
+		Votes:              5,
+	}
+	testRPCServerHandler(t, []rpcTest{{
+		name:    "handleGetBlockStats: block statistics index disabled",
+		handler: handleGetBlockStats,
+		cmd: &types.GetBlockStatsCmd{
+			HashOrHeight: types.HashOrHeight(blkHash.String()),
+		},
+		wantErr: true,
+		errCode: dcrjson.ErrRPCInternal.Code,
+	}, {
+		name:                  "handleGetBlockStats: invalid hash",
+		handler:               handleGetBlockStats,
+		mockBlockStatsIndexer: defaultMockBlockStatsIndexer(),
+		cmd: &types.GetBlockStatsCmd{
+			HashOrHeight: "invalid",
+		},
+		wantErr: true,
+		errCode: dcrjson.ErrRPCDecodeHexString,
+	}, {
+		name:                  "handleGetBlockStats: block not in main chain",
+		handler:               handleGetBlockStats,
+		mockBlockStatsIndexer: defaultMockBlockStatsIndexer(),
+		mockChain: func() *testRPCChain {
+			chain := defaultMockRPCChain()
+			chain.mainChainHasBlock = false
+			return chain
+		}(),
+		cmd: &types.GetBlockStatsCmd{
+			HashOrHeight: types.HashOrHeight(blkHash.String()),
+		},
+		wantErr: true,
+		errCode: dcrjson.ErrRPCBlockNotFound,
+	}, {
+		name:                  "handleGetBlockStats: height out of range",
+		handler:               handleGetBlockStats,
+		mockBlockStatsIndexer: defaultMockBlockStatsIndexer(),
+		mockChain: func() *testRPCChain {
+			chain := defaultMockRPCChain()
+			chain.blockHashByHeightErr = errors.New("block number out of range")
+			return chain
+		}(),
+		cmd: &types.GetBlockStatsCmd{
+			HashOrHeight: "999999999",
+		},
+		wantErr: true,
+		errCode: dcrjson.ErrRPCOutOfRange,
+	}, {
+		name:    "handleGetBlockStats: index not synced",
+		handler: handleGetBlockStats,
+		mockBlockStatsIndexer: func() *testBlockStatsIndexer {
+			idx := defaultMockBlockStatsIndexer()
+			idx.stats = nil
+			return idx
+		}(),
+		cmd: &types.GetBlockStatsCmd{
+			HashOrHeight: types.HashOrHeight(blkHash.String()),
+		},
+		wantErr: true,
+		errCode: dcrjson.ErrRPCInternal.Code,
+	}, {
+		name:    "handleGetBlockStats: stats error",
+		handler: handleGetBlockStats,
+		mockBlockStatsIndexer: func() *testBlockStatsIndexer {
+			idx := defaultMockBlockStatsIndexer()
+			idx.statsErr = errors.New("unable to fetch stats")
+			return idx
+		}(),
+		cmd: &types.GetBlockStatsCmd{
+			HashOrHeight: types.HashOrHeight(blkHash.String()),
+		},
+		wantErr: true,
+		errCode: dcrjson.ErrRPCInternal.Code,
+	}, {
+		name:                  "handleGetBlockStats: ok by hash",
+		handler:               handleGetBlockStats,
+		mockBlockStatsIndexer: defaultMockBlockStatsIndexer(),
+		cmd: &types.GetBlockStatsCmd{
+			HashOrHeight: types.HashOrHeight(blkHash.String()),
+		},
+		result: wantResult,
+	}, {
+		name:                  "handleGetBlockStats: ok by height",
+		handler:               handleGetBlockStats,
+		mockBlockStatsIndexer: defaultMockBlockStatsIndexer(),
+		cmd: &types.GetBlockStatsCmd{
+			HashOrHeight: types.HashOrHeight(strconv.FormatInt(blkHeight, 10)),
+		},
+		result: wantResult,
+	}, {
+		name:                  "handleGetBlockStats: selected fields",
+		handler:               handleGetBlockStats,
+		mockBlockStatsIndexer: defaultMockBlockStatsIndexer(),
+		cmd: &types.GetBlockStatsCmd{
+			HashOrHeight: types.HashOrHeight(blkHash.String()),
+			Fields:       &[]string{"totalfee", "votes"},
+		},
+		result: map[string]json.RawMessage{
+			"totalfee": json.RawMessage("0.0003"),
+			"votes":    json.RawMessage("5"),
+		},
+	}, {
+		name:                  "handleGetBlockStats: invalid selected field",
+		handler:               handleGetBlockStats,
+		mockBlockStatsIndexer: defaultMockBlockStatsIndexer(),
+		cmd: &types.GetBlockStatsCmd{
+			HashOrHeight: types.HashOrHeight(blkHash.String()),
+			Fields:       &[]string{"totalfee", "bogus"},
+		},
+		wantErr: true,
+		errCode: dcrjson.ErrRPCInvalidParameter,
+	}})
+}
+
 func TestHandleGetBlockSubsidy(t *testing.T) {
 	t.Parallel()
 
@@ -4493,6 +5632,155 @@ func TestHandleGetBlockSubsidy(t *testing.T) {
 	}})
 }
 
+func TestHandleGetCFilterHeaders(t *testing.T) {
+	t.Parallel()
+
+	blkHash := block616802.BlockHash()
+	blkHeight := int64(block616802.Header.Height)
+	entry := defaultMockCFHeaderIndexer().entry
+	heightRange := func(startHeight, endHeight int64) ([]chainhash.Hash, error) {
+		return []chainhash.Hash{blkHash}, nil
+	}
+	testRPCServerHandler(t, []rpcTest{{
+		name:    "handleGetCFilterHeaders: filter header index disabled",
+		handler: handleGetCFilterHeaders,
+		cmd: &types.GetCFilterHeadersCmd{
+			StartHeight: blkHeight,
+			Count:       1,
+		},
+		wantErr: true,
+		errCode: dcrjson.ErrRPCInternal.Code,
+	}, {
+		name:                "handleGetCFilterHeaders: zero count",
+		handler:             handleGetCFilterHeaders,
+		mockCFHeaderIndexer: defaultMockCFHeaderIndexer(),
+		cmd: &types.GetCFilterHeadersCmd{
+			StartHeight: blkHeight,
+			Count:       0,
+		},
+		wantErr: true,
+		errCode: dcrjson.ErrRPCInvalidParameter,
+	}, {
+		name:                "handleGetCFilterHeaders: count too high",
+		handler:             handleGetCFilterHeaders,
+		mockCFHeaderIndexer: defaultMockCFHeaderIndexer(),
+		cmd: &types.GetCFilterHeadersCmd{
+			StartHeight: blkHeight,
+			Count:       maxCFHeadersPerRange + 1,
+		},
+		wantErr: true,
+		errCode: dcrjson.ErrRPCInvalidParameter,
+	}, {
+		name:                "handleGetCFilterHeaders: start height out of range",
+		handler:             handleGetCFilterHeaders,
+		mockCFHeaderIndexer: defaultMockCFHeaderIndexer(),
+		cmd: &types.GetCFilterHeadersCmd{
+			StartHeight: blkHeight + 1,
+			Count:       1,
+		},
+		wantErr: true,
+		errCode: dcrjson.ErrRPCOutOfRange,
+	}, {
+		name:    "handleGetCFilterHeaders: index not synced",
+		handler: handleGetCFilterHeaders,
+		mockCFHeaderIndexer: func() *testCFHeaderIndexer {
+			idx := defaultMockCFHeaderIndexer()
+			idx.tipHeight -= 6
+			return idx
+		}(),
+		cmd: &types.GetCFilterHeadersCmd{
+			StartHeight: blkHeight,
+			Count:       1,
+		},
+		wantErr: true,
+		errCode: dcrjson.ErrRPCInternal.Code,
+	}, {
+		name:    "handleGetCFilterHeaders: missing entry",
+		handler: handleGetCFilterHeaders,
+		mockChain: func() *testRPCChain {
+			chain := defaultMockRPCChain()
+			chain.heightRangeFn = heightRange
+			return chain
+		}(),
+		mockCFHeaderIndexer: func() *testCFHeaderIndexer {
+			idx := defaultMockCFHeaderIndexer()
+			idx.entry = nil
+			return idx
+		}(),
+		cmd: &types.GetCFilterHeadersCmd{
+			StartHeight: blkHeight,
+			Count:       1,
+		},
+		wantErr: true,
+		errCode: dcrjson.ErrRPCInternal.Code,
+	}, {
+		name:    "handleGetCFilterHeaders: unable to load entry",
+		handler: handleGetCFilterHeaders,
+		mockChain: func() *testRPCChain {
+			chain := defaultMockRPCChain()
+			chain.heightRangeFn = heightRange
+			return chain
+		}(),
+		mockCFHeaderIndexer: func() *testCFHeaderIndexer {
+			idx := defaultMockCFHeaderIndexer()
+			idx.entryErr = errors.New("unable to load entry")
+			return idx
+		}(),
+		cmd: &types.GetCFilterHeadersCmd{
+			StartHeight: blkHeight,
+			Count:       1,
+		},
+		wantErr: true,
+		errCode: dcrjson.ErrRPCInternal.Code,
+	}, {
+		name:    "handleGetCFilterHeaders: ok",
+		handler: handleGetCFilterHeaders,
+		mockChain: func() *testRPCChain {
+			chain := defaultMockRPCChain()
+			chain.heightRangeFn = heightRange
+			return chain
+		}(),
+		mockCFHeaderIndexer: defaultMockCFHeaderIndexer(),
+		cmd: &types.GetCFilterHeadersCmd{
+			StartHeight: blkHeight,
+			Count:       10,
+		},
+		result: &types.GetCFilterHeadersResult{
+			PrevHeader: entry.Header.String(),
+			Headers: []types.CFilterHeaderResult{{
+				BlockHash:   blkHash.String(),
+				FilterHash:  entry.FilterHash.String(),
+				Header:      entry.Header.String(),
+				ProofIndex:  blockchain.HeaderCmtFilterIndex,
+				ProofHashes: nil,
+			}},
+		},
+	}, {
+		name:    "handleGetCFilterHeaders: ok from genesis",
+		handler: handleGetCFilterHeaders,
+		mockChain: func() *testRPCChain {
+			chain := defaultMockRPCChain()
+			chain.heightRangeFn = heightRange
+			return chain
+		}(),
+		mockCFHeaderIndexer: defaultMockCFHeaderIndexer(),
+		cmd: &types.GetCFilterHeadersCmd{
+			StartHeight: 0,
+			Count:       1,
+		},
+		result: &types.GetCFilterHeadersResult{
+			PrevHeader: zeroHash.String(),
+			Headers: []types.CFilterHeaderResult{{
+				BlockHash:   blkHash.String(),
+				FilterHash:  entry.FilterHash.String(),
+				Header:      entry.Header.String(),
+				ProofIndex:  blockchain.HeaderCmtFilterIndex,
+				ProofHashes: nil,
+			}},
+		},
+	}})
+}
+
 func TestHandleGetCFilterV2(t *testing.T) {
 	t.Parallel()
 
@@ -4547,6 +5835,89 @@ func TestHandleGetCFilterV2(t *testing.T) {
 	}})
 }
 
+func TestHandleGetCFilterV2Range(t *testing.T) {
+	t.Parallel()
+
+	blkHash := block616802.BlockHash()
+	blkHeight := int64(block616802.Header.Height)
+	filter := hex.EncodeToString(defaultMockFiltererV2().filterByBlockHash.Bytes())
+	heightRange := func(startHeight, endHeight int64) ([]chainhash.Hash, error) {
+		return []chainhash.Hash{blkHash}, nil
+	}
+	testRPCServerHandler(t, []rpcTest{{
+		name:    "handleGetCFilterV2Range: count too high",
+		handler: handleGetCFilterV2Range,
+		cmd: &types.GetCFilterV2RangeCmd{
+			StartHeight: blkHeight,
+			Count:       maxCFiltersPerRange + 1,
+		},
+		wantErr: true,
+		errCode: dcrjson.ErrRPCInvalidParameter,
+	}, {
+		name:    "handleGetCFilterV2Range: negative start height",
+		handler: handleGetCFilterV2Range,
+		cmd: &types.GetCFilterV2RangeCmd{
+			StartHeight: -1,
+			Count:       1,
+		},
+		wantErr: true,
+		errCode: dcrjson.ErrRPCOutOfRange,
+	}, {
+		name:    "handleGetCFilterV2Range: unable to fetch block hashes",
+		handler: handleGetCFilterV2Range,
+		mockChain: func() *testRPCChain {
+			chain := defaultMockRPCChain()
+			chain.heightRangeFn = func(startHeight, endHeight int64) ([]chainhash.Hash, error) {
+				return nil, errors.New("unable to fetch block hashes")
+			}
+			return chain
+		}(),
+		cmd: &types.GetCFilterV2RangeCmd{
+			StartHeight: blkHeight,
+			Count:       1,
+		},
+		wantErr: true,
+		errCode: dcrjson.ErrRPCInternal.Code,
+	}, {
+		name:    "handleGetCFilterV2Range: block not found",
+		handler: handleGetCFilterV2Range,
+		mockChain: func() *testRPCChain {
+			chain := defaultMockRPCChain()
+			chain.heightRangeFn = heightRange
+			return chain
+		}(),
+		mockFiltererV2: func() *testFiltererV2 {
+			testFiltererV2 := defaultMockFiltererV2()
+			testFiltererV2.filterByBlockHashErr = blockchain.ErrNoFilter
+			return testFiltererV2
+		}(),
+		cmd: &types.GetCFilterV2RangeCmd{
+			StartHeight: blkHeight,
+			Count:       1,
+		},
+		wantErr: true,
+		errCode: dcrjson.ErrRPCBlockNotFound,
+	}, {
+		name:    "handleGetCFilterV2Range: ok",
+		handler: handleGetCFilterV2Range,
+		mockChain: func() *testRPCChain {
+			chain := defaultMockRPCChain()
+			chain.heightRangeFn = heightRange
+			return chain
+		}(),
+		cmd: &types.GetCFilterV2RangeCmd{
+			StartHeight: blkHeight,
+			Count:       10,
+		},
+		result: []types.GetCFilterV2Result{{
+			BlockHash:   blkHash.String(),
+			Data:        filter,
+			ProofIndex:  blockchain.HeaderCmtFilterIndex,
+			ProofHashes: nil,
+		}},
+	}})
+}
+
 func TestHandleGetChainTips(t *testing.T) {
 	t.Parallel()
 
@@ -4687,6 +6058,72 @@ func TestHandleGetHeaders(t *testing.T) {
 	}})
 }
 
+func TestHandleGetIndexInfo(t *testing.T) {
+	t.Parallel()
+
+	indexMgr := defaultMockIndexManager()
+	addrIndexInfo := indexMgr.indexInfo[0]
+	txIndexInfo := indexMgr.indexInfo[1]
+	wantAddrIndex := types.IndexInfoResult{
+		Synced:          true,
+		BestBlockHeight: addrIndexInfo.Height,
+		BestBlockHash:   addrIndexInfo.Hash.String(),
+		DiskUsage:       addrIndexInfo.DiskUsage,
+	}
+	wantTxIndex := types.IndexInfoResult{
+		BestBlockHeight: txIndexInfo.Height,
+		BestBlockHash:   txIndexInfo.Hash.String(),
+		DiskUsage:       txIndexInfo.DiskUsage,
+	}
+	wantExistsAddrIndex := types.IndexInfoResult{
+		Dropping: true,
+	}
+	addrIndexName := "addrindex"
+	unknownName := "unknownindex"
+	testRPCServerHandler(t, []rpcTest{{
+		name:             "handleGetIndexInfo: all indexes",
+		handler:          handleGetIndexInfo,
+		cmd:              &types.GetIndexInfoCmd{},
+		mockIndexManager: indexMgr,
+		result: map[string]types.IndexInfoResult{
+			"addrindex":       wantAddrIndex,
+			"txindex":         wantTxIndex,
+			"existsaddrindex": wantExistsAddrIndex,
+		},
+	}, {
+		name:             "handleGetIndexInfo: filtered by name",
+		handler:          handleGetIndexInfo,
+		cmd:              &types.GetIndexInfoCmd{Name: &addrIndexName},
+		mockIndexManager: defaultMockIndexManager(),
+		result: map[string]types.IndexInfoResult{
+			"addrindex": wantAddrIndex,
+		},
+	}, {
+		name:             "handleGetIndexInfo: unknown name",
+		handler:          handleGetIndexInfo,
+		cmd:              &types.GetIndexInfoCmd{Name: &unknownName},
+		mockIndexManager: defaultMockIndexManager(),
+		result:           map[string]types.IndexInfoResult{},
+	}, {
+		name:    "handleGetIndexInfo: index info error",
+		handler: handleGetIndexInfo,
+		cmd:     &types.GetIndexInfoCmd{},
+		mockIndexManager: func() *testIndexManager {
+			indexMgr := defaultMockIndexManager()
+			indexMgr.indexInfoErr = errors.New("database error")
+			return indexMgr
+		}(),
+		wantErr: true,
+		errCode: dcrjson.ErrRPCInternal.Code,
+	}, {
+		name:    "handleGetIndexInfo: index management not available",
+		handler: handleGetIndexInfo,
+		cmd:     &types.GetIndexInfoCmd{},
+		wantErr: true,
+		errCode: dcrjson.ErrRPCInternal.Code,
+	}})
+}
+
 func TestHandleGetInfo(t *testing.T) {
 	t.Parallel()
 
@@ -4787,6 +6224,72 @@ func TestHandleGetNetTotals(t *testing.T) {
 			TotalBytesRecv: uint64(9598159),
 			TotalBytesSent: uint64(4783802),
 			TimeMillis:     int64(1592931302000),
+			UploadTarget: types.UploadTargetResult{
+				TimeFrame:             86400,
+				ServeHistoricalBlocks: true,
+			},
+		},
+	}, {
+		name:    "handleGetNetTotals: upload target reached",
+		handler: handleGetNetTotals,
+		cmd:     &types.GetNetTotalsCmd{},
+		mockClock: &testClock{
+			now: time.Unix(1592931302, 0),
+		},
+		mockConnManager: func() *testConnManager {
+			connManager := defaultMockConnManager()
+			connManager.uploadTarget = &UploadTarget{
+				Timeframe:        24 * time.Hour,
+				Target:           5000 * 1024 * 1024,
+				TargetReached:    true,
+				BytesLeftInCycle: 0,
+				TimeLeftInCycle:  2*time.Hour + 500*time.Millisecond,
+			}
+			return connManager
+		}(),
+		result: &types.GetNetTotalsResult{
+			TotalBytesRecv: uint64(9598159),
+			TotalBytesSent: uint64(4783802),
+			TimeMillis:     int64(1592931302000),
+			UploadTarget: types.UploadTargetResult{
+				TimeFrame:       86400,
+				Target:          5000 * 1024 * 1024,
+				TargetReached:   true,
+				TimeLeftInCycle: 7200,
+			},
+		},
+	}})
+}
+
+func TestHandleGetNetMsgStats(t *testing.T) {
+	t.Parallel()
+
+	testRPCServerHandler(t, []rpcTest{{
+		name:    "handleGetNetMsgStats: ok",
+		handler: handleGetNetMsgStats,
+		cmd:     &types.GetNetMsgStatsCmd{},
+		result: &types.GetNetMsgStatsResult{
+			Sent: map[string]types.NetMsgStat{
+				"block": {Msgs: 12, Bytes: 4712301},
+				"inv":   {Msgs: 73, Bytes: 71501},
+			},
+			Recv: map[string]types.NetMsgStat{
+				"getdata": {Msgs: 12, Bytes: 3560},
+				"inv":     {Msgs: 85, Bytes: 9594599},
+			},
+		},
+	}, {
+		name:    "handleGetNetMsgStats: no messages",
+		handler: handleGetNetMsgStats,
+		cmd:     &types.GetNetMsgStatsCmd{},
+		mockConnManager: func() *testConnManager {
+			connManager := defaultMockConnManager()
+			connManager.msgStats = &MsgStats{}
+			return connManager
+		}(),
+		result: &types.GetNetMsgStatsResult{
+			Sent: map[string]types.NetMsgStat{},
+			Recv: map[string]types.NetMsgStat{},
 		},
 	}})
 }
@@ -4937,6 +6440,8 @@ func TestHandleGetPeerInfo(t *testing.T) {
 					},
 					isTxRelayDisabled: false,
 					banScore:          uint32(0),
+					connType:          "outbound-full-relay",
+					mappedAS:          uint32(37963),
 					id:                int32(5),
 					addr:              "106.14.238.184:19108",
 					lastPingNonce:     uint64(10),
@@ -4959,6 +6464,15 @@ func TestHandleGetPeerInfo(t *testing.T) {
 						LastPingTime:   time.Unix(1592918788, 0),
 						LastPingMicros: int64(0),
 					},
+					msgStats: &MsgStats{
+						Sent: map[string]MsgStat{
+							"ping": {Msgs: 2, Bytes: 64},
+						},
+						Recv: map[string]MsgStat{
+							"pong":    {Msgs: 2, Bytes: 64},
+							"*other*": {Msgs: 1, Bytes: 24},
+						},
+					},
 				},
 			}
 			return connManager
@@ -4983,10 +6497,19 @@ func TestHandleGetPeerInfo(t *testing.T) {
 			Version:        uint32(6),
 			SubVer:         "/dcrwire:0.3.0/exccd:1.5.0(pre)/",
 			Inbound:        false,
+			ConnType:       "outbound-full-relay",
+			MappedAS:       uint32(37963),
 			StartingHeight: int64(323327),
 			CurrentHeight:  int64(323327),
 			BanScore:       int32(0),
 			SyncNode:       false,
+			SentPerMsg: map[string]types.NetMsgStat{
+				"ping": {Msgs: 2, Bytes: 64},
+			},
+			RecvPerMsg: map[string]types.NetMsgStat{
+				"pong":    {Msgs: 2, Bytes: 64},
+				"*other*": {Msgs: 1, Bytes: 24},
+			},
 		}},
 	}})
 }
@@ -5178,6 +6701,170 @@ func TestHandleGetStakeVersions(t *testing.T) {
 	}})
 }
 
+func TestHandleGetTicketInfo(t *testing.T) {
+	t.Parallel()
+
+	params := defaultChainParams
+	bestHeight := int64(block616802.Header.Height)
+	blkHash := block616802.BlockHash()
+	ticketHash := block616802.STransactions[1].TxHash()
+	voteHash := block616802.STransactions[0].TxHash()
+	purchaseHeight := bestHeight - int64(params.TicketMaturity) - 100
+	maturityHeight := purchaseHeight + int64(params.TicketMaturity)
+	expiryHeight := maturityHeight + int64(params.TicketExpiry)
+	testRPCServerHandler(t, []rpcTest{{
+		name:    "handleGetTicketInfo: invalid hash",
+		handler: handleGetTicketInfo,
+		cmd: &types.GetTicketInfoCmd{
+			Hash: "invalid",
+		},
+		mockTicketIndexer: defaultMockTicketIndexer(),
+		wantErr:           true,
+		errCode:           dcrjson.ErrRPCDecodeHexString,
+	}, {
+		name:    "handleGetTicketInfo: ticket index disabled",
+		handler: handleGetTicketInfo,
+		cmd: &types.GetTicketInfoCmd{
+			Hash: ticketHash.String(),
+		},
+		wantErr: true,
+		errCode: dcrjson.ErrRPCInternal.Code,
+	}, {
+		name:    "handleGetTicketInfo: unable to fetch index tip",
+		handler: handleGetTicketInfo,
+		mockTicketIndexer: func() *testTicketIndexer {
+			idx := defaultMockTicketIndexer()
+			idx.tipErr = errors.New("unable to fetch index tip")
+			return idx
+		}(),
+		cmd: &types.GetTicketInfoCmd{
+			Hash: ticketHash.String(),
+		},
+		wantErr: true,
+		errCode: dcrjson.ErrRPCInternal.Code,
+	}, {
+		name:    "handleGetTicketInfo: index not synced",
+		handler: handleGetTicketInfo,
+		mockTicketIndexer: func() *testTicketIndexer {
+			idx := defaultMockTicketIndexer()
+			idx.tipHeight = bestHeight - 6
+			return idx
+		}(),
+		cmd: &types.GetTicketInfoCmd{
+			Hash: ticketHash.String(),
+		},
+		wantErr: true,
+		errCode: dcrjson.ErrRPCInternal.Code,
+	}, {
+		name:    "handleGetTicketInfo: unable to fetch ticket",
+		handler: handleGetTicketInfo,
+		mockTicketIndexer: func() *testTicketIndexer {
+			idx := defaultMockTicketIndexer()
+			idx.entryErr = errors.New("unable to fetch ticket")
+			return idx
+		}(),
+		cmd: &types.GetTicketInfoCmd{
+			Hash: ticketHash.String(),
+		},
+		wantErr: true,
+		errCode: dcrjson.ErrRPCInternal.Code,
+	}, {
+		name:              "handleGetTicketInfo: ticket not found",
+		handler:           handleGetTicketInfo,
+		mockTicketIndexer: defaultMockTicketIndexer(),
+		cmd: &types.GetTicketInfoCmd{
+			Hash: ticketHash.String(),
+		},
+		wantErr: true,
+		errCode: dcrjson.ErrRPCNoTxInfo,
+	}, {
+		name:    "handleGetTicketInfo: live ticket",
+		handler: handleGetTicketInfo,
+		mockTicketIndexer: func() *testTicketIndexer {
+			idx := defaultMockTicketIndexer()
+			idx.entry = &indexers.TicketEntry{
+				Hash:           ticketHash,
+				PurchaseHeight: purchaseHeight,
+				Price:          14428162590,
+			}
+			return idx
+		}(),
+		cmd: &types.GetTicketInfoCmd{
+			Hash: ticketHash.String(),
+		},
+		result: &types.GetTicketInfoResult{
+			Hash:           ticketHash.String(),
+			Status:         "live",
+			Price:          144.2816259,
+			PurchaseHeight: purchaseHeight,
+			PurchaseBlock:  blkHash.String(),
+			MaturityHeight: maturityHeight,
+			ExpiryHeight:   expiryHeight,
+		},
+	}, {
+		name:    "handleGetTicketInfo: missed ticket",
+		handler: handleGetTicketInfo,
+		mockTicketIndexer: func() *testTicketIndexer {
+			idx := defaultMockTicketIndexer()
+			idx.entry = &indexers.TicketEntry{
+				Hash:           ticketHash,
+				PurchaseHeight: purchaseHeight,
+				Price:          14428162590,
+				MissedHeight:   bestHeight - 10,
+			}
+			return idx
+		}(),
+		cmd: &types.GetTicketInfoCmd{
+			Hash: ticketHash.String(),
+		},
+		result: &types.GetTicketInfoResult{
+			Hash:           ticketHash.String(),
+			Status:         "missed",
+			Price:          144.2816259,
+			PurchaseHeight: purchaseHeight,
+			PurchaseBlock:  blkHash.String(),
+			MaturityHeight: maturityHeight,
+			ExpiryHeight:   expiryHeight,
+			MissedHeight:   bestHeight - 10,
+		},
+	}, {
+		name:    "handleGetTicketInfo: voted ticket",
+		handler: handleGetTicketInfo,
+		mockTicketIndexer: func() *testTicketIndexer {
+			idx := defaultMockTicketIndexer()
+			idx.entry = &indexers.TicketEntry{
+				Hash:           ticketHash,
+				PurchaseHeight: purchaseHeight,
+				Price:          14428162590,
+				VoteHeight:     bestHeight,
+				VoteHash:       voteHash,
+				VoteVersion:    8,
+				VoteBits:       0x0001,
+			}
+			return idx
+		}(),
+		cmd: &types.GetTicketInfoCmd{
+			Hash: ticketHash.String(),
+		},
+		result: &types.GetTicketInfoResult{
+			Hash:           ticketHash.String(),
+			Status:         "voted",
+			Price:          144.2816259,
+			PurchaseHeight: purchaseHeight,
+			PurchaseBlock:  blkHash.String(),
+			MaturityHeight: maturityHeight,
+			ExpiryHeight:   expiryHeight,
+			Vote: &types.TicketVoteResult{
+				Txid:        voteHash.String(),
+				BlockHash:   blkHash.String(),
+				BlockHeight: bestHeight,
+				Version:     8,
+				VoteBits:    0x0001,
+			},
+		},
+	}})
+}
+
 func TestHandleGetTicketPoolValue(t *testing.T) {
 	t.Parallel()
 
@@ -5365,35 +7052,232 @@ func TestHandleGetTxOut(t *testing.T) {
 		wantErr: true,
 		errCode: dcrjson.ErrRPCInternal.Code,
 	}, {
-		name:    "handleGetTxOut: failed to retrieve utxo entry",
-		handler: handleGetTxOut,
-		cmd:     &cmd,
+		name:    "handleGetTxOut: failed to retrieve utxo entry",
+		handler: handleGetTxOut,
+		cmd:     &cmd,
+		mockChain: func() *testRPCChain {
+			chain := defaultMockRPCChain()
+			chain.fetchUtxoEntry = nil
+			chain.fetchUtxoEntryErr = errors.New("failed to retrieve utxo entry")
+			return chain
+		}(),
+		wantErr: true,
+		errCode: dcrjson.ErrRPCInternal.Code,
+	}})
+}
+
+func TestHandleGetTxOutSetInfo(t *testing.T) {
+	t.Parallel()
+
+	blkHash := block616802.Header.BlockHash()
+	blkHashStr := types.HashOrHeight(blkHash.String())
+	blkHeight := types.HashOrHeight(strconv.Itoa(int(block616802.Header.Height)))
+	wantIndexResult := types.GetTxOutSetInfoResult{
+		Height:      int64(block616802.Header.Height),
+		BestBlock:   blkHash.String(),
+		TxOuts:      1593879,
+		SetHash:     "3ac6c9e0d5d0f9d8c3e8a9d2b2c4f2a3a1b9c5d7e1f3a5b7c9d1e3f5a7b9c1d3",
+		BogoSize:    149425208,
+		TotalAmount: 1154067750680149,
+	}
+	testRPCServerHandler(t, []rpcTest{{
+		name:    "handleGetTxOutSetInfo: ok",
+		handler: handleGetTxOutSetInfo,
+		cmd:     &types.GetTxOutSetInfoCmd{},
+		result: types.GetTxOutSetInfoResult{
+			Height:         int64(block616802.Header.Height),
+			BestBlock:      block616802.BlockHash().String(),
+			Transactions:   689819,
+			TxOuts:         1593879,
+			SerializedHash: "fe7b32aa188800f07268b17f3bead5f3d8a1b6d18654182066436efce6effa86",
+			DiskSize:       36441617,
+			TotalAmount:    1154067750680149,
+		},
+	}, {
+		name:    "handleGetTxOutSetInfo: index not used",
+		handler: handleGetTxOutSetInfo,
+		cmd: &types.GetTxOutSetInfoCmd{
+			UseIndex: dcrjson.Bool(false),
+		},
+		mockCoinStatsIndexer: defaultMockCoinStatsIndexer(),
+		result: types.GetTxOutSetInfoResult{
+			Height:         int64(block616802.Header.Height),
+			BestBlock:      block616802.BlockHash().String(),
+			Transactions:   689819,
+			TxOuts:         1593879,
+			SerializedHash: "fe7b32aa188800f07268b17f3bead5f3d8a1b6d18654182066436efce6effa86",
+			DiskSize:       36441617,
+			TotalAmount:    1154067750680149,
+		},
+	}, {
+		name:    "handleGetTxOutSetInfo: block requested without index",
+		handler: handleGetTxOutSetInfo,
+		cmd: &types.GetTxOutSetInfoCmd{
+			HashOrHeight: &blkHeight,
+		},
+		wantErr: true,
+		errCode: dcrjson.ErrRPCInternal.Code,
+	}, {
+		name:    "handleGetTxOutSetInfo: block requested with index not used",
+		handler: handleGetTxOutSetInfo,
+		cmd: &types.GetTxOutSetInfoCmd{
+			HashOrHeight: &blkHeight,
+			UseIndex:     dcrjson.Bool(false),
+		},
+		mockCoinStatsIndexer: defaultMockCoinStatsIndexer(),
+		wantErr:              true,
+		errCode:              dcrjson.ErrRPCInvalidParameter,
+	}, {
+		name:    "handleGetTxOutSetInfo: invalid hash",
+		handler: handleGetTxOutSetInfo,
+		cmd: &types.GetTxOutSetInfoCmd{
+			HashOrHeight: func() *types.HashOrHeight {
+				v := types.HashOrHeight("invalid")
+				return &v
+			}(),
+		},
+		mockCoinStatsIndexer: defaultMockCoinStatsIndexer(),
+		wantErr:              true,
+		errCode:              dcrjson.ErrRPCDecodeHexString,
+	}, {
+		name:    "handleGetTxOutSetInfo: height out of range",
+		handler: handleGetTxOutSetInfo,
+		cmd: &types.GetTxOutSetInfoCmd{
+			HashOrHeight: &blkHeight,
+		},
 		mockChain: func() *testRPCChain {
 			chain := defaultMockRPCChain()
-			chain.fetchUtxoEntry = nil
-			chain.fetchUtxoEntryErr = errors.New("failed to retrieve utxo entry")
+			chain.blockHashByHeightErr = errors.New("block number out of range")
 			return chain
 		}(),
+		mockCoinStatsIndexer: defaultMockCoinStatsIndexer(),
+		wantErr:              true,
+		errCode:              dcrjson.ErrRPCOutOfRange,
+	}, {
+		name:    "handleGetTxOutSetInfo: index not synced",
+		handler: handleGetTxOutSetInfo,
+		cmd:     &types.GetTxOutSetInfoCmd{},
+		mockCoinStatsIndexer: func() *testCoinStatsIndexer {
+			idx := defaultMockCoinStatsIndexer()
+			idx.tipHeight -= 6
+			return idx
+		}(),
+		wantErr: true,
+		errCode: dcrjson.ErrRPCInternal.Code,
+	}, {
+		name:    "handleGetTxOutSetInfo: unable to fetch stats",
+		handler: handleGetTxOutSetInfo,
+		cmd:     &types.GetTxOutSetInfoCmd{},
+		mockCoinStatsIndexer: func() *testCoinStatsIndexer {
+			idx := defaultMockCoinStatsIndexer()
+			idx.statsErr = errors.New("unable to fetch stats")
+			return idx
+		}(),
+		wantErr: true,
+		errCode: dcrjson.ErrRPCInternal.Code,
+	}, {
+		name:    "handleGetTxOutSetInfo: no stats for block",
+		handler: handleGetTxOutSetInfo,
+		cmd:     &types.GetTxOutSetInfoCmd{},
+		mockCoinStatsIndexer: func() *testCoinStatsIndexer {
+			idx := defaultMockCoinStatsIndexer()
+			idx.stats = nil
+			return idx
+		}(),
 		wantErr: true,
 		errCode: dcrjson.ErrRPCInternal.Code,
+	}, {
+		name:                 "handleGetTxOutSetInfo: ok with index",
+		handler:              handleGetTxOutSetInfo,
+		cmd:                  &types.GetTxOutSetInfoCmd{},
+		mockCoinStatsIndexer: defaultMockCoinStatsIndexer(),
+		result:               wantIndexResult,
+	}, {
+		name:    "handleGetTxOutSetInfo: ok with index by height",
+		handler: handleGetTxOutSetInfo,
+		cmd: &types.GetTxOutSetInfoCmd{
+			HashOrHeight: &blkHeight,
+		},
+		mockCoinStatsIndexer: defaultMockCoinStatsIndexer(),
+		result:               wantIndexResult,
+	}, {
+		name:    "handleGetTxOutSetInfo: ok with index by hash",
+		handler: handleGetTxOutSetInfo,
+		cmd: &types.GetTxOutSetInfoCmd{
+			HashOrHeight: &blkHashStr,
+		},
+		mockCoinStatsIndexer: defaultMockCoinStatsIndexer(),
+		result:               wantIndexResult,
 	}})
 }
 
-func TestHandleGetTxOutSetInfo(t *testing.T) {
+func TestHandleGetUtxoCacheInfo(t *testing.T) {
 	t.Parallel()
 
+	lastFlushHash := mustParseHash("00000000000000001e6ec1501c858506de1de4703d1be8bab4061126e8f61480")
+	lastFlushTime := time.Unix(1644432000, 0)
 	testRPCServerHandler(t, []rpcTest{{
-		name:    "handleGetTxOutSetInfo: ok",
-		handler: handleGetTxOutSetInfo,
-		cmd:     &types.GetTxOutSetInfoCmd{},
-		result: types.GetTxOutSetInfoResult{
-			Height:         int64(block616802.Header.Height),
-			BestBlock:      block616802.BlockHash().String(),
-			Transactions:   689819,
-			TxOuts:         1593879,
-			SerializedHash: "fe7b32aa188800f07268b17f3bead5f3d8a1b6d18654182066436efce6effa86",
-			DiskSize:       36441617,
-			TotalAmount:    1154067750680149,
+		name:    "handleGetUtxoCacheInfo: ok",
+		handler: handleGetUtxoCacheInfo,
+		cmd:     &types.GetUtxoCacheInfoCmd{},
+		mockChain: func() *testRPCChain {
+			chain := defaultMockRPCChain()
+			chain.utxoCacheInfo = &blockchain.UtxoCacheInfo{
+				MaxSize:            157286400,
+				Size:               52428800,
+				Entries:            401234,
+				DirtyEntries:       1204,
+				DirtySize:          130032,
+				Hits:               9000,
+				Misses:             1000,
+				HitRatio:           90,
+				Prefetched:         750,
+				MemoryLimit:        4294967296,
+				MemoryInUse:        1073741824,
+				Flushes:            12,
+				LastFlushHash:      *lastFlushHash,
+				LastFlushTime:      lastFlushTime,
+				LastFlushReason:    "periodic",
+				LastFlushDuration:  1500 * time.Millisecond,
+				TotalFlushDuration: 12 * time.Second,
+			}
+			return chain
+		}(),
+		result: types.GetUtxoCacheInfoResult{
+			MaxSize:            157286400,
+			Size:               52428800,
+			Entries:            401234,
+			DirtyEntries:       1204,
+			DirtySize:          130032,
+			Hits:               9000,
+			Misses:             1000,
+			HitRatio:           90,
+			Prefetched:         750,
+			MemoryLimit:        4294967296,
+			MemoryInUse:        1073741824,
+			Flushes:            12,
+			LastFlushHash:      lastFlushHash.String(),
+			LastFlushTime:      1644432000,
+			LastFlushReason:    "periodic",
+			LastFlushDuration:  1500,
+			TotalFlushDuration: 12000,
+		},
+	}, {
+		name:    "handleGetUtxoCacheInfo: never flushed",
+		handler: handleGetUtxoCacheInfo,
+		cmd:     &types.GetUtxoCacheInfoCmd{},
+		mockChain: func() *testRPCChain {
+			chain := defaultMockRPCChain()
+			chain.utxoCacheInfo = &blockchain.UtxoCacheInfo{
+				MaxSize:         157286400,
+				LastFlushReason: "none",
+			}
+			return chain
+		}(),
+		result: types.GetUtxoCacheInfoResult{
+			MaxSize:         157286400,
+			LastFlushHash:   (&chainhash.Hash{}).String(),
+			LastFlushReason: "none",
 		},
 	}})
 }
@@ -5445,6 +7329,48 @@ func TestHandleInvalidateBlock(t *testing.T) {
 	}})
 }
 
+func TestHandleListBanned(t *testing.T) {
+	t.Parallel()
+
+	_, subnet, _ := net.ParseCIDR("10.0.0.0/8")
+	created := time.Unix(1700000000, 0)
+	testRPCServerHandler(t, []rpcTest{{
+		name:    "handleListBanned: ok",
+		handler: handleListBanned,
+		cmd:     &types.ListBannedCmd{},
+		mockConnManager: func() *testConnManager {
+			connManager := defaultMockConnManager()
+			connManager.bannedSubnets = []banmanager.BanEntry{{
+				Subnet:  subnet,
+				Created: created,
+				Expires: created.Add(time.Hour),
+			}}
+			return connManager
+		}(),
+		result: []types.ListBannedResult{{
+			Address:     "10.0.0.0/8",
+			BanCreated:  1700000000,
+			BannedUntil: 1700003600,
+		}},
+	}, {
+		name:    "handleListBanned: no bans",
+		handler: handleListBanned,
+		cmd:     &types.ListBannedCmd{},
+		result:  []types.ListBannedResult{},
+	}, {
+		name:    "handleListBanned: failed to list bans",
+		handler: handleListBanned,
+		cmd:     &types.ListBannedCmd{},
+		mockConnManager: func() *testConnManager {
+			connManager := defaultMockConnManager()
+			connManager.bannedSubnetsErr = errors.New("unable to write file")
+			return connManager
+		}(),
+		wantErr: true,
+		errCode: dcrjson.ErrRPCInternal.Code,
+	}})
+}
+
 func TestHandleLiveTickets(t *testing.T) {
 	t.Parallel()
 
@@ -6666,6 +8592,93 @@ func TestHandleGetWork(t *testing.T) {
 	}})
 }
 
+func TestHandleSetBan(t *testing.T) {
+	t.Parallel()
+
+	testRPCServerHandler(t, []rpcTest{{
+		name:    "handleSetBan: ok add address",
+		handler: handleSetBan,
+		cmd: &types.SetBanCmd{
+			SubCmd: types.SBAdd,
+			Subnet: "10.0.0.1",
+		},
+		result: nil,
+	}, {
+		name:    "handleSetBan: ok add subnet with duration",
+		handler: handleSetBan,
+		cmd: &types.SetBanCmd{
+			SubCmd:   types.SBAdd,
+			Subnet:   "10.0.0.0/8",
+			Duration: dcrjson.Int64(3600),
+		},
+		result: nil,
+	}, {
+		name:    "handleSetBan: add with invalid duration",
+		handler: handleSetBan,
+		cmd: &types.SetBanCmd{
+			SubCmd:   types.SBAdd,
+			Subnet:   "10.0.0.1",
+			Duration: dcrjson.Int64(0),
+		},
+		wantErr: true,
+		errCode: dcrjson.ErrRPCInvalidParameter,
+	}, {
+		name:    "handleSetBan: add failed to persist",
+		handler: handleSetBan,
+		cmd: &types.SetBanCmd{
+			SubCmd: types.SBAdd,
+			Subnet: "10.0.0.1",
+		},
+		mockConnManager: func() *testConnManager {
+			connManager := defaultMockConnManager()
+			connManager.banSubnetErr = errors.New("unable to write file")
+			return connManager
+		}(),
+		wantErr: true,
+		errCode: dcrjson.ErrRPCInternal.Code,
+	}, {
+		name:    "handleSetBan: ok remove",
+		handler: handleSetBan,
+		cmd: &types.SetBanCmd{
+			SubCmd: types.SBRemove,
+			Subnet: "10.0.0.1",
+		},
+		result: nil,
+	}, {
+		name:    "handleSetBan: remove not banned",
+		handler: handleSetBan,
+		cmd: &types.SetBanCmd{
+			SubCmd: types.SBRemove,
+			Subnet: "10.0.0.1",
+		},
+		mockConnManager: func() *testConnManager {
+			connManager := defaultMockConnManager()
+			connManager.unbanSubnetErr = banmanager.ErrNotBanned
+			return connManager
+		}(),
+		wantErr: true,
+		errCode: dcrjson.ErrRPCInvalidParameter,
+	}, {
+		name:    "handleSetBan: invalid subnet",
+		handler: handleSetBan,
+		cmd: &types.SetBanCmd{
+			SubCmd: types.SBAdd,
+			Subnet: "10.0.0.1/33",
+		},
+		wantErr: true,
+		errCode: dcrjson.ErrRPCInvalidParameter,
+	}, {
+		name:    "handleSetBan: invalid subcommand",
+		handler: handleSetBan,
+		cmd: &types.SetBanCmd{
+			SubCmd: "invalid",
+			Subnet: "10.0.0.1",
+		},
+		wantErr: true,
+		errCode: dcrjson.ErrRPCInvalidParameter,
+	}})
+}
+
 func TestHandleSetGenerate(t *testing.T) {
 	t.Parallel()
 
@@ -7313,6 +9326,141 @@ func TestHandleVerifyMessage(t *testing.T) {
 	}})
 }
 
+func TestHandleSearchScripts(t *testing.T) {
+	t.Parallel()
+
+	output := defaultMockScriptIndexer().outputs[0]
+	result := []types.SearchScriptsResult{{
+		TxID:          output.OutPoint.Hash.String(),
+		Vout:          output.OutPoint.Index,
+		Tree:          output.OutPoint.Tree,
+		BlockHash:     defaultMockRPCChain().blockHashByHeight.String(),
+		BlockHeight:   output.BlockHeight,
+		Value:         0,
+		ScriptVersion: output.ScriptVersion,
+		Script:        hex.EncodeToString(output.Script),
+	}}
+	modePtr := func(mode types.SearchScriptsMode) *types.SearchScriptsMode {
+		return &mode
+	}
+	testRPCServerHandler(t, []rpcTest{{
+		name:    "handleSearchScripts: script index disabled",
+		handler: handleSearchScripts,
+		cmd: &types.SearchScriptsCmd{
+			Data: "6a0a0102030405060708090a",
+		},
+		wantErr: true,
+		errCode: dcrjson.ErrRPCInternal.Code,
+	}, {
+		name:    "handleSearchScripts: index not synced",
+		handler: handleSearchScripts,
+		mockScriptIndexer: func() *testScriptIndexer {
+			idx := defaultMockScriptIndexer()
+			idx.tipHeight -= 6
+			return idx
+		}(),
+		cmd: &types.SearchScriptsCmd{
+			Data: "6a0a0102030405060708090a",
+		},
+		wantErr: true,
+		errCode: dcrjson.ErrRPCInternal.Code,
+	}, {
+		name:              "handleSearchScripts: invalid script hex",
+		handler:           handleSearchScripts,
+		mockScriptIndexer: defaultMockScriptIndexer(),
+		cmd: &types.SearchScriptsCmd{
+			Data: "6a0",
+		},
+		wantErr: true,
+		errCode: dcrjson.ErrRPCDecodeHexString,
+	}, {
+		name:              "handleSearchScripts: invalid script hash",
+		handler:           handleSearchScripts,
+		mockScriptIndexer: defaultMockScriptIndexer(),
+		cmd: &types.SearchScriptsCmd{
+			Data: "zz",
+			Mode: modePtr(types.SearchScriptsHash),
+		},
+		wantErr: true,
+		errCode: dcrjson.ErrRPCDecodeHexString,
+	}, {
+		name:              "handleSearchScripts: invalid mode",
+		handler:           handleSearchScripts,
+		mockScriptIndexer: defaultMockScriptIndexer(),
+		cmd: &types.SearchScriptsCmd{
+			Data: "6a0a0102030405060708090a",
+			Mode: modePtr("address"),
+		},
+		wantErr: true,
+		errCode: dcrjson.ErrRPCInvalidParameter,
+	}, {
+		name:    "handleSearchScripts: unable to fetch outputs",
+		handler: handleSearchScripts,
+		mockScriptIndexer: func() *testScriptIndexer {
+			idx := defaultMockScriptIndexer()
+			idx.outputsErr = errors.New("unable to fetch outputs")
+			return idx
+		}(),
+		cmd: &types.SearchScriptsCmd{
+			Data: "6a0a0102030405060708090a",
+		},
+		wantErr: true,
+		errCode: dcrjson.ErrRPCInternal.Code,
+	}, {
+		name:    "handleSearchScripts: unable to fetch block hash",
+		handler: handleSearchScripts,
+		mockChain: func() *testRPCChain {
+			chain := defaultMockRPCChain()
+			chain.blockHashByHeightErr = errors.New("block number out of range")
+			return chain
+		}(),
+		mockScriptIndexer: defaultMockScriptIndexer(),
+		cmd: &types.SearchScriptsCmd{
+			Data: "6a0a0102030405060708090a",
+		},
+		wantErr: true,
+		errCode: dcrjson.ErrRPCInternal.Code,
+	}, {
+		name:    "handleSearchScripts: no outputs",
+		handler: handleSearchScripts,
+		mockScriptIndexer: func() *testScriptIndexer {
+			idx := defaultMockScriptIndexer()
+			idx.outputs = nil
+			return idx
+		}(),
+		cmd: &types.SearchScriptsCmd{
+			Data: "6a0a0102030405060708090a",
+		},
+		result: []types.SearchScriptsResult{},
+	}, {
+		name:              "handleSearchScripts: ok by script",
+		handler:           handleSearchScripts,
+		mockScriptIndexer: defaultMockScriptIndexer(),
+		cmd: &types.SearchScriptsCmd{
+			Data: "6a0a0102030405060708090a",
+		},
+		result: result,
+	}, {
+		name:              "handleSearchScripts: ok by script hash",
+		handler:           handleSearchScripts,
+		mockScriptIndexer: defaultMockScriptIndexer(),
+		cmd: &types.SearchScriptsCmd{
+			Data: chainhash.HashH(output.Script).String(),
+			Mode: modePtr(types.SearchScriptsHash),
+		},
+		result: result,
+	}, {
+		name:              "handleSearchScripts: ok by null data prefix",
+		handler:           handleSearchScripts,
+		mockScriptIndexer: defaultMockScriptIndexer(),
+		cmd: &types.SearchScriptsCmd{
+			Data: "0102",
+			Mode: modePtr(types.SearchScriptsNullData),
+		},
+		result: result,
+	}})
+}
+
 func TestHandleSendRawTransaction(t *testing.T) {
 	t.Parallel()
 
@@ -8301,6 +10449,134 @@ func TestHandleVersion(t *testing.T) {
 	}})
 }
 
+func TestHandleGetSpendingTx(t *testing.T) {
+	t.Parallel()
+
+	txid := "2676a25f1567077f9728c09f8769182214f58a64e2336b4a83e6582345155400"
+	spenderHash := block616802.Transactions[1].TxHash()
+	blkHash := block616802.BlockHash()
+	blkHeight := int64(block616802.Header.Height)
+	entry := &indexers.SpenderIndexEntry{
+		SpenderHash: spenderHash,
+		InputIndex:  1,
+		BlockHeight: blkHeight,
+	}
+	testRPCServerHandler(t, []rpcTest{{
+		name:    "handleGetSpendingTx: spender index disabled",
+		handler: handleGetSpendingTx,
+		cmd: &types.GetSpendingTxCmd{
+			Txid: txid,
+			Vout: 0,
+		},
+		wantErr: true,
+		errCode: dcrjson.ErrRPCInternal.Code,
+	}, {
+		name:               "handleGetSpendingTx: invalid hash",
+		handler:            handleGetSpendingTx,
+		mockSpenderIndexer: defaultMockSpenderIndexer(),
+		cmd: &types.GetSpendingTxCmd{
+			Txid: "invalid",
+			Vout: 0,
+		},
+		wantErr: true,
+		errCode: dcrjson.ErrRPCDecodeHexString,
+	}, {
+		name:    "handleGetSpendingTx: unable to fetch index tip",
+		handler: handleGetSpendingTx,
+		mockSpenderIndexer: func() *testSpenderIndexer {
+			idx := defaultMockSpenderIndexer()
+			idx.tipErr = errors.New("unable to fetch index tip")
+			return idx
+		}(),
+		cmd: &types.GetSpendingTxCmd{
+			Txid: txid,
+			Vout: 0,
+		},
+		wantErr: true,
+		errCode: dcrjson.ErrRPCInternal.Code,
+	}, {
+		name:    "handleGetSpendingTx: index not synced",
+		handler: handleGetSpendingTx,
+		mockSpenderIndexer: func() *testSpenderIndexer {
+			idx := defaultMockSpenderIndexer()
+			idx.tipHeight -= 6
+			return idx
+		}(),
+		cmd: &types.GetSpendingTxCmd{
+			Txid: txid,
+			Vout: 0,
+		},
+		wantErr: true,
+		errCode: dcrjson.ErrRPCInternal.Code,
+	}, {
+		name:    "handleGetSpendingTx: unable to fetch index entry",
+		handler: handleGetSpendingTx,
+		mockSpenderIndexer: func() *testSpenderIndexer {
+			idx := defaultMockSpenderIndexer()
+			idx.entryErr = errors.New("unable to fetch index entry")
+			return idx
+		}(),
+		cmd: &types.GetSpendingTxCmd{
+			Txid: txid,
+			Vout: 0,
+		},
+		wantErr: true,
+		errCode: dcrjson.ErrRPCInternal.Code,
+	}, {
+		name:    "handleGetSpendingTx: unable to fetch block hash",
+		handler: handleGetSpendingTx,
+		mockChain: func() *testRPCChain {
+			chain := defaultMockRPCChain()
+			chain.blockHashByHeightErr = errors.New("unable to fetch " +
+				"block hash")
+			return chain
+		}(),
+		mockSpenderIndexer: func() *testSpenderIndexer {
+			idx := defaultMockSpenderIndexer()
+			idx.entry = entry
+			return idx
+		}(),
+		cmd: &types.GetSpendingTxCmd{
+			Txid: txid,
+			Vout: 0,
+		},
+		wantErr: true,
+		errCode: dcrjson.ErrRPCInternal.Code,
+	}, {
+		name:               "handleGetSpendingTx: unspent output",
+		handler:            handleGetSpendingTx,
+		mockSpenderIndexer: defaultMockSpenderIndexer(),
+		cmd: &types.GetSpendingTxCmd{
+			Txid: txid,
+			Vout: 0,
+		},
+		result: nil,
+	}, {
+		name:    "handleGetSpendingTx: ok",
+		handler: handleGetSpendingTx,
+		mockChain: func() *testRPCChain {
+			chain := defaultMockRPCChain()
+			chain.blockHashByHeight = &blkHash
+			return chain
+		}(),
+		mockSpenderIndexer: func() *testSpenderIndexer {
+			idx := defaultMockSpenderIndexer()
+			idx.entry = entry
+			return idx
+		}(),
+		cmd: &types.GetSpendingTxCmd{
+			Txid: txid,
+			Vout: 0,
+		},
+		result: &types.GetSpendingTxResult{
+			Txid:        spenderHash.String(),
+			Vin:         1,
+			BlockHash:   blkHash.String(),
+			BlockHeight: blkHeight,
+		},
+	}})
+}
+
 func TestHandleGetStakeDifficulty(t *testing.T) {
 	t.Parallel()
 
@@ -8451,6 +10727,27 @@ func testRPCServerHandler(t *testing.T, tests []rpcTest) {
 			if test.setTxIndexerNil {
 				rpcserverConfig.TxIndexer = nil
 			}
+			if test.mockSpenderIndexer != nil {
+				rpcserverConfig.SpenderIndexer = test.mockSpenderIndexer
+			}
+			if test.mockAddrBalIndexer != nil {
+				rpcserverConfig.AddrBalanceIndexer = test.mockAddrBalIndexer
+			}
+			if test.mockBlockStatsIndexer != nil {
+				rpcserverConfig.BlockStatsIndexer = test.mockBlockStatsIndexer
+			}
+			if test.mockTicketIndexer != nil {
+				rpcserverConfig.TicketIndexer = test.mockTicketIndexer
+			}
+			if test.mockCoinStatsIndexer != nil {
+				rpcserverConfig.CoinStatsIndexer = test.mockCoinStatsIndexer
+			}
+			if test.mockCFHeaderIndexer != nil {
+				rpcserverConfig.CFHeaderIndexer = test.mockCFHeaderIndexer
+			}
+			if test.mockScriptIndexer != nil {
+				rpcserverConfig.ScriptIndexer = test.mockScriptIndexer
+			}
 			if test.mockDB != nil {
 				rpcserverConfig.DB = test.mockDB
 			}
@@ -8466,6 +10763,9 @@ func testRPCServerHandler(t *testing.T, tests []rpcTest) {
 			if test.mockLogManager != nil {
 				rpcserverConfig.LogManager = test.mockLogManager
 			}
+			if test.mockIndexManager != nil {
+				rpcserverConfig.IndexManager = test.mockIndexManager
+			}
 			if test.mockSanityChecker != nil {
 				rpcserverConfig.SanityChecker = test.mockSanityChecker
 			}
@@ -41,6 +41,9 @@ var helpDescsEnUS = map[string]string{
 	"node-target":        "Either the IP address and port of the peer to operate on, or a valid peer ID.",
 	"node-connectsubcmd": "'perm' to make the connected peer a permanent one, 'temp' to try a single connect to a peer",
 
+	// ClearBannedCmd help.
+	"clearbanned--synopsis": "Removes all banned IP addresses and subnets.",
+
 	// TransactionInput help.
 	"transactioninput-amount": "The previous output amount in coins",
 	"transactioninput-txid":   "The hash of the input transaction",
@@ -139,6 +142,7 @@ var helpDescsEnUS = map[string]string{
 	"vout-n":            "The index of this transaction output",
 	"vout-version":      "The version of the public key script",
 	"vout-scriptPubKey": "The public key script used to pay coins as a JSON object",
+	"vout-spentby":      "The transaction that spends the output in the main chain (only when the spender index is enabled and the output is spent)",
 
 	// TxRawDecodeResult help.
 	"txrawdecoderesult-txid":     "The hash of the transaction",
@@ -164,6 +168,17 @@ var helpDescsEnUS = map[string]string{
 	"decodescript-hexscript": "Hex-encoded script",
 	"decodescript-version":   "The script version, defaults to version 0 if not set.",
 
+	// DisableIndexCmd help.
+	"disableindex--synopsis": "Stops maintaining an optional index and removes its data from the database in the background.\n" +
+		"Disabling txindex also disables addrindex since it depends on it.",
+	"disableindex-name": "The name of the index to disable (txindex, addrindex, or existsaddrindex)",
+
+	// EnableIndexCmd help.
+	"enableindex--synopsis": "Starts maintaining an optional index without restarting the node.\n" +
+		"The index catches up to the current best chain in the background and keeps up with new blocks once synced.\n" +
+		"Enabling addrindex also enables txindex since it depends on it.",
+	"enableindex-name": "The name of the index to enable (txindex, addrindex, or existsaddrindex)",
+
 	// ExistsAddressCmd help.
 	"existsaddress--synopsis": "Test for the existence of the provided address",
 	"existsaddress-address":   "The address to check",
@@ -222,6 +237,57 @@ var helpDescsEnUS = map[string]string{
 	"getaddednodeinfo--condition1": "dns=true",
 	"getaddednodeinfo--result0":    "List of added peers",
 
+	// GetAddressBalanceCmd help.
+	"getaddressbalance--synopsis": "Returns the balance of the given address along with its received and sent totals.\n" +
+		"This requires the address balance index to be enabled (--addrbalanceindex).",
+	"getaddressbalance-address": "The Decred address to query",
+
+	// GetAddressBalanceResult help.
+	"getaddressbalanceresult-address":     "The Decred address",
+	"getaddressbalanceresult-balance":     "The confirmed balance of the address",
+	"getaddressbalanceresult-received":    "The total amount of all confirmed outputs paid to the address",
+	"getaddressbalanceresult-sent":        "The total amount of all confirmed outputs paid to the address that have been spent",
+	"getaddressbalanceresult-unconfirmed": "The net change to the balance made by transactions in the memory pool",
+	"getaddressbalanceresult-numutxos":    "The number of confirmed unspent outputs paid to the address",
+
+	// GetAddressDeltasCmd help.
+	"getaddressdeltas--synopsis": "Returns the credits and debits of the given address in the order they appear in the blockchain.\n" +
+		"This requires the address balance index to be enabled (--addrbalanceindex).",
+	"getaddressdeltas-address":        "The Decred address to query",
+	"getaddressdeltas-skip":           "The number of leading deltas to leave out of the final response",
+	"getaddressdeltas-count":          "The maximum number of deltas to return",
+	"getaddressdeltas-includemempool": "Include the deltas made by transactions in the memory pool after the confirmed deltas",
+
+	// AddressDeltaResult help.
+	"addressdeltaresult-txid":          "The hash of the transaction that credits or debits the address",
+	"addressdeltaresult-index":         "The index of the input or output within the transaction",
+	"addressdeltaresult-isinput":       "Whether the delta is a debit made by an input as opposed to a credit made by an output",
+	"addressdeltaresult-amount":        "The signed amount of the delta, negative for debits",
+	"addressdeltaresult-height":        "The height of the block that contains the transaction or 0 when it is in the memory pool",
+	"addressdeltaresult-confirmations": "The number of confirmations of the transaction",
+
+	// GetAddressUtxosCmd help.
+	"getaddressutxos--synopsis": "Returns the confirmed unspent outputs paid to the given address ordered by block height.\n" +
+		"This requires the address balance index to be enabled (--addrbalanceindex).",
+	"getaddressutxos-address": "The Decred address to query",
+	"getaddressutxos-skip":    "The number of leading unspent outputs to leave out of the final response",
+	"getaddressutxos-count":   "The maximum number of unspent outputs to return",
+
+	// AddressUtxoResult help.
+	"addressutxoresult-txid":          "The hash of the transaction that contains the output",
+	"addressutxoresult-vout":          "The index of the output",
+	"addressutxoresult-tree":          "The tree of the transaction that contains the output",
+	"addressutxoresult-amount":        "The amount of the output",
+	"addressutxoresult-height":        "The height of the block that contains the output",
+	"addressutxoresult-confirmations": "The number of confirmations of the output",
+
+	// GetAddressTicketsCmd help.
+	"getaddresstickets--synopsis": "Returns the lifecycle of the tickets that commit to the given address as the voting address or as a commitment address ordered by purchase height.\n" +
+		"This requires the ticket index to be enabled (--ticketindex).",
+	"getaddresstickets-address": "The Decred address to query",
+	"getaddresstickets-skip":    "The number of leading tickets to leave out of the final response",
+	"getaddresstickets-count":   "The maximum number of tickets to return",
+
 	// GetBestBlockResult help.
 	"getbestblockresult-hash":   "Hex-encoded bytes of the best block hash",
 	"getbestblockresult-height": "Height of the best block",
@@ -374,6 +440,42 @@ var helpDescsEnUS = map[string]string{
 	"getblockheaderverboseresult-stakeversion":      "The stake version of the block",
 	"getblockheaderverboseresult-equihashsolution":  "The equihash solution of the block",
 
+	// GetBlockStatsCmd help.
+	"getblockstats--synopsis": "Returns statistics about the fees, sizes, and transactions of a block in the main chain.\n" +
+		"Fee statistics only consider the regular transactions other than the coinbase along with the ticket purchases and revocations.\n" +
+		"Requires the block statistics index to be enabled (--blockstatsindex).",
+	"getblockstats-hashorheight": "The hash or height of the block",
+	"getblockstats-fields":       "The statistics to return, all are returned when not specified",
+
+	// GetBlockStatsResult help.
+	"getblockstatsresult-avgfee":             "The average fee of the transactions that pay fees",
+	"getblockstatsresult-avgfeerate":         "The average fee rate in coins per kilobyte of the transactions that pay fees",
+	"getblockstatsresult-avgtxsize":          "The average size of the transactions that pay fees",
+	"getblockstatsresult-blockhash":          "The hash of the block",
+	"getblockstatsresult-feeratepercentiles": "The fee rates in coins per kilobyte at the 10th, 25th, 50th, 75th, and 90th percentiles weighted by transaction size",
+	"getblockstatsresult-height":             "The height of the block",
+	"getblockstatsresult-ins":                "The number of inputs that spend a previous output",
+	"getblockstatsresult-maxfee":             "The highest fee paid by a transaction",
+	"getblockstatsresult-maxfeerate":         "The highest fee rate in coins per kilobyte paid by a transaction",
+	"getblockstatsresult-maxtxsize":          "The size of the largest transaction that pays fees",
+	"getblockstatsresult-medianfee":          "The median fee of the transactions that pay fees",
+	"getblockstatsresult-mediantxsize":       "The median size of the transactions that pay fees",
+	"getblockstatsresult-minfee":             "The lowest fee paid by a transaction",
+	"getblockstatsresult-minfeerate":         "The lowest fee rate in coins per kilobyte paid by a transaction",
+	"getblockstatsresult-mintxsize":          "The size of the smallest transaction that pays fees",
+	"getblockstatsresult-outs":               "The number of outputs created",
+	"getblockstatsresult-revocations":        "The number of revocations",
+	"getblockstatsresult-size":               "The size of the block",
+	"getblockstatsresult-stxs":               "The number of transactions in the stake transaction tree",
+	"getblockstatsresult-tickets":            "The number of ticket purchases",
+	"getblockstatsresult-time":               "The block time in seconds since 1 Jan 1970 GMT",
+	"getblockstatsresult-totalfee":           "The total fees paid",
+	"getblockstatsresult-totalout":           "The total value of the outputs of the transactions that pay fees",
+	"getblockstatsresult-totalsize":          "The total size of the transactions that pay fees",
+	"getblockstatsresult-txs":                "The number of transactions in the regular transaction tree",
+	"getblockstatsresult-utxoincrease":       "The number of spendable outputs created minus the number of outputs spent",
+	"getblockstatsresult-votes":              "The number of votes",
+
 	// GetBlockSubsidyCmd help.
 	"getblocksubsidy--synopsis": "Returns information regarding subsidy amounts.",
 	"getblocksubsidy-height":    "The block height",
@@ -384,10 +486,33 @@ var helpDescsEnUS = map[string]string{
 	"getblocksubsidyresult-pow":   "The Proof-of-Work subsidy",
 	"getblocksubsidyresult-total": "The total subsidy",
 
+	// GetCFilterHeadersCmd help.
+	"getcfilterheaders--synopsis": "Returns the version 2 filter hashes and filter headers for a range of blocks in the main chain along with proofs that can be used to prove each filter is committed to by its block header.\n" +
+		"Each filter header is the BLAKE-256 hash of the filter hash concatenated with the previous filter header, and the previous filter header of the genesis block is all zeros.\n" +
+		"This requires the filter header index to be enabled (--cfheaderindex).",
+	"getcfilterheaders-startheight": "The height of the first block in the range",
+	"getcfilterheaders-count":       "The number of blocks in the range (max: 2000), limited to the best block",
+
+	// GetCFilterHeadersResult help.
+	"getcfilterheadersresult-prevheader": "The filter header of the block before the first block in the range",
+	"getcfilterheadersresult-headers":    "The filter hashes and filter headers of the blocks in the range",
+
+	// CFilterHeaderResult help.
+	"cfilterheaderresult-blockhash":   "The hash of the block",
+	"cfilterheaderresult-filterhash":  "The hash of the version 2 filter of the block",
+	"cfilterheaderresult-header":      "The filter header that commits to the filter and all previous filters",
+	"cfilterheaderresult-proofindex":  "The index of the leaf that represents the filter hash in the header commitment",
+	"cfilterheaderresult-proofhashes": "The hashes needed to prove the filter is committed to by the header commitment",
+
 	// GetCFilterV2Cmd help.
 	"getcfilterv2--synopsis": "Returns the version 2 block filter for the given block along with a proof that can be used to prove the filter is committed to by the block header",
 	"getcfilterv2-blockhash": "The block hash of the filter to retrieve",
 
+	// GetCFilterV2RangeCmd help.
+	"getcfilterv2range--synopsis":   "Returns the version 2 block filters for a range of blocks in the main chain along with proofs that can be used to prove each filter is committed to by its block header",
+	"getcfilterv2range-startheight": "The height of the first block in the range",
+	"getcfilterv2range-count":       "The number of blocks in the range (max: 1000), limited to the best block",
+
 	// GetCFilterV2Result help.
 	"getcfilterv2result-blockhash":   "The block hash for which the filter includes data",
 	"getcfilterv2result-data":        "Hex-encoded bytes of the serialized filter",
@@ -526,6 +651,20 @@ var helpDescsEnUS = map[string]string{
 	"getheaders-hashstop":      "Block hash to stop including block headers for. Set to zero to get as many blocks as possible",
 	"getheadersresult-headers": "Serialized block headers of all located blocks, limited to some arbitrary maximum number of hashes (currently 2000, which matches the wire protocol headers message, but this is not guaranteed)",
 
+	// GetIndexInfoCmd help.
+	"getindexinfo--synopsis":       "Returns the status of the enabled optional indexes along with any indexes that are still being removed.",
+	"getindexinfo-name":            "Only return the status of the index with this name",
+	"getindexinfo--result0--desc":  "Index status objects keyed by the index name",
+	"getindexinfo--result0--key":   "The index name",
+	"getindexinfo--result0--value": "Object containing the index status",
+
+	// IndexInfoResult help.
+	"indexinforesult-synced":          "Whether or not the index is synced to the current best chain and receiving updates",
+	"indexinforesult-dropping":        "Whether or not the index is being removed",
+	"indexinforesult-bestblockheight": "The height of the most recent block the index has processed",
+	"indexinforesult-bestblockhash":   "The hash of the most recent block the index has processed (empty while dropping)",
+	"indexinforesult-diskusage":       "The approximate number of bytes used by the index in the database",
+
 	// GetInfoCmd help.
 	"getinfo--synopsis": "Returns a JSON object containing various state info.",
 
@@ -585,6 +724,23 @@ var helpDescsEnUS = map[string]string{
 	"getnetworkinforesult-localaddresses":  "An array of objects describing local addresses being listened on by the node",
 	"getnetworkinforesult-localservices":   "The services supported by the node, as advertised in its version message",
 
+	// GetNetMsgStatsCmd help.
+	"getnetmsgstats--synopsis": "Returns the number of messages and bytes sent to and received from all peers since the server started per message type.",
+
+	// GetNetMsgStatsResult help.
+	"getnetmsgstatsresult-sent":        "Messages sent",
+	"getnetmsgstatsresult-sent--desc":  "The messages sent per message type",
+	"getnetmsgstatsresult-sent--key":   "The message type ('*other*' for data that could not be decoded)",
+	"getnetmsgstatsresult-sent--value": "The number of messages and bytes sent",
+	"getnetmsgstatsresult-recv":        "Messages received",
+	"getnetmsgstatsresult-recv--desc":  "The messages received per message type",
+	"getnetmsgstatsresult-recv--key":   "The message type ('*other*' for data that could not be decoded)",
+	"getnetmsgstatsresult-recv--value": "The number of messages and bytes received",
+
+	// NetMsgStat help.
+	"netmsgstat-msgs":  "The number of messages",
+	"netmsgstat-bytes": "The total size of the messages in bytes",
+
 	// GetNetTotalsCmd help.
 	"getnettotals--synopsis": "Returns a JSON object containing network traffic statistics.",
 
@@ -592,28 +748,47 @@ var helpDescsEnUS = map[string]string{
 	"getnettotalsresult-totalbytesrecv": "Total bytes received",
 	"getnettotalsresult-totalbytessent": "Total bytes sent",
 	"getnettotalsresult-timemillis":     "Number of milliseconds since 1 Jan 1970 GMT",
+	"getnettotalsresult-uploadtarget":   "The state of the target for the maximum number of bytes to send to peers per cycle",
+
+	// UploadTargetResult help.
+	"uploadtargetresult-timeframe":               "The duration of each cycle in seconds",
+	"uploadtargetresult-target":                  "The maximum number of bytes to send per cycle (0 when there is no target)",
+	"uploadtargetresult-target_reached":          "Whether or not the number of bytes sent during the current cycle has reached the target",
+	"uploadtargetresult-serve_historical_blocks": "Whether or not historical blocks are still served to peers that are not whitelisted",
+	"uploadtargetresult-bytes_left_in_cycle":     "The number of bytes that may still be sent during the current cycle",
+	"uploadtargetresult-time_left_in_cycle":      "The number of seconds that remain in the current cycle",
 
 	// GetPeerInfoResult help.
-	"getpeerinforesult-id":             "A unique node ID",
-	"getpeerinforesult-addr":           "The ip address and port of the peer",
-	"getpeerinforesult-addrlocal":      "Local address",
-	"getpeerinforesult-services":       "Services bitmask which represents the services supported by the peer",
-	"getpeerinforesult-relaytxes":      "Peer has requested transactions be relayed to it",
-	"getpeerinforesult-lastsend":       "Time the last message was received in seconds since 1 Jan 1970 GMT",
-	"getpeerinforesult-lastrecv":       "Time the last message was sent in seconds since 1 Jan 1970 GMT",
-	"getpeerinforesult-bytessent":      "Total bytes sent",
-	"getpeerinforesult-bytesrecv":      "Total bytes received",
-	"getpeerinforesult-conntime":       "Time the connection was made in seconds since 1 Jan 1970 GMT",
-	"getpeerinforesult-timeoffset":     "The time offset of the peer",
-	"getpeerinforesult-pingtime":       "Number of microseconds the last ping took",
-	"getpeerinforesult-pingwait":       "Number of microseconds a queued ping has been waiting for a response",
-	"getpeerinforesult-version":        "The protocol version of the peer",
-	"getpeerinforesult-subver":         "The user agent of the peer",
-	"getpeerinforesult-inbound":        "Whether or not the peer is an inbound connection",
-	"getpeerinforesult-startingheight": "The latest block height the peer knew about when the connection was established",
-	"getpeerinforesult-currentheight":  "The current height of the peer",
-	"getpeerinforesult-banscore":       "The ban score",
-	"getpeerinforesult-syncnode":       "Whether or not the peer is the sync peer",
+	"getpeerinforesult-id":                "A unique node ID",
+	"getpeerinforesult-addr":              "The ip address and port of the peer",
+	"getpeerinforesult-addrlocal":         "Local address",
+	"getpeerinforesult-services":          "Services bitmask which represents the services supported by the peer",
+	"getpeerinforesult-relaytxes":         "Peer has requested transactions be relayed to it",
+	"getpeerinforesult-lastsend":          "Time the last message was received in seconds since 1 Jan 1970 GMT",
+	"getpeerinforesult-lastrecv":          "Time the last message was sent in seconds since 1 Jan 1970 GMT",
+	"getpeerinforesult-bytessent":         "Total bytes sent",
+	"getpeerinforesult-bytesrecv":         "Total bytes received",
+	"getpeerinforesult-conntime":          "Time the connection was made in seconds since 1 Jan 1970 GMT",
+	"getpeerinforesult-timeoffset":        "The time offset of the peer",
+	"getpeerinforesult-pingtime":          "Number of microseconds the last ping took",
+	"getpeerinforesult-pingwait":          "Number of microseconds a queued ping has been waiting for a response",
+	"getpeerinforesult-version":           "The protocol version of the peer",
+	"getpeerinforesult-subver":            "The user agent of the peer",
+	"getpeerinforesult-inbound":           "Whether or not the peer is an inbound connection",
+	"getpeerinforesult-conntype":          "The type of the connection (inbound, manual, outbound-full-relay, or block-relay-only)",
+	"getpeerinforesult-mappedas":          "The autonomous system number the address of the peer is mapped to by the AS map in use (omitted when not mapped)",
+	"getpeerinforesult-startingheight":    "The latest block height the peer knew about when the connection was established",
+	"getpeerinforesult-currentheight":     "The current height of the peer",
+	"getpeerinforesult-banscore":          "The ban score",
+	"getpeerinforesult-syncnode":          "Whether or not the peer is the sync peer",
+	"getpeerinforesult-sentpermsg":        "Messages sent to the peer",
+	"getpeerinforesult-sentpermsg--desc":  "The messages sent to the peer per message type",
+	"getpeerinforesult-sentpermsg--key":   "The message type ('*other*' for data that could not be decoded)",
+	"getpeerinforesult-sentpermsg--value": "The number of messages and bytes sent",
+	"getpeerinforesult-recvpermsg":        "Messages received from the peer",
+	"getpeerinforesult-recvpermsg--desc":  "The messages received from the peer per message type",
+	"getpeerinforesult-recvpermsg--key":   "The message type ('*other*' for data that could not be decoded)",
+	"getpeerinforesult-recvpermsg--value": "The number of messages and bytes received",
 
 	// GetPeerInfoCmd help.
 	"getpeerinfo--synopsis": "Returns data about each connected network peer as an array of json objects.",
@@ -643,6 +818,48 @@ var helpDescsEnUS = map[string]string{
 	"getrawtransaction--condition1": "verbose=true",
 	"getrawtransaction--result0":    "Hex-encoded bytes of the serialized transaction",
 
+	// GetSpendingTxCmd help.
+	"getspendingtx--synopsis": "Returns the transaction that spends the given output in the main chain or null when it is unspent.\n" +
+		"This requires the spender index to be enabled (--spenderindex).",
+	"getspendingtx-txid": "The hash of the transaction that contains the output",
+	"getspendingtx-vout": "The index of the output",
+
+	// GetSpendingTxResult help.
+	"getspendingtxresult-txid":        "The hash of the spending transaction",
+	"getspendingtxresult-vin":         "The index of the input that spends the output",
+	"getspendingtxresult-blockhash":   "The hash of the block that contains the spending transaction",
+	"getspendingtxresult-blockheight": "The height of the block that contains the spending transaction",
+
+	// GetTicketInfoCmd help.
+	"getticketinfo--synopsis": "Returns the lifecycle of the given ticket from its purchase through the vote or revocation that spends it.\n" +
+		"This requires the ticket index to be enabled (--ticketindex).",
+	"getticketinfo-hash": "The hash of the ticket purchase transaction",
+
+	// GetTicketInfoResult help.
+	"getticketinforesult-hash":           "The hash of the ticket purchase transaction",
+	"getticketinforesult-status":         "The status of the ticket (immature, live, voted, missed, expired, or revoked)",
+	"getticketinforesult-price":          "The price of the ticket",
+	"getticketinforesult-purchaseheight": "The height of the block that contains the ticket",
+	"getticketinforesult-purchaseblock":  "The hash of the block that contains the ticket",
+	"getticketinforesult-maturityheight": "The height at which the ticket becomes live",
+	"getticketinforesult-expiryheight":   "The height at which the ticket expires when it has not voted",
+	"getticketinforesult-missedheight":   "The height of the block that missed the ticket when it was selected to vote",
+	"getticketinforesult-expiredheight":  "The height of the block that expired the ticket",
+	"getticketinforesult-vote":           "The vote that spent the ticket",
+	"getticketinforesult-revocation":     "The revocation that spent the ticket",
+
+	// TicketVoteResult help.
+	"ticketvoteresult-txid":        "The hash of the vote",
+	"ticketvoteresult-blockhash":   "The hash of the block that contains the vote",
+	"ticketvoteresult-blockheight": "The height of the block that contains the vote",
+	"ticketvoteresult-version":     "The version of the vote",
+	"ticketvoteresult-votebits":    "The vote bits of the vote",
+
+	// TicketRevocationResult help.
+	"ticketrevocationresult-txid":        "The hash of the revocation",
+	"ticketrevocationresult-blockhash":   "The hash of the block that contains the revocation",
+	"ticketrevocationresult-blockheight": "The height of the block that contains the revocation",
+
 	// GetTicketPoolValue help.
 	"getticketpoolvalue--synopsis": "Return the current value of all locked funds in the ticket pool",
 	"getticketpoolvalue--result0":  "Total value of ticket pool",
@@ -695,15 +912,43 @@ var helpDescsEnUS = map[string]string{
 	"gettxout-includemempool": "Include the mempool when true",
 
 	// GetTxOutSetInfoCmd help.
-	"gettxoutsetinfo--synopsis": "Returns statistics on current unspent transaction output set.",
+	"gettxoutsetinfo--synopsis": "Returns statistics on the unspent transaction output set.\n" +
+		"The statistics are calculated from the entire current set unless the coin statistics index is enabled and used, in which case they are returned instantly and are also available as of any block in the main chain.\n" +
+		"The transactions, serializedhash, and disksize fields are only available when the statistics are calculated from the entire set, while the sethash and bogosize fields are only available from the index.",
+	"gettxoutsetinfo-hashorheight": "The hash or height of the block in the main chain to return the statistics as of, requires the coin statistics index (default: the best block)",
+	"gettxoutsetinfo-useindex":     "Use the coin statistics index when it is enabled",
+
+	// GetUtxoCacheInfoCmd help.
+	"getutxocacheinfo--synopsis": "Returns information about the state and performance of the utxo cache.",
+
+	// GetUtxoCacheInfoResult help.
+	"getutxocacheinforesult-maxsize":            "The maximum size of the cache, in bytes.",
+	"getutxocacheinforesult-size":               "The current size of the cache, in bytes.",
+	"getutxocacheinforesult-entries":            "The number of entries in the cache.",
+	"getutxocacheinforesult-dirtyentries":       "The number of entries that have been modified since the last flush.",
+	"getutxocacheinforesult-dirtysize":          "The size of the entries that have been modified since the last flush, in bytes.",
+	"getutxocacheinforesult-hits":               "The total number of lookups that were satisfied by the cache.",
+	"getutxocacheinforesult-misses":             "The total number of lookups that were not satisfied by the cache.",
+	"getutxocacheinforesult-hitratio":           "The percentage of lookups that were satisfied by the cache.",
+	"getutxocacheinforesult-prefetched":         "The total number of entries loaded into the cache ahead of time for upcoming blocks.",
+	"getutxocacheinforesult-memorylimit":        "The memory in use by the process, in bytes, at which the cache is flushed (0 when disabled).",
+	"getutxocacheinforesult-memoryinuse":        "The most recently sampled memory in use by the process, in bytes (0 when the memory limit is disabled).",
+	"getutxocacheinforesult-flushes":            "The total number of flushes to the database.",
+	"getutxocacheinforesult-lastflushhash":      "The hash of the best block as of the last flush.",
+	"getutxocacheinforesult-lastflushtime":      "The time of the last flush in seconds since 1 Jan 1970 GMT.",
+	"getutxocacheinforesult-lastflushreason":    "The reason for the last flush (none, forced, maxsize, memorypressure, or periodic).",
+	"getutxocacheinforesult-lastflushduration":  "The amount of time the last flush took, in milliseconds.",
+	"getutxocacheinforesult-totalflushduration": "The total amount of time spent flushing, in milliseconds.",
 
 	// GetTxOutSetInfoResult help.
-	"gettxoutsetinforesult-height":         "The current block height.",
-	"gettxoutsetinforesult-bestblock":      "The hex encoded hash of the best block.",
+	"gettxoutsetinforesult-height":         "The height of the block the statistics are as of.",
+	"gettxoutsetinforesult-bestblock":      "The hex encoded hash of the block the statistics are as of.",
 	"gettxoutsetinforesult-transactions":   "The number of unique transactions referenced by outputs.",
 	"gettxoutsetinforesult-txouts":         "The number of transaction outputs.",
 	"gettxoutsetinforesult-serializedhash": "The merklized hash of the utxo set.",
+	"gettxoutsetinforesult-sethash":        "The rolling multiset hash of the utxo set which commits to every unspent output.",
 	"gettxoutsetinforesult-disksize":       "The size of the utxo set on disk, in bytes.",
+	"gettxoutsetinforesult-bogosize":       "The database-independent size of the serialized unspent outputs, in bytes.",
 	"gettxoutsetinforesult-totalamount":    "The total value of the utxo set.",
 
 	// GetWorkResult help.
@@ -764,12 +1009,39 @@ var helpDescsEnUS = map[string]string{
 	"searchrawtransactions-filteraddrs": "Address list.  Only inputs or outputs with matching address will be returned",
 	"searchrawtransactions--result0":    "Hex-encoded serialized transaction",
 
+	// SearchScriptsCmd help.
+	"searchscripts--synopsis": "Returns the outputs in the main chain with scripts that do not decode to addresses, such as non-standard scripts, scripts with unknown versions, and null data scripts, that match the provided data.\n" +
+		"Outputs are returned in order of block height, except for null data prefixes shorter than 8 bytes, in which case they are ordered by the first 8 bytes of the data they carry and then by block height.\n" +
+		"Only outputs in the regular transaction tree are indexed.\n" +
+		"This requires the script index to be enabled (--scriptindex).",
+	"searchscripts-data":  "The hex-encoded script, the hash of the script, or the hex-encoded prefix of the data carried by null data scripts depending on the mode",
+	"searchscripts-mode":  "How the data is interpreted: 'script' for an exact script, 'hash' for the BLAKE-256 hash of a script, or 'nulldata' for a null data prefix",
+	"searchscripts-skip":  "The number of leading outputs to leave out of the final response",
+	"searchscripts-count": "The maximum number of outputs to return",
+
+	// SearchScriptsResult help.
+	"searchscriptsresult-txid":          "The hash of the transaction that contains the output",
+	"searchscriptsresult-vout":          "The index of the output",
+	"searchscriptsresult-tree":          "The tree of the transaction that contains the output",
+	"searchscriptsresult-blockhash":     "The hash of the block that contains the output",
+	"searchscriptsresult-blockheight":   "The height of the block that contains the output",
+	"searchscriptsresult-value":         "The amount of the output",
+	"searchscriptsresult-scriptversion": "The version of the output script",
+	"searchscriptsresult-script":        "The hex-encoded output script",
+
 	// SendRawTransactionCmd help.
 	"sendrawtransaction--synopsis":     "Submits the serialized, hex-encoded transaction to the local peer and relays it to the network.",
 	"sendrawtransaction-hextx":         "Serialized, hex-encoded signed transaction",
 	"sendrawtransaction-allowhighfees": "Whether or not to allow insanely high fees (exccd does not yet implement this parameter, so it has no effect)",
 	"sendrawtransaction--result0":      "The hash of the transaction",
 
+	// SetBanCmd help.
+	"setban--synopsis": "Bans or removes the ban for an IP address or subnet.\n" +
+		"Banned addresses are disconnected and are not allowed to connect until the ban expires or is removed.",
+	"setban-subcmd":   "'add' to ban an IP address or subnet, or 'remove' to remove a ban",
+	"setban-subnet":   "The IP address or subnet in CIDR notation (e.g. 192.168.0.0/24) to operate on",
+	"setban-duration": "The number of seconds the ban lasts (default: the configured ban duration, ignored when removing a ban)",
+
 	// SetGenerateCmd help.
 	"setgenerate--synopsis":    "Set the server to generate coins (mine) or not.",
 	"setgenerate-generate":     "Use true to enable generation, false to disable it",
@@ -831,6 +1103,23 @@ var helpDescsEnUS = map[string]string{
 	// NotifyWinningTicketsCmd help
 	"notifywinningtickets--synopsis": "Request notifications for whenever any tickets are chosen to vote.",
 
+	// AddressCursor help.
+	"addresscursor-blockhash":   "The hash of the block that contains the transaction",
+	"addresscursor-blockheight": "The height of the block that contains the transaction (informational only)",
+	"addresscursor-tree":        "The tree of the block that contains the transaction",
+	"addresscursor-index":       "The index of the transaction within the tree of the block",
+
+	// NotifyAddressesCmd help.
+	"notifyaddresses--synopsis": "Request addresstx notifications for whenever a transaction involving any of the passed addresses is accepted into the mempool or mined into the main (best) chain.\n" +
+		"When a cursor from a previous addresstx notification is provided, all mined transactions after it followed by all unconfirmed transactions that involve the passed addresses are sent first.\n" +
+		"Pay-to-pubkey addresses are watched and reported as their pay-to-pubkey-hash equivalent.",
+	"notifyaddresses-addresses": "Array of addresses to watch",
+	"notifyaddresses-cursor":    "The cursor of the last mined transaction received for the addresses to resume notifications after (requires --addrindex)",
+
+	// StopNotifyAddressesCmd help.
+	"stopnotifyaddresses--synopsis": "Cancel registered addresstx notifications for the passed addresses.",
+	"stopnotifyaddresses-addresses": "Array of addresses to stop watching or an empty array to stop watching all of them",
+
 	// NotifyBlocksCmd help.
 	"notifyblocks--synopsis": "Request notifications for whenever a block is connected or disconnected from the main (best) chain.",
 
@@ -898,6 +1187,12 @@ var helpDescsEnUS = map[string]string{
 	"getcoinsupply--synopsis": "Returns current total coin supply in atoms",
 	"getcoinsupply--result0":  "Current coin supply in atoms",
 
+	// ListBannedCmd help.
+	"listbanned--synopsis":          "Returns all banned IP addresses and subnets.",
+	"listbannedresult-address":      "The banned IP address or subnet in CIDR notation",
+	"listbannedresult-ban_created":  "The time the ban was created in seconds since 1 Jan 1970 GMT",
+	"listbannedresult-banned_until": "The time the ban expires in seconds since 1 Jan 1970 GMT",
+
 	// LiveTickets help.
 	"livetickets--synopsis":     "Returns live ticket hashes from the ticket database",
 	"liveticketsresult-tickets": "List of live tickets",
@@ -992,12 +1287,15 @@ var helpDescsEnUS = map[string]string{
 // pointer to the type (or nil to indicate no return value).
 var rpcResultTypes = map[types.Method][]interface{}{
 	"addnode":               nil,
+	"clearbanned":           nil,
 	"createrawsstx":         {(*string)(nil)},
 	"createrawssrtx":        {(*string)(nil)},
 	"createrawtransaction":  {(*string)(nil)},
 	"debuglevel":            {(*string)(nil), (*string)(nil)},
 	"decoderawtransaction":  {(*types.TxRawDecodeResult)(nil)},
 	"decodescript":          {(*types.DecodeScriptResult)(nil)},
+	"disableindex":          nil,
+	"enableindex":           nil,
 	"estimatefee":           {(*float64)(nil)},
 	"estimatesmartfee":      {(*types.EstimateSmartFeeResult)(nil)},
 	"estimatestakediff":     {(*types.EstimateStakeDiffResult)(nil)},
@@ -1009,6 +1307,10 @@ var rpcResultTypes = map[types.Method][]interface{}{
 	"existslivetickets":     {(*string)(nil)},
 	"existsmempooltxs":      {(*string)(nil)},
 	"getaddednodeinfo":      {(*[]string)(nil), (*[]types.GetAddedNodeInfoResult)(nil)},
+	"getaddressbalance":     {(*types.GetAddressBalanceResult)(nil)},
+	"getaddressdeltas":      {(*[]types.AddressDeltaResult)(nil)},
+	"getaddresstickets":     {(*[]types.GetTicketInfoResult)(nil)},
+	"getaddressutxos":       {(*[]types.AddressUtxoResult)(nil)},
 	"getbestblock":          {(*types.GetBestBlockResult)(nil)},
 	"generate":              {(*[]string)(nil)},
 	"getbestblockhash":      {(*string)(nil)},
@@ -1017,8 +1319,11 @@ var rpcResultTypes = map[types.Method][]interface{}{
 	"getblockcount":         {(*int64)(nil)},
 	"getblockhash":          {(*string)(nil)},
 	"getblockheader":        {(*string)(nil), (*types.GetBlockHeaderVerboseResult)(nil)},
+	"getblockstats":         {(*types.GetBlockStatsResult)(nil)},
 	"getblocksubsidy":       {(*types.GetBlockSubsidyResult)(nil)},
+	"getcfilterheaders":     {(*types.GetCFilterHeadersResult)(nil)},
 	"getcfilterv2":          {(*types.GetCFilterV2Result)(nil)},
+	"getcfilterv2range":     {(*[]types.GetCFilterV2Result)(nil)},
 	"getchaintips":          {(*[]types.GetChainTipsResult)(nil)},
 	"getconnectioncount":    {(*int32)(nil)},
 	"getcurrentnet":         {(*uint32)(nil)},
@@ -1029,25 +1334,31 @@ var rpcResultTypes = map[types.Method][]interface{}{
 	"getgenerate":           {(*bool)(nil)},
 	"gethashespersec":       {(*float64)(nil)},
 	"getheaders":            {(*types.GetHeadersResult)(nil)},
+	"getindexinfo":          {(*map[string]types.IndexInfoResult)(nil)},
 	"getinfo":               {(*types.InfoChainResult)(nil)},
 	"getmempoolinfo":        {(*types.GetMempoolInfoResult)(nil)},
 	"getmininginfo":         {(*types.GetMiningInfoResult)(nil)},
+	"getnetmsgstats":        {(*types.GetNetMsgStatsResult)(nil)},
 	"getnettotals":          {(*types.GetNetTotalsResult)(nil)},
 	"getnetworkhashps":      {(*int64)(nil)},
 	"getnetworkinfo":        {(*[]types.GetNetworkInfoResult)(nil)},
 	"getpeerinfo":           {(*[]types.GetPeerInfoResult)(nil)},
 	"getrawmempool":         {(*[]string)(nil), (*types.GetRawMempoolVerboseResult)(nil)},
 	"getrawtransaction":     {(*string)(nil), (*types.TxRawResult)(nil)},
+	"getspendingtx":         {(*types.GetSpendingTxResult)(nil)},
+	"getticketinfo":         {(*types.GetTicketInfoResult)(nil)},
 	"getticketpoolvalue":    {(*float64)(nil)},
 	"gettreasurybalance":    {(*types.GetTreasuryBalanceResult)(nil)},
 	"gettreasuryspendvotes": {(*types.GetTreasurySpendVotesResult)(nil)},
 	"gettxout":              {(*types.GetTxOutResult)(nil)},
 	"gettxoutsetinfo":       {(*types.GetTxOutSetInfoResult)(nil)},
+	"getutxocacheinfo":      {(*types.GetUtxoCacheInfoResult)(nil)},
 	"getvoteinfo":           {(*types.GetVoteInfoResult)(nil)},
 	"getwork":               {(*types.GetWorkResult)(nil), (*bool)(nil)},
 	"getcoinsupply":         {(*int64)(nil)},
 	"help":                  {(*string)(nil), (*string)(nil)},
 	"invalidateblock":       nil,
+	"listbanned":            {(*[]types.ListBannedResult)(nil)},
 	"livetickets":           {(*types.LiveTicketsResult)(nil)},
 	"missedtickets":         {(*types.MissedTicketsResult)(nil)},
 	"node":                  nil,
@@ -1055,7 +1366,9 @@ var rpcResultTypes = map[types.Method][]interface{}{
 	"reconsiderblock":       nil,
 	"regentemplate":         nil,
 	"searchrawtransactions": {(*string)(nil), (*[]types.SearchRawTransactionsResult)(nil)},
+	"searchscripts":         {(*[]types.SearchScriptsResult)(nil)},
 	"sendrawtransaction":    {(*string)(nil)},
+	"setban":                nil,
 	"setgenerate":           nil,
 	"stop":                  {(*string)(nil)},
 	"submitblock":           {nil, (*string)(nil)},
@@ -1070,6 +1383,7 @@ var rpcResultTypes = map[types.Method][]interface{}{
 
 	// Websocket commands.
 	"loadtxfilter":                nil,
+	"notifyaddresses":             nil,
 	"notifywinningtickets":        nil,
 	"notifyspentandmissedtickets": nil,
 	"notifynewtickets":            nil,
@@ -1083,6 +1397,7 @@ var rpcResultTypes = map[types.Method][]interface{}{
 	"rebroadcastwinners":          nil,
 	"rescan":                      nil,
 	"session":                     {(*types.SessionResult)(nil)},
+	"stopnotifyaddresses":         nil,
 	"stopnotifyblocks":            nil,
 	"stopnotifywork":              nil,
 	"stopnotifytspend":            nil,
@@ -13,6 +13,8 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"math"
+	"sort"
 	"strconv"
 	"sync"
 	"time"
@@ -22,9 +24,11 @@ import (
 	"github.com/EXCCoin/exccd/blockchain/stake/v4"
 	"github.com/EXCCoin/exccd/blockchain/standalone/v2"
 	"github.com/EXCCoin/exccd/blockchain/v4"
+	"github.com/EXCCoin/exccd/blockchain/v4/indexers"
 	"github.com/EXCCoin/exccd/chaincfg/chainhash"
 	"github.com/EXCCoin/exccd/chaincfg/v3"
 	"github.com/EXCCoin/exccd/crypto/ripemd160"
+	"github.com/EXCCoin/exccd/database/v3"
 	"github.com/EXCCoin/exccd/dcrjson/v4"
 	"github.com/EXCCoin/exccd/dcrutil/v4"
 	"github.com/EXCCoin/exccd/internal/mining"
@@ -79,6 +83,7 @@ var wsHandlers map[types.Method]wsCommandHandler
 var wsHandlersBeforeInit = map[types.Method]wsCommandHandler{
 	"help":                        handleWebsocketHelp,
 	"loadtxfilter":                handleLoadTxFilter,
+	"notifyaddresses":             handleNotifyAddresses,
 	"notifyblocks":                handleNotifyBlocks,
 	"notifywork":                  handleNotifyWork,
 	"notifytspend":                handleNotifyTSpend,
@@ -90,6 +95,7 @@ var wsHandlersBeforeInit = map[types.Method]wsCommandHandler{
 	"rebroadcastwinners":          handleRebroadcastWinners,
 	"rescan":                      handleRescan,
 	"session":                     handleSession,
+	"stopnotifyaddresses":         handleStopNotifyAddresses,
 	"stopnotifyblocks":            handleStopNotifyBlocks,
 	"stopnotifywork":              handleStopNotifyWork,
 	"stopnotifytspend":            handleStopNotifyTSpend,
@@ -212,10 +218,16 @@ out:
 
 // NotifyBlockConnected passes a block newly-connected to the best chain
 // to the notification manager for block and transaction notification
-// processing.
-func (m *wsNotificationManager) NotifyBlockConnected(block *dcrutil.Block) {
+// processing.  The previous scripts provide the scripts of the outputs spent
+// by the block.
+func (m *wsNotificationManager) NotifyBlockConnected(block *dcrutil.Block, prevScripts indexers.PrevScripter) {
+	n := &notificationBlockConnected{
+		block:       block,
+		prevScripts: prevScripts,
+	}
+
 	select {
-	case m.queueNotification <- (*notificationBlockConnected)(block):
+	case m.queueNotification <- n:
 	case <-m.quit:
 	}
 }
@@ -417,8 +429,183 @@ func (f *wsClientFilter) existsUnspentOutPoint(op *wire.OutPoint) bool {
 	return ok
 }
 
+// wsAddressWatch houses the addresses a websocket client registered for with
+// notifyaddresses along with the state needed to resume notifications from a
+// cursor without dropping or reordering any of them.
+type wsAddressWatch struct {
+	mu sync.Mutex
+
+	// addrs maps the keys of the watched addresses to the height through
+	// which mined transactions involving them were already sent by a replay.
+	// Notifications for transactions mined at or below that height are not
+	// sent again.
+	addrs map[string]int64
+
+	// replaying is set while the transactions since a cursor are replayed.
+	// Notifications generated in the mean time are held in pending and sent
+	// once the replay completes so they are delivered in order.
+	//
+	// rewindHeight is the lowest height of the blocks disconnected during
+	// the replay.
+	replaying    bool
+	pending      []wsAddressNtfn
+	rewindHeight int64
+}
+
+// wsAddressNtfn is an addresstx notification held while a replay is running
+// along with the height of the block that contains the transaction, or -1
+// when it is unconfirmed.
+type wsAddressNtfn struct {
+	height int64
+	ntfn   *types.AddressTxNtfn
+}
+
+// newWSAddressWatch returns a new empty address watch.
+func newWSAddressWatch() *wsAddressWatch {
+	return &wsAddressWatch{
+		addrs: make(map[string]int64),
+	}
+}
+
+// watchAddrKey returns the key used to watch the passed address.  Addresses
+// that commit to a public key are watched by their pay-to-pubkey-hash
+// equivalent in order to match the way they are indexed.
+func watchAddrKey(addr stdaddr.Address) string {
+	if a, ok := addr.(stdaddr.AddressPubKeyHasher); ok {
+		return a.AddressPubKeyHash().String()
+	}
+	return addr.String()
+}
+
+// matches returns the sorted watched addresses from the passed set of address
+// keys that are not excluded due to having already been sent by a replay for
+// the given height.  A negative height indicates an unconfirmed transaction.
+//
+// This function MUST be called with the watch mutex held.
+func (w *wsAddressWatch) matches(keys map[string]struct{}, height int64) []string {
+	var matched []string
+	for key := range keys {
+		replayed, ok := w.addrs[key]
+		if !ok || (height >= 0 && height <= replayed) {
+			continue
+		}
+		matched = append(matched, key)
+	}
+	sort.Strings(matched)
+	return matched
+}
+
+// notify sends the passed notification to the client or holds it until the
+// running replay completes.
+//
+// This function MUST be called with the watch mutex held.
+func (w *wsAddressWatch) notify(wsc *wsClient, height int64, ntfn *types.AddressTxNtfn) {
+	if w.replaying {
+		w.pending = append(w.pending, wsAddressNtfn{height: height, ntfn: ntfn})
+		return
+	}
+	queueAddressTxNtfn(wsc, ntfn)
+}
+
+// rewind lowers the heights through which mined transactions were replayed
+// when a block at the passed height is disconnected so the transactions of any
+// block that replaces it are sent.
+//
+// This function MUST be called with the watch mutex held.
+func (w *wsAddressWatch) rewind(height int64) {
+	for key, replayed := range w.addrs {
+		if replayed >= height {
+			w.addrs[key] = height - 1
+		}
+	}
+	if w.replaying && height < w.rewindHeight {
+		w.rewindHeight = height
+	}
+}
+
+// queueAddressTxNtfn marshals and queues the passed addresstx notification for
+// the client.
+func queueAddressTxNtfn(wsc *wsClient, ntfn *types.AddressTxNtfn) {
+	marshalled, err := dcrjson.MarshalCmd("1.0", nil, ntfn)
+	if err != nil {
+		log.Errorf("Failed to marshal address tx notification: %v", err)
+		return
+	}
+	wsc.QueueNotification(marshalled)
+}
+
+// txWatchAddrKeys returns the keys of all addresses involved in the passed
+// transaction.  That is, the addresses paid by its outputs, including the
+// commitments of tickets, and the addresses of the previous outputs spent by
+// its inputs as provided by the previous scripter.
+func txWatchAddrKeys(tx *wire.MsgTx, prevScripts indexers.PrevScripter, params *chaincfg.Params) map[string]struct{} {
+	keys := make(map[string]struct{})
+	addKeys := func(version uint16, pkScript []byte) {
+		_, addrs := stdscript.ExtractAddrs(version, pkScript, params)
+		for _, addr := range addrs {
+			keys[watchAddrKey(addr)] = struct{}{}
+		}
+	}
+
+	// Inputs that do not reference a previous output, such as those of
+	// coinbases and stakebases, are not found by the previous scripter.
+	for _, txIn := range tx.TxIn {
+		version, pkScript, ok := prevScripts.PrevScript(&txIn.PreviousOutPoint)
+		if !ok {
+			continue
+		}
+		addKeys(version, pkScript)
+	}
+
+	isTicket := stake.IsSStx(tx)
+	for i, txOut := range tx.TxOut {
+		// Ticket commitments may contain relevant P2PKH or P2SH HASH160s.
+		if isTicket && i&1 == 1 {
+			addr, err := stake.AddrFromSStxPkScrCommitment(txOut.PkScript,
+				params)
+			if err == nil {
+				keys[watchAddrKey(addr)] = struct{}{}
+			}
+			continue
+		}
+		addKeys(txOut.Version, txOut.PkScript)
+	}
+
+	return keys
+}
+
+// unminedPrevScripts provides the scripts of the previous outputs spent by
+// transactions in the mempool by looking them up in the mempool and the set of
+// unspent transaction outputs.
+type unminedPrevScripts Server
+
+// PrevScript returns the script and script version associated with the
+// provided previous outpoint along with a bool that indicates whether or not
+// the requested entry exists.
+//
+// This is part of the indexers.PrevScripter interface.
+func (s *unminedPrevScripts) PrevScript(op *wire.OutPoint) (uint16, []byte, bool) {
+	if tx, err := s.cfg.TxMempooler.FetchTransaction(&op.Hash); err == nil {
+		txOuts := tx.MsgTx().TxOut
+		if op.Index >= uint32(len(txOuts)) {
+			return 0, nil, false
+		}
+		txOut := txOuts[op.Index]
+		return txOut.Version, txOut.PkScript, true
+	}
+
+	entry, err := s.cfg.Chain.FetchUtxoEntry(*op)
+	if err != nil || entry == nil || entry.IsSpent() {
+		return 0, nil, false
+	}
+	return entry.ScriptVersion(), entry.PkScript(), true
+}
+
 // Notification types
-type notificationBlockConnected dcrutil.Block
+type notificationBlockConnected struct {
+	block       *dcrutil.Block
+	prevScripts indexers.PrevScripter
+}
 type notificationBlockDisconnected dcrutil.Block
 type notificationWork mining.TemplateNtfn
 type notificationTSpend dcrutil.Tx
@@ -484,7 +671,7 @@ out:
 			}
 			switch n := n.(type) {
 			case *notificationBlockConnected:
-				block := (*dcrutil.Block)(n)
+				m.notifyAddressTxsMined(clients, n.block, n.prevScripts)
 
 				// Skip iterating through all txs if no tx
 				// notification requests exist.
@@ -492,11 +679,12 @@ out:
 					continue
 				}
 
-				m.notifyBlockConnected(blockNotifications, block)
+				m.notifyBlockConnected(blockNotifications, n.block)
 
 			case *notificationBlockDisconnected:
-				m.notifyBlockDisconnected(blockNotifications,
-					(*dcrutil.Block)(n))
+				block := (*dcrutil.Block)(n)
+				m.rewindAddressWatches(clients, block.Height())
+				m.notifyBlockDisconnected(blockNotifications, block)
 
 			case *notificationWork:
 				m.notifyWork(workNotifications, (*mining.TemplateNtfn)(n))
@@ -525,6 +713,7 @@ out:
 					m.notifyForNewTx(txNotifications, n.tx)
 				}
 				m.notifyRelevantTxAccepted(n.tx, clients)
+				m.notifyAddressTxAccepted(clients, n.tx)
 
 			case *notificationRegisterBlocks:
 				wsc := (*wsClient)(n)
@@ -1249,6 +1438,106 @@ func (m *wsNotificationManager) notifyRelevantTxAccepted(tx *dcrutil.Tx,
 	}
 }
 
+// addressWatches returns the address watches of the passed clients that have
+// registered for address notifications keyed by the client.
+func addressWatches(clients map[chan struct{}]*wsClient) map[*wsClient]*wsAddressWatch {
+	var watches map[*wsClient]*wsAddressWatch
+	for _, c := range clients {
+		c.Lock()
+		w := c.addrWatch
+		c.Unlock()
+		if w == nil {
+			continue
+		}
+		if watches == nil {
+			watches = make(map[*wsClient]*wsAddressWatch)
+		}
+		watches[c] = w
+	}
+	return watches
+}
+
+// notifyAddressTxsMined notifies websocket clients that have registered for
+// address notifications of the transactions in a block connected to the main
+// chain that involve their watched addresses.
+func (m *wsNotificationManager) notifyAddressTxsMined(clients map[chan struct{}]*wsClient,
+	block *dcrutil.Block, prevScripts indexers.PrevScripter) {
+
+	watches := addressWatches(clients)
+	if len(watches) == 0 {
+		return
+	}
+
+	params := m.server.cfg.ChainParams
+	blockHash := block.Hash().String()
+	height := block.Height()
+	notifyTxns := func(txns []*dcrutil.Tx, tree int8) {
+		for i, tx := range txns {
+			keys := txWatchAddrKeys(tx.MsgTx(), prevScripts, params)
+			if len(keys) == 0 {
+				continue
+			}
+			cursor := &types.AddressCursor{
+				BlockHash:   blockHash,
+				BlockHeight: height,
+				Tree:        tree,
+				Index:       uint32(i),
+			}
+			var txHex string
+			for c, w := range watches {
+				w.mu.Lock()
+				if matched := w.matches(keys, height); len(matched) != 0 {
+					if txHex == "" {
+						txHex = txHexString(tx.MsgTx())
+					}
+					ntfn := types.NewAddressTxNtfn(matched, txHex, cursor)
+					w.notify(c, height, ntfn)
+				}
+				w.mu.Unlock()
+			}
+		}
+	}
+	notifyTxns(block.Transactions(), wire.TxTreeRegular)
+	notifyTxns(block.STransactions(), wire.TxTreeStake)
+}
+
+// notifyAddressTxAccepted notifies websocket clients that have registered for
+// address notifications of a transaction accepted by the mempool that involves
+// their watched addresses.
+func (m *wsNotificationManager) notifyAddressTxAccepted(clients map[chan struct{}]*wsClient, tx *dcrutil.Tx) {
+	watches := addressWatches(clients)
+	if len(watches) == 0 {
+		return
+	}
+
+	prevScripts := (*unminedPrevScripts)(m.server)
+	keys := txWatchAddrKeys(tx.MsgTx(), prevScripts, m.server.cfg.ChainParams)
+	if len(keys) == 0 {
+		return
+	}
+	var txHex string
+	for c, w := range watches {
+		w.mu.Lock()
+		if matched := w.matches(keys, -1); len(matched) != 0 {
+			if txHex == "" {
+				txHex = txHexString(tx.MsgTx())
+			}
+			w.notify(c, -1, types.NewAddressTxNtfn(matched, txHex, nil))
+		}
+		w.mu.Unlock()
+	}
+}
+
+// rewindAddressWatches updates the address watches of websocket clients when
+// a block at the passed height is disconnected from the main chain.
+func (m *wsNotificationManager) rewindAddressWatches(clients map[chan struct{}]*wsClient, height int64) {
+	for _, w := range addressWatches(clients) {
+		w.mu.Lock()
+		w.rewind(height)
+		w.mu.Unlock()
+	}
+}
+
 // AddClient adds the passed websocket client to the notification manager.
 func (m *wsNotificationManager) AddClient(wsc *wsClient) {
 	m.queueNotification <- (*notificationRegisterClient)(wsc)
@@ -1344,6 +1633,9 @@ type wsClient struct {
 
 	filterData *wsClientFilter
 
+	// addrWatch houses the addresses registered for with notifyaddresses.
+	addrWatch *wsAddressWatch
+
 	// Networking infrastructure.
 	serviceRequestSem semaphore
 	ntfnChan          chan []byte
@@ -2277,6 +2569,426 @@ func handleStopNotifyNewTransactions(wsc *wsClient, icmd interface{}) (interface
 	return nil, nil
 }
 
+// decodeWatchAddrs decodes the passed addresses and returns them keyed by the
+// key used to watch them.  Addresses that commit to a public key are converted
+// to their pay-to-pubkey-hash equivalent.
+func decodeWatchAddrs(addresses []string, params stdaddr.AddressParams) (map[string]stdaddr.Address, error) {
+	addrs := make(map[string]stdaddr.Address, len(addresses))
+	for _, a := range addresses {
+		addr, err := stdaddr.DecodeAddress(a, params)
+		if err != nil {
+			return nil, rpcAddressKeyError("Could not decode address: %v",
+				err)
+		}
+		if pkHasher, ok := addr.(stdaddr.AddressPubKeyHasher); ok {
+			addr = pkHasher.AddressPubKeyHash()
+		}
+		addrs[addr.String()] = addr
+	}
+	return addrs, nil
+}
+
+// addressCursorStart returns the height of the first block to replay for the
+// passed cursor along with whether or not the transactions in that block at or
+// before the position of the cursor have already been sent.  Cursors that
+// refer to blocks which are no longer in the main chain resume from the block
+// after the fork point.
+func (s *Server) addressCursorStart(cursor *types.AddressCursor) (int64, bool, error) {
+	hash, err := chainhash.NewHashFromStr(cursor.BlockHash)
+	if err != nil {
+		return 0, false, rpcDecodeHexError(cursor.BlockHash)
+	}
+
+	chain := s.cfg.Chain
+	resume := true
+	for !chain.MainChainHasBlock(hash) {
+		header, err := chain.HeaderByHash(hash)
+		if err != nil {
+			return 0, false, rpcBlockNotFoundError(*hash)
+		}
+		hash = &header.PrevBlock
+		resume = false
+	}
+	height, err := chain.BlockHeightByHash(hash)
+	if err != nil {
+		context := "Failed to obtain block height"
+		return 0, false, rpcInternalError(err.Error(), context)
+	}
+	if !resume {
+		height++
+	}
+	return height, resume, nil
+}
+
+// addressReplayTx describes a mined transaction found by a replay along with
+// the keys of the replayed addresses it involves.
+type addressReplayTx struct {
+	keys   map[string]struct{}
+	height int64
+	offset uint32
+	tree   int8
+	index  uint32
+	tx     *wire.MsgTx
+}
+
+// replayAddressTxs sends addresstx notifications to the websocket client for
+// all transactions mined in the main chain from the passed start height that
+// involve the provided addresses, followed by all unconfirmed transactions
+// that involve them.  Transactions in the block at the start height at or
+// before the position of the passed cursor are skipped when it is not nil.
+//
+// It returns the height of the final block that was replayed.
+func (s *Server) replayAddressTxs(wsc *wsClient, addrIndex AddrIndexer,
+	addrs map[string]stdaddr.Address, startHeight int64,
+	skipTo *types.AddressCursor) (int64, error) {
+
+	// Wait for the address index to reach the main chain tip as of the time
+	// the addresses were registered.  This ensures every transaction mined
+	// prior to the point live notifications began is replayed.
+	chain := s.cfg.Chain
+	best := chain.BestSnapshot()
+	var tipHeight int64
+	for {
+		tHeight, tHash, err := addrIndex.Tip()
+		if err != nil {
+			return 0, rpcInternalError(err.Error(), "Tip")
+		}
+		if tHeight >= best.Height && chain.MainChainHasBlock(tHash) {
+			tipHeight = tHeight
+			break
+		}
+
+		// Return an out-of-sync error if index is lagging a maximum reorg
+		// depth (6) blocks or more from the chain tip.
+		if best.Height > tHeight+5 {
+			msg := fmt.Sprintf("%s: index not synced", addrIndex.Name())
+			return 0, rpcInternalError(msg, "Sync")
+		}
+		select {
+		case <-time.After(syncWait):
+			msg := fmt.Sprintf("%s: index not synced", addrIndex.Name())
+			return 0, rpcInternalError(msg, "Sync")
+		case <-addrIndex.WaitForSync():
+		}
+	}
+
+	// Load the entries for all mined transactions since the start height that
+	// involve the addresses grouped by block.  The entries are loaded from the
+	// most recent one backwards since there is no way to seek to a height.
+	//
+	// Transactions mined after the tip the index reached above are skipped
+	// since they are sent by live notifications.
+	const pageSize = 1000
+	blocks := make(map[chainhash.Hash]map[uint32]*addressReplayTx)
+	heights := make(map[chainhash.Hash]int64)
+	err := s.cfg.DB.View(func(dbTx database.Tx) error {
+		for key, addr := range addrs {
+			var numToSkip uint32
+			for {
+				entries, _, err := addrIndex.EntriesForAddress(dbTx, addr,
+					numToSkip, pageSize, true)
+				if err != nil {
+					return err
+				}
+				done := len(entries) < pageSize
+				for i := range entries {
+					region := &entries[i].BlockRegion
+					height, ok := heights[*region.Hash]
+					if !ok {
+						height, err = chain.BlockHeightByHash(region.Hash)
+						if err != nil {
+							// Not in the main chain.
+							height = -1
+						}
+						heights[*region.Hash] = height
+					}
+					if height < 0 || height > tipHeight {
+						continue
+					}
+					if height < startHeight {
+						done = true
+						break
+					}
+
+					txns := blocks[*region.Hash]
+					if txns == nil {
+						txns = make(map[uint32]*addressReplayTx)
+						blocks[*region.Hash] = txns
+					}
+					tx := txns[region.Offset]
+					if tx == nil {
+						tx = &addressReplayTx{
+							keys:   make(map[string]struct{}),
+							height: height,
+							offset: region.Offset,
+							index:  entries[i].BlockIndex,
+						}
+						txns[region.Offset] = tx
+					}
+					tx.keys[key] = struct{}{}
+				}
+				if done {
+					break
+				}
+				numToSkip += uint32(len(entries))
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		context := "Failed to load address index entries"
+		return 0, rpcInternalError(err.Error(), context)
+	}
+
+	// Determine the tree of each transaction from its location in the block
+	// that contains it.
+	var replayTxns []*addressReplayTx
+	for hash, txns := range blocks {
+		block, err := chain.BlockByHash(&hash)
+		if err != nil {
+			return 0, rpcBlockNotFoundError(hash)
+		}
+		txLocs, stxLocs, err := block.TxLoc()
+		if err != nil {
+			context := "Failed to locate block transactions"
+			return 0, rpcInternalError(err.Error(), context)
+		}
+		msgBlock := block.MsgBlock()
+		for _, tx := range txns {
+			switch {
+			case tx.index < uint32(len(txLocs)) &&
+				txLocs[tx.index].TxStart == int(tx.offset):
+				tx.tree = wire.TxTreeRegular
+				tx.tx = msgBlock.Transactions[tx.index]
+
+			case tx.index < uint32(len(stxLocs)) &&
+				stxLocs[tx.index].TxStart == int(tx.offset):
+				tx.tree = wire.TxTreeStake
+				tx.tx = msgBlock.STransactions[tx.index]
+
+			default:
+				log.Errorf("Address index entry at offset %d of block %v "+
+					"does not match any transaction", tx.offset, hash)
+				continue
+			}
+
+			// Skip transactions the client already received.
+			if skipTo != nil && tx.height == startHeight &&
+				(tx.tree < skipTo.Tree || (tx.tree == skipTo.Tree &&
+					tx.index <= skipTo.Index)) {
+
+				continue
+			}
+			replayTxns = append(replayTxns, tx)
+		}
+	}
+
+	// Send the mined transactions in the order they appear in the chain.
+	sort.Slice(replayTxns, func(i, j int) bool {
+		a, b := replayTxns[i], replayTxns[j]
+		if a.height != b.height {
+			return a.height < b.height
+		}
+		if a.tree != b.tree {
+			return a.tree < b.tree
+		}
+		return a.index < b.index
+	})
+	for _, tx := range replayTxns {
+		blockHash, err := chain.BlockHashByHeight(tx.height)
+		if err != nil {
+			context := "Failed to obtain block hash"
+			return 0, rpcInternalError(err.Error(), context)
+		}
+		keys := make([]string, 0, len(tx.keys))
+		for key := range tx.keys {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		cursor := &types.AddressCursor{
+			BlockHash:   blockHash.String(),
+			BlockHeight: tx.height,
+			Tree:        tx.tree,
+			Index:       tx.index,
+		}
+		ntfn := types.NewAddressTxNtfn(keys, txHexString(tx.tx), cursor)
+		queueAddressTxNtfn(wsc, ntfn)
+	}
+
+	// Send all unconfirmed transactions that involve the addresses.
+	keys := make([]string, 0, len(addrs))
+	for key := range addrs {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	var unmined []*types.AddressTxNtfn
+	unminedIdx := make(map[chainhash.Hash]int)
+	for _, key := range keys {
+		for _, tx := range addrIndex.UnconfirmedTxnsForAddress(addrs[key]) {
+			if idx, ok := unminedIdx[*tx.Hash()]; ok {
+				unmined[idx].Addresses = append(unmined[idx].Addresses, key)
+				continue
+			}
+			unminedIdx[*tx.Hash()] = len(unmined)
+			txHex := txHexString(tx.MsgTx())
+			ntfn := types.NewAddressTxNtfn([]string{key}, txHex, nil)
+			unmined = append(unmined, ntfn)
+		}
+	}
+	for _, ntfn := range unmined {
+		queueAddressTxNtfn(wsc, ntfn)
+	}
+
+	return tipHeight, nil
+}
+
+// handleNotifyAddresses implements the notifyaddresses command extension for
+// websocket connections.
+func handleNotifyAddresses(wsc *wsClient, icmd interface{}) (interface{}, error) {
+	cmd, ok := icmd.(*types.NotifyAddressesCmd)
+	if !ok {
+		return nil, dcrjson.ErrRPCInternal
+	}
+
+	// Replaying the transactions since a cursor requires the address index.
+	s := wsc.rpcServer
+	var addrIndex AddrIndexer
+	if cmd.Cursor != nil {
+		addrIndex = s.addrIndexer()
+		if addrIndex == nil {
+			return nil, rpcInternalError("Address index must be "+
+				"enabled (--addrindex)", "Configuration")
+		}
+	}
+
+	addrs, err := decodeWatchAddrs(cmd.Addresses, s.cfg.ChainParams)
+	if err != nil {
+		return nil, err
+	}
+	var startHeight int64
+	var skipTo *types.AddressCursor
+	if cmd.Cursor != nil {
+		var resume bool
+		startHeight, resume, err = s.addressCursorStart(cmd.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		if resume {
+			skipTo = cmd.Cursor
+		}
+	}
+
+	wsc.Lock()
+	w := wsc.addrWatch
+	if w == nil {
+		w = newWSAddressWatch()
+		wsc.addrWatch = w
+	}
+	wsc.Unlock()
+
+	// Start watching the addresses.  Any notifications generated from this
+	// point on are held while the transactions since the cursor are replayed.
+	w.mu.Lock()
+	if cmd.Cursor != nil && w.replaying {
+		w.mu.Unlock()
+		return nil, rpcMiscError("Address notifications are already being " +
+			"replayed")
+	}
+	var added []string
+	for key := range addrs {
+		if _, ok := w.addrs[key]; !ok {
+			w.addrs[key] = -1
+			added = append(added, key)
+		}
+	}
+	if cmd.Cursor == nil {
+		w.mu.Unlock()
+		return nil, nil
+	}
+	w.replaying = true
+	w.rewindHeight = math.MaxInt64
+	w.mu.Unlock()
+
+	tipHeight, err := s.replayAddressTxs(wsc, addrIndex, addrs, startHeight,
+		skipTo)
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if err != nil {
+		for _, key := range added {
+			delete(w.addrs, key)
+		}
+	} else {
+		// Mined transactions through the tip were sent by the replay unless
+		// blocks were disconnected in the mean time.
+		replayed := tipHeight
+		if w.rewindHeight <= replayed {
+			replayed = w.rewindHeight - 1
+		}
+		for key := range addrs {
+			if cur, ok := w.addrs[key]; ok && replayed > cur {
+				w.addrs[key] = replayed
+			}
+		}
+	}
+
+	// Send the notifications held during the replay that were not already
+	// sent by it.
+	pending := w.pending
+	w.pending = nil
+	w.replaying = false
+	for _, p := range pending {
+		keys := make(map[string]struct{}, len(p.ntfn.Addresses))
+		for _, key := range p.ntfn.Addresses {
+			keys[key] = struct{}{}
+		}
+		matched := w.matches(keys, p.height)
+		if len(matched) == 0 {
+			continue
+		}
+		p.ntfn.Addresses = matched
+		queueAddressTxNtfn(wsc, p.ntfn)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return nil, nil
+}
+
+// handleStopNotifyAddresses implements the stopnotifyaddresses command
+// extension for websocket connections.  All watched addresses are removed when
+// none are provided.
+func handleStopNotifyAddresses(wsc *wsClient, icmd interface{}) (interface{}, error) {
+	cmd, ok := icmd.(*types.StopNotifyAddressesCmd)
+	if !ok {
+		return nil, dcrjson.ErrRPCInternal
+	}
+
+	addrs, err := decodeWatchAddrs(cmd.Addresses, wsc.rpcServer.cfg.ChainParams)
+	if err != nil {
+		return nil, err
+	}
+
+	wsc.Lock()
+	w := wsc.addrWatch
+	wsc.Unlock()
+	if w == nil {
+		return nil, nil
+	}
+
+	w.mu.Lock()
+	if len(addrs) == 0 {
+		w.addrs = make(map[string]int64)
+	}
+	for key := range addrs {
+		delete(w.addrs, key)
+	}
+	w.mu.Unlock()
+
+	return nil, nil
+}
+
 // rescanBlock rescans a block for any relevant transactions for the passed
 // lookup keys.  Any discovered transactions are returned hex encoded as a
 // string slice.
@@ -0,0 +1,140 @@
+// Copyright (c) 2023 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package rpcserver
+
+import (
+	"encoding/hex"
+	"reflect"
+	"testing"
+
+	"github.com/EXCCoin/exccd/chaincfg/chainhash"
+	"github.com/EXCCoin/exccd/chaincfg/v3"
+	"github.com/EXCCoin/exccd/txscript/v4/stdaddr"
+	"github.com/EXCCoin/exccd/wire"
+)
+
+// testPrevScripter provides a previous scripter backed by a map for use in
+// tests.
+type testPrevScripter map[wire.OutPoint]*wire.TxOut
+
+// PrevScript returns the script and script version associated with the
+// provided previous outpoint along with a bool that indicates whether or not
+// the requested entry exists.
+func (p testPrevScripter) PrevScript(op *wire.OutPoint) (uint16, []byte, bool) {
+	txOut, ok := p[*op]
+	if !ok {
+		return 0, nil, false
+	}
+	return txOut.Version, txOut.PkScript, true
+}
+
+// TestTxWatchAddrKeys ensures the addresses involved in a transaction are
+// determined from both its outputs and the previous outputs it spends.
+func TestTxWatchAddrKeys(t *testing.T) {
+	t.Parallel()
+
+	params := chaincfg.MainNetParams()
+	pkHashAddr := func(b byte) stdaddr.Address {
+		var hash [20]byte
+		hash[0] = b
+		addr, err := stdaddr.NewAddressPubKeyHashEcdsaSecp256k1V0(hash[:],
+			params)
+		if err != nil {
+			t.Fatalf("unexpected error creating address: %v", err)
+		}
+		return addr
+	}
+	pubKey, _ := hex.DecodeString("0279be667ef9dcbbac55a06295ce870b07029bfc" +
+		"db2dce28d959f2815b16f81798")
+	pubKeyAddr, err := stdaddr.NewAddressPubKeyEcdsaSecp256k1V0Raw(pubKey,
+		params)
+	if err != nil {
+		t.Fatalf("unexpected error creating address: %v", err)
+	}
+	txOut := func(addr stdaddr.Address) *wire.TxOut {
+		version, script := addr.PaymentScript()
+		return &wire.TxOut{Value: 1, Version: version, PkScript: script}
+	}
+
+	spentAddr, paidAddr, unrelatedAddr := pkHashAddr(1), pkHashAddr(2),
+		pkHashAddr(3)
+	spent := wire.OutPoint{Hash: chainhash.Hash{0x01}}
+	missing := wire.OutPoint{Hash: chainhash.Hash{0x02}}
+	prevScripts := testPrevScripter{
+		spent: txOut(spentAddr),
+		wire.OutPoint{Hash: chainhash.Hash{0x03}}: txOut(unrelatedAddr),
+	}
+
+	tx := wire.NewMsgTx()
+	tx.AddTxIn(wire.NewTxIn(&spent, 1, nil))
+	tx.AddTxIn(wire.NewTxIn(&missing, 1, nil))
+	tx.AddTxOut(txOut(paidAddr))
+	tx.AddTxOut(txOut(pubKeyAddr))
+	tx.AddTxOut(wire.NewTxOut(0, []byte{0x6a, 0x01, 0x01}))
+
+	// Pay-to-pubkey outputs are reported as their pay-to-pubkey-hash
+	// equivalent.
+	pubKeyHashAddr := pubKeyAddr.AddressPubKeyHash()
+	want := map[string]struct{}{
+		spentAddr.String():      {},
+		paidAddr.String():       {},
+		pubKeyHashAddr.String(): {},
+	}
+	got := txWatchAddrKeys(tx, prevScripts, params)
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("mismatched address keys - got %v, want %v", got, want)
+	}
+}
+
+// TestAddressWatch ensures address watches only match the watched addresses
+// that have not already been sent by a replay and that disconnected blocks
+// rewind the replayed heights.
+func TestAddressWatch(t *testing.T) {
+	t.Parallel()
+
+	w := newWSAddressWatch()
+	w.addrs["a"] = -1
+	w.addrs["b"] = 10
+	keys := map[string]struct{}{"a": {}, "b": {}, "c": {}}
+
+	tests := []struct {
+		name   string
+		rewind int64
+		height int64
+		want   []string
+	}{{
+		name:   "unconfirmed matches all watched",
+		height: -1,
+		want:   []string{"a", "b"},
+	}, {
+		name:   "mined at replayed height",
+		height: 10,
+		want:   []string{"a"},
+	}, {
+		name:   "mined after replayed height",
+		height: 11,
+		want:   []string{"a", "b"},
+	}, {
+		name:   "mined at replayed height after rewind",
+		rewind: 10,
+		height: 10,
+		want:   []string{"a", "b"},
+	}, {
+		name:   "mined before rewound height",
+		height: 9,
+		want:   []string{"a"},
+	}}
+
+	for _, test := range tests {
+		if test.rewind != 0 {
+			w.rewind(test.rewind)
+		}
+		got := w.matches(keys, test.height)
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%q: mismatched matches - got %v, want %v", test.name,
+				got, test.want)
+		}
+	}
+}
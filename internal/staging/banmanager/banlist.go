@@ -0,0 +1,269 @@
+// Copyright (c) 2023 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package banmanager
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"sort"
+	"sync"
+	"time"
+)
+
+// banListVersion is the current version of the serialized ban list.
+const banListVersion = 1
+
+// ErrNotBanned indicates an attempt to remove a ban for a subnet that is not
+// banned.
+var ErrNotBanned = errors.New("subnet is not banned")
+
+// BanEntry describes a banned subnet along with when the ban was created and
+// when it expires.
+type BanEntry struct {
+	Subnet  *net.IPNet
+	Created time.Time
+	Expires time.Time
+}
+
+// serializedBanEntry is the form of a ban entry that is persisted to disk.
+type serializedBanEntry struct {
+	Subnet  string `json:"subnet"`
+	Created int64  `json:"created"`
+	Expires int64  `json:"expires"`
+}
+
+// serializedBanList is the form of the ban list that is persisted to disk.
+type serializedBanList struct {
+	Version int                  `json:"version"`
+	Bans    []serializedBanEntry `json:"bans"`
+}
+
+// BanList houses a set of banned subnets along with their expiration times and
+// optionally persists them to disk so they survive restarts.
+//
+// All methods are safe for concurrent access.
+type BanList struct {
+	mtx  sync.Mutex
+	path string
+	bans map[string]*BanEntry
+}
+
+// NewBanList returns a new empty ban list that is persisted to the provided
+// file path.  An empty path results in a ban list that is only kept in memory.
+//
+// Load must be called to populate the ban list with any bans that were
+// previously persisted.
+func NewBanList(path string) *BanList {
+	return &BanList{
+		path: path,
+		bans: make(map[string]*BanEntry),
+	}
+}
+
+// ParseSubnet parses the provided string as either a subnet in CIDR notation or
+// a single IP address.  A single IP address is treated as a subnet that only
+// contains that address.
+func ParseSubnet(s string) (*net.IPNet, error) {
+	if ip := net.ParseIP(s); ip != nil {
+		if ip4 := ip.To4(); ip4 != nil {
+			return &net.IPNet{IP: ip4, Mask: net.CIDRMask(32, 32)}, nil
+		}
+		return &net.IPNet{IP: ip, Mask: net.CIDRMask(128, 128)}, nil
+	}
+
+	_, subnet, err := net.ParseCIDR(s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid IP address or subnet %q", s)
+	}
+	return subnet, nil
+}
+
+// pruneExpired removes all bans that have expired as of the provided time and
+// returns whether or not any were removed.
+//
+// This function MUST be called with the ban list mutex held (for writes).
+func (bl *BanList) pruneExpired(now time.Time) bool {
+	var pruned bool
+	for key, entry := range bl.bans {
+		if !now.Before(entry.Expires) {
+			log.Infof("Ban for %s has expired", key)
+			delete(bl.bans, key)
+			pruned = true
+		}
+	}
+	return pruned
+}
+
+// save writes the ban list to disk by first writing to a temporary file and
+// then moving it into place.  It does nothing when the ban list is not
+// persisted.
+//
+// This function MUST be called with the ban list mutex held (for reads).
+func (bl *BanList) save() error {
+	if bl.path == "" {
+		return nil
+	}
+
+	sbl := serializedBanList{
+		Version: banListVersion,
+		Bans:    make([]serializedBanEntry, 0, len(bl.bans)),
+	}
+	for key, entry := range bl.bans {
+		sbl.Bans = append(sbl.Bans, serializedBanEntry{
+			Subnet:  key,
+			Created: entry.Created.Unix(),
+			Expires: entry.Expires.Unix(),
+		})
+	}
+	sort.Slice(sbl.Bans, func(i, j int) bool {
+		return sbl.Bans[i].Subnet < sbl.Bans[j].Subnet
+	})
+
+	tmpFile := bl.path + ".new"
+	w, err := os.Create(tmpFile)
+	if err != nil {
+		return fmt.Errorf("unable to create ban list file %s: %w", tmpFile,
+			err)
+	}
+	if err := json.NewEncoder(w).Encode(&sbl); err != nil {
+		w.Close()
+		return fmt.Errorf("unable to encode ban list file %s: %w", tmpFile,
+			err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("unable to close ban list file %s: %w", tmpFile,
+			err)
+	}
+	if err := os.Rename(tmpFile, bl.path); err != nil {
+		return fmt.Errorf("unable to move ban list file %s into place: %w",
+			tmpFile, err)
+	}
+	return nil
+}
+
+// Load populates the ban list with the bans previously persisted to disk while
+// discarding any that have since expired.  A missing file is not an error.
+func (bl *BanList) Load() error {
+	if bl.path == "" {
+		return nil
+	}
+
+	f, err := os.Open(bl.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("unable to open ban list file %s: %w", bl.path, err)
+	}
+	defer f.Close()
+
+	var sbl serializedBanList
+	if err := json.NewDecoder(f).Decode(&sbl); err != nil {
+		return fmt.Errorf("unable to decode ban list file %s: %w", bl.path,
+			err)
+	}
+	if sbl.Version != banListVersion {
+		return fmt.Errorf("unknown version %d in ban list file %s",
+			sbl.Version, bl.path)
+	}
+
+	bans := make(map[string]*BanEntry, len(sbl.Bans))
+	for _, sbe := range sbl.Bans {
+		subnet, err := ParseSubnet(sbe.Subnet)
+		if err != nil {
+			return fmt.Errorf("malformed ban list file %s: %w", bl.path, err)
+		}
+		bans[subnet.String()] = &BanEntry{
+			Subnet:  subnet,
+			Created: time.Unix(sbe.Created, 0),
+			Expires: time.Unix(sbe.Expires, 0),
+		}
+	}
+
+	bl.mtx.Lock()
+	defer bl.mtx.Unlock()
+	bl.bans = bans
+	bl.pruneExpired(time.Now())
+	log.Infof("Loaded %d bans from file '%s'", len(bl.bans), bl.path)
+	return nil
+}
+
+// Ban bans the provided subnet until the provided time.  Banning a subnet that
+// is already banned replaces the existing ban.
+func (bl *BanList) Ban(subnet *net.IPNet, until time.Time) error {
+	key := subnet.String()
+
+	bl.mtx.Lock()
+	defer bl.mtx.Unlock()
+	bl.bans[key] = &BanEntry{
+		Subnet:  subnet,
+		Created: time.Now(),
+		Expires: until,
+	}
+	return bl.save()
+}
+
+// Unban removes the ban for the provided subnet.  ErrNotBanned is returned
+// when the subnet is not banned.
+func (bl *BanList) Unban(subnet *net.IPNet) error {
+	key := subnet.String()
+
+	bl.mtx.Lock()
+	defer bl.mtx.Unlock()
+	if _, ok := bl.bans[key]; !ok {
+		return ErrNotBanned
+	}
+	delete(bl.bans, key)
+	return bl.save()
+}
+
+// IsBanned returns whether or not the provided IP is contained in a banned
+// subnet along with the latest time a ban that applies to it expires.
+func (bl *BanList) IsBanned(ip net.IP) (time.Time, bool) {
+	now := time.Now()
+
+	bl.mtx.Lock()
+	defer bl.mtx.Unlock()
+	var until time.Time
+	var banned bool
+	for _, entry := range bl.bans {
+		if now.Before(entry.Expires) && entry.Subnet.Contains(ip) {
+			if entry.Expires.After(until) {
+				until = entry.Expires
+			}
+			banned = true
+		}
+	}
+	return until, banned
+}
+
+// Bans returns all bans that have not yet expired sorted by subnet.
+func (bl *BanList) Bans() ([]BanEntry, error) {
+	bl.mtx.Lock()
+	defer bl.mtx.Unlock()
+	var err error
+	if bl.pruneExpired(time.Now()) {
+		err = bl.save()
+	}
+	bans := make([]BanEntry, 0, len(bl.bans))
+	for _, entry := range bl.bans {
+		bans = append(bans, *entry)
+	}
+	sort.Slice(bans, func(i, j int) bool {
+		return bans[i].Subnet.String() < bans[j].Subnet.String()
+	})
+	return bans, err
+}
+
+// Clear removes all bans.
+func (bl *BanList) Clear() error {
+	bl.mtx.Lock()
+	defer bl.mtx.Unlock()
+	bl.bans = make(map[string]*BanEntry)
+	return bl.save()
+}
@@ -0,0 +1,166 @@
+// Copyright (c) 2023 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package banmanager
+
+import (
+	"errors"
+	"net"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// TestParseSubnet ensures subnets and single IP addresses are parsed into the
+// expected subnets.
+func TestParseSubnet(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		subnet  string
+		want    string
+		wantErr bool
+	}{{
+		name:   "ipv4 address",
+		subnet: "10.0.0.1",
+		want:   "10.0.0.1/32",
+	}, {
+		name:   "ipv6 address",
+		subnet: "2001:db8::1",
+		want:   "2001:db8::1/128",
+	}, {
+		name:   "ipv4 subnet with host bits",
+		subnet: "10.0.0.1/24",
+		want:   "10.0.0.0/24",
+	}, {
+		name:   "ipv6 subnet",
+		subnet: "2001:db8::/32",
+		want:   "2001:db8::/32",
+	}, {
+		name:    "hostname",
+		subnet:  "example.com",
+		wantErr: true,
+	}, {
+		name:    "invalid prefix length",
+		subnet:  "10.0.0.1/33",
+		wantErr: true,
+	}}
+
+	for _, test := range tests {
+		subnet, err := ParseSubnet(test.subnet)
+		if test.wantErr {
+			if err == nil {
+				t.Errorf("%q: did not receive expected error", test.name)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%q: unexpected error: %v", test.name, err)
+			continue
+		}
+		if subnet.String() != test.want {
+			t.Errorf("%q: mismatched subnet - got %s, want %s", test.name,
+				subnet, test.want)
+		}
+	}
+}
+
+// TestBanList ensures bans apply to every address in the banned subnets, expire
+// as expected, and persist across ban list instances.
+func TestBanList(t *testing.T) {
+	t.Parallel()
+
+	mustParseSubnet := func(s string) *net.IPNet {
+		subnet, err := ParseSubnet(s)
+		if err != nil {
+			t.Fatalf("unexpected error parsing subnet %q: %v", s, err)
+		}
+		return subnet
+	}
+	assertBanned := func(bl *BanList, ip string, want bool) {
+		t.Helper()
+		if _, banned := bl.IsBanned(net.ParseIP(ip)); banned != want {
+			t.Fatalf("mismatched ban status for %s - got %v, want %v", ip,
+				banned, want)
+		}
+	}
+
+	path := filepath.Join(t.TempDir(), "banlist.json")
+	bl := NewBanList(path)
+	if err := bl.Load(); err != nil {
+		t.Fatalf("unexpected error loading missing ban list: %v", err)
+	}
+
+	// Ban a subnet along with a single address and an already expired
+	// address.
+	now := time.Now()
+	until := now.Add(time.Hour)
+	if err := bl.Ban(mustParseSubnet("10.1.0.0/16"), until); err != nil {
+		t.Fatalf("unexpected error banning subnet: %v", err)
+	}
+	if err := bl.Ban(mustParseSubnet("2001:db8::1"), until); err != nil {
+		t.Fatalf("unexpected error banning address: %v", err)
+	}
+	if err := bl.Ban(mustParseSubnet("10.2.0.1"), now); err != nil {
+		t.Fatalf("unexpected error banning address: %v", err)
+	}
+	assertBanned(bl, "10.1.2.3", true)
+	assertBanned(bl, "::ffff:10.1.2.3", true)
+	assertBanned(bl, "10.3.0.1", false)
+	assertBanned(bl, "2001:db8::1", true)
+	assertBanned(bl, "2001:db8::2", false)
+	assertBanned(bl, "10.2.0.1", false)
+
+	// Ensure the expired ban is not reported.
+	bans, err := bl.Bans()
+	if err != nil {
+		t.Fatalf("unexpected error listing bans: %v", err)
+	}
+	if len(bans) != 2 {
+		t.Fatalf("mismatched number of bans - got %d, want 2", len(bans))
+	}
+	if got := bans[0].Subnet.String(); got != "10.1.0.0/16" {
+		t.Fatalf("mismatched first ban - got %s, want 10.1.0.0/16", got)
+	}
+
+	// Ensure the bans are persisted.
+	bl = NewBanList(path)
+	if err := bl.Load(); err != nil {
+		t.Fatalf("unexpected error loading ban list: %v", err)
+	}
+	assertBanned(bl, "10.1.2.3", true)
+	assertBanned(bl, "2001:db8::1", true)
+	bans, err = bl.Bans()
+	if err != nil {
+		t.Fatalf("unexpected error listing bans: %v", err)
+	}
+	if len(bans) != 2 {
+		t.Fatalf("mismatched number of bans - got %d, want 2", len(bans))
+	}
+	if !bans[0].Expires.Equal(time.Unix(until.Unix(), 0)) {
+		t.Fatalf("mismatched expiration - got %v, want %v",
+			bans[0].Expires, until)
+	}
+
+	// Ensure removing a ban lifts it and removing it again fails.
+	if err := bl.Unban(mustParseSubnet("10.1.0.0/16")); err != nil {
+		t.Fatalf("unexpected error removing ban: %v", err)
+	}
+	assertBanned(bl, "10.1.2.3", false)
+	err = bl.Unban(mustParseSubnet("10.1.0.0/16"))
+	if !errors.Is(err, ErrNotBanned) {
+		t.Fatalf("mismatched error - got %v, want %v", err, ErrNotBanned)
+	}
+
+	// Ensure clearing the bans is persisted.
+	if err := bl.Clear(); err != nil {
+		t.Fatalf("unexpected error clearing bans: %v", err)
+	}
+	bl = NewBanList(path)
+	if err := bl.Load(); err != nil {
+		t.Fatalf("unexpected error loading ban list: %v", err)
+	}
+	assertBanned(bl, "2001:db8::1", false)
+}
@@ -34,6 +34,10 @@ type Config struct {
 
 	// Whitelist represents the whitelisted IPs of the server.
 	WhiteList []net.IPNet
+
+	// BanList houses the banned subnets.  A ban list that is only kept in
+	// memory is used when it is not specified.
+	BanList *BanList
 }
 
 // banMgrPeer extends a peer to maintain additional state maintained by the
@@ -47,19 +51,21 @@ type banMgrPeer struct {
 
 // BanManager represents a peer ban score tracking manager.
 type BanManager struct {
-	cfg    Config
-	peers  map[*peer.Peer]*banMgrPeer
-	banned map[string]time.Time
-	mtx    sync.Mutex
+	cfg   Config
+	peers map[*peer.Peer]*banMgrPeer
+	mtx   sync.Mutex
 }
 
 // NewBanManager initializes a new peer banning manager.
 func NewBanManager(cfg *Config) *BanManager {
-	return &BanManager{
-		cfg:    *cfg,
-		peers:  make(map[*peer.Peer]*banMgrPeer, cfg.MaxPeers),
-		banned: make(map[string]time.Time, cfg.MaxPeers),
+	bm := &BanManager{
+		cfg:   *cfg,
+		peers: make(map[*peer.Peer]*banMgrPeer, cfg.MaxPeers),
+	}
+	if bm.cfg.BanList == nil {
+		bm.cfg.BanList = NewBanList("")
 	}
+	return bm
 }
 
 // lookupPeer returns the ban manager peer that maintains additional state for
@@ -122,22 +128,15 @@ func (bm *BanManager) AddPeer(p *peer.Peer) error {
 		return fmt.Errorf("cannot split hostport %v", err)
 	}
 
-	bm.mtx.Lock()
-	banEnd, ok := bm.banned[host]
-	bm.mtx.Unlock()
-
-	if ok {
-		if time.Now().Before(banEnd) {
-			p.Disconnect()
-			return fmt.Errorf("peer %s is banned for another %v - disconnecting",
-				host, time.Until(banEnd))
-		}
-
-		log.Infof("Peer %s is no longer banned", host)
-
-		bm.mtx.Lock()
-		delete(bm.banned, host)
-		bm.mtx.Unlock()
+	ip := net.ParseIP(host)
+	if ip == nil {
+		p.Disconnect()
+		return fmt.Errorf("cannot parse peer IP '%s'", host)
+	}
+	if banEnd, ok := bm.cfg.BanList.IsBanned(ip); ok {
+		p.Disconnect()
+		return fmt.Errorf("peer %s is banned for another %v - disconnecting",
+			host, time.Until(banEnd))
 	}
 
 	bmp := &banMgrPeer{
@@ -185,13 +184,20 @@ func (bm *BanManager) BanPeer(p *peer.Peer) {
 		return
 	}
 
+	subnet, err := ParseSubnet(host)
+	if err != nil {
+		log.Debugf("can't parse ban peer %s %v", p.Addr(), err)
+		return
+	}
+
 	direction := directionString(p.Inbound())
 	log.Infof("Banned peer %s (%s) for %v", host, direction,
 		bm.cfg.BanDuration)
 
-	bm.mtx.Lock()
-	bm.banned[host] = time.Now().Add(bm.cfg.BanDuration)
-	bm.mtx.Unlock()
+	err = bm.cfg.BanList.Ban(subnet, time.Now().Add(bm.cfg.BanDuration))
+	if err != nil {
+		log.Errorf("Unable to persist ban for peer %s: %v", host, err)
+	}
 
 	p.Disconnect()
 	bm.RemovePeer(p)
@@ -121,12 +121,13 @@ func TestBanPeer(t *testing.T) {
 	bmgr.mtx.Unlock()
 
 	// Ensure there are two banned peers being tracked by the manager.
-	bmgr.mtx.Lock()
-	if len(bmgr.banned) != 2 {
-		bmgr.mtx.Unlock()
-		t.Fatalf("expected two tracked banned peers, got %d", len(bmgr.banned))
+	bans, err := bmgr.cfg.BanList.Bans()
+	if err != nil {
+		t.Fatalf("unexpected err -%v\n", err)
+	}
+	if len(bans) != 2 {
+		t.Fatalf("expected two tracked banned peers, got %d", len(bans))
 	}
-	bmgr.mtx.Unlock()
 
 	// Ensure re-adding a banned peer fails if it is before the ban period ends.
 	err = bmgr.AddPeer(pA)
@@ -22,6 +22,7 @@ import (
 	"github.com/EXCCoin/exccd/internal/mining/cpuminer"
 	"github.com/EXCCoin/exccd/internal/netsync"
 	"github.com/EXCCoin/exccd/internal/rpcserver"
+	"github.com/EXCCoin/exccd/internal/staging/banmanager"
 	"github.com/EXCCoin/exccd/peer/v3"
 	"github.com/EXCCoin/exccd/txscript/v4"
 	"github.com/decred/slog"
@@ -81,6 +82,7 @@ var (
 // Initialize package-global logger variables.
 func init() {
 	addrmgr.UseLogger(amgrLog)
+	banmanager.UseLogger(srvrLog)
 	blockchain.UseLogger(chanLog)
 	blockchain.UseTreasuryLogger(trsyLog)
 	connmgr.UseLogger(cmgrLog)
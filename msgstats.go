@@ -0,0 +1,205 @@
+// Copyright (c) 2023 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"sync"
+	"time"
+
+	"github.com/EXCCoin/exccd/internal/rpcserver"
+	"github.com/EXCCoin/exccd/wire"
+)
+
+const (
+	// otherMsgType is the message type used to track bytes for which the
+	// message could not be decoded.
+	otherMsgType = "*other*"
+
+	// uploadTargetTimeframe is the duration of each cycle the upload target
+	// applies to.
+	uploadTargetTimeframe = 24 * time.Hour
+
+	// historicalBlockAge is the age after which blocks are considered
+	// historical and are therefore no longer served to peers that are not
+	// whitelisted once the upload target has been reached.
+	historicalBlockAge = 7 * 24 * time.Hour
+)
+
+// msgStats tracks the number of messages and bytes that have been sent and
+// received per message type.  It is safe for concurrent access.
+type msgStats struct {
+	mtx  sync.Mutex
+	sent map[string]rpcserver.MsgStat
+	recv map[string]rpcserver.MsgStat
+}
+
+// newMsgStats returns a new message statistics tracker with no messages.
+func newMsgStats() *msgStats {
+	return &msgStats{
+		sent: make(map[string]rpcserver.MsgStat),
+		recv: make(map[string]rpcserver.MsgStat),
+	}
+}
+
+// add adds the provided message and number of bytes to the statistics for the
+// message type in the given direction.  The bytes are tracked as a message of
+// type otherMsgType when the message is nil which happens when it could not be
+// decoded.
+func (s *msgStats) add(sent bool, msg wire.Message, bytes int) {
+	msgType := otherMsgType
+	if msg != nil {
+		msgType = msg.Command()
+	}
+
+	s.mtx.Lock()
+	stats := s.recv
+	if sent {
+		stats = s.sent
+	}
+	stat := stats[msgType]
+	stat.Msgs++
+	stat.Bytes += uint64(bytes)
+	stats[msgType] = stat
+	s.mtx.Unlock()
+}
+
+// snapshot returns a copy of the current statistics.
+func (s *msgStats) snapshot() *rpcserver.MsgStats {
+	s.mtx.Lock()
+	snap := &rpcserver.MsgStats{
+		Sent: make(map[string]rpcserver.MsgStat, len(s.sent)),
+		Recv: make(map[string]rpcserver.MsgStat, len(s.recv)),
+	}
+	for msgType, stat := range s.sent {
+		snap.Sent[msgType] = stat
+	}
+	for msgType, stat := range s.recv {
+		snap.Recv[msgType] = stat
+	}
+	s.mtx.Unlock()
+	return snap
+}
+
+// uploadTarget tracks the number of bytes sent to peers during the current
+// cycle in order to keep the total under a configured target.  Once the bytes
+// that remain in the cycle are no longer enough to relay all of the new blocks
+// that are expected during it, historical blocks are no longer served to peers
+// that are not whitelisted.  It is safe for concurrent access.
+type uploadTarget struct {
+	// The following fields are set at creation and never change.
+	target         uint64
+	maxBlockSize   uint64
+	timePerBlock   time.Duration
+	cycleTimeframe time.Duration
+	historicalAge  time.Duration
+
+	mtx        sync.Mutex
+	cycleStart time.Time
+	cycleBytes uint64
+}
+
+// newUploadTarget returns a new upload target tracker for the provided
+// maximum number of bytes per cycle along with the maximum size of blocks and
+// the expected time between them which are used to determine how many bytes to
+// reserve for relaying new blocks.  A target of zero disables the target.
+func newUploadTarget(target uint64, maxBlockSize int, timePerBlock time.Duration) *uploadTarget {
+	return &uploadTarget{
+		target:         target,
+		maxBlockSize:   uint64(maxBlockSize),
+		timePerBlock:   timePerBlock,
+		cycleTimeframe: uploadTargetTimeframe,
+		historicalAge:  historicalBlockAge,
+	}
+}
+
+// maybeStartCycle starts a new cycle when the current one has ended as of the
+// provided time.
+//
+// This function MUST be called with the mutex held (for writes).
+func (u *uploadTarget) maybeStartCycle(now time.Time) {
+	if now.Sub(u.cycleStart) >= u.cycleTimeframe {
+		u.cycleStart = now
+		u.cycleBytes = 0
+	}
+}
+
+// addBytesSent adds the provided number of bytes to the bytes sent during the
+// current cycle as of the provided time.
+func (u *uploadTarget) addBytesSent(bytes uint64, now time.Time) {
+	if u.target == 0 {
+		return
+	}
+
+	u.mtx.Lock()
+	u.maybeStartCycle(now)
+	u.cycleBytes += bytes
+	u.mtx.Unlock()
+}
+
+// timeLeftInCycle returns the time that remains in the current cycle as of the
+// provided time.
+//
+// This function MUST be called with the mutex held (for reads).
+func (u *uploadTarget) timeLeftInCycle(now time.Time) time.Duration {
+	left := u.cycleStart.Add(u.cycleTimeframe).Sub(now)
+	if left < 0 {
+		return 0
+	}
+	return left
+}
+
+// historicalLimitReached returns whether or not the bytes that remain in the
+// current cycle as of the provided time are no longer enough to relay all of
+// the new blocks that are expected during it, meaning historical blocks should
+// no longer be served.
+//
+// This function MUST be called with the mutex held (for writes).
+func (u *uploadTarget) historicalLimitReached(now time.Time) bool {
+	if u.target == 0 {
+		return false
+	}
+
+	u.maybeStartCycle(now)
+	expectedBlocks := uint64(u.timeLeftInCycle(now) / u.timePerBlock)
+	reserve := expectedBlocks * u.maxBlockSize
+	return reserve >= u.target || u.cycleBytes >= u.target-reserve
+}
+
+// serveBlock returns whether or not a block with the provided timestamp may be
+// served as of the provided time.  Blocks that are not historical may always be
+// served, while historical blocks may only be served until the bytes that
+// remain in the current cycle are needed to relay new blocks.
+func (u *uploadTarget) serveBlock(blockTime, now time.Time) bool {
+	if u.target == 0 || now.Sub(blockTime) < u.historicalAge {
+		return true
+	}
+
+	u.mtx.Lock()
+	reached := u.historicalLimitReached(now)
+	u.mtx.Unlock()
+	return !reached
+}
+
+// state returns the state of the upload target as of the provided time.
+func (u *uploadTarget) state(now time.Time) *rpcserver.UploadTarget {
+	state := &rpcserver.UploadTarget{
+		Timeframe:             u.cycleTimeframe,
+		Target:                u.target,
+		ServeHistoricalBlocks: true,
+	}
+	if u.target == 0 {
+		return state
+	}
+
+	u.mtx.Lock()
+	state.ServeHistoricalBlocks = !u.historicalLimitReached(now)
+	state.TargetReached = u.cycleBytes >= u.target
+	if !state.TargetReached {
+		state.BytesLeftInCycle = u.target - u.cycleBytes
+	}
+	state.TimeLeftInCycle = u.timeLeftInCycle(now)
+	u.mtx.Unlock()
+	return state
+}
@@ -0,0 +1,131 @@
+// Copyright (c) 2023 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/EXCCoin/exccd/internal/rpcserver"
+	"github.com/EXCCoin/exccd/wire"
+)
+
+// TestMsgStats ensures the message statistics are tracked per message type and
+// direction as expected.
+func TestMsgStats(t *testing.T) {
+	stats := newMsgStats()
+	stats.add(true, &wire.MsgPing{}, 32)
+	stats.add(true, &wire.MsgPing{}, 32)
+	stats.add(false, &wire.MsgPong{}, 32)
+	stats.add(false, &wire.MsgInv{}, 1000)
+	stats.add(false, nil, 24)
+
+	want := &rpcserver.MsgStats{
+		Sent: map[string]rpcserver.MsgStat{
+			wire.CmdPing: {Msgs: 2, Bytes: 64},
+		},
+		Recv: map[string]rpcserver.MsgStat{
+			wire.CmdPong: {Msgs: 1, Bytes: 32},
+			wire.CmdInv:  {Msgs: 1, Bytes: 1000},
+			otherMsgType: {Msgs: 1, Bytes: 24},
+		},
+	}
+	snap := stats.snapshot()
+	if !reflect.DeepEqual(snap, want) {
+		t.Fatalf("mismatched stats -- got %+v, want %+v", snap, want)
+	}
+
+	// Ensure the snapshot is not modified by later messages.
+	stats.add(true, &wire.MsgPing{}, 32)
+	if !reflect.DeepEqual(snap, want) {
+		t.Fatalf("snapshot modified -- got %+v, want %+v", snap, want)
+	}
+}
+
+// TestUploadTarget ensures the upload target only stops serving historical
+// blocks once the bytes that remain in the cycle are needed to relay the new
+// blocks that are expected during it and that cycles reset as expected.
+func TestUploadTarget(t *testing.T) {
+	const (
+		maxBlockSize = 1000
+		timePerBlock = time.Hour
+	)
+	start := time.Unix(1600000000, 0)
+	recentBlock := start.Add(-time.Hour)
+	oldBlock := start.Add(-2 * historicalBlockAge)
+
+	// Ensure everything is served when the target is disabled.
+	disabled := newUploadTarget(0, maxBlockSize, timePerBlock)
+	disabled.addBytesSent(1<<40, start)
+	if !disabled.serveBlock(oldBlock, start) {
+		t.Fatal("disabled target does not serve historical blocks")
+	}
+	wantState := &rpcserver.UploadTarget{
+		Timeframe:             uploadTargetTimeframe,
+		ServeHistoricalBlocks: true,
+	}
+	if state := disabled.state(start); !reflect.DeepEqual(state, wantState) {
+		t.Fatalf("mismatched disabled state -- got %+v, want %+v", state,
+			wantState)
+	}
+
+	// Create a target that leaves room for 10 blocks beyond the 24 that are
+	// expected at the start of the cycle.
+	const target = 34 * maxBlockSize
+	u := newUploadTarget(target, maxBlockSize, timePerBlock)
+	u.addBytesSent(9*maxBlockSize, start)
+	if !u.serveBlock(oldBlock, start) {
+		t.Fatal("historical blocks not served before limit")
+	}
+	wantState = &rpcserver.UploadTarget{
+		Timeframe:             uploadTargetTimeframe,
+		Target:                target,
+		ServeHistoricalBlocks: true,
+		BytesLeftInCycle:      25 * maxBlockSize,
+		TimeLeftInCycle:       uploadTargetTimeframe,
+	}
+	if state := u.state(start); !reflect.DeepEqual(state, wantState) {
+		t.Fatalf("mismatched state -- got %+v, want %+v", state, wantState)
+	}
+
+	// Ensure historical blocks are no longer served once the remaining bytes
+	// are reserved for new blocks while recent blocks still are.
+	u.addBytesSent(maxBlockSize, start)
+	if u.serveBlock(oldBlock, start) {
+		t.Fatal("historical blocks served after limit")
+	}
+	if !u.serveBlock(recentBlock, start) {
+		t.Fatal("recent blocks not served after limit")
+	}
+
+	// Ensure the reserve shrinks as the cycle progresses.
+	later := start.Add(2 * timePerBlock)
+	if !u.serveBlock(oldBlock, later) {
+		t.Fatal("historical blocks not served after reserve shrank")
+	}
+
+	// Ensure the target is reported as reached once it is exceeded.
+	u.addBytesSent(target, later)
+	wantState = &rpcserver.UploadTarget{
+		Timeframe:       uploadTargetTimeframe,
+		Target:          target,
+		TargetReached:   true,
+		TimeLeftInCycle: uploadTargetTimeframe - 2*timePerBlock,
+	}
+	if state := u.state(later); !reflect.DeepEqual(state, wantState) {
+		t.Fatalf("mismatched state -- got %+v, want %+v", state, wantState)
+	}
+
+	// Ensure a new cycle resets the bytes sent.
+	nextCycle := start.Add(uploadTargetTimeframe)
+	if !u.serveBlock(oldBlock, nextCycle) {
+		t.Fatal("historical blocks not served in new cycle")
+	}
+	state := u.state(nextCycle)
+	if state.BytesLeftInCycle != target || state.TargetReached {
+		t.Fatalf("cycle not reset -- got %+v", state)
+	}
+}
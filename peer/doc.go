@@ -101,6 +101,15 @@ QueueInventory function.  It employs batching and trickling along with
 intelligent known remote peer inventory detection and avoidance through the use
 of a most-recently used algorithm.
 
+Queued inventory is announced in a random order after exponentially distributed
+delays so the announcements form a Poisson process.  This makes it harder to use
+timing analysis to determine which node first announced a transaction.  Each
+peer uses its own independent delays by default, while a TrickleSchedule may be
+shared by multiple peers, such as all inbound peers, so that making several
+connections does not reveal any additional timing information.  Inventory that
+originated locally may be queued via QueueLocalInventory which announces it
+after shorter delays that may likewise be drawn from a shared TrickleSchedule.
+
 Message Sending Helper Functions
 
 In addition to the bare QueueMessage function previously described, the
@@ -10,7 +10,7 @@ require (
 	github.com/EXCCoin/exccd/crypto/ripemd160 v0.0.0-20231114084634-503e41f75524 // indirect
 	github.com/EXCCoin/exccd/dcrec v0.0.0-20231114084634-503e41f75524 // indirect
 	github.com/EXCCoin/exccd/dcrec/edwards/v2 v2.0.0-20231114084634-503e41f75524 // indirect
-	github.com/EXCCoin/exccd/dcrec/secp256k1/v4 v4.0.0-20231114084634-503e41f75524 // indirect
+	github.com/EXCCoin/exccd/dcrec/secp256k1/v4 v4.0.0-20231114084634-503e41f75524
 	github.com/EXCCoin/exccd/lru v0.0.0-20231114084634-503e41f75524
 	github.com/EXCCoin/exccd/txscript/v4 v4.0.0-20231114084634-503e41f75524
 	github.com/EXCCoin/exccd/wire v0.0.0-20231114084634-503e41f75524
@@ -18,5 +18,6 @@ require (
 	github.com/dchest/siphash v1.2.3 // indirect
 	github.com/decred/go-socks v1.1.0
 	github.com/decred/slog v1.2.0
+	golang.org/x/crypto v0.15.0
 	golang.org/x/net v0.7.0 // indirect
 )
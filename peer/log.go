@@ -114,6 +114,9 @@ func messageSummary(msg wire.Message) string {
 	case *wire.MsgAddr:
 		return fmt.Sprintf("%d addr", len(msg.AddrList))
 
+	case *wire.MsgAddrV2:
+		return fmt.Sprintf("%d addr", len(msg.AddrList))
+
 	case *wire.MsgPing:
 		// No summary - perhaps add nonce.
 
@@ -164,6 +167,24 @@ func messageSummary(msg wire.Message) string {
 		return fmt.Sprintf("blockHashes %d, voteHashes %d, tspendHashes %d",
 			len(msg.BlockHashes), len(msg.VoteHashes),
 			len(msg.TSpendHashes))
+
+	case *wire.MsgSendCmpct:
+		return fmt.Sprintf("announce %v, version %d",
+			msg.AnnounceUsingCmpctBlock, msg.Version)
+
+	case *wire.MsgCmpctBlock:
+		return fmt.Sprintf("hash %s, height %d, shortids %d/%d, "+
+			"prefilled %d/%d", msg.Header.BlockHash(), msg.Header.Height,
+			len(msg.ShortIDs), len(msg.STxShortIDs),
+			len(msg.PrefilledTxns), len(msg.PrefilledSTxns))
+
+	case *wire.MsgGetBlockTxn:
+		return fmt.Sprintf("hash %s, indexes %d/%d", msg.BlockHash,
+			len(msg.Indexes), len(msg.STxIndexes))
+
+	case *wire.MsgBlockTxn:
+		return fmt.Sprintf("hash %s, txns %d/%d", msg.BlockHash,
+			len(msg.Transactions), len(msg.STransactions))
 	}
 
 	// No summary for other messages.
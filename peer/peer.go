@@ -27,7 +27,7 @@ import (
 
 const (
 	// MaxProtocolVersion is the max protocol version the peer supports.
-	MaxProtocolVersion = wire.InitStateVersion
+	MaxProtocolVersion = wire.AddrV2Version
 
 	// outputBufferSize is the number of elements the output channels use.
 	outputBufferSize = 5000
@@ -54,10 +54,6 @@ const (
 	// only checked on each stall tick interval.
 	stallResponseTimeout = 30 * time.Second
 
-	// trickleTimeout is the duration of the ticker which trickles down the
-	// inventory to a peer.
-	trickleTimeout = 500 * time.Millisecond
-
 	// defaultIdleTimeout is the default duration of inactivity before a peer is
 	// timed out when a peer is created with the idle timeout configuration
 	// option set to 0.
@@ -99,6 +95,9 @@ type MessageListeners struct {
 	// OnAddr is invoked when a peer receives an addr wire message.
 	OnAddr func(p *Peer, msg *wire.MsgAddr)
 
+	// OnAddrV2 is invoked when a peer receives an addrv2 wire message.
+	OnAddrV2 func(p *Peer, msg *wire.MsgAddrV2)
+
 	// OnPing is invoked when a peer receives a ping wire message.
 	OnPing func(p *Peer, msg *wire.MsgPing)
 
@@ -196,6 +195,20 @@ type MessageListeners struct {
 	// OnInitState is invoked when a peer receives an initstate message.
 	OnInitState func(p *Peer, msg *wire.MsgInitState)
 
+	// OnSendCmpct is invoked when a peer receives a sendcmpct wire message.
+	OnSendCmpct func(p *Peer, msg *wire.MsgSendCmpct)
+
+	// OnCmpctBlock is invoked when a peer receives a cmpctblock wire
+	// message.
+	OnCmpctBlock func(p *Peer, msg *wire.MsgCmpctBlock)
+
+	// OnGetBlockTxn is invoked when a peer receives a getblocktxn wire
+	// message.
+	OnGetBlockTxn func(p *Peer, msg *wire.MsgGetBlockTxn)
+
+	// OnBlockTxn is invoked when a peer receives a blocktxn wire message.
+	OnBlockTxn func(p *Peer, msg *wire.MsgBlockTxn)
+
 	// OnRead is invoked when a peer receives a wire message.  It consists
 	// of the number of bytes read, the message, and whether or not an error
 	// in the read occurred.  Typically, callers will opt to use the
@@ -253,6 +266,13 @@ type Config struct {
 	// Services specifies which services to advertise as supported by the
 	// local peer.  This field can be omitted in which case it will be 0
 	// and therefore advertise no supported services.
+	//
+	// Including wire.SFNodeP2PV2 enables the encrypted and authenticated v2
+	// transport.  Inbound peers then accept both the v1 and v2 transports,
+	// while outbound peers only use the v2 transport when the network address
+	// of the remote peer also advertises support for it.  Outbound peers are
+	// disconnected when negotiating the v2 transport fails, which is reported
+	// by V2TransportFailed.
 	Services wire.ServiceFlag
 
 	// ProtocolVersion specifies the maximum protocol version to use and
@@ -271,6 +291,35 @@ type Config struct {
 	// IdleTimeout is the duration of inactivity before a peer is timed
 	// out in seconds.
 	IdleTimeout time.Duration
+
+	// TrickleInterval is the mean duration between announcements of the
+	// inventory queued via QueueInventory.  The delays between announcements
+	// are drawn independently from an exponential distribution so the
+	// announcements form a Poisson process which makes it harder to link
+	// inventory to the node that first announced it via timing analysis.
+	// This field can be omitted in which case DefaultTrickleInterval will be
+	// used.  It is ignored when TrickleSchedule is specified.
+	TrickleInterval time.Duration
+
+	// TrickleSchedule specifies an inventory announcement schedule that is
+	// shared with other peers.  It is typically specified for inbound peers
+	// so that making several connections does not allow learning about
+	// inventory any faster.  This field can be omitted in which case the peer
+	// uses its own independent schedule.
+	TrickleSchedule *TrickleSchedule
+
+	// LocalTrickleInterval is the mean duration between announcements of the
+	// inventory queued via QueueLocalInventory.  This field can be omitted in
+	// which case DefaultLocalTrickleInterval will be used.  It is ignored when
+	// LocalTrickleSchedule is specified.
+	LocalTrickleInterval time.Duration
+
+	// LocalTrickleSchedule specifies an announcement schedule for the
+	// inventory queued via QueueLocalInventory that is shared with other
+	// peers.  It is typically specified for inbound peers for the same reason
+	// as TrickleSchedule.  This field can be omitted in which case the peer
+	// uses its own independent schedule.
+	LocalTrickleSchedule *TrickleSchedule
 }
 
 // minUint32 is a helper function to return the minimum of two uint32s.
@@ -328,6 +377,13 @@ type outMsg struct {
 	doneChan chan<- struct{}
 }
 
+// queuedInv is used to house inventory queued to be trickled to the peer along
+// with whether or not it originated locally.
+type queuedInv struct {
+	iv    *wire.InvVect
+	local bool
+}
+
 // stallControlCmd represents the command of a stall control message.
 type stallControlCmd uint8
 
@@ -373,6 +429,7 @@ type StatsSnap struct {
 	LastPingNonce  uint64
 	LastPingTime   time.Time
 	LastPingMicros int64
+	V2Transport    bool
 }
 
 // HashFunc is a function which returns a block hash, height and error
@@ -424,6 +481,19 @@ type Peer struct {
 
 	conn net.Conn
 
+	// connReader is the reader used to read messages from the connection.
+	// It is the connection itself unless bytes were read from it in order to
+	// detect the transport used by the remote peer, in which case those bytes
+	// are read first.
+	//
+	// v2 is the encrypted v2 transport session or nil when the v1 transport
+	// is in use.
+	//
+	// Both fields are only modified while negotiating the transport prior to
+	// starting the input and output handlers.
+	connReader io.Reader
+	v2         *v2Transport
+
 	// These fields are set at creation time and never modified, so they are
 	// safe to read from concurrently without a mutex.
 	addr    string
@@ -439,6 +509,10 @@ type Peer struct {
 	advertisedProtoVer   uint32 // protocol version advertised by remote
 	protocolVersion      uint32 // negotiated protocol version
 	sendHeadersPreferred bool   // peer sent a sendheaders message
+	cmpctBlocksSupported bool   // peer sent a supported sendcmpct message
+	cmpctHighBandwidth   bool   // peer wants new blocks as cmpctblock
+	v2TransportUsed      bool   // encrypted v2 transport in use
+	v2TransportFailed    bool   // outbound v2 transport negotiation failed
 	versionSent          bool
 	verAckReceived       bool
 
@@ -465,7 +539,7 @@ type Peer struct {
 	outputQueue   chan outMsg
 	sendQueue     chan outMsg
 	sendDoneQueue chan struct{}
-	outputInvChan chan *wire.InvVect
+	outputInvChan chan queuedInv
 	inQuit        chan struct{}
 	queueQuit     chan struct{}
 	outQuit       chan struct{}
@@ -523,6 +597,7 @@ func (p *Peer) StatsSnapshot() *StatsSnap {
 	userAgent := p.userAgent
 	services := p.services
 	protocolVersion := p.advertisedProtoVer
+	v2Transport := p.v2TransportUsed
 	p.flagsMtx.Unlock()
 
 	// Get a copy of all relevant flags and stats.
@@ -544,6 +619,7 @@ func (p *Peer) StatsSnapshot() *StatsSnap {
 		LastPingNonce:  p.lastPingNonce,
 		LastPingMicros: p.lastPingMicros,
 		LastPingTime:   p.lastPingTime,
+		V2Transport:    v2Transport,
 	}
 
 	p.statsMtx.RUnlock()
@@ -781,6 +857,58 @@ func (p *Peer) WantsHeaders() bool {
 	return sendHeadersPreferred
 }
 
+// SupportsCmpctBlocks returns if the peer signaled support for a compact block
+// encoding version this package supports via a sendcmpct message.
+//
+// This function is safe for concurrent access.
+func (p *Peer) SupportsCmpctBlocks() bool {
+	p.flagsMtx.Lock()
+	supported := p.cmpctBlocksSupported
+	p.flagsMtx.Unlock()
+
+	return supported
+}
+
+// WantsCmpctBlocks returns if the peer wants new blocks announced by sending
+// cmpctblock messages without waiting for a request, which is otherwise known
+// as high-bandwidth compact block relay.
+//
+// This function is safe for concurrent access.
+func (p *Peer) WantsCmpctBlocks() bool {
+	p.flagsMtx.Lock()
+	wants := p.cmpctBlocksSupported && p.cmpctHighBandwidth
+	p.flagsMtx.Unlock()
+
+	return wants
+}
+
+// V2Transport returns whether or not the connection to the peer uses the
+// encrypted and authenticated v2 transport.
+//
+// This function is safe for concurrent access.
+func (p *Peer) V2Transport() bool {
+	p.flagsMtx.Lock()
+	v2Transport := p.v2TransportUsed
+	p.flagsMtx.Unlock()
+
+	return v2Transport
+}
+
+// V2TransportFailed returns whether or not negotiating the v2 transport with
+// the remote peer of an outbound connection failed.  This typically happens
+// when the remote peer was advertised as supporting the v2 transport, but it
+// only supports the v1 transport, so callers may use it to connect to the
+// remote peer again without advertising the v2 transport service for it.
+//
+// This function is safe for concurrent access.
+func (p *Peer) V2TransportFailed() bool {
+	p.flagsMtx.Lock()
+	v2TransportFailed := p.v2TransportFailed
+	p.flagsMtx.Unlock()
+
+	return v2TransportFailed
+}
+
 // PushAddrMsg sends an addr message to the connected peer using the provided
 // addresses.  This function is useful over manually sending the message via
 // QueueMessage since it automatically limits the addresses to the maximum
@@ -815,6 +943,40 @@ func (p *Peer) PushAddrMsg(addresses []*wire.NetAddress) ([]*wire.NetAddress, er
 	return msg.AddrList, nil
 }
 
+// PushAddrV2Msg sends an addrv2 message to the connected peer using the
+// provided addresses.  It behaves the same as PushAddrMsg except the addresses
+// may belong to any of the networks supported by the addrv2 message.
+//
+// The peer must have negotiated a protocol version of at least
+// wire.AddrV2Version.
+//
+// This function is safe for concurrent access.
+func (p *Peer) PushAddrV2Msg(addresses []*wire.NetAddressV2) ([]*wire.NetAddressV2, error) {
+	// Nothing to send.
+	if len(addresses) == 0 {
+		return nil, nil
+	}
+
+	msg := wire.NewMsgAddrV2()
+	msg.AddrList = make([]*wire.NetAddressV2, len(addresses))
+	copy(msg.AddrList, addresses)
+
+	// Randomize the addresses sent if there are more than the maximum allowed.
+	if len(msg.AddrList) > wire.MaxAddrPerV2Msg {
+		// Shuffle the address list.
+		for i := range msg.AddrList {
+			j := rand.Intn(i + 1)
+			msg.AddrList[i], msg.AddrList[j] = msg.AddrList[j], msg.AddrList[i]
+		}
+
+		// Truncate it to the maximum size.
+		msg.AddrList = msg.AddrList[:wire.MaxAddrPerV2Msg]
+	}
+
+	p.QueueMessage(msg, nil)
+	return msg.AddrList, nil
+}
+
 // PushGetBlocksMsg sends a getblocks message for the provided block locator
 // and stop hash.  It will ignore back-to-back duplicate requests.
 //
@@ -940,8 +1102,16 @@ func (p *Peer) readMessage() (wire.Message, []byte, error) {
 	if err != nil {
 		return nil, nil, err
 	}
-	n, msg, buf, err := wire.ReadMessageN(p.conn, p.ProtocolVersion(),
-		p.cfg.Net)
+	var n int
+	var msg wire.Message
+	var buf []byte
+	if p.v2 != nil {
+		n, msg, buf, err = p.v2.readMessage(p.connReader, p.ProtocolVersion(),
+			p.cfg.Net)
+	} else {
+		n, msg, buf, err = wire.ReadMessageN(p.connReader, p.ProtocolVersion(),
+			p.cfg.Net)
+	}
 	atomic.AddUint64(&p.bytesReceived, uint64(n))
 	if p.cfg.Listeners.OnRead != nil {
 		p.cfg.Listeners.OnRead(p, n, msg, err)
@@ -994,7 +1164,13 @@ func (p *Peer) writeMessage(msg wire.Message) error {
 	}
 
 	// Write the message to the peer.
-	n, err := wire.WriteMessageN(p.conn, msg, p.ProtocolVersion(), p.cfg.Net)
+	var n int
+	var err error
+	if p.v2 != nil {
+		n, err = p.v2.writeMessage(p.conn, msg, p.ProtocolVersion(), p.cfg.Net)
+	} else {
+		n, err = wire.WriteMessageN(p.conn, msg, p.ProtocolVersion(), p.cfg.Net)
+	}
 	atomic.AddUint64(&p.bytesSent, uint64(n))
 	if p.cfg.Listeners.OnWrite != nil {
 		p.cfg.Listeners.OnWrite(p, n, msg, err)
@@ -1061,12 +1237,18 @@ func (p *Peer) maybeAddDeadline(pendingResponses map[string]time.Time, msgCmd st
 		addedDeadline = true
 
 	case wire.CmdGetData:
-		// Expects a block, tx, or notfound message.
+		// Expects a block, cmpctblock, tx, or notfound message.
 		pendingResponses[wire.CmdBlock] = deadline
+		pendingResponses[wire.CmdCmpctBlock] = deadline
 		pendingResponses[wire.CmdTx] = deadline
 		pendingResponses[wire.CmdNotFound] = deadline
 		addedDeadline = true
 
+	case wire.CmdGetBlockTxn:
+		// Expects a blocktxn message.
+		pendingResponses[wire.CmdBlockTxn] = deadline
+		addedDeadline = true
+
 	case wire.CmdGetHeaders:
 		// Expects a headers message.  Use a longer deadline since it
 		// can take a while for the remote peer to load all of the
@@ -1133,10 +1315,13 @@ out:
 				switch msgCmd := msg.message.Command(); msgCmd {
 				case wire.CmdBlock:
 					fallthrough
+				case wire.CmdCmpctBlock:
+					fallthrough
 				case wire.CmdTx:
 					fallthrough
 				case wire.CmdNotFound:
 					delete(pendingResponses, wire.CmdBlock)
+					delete(pendingResponses, wire.CmdCmpctBlock)
 					delete(pendingResponses, wire.CmdTx)
 					delete(pendingResponses, wire.CmdNotFound)
 
@@ -1302,6 +1487,11 @@ out:
 				p.cfg.Listeners.OnAddr(p, msg)
 			}
 
+		case *wire.MsgAddrV2:
+			if p.cfg.Listeners.OnAddrV2 != nil {
+				p.cfg.Listeners.OnAddrV2(p, msg)
+			}
+
 		case *wire.MsgPing:
 			p.handlePingMsg(msg)
 			if p.cfg.Listeners.OnPing != nil {
@@ -1438,6 +1628,36 @@ out:
 				p.cfg.Listeners.OnInitState(p, msg)
 			}
 
+		case *wire.MsgSendCmpct:
+			// Only record the preferences for supported compact block
+			// encoding versions.  Messages for other versions are
+			// ignored as required.
+			if msg.Version == wire.CmpctBlockEncodingVersion {
+				p.flagsMtx.Lock()
+				p.cmpctBlocksSupported = true
+				p.cmpctHighBandwidth = msg.AnnounceUsingCmpctBlock
+				p.flagsMtx.Unlock()
+			}
+
+			if p.cfg.Listeners.OnSendCmpct != nil {
+				p.cfg.Listeners.OnSendCmpct(p, msg)
+			}
+
+		case *wire.MsgCmpctBlock:
+			if p.cfg.Listeners.OnCmpctBlock != nil {
+				p.cfg.Listeners.OnCmpctBlock(p, msg)
+			}
+
+		case *wire.MsgGetBlockTxn:
+			if p.cfg.Listeners.OnGetBlockTxn != nil {
+				p.cfg.Listeners.OnGetBlockTxn(p, msg)
+			}
+
+		case *wire.MsgBlockTxn:
+			if p.cfg.Listeners.OnBlockTxn != nil {
+				p.cfg.Listeners.OnBlockTxn(p, msg)
+			}
+
 		default:
 			log.Debugf("Received unhandled message of type %v "+
 				"from %v", rmsg.Command(), p)
@@ -1452,15 +1672,38 @@ out:
 	log.Tracef("Peer input handler done for %s", p)
 }
 
+// nextTrickleTime returns the time queued inventory should next be announced
+// to the peer after the provided time.  It is drawn from the shared schedule
+// when one was specified and is otherwise independent of all other peers.
+func (p *Peer) nextTrickleTime(now time.Time) time.Time {
+	if p.cfg.TrickleSchedule != nil {
+		return p.cfg.TrickleSchedule.Next(now)
+	}
+	return now.Add(poissonDelay(p.cfg.TrickleInterval))
+}
+
+// nextLocalTrickleTime returns the time queued inventory that originated
+// locally should next be announced to the peer after the provided time.  It is
+// drawn from the shared local schedule when one was specified and is otherwise
+// independent of all other peers.
+func (p *Peer) nextLocalTrickleTime(now time.Time) time.Time {
+	if p.cfg.LocalTrickleSchedule != nil {
+		return p.cfg.LocalTrickleSchedule.Next(now)
+	}
+	return now.Add(poissonDelay(p.cfg.LocalTrickleInterval))
+}
+
 // queueHandler handles the queuing of outgoing data for the peer. This runs as
 // a muxer for various sources of input so we can ensure that server and peer
 // handlers will not block on us sending a message.  That data is then passed on
 // to outHandler to be actually written.
 func (p *Peer) queueHandler() {
 	var pendingMsgs []outMsg
-	var invSendQueue []*wire.InvVect
-	trickleTicker := time.NewTicker(trickleTimeout)
-	defer trickleTicker.Stop()
+	var invSendQueue, localInvSendQueue []*wire.InvVect
+	trickleTimer := time.NewTimer(time.Until(p.nextTrickleTime(time.Now())))
+	defer trickleTimer.Stop()
+	localTrickleTimer := time.NewTimer(time.Until(p.nextLocalTrickleTime(time.Now())))
+	defer localTrickleTimer.Stop()
 
 	// We keep the waiting flag so that we know if we have a message queued
 	// to the outHandler or not.  We could use the presence of a head of
@@ -1481,6 +1724,43 @@ func (p *Peer) queueHandler() {
 		// we are always waiting now.
 		return true
 	}
+
+	// trickleInv creates and queues as many inv messages as needed to announce
+	// the provided inventory in a random order so the order does not reveal
+	// the order it was received in.
+	trickleInv := func(invs []*wire.InvVect) {
+		// Don't send anything if we're disconnecting or there is no queued
+		// inventory.  Version is known if the send queue has any entries.
+		if atomic.LoadInt32(&p.disconnect) != 0 || len(invs) == 0 {
+			return
+		}
+
+		rand.Shuffle(len(invs), func(i, j int) {
+			invs[i], invs[j] = invs[j], invs[i]
+		})
+		invMsg := wire.NewMsgInvSizeHint(uint(len(invs)))
+		for _, iv := range invs {
+			// Don't send inventory that became known after the initial
+			// check.
+			if p.knownInventory.Contains(iv) {
+				continue
+			}
+
+			invMsg.AddInvVect(iv)
+			if len(invMsg.InvList) >= maxInvTrickleSize {
+				waiting = queuePacket(outMsg{msg: invMsg}, &pendingMsgs,
+					waiting)
+				invMsg = wire.NewMsgInvSizeHint(uint(len(invs)))
+			}
+
+			// Add the inventory that is being relayed to the known
+			// inventory for the peer.
+			p.AddKnownInventory(iv)
+		}
+		if len(invMsg.InvList) > 0 {
+			waiting = queuePacket(outMsg{msg: invMsg}, &pendingMsgs, waiting)
+		}
+	}
 out:
 	for {
 		select {
@@ -1503,49 +1783,31 @@ out:
 			pendingMsgs = pendingMsgs[1:]
 			p.sendQueue <- next
 
-		case iv := <-p.outputInvChan:
+		case inv := <-p.outputInvChan:
 			// No handshake?  They'll find out soon enough.
-			if p.VersionKnown() {
-				invSendQueue = append(invSendQueue, iv)
-			}
-
-		case <-trickleTicker.C:
-			// Don't send anything if we're disconnecting or there
-			// is no queued inventory.
-			// version is known if send queue has any entries.
-			if atomic.LoadInt32(&p.disconnect) != 0 ||
-				len(invSendQueue) == 0 {
+			if !p.VersionKnown() {
 				continue
 			}
-
-			// Create and send as many inv messages as needed to
-			// drain the inventory send queue.
-			invMsg := wire.NewMsgInvSizeHint(uint(len(invSendQueue)))
-
-			for _, iv := range invSendQueue {
-				// Don't send inventory that became known after
-				// the initial check.
-				if p.knownInventory.Contains(iv) {
-					continue
-				}
-
-				invMsg.AddInvVect(iv)
-				if len(invMsg.InvList) >= maxInvTrickleSize {
-					waiting = queuePacket(
-						outMsg{msg: invMsg},
-						&pendingMsgs, waiting)
-					invMsg = wire.NewMsgInvSizeHint(uint(len(invSendQueue)))
-				}
-
-				// Add the inventory that is being relayed to
-				// the known inventory for the peer.
-				p.AddKnownInventory(iv)
-			}
-			if len(invMsg.InvList) > 0 {
-				waiting = queuePacket(outMsg{msg: invMsg},
-					&pendingMsgs, waiting)
+			if inv.local {
+				localInvSendQueue = append(localInvSendQueue, inv.iv)
+			} else {
+				invSendQueue = append(invSendQueue, inv.iv)
 			}
-			invSendQueue = nil
+
+		case <-trickleTimer.C:
+			// Announce all queued inventory, including any that originated
+			// locally, and schedule the next announcement.
+			invSendQueue = append(invSendQueue, localInvSendQueue...)
+			trickleInv(invSendQueue)
+			invSendQueue, localInvSendQueue = nil, nil
+			trickleTimer.Reset(time.Until(p.nextTrickleTime(time.Now())))
+
+		case <-localTrickleTimer.C:
+			// Announce the queued inventory that originated locally sooner
+			// than the remaining inventory.
+			trickleInv(localInvSendQueue)
+			localInvSendQueue = nil
+			localTrickleTimer.Reset(time.Until(p.nextLocalTrickleTime(time.Now())))
 
 		case <-p.quit:
 			break out
@@ -1696,12 +1958,12 @@ func (p *Peer) QueueMessage(msg wire.Message, doneChan chan<- struct{}) {
 	p.outputQueue <- outMsg{msg: msg, doneChan: doneChan}
 }
 
-// QueueInventory adds the passed inventory to the inventory send queue which
-// might not be sent right away, rather it is trickled to the peer in batches.
-// Inventory that the peer is already known to have is ignored.
+// queueInventory adds the passed inventory to the inventory send queue along
+// with whether or not it originated locally.  Inventory that the peer is
+// already known to have is ignored.
 //
 // This function is safe for concurrent access.
-func (p *Peer) QueueInventory(invVect *wire.InvVect) {
+func (p *Peer) queueInventory(invVect *wire.InvVect, local bool) {
 	// Don't add the inventory to the send queue if the peer is already
 	// known to have it.
 	if p.knownInventory.Contains(invVect) {
@@ -1715,7 +1977,28 @@ func (p *Peer) QueueInventory(invVect *wire.InvVect) {
 		return
 	}
 
-	p.outputInvChan <- invVect
+	p.outputInvChan <- queuedInv{iv: invVect, local: local}
+}
+
+// QueueInventory adds the passed inventory to the inventory send queue which
+// might not be sent right away, rather it is trickled to the peer in batches
+// after random delays.  Inventory that the peer is already known to have is
+// ignored.
+//
+// This function is safe for concurrent access.
+func (p *Peer) QueueInventory(invVect *wire.InvVect) {
+	p.queueInventory(invVect, false)
+}
+
+// QueueLocalInventory adds the passed inventory, which must have originated
+// locally such as transactions submitted by a wallet, to the inventory send
+// queue.  It is trickled to the peer in the same way as inventory queued via
+// QueueInventory, however, the mean delay before it is announced is shorter.
+// Inventory that the peer is already known to have is ignored.
+//
+// This function is safe for concurrent access.
+func (p *Peer) QueueLocalInventory(invVect *wire.InvVect) {
+	p.queueInventory(invVect, true)
 }
 
 // QueueInventoryImmediate adds the passed inventory to the send queue to be
@@ -1934,12 +2217,66 @@ func (p *Peer) negotiateOutboundProtocol() error {
 	return p.readRemoteVersionMsg()
 }
 
+// negotiateTransport establishes the encrypted v2 transport with the remote
+// peer when both peers support it.  The v1 transport is used otherwise.
+//
+// Inbound peers that support the v2 transport detect which transport the
+// remote peer is using from the first bytes it sends, while outbound peers only
+// attempt the v2 transport when the remote peer advertises support for it.
+func (p *Peer) negotiateTransport() error {
+	if p.cfg.Services&wire.SFNodeP2PV2 != wire.SFNodeP2PV2 {
+		return nil
+	}
+
+	var t *v2Transport
+	if p.inbound {
+		var prefix []byte
+		var err error
+		t, prefix, err = acceptV2Transport(p.conn, p.cfg.Net)
+		if err != nil {
+			return err
+		}
+		if t == nil {
+			p.connReader = io.MultiReader(bytes.NewReader(prefix), p.conn)
+			return nil
+		}
+	} else {
+		if !p.na.HasService(wire.SFNodeP2PV2) {
+			return nil
+		}
+
+		// Consider the negotiation failed until it completes so that it is
+		// also reported as failed when it times out.
+		p.flagsMtx.Lock()
+		p.v2TransportFailed = true
+		p.flagsMtx.Unlock()
+
+		var err error
+		t, err = initiateV2Transport(p.conn, p.cfg.Net)
+		if err != nil {
+			return err
+		}
+	}
+
+	p.v2 = t
+	p.flagsMtx.Lock()
+	p.v2TransportUsed = true
+	p.v2TransportFailed = false
+	p.flagsMtx.Unlock()
+	log.Debugf("Negotiated v2 transport with %s", p)
+	return nil
+}
+
 // start begins processing input and output messages.
 func (p *Peer) start() error {
 	log.Tracef("Starting peer %s", p)
 
 	negotiateErr := make(chan error, 1)
 	go func() {
+		if err := p.negotiateTransport(); err != nil {
+			negotiateErr <- err
+			return
+		}
 		if p.inbound {
 			negotiateErr <- p.negotiateInboundProtocol()
 		} else {
@@ -1982,6 +2319,7 @@ func (p *Peer) AssociateConnection(conn net.Conn) {
 	}
 
 	p.conn = conn
+	p.connReader = conn
 	p.timeConnected = time.Now()
 
 	if p.inbound {
@@ -2040,6 +2378,15 @@ func newPeerBase(cfgOrig *Config, inbound bool) *Peer {
 		cfg.IdleTimeout = defaultIdleTimeout
 	}
 
+	// Set the default inventory trickle intervals if the caller did not
+	// specify them.
+	if cfg.TrickleInterval == 0 {
+		cfg.TrickleInterval = DefaultTrickleInterval
+	}
+	if cfg.LocalTrickleInterval == 0 {
+		cfg.LocalTrickleInterval = DefaultLocalTrickleInterval
+	}
+
 	p := Peer{
 		inbound:         inbound,
 		knownInventory:  lru.NewCache(maxKnownInventory),
@@ -2047,7 +2394,7 @@ func newPeerBase(cfgOrig *Config, inbound bool) *Peer {
 		outputQueue:     make(chan outMsg, outputBufferSize),
 		sendQueue:       make(chan outMsg, 1),   // nonblocking sync
 		sendDoneQueue:   make(chan struct{}, 1), // nonblocking sync
-		outputInvChan:   make(chan *wire.InvVect, outputBufferSize),
+		outputInvChan:   make(chan queuedInv, outputBufferSize),
 		inQuit:          make(chan struct{}),
 		queueQuit:       make(chan struct{}),
 		outQuit:         make(chan struct{}),
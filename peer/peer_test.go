@@ -332,6 +332,9 @@ func TestPeerListeners(t *testing.T) {
 			OnAddr: func(p *Peer, msg *wire.MsgAddr) {
 				ok <- msg
 			},
+			OnAddrV2: func(p *Peer, msg *wire.MsgAddrV2) {
+				ok <- msg
+			},
 			OnPing: func(p *Peer, msg *wire.MsgPing) {
 				ok <- msg
 			},
@@ -392,9 +395,6 @@ func TestPeerListeners(t *testing.T) {
 			OnVerAck: func(p *Peer, msg *wire.MsgVerAck) {
 				verack <- struct{}{}
 			},
-			OnReject: func(p *Peer, msg *wire.MsgReject) {
-				ok <- msg
-			},
 			OnSendHeaders: func(p *Peer, msg *wire.MsgSendHeaders) {
 				ok <- msg
 			},
@@ -410,6 +410,18 @@ func TestPeerListeners(t *testing.T) {
 			OnInitState: func(p *Peer, msg *wire.MsgInitState) {
 				ok <- msg
 			},
+			OnSendCmpct: func(p *Peer, msg *wire.MsgSendCmpct) {
+				ok <- msg
+			},
+			OnCmpctBlock: func(p *Peer, msg *wire.MsgCmpctBlock) {
+				ok <- msg
+			},
+			OnGetBlockTxn: func(p *Peer, msg *wire.MsgGetBlockTxn) {
+				ok <- msg
+			},
+			OnBlockTxn: func(p *Peer, msg *wire.MsgBlockTxn) {
+				ok <- msg
+			},
 		},
 		UserAgentName:    "peer",
 		UserAgentVersion: "1.0",
@@ -463,6 +475,10 @@ func TestPeerListeners(t *testing.T) {
 			"OnAddr",
 			wire.NewMsgAddr(),
 		},
+		{
+			"OnAddrV2",
+			wire.NewMsgAddrV2(),
+		},
 		{
 			"OnPing",
 			wire.NewMsgPing(42),
@@ -552,10 +568,6 @@ func TestPeerListeners(t *testing.T) {
 		},
 		// only one version message is allowed
 		// only one verack message is allowed
-		{
-			"OnReject",
-			wire.NewMsgReject("block", wire.RejectDuplicate, "dupe block"),
-		},
 		{
 			"OnSendHeaders",
 			wire.NewMsgSendHeaders(),
@@ -568,6 +580,22 @@ func TestPeerListeners(t *testing.T) {
 			"OnInitState",
 			wire.NewMsgInitState(),
 		},
+		{
+			"OnSendCmpct",
+			wire.NewMsgSendCmpct(true, wire.CmpctBlockEncodingVersion),
+		},
+		{
+			"OnCmpctBlock",
+			wire.NewMsgCmpctBlock(&wire.BlockHeader{}, 1),
+		},
+		{
+			"OnGetBlockTxn",
+			wire.NewMsgGetBlockTxn(&chainhash.Hash{}),
+		},
+		{
+			"OnBlockTxn",
+			wire.NewMsgBlockTxn(&chainhash.Hash{}),
+		},
 	}
 	t.Logf("Running %d tests", len(tests))
 	for _, test := range tests {
@@ -688,6 +716,15 @@ func TestOutboundPeer(t *testing.T) {
 		t.Errorf("PushAddrMsg: unexpected err %v\n", err)
 		return
 	}
+	var addrsV2 []*wire.NetAddressV2
+	for i := 0; i < 5; i++ {
+		na := wire.NewNetAddressV2IPPort(net.ParseIP("10.0.0.1"), 8333, 0)
+		addrsV2 = append(addrsV2, na)
+	}
+	if _, err := p2.PushAddrV2Msg(addrsV2); err != nil {
+		t.Errorf("PushAddrV2Msg: unexpected err %v\n", err)
+		return
+	}
 	if err := p2.PushGetBlocksMsg(nil, &chainhash.Hash{}); err != nil {
 		t.Errorf("PushGetBlocksMsg: unexpected err %v\n", err)
 		return
@@ -770,6 +807,107 @@ func TestDuplicateVersionMsg(t *testing.T) {
 	}
 }
 
+// TestCmpctBlockPreferences ensures that the compact block relay preferences
+// signaled by a remote peer via sendcmpct messages are tracked as expected.
+func TestCmpctBlockPreferences(t *testing.T) {
+	// Create a pair of peers that are connected to each other using a fake
+	// connection.
+	verack := make(chan struct{})
+	sendCmpct := make(chan struct{})
+	peerCfg := &Config{
+		Listeners: MessageListeners{
+			OnVerAck: func(p *Peer, msg *wire.MsgVerAck) {
+				verack <- struct{}{}
+			},
+			OnSendCmpct: func(p *Peer, msg *wire.MsgSendCmpct) {
+				sendCmpct <- struct{}{}
+			},
+		},
+		UserAgentName:    "peer",
+		UserAgentVersion: "1.0",
+		Net:              wire.MainNet,
+		Services:         0,
+	}
+	inConn, outConn := pipe(
+		&conn{laddr: "10.0.0.1:9108", raddr: "10.0.0.2:9108"},
+		&conn{laddr: "10.0.0.2:9108", raddr: "10.0.0.1:9108"},
+	)
+	outPeer, err := NewOutboundPeer(peerCfg, inConn.laddr)
+	if err != nil {
+		t.Fatalf("NewOutboundPeer: unexpected err: %v\n", err)
+	}
+	outPeer.AssociateConnection(outConn)
+	inPeer := NewInboundPeer(peerCfg)
+	inPeer.AssociateConnection(inConn)
+	defer outPeer.Disconnect()
+	defer inPeer.Disconnect()
+
+	// Wait for the veracks from the initial protocol version negotiation.
+	for i := 0; i < 2; i++ {
+		select {
+		case <-verack:
+		case <-time.After(time.Second):
+			t.Fatal("verack timeout")
+		}
+	}
+
+	// Ensure compact blocks are not considered supported prior to receiving
+	// a sendcmpct message.
+	if inPeer.SupportsCmpctBlocks() || inPeer.WantsCmpctBlocks() {
+		t.Fatal("compact blocks supported prior to sendcmpct")
+	}
+
+	tests := []struct {
+		name          string
+		msg           *wire.MsgSendCmpct
+		wantSupported bool
+		wantHB        bool
+	}{{
+		name:          "unsupported encoding version is ignored",
+		msg:           wire.NewMsgSendCmpct(true, wire.CmpctBlockEncodingVersion+1),
+		wantSupported: false,
+		wantHB:        false,
+	}, {
+		name:          "low-bandwidth mode",
+		msg:           wire.NewMsgSendCmpct(false, wire.CmpctBlockEncodingVersion),
+		wantSupported: true,
+		wantHB:        false,
+	}, {
+		name:          "high-bandwidth mode",
+		msg:           wire.NewMsgSendCmpct(true, wire.CmpctBlockEncodingVersion),
+		wantSupported: true,
+		wantHB:        true,
+	}, {
+		name:          "unsupported version does not change mode",
+		msg:           wire.NewMsgSendCmpct(false, wire.CmpctBlockEncodingVersion+1),
+		wantSupported: true,
+		wantHB:        true,
+	}, {
+		name:          "back to low-bandwidth mode",
+		msg:           wire.NewMsgSendCmpct(false, wire.CmpctBlockEncodingVersion),
+		wantSupported: true,
+		wantHB:        false,
+	}}
+
+	for _, test := range tests {
+		outPeer.QueueMessage(test.msg, nil)
+		select {
+		case <-sendCmpct:
+		case <-time.After(time.Second):
+			t.Fatalf("%q: sendcmpct timeout", test.name)
+		}
+
+		if got := inPeer.SupportsCmpctBlocks(); got != test.wantSupported {
+			t.Fatalf("%q: mismatched compact block support -- got %v, "+
+				"want %v", test.name, got, test.wantSupported)
+		}
+		if got := inPeer.WantsCmpctBlocks(); got != test.wantHB {
+			t.Fatalf("%q: mismatched high-bandwidth mode -- got %v, "+
+				"want %v", test.name, got, test.wantHB)
+		}
+	}
+}
+
 // TestNetFallback ensures the network is set to the expected value in
 // accordance with the parameters.
 func TestNetFallback(t *testing.T) {
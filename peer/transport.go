@@ -0,0 +1,295 @@
+// Copyright (c) 2023 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package peer
+
+import (
+	"bytes"
+	"crypto/cipher"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/EXCCoin/exccd/dcrec/secp256k1/v4"
+	"github.com/EXCCoin/exccd/wire"
+	"golang.org/x/crypto/chacha20"
+	"golang.org/x/crypto/chacha20poly1305"
+	"golang.org/x/crypto/hkdf"
+)
+
+const (
+	// v2PubKeySize is the size of the ephemeral public keys that are
+	// exchanged during the v2 transport handshake.
+	v2PubKeySize = secp256k1.PubKeyBytesLenCompressed
+
+	// v2LengthFieldSize is the size of the encrypted length field that
+	// prefixes each v2 transport packet.
+	v2LengthFieldSize = 4
+
+	// v2MaxPacketPayload is the maximum size of the plaintext of a v2
+	// transport packet.  Each packet carries exactly one wire message.
+	v2MaxPacketPayload = wire.MessageHeaderSize + wire.MaxMessagePayload
+
+	// v1PrefixSize is the number of bytes sent by a peer initiating a v1
+	// connection that are inspected to distinguish it from a peer initiating
+	// a v2 connection.  It consists of the network magic followed by the
+	// version command of the first message header.
+	v1PrefixSize = 16
+)
+
+// v2TransportSalt is combined with the network magic to form the salt used
+// when deriving the v2 transport session keys.  This ensures the keys differ
+// between networks.
+const v2TransportSalt = "exccd v2 transport"
+
+// v1Prefix returns the first bytes a peer initiating a v1 connection on the
+// provided network sends.
+func v1Prefix(net wire.CurrencyNet) [v1PrefixSize]byte {
+	var prefix [v1PrefixSize]byte
+	binary.LittleEndian.PutUint32(prefix[:4], uint32(net))
+	copy(prefix[4:], wire.CmdVersion)
+	return prefix
+}
+
+// v2Transport houses the state of an established encrypted and authenticated
+// v2 transport session.
+//
+// Each wire message is sent as a single packet that consists of the message
+// length encrypted with a ChaCha20 stream followed by the message encrypted
+// and authenticated with ChaCha20-Poly1305 using the encrypted length as the
+// associated data.  Each direction of the session uses independent keys and
+// nonces.
+//
+// Reading and writing may happen concurrently, however, multiple concurrent
+// reads or multiple concurrent writes are not safe.
+type v2Transport struct {
+	sendLenCipher *chacha20.Cipher
+	sendAEAD      cipher.AEAD
+	sendNonce     uint64
+
+	recvLenCipher *chacha20.Cipher
+	recvAEAD      cipher.AEAD
+	recvNonce     uint64
+}
+
+// newV2Transport derives the session keys from the ECDH shared secret of the
+// provided local private key and remote public key and returns the resulting
+// v2 transport session.
+func newV2Transport(privKey *secp256k1.PrivateKey, localPubKey []byte,
+	remotePubKey *secp256k1.PublicKey, initiator bool,
+	net wire.CurrencyNet) (*v2Transport, error) {
+
+	// The session keys are bound to the network and both ephemeral public
+	// keys in the order of initiator followed by responder.
+	salt := make([]byte, len(v2TransportSalt)+4)
+	copy(salt, v2TransportSalt)
+	binary.LittleEndian.PutUint32(salt[len(v2TransportSalt):], uint32(net))
+	remotePubKeyBytes := remotePubKey.SerializeCompressed()
+	info := make([]byte, 0, 2*v2PubKeySize)
+	if initiator {
+		info = append(info, localPubKey...)
+		info = append(info, remotePubKeyBytes...)
+	} else {
+		info = append(info, remotePubKeyBytes...)
+		info = append(info, localPubKey...)
+	}
+	secret := secp256k1.GenerateSharedSecret(privKey, remotePubKey)
+	kdf := hkdf.New(sha256.New, secret, salt, info)
+
+	// Derive the length and payload keys for the initiator followed by the
+	// responder.
+	var keys [4][chacha20poly1305.KeySize]byte
+	for i := range keys {
+		if _, err := io.ReadFull(kdf, keys[i][:]); err != nil {
+			return nil, err
+		}
+	}
+	var nonce [chacha20.NonceSize]byte
+	newCiphers := func(lenKey, key []byte) (*chacha20.Cipher, cipher.AEAD, error) {
+		lenCipher, err := chacha20.NewUnauthenticatedCipher(lenKey, nonce[:])
+		if err != nil {
+			return nil, nil, err
+		}
+		aead, err := chacha20poly1305.New(key)
+		if err != nil {
+			return nil, nil, err
+		}
+		return lenCipher, aead, nil
+	}
+	initLenCipher, initAEAD, err := newCiphers(keys[0][:], keys[1][:])
+	if err != nil {
+		return nil, err
+	}
+	respLenCipher, respAEAD, err := newCiphers(keys[2][:], keys[3][:])
+	if err != nil {
+		return nil, err
+	}
+
+	if initiator {
+		return &v2Transport{
+			sendLenCipher: initLenCipher,
+			sendAEAD:      initAEAD,
+			recvLenCipher: respLenCipher,
+			recvAEAD:      respAEAD,
+		}, nil
+	}
+	return &v2Transport{
+		sendLenCipher: respLenCipher,
+		sendAEAD:      respAEAD,
+		recvLenCipher: initLenCipher,
+		recvAEAD:      initAEAD,
+	}, nil
+}
+
+// readV2PubKey reads the remaining bytes of an ephemeral public key from r and
+// parses the full public key.  The provided prefix contains any bytes of the
+// key that were already read.
+func readV2PubKey(r io.Reader, prefix []byte) (*secp256k1.PublicKey, error) {
+	var pubKeyBytes [v2PubKeySize]byte
+	n := copy(pubKeyBytes[:], prefix)
+	if _, err := io.ReadFull(r, pubKeyBytes[n:]); err != nil {
+		return nil, err
+	}
+	pubKey, err := secp256k1.ParsePubKey(pubKeyBytes[:])
+	if err != nil {
+		return nil, fmt.Errorf("invalid v2 transport public key: %w", err)
+	}
+	return pubKey, nil
+}
+
+// initiateV2Transport performs the handshake for the peer that initiated the
+// connection and returns the resulting v2 transport session.  The ephemeral
+// public key of the local peer is sent first followed by waiting for the
+// ephemeral public key of the remote peer.
+func initiateV2Transport(rw io.ReadWriter, net wire.CurrencyNet) (*v2Transport, error) {
+	privKey, err := secp256k1.GeneratePrivateKey()
+	if err != nil {
+		return nil, err
+	}
+	pubKey := privKey.PubKey().SerializeCompressed()
+	if _, err := rw.Write(pubKey); err != nil {
+		return nil, err
+	}
+
+	remotePubKey, err := readV2PubKey(rw, nil)
+	if err != nil {
+		return nil, err
+	}
+	return newV2Transport(privKey, pubKey, remotePubKey, true, net)
+}
+
+// acceptV2Transport performs the handshake for the peer that accepted the
+// connection and returns the resulting v2 transport session.
+//
+// Since peers that do not support the v2 transport immediately send a version
+// message, the first bytes are inspected to determine which transport the
+// remote peer is using.  When they match the start of a version message, no
+// session is returned and the bytes that were read are returned instead so
+// the caller can continue with the v1 transport.
+func acceptV2Transport(rw io.ReadWriter, net wire.CurrencyNet) (*v2Transport, []byte, error) {
+	var prefix [v1PrefixSize]byte
+	if _, err := io.ReadFull(rw, prefix[:]); err != nil {
+		return nil, nil, err
+	}
+	if prefix == v1Prefix(net) {
+		return nil, prefix[:], nil
+	}
+
+	remotePubKey, err := readV2PubKey(rw, prefix[:])
+	if err != nil {
+		return nil, nil, err
+	}
+	privKey, err := secp256k1.GeneratePrivateKey()
+	if err != nil {
+		return nil, nil, err
+	}
+	pubKey := privKey.PubKey().SerializeCompressed()
+	if _, err := rw.Write(pubKey); err != nil {
+		return nil, nil, err
+	}
+	t, err := newV2Transport(privKey, pubKey, remotePubKey, false, net)
+	if err != nil {
+		return nil, nil, err
+	}
+	return t, nil, nil
+}
+
+// nextNonce returns the AEAD nonce for the provided packet counter and
+// increments the counter.
+func nextNonce(counter *uint64) []byte {
+	var nonce [chacha20poly1305.NonceSize]byte
+	binary.LittleEndian.PutUint64(nonce[4:], *counter)
+	*counter++
+	return nonce[:]
+}
+
+// readMessage reads the next packet from r and decodes the wire message it
+// contains.  It returns the number of bytes read from r along with the decoded
+// message and its raw payload bytes.
+func (t *v2Transport) readMessage(r io.Reader, pver uint32,
+	net wire.CurrencyNet) (int, wire.Message, []byte, error) {
+
+	var encLen [v2LengthFieldSize]byte
+	n, err := io.ReadFull(r, encLen[:])
+	if err != nil {
+		return n, nil, nil, err
+	}
+	var plainLen [v2LengthFieldSize]byte
+	t.recvLenCipher.XORKeyStream(plainLen[:], encLen[:])
+	length := binary.LittleEndian.Uint32(plainLen[:])
+	if length > v2MaxPacketPayload {
+		str := fmt.Sprintf("v2 transport packet length %d exceeds the max "+
+			"allowed length %d", length, v2MaxPacketPayload)
+		return n, nil, nil, errors.New(str)
+	}
+
+	packet := make([]byte, int(length)+chacha20poly1305.Overhead)
+	read, err := io.ReadFull(r, packet)
+	n += read
+	if err != nil {
+		return n, nil, nil, err
+	}
+	plaintext, err := t.recvAEAD.Open(packet[:0], nextNonce(&t.recvNonce),
+		packet, encLen[:])
+	if err != nil {
+		return n, nil, nil, errors.New("v2 transport packet failed " +
+			"authentication")
+	}
+
+	msgReader := bytes.NewReader(plaintext)
+	_, msg, buf, err := wire.ReadMessageN(msgReader, pver, net)
+	if err != nil {
+		return n, nil, nil, err
+	}
+	if msgReader.Len() != 0 {
+		str := fmt.Sprintf("v2 transport packet contains %d bytes after "+
+			"the %s message", msgReader.Len(), msg.Command())
+		return n, nil, nil, errors.New(str)
+	}
+	return n, msg, buf, nil
+}
+
+// writeMessage encodes the provided wire message into a single packet and
+// writes it to w.  It returns the number of bytes written to w.
+func (t *v2Transport) writeMessage(w io.Writer, msg wire.Message, pver uint32,
+	net wire.CurrencyNet) (int, error) {
+
+	var buf bytes.Buffer
+	if _, err := wire.WriteMessageN(&buf, msg, pver, net); err != nil {
+		return 0, err
+	}
+	plaintext := buf.Bytes()
+
+	var encLen [v2LengthFieldSize]byte
+	binary.LittleEndian.PutUint32(encLen[:], uint32(len(plaintext)))
+	t.sendLenCipher.XORKeyStream(encLen[:], encLen[:])
+	packet := make([]byte, 0, v2LengthFieldSize+len(plaintext)+
+		chacha20poly1305.Overhead)
+	packet = append(packet, encLen[:]...)
+	packet = t.sendAEAD.Seal(packet, nextNonce(&t.sendNonce), plaintext,
+		encLen[:])
+	return w.Write(packet)
+}
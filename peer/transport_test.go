@@ -0,0 +1,404 @@
+// Copyright (c) 2023 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package peer
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/EXCCoin/exccd/wire"
+)
+
+// v2TransportPair performs the v2 transport handshake over a fake connection
+// using the provided networks for the initiator and responder, respectively,
+// and returns the resulting sessions along with the connections.
+func v2TransportPair(initNet, respNet wire.CurrencyNet) (*v2Transport, *v2Transport, *conn, *conn, error) {
+	initConn, respConn := pipe(&conn{}, &conn{})
+
+	type result struct {
+		t   *v2Transport
+		err error
+	}
+	respResult := make(chan result, 1)
+	go func() {
+		t, prefix, err := acceptV2Transport(respConn, respNet)
+		if err == nil && t == nil {
+			err = errors.New("v1 transport detected")
+		}
+		if prefix != nil {
+			err = errors.New("unexpected v1 prefix")
+		}
+		respResult <- result{t, err}
+	}()
+
+	initT, err := initiateV2Transport(initConn, initNet)
+	if err != nil {
+		return nil, nil, nil, nil, err
+	}
+	res := <-respResult
+	if res.err != nil {
+		return nil, nil, nil, nil, res.err
+	}
+	return initT, res.t, initConn, respConn, nil
+}
+
+// TestV2Transport ensures wire messages written via one side of a v2 transport
+// session are read back by the other side in both directions, that the data
+// sent over the connection is encrypted, and that tampered packets are
+// rejected.
+func TestV2Transport(t *testing.T) {
+	const pver = MaxProtocolVersion
+	initT, respT, initConn, respConn, err := v2TransportPair(wire.MainNet,
+		wire.MainNet)
+	if err != nil {
+		t.Fatalf("handshake failed: %v", err)
+	}
+
+	msgs := []wire.Message{
+		wire.NewMsgPing(123123),
+		wire.NewMsgPong(321321),
+		wire.NewMsgVerAck(),
+		wire.NewMsgGetAddr(),
+	}
+	for i, msg := range msgs {
+		// Alternate the direction the message is sent.
+		sendT, recvT := initT, respT
+		w, r := initConn.Writer, respConn.Reader
+		if i%2 == 1 {
+			sendT, recvT = respT, initT
+			w, r = respConn.Writer, initConn.Reader
+		}
+
+		// Capture the data sent over the connection while it is being
+		// read by the other side.
+		var sent bytes.Buffer
+		writeErr := make(chan error, 1)
+		go func() {
+			_, err := sendT.writeMessage(io.MultiWriter(&sent, w), msg, pver,
+				wire.MainNet)
+			writeErr <- err
+		}()
+		n, gotMsg, _, err := recvT.readMessage(r, pver, wire.MainNet)
+		if err != nil {
+			t.Fatalf("#%d: unexpected read error: %v", i, err)
+		}
+		if err := <-writeErr; err != nil {
+			t.Fatalf("#%d: unexpected write error: %v", i, err)
+		}
+		if !reflect.DeepEqual(gotMsg, msg) {
+			t.Fatalf("#%d: mismatched message -- got %v, want %v", i,
+				gotMsg, msg)
+		}
+		if n != sent.Len() {
+			t.Fatalf("#%d: mismatched bytes read -- got %d, want %d", i, n,
+				sent.Len())
+		}
+
+		// Ensure the command is not visible in the data that was sent.
+		if bytes.Contains(sent.Bytes(), []byte(msg.Command())) {
+			t.Fatalf("#%d: command %q sent in plaintext", i, msg.Command())
+		}
+	}
+
+	// Ensure a packet that was modified in transit fails authentication.
+	var packet bytes.Buffer
+	_, err = initT.writeMessage(&packet, wire.NewMsgPing(1), pver, wire.MainNet)
+	if err != nil {
+		t.Fatalf("unexpected write error: %v", err)
+	}
+	tampered := packet.Bytes()
+	tampered[len(tampered)-1] ^= 0x01
+	_, _, _, err = respT.readMessage(bytes.NewReader(tampered), pver,
+		wire.MainNet)
+	if err == nil {
+		t.Fatal("tampered packet did not fail authentication")
+	}
+}
+
+// TestV2TransportNetMismatch ensures peers on different networks are not able
+// to communicate via the v2 transport since the session keys are bound to the
+// network.
+func TestV2TransportNetMismatch(t *testing.T) {
+	const pver = MaxProtocolVersion
+	initT, respT, _, _, err := v2TransportPair(wire.MainNet, wire.TestNet3)
+	if err != nil {
+		t.Fatalf("handshake failed: %v", err)
+	}
+
+	var packet bytes.Buffer
+	_, err = initT.writeMessage(&packet, wire.NewMsgVerAck(), pver,
+		wire.MainNet)
+	if err != nil {
+		t.Fatalf("unexpected write error: %v", err)
+	}
+	_, _, _, err = respT.readMessage(&packet, pver, wire.TestNet3)
+	if err == nil {
+		t.Fatal("read of packet from other network did not fail")
+	}
+}
+
+// TestV2TransportV1Detection ensures a peer accepting a connection detects the
+// remote peer is using the v1 transport and returns the bytes that were read
+// so the version message can still be decoded.
+func TestV2TransportV1Detection(t *testing.T) {
+	const pver = MaxProtocolVersion
+	me := wire.NewNetAddressIPPort(net.ParseIP("10.0.0.1"), 9108, 0)
+	you := wire.NewNetAddressIPPort(net.ParseIP("10.0.0.2"), 9108, 0)
+	version := wire.NewMsgVersion(me, you, 123, 0)
+
+	var buf bytes.Buffer
+	if err := wire.WriteMessage(&buf, version, pver, wire.MainNet); err != nil {
+		t.Fatalf("unexpected write error: %v", err)
+	}
+	rw := struct {
+		io.Reader
+		io.Writer
+	}{&buf, io.Discard}
+	tr, prefix, err := acceptV2Transport(rw, wire.MainNet)
+	if err != nil {
+		t.Fatalf("unexpected accept error: %v", err)
+	}
+	if tr != nil {
+		t.Fatal("v2 transport negotiated with v1 peer")
+	}
+
+	r := io.MultiReader(bytes.NewReader(prefix), &buf)
+	_, msg, _, err := wire.ReadMessageN(r, pver, wire.MainNet)
+	if err != nil {
+		t.Fatalf("unexpected read error: %v", err)
+	}
+	gotVersion, ok := msg.(*wire.MsgVersion)
+	if !ok {
+		t.Fatalf("unexpected message type %T", msg)
+	}
+	if gotVersion.Nonce != version.Nonce {
+		t.Fatalf("mismatched version nonce -- got %d, want %d",
+			gotVersion.Nonce, version.Nonce)
+	}
+}
+
+// TestPeerV2Transport ensures peers negotiate the v2 transport when both of
+// them support it and fall back to the v1 transport otherwise.
+func TestPeerV2Transport(t *testing.T) {
+	tests := []struct {
+		name        string
+		inServices  wire.ServiceFlag
+		outServices wire.ServiceFlag
+		advertised  bool
+		wantV2      bool
+	}{{
+		name:        "both peers support v2",
+		inServices:  wire.SFNodeP2PV2,
+		outServices: wire.SFNodeP2PV2,
+		advertised:  true,
+		wantV2:      true,
+	}, {
+		name:        "remote support not advertised",
+		inServices:  wire.SFNodeP2PV2,
+		outServices: wire.SFNodeP2PV2,
+		advertised:  false,
+		wantV2:      false,
+	}, {
+		name:        "outbound peer does not support v2",
+		inServices:  wire.SFNodeP2PV2,
+		outServices: 0,
+		advertised:  true,
+		wantV2:      false,
+	}, {
+		name:        "neither peer supports v2",
+		inServices:  0,
+		outServices: 0,
+		advertised:  false,
+		wantV2:      false,
+	}}
+
+	for _, test := range tests {
+		verack := make(chan struct{}, 2)
+		pong := make(chan *wire.MsgPong, 1)
+		newConfig := func(services wire.ServiceFlag) *Config {
+			return &Config{
+				Listeners: MessageListeners{
+					OnVerAck: func(p *Peer, msg *wire.MsgVerAck) {
+						verack <- struct{}{}
+					},
+					OnPong: func(p *Peer, msg *wire.MsgPong) {
+						pong <- msg
+					},
+				},
+				HostToNetAddress: func(host string, port uint16, services wire.ServiceFlag) (*wire.NetAddress, error) {
+					if test.advertised {
+						services |= wire.SFNodeP2PV2
+					}
+					return wire.NewNetAddressIPPort(net.ParseIP(host), port,
+						services), nil
+				},
+				UserAgentName:    "peer",
+				UserAgentVersion: "1.0",
+				Net:              wire.MainNet,
+				Services:         services,
+			}
+		}
+
+		inConn, outConn := pipe(
+			&conn{laddr: "10.0.0.1:9108", raddr: "10.0.0.2:9108"},
+			&conn{laddr: "10.0.0.2:9108", raddr: "10.0.0.1:9108"},
+		)
+		outPeer, err := NewOutboundPeer(newConfig(test.outServices),
+			inConn.laddr)
+		if err != nil {
+			t.Fatalf("%q: NewOutboundPeer: unexpected err: %v", test.name,
+				err)
+		}
+		outPeer.AssociateConnection(outConn)
+		inPeer := NewInboundPeer(newConfig(test.inServices))
+		inPeer.AssociateConnection(inConn)
+
+		// Wait for the veracks from the initial protocol version negotiation.
+		for i := 0; i < 2; i++ {
+			select {
+			case <-verack:
+			case <-time.After(time.Second):
+				t.Fatalf("%q: verack timeout", test.name)
+			}
+		}
+
+		if got := outPeer.V2Transport(); got != test.wantV2 {
+			t.Fatalf("%q: mismatched outbound v2 transport -- got %v, "+
+				"want %v", test.name, got, test.wantV2)
+		}
+		if got := inPeer.V2Transport(); got != test.wantV2 {
+			t.Fatalf("%q: mismatched inbound v2 transport -- got %v, "+
+				"want %v", test.name, got, test.wantV2)
+		}
+
+		// Ensure messages continue to flow after the handshake.
+		outPeer.QueueMessage(wire.NewMsgPing(42), nil)
+		select {
+		case msg := <-pong:
+			if msg.Nonce != 42 {
+				t.Fatalf("%q: mismatched pong nonce -- got %d, want 42",
+					test.name, msg.Nonce)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("%q: pong timeout", test.name)
+		}
+
+		outPeer.Disconnect()
+		inPeer.Disconnect()
+	}
+}
+
+// TestPeerV2TransportV1Only ensures that negotiating the v2 transport with a
+// remote peer that is advertised as supporting it, but only supports the v1
+// transport, is reported as failed and that connecting to the remote peer
+// again without advertising the v2 transport uses the v1 transport.
+func TestPeerV2TransportV1Only(t *testing.T) {
+	verack := make(chan struct{}, 2)
+	newConfig := func(services wire.ServiceFlag, advertised bool) *Config {
+		return &Config{
+			Listeners: MessageListeners{
+				OnVerAck: func(p *Peer, msg *wire.MsgVerAck) {
+					verack <- struct{}{}
+				},
+			},
+			HostToNetAddress: func(host string, port uint16, services wire.ServiceFlag) (*wire.NetAddress, error) {
+				if advertised {
+					services |= wire.SFNodeP2PV2
+				}
+				return wire.NewNetAddressIPPort(net.ParseIP(host), port,
+					services), nil
+			},
+			UserAgentName:    "peer",
+			UserAgentVersion: "1.0",
+			Net:              wire.MainNet,
+			Services:         services,
+		}
+	}
+
+	// Connect to a remote peer that only supports the v1 transport while it
+	// is advertised as supporting the v2 transport.
+	inConn, outConn := pipe(
+		&conn{laddr: "10.0.0.1:9108", raddr: "10.0.0.2:9108"},
+		&conn{laddr: "10.0.0.2:9108", raddr: "10.0.0.1:9108"},
+	)
+	outPeer, err := NewOutboundPeer(newConfig(wire.SFNodeP2PV2, true),
+		inConn.laddr)
+	if err != nil {
+		t.Fatalf("NewOutboundPeer: unexpected err: %v", err)
+	}
+	outPeer.AssociateConnection(outConn)
+	inPeer := NewInboundPeer(newConfig(0, false))
+	inPeer.AssociateConnection(inConn)
+
+	// The remote peer disconnects since it does not understand the v2
+	// handshake, so close its side of the connection as a real connection
+	// would be closed.
+	disconnected := make(chan struct{})
+	go func() {
+		inPeer.WaitForDisconnect()
+		close(disconnected)
+	}()
+	select {
+	case <-disconnected:
+	case <-time.After(time.Second):
+		t.Fatal("timeout waiting for remote peer disconnect")
+	}
+	inConn.Reader.(*io.PipeReader).Close()
+	inConn.Writer.(*io.PipeWriter).Close()
+
+	// Ensure the local peer disconnects and reports the failed negotiation.
+	disconnected = make(chan struct{})
+	go func() {
+		outPeer.WaitForDisconnect()
+		close(disconnected)
+	}()
+	select {
+	case <-disconnected:
+	case <-time.After(time.Second):
+		t.Fatal("timeout waiting for local peer disconnect")
+	}
+	if !outPeer.V2TransportFailed() {
+		t.Fatal("v2 transport negotiation not reported as failed")
+	}
+	if outPeer.V2Transport() {
+		t.Fatal("v2 transport reported as used after failed negotiation")
+	}
+
+	// Connect to the remote peer again without advertising the v2 transport
+	// and ensure the v1 transport is used.
+	inConn, outConn = pipe(
+		&conn{laddr: "10.0.0.1:9108", raddr: "10.0.0.2:9108"},
+		&conn{laddr: "10.0.0.2:9108", raddr: "10.0.0.1:9108"},
+	)
+	outPeer, err = NewOutboundPeer(newConfig(wire.SFNodeP2PV2, false),
+		inConn.laddr)
+	if err != nil {
+		t.Fatalf("NewOutboundPeer: unexpected err: %v", err)
+	}
+	outPeer.AssociateConnection(outConn)
+	inPeer = NewInboundPeer(newConfig(0, false))
+	inPeer.AssociateConnection(inConn)
+	for i := 0; i < 2; i++ {
+		select {
+		case <-verack:
+		case <-time.After(time.Second):
+			t.Fatal("verack timeout")
+		}
+	}
+	if outPeer.V2TransportFailed() {
+		t.Fatal("v2 transport negotiation reported as failed")
+	}
+	if outPeer.V2Transport() || inPeer.V2Transport() {
+		t.Fatal("v2 transport reported as used by v1 connection")
+	}
+
+	outPeer.Disconnect()
+	inPeer.Disconnect()
+}
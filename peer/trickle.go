@@ -0,0 +1,70 @@
+// Copyright (c) 2023 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package peer
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultTrickleInterval is the default mean duration between
+	// announcements of queued inventory to a peer that uses its own
+	// announcement schedule.
+	DefaultTrickleInterval = 2 * time.Second
+
+	// DefaultInboundTrickleInterval is the recommended mean duration between
+	// announcements of queued inventory for schedules shared by inbound peers.
+	// It is longer than the default interval since anyone is able to make
+	// inbound connections.
+	DefaultInboundTrickleInterval = 5 * time.Second
+
+	// DefaultLocalTrickleInterval is the default mean duration between
+	// announcements of inventory that originated locally.
+	DefaultLocalTrickleInterval = 500 * time.Millisecond
+)
+
+// poissonDelay returns a random delay drawn from an exponential distribution
+// with the provided mean.  Separating events by such delays results in a
+// Poisson process which means the time of the next event is independent of the
+// time of any previous events.
+func poissonDelay(mean time.Duration) time.Duration {
+	return time.Duration(rand.ExpFloat64() * float64(mean))
+}
+
+// TrickleSchedule provides a schedule of inventory announcement times that is
+// shared by multiple peers.  The announcement times form a Poisson process with
+// a configured mean interval.
+//
+// Sharing a schedule among peers, such as all inbound peers, ensures an
+// attacker that makes several connections does not learn about inventory any
+// faster or with any more timing information than it would with a single
+// connection.
+type TrickleSchedule struct {
+	mtx  sync.Mutex
+	mean time.Duration
+	next time.Time
+}
+
+// NewTrickleSchedule returns a new shared inventory announcement schedule with
+// the provided mean interval between announcements.
+func NewTrickleSchedule(mean time.Duration) *TrickleSchedule {
+	return &TrickleSchedule{mean: mean}
+}
+
+// Next returns the next announcement time after the provided time.  All callers
+// receive the same time until it has passed.
+//
+// This function is safe for concurrent access.
+func (s *TrickleSchedule) Next(now time.Time) time.Time {
+	s.mtx.Lock()
+	if !s.next.After(now) {
+		s.next = now.Add(poissonDelay(s.mean))
+	}
+	next := s.next
+	s.mtx.Unlock()
+	return next
+}
@@ -0,0 +1,270 @@
+// Copyright (c) 2023 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package peer
+
+import (
+	"math"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/EXCCoin/exccd/chaincfg/chainhash"
+	"github.com/EXCCoin/exccd/wire"
+)
+
+// TestPoissonDelay ensures the inventory announcement delays follow an
+// exponential distribution with the requested mean.
+func TestPoissonDelay(t *testing.T) {
+	const (
+		numSamples = 100000
+		mean       = time.Second
+	)
+
+	// Draw the samples as multiples of the mean.
+	samples := make([]float64, numSamples)
+	var sum float64
+	for i := range samples {
+		delay := poissonDelay(mean)
+		if delay < 0 {
+			t.Fatalf("negative delay %v", delay)
+		}
+		samples[i] = float64(delay) / float64(mean)
+		sum += samples[i]
+	}
+
+	// The standard deviation of an exponential distribution is equal to its
+	// mean, so the standard error of the sample mean is 1/sqrt(n).  Allow a
+	// deviation of over 6 standard errors to avoid spurious failures.
+	sampleMean := sum / numSamples
+	if math.Abs(sampleMean-1) > 0.02 {
+		t.Fatalf("mismatched sample mean -- got %.4f, want 1 +/- 0.02",
+			sampleMean)
+	}
+
+	// Ensure the samples fit the exponential distribution by calculating the
+	// Kolmogorov-Smirnov statistic, which is the maximum distance between the
+	// empirical distribution function of the samples and the cumulative
+	// distribution function of the exponential distribution.  The critical
+	// value for a significance level of 0.001 is approximately 1.95/sqrt(n).
+	sort.Float64s(samples)
+	var ksStat float64
+	for i, x := range samples {
+		cdf := 1 - math.Exp(-x)
+		lower := math.Abs(cdf - float64(i)/numSamples)
+		upper := math.Abs(float64(i+1)/numSamples - cdf)
+		ksStat = math.Max(ksStat, math.Max(lower, upper))
+	}
+	critical := 1.95 / math.Sqrt(numSamples)
+	if ksStat > critical {
+		t.Fatalf("samples do not fit an exponential distribution -- KS "+
+			"statistic %.5f exceeds critical value %.5f", ksStat, critical)
+	}
+
+	// Ensure the delays are memoryless by checking that the fraction of the
+	// delays that exceed twice the mean out of those that exceed the mean is
+	// the same as the fraction of all delays that exceed the mean, e^-1.
+	var overMean, overTwiceMean int
+	for _, x := range samples {
+		if x > 1 {
+			overMean++
+		}
+		if x > 2 {
+			overTwiceMean++
+		}
+	}
+	conditional := float64(overTwiceMean) / float64(overMean)
+	if math.Abs(conditional-math.Exp(-1)) > 0.02 {
+		t.Fatalf("delays are not memoryless -- got P(X > 2m | X > m) = "+
+			"%.4f, want %.4f +/- 0.02", conditional, math.Exp(-1))
+	}
+}
+
+// TestTrickleSchedule ensures shared inventory announcement schedules return
+// the same time to all callers until it passes.
+func TestTrickleSchedule(t *testing.T) {
+	schedule := NewTrickleSchedule(time.Second)
+	now := time.Unix(1600000000, 0)
+	next := schedule.Next(now)
+	if next.Before(now) {
+		t.Fatalf("next announcement %v is before %v", next, now)
+	}
+
+	// Ensure all callers receive the same time before it passes.
+	for i := 0; i < 10; i++ {
+		if got := schedule.Next(now); !got.Equal(next) {
+			t.Fatalf("mismatched shared announcement time -- got %v, "+
+				"want %v", got, next)
+		}
+	}
+
+	// Ensure a new time is chosen once the previous one has passed.  It is
+	// technically possible for the new delay to be zero, so try a few times.
+	for i := 0; i < 10; i++ {
+		now = next
+		next = schedule.Next(now)
+		if next.After(now) {
+			return
+		}
+	}
+	t.Fatal("shared announcement time did not advance")
+}
+
+// TestSharedLocalTrickleSchedule ensures peers that share a local inventory
+// announcement schedule announce local inventory at the same times while peers
+// without one use independent times.
+func TestSharedLocalTrickleSchedule(t *testing.T) {
+	schedule := NewTrickleSchedule(time.Hour)
+	newPeer := func(schedule *TrickleSchedule) *Peer {
+		return NewInboundPeer(&Config{
+			Net:                  wire.MainNet,
+			LocalTrickleInterval: time.Hour,
+			LocalTrickleSchedule: schedule,
+		})
+	}
+	now := time.Unix(1600000000, 0)
+	want := schedule.Next(now)
+	for i := 0; i < 5; i++ {
+		got := newPeer(schedule).nextLocalTrickleTime(now)
+		if !got.Equal(want) {
+			t.Fatalf("mismatched shared local announcement time -- got "+
+				"%v, want %v", got, want)
+		}
+	}
+
+	// Ensure peers without a shared schedule do not all use the same time.
+	// It is technically possible for the independent delays to match, so
+	// try a few times.
+	for i := 0; i < 10; i++ {
+		a := newPeer(nil).nextLocalTrickleTime(now)
+		b := newPeer(nil).nextLocalTrickleTime(now)
+		if !a.Equal(b) {
+			return
+		}
+	}
+	t.Fatal("independent local announcement times are always the same")
+}
+
+// TestPeerTrickleInventory ensures inventory queued via QueueInventory and
+// QueueLocalInventory is announced to the remote peer after the respective
+// delays.
+func TestPeerTrickleInventory(t *testing.T) {
+	invs := make(chan *wire.MsgInv, 10)
+	verack := make(chan struct{}, 2)
+	newConfig := func(trickleInterval time.Duration) *Config {
+		return &Config{
+			Listeners: MessageListeners{
+				OnVerAck: func(p *Peer, msg *wire.MsgVerAck) {
+					verack <- struct{}{}
+				},
+				OnInv: func(p *Peer, msg *wire.MsgInv) {
+					invs <- msg
+				},
+			},
+			UserAgentName:        "peer",
+			UserAgentVersion:     "1.0",
+			Net:                  wire.MainNet,
+			TrickleInterval:      trickleInterval,
+			LocalTrickleInterval: time.Millisecond,
+		}
+	}
+
+	// Create a pair of peers where the outbound peer uses a schedule that
+	// will not announce the regular inventory during the test and the inbound
+	// peer uses short shared schedules for both kinds of inventory that take
+	// precedence over its intervals.
+	inConn, outConn := pipe(
+		&conn{laddr: "10.0.0.1:9108", raddr: "10.0.0.2:9108"},
+		&conn{laddr: "10.0.0.2:9108", raddr: "10.0.0.1:9108"},
+	)
+	outPeer, err := NewOutboundPeer(newConfig(time.Hour), inConn.laddr)
+	if err != nil {
+		t.Fatalf("NewOutboundPeer: unexpected err: %v", err)
+	}
+	outPeer.AssociateConnection(outConn)
+	inCfg := newConfig(time.Hour)
+	inCfg.TrickleSchedule = NewTrickleSchedule(time.Millisecond)
+	inCfg.LocalTrickleInterval = time.Hour
+	inCfg.LocalTrickleSchedule = NewTrickleSchedule(time.Millisecond)
+	inPeer := NewInboundPeer(inCfg)
+	inPeer.AssociateConnection(inConn)
+	defer outPeer.Disconnect()
+	defer inPeer.Disconnect()
+	for i := 0; i < 2; i++ {
+		select {
+		case <-verack:
+		case <-time.After(time.Second):
+			t.Fatal("verack timeout")
+		}
+	}
+
+	// newInvVects returns the provided number of unique tx inventory vectors.
+	var nextHash byte
+	newInvVects := func(n int) []*wire.InvVect {
+		ivs := make([]*wire.InvVect, n)
+		for i := range ivs {
+			nextHash++
+			ivs[i] = wire.NewInvVect(wire.InvTypeTx, &chainhash.Hash{nextHash})
+		}
+		return ivs
+	}
+
+	// waitInv waits for the announcement of the provided inventory in any
+	// order.
+	waitInv := func(name string, want []*wire.InvVect) {
+		t.Helper()
+		remaining := make(map[wire.InvVect]struct{}, len(want))
+		for _, iv := range want {
+			remaining[*iv] = struct{}{}
+		}
+		for len(remaining) > 0 {
+			select {
+			case msg := <-invs:
+				for _, iv := range msg.InvList {
+					if _, ok := remaining[*iv]; !ok {
+						t.Fatalf("%s: unexpected inventory %v", name, iv)
+					}
+					delete(remaining, *iv)
+				}
+			case <-time.After(time.Second):
+				t.Fatalf("%s: timeout waiting for inventory", name)
+			}
+		}
+	}
+
+	// Ensure local inventory is announced by the outbound peer even though
+	// its regular schedule does not announce anything during the test.
+	local := newInvVects(5)
+	for _, iv := range local {
+		outPeer.QueueLocalInventory(iv)
+	}
+	waitInv("local inventory", local)
+
+	// Ensure regular inventory is announced by the inbound peer via the
+	// shared schedule.
+	regular := newInvVects(5)
+	for _, iv := range regular {
+		inPeer.QueueInventory(iv)
+	}
+	waitInv("shared schedule inventory", regular)
+
+	// Ensure local inventory is announced by the inbound peer via the shared
+	// local schedule.
+	sharedLocal := newInvVects(5)
+	for _, iv := range sharedLocal {
+		inPeer.QueueLocalInventory(iv)
+	}
+	waitInv("shared local schedule inventory", sharedLocal)
+
+	// Ensure regular inventory queued on the outbound peer is not announced
+	// before its schedule while inventory it is already known to have is
+	// never queued.
+	outPeer.QueueInventory(newInvVects(1)[0])
+	outPeer.QueueLocalInventory(local[0])
+	select {
+	case msg := <-invs:
+		t.Fatalf("unexpected inventory announcement %v", msg.InvList)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
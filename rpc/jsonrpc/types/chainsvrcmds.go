@@ -9,6 +9,9 @@
 package types
 
 import (
+	"encoding/json"
+	"strconv"
+
 	"github.com/EXCCoin/exccd/dcrjson/v4"
 )
 
@@ -45,6 +48,19 @@ const (
 	NDisconnect NodeSubCmd = "disconnect"
 )
 
+// SetBanSubCmd defines the type used in the setban JSON-RPC command for the
+// sub command field.
+type SetBanSubCmd string
+
+const (
+	// SBAdd indicates the specified IP address or subnet should be banned.
+	SBAdd SetBanSubCmd = "add"
+
+	// SBRemove indicates the ban for the specified IP address or subnet
+	// should be removed.
+	SBRemove SetBanSubCmd = "remove"
+)
+
 // AddNodeCmd defines the addnode JSON-RPC command.
 type AddNodeCmd struct {
 	Addr   string
@@ -60,6 +76,15 @@ func NewAddNodeCmd(addr string, subCmd AddNodeSubCmd) *AddNodeCmd {
 	}
 }
 
+// ClearBannedCmd defines the clearbanned JSON-RPC command.
+type ClearBannedCmd struct{}
+
+// NewClearBannedCmd returns a new instance which can be used to issue a
+// clearbanned JSON-RPC command.
+func NewClearBannedCmd() *ClearBannedCmd {
+	return &ClearBannedCmd{}
+}
+
 // SStxInput represents the inputs to an SStx transaction. Specifically a
 // transactionsha and output number pair, along with the output amounts.
 type SStxInput struct {
@@ -185,6 +210,32 @@ func NewDecodeScriptCmd(hexScript string) *DecodeScriptCmd {
 	}
 }
 
+// DisableIndexCmd defines the disableindex JSON-RPC command.
+type DisableIndexCmd struct {
+	Name string
+}
+
+// NewDisableIndexCmd returns a new instance which can be used to issue a
+// disableindex JSON-RPC command.
+func NewDisableIndexCmd(name string) *DisableIndexCmd {
+	return &DisableIndexCmd{
+		Name: name,
+	}
+}
+
+// EnableIndexCmd defines the enableindex JSON-RPC command.
+type EnableIndexCmd struct {
+	Name string
+}
+
+// NewEnableIndexCmd returns a new instance which can be used to issue an
+// enableindex JSON-RPC command.
+func NewEnableIndexCmd(name string) *EnableIndexCmd {
+	return &EnableIndexCmd{
+		Name: name,
+	}
+}
+
 // EstimateFeeCmd defines the estimatefee JSON-RPC command.
 type EstimateFeeCmd struct {
 	NumBlocks int64
@@ -361,6 +412,81 @@ func NewGetAddedNodeInfoCmd(dns bool, node *string) *GetAddedNodeInfoCmd {
 	}
 }
 
+// GetAddressBalanceCmd defines the getaddressbalance JSON-RPC command.
+type GetAddressBalanceCmd struct {
+	Address string
+}
+
+// NewGetAddressBalanceCmd returns a new instance which can be used to issue a
+// getaddressbalance JSON-RPC command.
+func NewGetAddressBalanceCmd(address string) *GetAddressBalanceCmd {
+	return &GetAddressBalanceCmd{
+		Address: address,
+	}
+}
+
+// GetAddressDeltasCmd defines the getaddressdeltas JSON-RPC command.
+type GetAddressDeltasCmd struct {
+	Address        string
+	Skip           *int  `jsonrpcdefault:"0"`
+	Count          *int  `jsonrpcdefault:"100"`
+	IncludeMempool *bool `jsonrpcdefault:"false"`
+}
+
+// NewGetAddressDeltasCmd returns a new instance which can be used to issue a
+// getaddressdeltas JSON-RPC command.
+//
+// The parameters which are pointers indicate they are optional.  Passing nil
+// for optional parameters will use the default value.
+func NewGetAddressDeltasCmd(address string, skip, count *int, includeMempool *bool) *GetAddressDeltasCmd {
+	return &GetAddressDeltasCmd{
+		Address:        address,
+		Skip:           skip,
+		Count:          count,
+		IncludeMempool: includeMempool,
+	}
+}
+
+// GetAddressUtxosCmd defines the getaddressutxos JSON-RPC command.
+type GetAddressUtxosCmd struct {
+	Address string
+	Skip    *int `jsonrpcdefault:"0"`
+	Count   *int `jsonrpcdefault:"100"`
+}
+
+// NewGetAddressUtxosCmd returns a new instance which can be used to issue a
+// getaddressutxos JSON-RPC command.
+//
+// The parameters which are pointers indicate they are optional.  Passing nil
+// for optional parameters will use the default value.
+func NewGetAddressUtxosCmd(address string, skip, count *int) *GetAddressUtxosCmd {
+	return &GetAddressUtxosCmd{
+		Address: address,
+		Skip:    skip,
+		Count:   count,
+	}
+}
+
+// GetAddressTicketsCmd defines the getaddresstickets JSON-RPC command.
+type GetAddressTicketsCmd struct {
+	Address string
+	Skip    *int `jsonrpcdefault:"0"`
+	Count   *int `jsonrpcdefault:"100"`
+}
+
+// NewGetAddressTicketsCmd returns a new instance which can be used to issue a
+// getaddresstickets JSON-RPC command.
+//
+// The parameters which are pointers indicate they are optional.  Passing nil
+// for optional parameters will use the default value.
+func NewGetAddressTicketsCmd(address string, skip, count *int) *GetAddressTicketsCmd {
+	return &GetAddressTicketsCmd{
+		Address: address,
+		Skip:    skip,
+		Count:   count,
+	}
+}
+
 // GetBestBlockCmd defines the getbestblock JSON-RPC command.
 type GetBestBlockCmd struct{}
 
@@ -445,6 +571,45 @@ func NewGetBlockHeaderCmd(hash string, verbose *bool) *GetBlockHeaderCmd {
 	}
 }
 
+// HashOrHeight identifies a block by either its hash or its height in the main
+// chain.  It may be provided as either a JSON string or a JSON number.
+type HashOrHeight string
+
+// UnmarshalJSON unmarshals a block hash provided as a JSON string or a block
+// height provided as either a JSON string or a JSON number.
+func (h *HashOrHeight) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err == nil {
+		*h = HashOrHeight(s)
+		return nil
+	}
+
+	var height int64
+	if err := json.Unmarshal(b, &height); err != nil {
+		return err
+	}
+	*h = HashOrHeight(strconv.FormatInt(height, 10))
+	return nil
+}
+
+// GetBlockStatsCmd defines the getblockstats JSON-RPC command.
+type GetBlockStatsCmd struct {
+	HashOrHeight HashOrHeight
+	Fields       *[]string
+}
+
+// NewGetBlockStatsCmd returns a new instance which can be used to issue a
+// getblockstats JSON-RPC command.
+//
+// The parameters which are pointers indicate they are optional.  Passing nil
+// for optional parameters will use the default value.
+func NewGetBlockStatsCmd(hashOrHeight HashOrHeight, fields *[]string) *GetBlockStatsCmd {
+	return &GetBlockStatsCmd{
+		HashOrHeight: hashOrHeight,
+		Fields:       fields,
+	}
+}
+
 // GetBlockSubsidyCmd defines the getblocksubsidy JSON-RPC command.
 type GetBlockSubsidyCmd struct {
 	Height int64
@@ -460,6 +625,21 @@ func NewGetBlockSubsidyCmd(height int64, voters uint16) *GetBlockSubsidyCmd {
 	}
 }
 
+// GetCFilterHeadersCmd defines the getcfilterheaders JSON-RPC command.
+type GetCFilterHeadersCmd struct {
+	StartHeight int64
+	Count       uint32
+}
+
+// NewGetCFilterHeadersCmd returns a new instance which can be used to issue a
+// getcfilterheaders JSON-RPC command.
+func NewGetCFilterHeadersCmd(startHeight int64, count uint32) *GetCFilterHeadersCmd {
+	return &GetCFilterHeadersCmd{
+		StartHeight: startHeight,
+		Count:       count,
+	}
+}
+
 // GetCFilterV2Cmd defines the getcfilterv2 JSON-RPC command.
 type GetCFilterV2Cmd struct {
 	BlockHash string
@@ -473,6 +653,21 @@ func NewGetCFilterV2Cmd(hash string) *GetCFilterV2Cmd {
 	}
 }
 
+// GetCFilterV2RangeCmd defines the getcfilterv2range JSON-RPC command.
+type GetCFilterV2RangeCmd struct {
+	StartHeight int64
+	Count       uint32
+}
+
+// NewGetCFilterV2RangeCmd returns a new instance which can be used to issue a
+// getcfilterv2range JSON-RPC command.
+func NewGetCFilterV2RangeCmd(startHeight int64, count uint32) *GetCFilterV2RangeCmd {
+	return &GetCFilterV2RangeCmd{
+		StartHeight: startHeight,
+		Count:       count,
+	}
+}
+
 // GetChainTipsCmd defines the getchaintips JSON-RPC command.
 type GetChainTipsCmd struct{}
 
@@ -536,6 +731,19 @@ func NewGetHashesPerSecCmd() *GetHashesPerSecCmd {
 	return &GetHashesPerSecCmd{}
 }
 
+// GetIndexInfoCmd defines the getindexinfo JSON-RPC command.
+type GetIndexInfoCmd struct {
+	Name *string
+}
+
+// NewGetIndexInfoCmd returns a new instance which can be used to issue a
+// getindexinfo JSON-RPC command.
+func NewGetIndexInfoCmd(name *string) *GetIndexInfoCmd {
+	return &GetIndexInfoCmd{
+		Name: name,
+	}
+}
+
 // GetInfoCmd defines the getinfo JSON-RPC command.
 type GetInfoCmd struct{}
 
@@ -587,6 +795,15 @@ func NewGetNetworkInfoCmd() *GetNetworkInfoCmd {
 	return &GetNetworkInfoCmd{}
 }
 
+// GetNetMsgStatsCmd defines the getnetmsgstats JSON-RPC command.
+type GetNetMsgStatsCmd struct{}
+
+// NewGetNetMsgStatsCmd returns a new instance which can be used to issue a
+// getnetmsgstats JSON-RPC command.
+func NewGetNetMsgStatsCmd() *GetNetMsgStatsCmd {
+	return &GetNetMsgStatsCmd{}
+}
+
 // GetNetTotalsCmd defines the getnettotals JSON-RPC command.
 type GetNetTotalsCmd struct{}
 
@@ -689,6 +906,21 @@ func NewGetRawTransactionCmd(txHash string, verbose *int) *GetRawTransactionCmd
 	}
 }
 
+// GetSpendingTxCmd defines the getspendingtx JSON-RPC command.
+type GetSpendingTxCmd struct {
+	Txid string
+	Vout uint32
+}
+
+// NewGetSpendingTxCmd returns a new instance which can be used to issue a
+// getspendingtx JSON-RPC command.
+func NewGetSpendingTxCmd(txHash string, vout uint32) *GetSpendingTxCmd {
+	return &GetSpendingTxCmd{
+		Txid: txHash,
+		Vout: vout,
+	}
+}
+
 // GetStakeDifficultyCmd is a type handling custom marshaling and
 // unmarshaling of getstakedifficulty JSON RPC commands.
 type GetStakeDifficultyCmd struct{}
@@ -729,6 +961,19 @@ func NewGetStakeVersionsCmd(hash string, count int32) *GetStakeVersionsCmd {
 	}
 }
 
+// GetTicketInfoCmd defines the getticketinfo JSON-RPC command.
+type GetTicketInfoCmd struct {
+	Hash string
+}
+
+// NewGetTicketInfoCmd returns a new instance which can be used to issue a
+// getticketinfo JSON-RPC command.
+func NewGetTicketInfoCmd(hash string) *GetTicketInfoCmd {
+	return &GetTicketInfoCmd{
+		Hash: hash,
+	}
+}
+
 // GetTicketPoolValueCmd defines the getticketpoolvalue JSON-RPC command.
 type GetTicketPoolValueCmd struct{}
 
@@ -761,12 +1006,30 @@ func NewGetTxOutCmd(txHash string, vout uint32, tree int8, includeMempool *bool)
 }
 
 // GetTxOutSetInfoCmd defines the gettxoutsetinfo JSON-RPC command.
-type GetTxOutSetInfoCmd struct{}
+type GetTxOutSetInfoCmd struct {
+	HashOrHeight *HashOrHeight
+	UseIndex     *bool `jsonrpcdefault:"true"`
+}
 
 // NewGetTxOutSetInfoCmd returns a new instance which can be used to issue a
 // gettxoutsetinfo JSON-RPC command.
-func NewGetTxOutSetInfoCmd() *GetTxOutSetInfoCmd {
-	return &GetTxOutSetInfoCmd{}
+//
+// The parameters which are pointers indicate they are optional.  Passing nil
+// for optional parameters will use the default value.
+func NewGetTxOutSetInfoCmd(hashOrHeight *HashOrHeight, useIndex *bool) *GetTxOutSetInfoCmd {
+	return &GetTxOutSetInfoCmd{
+		HashOrHeight: hashOrHeight,
+		UseIndex:     useIndex,
+	}
+}
+
+// GetUtxoCacheInfoCmd defines the getutxocacheinfo JSON-RPC command.
+type GetUtxoCacheInfoCmd struct{}
+
+// NewGetUtxoCacheInfoCmd returns a new instance which can be used to issue a
+// getutxocacheinfo JSON-RPC command.
+func NewGetUtxoCacheInfoCmd() *GetUtxoCacheInfoCmd {
+	return &GetUtxoCacheInfoCmd{}
 }
 
 // GetVoteInfoCmd returns voting results over a range of blocks.  Count
@@ -872,6 +1135,15 @@ func NewInvalidateBlockCmd(hash string) *InvalidateBlockCmd {
 	}
 }
 
+// ListBannedCmd defines the listbanned JSON-RPC command.
+type ListBannedCmd struct{}
+
+// NewListBannedCmd returns a new instance which can be used to issue a
+// listbanned JSON-RPC command.
+func NewListBannedCmd() *ListBannedCmd {
+	return &ListBannedCmd{}
+}
+
 // LiveTicketsCmd is a type handling custom marshaling and
 // unmarshaling of livetickets JSON RPC commands.
 type LiveTicketsCmd struct{}
@@ -962,6 +1234,46 @@ func NewSearchRawTransactionsCmd(address string, verbose, skip, count *int, vinE
 	}
 }
 
+// SearchScriptsMode defines how the data provided to the searchscripts command
+// is interpreted.
+type SearchScriptsMode string
+
+const (
+	// SearchScriptsScript searches for outputs with the provided hex-encoded
+	// script.
+	SearchScriptsScript SearchScriptsMode = "script"
+
+	// SearchScriptsHash searches for outputs with a script that has the
+	// provided BLAKE-256 hash.
+	SearchScriptsHash SearchScriptsMode = "hash"
+
+	// SearchScriptsNullData searches for null data outputs that carry data
+	// beginning with the provided hex-encoded prefix.
+	SearchScriptsNullData SearchScriptsMode = "nulldata"
+)
+
+// SearchScriptsCmd defines the searchscripts JSON-RPC command.
+type SearchScriptsCmd struct {
+	Data  string
+	Mode  *SearchScriptsMode `jsonrpcdefault:"\"script\""`
+	Skip  *int               `jsonrpcdefault:"0"`
+	Count *int               `jsonrpcdefault:"100"`
+}
+
+// NewSearchScriptsCmd returns a new instance which can be used to issue a
+// searchscripts JSON-RPC command.
+//
+// The parameters which are pointers indicate they are optional.  Passing nil
+// for optional parameters will use the default value.
+func NewSearchScriptsCmd(data string, mode *SearchScriptsMode, skip, count *int) *SearchScriptsCmd {
+	return &SearchScriptsCmd{
+		Data:  data,
+		Mode:  mode,
+		Skip:  skip,
+		Count: count,
+	}
+}
+
 // SendRawTransactionCmd defines the sendrawtransaction JSON-RPC command.
 type SendRawTransactionCmd struct {
 	HexTx         string
@@ -980,6 +1292,25 @@ func NewSendRawTransactionCmd(hexTx string, allowHighFees *bool) *SendRawTransac
 	}
 }
 
+// SetBanCmd defines the setban JSON-RPC command.
+type SetBanCmd struct {
+	SubCmd   SetBanSubCmd `jsonrpcusage:"\"add|remove\""`
+	Subnet   string
+	Duration *int64
+}
+
+// NewSetBanCmd returns a new instance which can be used to issue a setban
+// JSON-RPC command.  The duration is the number of seconds the ban lasts and
+// only applies when adding a ban.  Passing nil for the duration results in the
+// server's default ban duration.
+func NewSetBanCmd(subCmd SetBanSubCmd, subnet string, duration *int64) *SetBanCmd {
+	return &SetBanCmd{
+		SubCmd:   subCmd,
+		Subnet:   subnet,
+		Duration: duration,
+	}
+}
+
 // SetGenerateCmd defines the setgenerate JSON-RPC command.
 type SetGenerateCmd struct {
 	Generate     bool
@@ -1152,12 +1483,15 @@ func init() {
 	flags := dcrjson.UsageFlag(0)
 
 	dcrjson.MustRegister(Method("addnode"), (*AddNodeCmd)(nil), flags)
+	dcrjson.MustRegister(Method("clearbanned"), (*ClearBannedCmd)(nil), flags)
 	dcrjson.MustRegister(Method("createrawssrtx"), (*CreateRawSSRtxCmd)(nil), flags)
 	dcrjson.MustRegister(Method("createrawsstx"), (*CreateRawSStxCmd)(nil), flags)
 	dcrjson.MustRegister(Method("createrawtransaction"), (*CreateRawTransactionCmd)(nil), flags)
 	dcrjson.MustRegister(Method("debuglevel"), (*DebugLevelCmd)(nil), flags)
 	dcrjson.MustRegister(Method("decoderawtransaction"), (*DecodeRawTransactionCmd)(nil), flags)
 	dcrjson.MustRegister(Method("decodescript"), (*DecodeScriptCmd)(nil), flags)
+	dcrjson.MustRegister(Method("disableindex"), (*DisableIndexCmd)(nil), flags)
+	dcrjson.MustRegister(Method("enableindex"), (*EnableIndexCmd)(nil), flags)
 	dcrjson.MustRegister(Method("estimatefee"), (*EstimateFeeCmd)(nil), flags)
 	dcrjson.MustRegister(Method("estimatesmartfee"), (*EstimateSmartFeeCmd)(nil), flags)
 	dcrjson.MustRegister(Method("estimatestakediff"), (*EstimateStakeDiffCmd)(nil), flags)
@@ -1170,6 +1504,10 @@ func init() {
 	dcrjson.MustRegister(Method("existsmempooltxs"), (*ExistsMempoolTxsCmd)(nil), flags)
 	dcrjson.MustRegister(Method("generate"), (*GenerateCmd)(nil), flags)
 	dcrjson.MustRegister(Method("getaddednodeinfo"), (*GetAddedNodeInfoCmd)(nil), flags)
+	dcrjson.MustRegister(Method("getaddressbalance"), (*GetAddressBalanceCmd)(nil), flags)
+	dcrjson.MustRegister(Method("getaddressdeltas"), (*GetAddressDeltasCmd)(nil), flags)
+	dcrjson.MustRegister(Method("getaddresstickets"), (*GetAddressTicketsCmd)(nil), flags)
+	dcrjson.MustRegister(Method("getaddressutxos"), (*GetAddressUtxosCmd)(nil), flags)
 	dcrjson.MustRegister(Method("getbestblock"), (*GetBestBlockCmd)(nil), flags)
 	dcrjson.MustRegister(Method("getbestblockhash"), (*GetBestBlockHashCmd)(nil), flags)
 	dcrjson.MustRegister(Method("getblock"), (*GetBlockCmd)(nil), flags)
@@ -1177,8 +1515,11 @@ func init() {
 	dcrjson.MustRegister(Method("getblockcount"), (*GetBlockCountCmd)(nil), flags)
 	dcrjson.MustRegister(Method("getblockhash"), (*GetBlockHashCmd)(nil), flags)
 	dcrjson.MustRegister(Method("getblockheader"), (*GetBlockHeaderCmd)(nil), flags)
+	dcrjson.MustRegister(Method("getblockstats"), (*GetBlockStatsCmd)(nil), flags)
 	dcrjson.MustRegister(Method("getblocksubsidy"), (*GetBlockSubsidyCmd)(nil), flags)
+	dcrjson.MustRegister(Method("getcfilterheaders"), (*GetCFilterHeadersCmd)(nil), flags)
 	dcrjson.MustRegister(Method("getcfilterv2"), (*GetCFilterV2Cmd)(nil), flags)
+	dcrjson.MustRegister(Method("getcfilterv2range"), (*GetCFilterV2RangeCmd)(nil), flags)
 	dcrjson.MustRegister(Method("getchaintips"), (*GetChainTipsCmd)(nil), flags)
 	dcrjson.MustRegister(Method("getcoinsupply"), (*GetCoinSupplyCmd)(nil), flags)
 	dcrjson.MustRegister(Method("getconnectioncount"), (*GetConnectionCountCmd)(nil), flags)
@@ -1187,27 +1528,33 @@ func init() {
 	dcrjson.MustRegister(Method("getgenerate"), (*GetGenerateCmd)(nil), flags)
 	dcrjson.MustRegister(Method("gethashespersec"), (*GetHashesPerSecCmd)(nil), flags)
 	dcrjson.MustRegister(Method("getheaders"), (*GetHeadersCmd)(nil), flags)
+	dcrjson.MustRegister(Method("getindexinfo"), (*GetIndexInfoCmd)(nil), flags)
 	dcrjson.MustRegister(Method("getinfo"), (*GetInfoCmd)(nil), flags)
 	dcrjson.MustRegister(Method("getmempoolinfo"), (*GetMempoolInfoCmd)(nil), flags)
 	dcrjson.MustRegister(Method("getmininginfo"), (*GetMiningInfoCmd)(nil), flags)
 	dcrjson.MustRegister(Method("getnetworkinfo"), (*GetNetworkInfoCmd)(nil), flags)
+	dcrjson.MustRegister(Method("getnetmsgstats"), (*GetNetMsgStatsCmd)(nil), flags)
 	dcrjson.MustRegister(Method("getnettotals"), (*GetNetTotalsCmd)(nil), flags)
 	dcrjson.MustRegister(Method("getnetworkhashps"), (*GetNetworkHashPSCmd)(nil), flags)
 	dcrjson.MustRegister(Method("getpeerinfo"), (*GetPeerInfoCmd)(nil), flags)
 	dcrjson.MustRegister(Method("getrawmempool"), (*GetRawMempoolCmd)(nil), flags)
 	dcrjson.MustRegister(Method("getrawtransaction"), (*GetRawTransactionCmd)(nil), flags)
+	dcrjson.MustRegister(Method("getspendingtx"), (*GetSpendingTxCmd)(nil), flags)
 	dcrjson.MustRegister(Method("getstakedifficulty"), (*GetStakeDifficultyCmd)(nil), flags)
 	dcrjson.MustRegister(Method("getstakeversioninfo"), (*GetStakeVersionInfoCmd)(nil), flags)
 	dcrjson.MustRegister(Method("getstakeversions"), (*GetStakeVersionsCmd)(nil), flags)
+	dcrjson.MustRegister(Method("getticketinfo"), (*GetTicketInfoCmd)(nil), flags)
 	dcrjson.MustRegister(Method("getticketpoolvalue"), (*GetTicketPoolValueCmd)(nil), flags)
 	dcrjson.MustRegister(Method("gettreasurybalance"), (*GetTreasuryBalanceCmd)(nil), flags)
 	dcrjson.MustRegister(Method("gettreasuryspendvotes"), (*GetTreasurySpendVotesCmd)(nil), flags)
 	dcrjson.MustRegister(Method("gettxout"), (*GetTxOutCmd)(nil), flags)
 	dcrjson.MustRegister(Method("gettxoutsetinfo"), (*GetTxOutSetInfoCmd)(nil), flags)
+	dcrjson.MustRegister(Method("getutxocacheinfo"), (*GetUtxoCacheInfoCmd)(nil), flags)
 	dcrjson.MustRegister(Method("getvoteinfo"), (*GetVoteInfoCmd)(nil), flags)
 	dcrjson.MustRegister(Method("getwork"), (*GetWorkCmd)(nil), flags)
 	dcrjson.MustRegister(Method("help"), (*HelpCmd)(nil), flags)
 	dcrjson.MustRegister(Method("invalidateblock"), (*InvalidateBlockCmd)(nil), flags)
+	dcrjson.MustRegister(Method("listbanned"), (*ListBannedCmd)(nil), flags)
 	dcrjson.MustRegister(Method("livetickets"), (*LiveTicketsCmd)(nil), flags)
 	dcrjson.MustRegister(Method("missedtickets"), (*MissedTicketsCmd)(nil), flags)
 	dcrjson.MustRegister(Method("node"), (*NodeCmd)(nil), flags)
@@ -1215,7 +1562,9 @@ func init() {
 	dcrjson.MustRegister(Method("reconsiderblock"), (*ReconsiderBlockCmd)(nil), flags)
 	dcrjson.MustRegister(Method("regentemplate"), (*RegenTemplateCmd)(nil), flags)
 	dcrjson.MustRegister(Method("searchrawtransactions"), (*SearchRawTransactionsCmd)(nil), flags)
+	dcrjson.MustRegister(Method("searchscripts"), (*SearchScriptsCmd)(nil), flags)
 	dcrjson.MustRegister(Method("sendrawtransaction"), (*SendRawTransactionCmd)(nil), flags)
+	dcrjson.MustRegister(Method("setban"), (*SetBanCmd)(nil), flags)
 	dcrjson.MustRegister(Method("setgenerate"), (*SetGenerateCmd)(nil), flags)
 	dcrjson.MustRegister(Method("stop"), (*StopCmd)(nil), flags)
 	dcrjson.MustRegister(Method("submitblock"), (*SubmitBlockCmd)(nil), flags)
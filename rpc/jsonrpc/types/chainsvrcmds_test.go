@@ -41,6 +41,17 @@ func TestChainSvrCmds(t *testing.T) {
 			marshalled:   `{"jsonrpc":"1.0","method":"addnode","params":["127.0.0.1","remove"],"id":1}`,
 			unmarshalled: &AddNodeCmd{Addr: "127.0.0.1", SubCmd: ANRemove},
 		},
+		{
+			name: "clearbanned",
+			newCmd: func() (interface{}, error) {
+				return dcrjson.NewCmd(Method("clearbanned"))
+			},
+			staticCmd: func() interface{} {
+				return NewClearBannedCmd()
+			},
+			marshalled:   `{"jsonrpc":"1.0","method":"clearbanned","params":[],"id":1}`,
+			unmarshalled: &ClearBannedCmd{},
+		},
 		{
 			name: "createrawtransaction",
 			newCmd: func() (interface{}, error) {
@@ -129,6 +140,28 @@ func TestChainSvrCmds(t *testing.T) {
 			marshalled:   `{"jsonrpc":"1.0","method":"decodescript","params":["00",1],"id":1}`,
 			unmarshalled: &DecodeScriptCmd{HexScript: "00", Version: dcrjson.Uint16(1)},
 		},
+		{
+			name: "disableindex",
+			newCmd: func() (interface{}, error) {
+				return dcrjson.NewCmd(Method("disableindex"), "txindex")
+			},
+			staticCmd: func() interface{} {
+				return NewDisableIndexCmd("txindex")
+			},
+			marshalled:   `{"jsonrpc":"1.0","method":"disableindex","params":["txindex"],"id":1}`,
+			unmarshalled: &DisableIndexCmd{Name: "txindex"},
+		},
+		{
+			name: "enableindex",
+			newCmd: func() (interface{}, error) {
+				return dcrjson.NewCmd(Method("enableindex"), "addrindex")
+			},
+			staticCmd: func() interface{} {
+				return NewEnableIndexCmd("addrindex")
+			},
+			marshalled:   `{"jsonrpc":"1.0","method":"enableindex","params":["addrindex"],"id":1}`,
+			unmarshalled: &EnableIndexCmd{Name: "addrindex"},
+		},
 		{
 			name: "estimatefee",
 			newCmd: func() (interface{}, error) {
@@ -209,6 +242,83 @@ func TestChainSvrCmds(t *testing.T) {
 				Node: dcrjson.String("127.0.0.1"),
 			},
 		},
+		{
+			name: "getaddressbalance",
+			newCmd: func() (interface{}, error) {
+				return dcrjson.NewCmd(Method("getaddressbalance"), "1Address")
+			},
+			staticCmd: func() interface{} {
+				return NewGetAddressBalanceCmd("1Address")
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"getaddressbalance","params":["1Address"],"id":1}`,
+			unmarshalled: &GetAddressBalanceCmd{
+				Address: "1Address",
+			},
+		},
+		{
+			name: "getaddressdeltas",
+			newCmd: func() (interface{}, error) {
+				return dcrjson.NewCmd(Method("getaddressdeltas"), "1Address")
+			},
+			staticCmd: func() interface{} {
+				return NewGetAddressDeltasCmd("1Address", nil, nil, nil)
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"getaddressdeltas","params":["1Address"],"id":1}`,
+			unmarshalled: &GetAddressDeltasCmd{
+				Address:        "1Address",
+				Skip:           dcrjson.Int(0),
+				Count:          dcrjson.Int(100),
+				IncludeMempool: dcrjson.Bool(false),
+			},
+		},
+		{
+			name: "getaddressdeltas optional",
+			newCmd: func() (interface{}, error) {
+				return dcrjson.NewCmd(Method("getaddressdeltas"), "1Address", 5, 10, true)
+			},
+			staticCmd: func() interface{} {
+				return NewGetAddressDeltasCmd("1Address", dcrjson.Int(5),
+					dcrjson.Int(10), dcrjson.Bool(true))
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"getaddressdeltas","params":["1Address",5,10,true],"id":1}`,
+			unmarshalled: &GetAddressDeltasCmd{
+				Address:        "1Address",
+				Skip:           dcrjson.Int(5),
+				Count:          dcrjson.Int(10),
+				IncludeMempool: dcrjson.Bool(true),
+			},
+		},
+		{
+			name: "getaddressutxos",
+			newCmd: func() (interface{}, error) {
+				return dcrjson.NewCmd(Method("getaddressutxos"), "1Address", 5, 10)
+			},
+			staticCmd: func() interface{} {
+				return NewGetAddressUtxosCmd("1Address", dcrjson.Int(5),
+					dcrjson.Int(10))
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"getaddressutxos","params":["1Address",5,10],"id":1}`,
+			unmarshalled: &GetAddressUtxosCmd{
+				Address: "1Address",
+				Skip:    dcrjson.Int(5),
+				Count:   dcrjson.Int(10),
+			},
+		},
+		{
+			name: "getaddresstickets",
+			newCmd: func() (interface{}, error) {
+				return dcrjson.NewCmd(Method("getaddresstickets"), "1Address")
+			},
+			staticCmd: func() interface{} {
+				return NewGetAddressTicketsCmd("1Address", nil, nil)
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"getaddresstickets","params":["1Address"],"id":1}`,
+			unmarshalled: &GetAddressTicketsCmd{
+				Address: "1Address",
+				Skip:    dcrjson.Int(0),
+				Count:   dcrjson.Int(100),
+			},
+		},
 		{
 			name: "getbestblock",
 			newCmd: func() (interface{}, error) {
@@ -327,6 +437,36 @@ func TestChainSvrCmds(t *testing.T) {
 				Verbose: dcrjson.Bool(true),
 			},
 		},
+		{
+			name: "getblockstats hash",
+			newCmd: func() (interface{}, error) {
+				return dcrjson.NewCmd(Method("getblockstats"), "123")
+			},
+			staticCmd: func() interface{} {
+				return NewGetBlockStatsCmd("123", nil)
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"getblockstats","params":["123"],"id":1}`,
+			unmarshalled: &GetBlockStatsCmd{
+				HashOrHeight: "123",
+				Fields:       nil,
+			},
+		},
+		{
+			name: "getblockstats optional fields",
+			newCmd: func() (interface{}, error) {
+				return dcrjson.NewCmd(Method("getblockstats"), "100",
+					[]string{"totalfee", "txs"})
+			},
+			staticCmd: func() interface{} {
+				return NewGetBlockStatsCmd("100",
+					&[]string{"totalfee", "txs"})
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"getblockstats","params":["100",["totalfee","txs"]],"id":1}`,
+			unmarshalled: &GetBlockStatsCmd{
+				HashOrHeight: "100",
+				Fields:       &[]string{"totalfee", "txs"},
+			},
+		},
 		{
 			name: "getblocksubsidy",
 			newCmd: func() (interface{}, error) {
@@ -341,6 +481,20 @@ func TestChainSvrCmds(t *testing.T) {
 				Voters: 256,
 			},
 		},
+		{
+			name: "getcfilterheaders",
+			newCmd: func() (interface{}, error) {
+				return dcrjson.NewCmd(Method("getcfilterheaders"), 100, 2000)
+			},
+			staticCmd: func() interface{} {
+				return NewGetCFilterHeadersCmd(100, 2000)
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"getcfilterheaders","params":[100,2000],"id":1}`,
+			unmarshalled: &GetCFilterHeadersCmd{
+				StartHeight: 100,
+				Count:       2000,
+			},
+		},
 		{
 			name: "getcfilterv2",
 			newCmd: func() (interface{}, error) {
@@ -354,6 +508,20 @@ func TestChainSvrCmds(t *testing.T) {
 				BlockHash: "123",
 			},
 		},
+		{
+			name: "getcfilterv2range",
+			newCmd: func() (interface{}, error) {
+				return dcrjson.NewCmd(Method("getcfilterv2range"), 100, 1000)
+			},
+			staticCmd: func() interface{} {
+				return NewGetCFilterV2RangeCmd(100, 1000)
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"getcfilterv2range","params":[100,1000],"id":1}`,
+			unmarshalled: &GetCFilterV2RangeCmd{
+				StartHeight: 100,
+				Count:       1000,
+			},
+		},
 		{
 			name: "getchaintips",
 			newCmd: func() (interface{}, error) {
@@ -420,6 +588,28 @@ func TestChainSvrCmds(t *testing.T) {
 			marshalled:   `{"jsonrpc":"1.0","method":"gethashespersec","params":[],"id":1}`,
 			unmarshalled: &GetHashesPerSecCmd{},
 		},
+		{
+			name: "getindexinfo",
+			newCmd: func() (interface{}, error) {
+				return dcrjson.NewCmd(Method("getindexinfo"))
+			},
+			staticCmd: func() interface{} {
+				return NewGetIndexInfoCmd(nil)
+			},
+			marshalled:   `{"jsonrpc":"1.0","method":"getindexinfo","params":[],"id":1}`,
+			unmarshalled: &GetIndexInfoCmd{Name: nil},
+		},
+		{
+			name: "getindexinfo optional",
+			newCmd: func() (interface{}, error) {
+				return dcrjson.NewCmd(Method("getindexinfo"), "txindex")
+			},
+			staticCmd: func() interface{} {
+				return NewGetIndexInfoCmd(dcrjson.String("txindex"))
+			},
+			marshalled:   `{"jsonrpc":"1.0","method":"getindexinfo","params":["txindex"],"id":1}`,
+			unmarshalled: &GetIndexInfoCmd{Name: dcrjson.String("txindex")},
+		},
 		{
 			name: "getinfo",
 			newCmd: func() (interface{}, error) {
@@ -464,6 +654,17 @@ func TestChainSvrCmds(t *testing.T) {
 			marshalled:   `{"jsonrpc":"1.0","method":"getnetworkinfo","params":[],"id":1}`,
 			unmarshalled: &GetNetworkInfoCmd{},
 		},
+		{
+			name: "getnetmsgstats",
+			newCmd: func() (interface{}, error) {
+				return dcrjson.NewCmd(Method("getnetmsgstats"))
+			},
+			staticCmd: func() interface{} {
+				return NewGetNetMsgStatsCmd()
+			},
+			marshalled:   `{"jsonrpc":"1.0","method":"getnetmsgstats","params":[],"id":1}`,
+			unmarshalled: &GetNetMsgStatsCmd{},
+		},
 		{
 			name: "getnettotals",
 			newCmd: func() (interface{}, error) {
@@ -596,6 +797,20 @@ func TestChainSvrCmds(t *testing.T) {
 				Verbose: dcrjson.Int(1),
 			},
 		},
+		{
+			name: "getspendingtx",
+			newCmd: func() (interface{}, error) {
+				return dcrjson.NewCmd(Method("getspendingtx"), "123", 1)
+			},
+			staticCmd: func() interface{} {
+				return NewGetSpendingTxCmd("123", 1)
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"getspendingtx","params":["123",1],"id":1}`,
+			unmarshalled: &GetSpendingTxCmd{
+				Txid: "123",
+				Vout: 1,
+			},
+		},
 		{
 			name: "getstakeversions",
 			newCmd: func() (interface{}, error) {
@@ -610,6 +825,19 @@ func TestChainSvrCmds(t *testing.T) {
 				Count: 1,
 			},
 		},
+		{
+			name: "getticketinfo",
+			newCmd: func() (interface{}, error) {
+				return dcrjson.NewCmd(Method("getticketinfo"), "123")
+			},
+			staticCmd: func() interface{} {
+				return NewGetTicketInfoCmd("123")
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"getticketinfo","params":["123"],"id":1}`,
+			unmarshalled: &GetTicketInfoCmd{
+				Hash: "123",
+			},
+		},
 		{
 			name: "gettxout",
 			newCmd: func() (interface{}, error) {
@@ -648,10 +876,41 @@ func TestChainSvrCmds(t *testing.T) {
 				return dcrjson.NewCmd(Method("gettxoutsetinfo"))
 			},
 			staticCmd: func() interface{} {
-				return NewGetTxOutSetInfoCmd()
+				return NewGetTxOutSetInfoCmd(nil, nil)
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"gettxoutsetinfo","params":[],"id":1}`,
+			unmarshalled: &GetTxOutSetInfoCmd{
+				UseIndex: dcrjson.Bool(true),
+			},
+		},
+		{
+			name: "gettxoutsetinfo optional",
+			newCmd: func() (interface{}, error) {
+				return dcrjson.NewCmd(Method("gettxoutsetinfo"), "12345", false)
+			},
+			staticCmd: func() interface{} {
+				hashOrHeight := HashOrHeight("12345")
+				return NewGetTxOutSetInfoCmd(&hashOrHeight, dcrjson.Bool(false))
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"gettxoutsetinfo","params":["12345",false],"id":1}`,
+			unmarshalled: &GetTxOutSetInfoCmd{
+				HashOrHeight: func() *HashOrHeight {
+					h := HashOrHeight("12345")
+					return &h
+				}(),
+				UseIndex: dcrjson.Bool(false),
+			},
+		},
+		{
+			name: "getutxocacheinfo",
+			newCmd: func() (interface{}, error) {
+				return dcrjson.NewCmd(Method("getutxocacheinfo"))
+			},
+			staticCmd: func() interface{} {
+				return NewGetUtxoCacheInfoCmd()
 			},
-			marshalled:   `{"jsonrpc":"1.0","method":"gettxoutsetinfo","params":[],"id":1}`,
-			unmarshalled: &GetTxOutSetInfoCmd{},
+			marshalled:   `{"jsonrpc":"1.0","method":"getutxocacheinfo","params":[],"id":1}`,
+			unmarshalled: &GetUtxoCacheInfoCmd{},
 		},
 		{
 			name: "getvoteinfo",
@@ -803,6 +1062,17 @@ func TestChainSvrCmds(t *testing.T) {
 				ConnectSubCmd: dcrjson.String("perm"),
 			},
 		},
+		{
+			name: "listbanned",
+			newCmd: func() (interface{}, error) {
+				return dcrjson.NewCmd(Method("listbanned"))
+			},
+			staticCmd: func() interface{} {
+				return NewListBannedCmd()
+			},
+			marshalled:   `{"jsonrpc":"1.0","method":"listbanned","params":[],"id":1}`,
+			unmarshalled: &ListBannedCmd{},
+		},
 		{
 			name: "ping",
 			newCmd: func() (interface{}, error) {
@@ -955,6 +1225,40 @@ func TestChainSvrCmds(t *testing.T) {
 				FilterAddrs: &[]string{"1Address"},
 			},
 		},
+		{
+			name: "searchscripts",
+			newCmd: func() (interface{}, error) {
+				return dcrjson.NewCmd(Method("searchscripts"), "6a0401020304")
+			},
+			staticCmd: func() interface{} {
+				return NewSearchScriptsCmd("6a0401020304", nil, nil, nil)
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"searchscripts","params":["6a0401020304"],"id":1}`,
+			unmarshalled: &SearchScriptsCmd{
+				Data:  "6a0401020304",
+				Mode:  SearchScriptsModeAddr(SearchScriptsScript),
+				Skip:  dcrjson.Int(0),
+				Count: dcrjson.Int(100),
+			},
+		},
+		{
+			name: "searchscripts optional",
+			newCmd: func() (interface{}, error) {
+				return dcrjson.NewCmd(Method("searchscripts"), "01020304", "nulldata", 5, 10)
+			},
+			staticCmd: func() interface{} {
+				return NewSearchScriptsCmd("01020304",
+					SearchScriptsModeAddr(SearchScriptsNullData), dcrjson.Int(5),
+					dcrjson.Int(10))
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"searchscripts","params":["01020304","nulldata",5,10],"id":1}`,
+			unmarshalled: &SearchScriptsCmd{
+				Data:  "01020304",
+				Mode:  SearchScriptsModeAddr(SearchScriptsNullData),
+				Skip:  dcrjson.Int(5),
+				Count: dcrjson.Int(10),
+			},
+		},
 		{
 			name: "sendrawtransaction",
 			newCmd: func() (interface{}, error) {
@@ -983,6 +1287,35 @@ func TestChainSvrCmds(t *testing.T) {
 				AllowHighFees: dcrjson.Bool(false),
 			},
 		},
+		{
+			name: "setban",
+			newCmd: func() (interface{}, error) {
+				return dcrjson.NewCmd(Method("setban"), "add", "10.0.0.0/8")
+			},
+			staticCmd: func() interface{} {
+				return NewSetBanCmd(SBAdd, "10.0.0.0/8", nil)
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"setban","params":["add","10.0.0.0/8"],"id":1}`,
+			unmarshalled: &SetBanCmd{
+				SubCmd: SBAdd,
+				Subnet: "10.0.0.0/8",
+			},
+		},
+		{
+			name: "setban optional",
+			newCmd: func() (interface{}, error) {
+				return dcrjson.NewCmd(Method("setban"), "add", "10.0.0.1", 3600)
+			},
+			staticCmd: func() interface{} {
+				return NewSetBanCmd(SBAdd, "10.0.0.1", dcrjson.Int64(3600))
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"setban","params":["add","10.0.0.1",3600],"id":1}`,
+			unmarshalled: &SetBanCmd{
+				SubCmd:   SBAdd,
+				Subnet:   "10.0.0.1",
+				Duration: dcrjson.Int64(3600),
+			},
+		},
 		{
 			name: "setgenerate",
 			newCmd: func() (interface{}, error) {
@@ -1194,3 +1527,44 @@ func TestChainSvrCmds(t *testing.T) {
 		}
 	}
 }
+
+// TestHashOrHeightParams ensures the block identifier of the getblockstats
+// command may be provided as either a JSON string or a JSON number.
+func TestHashOrHeightParams(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		params string
+		want   HashOrHeight
+		err    bool
+	}{
+		{name: "hash string", params: `["00aa"]`, want: "00aa"},
+		{name: "height string", params: `["100"]`, want: "100"},
+		{name: "height number", params: `[100]`, want: "100"},
+		{name: "bool", params: `[true]`, err: true},
+	}
+
+	for _, test := range tests {
+		var params []json.RawMessage
+		if err := json.Unmarshal([]byte(test.params), &params); err != nil {
+			t.Fatalf("%s: invalid test params: %v", test.name, err)
+		}
+		cmd, err := dcrjson.ParseParams(Method("getblockstats"), params)
+		if test.err {
+			if err == nil {
+				t.Errorf("%s: did not receive expected error", test.name)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", test.name, err)
+			continue
+		}
+		got := cmd.(*GetBlockStatsCmd).HashOrHeight
+		if got != test.want {
+			t.Errorf("%s: unexpected block identifier - got %q, want %q",
+				test.name, got, test.want)
+		}
+	}
+}
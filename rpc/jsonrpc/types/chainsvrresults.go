@@ -57,6 +57,38 @@ type GetAddedNodeInfoResult struct {
 	Addresses *[]GetAddedNodeInfoResultAddr `json:"addresses,omitempty"`
 }
 
+// GetAddressBalanceResult models the data from the getaddressbalance command.
+type GetAddressBalanceResult struct {
+	Address     string  `json:"address"`
+	Balance     float64 `json:"balance"`
+	Received    float64 `json:"received"`
+	Sent        float64 `json:"sent"`
+	Unconfirmed float64 `json:"unconfirmed"`
+	NumUtxos    uint32  `json:"numutxos"`
+}
+
+// AddressDeltaResult models the data of a single credit or debit of an address
+// returned by the getaddressdeltas command.
+type AddressDeltaResult struct {
+	Txid          string  `json:"txid"`
+	Index         uint32  `json:"index"`
+	IsInput       bool    `json:"isinput"`
+	Amount        float64 `json:"amount"`
+	Height        int64   `json:"height"`
+	Confirmations int64   `json:"confirmations"`
+}
+
+// AddressUtxoResult models the data of an unspent output paid to an address
+// returned by the getaddressutxos command.
+type AddressUtxoResult struct {
+	Txid          string  `json:"txid"`
+	Vout          uint32  `json:"vout"`
+	Tree          int8    `json:"tree"`
+	Amount        float64 `json:"amount"`
+	Height        int64   `json:"height"`
+	Confirmations int64   `json:"confirmations"`
+}
+
 // GetBlockVerboseResult models the data from the getblock command when the
 // verbose flag is set.  When the verbose flag is not set, getblock returns a
 // hex-encoded string.  Contains Decred additions.
@@ -154,6 +186,38 @@ type GetBlockHeaderVerboseResult struct {
 	EquihashSolution []byte  `json:"equihashsolution"`
 }
 
+// GetBlockStatsResult models the data returned from the getblockstats command.
+// Only the requested fields are returned when specific fields are requested.
+type GetBlockStatsResult struct {
+	AvgFee             float64   `json:"avgfee"`
+	AvgFeeRate         float64   `json:"avgfeerate"`
+	AvgTxSize          int64     `json:"avgtxsize"`
+	BlockHash          string    `json:"blockhash"`
+	FeeRatePercentiles []float64 `json:"feeratepercentiles"`
+	Height             int64     `json:"height"`
+	Ins                int64     `json:"ins"`
+	MaxFee             float64   `json:"maxfee"`
+	MaxFeeRate         float64   `json:"maxfeerate"`
+	MaxTxSize          int64     `json:"maxtxsize"`
+	MedianFee          float64   `json:"medianfee"`
+	MedianTxSize       int64     `json:"mediantxsize"`
+	MinFee             float64   `json:"minfee"`
+	MinFeeRate         float64   `json:"minfeerate"`
+	MinTxSize          int64     `json:"mintxsize"`
+	Outs               int64     `json:"outs"`
+	Revocations        int64     `json:"revocations"`
+	Size               int64     `json:"size"`
+	STxs               int64     `json:"stxs"`
+	Tickets            int64     `json:"tickets"`
+	Time               int64     `json:"time"`
+	TotalFee           float64   `json:"totalfee"`
+	TotalOut           float64   `json:"totalout"`
+	TotalSize          int64     `json:"totalsize"`
+	Txs                int64     `json:"txs"`
+	UtxoIncrease       int64     `json:"utxoincrease"`
+	Votes              int64     `json:"votes"`
+}
+
 // GetBlockSubsidyResult models the data returned from the getblocksubsidy
 // command.
 type GetBlockSubsidyResult struct {
@@ -170,6 +234,24 @@ type GetChainTipsResult struct {
 	Status    string `json:"status"`
 }
 
+// CFilterHeaderResult models the filter hash and filter header of a block
+// along with the proof that the filter is committed to by the block header as
+// returned by the getcfilterheaders command.
+type CFilterHeaderResult struct {
+	BlockHash   string   `json:"blockhash"`
+	FilterHash  string   `json:"filterhash"`
+	Header      string   `json:"header"`
+	ProofIndex  uint32   `json:"proofindex"`
+	ProofHashes []string `json:"proofhashes"`
+}
+
+// GetCFilterHeadersResult models the data returned from the getcfilterheaders
+// command.
+type GetCFilterHeadersResult struct {
+	PrevHeader string                `json:"prevheader"`
+	Headers    []CFilterHeaderResult `json:"headers"`
+}
+
 // GetCFilterV2Result models the data returned from the getcfilterv2 command.
 type GetCFilterV2Result struct {
 	BlockHash   string   `json:"blockhash"`
@@ -184,6 +266,16 @@ type GetHeadersResult struct {
 	Headers []string `json:"headers"`
 }
 
+// IndexInfoResult models objects included in the getindexinfo response.  In the
+// actual result, these objects are keyed by the index name.
+type IndexInfoResult struct {
+	Synced          bool   `json:"synced"`
+	Dropping        bool   `json:"dropping"`
+	BestBlockHeight int64  `json:"bestblockheight"`
+	BestBlockHash   string `json:"bestblockhash"`
+	DiskUsage       int64  `json:"diskusage"`
+}
+
 // InfoChainResult models the data returned by the chain server getinfo command.
 type InfoChainResult struct {
 	Version         int32   `json:"version"`
@@ -255,35 +347,66 @@ type GetNetworkInfoResult struct {
 	LocalServices   string                 `json:"localservices"`
 }
 
+// NetMsgStat models the number of messages of a given type that have been
+// sent or received along with their total size in bytes as returned from the
+// getnetmsgstats and getpeerinfo commands.
+type NetMsgStat struct {
+	Msgs  uint64 `json:"msgs"`
+	Bytes uint64 `json:"bytes"`
+}
+
+// GetNetMsgStatsResult models the data returned from the getnetmsgstats
+// command.
+type GetNetMsgStatsResult struct {
+	Sent map[string]NetMsgStat `json:"sent"`
+	Recv map[string]NetMsgStat `json:"recv"`
+}
+
+// UploadTargetResult models the upload target details returned from the
+// getnettotals command.
+type UploadTargetResult struct {
+	TimeFrame             int64  `json:"timeframe"`
+	Target                uint64 `json:"target"`
+	TargetReached         bool   `json:"target_reached"`
+	ServeHistoricalBlocks bool   `json:"serve_historical_blocks"`
+	BytesLeftInCycle      uint64 `json:"bytes_left_in_cycle"`
+	TimeLeftInCycle       int64  `json:"time_left_in_cycle"`
+}
+
 // GetNetTotalsResult models the data returned from the getnettotals command.
 type GetNetTotalsResult struct {
-	TotalBytesRecv uint64 `json:"totalbytesrecv"`
-	TotalBytesSent uint64 `json:"totalbytessent"`
-	TimeMillis     int64  `json:"timemillis"`
+	TotalBytesRecv uint64             `json:"totalbytesrecv"`
+	TotalBytesSent uint64             `json:"totalbytessent"`
+	TimeMillis     int64              `json:"timemillis"`
+	UploadTarget   UploadTargetResult `json:"uploadtarget"`
 }
 
 // GetPeerInfoResult models the data returned from the getpeerinfo command.
 type GetPeerInfoResult struct {
-	ID             int32   `json:"id"`
-	Addr           string  `json:"addr"`
-	AddrLocal      string  `json:"addrlocal,omitempty"`
-	Services       string  `json:"services"`
-	RelayTxes      bool    `json:"relaytxes"`
-	LastSend       int64   `json:"lastsend"`
-	LastRecv       int64   `json:"lastrecv"`
-	BytesSent      uint64  `json:"bytessent"`
-	BytesRecv      uint64  `json:"bytesrecv"`
-	ConnTime       int64   `json:"conntime"`
-	TimeOffset     int64   `json:"timeoffset"`
-	PingTime       float64 `json:"pingtime"`
-	PingWait       float64 `json:"pingwait,omitempty"`
-	Version        uint32  `json:"version"`
-	SubVer         string  `json:"subver"`
-	Inbound        bool    `json:"inbound"`
-	StartingHeight int64   `json:"startingheight"`
-	CurrentHeight  int64   `json:"currentheight,omitempty"`
-	BanScore       int32   `json:"banscore"`
-	SyncNode       bool    `json:"syncnode"`
+	ID             int32                 `json:"id"`
+	Addr           string                `json:"addr"`
+	AddrLocal      string                `json:"addrlocal,omitempty"`
+	Services       string                `json:"services"`
+	RelayTxes      bool                  `json:"relaytxes"`
+	LastSend       int64                 `json:"lastsend"`
+	LastRecv       int64                 `json:"lastrecv"`
+	BytesSent      uint64                `json:"bytessent"`
+	BytesRecv      uint64                `json:"bytesrecv"`
+	ConnTime       int64                 `json:"conntime"`
+	TimeOffset     int64                 `json:"timeoffset"`
+	PingTime       float64               `json:"pingtime"`
+	PingWait       float64               `json:"pingwait,omitempty"`
+	Version        uint32                `json:"version"`
+	SubVer         string                `json:"subver"`
+	Inbound        bool                  `json:"inbound"`
+	ConnType       string                `json:"conntype"`
+	MappedAS       uint32                `json:"mappedas,omitempty"`
+	StartingHeight int64                 `json:"startingheight"`
+	CurrentHeight  int64                 `json:"currentheight,omitempty"`
+	BanScore       int32                 `json:"banscore"`
+	SyncNode       bool                  `json:"syncnode"`
+	SentPerMsg     map[string]NetMsgStat `json:"sentpermsg"`
+	RecvPerMsg     map[string]NetMsgStat `json:"recvpermsg"`
 }
 
 // GetRawMempoolVerboseResult models the data returned from the getrawmempool
@@ -316,6 +439,16 @@ type TxRawResult struct {
 	Blocktime     int64  `json:"blocktime,omitempty"`
 }
 
+// GetSpendingTxResult models the data returned from the getspendingtx command.
+// It is also used to describe the spender of each output in the verbose
+// getrawtransaction results.
+type GetSpendingTxResult struct {
+	Txid        string `json:"txid"`
+	Vin         uint32 `json:"vin"`
+	BlockHash   string `json:"blockhash"`
+	BlockHeight int64  `json:"blockheight"`
+}
+
 // GetStakeDifficultyResult models the data returned from the
 // getstakedifficulty command.
 type GetStakeDifficultyResult struct {
@@ -366,6 +499,40 @@ type GetStakeVersionsResult struct {
 	StakeVersions []StakeVersions `json:"stakeversions"`
 }
 
+// TicketVoteResult models the data of the vote that spent a ticket returned by
+// the getticketinfo and getaddresstickets commands.
+type TicketVoteResult struct {
+	Txid        string `json:"txid"`
+	BlockHash   string `json:"blockhash"`
+	BlockHeight int64  `json:"blockheight"`
+	Version     uint32 `json:"version"`
+	VoteBits    uint16 `json:"votebits"`
+}
+
+// TicketRevocationResult models the data of the revocation that spent a ticket
+// returned by the getticketinfo and getaddresstickets commands.
+type TicketRevocationResult struct {
+	Txid        string `json:"txid"`
+	BlockHash   string `json:"blockhash"`
+	BlockHeight int64  `json:"blockheight"`
+}
+
+// GetTicketInfoResult models the data returned from the getticketinfo command.
+// It is also used to describe each ticket in the getaddresstickets results.
+type GetTicketInfoResult struct {
+	Hash           string                  `json:"hash"`
+	Status         string                  `json:"status"`
+	Price          float64                 `json:"price"`
+	PurchaseHeight int64                   `json:"purchaseheight"`
+	PurchaseBlock  string                  `json:"purchaseblock"`
+	MaturityHeight int64                   `json:"maturityheight"`
+	ExpiryHeight   int64                   `json:"expiryheight"`
+	MissedHeight   int64                   `json:"missedheight,omitempty"`
+	ExpiredHeight  int64                   `json:"expiredheight,omitempty"`
+	Vote           *TicketVoteResult       `json:"vote,omitempty"`
+	Revocation     *TicketRevocationResult `json:"revocation,omitempty"`
+}
+
 // GetTxOutResult models the data from the gettxout command.
 type GetTxOutResult struct {
 	BestBlock     string             `json:"bestblock"`
@@ -376,16 +543,44 @@ type GetTxOutResult struct {
 }
 
 // GetTxOutSetInfoResult models the data from the gettxoutsetinfo command.
+//
+// The number of transactions, the serialized hash, and the disk size are only
+// available when the statistics are calculated from the unspent output set
+// itself while the set hash and the bogo size are only available when they are
+// obtained from the coin statistics index.
 type GetTxOutSetInfoResult struct {
 	Height         int64  `json:"height"`
 	BestBlock      string `json:"bestblock"`
-	Transactions   int64  `json:"transactions"`
+	Transactions   int64  `json:"transactions,omitempty"`
 	TxOuts         int64  `json:"txouts"`
-	SerializedHash string `json:"serializedhash"`
-	DiskSize       int64  `json:"disksize"`
+	SerializedHash string `json:"serializedhash,omitempty"`
+	SetHash        string `json:"sethash,omitempty"`
+	DiskSize       int64  `json:"disksize,omitempty"`
+	BogoSize       int64  `json:"bogosize,omitempty"`
 	TotalAmount    int64  `json:"totalamount"`
 }
 
+// GetUtxoCacheInfoResult models the data from the getutxocacheinfo command.
+type GetUtxoCacheInfoResult struct {
+	MaxSize            uint64  `json:"maxsize"`
+	Size               uint64  `json:"size"`
+	Entries            uint64  `json:"entries"`
+	DirtyEntries       uint64  `json:"dirtyentries"`
+	DirtySize          uint64  `json:"dirtysize"`
+	Hits               uint64  `json:"hits"`
+	Misses             uint64  `json:"misses"`
+	HitRatio           float64 `json:"hitratio"`
+	Prefetched         uint64  `json:"prefetched"`
+	MemoryLimit        uint64  `json:"memorylimit"`
+	MemoryInUse        uint64  `json:"memoryinuse"`
+	Flushes            uint64  `json:"flushes"`
+	LastFlushHash      string  `json:"lastflushhash"`
+	LastFlushTime      int64   `json:"lastflushtime"`
+	LastFlushReason    string  `json:"lastflushreason"`
+	LastFlushDuration  int64   `json:"lastflushduration"`
+	TotalFlushDuration int64   `json:"totalflushduration"`
+}
+
 // Choice models an individual choice inside an Agenda.
 type Choice struct {
 	ID          string  `json:"id"`
@@ -461,6 +656,14 @@ type Ticket struct {
 	Owner string `json:"owner"`
 }
 
+// ListBannedResult models the data of a banned subnet returned from the
+// listbanned command.
+type ListBannedResult struct {
+	Address     string `json:"address"`
+	BanCreated  int64  `json:"ban_created"`
+	BannedUntil int64  `json:"banned_until"`
+}
+
 // LiveTicketsResult models the data returned from the livetickets
 // command.
 type LiveTicketsResult struct {
@@ -542,6 +745,18 @@ type SearchRawTransactionsResult struct {
 	Blocktime     int64        `json:"blocktime,omitempty"`
 }
 
+// SearchScriptsResult models an output returned by the searchscripts command.
+type SearchScriptsResult struct {
+	TxID          string  `json:"txid"`
+	Vout          uint32  `json:"vout"`
+	Tree          int8    `json:"tree"`
+	BlockHash     string  `json:"blockhash"`
+	BlockHeight   int64   `json:"blockheight"`
+	Value         float64 `json:"value"`
+	ScriptVersion uint16  `json:"scriptversion"`
+	Script        string  `json:"script"`
+}
+
 // TxFeeInfoResult models the data returned from the ticketfeeinfo command.
 // command.
 type TxFeeInfoResult struct {
@@ -834,8 +1049,9 @@ func (v *VinPrevOut) MarshalJSON() ([]byte, error) {
 // Vout models parts of the tx data.  It is defined separately since both
 // getrawtransaction and decoderawtransaction use the same structure.
 type Vout struct {
-	Value        float64            `json:"value"`
-	N            uint32             `json:"n"`
-	Version      uint16             `json:"version"`
-	ScriptPubKey ScriptPubKeyResult `json:"scriptPubKey"`
+	Value        float64              `json:"value"`
+	N            uint32               `json:"n"`
+	Version      uint16               `json:"version"`
+	ScriptPubKey ScriptPubKeyResult   `json:"scriptPubKey"`
+	SpentBy      *GetSpendingTxResult `json:"spentby,omitempty"`
 }
@@ -51,6 +51,37 @@ func NewLoadTxFilterCmd(reload bool, addresses []string, outPoints []OutPoint) *
 	}
 }
 
+// AddressCursor identifies the position of a mined transaction that involves
+// an address registered with notifyaddresses.  It is included with each
+// notification for a mined transaction and may be provided to notifyaddresses
+// after reconnecting in order to resume notifications after that transaction.
+//
+// The block height is informational only and is ignored when resuming.
+type AddressCursor struct {
+	BlockHash   string `json:"blockhash"`
+	BlockHeight int64  `json:"blockheight"`
+	Tree        int8   `json:"tree"`
+	Index       uint32 `json:"index"`
+}
+
+// NotifyAddressesCmd defines the notifyaddresses JSON-RPC command.
+type NotifyAddressesCmd struct {
+	Addresses []string
+	Cursor    *AddressCursor
+}
+
+// NewNotifyAddressesCmd returns a new instance which can be used to issue a
+// notifyaddresses JSON-RPC command.
+//
+// The parameters which are pointers indicate they are optional.  Passing nil
+// for optional parameters will use the default value.
+func NewNotifyAddressesCmd(addresses []string, cursor *AddressCursor) *NotifyAddressesCmd {
+	return &NotifyAddressesCmd{
+		Addresses: addresses,
+		Cursor:    cursor,
+	}
+}
+
 // NotifyBlocksCmd defines the notifyblocks JSON-RPC command.
 type NotifyBlocksCmd struct{}
 
@@ -131,6 +162,17 @@ func NewRebroadcastWinnersCmd() *RebroadcastWinnersCmd {
 	return &RebroadcastWinnersCmd{}
 }
 
+// StopNotifyAddressesCmd defines the stopnotifyaddresses JSON-RPC command.
+type StopNotifyAddressesCmd struct {
+	Addresses []string
+}
+
+// NewStopNotifyAddressesCmd returns a new instance which can be used to issue a
+// stopnotifyaddresses JSON-RPC command.
+func NewStopNotifyAddressesCmd(addresses []string) *StopNotifyAddressesCmd {
+	return &StopNotifyAddressesCmd{Addresses: addresses}
+}
+
 // StopNotifyBlocksCmd defines the stopnotifyblocks JSON-RPC command.
 type StopNotifyBlocksCmd struct{}
 
@@ -212,6 +254,7 @@ func init() {
 
 	dcrjson.MustRegister(Method("authenticate"), (*AuthenticateCmd)(nil), flags)
 	dcrjson.MustRegister(Method("loadtxfilter"), (*LoadTxFilterCmd)(nil), flags)
+	dcrjson.MustRegister(Method("notifyaddresses"), (*NotifyAddressesCmd)(nil), flags)
 	dcrjson.MustRegister(Method("notifyblocks"), (*NotifyBlocksCmd)(nil), flags)
 	dcrjson.MustRegister(Method("notifywork"), (*NotifyWorkCmd)(nil), flags)
 	dcrjson.MustRegister(Method("notifytspend"), (*NotifyTSpendCmd)(nil), flags)
@@ -222,6 +265,7 @@ func init() {
 	dcrjson.MustRegister(Method("rebroadcastmissed"), (*RebroadcastMissedCmd)(nil), flags)
 	dcrjson.MustRegister(Method("rebroadcastwinners"), (*RebroadcastWinnersCmd)(nil), flags)
 	dcrjson.MustRegister(Method("session"), (*SessionCmd)(nil), flags)
+	dcrjson.MustRegister(Method("stopnotifyaddresses"), (*StopNotifyAddressesCmd)(nil), flags)
 	dcrjson.MustRegister(Method("stopnotifyblocks"), (*StopNotifyBlocksCmd)(nil), flags)
 	dcrjson.MustRegister(Method("stopnotifywork"), (*StopNotifyWorkCmd)(nil), flags)
 	dcrjson.MustRegister(Method("stopnotifytspend"), (*StopNotifyTSpendCmd)(nil), flags)
@@ -74,6 +74,45 @@ func TestChainSvrWsCmds(t *testing.T) {
 			marshalled:   `{"jsonrpc":"1.0","method":"notifynewtickets","params":[],"id":1}`,
 			unmarshalled: &NotifyNewTicketsCmd{},
 		},
+		{
+			name: "notifyaddresses",
+			newCmd: func() (interface{}, error) {
+				return dcrjson.NewCmd(Method("notifyaddresses"), []string{"addr1"})
+			},
+			staticCmd: func() interface{} {
+				return NewNotifyAddressesCmd([]string{"addr1"}, nil)
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"notifyaddresses","params":[["addr1"]],"id":1}`,
+			unmarshalled: &NotifyAddressesCmd{
+				Addresses: []string{"addr1"},
+			},
+		},
+		{
+			name: "notifyaddresses optional cursor",
+			newCmd: func() (interface{}, error) {
+				return dcrjson.NewCmd(Method("notifyaddresses"), []string{"addr1"},
+					`{"blockhash":"123","blockheight":10,"tree":1,"index":2}`)
+			},
+			staticCmd: func() interface{} {
+				cursor := &AddressCursor{
+					BlockHash:   "123",
+					BlockHeight: 10,
+					Tree:        1,
+					Index:       2,
+				}
+				return NewNotifyAddressesCmd([]string{"addr1"}, cursor)
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"notifyaddresses","params":[["addr1"],{"blockhash":"123","blockheight":10,"tree":1,"index":2}],"id":1}`,
+			unmarshalled: &NotifyAddressesCmd{
+				Addresses: []string{"addr1"},
+				Cursor: &AddressCursor{
+					BlockHash:   "123",
+					BlockHeight: 10,
+					Tree:        1,
+					Index:       2,
+				},
+			},
+		},
 		{
 			name: "notifyblocks",
 			newCmd: func() (interface{}, error) {
@@ -107,6 +146,19 @@ func TestChainSvrWsCmds(t *testing.T) {
 			marshalled:   `{"jsonrpc":"1.0","method":"notifytspend","params":[],"id":1}`,
 			unmarshalled: &NotifyTSpendCmd{},
 		},
+		{
+			name: "stopnotifyaddresses",
+			newCmd: func() (interface{}, error) {
+				return dcrjson.NewCmd(Method("stopnotifyaddresses"), []string{"addr1"})
+			},
+			staticCmd: func() interface{} {
+				return NewStopNotifyAddressesCmd([]string{"addr1"})
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"stopnotifyaddresses","params":[["addr1"]],"id":1}`,
+			unmarshalled: &StopNotifyAddressesCmd{
+				Addresses: []string{"addr1"},
+			},
+		},
 		{
 			name: "stopnotifyblocks",
 			newCmd: func() (interface{}, error) {
@@ -11,6 +11,11 @@ package types
 import "github.com/EXCCoin/exccd/dcrjson/v4"
 
 const (
+	// AddressTxNtfnMethod is the method used for notifications from the chain
+	// server that a transaction involving addresses registered with
+	// notifyaddresses has been accepted into the mempool or mined.
+	AddressTxNtfnMethod Method = "addresstx"
+
 	// BlockConnectedNtfnMethod is the method used for notifications from
 	// the chain server that a block has been connected.
 	BlockConnectedNtfnMethod Method = "blockconnected"
@@ -58,6 +63,24 @@ const (
 	WinningTicketsNtfnMethod Method = "winningtickets"
 )
 
+// AddressTxNtfn defines the addresstx JSON-RPC notification.  The cursor is
+// only set for mined transactions.
+type AddressTxNtfn struct {
+	Addresses   []string       `json:"addresses"`
+	Transaction string         `json:"transaction"`
+	Cursor      *AddressCursor `json:"cursor"`
+}
+
+// NewAddressTxNtfn returns a new instance which can be used to issue an
+// addresstx JSON-RPC notification.
+func NewAddressTxNtfn(addresses []string, transaction string, cursor *AddressCursor) *AddressTxNtfn {
+	return &AddressTxNtfn{
+		Addresses:   addresses,
+		Transaction: transaction,
+		Cursor:      cursor,
+	}
+}
+
 // BlockConnectedNtfn defines the blockconnected JSON-RPC notification.
 type BlockConnectedNtfn struct {
 	Header        string   `json:"header"`
@@ -235,6 +258,7 @@ func init() {
 	// notifications.
 	flags := dcrjson.UFWebsocketOnly | dcrjson.UFNotification
 
+	dcrjson.MustRegister(AddressTxNtfnMethod, (*AddressTxNtfn)(nil), flags)
 	dcrjson.MustRegister(BlockConnectedNtfnMethod, (*BlockConnectedNtfn)(nil), flags)
 	dcrjson.MustRegister(BlockDisconnectedNtfnMethod, (*BlockDisconnectedNtfn)(nil), flags)
 	dcrjson.MustRegister(WorkNtfnMethod, (*WorkNtfn)(nil), flags)
@@ -29,6 +29,45 @@ func TestChainSvrWsNtfns(t *testing.T) {
 		marshalled   string
 		unmarshalled interface{}
 	}{
+		{
+			name: "addresstx",
+			newNtfn: func() (interface{}, error) {
+				return dcrjson.NewCmd(Method("addresstx"), []string{"addr1"}, "001122")
+			},
+			staticNtfn: func() interface{} {
+				return NewAddressTxNtfn([]string{"addr1"}, "001122", nil)
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"addresstx","params":[["addr1"],"001122"],"id":null}`,
+			unmarshalled: &AddressTxNtfn{
+				Addresses:   []string{"addr1"},
+				Transaction: "001122",
+			},
+		},
+		{
+			name: "addresstx mined",
+			newNtfn: func() (interface{}, error) {
+				return dcrjson.NewCmd(Method("addresstx"), []string{"addr1"}, "001122",
+					`{"blockhash":"123","blockheight":10,"tree":0,"index":2}`)
+			},
+			staticNtfn: func() interface{} {
+				cursor := &AddressCursor{
+					BlockHash:   "123",
+					BlockHeight: 10,
+					Index:       2,
+				}
+				return NewAddressTxNtfn([]string{"addr1"}, "001122", cursor)
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"addresstx","params":[["addr1"],"001122",{"blockhash":"123","blockheight":10,"tree":0,"index":2}],"id":null}`,
+			unmarshalled: &AddressTxNtfn{
+				Addresses:   []string{"addr1"},
+				Transaction: "001122",
+				Cursor: &AddressCursor{
+					BlockHash:   "123",
+					BlockHeight: 10,
+					Index:       2,
+				},
+			},
+		},
 		{
 			name: "blockconnected",
 			newNtfn: func() (interface{}, error) {
@@ -12,3 +12,12 @@ func EstimateSmartFeeModeAddr(v EstimateSmartFeeMode) *EstimateSmartFeeMode {
 	*p = v
 	return p
 }
+
+// SearchScriptsModeAddr is a helper routine that allocates a new
+// SearchScriptsMode value to store v and returns a pointer to it. This is
+// useful when assigning optional parameters.
+func SearchScriptsModeAddr(v SearchScriptsMode) *SearchScriptsMode {
+	p := new(SearchScriptsMode)
+	*p = v
+	return p
+}
@@ -20,6 +20,7 @@ import (
 	"github.com/EXCCoin/exccd/internal/mining/cpuminer"
 	"github.com/EXCCoin/exccd/internal/netsync"
 	"github.com/EXCCoin/exccd/internal/rpcserver"
+	"github.com/EXCCoin/exccd/internal/staging/banmanager"
 	"github.com/EXCCoin/exccd/peer/v3"
 	"github.com/EXCCoin/exccd/wire"
 )
@@ -106,6 +107,37 @@ func (p *rpcPeer) BanScore() uint32 {
 	return (*serverPeer)(p).banScore.Int()
 }
 
+// ConnType returns a human-readable description of the type of the connection
+// to the peer such as inbound or block-relay-only.
+//
+// This function is safe for concurrent access and is part of the rpcserver.Peer
+// interface implementation.
+func (p *rpcPeer) ConnType() string {
+	return (*serverPeer)(p).connTypeString()
+}
+
+// MappedAS returns the autonomous system number the address of the peer is
+// mapped to by the AS map in use or 0 when it is not mapped.
+//
+// This function is safe for concurrent access and is part of the rpcserver.Peer
+// interface implementation.
+func (p *rpcPeer) MappedAS() uint32 {
+	sp := (*serverPeer)(p)
+	if sp.NA() == nil {
+		return 0
+	}
+	return sp.server.addrManager.ASN(sp.remoteNetAddr())
+}
+
+// MsgStats returns the number of messages and bytes that have been sent to and
+// received from the peer per message type.
+//
+// This function is safe for concurrent access and is part of the rpcserver.Peer
+// interface implementation.
+func (p *rpcPeer) MsgStats() *rpcserver.MsgStats {
+	return (*serverPeer)(p).msgStats.snapshot()
+}
+
 // rpcConnManager provides a connection manager for use with the RPC server and
 // implements the rpcserver.ConnManager interface.
 type rpcConnManager struct {
@@ -220,6 +252,24 @@ func (cm *rpcConnManager) NetTotals() (uint64, uint64) {
 	return cm.server.NetTotals()
 }
 
+// MsgStats returns the number of messages and bytes that have been sent and
+// received across the network for all peers per message type.
+//
+// This function is safe for concurrent access and is part of the
+// rpcserver.ConnManager interface implementation.
+func (cm *rpcConnManager) MsgStats() *rpcserver.MsgStats {
+	return cm.server.msgStats.snapshot()
+}
+
+// UploadTarget returns the state of the target for the maximum number of bytes
+// to send to peers per cycle.
+//
+// This function is safe for concurrent access and is part of the
+// rpcserver.ConnManager interface implementation.
+func (cm *rpcConnManager) UploadTarget() *rpcserver.UploadTarget {
+	return cm.server.uploadTarget.state(time.Now())
+}
+
 // ConnectedPeers returns an array consisting of all connected peers.
 //
 // This function is safe for concurrent access and is part of the
@@ -274,12 +324,14 @@ func (cm *rpcConnManager) AddRebroadcastInventory(iv *wire.InvVect, data interfa
 }
 
 // RelayTransactions generates and relays inventory vectors for all of the
-// passed transactions to all connected peers.
+// passed transactions to all connected peers.  The transactions are treated as
+// having originated locally and are therefore announced after a shorter delay
+// than other transactions.
 //
 // This function is safe for concurrent access and is part of the
 // rpcserver.ConnManager interface implementation.
 func (cm *rpcConnManager) RelayTransactions(txns []*dcrutil.Tx) {
-	cm.server.relayTransactions(txns)
+	cm.server.relayTransactions(txns, true)
 }
 
 // AddedNodeInfo returns information describing persistent (added) nodes.
@@ -306,6 +358,66 @@ func (*rpcConnManager) Lookup(host string) ([]net.IP, error) {
 	return dcrdLookup(host)
 }
 
+// BanSubnet bans the provided subnet until the provided time and disconnects
+// any connected peers within it.  The ban is persisted so it survives
+// restarts.
+//
+// This function is safe for concurrent access and is part of the
+// rpcserver.ConnManager interface implementation.
+func (cm *rpcConnManager) BanSubnet(subnet *net.IPNet, until time.Time) error {
+	if err := cm.server.banList.Ban(subnet, until); err != nil {
+		return err
+	}
+
+	// Disconnect all peers within the newly banned subnet.  Each query
+	// disconnects at least one matching peer, so keep going until no more
+	// are found.
+	inSubnet := func(sp *serverPeer) bool {
+		host, _, err := net.SplitHostPort(sp.Addr())
+		if err != nil {
+			return false
+		}
+		ip := net.ParseIP(host)
+		return ip != nil && subnet.Contains(ip)
+	}
+	for {
+		replyChan := make(chan error)
+		cm.server.query <- disconnectNodeMsg{
+			cmp:   inSubnet,
+			reply: replyChan,
+		}
+		if err := <-replyChan; err != nil {
+			break
+		}
+	}
+	return nil
+}
+
+// UnbanSubnet removes the ban for the provided subnet.  Attempting to remove a
+// ban for a subnet that is not banned will return an error.
+//
+// This function is safe for concurrent access and is part of the
+// rpcserver.ConnManager interface implementation.
+func (cm *rpcConnManager) UnbanSubnet(subnet *net.IPNet) error {
+	return cm.server.banList.Unban(subnet)
+}
+
+// BannedSubnets returns all subnets that are currently banned.
+//
+// This function is safe for concurrent access and is part of the
+// rpcserver.ConnManager interface implementation.
+func (cm *rpcConnManager) BannedSubnets() ([]banmanager.BanEntry, error) {
+	return cm.server.banList.Bans()
+}
+
+// ClearBans removes all bans.
+//
+// This function is safe for concurrent access and is part of the
+// rpcserver.ConnManager interface implementation.
+func (cm *rpcConnManager) ClearBans() error {
+	return cm.server.banList.Clear()
+}
+
 // rpcSyncMgr provides an adaptor for use with the RPC server and implements the
 // rpcserver.SyncManager interface.
 type rpcSyncMgr struct {
@@ -270,6 +270,44 @@ func (c *Client) GetBlockChainInfo(ctx context.Context) (*chainjson.GetBlockChai
 	return c.GetBlockChainInfoAsync(ctx).Receive()
 }
 
+// FutureGetUtxoCacheInfoResult is a future promise to deliver the result of a
+// GetUtxoCacheInfoAsync RPC invocation (or an applicable error).
+type FutureGetUtxoCacheInfoResult cmdRes
+
+// Receive waits for the response promised by the future and returns the utxo
+// cache info provided by the server.
+func (r *FutureGetUtxoCacheInfoResult) Receive() (*chainjson.GetUtxoCacheInfoResult, error) {
+	res, err := receiveFuture(r.ctx, r.c)
+	if err != nil {
+		return nil, err
+	}
+
+	// Unmarshal result as a getutxocacheinfo result object.
+	var utxoCacheInfoRes chainjson.GetUtxoCacheInfoResult
+	err = json.Unmarshal(res, &utxoCacheInfoRes)
+	if err != nil {
+		return nil, err
+	}
+
+	return &utxoCacheInfoRes, nil
+}
+
+// GetUtxoCacheInfoAsync returns an instance of a type that can be used to get
+// the result of the RPC at some future time by invoking the Receive function on
+// the returned instance.
+//
+// See GetUtxoCacheInfo for the blocking version and more details.
+func (c *Client) GetUtxoCacheInfoAsync(ctx context.Context) *FutureGetUtxoCacheInfoResult {
+	cmd := chainjson.NewGetUtxoCacheInfoCmd()
+	return (*FutureGetUtxoCacheInfoResult)(c.sendCmd(ctx, cmd))
+}
+
+// GetUtxoCacheInfo returns information about the state and performance of the
+// utxo cache.
+func (c *Client) GetUtxoCacheInfo(ctx context.Context) (*chainjson.GetUtxoCacheInfoResult, error) {
+	return c.GetUtxoCacheInfoAsync(ctx).Receive()
+}
+
 // FutureGetInfoResult is a future promise to deliver the result of a
 // GetInfoAsync RPC invocation (or an applicable error).
 type FutureGetInfoResult cmdRes
@@ -433,6 +471,51 @@ func (c *Client) GetBlockHeaderVerbose(ctx context.Context, hash *chainhash.Hash
 	return c.GetBlockHeaderVerboseAsync(ctx, hash).Receive()
 }
 
+// FutureGetBlockStatsResult is a future promise to deliver the result of a
+// GetBlockStatsAsync RPC invocation (or an applicable error).
+type FutureGetBlockStatsResult cmdRes
+
+// Receive waits for the response promised by the future and returns the
+// statistics of the requested block.  Only the requested fields are set when
+// specific fields were requested.
+func (r *FutureGetBlockStatsResult) Receive() (*chainjson.GetBlockStatsResult, error) {
+	res, err := receiveFuture(r.ctx, r.c)
+	if err != nil {
+		return nil, err
+	}
+
+	// Unmarshal result as a getblockstats result object.
+	var stats chainjson.GetBlockStatsResult
+	err = json.Unmarshal(res, &stats)
+	if err != nil {
+		return nil, err
+	}
+	return &stats, nil
+}
+
+// GetBlockStatsAsync returns an instance of a type that can be used to get the
+// result of the RPC at some future time by invoking the Receive function on the
+// returned instance.
+//
+// See GetBlockStats for the blocking version and more details.
+func (c *Client) GetBlockStatsAsync(ctx context.Context, hashOrHeight chainjson.HashOrHeight, fields []string) *FutureGetBlockStatsResult {
+	var fieldsPtr *[]string
+	if len(fields) > 0 {
+		fieldsPtr = &fields
+	}
+	cmd := chainjson.NewGetBlockStatsCmd(hashOrHeight, fieldsPtr)
+	return (*FutureGetBlockStatsResult)(c.sendCmd(ctx, cmd))
+}
+
+// GetBlockStats returns statistics about the fees, sizes, and transactions of
+// the block identified by the provided hash or height.  All statistics are
+// returned when no fields are specified.
+//
+// NOTE: This requires the server to have the block statistics index enabled.
+func (c *Client) GetBlockStats(ctx context.Context, hashOrHeight chainjson.HashOrHeight, fields []string) (*chainjson.GetBlockStatsResult, error) {
+	return c.GetBlockStatsAsync(ctx, hashOrHeight, fields).Receive()
+}
+
 // FutureGetBlockSubsidyResult is a future promise to deliver the result of a
 // GetBlockSubsidyAsync RPC invocation (or an applicable error).
 type FutureGetBlockSubsidyResult cmdRes
@@ -855,6 +938,261 @@ func (c *Client) GetTxOut(ctx context.Context, txHash *chainhash.Hash, index uin
 	return c.GetTxOutAsync(ctx, txHash, index, tree, mempool).Receive()
 }
 
+// FutureGetSpendingTxResult is a future promise to deliver the result of a
+// GetSpendingTxAsync RPC invocation (or an applicable error).
+type FutureGetSpendingTxResult cmdRes
+
+// Receive waits for the response promised by the future and returns the
+// transaction that spends an output.
+func (r *FutureGetSpendingTxResult) Receive() (*chainjson.GetSpendingTxResult, error) {
+	res, err := receiveFuture(r.ctx, r.c)
+	if err != nil {
+		return nil, err
+	}
+
+	// Take care of the special case where the output has not been spent which
+	// results in the string "null".
+	if string(res) == "null" {
+		return nil, nil
+	}
+
+	// Unmarshal result as a getspendingtx result object.
+	var spendingTx *chainjson.GetSpendingTxResult
+	err = json.Unmarshal(res, &spendingTx)
+	if err != nil {
+		return nil, err
+	}
+
+	return spendingTx, nil
+}
+
+// GetSpendingTxAsync returns an instance of a type that can be used to get
+// the result of the RPC at some future time by invoking the Receive function on
+// the returned instance.
+//
+// See GetSpendingTx for the blocking version and more details.
+func (c *Client) GetSpendingTxAsync(ctx context.Context, txHash *chainhash.Hash, index uint32) *FutureGetSpendingTxResult {
+	hash := ""
+	if txHash != nil {
+		hash = txHash.String()
+	}
+
+	cmd := chainjson.NewGetSpendingTxCmd(hash, index)
+	return (*FutureGetSpendingTxResult)(c.sendCmd(ctx, cmd))
+}
+
+// GetSpendingTx returns the transaction that spends the provided output in the
+// main chain and nil when it is unspent.
+//
+// NOTE: This requires the server to have the spender index enabled.
+func (c *Client) GetSpendingTx(ctx context.Context, txHash *chainhash.Hash, index uint32) (*chainjson.GetSpendingTxResult, error) {
+	return c.GetSpendingTxAsync(ctx, txHash, index).Receive()
+}
+
+// FutureGetAddressBalanceResult is a future promise to deliver the result of a
+// GetAddressBalanceAsync RPC invocation (or an applicable error).
+type FutureGetAddressBalanceResult cmdRes
+
+// Receive waits for the response promised by the future and returns the
+// balance of an address.
+func (r *FutureGetAddressBalanceResult) Receive() (*chainjson.GetAddressBalanceResult, error) {
+	res, err := receiveFuture(r.ctx, r.c)
+	if err != nil {
+		return nil, err
+	}
+
+	// Unmarshal result as a getaddressbalance result object.
+	var balance chainjson.GetAddressBalanceResult
+	err = json.Unmarshal(res, &balance)
+	if err != nil {
+		return nil, err
+	}
+
+	return &balance, nil
+}
+
+// GetAddressBalanceAsync returns an instance of a type that can be used to get
+// the result of the RPC at some future time by invoking the Receive function on
+// the returned instance.
+//
+// See GetAddressBalance for the blocking version and more details.
+func (c *Client) GetAddressBalanceAsync(ctx context.Context, address stdaddr.Address) *FutureGetAddressBalanceResult {
+	cmd := chainjson.NewGetAddressBalanceCmd(address.String())
+	return (*FutureGetAddressBalanceResult)(c.sendCmd(ctx, cmd))
+}
+
+// GetAddressBalance returns the balance of the provided address along with its
+// received and sent totals.
+//
+// NOTE: This requires the server to have the address balance index enabled.
+func (c *Client) GetAddressBalance(ctx context.Context, address stdaddr.Address) (*chainjson.GetAddressBalanceResult, error) {
+	return c.GetAddressBalanceAsync(ctx, address).Receive()
+}
+
+// FutureGetAddressDeltasResult is a future promise to deliver the result of a
+// GetAddressDeltasAsync RPC invocation (or an applicable error).
+type FutureGetAddressDeltasResult cmdRes
+
+// Receive waits for the response promised by the future and returns the
+// credits and debits of an address.
+func (r *FutureGetAddressDeltasResult) Receive() ([]chainjson.AddressDeltaResult, error) {
+	res, err := receiveFuture(r.ctx, r.c)
+	if err != nil {
+		return nil, err
+	}
+
+	// Unmarshal result as an array of address delta objects.
+	var deltas []chainjson.AddressDeltaResult
+	err = json.Unmarshal(res, &deltas)
+	if err != nil {
+		return nil, err
+	}
+
+	return deltas, nil
+}
+
+// GetAddressDeltasAsync returns an instance of a type that can be used to get
+// the result of the RPC at some future time by invoking the Receive function on
+// the returned instance.
+//
+// See GetAddressDeltas for the blocking version and more details.
+func (c *Client) GetAddressDeltasAsync(ctx context.Context, address stdaddr.Address, skip, count int, includeMempool bool) *FutureGetAddressDeltasResult {
+	cmd := chainjson.NewGetAddressDeltasCmd(address.String(), &skip, &count,
+		&includeMempool)
+	return (*FutureGetAddressDeltasResult)(c.sendCmd(ctx, cmd))
+}
+
+// GetAddressDeltas returns the credits and debits of the provided address in
+// the order they appear in the blockchain, optionally followed by those made
+// by transactions in the memory pool.
+//
+// NOTE: This requires the server to have the address balance index enabled.
+func (c *Client) GetAddressDeltas(ctx context.Context, address stdaddr.Address, skip, count int, includeMempool bool) ([]chainjson.AddressDeltaResult, error) {
+	return c.GetAddressDeltasAsync(ctx, address, skip, count,
+		includeMempool).Receive()
+}
+
+// FutureGetAddressUtxosResult is a future promise to deliver the result of a
+// GetAddressUtxosAsync RPC invocation (or an applicable error).
+type FutureGetAddressUtxosResult cmdRes
+
+// Receive waits for the response promised by the future and returns the
+// unspent outputs paid to an address.
+func (r *FutureGetAddressUtxosResult) Receive() ([]chainjson.AddressUtxoResult, error) {
+	res, err := receiveFuture(r.ctx, r.c)
+	if err != nil {
+		return nil, err
+	}
+
+	// Unmarshal result as an array of address utxo objects.
+	var utxos []chainjson.AddressUtxoResult
+	err = json.Unmarshal(res, &utxos)
+	if err != nil {
+		return nil, err
+	}
+
+	return utxos, nil
+}
+
+// GetAddressUtxosAsync returns an instance of a type that can be used to get
+// the result of the RPC at some future time by invoking the Receive function on
+// the returned instance.
+//
+// See GetAddressUtxos for the blocking version and more details.
+func (c *Client) GetAddressUtxosAsync(ctx context.Context, address stdaddr.Address, skip, count int) *FutureGetAddressUtxosResult {
+	cmd := chainjson.NewGetAddressUtxosCmd(address.String(), &skip, &count)
+	return (*FutureGetAddressUtxosResult)(c.sendCmd(ctx, cmd))
+}
+
+// GetAddressUtxos returns the confirmed unspent outputs paid to the provided
+// address ordered by block height.
+//
+// NOTE: This requires the server to have the address balance index enabled.
+func (c *Client) GetAddressUtxos(ctx context.Context, address stdaddr.Address, skip, count int) ([]chainjson.AddressUtxoResult, error) {
+	return c.GetAddressUtxosAsync(ctx, address, skip, count).Receive()
+}
+
+// FutureGetAddressTicketsResult is a future promise to deliver the result of a
+// GetAddressTicketsAsync RPC invocation (or an applicable error).
+type FutureGetAddressTicketsResult cmdRes
+
+// Receive waits for the response promised by the future and returns the
+// lifecycle details of the tickets associated with an address.
+func (r *FutureGetAddressTicketsResult) Receive() ([]chainjson.GetTicketInfoResult, error) {
+	res, err := receiveFuture(r.ctx, r.c)
+	if err != nil {
+		return nil, err
+	}
+
+	// Unmarshal result as an array of ticket info objects.
+	var tickets []chainjson.GetTicketInfoResult
+	err = json.Unmarshal(res, &tickets)
+	if err != nil {
+		return nil, err
+	}
+
+	return tickets, nil
+}
+
+// GetAddressTicketsAsync returns an instance of a type that can be used to get
+// the result of the RPC at some future time by invoking the Receive function on
+// the returned instance.
+//
+// See GetAddressTickets for the blocking version and more details.
+func (c *Client) GetAddressTicketsAsync(ctx context.Context, address stdaddr.Address, skip, count int) *FutureGetAddressTicketsResult {
+	cmd := chainjson.NewGetAddressTicketsCmd(address.String(), &skip, &count)
+	return (*FutureGetAddressTicketsResult)(c.sendCmd(ctx, cmd))
+}
+
+// GetAddressTickets returns the lifecycle details of the tickets that either
+// pay their voting rights to or commit their rewards to the provided address
+// ordered by purchase height.
+//
+// NOTE: This requires the server to have the ticket index enabled.
+func (c *Client) GetAddressTickets(ctx context.Context, address stdaddr.Address, skip, count int) ([]chainjson.GetTicketInfoResult, error) {
+	return c.GetAddressTicketsAsync(ctx, address, skip, count).Receive()
+}
+
+// FutureGetTicketInfoResult is a future promise to deliver the result of a
+// GetTicketInfoAsync RPC invocation (or an applicable error).
+type FutureGetTicketInfoResult cmdRes
+
+// Receive waits for the response promised by the future and returns the
+// lifecycle details of a ticket.
+func (r *FutureGetTicketInfoResult) Receive() (*chainjson.GetTicketInfoResult, error) {
+	res, err := receiveFuture(r.ctx, r.c)
+	if err != nil {
+		return nil, err
+	}
+
+	// Unmarshal result as a getticketinfo result object.
+	var info chainjson.GetTicketInfoResult
+	err = json.Unmarshal(res, &info)
+	if err != nil {
+		return nil, err
+	}
+
+	return &info, nil
+}
+
+// GetTicketInfoAsync returns an instance of a type that can be used to get the
+// result of the RPC at some future time by invoking the Receive function on the
+// returned instance.
+//
+// See GetTicketInfo for the blocking version and more details.
+func (c *Client) GetTicketInfoAsync(ctx context.Context, ticketHash *chainhash.Hash) *FutureGetTicketInfoResult {
+	cmd := chainjson.NewGetTicketInfoCmd(ticketHash.String())
+	return (*FutureGetTicketInfoResult)(c.sendCmd(ctx, cmd))
+}
+
+// GetTicketInfo returns the purchase, vote, revocation, miss, and expiry
+// details of the provided ticket.
+//
+// NOTE: This requires the server to have the ticket index enabled.
+func (c *Client) GetTicketInfo(ctx context.Context, ticketHash *chainhash.Hash) (*chainjson.GetTicketInfoResult, error) {
+	return c.GetTicketInfoAsync(ctx, ticketHash).Receive()
+}
+
 // FutureRescanResult is a future promise to deliver the result of a
 // RescanAsynnc RPC invocation (or an applicable error).
 type FutureRescanResult cmdRes
@@ -925,6 +1263,12 @@ func (r *FutureGetCFilterV2Result) Receive() (*CFilterV2Result, error) {
 		return nil, err
 	}
 
+	return parseCFilterV2Result(&filterResult)
+}
+
+// parseCFilterV2Result decodes the provided getcfilterv2 result into its
+// native types.
+func parseCFilterV2Result(filterResult *chainjson.GetCFilterV2Result) (*CFilterV2Result, error) {
 	blockHash, err := chainhash.NewHashFromStr(filterResult.BlockHash)
 	if err != nil {
 		return nil, err
@@ -973,6 +1317,129 @@ func (c *Client) GetCFilterV2(ctx context.Context, blockHash *chainhash.Hash) (*
 	return c.GetCFilterV2Async(ctx, blockHash).Receive()
 }
 
+// FutureGetCFilterV2RangeResult is a future promise to deliver the result of a
+// GetCFilterV2RangeAsync RPC invocation (or an applicable error).
+type FutureGetCFilterV2RangeResult cmdRes
+
+// Receive waits for the response promised by the future and returns the
+// version 2 block filters for the requested range of blocks.
+func (r *FutureGetCFilterV2RangeResult) Receive() ([]*CFilterV2Result, error) {
+	res, err := receiveFuture(r.ctx, r.c)
+	if err != nil {
+		return nil, err
+	}
+
+	var filterResults []chainjson.GetCFilterV2Result
+	err = json.Unmarshal(res, &filterResults)
+	if err != nil {
+		return nil, err
+	}
+
+	filters := make([]*CFilterV2Result, 0, len(filterResults))
+	for i := range filterResults {
+		filter, err := parseCFilterV2Result(&filterResults[i])
+		if err != nil {
+			return nil, err
+		}
+		filters = append(filters, filter)
+	}
+	return filters, nil
+}
+
+// GetCFilterV2RangeAsync returns an instance of a type that can be used to get
+// the result of the RPC at some future time by invoking the Receive function on
+// the returned instance.
+//
+// See GetCFilterV2Range for the blocking version and more details.
+func (c *Client) GetCFilterV2RangeAsync(ctx context.Context, startHeight int64, count uint32) *FutureGetCFilterV2RangeResult {
+	cmd := chainjson.NewGetCFilterV2RangeCmd(startHeight, count)
+	return (*FutureGetCFilterV2RangeResult)(c.sendCmd(ctx, cmd))
+}
+
+// GetCFilterV2Range returns the version 2 block filters for up to count blocks
+// in the main chain starting at the given height along with proofs that can be
+// used to prove each filter is committed to by its block header.
+func (c *Client) GetCFilterV2Range(ctx context.Context, startHeight int64, count uint32) ([]*CFilterV2Result, error) {
+	return c.GetCFilterV2RangeAsync(ctx, startHeight, count).Receive()
+}
+
+// FutureGetCFilterHeadersResult is a future promise to deliver the result of a
+// GetCFilterHeadersAsync RPC invocation (or an applicable error).
+type FutureGetCFilterHeadersResult cmdRes
+
+// Receive waits for the response promised by the future and returns the
+// filter hashes and filter headers for the requested range of blocks.
+func (r *FutureGetCFilterHeadersResult) Receive() (*chainjson.GetCFilterHeadersResult, error) {
+	res, err := receiveFuture(r.ctx, r.c)
+	if err != nil {
+		return nil, err
+	}
+
+	var result chainjson.GetCFilterHeadersResult
+	err = json.Unmarshal(res, &result)
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// GetCFilterHeadersAsync returns an instance of a type that can be used to get
+// the result of the RPC at some future time by invoking the Receive function on
+// the returned instance.
+//
+// See GetCFilterHeaders for the blocking version and more details.
+func (c *Client) GetCFilterHeadersAsync(ctx context.Context, startHeight int64, count uint32) *FutureGetCFilterHeadersResult {
+	cmd := chainjson.NewGetCFilterHeadersCmd(startHeight, count)
+	return (*FutureGetCFilterHeadersResult)(c.sendCmd(ctx, cmd))
+}
+
+// GetCFilterHeaders returns the version 2 filter hashes and filter headers for
+// up to count blocks in the main chain starting at the given height.
+//
+// NOTE: This requires the server to have the filter header index enabled.
+func (c *Client) GetCFilterHeaders(ctx context.Context, startHeight int64, count uint32) (*chainjson.GetCFilterHeadersResult, error) {
+	return c.GetCFilterHeadersAsync(ctx, startHeight, count).Receive()
+}
+
+// FutureSearchScriptsResult is a future promise to deliver the result of a
+// SearchScriptsAsync RPC invocation (or an applicable error).
+type FutureSearchScriptsResult cmdRes
+
+// Receive waits for the response promised by the future and returns the
+// outputs that match the search.
+func (r *FutureSearchScriptsResult) Receive() ([]chainjson.SearchScriptsResult, error) {
+	res, err := receiveFuture(r.ctx, r.c)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []chainjson.SearchScriptsResult
+	err = json.Unmarshal(res, &result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// SearchScriptsAsync returns an instance of a type that can be used to get the
+// result of the RPC at some future time by invoking the Receive function on the
+// returned instance.
+//
+// See SearchScripts for the blocking version and more details.
+func (c *Client) SearchScriptsAsync(ctx context.Context, data string, mode chainjson.SearchScriptsMode, skip, count int) *FutureSearchScriptsResult {
+	cmd := chainjson.NewSearchScriptsCmd(data, &mode, &skip, &count)
+	return (*FutureSearchScriptsResult)(c.sendCmd(ctx, cmd))
+}
+
+// SearchScripts returns the outputs in the main chain with scripts that do not
+// decode to addresses that match the provided data, which is interpreted
+// according to the given mode.
+//
+// NOTE: This requires the server to have the script index enabled.
+func (c *Client) SearchScripts(ctx context.Context, data string, mode chainjson.SearchScriptsMode, skip, count int) ([]chainjson.SearchScriptsResult, error) {
+	return c.SearchScriptsAsync(ctx, data, mode, skip, count).Receive()
+}
+
 // FutureEstimateSmartFeeResult is a future promise to deliver the result of a
 // EstimateSmartFee RPC invocation (or an applicable error).
 type FutureEstimateSmartFeeResult cmdRes
@@ -69,6 +69,69 @@ func (c *Client) DebugLevel(ctx context.Context, levelSpec string) (string, erro
 	return c.DebugLevelAsync(ctx, levelSpec).Receive()
 }
 
+// FutureDisableIndexResult is a future promise to deliver the result of a
+// DisableIndexAsync RPC invocation (or an applicable error).
+type FutureDisableIndexResult cmdRes
+
+// Receive waits for the response promised by the future and returns an error if
+// any occurred when disabling the index.
+func (r *FutureDisableIndexResult) Receive() error {
+	_, err := receiveFuture(r.ctx, r.c)
+	return err
+}
+
+// DisableIndexAsync returns an instance of a type that can be used to get the
+// result of the RPC at some future time by invoking the Receive function on
+// the returned instance.
+//
+// See DisableIndex for the blocking version and more details.
+//
+// NOTE: This is a dcrd extension.
+func (c *Client) DisableIndexAsync(ctx context.Context, name string) *FutureDisableIndexResult {
+	cmd := chainjson.NewDisableIndexCmd(name)
+	return (*FutureDisableIndexResult)(c.sendCmd(ctx, cmd))
+}
+
+// DisableIndex stops the server from maintaining the named optional index and
+// removes the index from its database in the background.
+//
+// NOTE: This is a dcrd extension.
+func (c *Client) DisableIndex(ctx context.Context, name string) error {
+	return c.DisableIndexAsync(ctx, name).Receive()
+}
+
+// FutureEnableIndexResult is a future promise to deliver the result of an
+// EnableIndexAsync RPC invocation (or an applicable error).
+type FutureEnableIndexResult cmdRes
+
+// Receive waits for the response promised by the future and returns an error if
+// any occurred when enabling the index.
+func (r *FutureEnableIndexResult) Receive() error {
+	_, err := receiveFuture(r.ctx, r.c)
+	return err
+}
+
+// EnableIndexAsync returns an instance of a type that can be used to get the
+// result of the RPC at some future time by invoking the Receive function on
+// the returned instance.
+//
+// See EnableIndex for the blocking version and more details.
+//
+// NOTE: This is a dcrd extension.
+func (c *Client) EnableIndexAsync(ctx context.Context, name string) *FutureEnableIndexResult {
+	cmd := chainjson.NewEnableIndexCmd(name)
+	return (*FutureEnableIndexResult)(c.sendCmd(ctx, cmd))
+}
+
+// EnableIndex starts maintaining the named optional index without restarting
+// the server.  The index catches up to the main chain in the background and
+// GetIndexInfo can be used to determine when it is synced.
+//
+// NOTE: This is a dcrd extension.
+func (c *Client) EnableIndex(ctx context.Context, name string) error {
+	return c.EnableIndexAsync(ctx, name).Receive()
+}
+
 // FutureEstimateStakeDiffResult is a future promise to deliver the result of a
 // EstimateStakeDiffAsync RPC invocation (or an applicable error).
 type FutureEstimateStakeDiffResult cmdRes
@@ -531,6 +594,52 @@ func (c *Client) GetHeaders(ctx context.Context, blockLocators []*chainhash.Hash
 	return c.GetHeadersAsync(ctx, blockLocators, hashStop).Receive()
 }
 
+// FutureGetIndexInfoResult is a future promise to deliver the result of a
+// GetIndexInfoAsync RPC invocation (or an applicable error).
+type FutureGetIndexInfoResult cmdRes
+
+// Receive waits for the response promised by the future and returns the status
+// of the optional indexes keyed by their name.
+func (r *FutureGetIndexInfoResult) Receive() (map[string]chainjson.IndexInfoResult, error) {
+	res, err := receiveFuture(r.ctx, r.c)
+	if err != nil {
+		return nil, err
+	}
+
+	// Unmarshal result as a map of index status objects.
+	var result map[string]chainjson.IndexInfoResult
+	err = json.Unmarshal(res, &result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetIndexInfoAsync returns an instance of a type that can be used to get the
+// result of the RPC at some future time by invoking the Receive function on
+// the returned instance.
+//
+// See GetIndexInfo for the blocking version and more details.
+//
+// NOTE: This is a dcrd extension.
+func (c *Client) GetIndexInfoAsync(ctx context.Context, name string) *FutureGetIndexInfoResult {
+	var namePtr *string
+	if name != "" {
+		namePtr = &name
+	}
+	cmd := chainjson.NewGetIndexInfoCmd(namePtr)
+	return (*FutureGetIndexInfoResult)(c.sendCmd(ctx, cmd))
+}
+
+// GetIndexInfo returns the status of the enabled optional indexes along with
+// any indexes that are still being removed.  Only the status of the named
+// index is returned when the name is not empty.
+//
+// NOTE: This is a dcrd extension.
+func (c *Client) GetIndexInfo(ctx context.Context, name string) (map[string]chainjson.IndexInfoResult, error) {
+	return c.GetIndexInfoAsync(ctx, name).Receive()
+}
+
 // FutureGetStakeDifficultyResult is a future promise to deliver the result of a
 // GetStakeDifficultyAsync RPC invocation (or an applicable error).
 type FutureGetStakeDifficultyResult cmdRes
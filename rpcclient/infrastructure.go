@@ -300,6 +300,26 @@ func (c *Client) trackRegisteredNtfns(cmd interface{}) {
 
 	case *chainjson.NotifyTSpendCmd:
 		c.ntfnState.notifyTSpend = true
+
+	case *chainjson.NotifyAddressesCmd:
+		for _, addr := range bcmd.Addresses {
+			c.ntfnState.notifyAddresses[addr] = struct{}{}
+		}
+		if bcmd.Cursor != nil && c.ntfnState.addressCursor == nil {
+			cursor := *bcmd.Cursor
+			c.ntfnState.addressCursor = &cursor
+		}
+
+	case *chainjson.StopNotifyAddressesCmd:
+		if len(bcmd.Addresses) == 0 {
+			c.ntfnState.notifyAddresses = make(map[string]struct{})
+		}
+		for _, addr := range bcmd.Addresses {
+			delete(c.ntfnState.notifyAddresses, addr)
+		}
+		if len(c.ntfnState.notifyAddresses) == 0 {
+			c.ntfnState.addressCursor = nil
+		}
 	}
 }
 
@@ -602,6 +622,21 @@ func (c *Client) reregisterNtfns(ctx context.Context) error {
 		}
 	}
 
+	// Reregister notifyaddresses if needed.  Notifications resume after the
+	// most recent mined transaction that was received.
+	if len(stateCopy.notifyAddresses) != 0 {
+		log.Debugf("Reregistering [notifyaddresses] (%d addresses)",
+			len(stateCopy.notifyAddresses))
+		addrs := make([]string, 0, len(stateCopy.notifyAddresses))
+		for addr := range stateCopy.notifyAddresses {
+			addrs = append(addrs, addr)
+		}
+		cmd := chainjson.NewNotifyAddressesCmd(addrs, stateCopy.addressCursor)
+		if _, err := receiveFuture(ctx, c.sendCmd(ctx, cmd).c); err != nil {
+			return err
+		}
+	}
+
 	// Reregister notifynewtransactions if needed.
 	if stateCopy.notifyNewTx || stateCopy.notifyNewTxVerbose {
 		log.Debugf("Reregistering [notifynewtransactions] (verbose=%v)",
@@ -8,6 +8,7 @@ package rpcclient
 import (
 	"context"
 	"encoding/json"
+	"time"
 
 	chainjson "github.com/EXCCoin/exccd/rpc/jsonrpc/types/v3"
 )
@@ -35,6 +36,25 @@ func (cmd AddNodeCommand) String() string {
 	return string(cmd)
 }
 
+// SetBanCommand enumerates the available commands that the SetBan function
+// accepts.
+type SetBanCommand string
+
+// Constants used to indicate the command for the SetBan function.
+const (
+	// SBAdd indicates the specified IP address or subnet should be banned.
+	SBAdd SetBanCommand = "add"
+
+	// SBRemove indicates the ban for the specified IP address or subnet
+	// should be removed.
+	SBRemove SetBanCommand = "remove"
+)
+
+// String returns the SetBanCommand in human-readable form.
+func (cmd SetBanCommand) String() string {
+	return string(cmd)
+}
+
 // FutureAddNodeResult is a future promise to deliver the result of an
 // AddNodeAsync RPC invocation (or an applicable error).
 type FutureAddNodeResult cmdRes
@@ -286,6 +306,44 @@ func (c *Client) GetNetTotals(ctx context.Context) (*chainjson.GetNetTotalsResul
 	return c.GetNetTotalsAsync(ctx).Receive()
 }
 
+// FutureGetNetMsgStatsResult is a future promise to deliver the result of a
+// GetNetMsgStatsAsync RPC invocation (or an applicable error).
+type FutureGetNetMsgStatsResult cmdRes
+
+// Receive waits for the response promised by the future and returns the number
+// of messages and bytes sent and received per message type.
+func (r *FutureGetNetMsgStatsResult) Receive() (*chainjson.GetNetMsgStatsResult, error) {
+	res, err := receiveFuture(r.ctx, r.c)
+	if err != nil {
+		return nil, err
+	}
+
+	// Unmarshal result as a getnetmsgstats result object.
+	var stats chainjson.GetNetMsgStatsResult
+	err = json.Unmarshal(res, &stats)
+	if err != nil {
+		return nil, err
+	}
+
+	return &stats, nil
+}
+
+// GetNetMsgStatsAsync returns an instance of a type that can be used to get the
+// result of the RPC at some future time by invoking the Receive function on the
+// returned instance.
+//
+// See GetNetMsgStats for the blocking version and more details.
+func (c *Client) GetNetMsgStatsAsync(ctx context.Context) *FutureGetNetMsgStatsResult {
+	cmd := chainjson.NewGetNetMsgStatsCmd()
+	return (*FutureGetNetMsgStatsResult)(c.sendCmd(ctx, cmd))
+}
+
+// GetNetMsgStats returns the number of messages and bytes sent to and received
+// from all peers since the server started per message type.
+func (c *Client) GetNetMsgStats(ctx context.Context) (*chainjson.GetNetMsgStatsResult, error) {
+	return c.GetNetMsgStatsAsync(ctx).Receive()
+}
+
 // FutureGetNetworkInfoResult is a future promise to deliver the result of a
 // GetNetworkInfo RPC invocation (or an applicable error).
 type FutureGetNetworkInfoResult cmdRes
@@ -322,3 +380,102 @@ func (c *Client) GetNetworkInfoAsync(ctx context.Context) *FutureGetNetworkInfoR
 func (c *Client) GetNetworkInfo(ctx context.Context) (*chainjson.GetNetworkInfoResult, error) {
 	return c.GetNetworkInfoAsync(ctx).Receive()
 }
+
+// FutureSetBanResult is a future promise to deliver the result of a
+// SetBanAsync RPC invocation (or an applicable error).
+type FutureSetBanResult cmdRes
+
+// Receive waits for the response promised by the future and returns an error if
+// any occurred when performing the specified command.
+func (r *FutureSetBanResult) Receive() error {
+	_, err := receiveFuture(r.ctx, r.c)
+	return err
+}
+
+// SetBanAsync returns an instance of a type that can be used to get the result
+// of the RPC at some future time by invoking the Receive function on the
+// returned instance.
+//
+// See SetBan for the blocking version and more details.
+func (c *Client) SetBanAsync(ctx context.Context, command SetBanCommand, subnet string, duration time.Duration) *FutureSetBanResult {
+	var durationSecs *int64
+	if duration != 0 {
+		secs := int64(duration / time.Second)
+		durationSecs = &secs
+	}
+	cmd := chainjson.NewSetBanCmd(chainjson.SetBanSubCmd(command), subnet,
+		durationSecs)
+	return (*FutureSetBanResult)(c.sendCmd(ctx, cmd))
+}
+
+// SetBan attempts to perform the passed command on the passed IP address or
+// subnet in CIDR notation.  For example, it can be used to ban a subnet for the
+// passed duration or to remove an existing ban.  A duration of zero results in
+// the server's default ban duration and is ignored when removing a ban.
+func (c *Client) SetBan(ctx context.Context, command SetBanCommand, subnet string, duration time.Duration) error {
+	return c.SetBanAsync(ctx, command, subnet, duration).Receive()
+}
+
+// FutureListBannedResult is a future promise to deliver the result of a
+// ListBannedAsync RPC invocation (or an applicable error).
+type FutureListBannedResult cmdRes
+
+// Receive waits for the response promised by the future and returns the banned
+// IP addresses and subnets.
+func (r *FutureListBannedResult) Receive() ([]chainjson.ListBannedResult, error) {
+	res, err := receiveFuture(r.ctx, r.c)
+	if err != nil {
+		return nil, err
+	}
+
+	// Unmarshal result as an array of listbanned result objects.
+	var bans []chainjson.ListBannedResult
+	err = json.Unmarshal(res, &bans)
+	if err != nil {
+		return nil, err
+	}
+
+	return bans, nil
+}
+
+// ListBannedAsync returns an instance of a type that can be used to get the
+// result of the RPC at some future time by invoking the Receive function on the
+// returned instance.
+//
+// See ListBanned for the blocking version and more details.
+func (c *Client) ListBannedAsync(ctx context.Context) *FutureListBannedResult {
+	cmd := chainjson.NewListBannedCmd()
+	return (*FutureListBannedResult)(c.sendCmd(ctx, cmd))
+}
+
+// ListBanned returns the banned IP addresses and subnets along with when the
+// bans were created and when they expire.
+func (c *Client) ListBanned(ctx context.Context) ([]chainjson.ListBannedResult, error) {
+	return c.ListBannedAsync(ctx).Receive()
+}
+
+// FutureClearBannedResult is a future promise to deliver the result of a
+// ClearBannedAsync RPC invocation (or an applicable error).
+type FutureClearBannedResult cmdRes
+
+// Receive waits for the response promised by the future and returns an error if
+// any occurred when clearing the bans.
+func (r *FutureClearBannedResult) Receive() error {
+	_, err := receiveFuture(r.ctx, r.c)
+	return err
+}
+
+// ClearBannedAsync returns an instance of a type that can be used to get the
+// result of the RPC at some future time by invoking the Receive function on the
+// returned instance.
+//
+// See ClearBanned for the blocking version and more details.
+func (c *Client) ClearBannedAsync(ctx context.Context) *FutureClearBannedResult {
+	cmd := chainjson.NewClearBannedCmd()
+	return (*FutureClearBannedResult)(c.sendCmd(ctx, cmd))
+}
+
+// ClearBanned removes all banned IP addresses and subnets.
+func (c *Client) ClearBanned(ctx context.Context) error {
+	return c.ClearBannedAsync(ctx).Receive()
+}
@@ -41,6 +41,8 @@ type notificationState struct {
 	notifyNewTickets            bool
 	notifyNewTx                 bool
 	notifyNewTxVerbose          bool
+	notifyAddresses             map[string]struct{}
+	addressCursor               *chainjson.AddressCursor
 }
 
 // Copy returns a deep copy of the receiver.
@@ -55,12 +57,23 @@ func (s *notificationState) Copy() *notificationState {
 	stateCopy.notifyNewTx = s.notifyNewTx
 	stateCopy.notifyNewTxVerbose = s.notifyNewTxVerbose
 
+	stateCopy.notifyAddresses = make(map[string]struct{}, len(s.notifyAddresses))
+	for addr := range s.notifyAddresses {
+		stateCopy.notifyAddresses[addr] = struct{}{}
+	}
+	if s.addressCursor != nil {
+		cursor := *s.addressCursor
+		stateCopy.addressCursor = &cursor
+	}
+
 	return &stateCopy
 }
 
 // newNotificationState returns a new notification state ready to be populated.
 func newNotificationState() *notificationState {
-	return &notificationState{}
+	return &notificationState{
+		notifyAddresses: make(map[string]struct{}),
+	}
 }
 
 // newNilFutureResult returns a new future result that already has the result
@@ -114,6 +127,14 @@ type NotificationHandlers struct {
 	// the client's transaction filter.
 	OnRelevantTxAccepted func(transaction []byte)
 
+	// OnAddressTx is invoked when a transaction involving any of the
+	// addresses registered with NotifyAddresses is accepted into the memory
+	// pool or mined.  The cursor is only set for mined transactions.  It will
+	// only be invoked if a preceding call to NotifyAddresses has been made to
+	// register for the notification and the function is non-nil.
+	OnAddressTx func(addresses []string, transaction []byte,
+		cursor *chainjson.AddressCursor)
+
 	// OnReorganization is invoked when the blockchain begins reorganizing.
 	// It will only be invoked if a preceding call to NotifyBlocks has been
 	// made to register for the notification and the function is non-nil.
@@ -262,6 +283,30 @@ func (c *Client) handleNotification(ntfn *rawNotification) {
 
 		c.ntfnHandlers.OnRelevantTxAccepted(transaction)
 
+	// OnAddressTx
+	case chainjson.AddressTxNtfnMethod:
+		addresses, transaction, cursor, err := parseAddressTxNtfnParams(ntfn.Params)
+		if err != nil {
+			log.Warnf("Received invalid addresstx notification: %v", err)
+			return
+		}
+
+		// Track the cursor of the most recent mined transaction so
+		// notifications resume after it on reconnect.
+		if cursor != nil {
+			c.ntfnStateLock.Lock()
+			c.ntfnState.addressCursor = cursor
+			c.ntfnStateLock.Unlock()
+		}
+
+		// Ignore the notification if the client is not interested in
+		// it.
+		if c.ntfnHandlers.OnAddressTx == nil {
+			return
+		}
+
+		c.ntfnHandlers.OnAddressTx(addresses, transaction, cursor)
+
 	// OnReorganization
 	case chainjson.ReorganizationNtfnMethod:
 		// Ignore the notification if the client is not interested in
@@ -492,6 +537,37 @@ func parseRelevantTxAcceptedParams(params []json.RawMessage) (transaction []byte
 	return parseHexParam(params[0])
 }
 
+// parseAddressTxNtfnParams parses out the addresses, transaction, and optional
+// cursor included in an addresstx notification.
+func parseAddressTxNtfnParams(params []json.RawMessage) ([]string, []byte,
+	*chainjson.AddressCursor, error) {
+
+	if len(params) != 2 && len(params) != 3 {
+		return nil, nil, nil, wrongNumParams(len(params))
+	}
+
+	// Unmarshal first parameter as a slice of strings.
+	var addresses []string
+	if err := json.Unmarshal(params[0], &addresses); err != nil {
+		return nil, nil, nil, err
+	}
+
+	transaction, err := parseHexParam(params[1])
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	// Unmarshal optional third parameter as an address cursor.
+	var cursor *chainjson.AddressCursor
+	if len(params) == 3 {
+		if err := json.Unmarshal(params[2], &cursor); err != nil {
+			return nil, nil, nil, err
+		}
+	}
+
+	return addresses, transaction, cursor, nil
+}
+
 func parseReorganizationNtfnParams(params []json.RawMessage) (*chainhash.Hash,
 	int32, *chainhash.Hash, int32, error) {
 	errorOut := func(err error) (*chainhash.Hash, int32, *chainhash.Hash,
@@ -1168,3 +1244,111 @@ func (c *Client) LoadTxFilterAsync(ctx context.Context, reload bool, addresses [
 func (c *Client) LoadTxFilter(ctx context.Context, reload bool, addresses []stdaddr.Address, outPoints []wire.OutPoint) error {
 	return c.LoadTxFilterAsync(ctx, reload, addresses, outPoints).Receive()
 }
+
+// FutureNotifyAddressesResult is a future promise to deliver the result of a
+// NotifyAddressesAsync RPC invocation (or an applicable error).
+type FutureNotifyAddressesResult cmdRes
+
+// Receive waits for the response promised by the future and returns an error
+// if the registration was not successful.
+func (r *FutureNotifyAddressesResult) Receive() error {
+	_, err := receiveFuture(r.ctx, r.c)
+	return err
+}
+
+// NotifyAddressesAsync returns an instance of a type that can be used to get
+// the result of the RPC at some future time by invoking the Receive function
+// on the returned instance.
+//
+// See NotifyAddresses for the blocking version and more details.
+//
+// NOTE: This is a dcrd extension and requires a websocket connection.
+func (c *Client) NotifyAddressesAsync(ctx context.Context, addresses []stdaddr.Address,
+	cursor *chainjson.AddressCursor) *FutureNotifyAddressesResult {
+
+	// Not supported in HTTP POST mode.
+	if c.config.HTTPPostMode {
+		return (*FutureNotifyAddressesResult)(newFutureError(ctx, ErrWebsocketsRequired))
+	}
+
+	// Ignore the notification if the client is not interested in
+	// notifications.
+	if c.ntfnHandlers == nil {
+		return (*FutureNotifyAddressesResult)(newNilFutureResult(ctx))
+	}
+
+	addrStrs := make([]string, len(addresses))
+	for i, a := range addresses {
+		addrStrs[i] = a.String()
+	}
+	cmd := chainjson.NewNotifyAddressesCmd(addrStrs, cursor)
+	return (*FutureNotifyAddressesResult)(c.sendCmd(ctx, cmd))
+}
+
+// NotifyAddresses registers the client to receive notifications every time a
+// transaction involving any of the passed addresses is accepted into the
+// memory pool or mined.  The notifications are delivered to the notification
+// handlers associated with the client.  Calling this function has no effect if
+// there are no notification handlers and will result in an error if the client
+// is configured to run in HTTP POST mode.
+//
+// When a cursor from a previous notification is provided, all mined
+// transactions after it that involve the addresses are delivered first,
+// followed by all unconfirmed transactions that involve them.  The client
+// tracks the cursor of the most recent mined transaction and automatically
+// resumes from it when it reconnects.
+//
+// The notifications delivered as a result of this call will be via
+// OnAddressTx.
+//
+// NOTE: This is a dcrd extension and requires a websocket connection.
+func (c *Client) NotifyAddresses(ctx context.Context, addresses []stdaddr.Address, cursor *chainjson.AddressCursor) error {
+	return c.NotifyAddressesAsync(ctx, addresses, cursor).Receive()
+}
+
+// FutureStopNotifyAddressesResult is a future promise to deliver the result of
+// a StopNotifyAddressesAsync RPC invocation (or an applicable error).
+type FutureStopNotifyAddressesResult cmdRes
+
+// Receive waits for the response promised by the future and returns an error
+// if the unregistration was not successful.
+func (r *FutureStopNotifyAddressesResult) Receive() error {
+	_, err := receiveFuture(r.ctx, r.c)
+	return err
+}
+
+// StopNotifyAddressesAsync returns an instance of a type that can be used to
+// get the result of the RPC at some future time by invoking the Receive
+// function on the returned instance.
+//
+// See StopNotifyAddresses for the blocking version and more details.
+//
+// NOTE: This is a dcrd extension and requires a websocket connection.
+func (c *Client) StopNotifyAddressesAsync(ctx context.Context, addresses []stdaddr.Address) *FutureStopNotifyAddressesResult {
+	// Not supported in HTTP POST mode.
+	if c.config.HTTPPostMode {
+		return (*FutureStopNotifyAddressesResult)(newFutureError(ctx, ErrWebsocketsRequired))
+	}
+
+	// Ignore the notification if the client is not interested in
+	// notifications.
+	if c.ntfnHandlers == nil {
+		return (*FutureStopNotifyAddressesResult)(newNilFutureResult(ctx))
+	}
+
+	addrStrs := make([]string, len(addresses))
+	for i, a := range addresses {
+		addrStrs[i] = a.String()
+	}
+	cmd := chainjson.NewStopNotifyAddressesCmd(addrStrs)
+	return (*FutureStopNotifyAddressesResult)(c.sendCmd(ctx, cmd))
+}
+
+// StopNotifyAddresses cancels the notifications registered with
+// NotifyAddresses for the passed addresses.  Notifications for all addresses
+// are cancelled when none are provided.
+//
+// NOTE: This is a dcrd extension and requires a websocket connection.
+func (c *Client) StopNotifyAddresses(ctx context.Context, addresses []stdaddr.Address) error {
+	return c.StopNotifyAddressesAsync(ctx, addresses).Receive()
+}
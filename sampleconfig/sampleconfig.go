@@ -22,6 +22,20 @@ const fileContents = `[Application Options]
 ; datadir=$LOCALAPPDATA/Exccd/data                 ; Windows
 ; datadir=~/Library/Application Support/Exccd/data ; macOS
 
+; The directories to store the block and UTXO databases in.  They default to the
+; data directory and may be placed elsewhere, such as keeping the UTXO database
+; on faster storage than the block database.  The databases are stored in a
+; network-specific subdirectory.  Existing databases may be moved with the
+; dbtool relocate command.
+; blocksdir=/mnt/hdd/exccd/blocks
+; utxodir=/mnt/nvme/exccd/utxo
+
+; The directory to store the optional indexes in.  The indexes are stored in the
+; block database by default.  Specifying a directory stores them in a separate
+; database there instead.  Existing indexes may be moved out of the block
+; database with the dbtool relocate command.
+; indexdir=/mnt/ssd/exccd/indexes
+
 
 ; ------------------------------------------------------------------------------
 ; Network settings
@@ -62,6 +76,14 @@ const fileContents = `[Application Options]
 ; to correlate connections.
 ; torisolation=1
 
+; Automatically create a Tor v3 hidden service for the P2P listener via the Tor
+; control port and advertise its address to peers.  The private key of the
+; hidden service is stored in the data directory so the address is the same
+; across restarts.  The password is only needed when the control port is
+; configured to use password authentication as opposed to cookie authentication.
+; torcontrol=127.0.0.1:9051
+; torcontrolpass=
+
 ; Use Universal Plug and Play (UPnP) to automatically open the listen port
 ; and obtain the external IP address from supported devices.  NOTE: This option
 ; will have no effect if external IP addresses are specified.
@@ -272,6 +294,27 @@ const fileContents = `[Application Options]
 ; Delete the entire address index on start up, then exit.
 ; dropaddrindex=0
 
+; Delete the entire spent output index on start up, then exit.
+; dropspenderindex=0
+
+; Delete the entire address balance index on start up, then exit.
+; dropaddrbalanceindex=0
+
+; Delete the entire block statistics index on start up, then exit.
+; dropblockstatsindex=0
+
+; Delete the entire ticket index on start up, then exit.
+; dropticketindex=0
+
+; Delete the entire coin statistics index on start up, then exit.
+; dropcoinstatsindex=0
+
+; Delete the entire filter header index on start up, then exit.
+; dropcfheaderindex=0
+
+; Delete the entire script index on start up, then exit.
+; dropscriptindex=0
+
 
 ; ------------------------------------------------------------------------------
 ; Optional Indexes
@@ -285,6 +328,45 @@ const fileContents = `[Application Options]
 ; searchrawtransactions RPC available.
 ; addrindex=1
 
+; Build and maintain a full spent output index which makes the getspendingtx
+; RPC available and adds the spending transaction of each output to the verbose
+; getrawtransaction results.
+; spenderindex=1
+
+; Build and maintain a full address balance and unspent output index which makes
+; the getaddressbalance, getaddressutxos, and getaddressdeltas RPCs available.
+; addrbalanceindex=1
+
+; Build and maintain a full block statistics index which makes the getblockstats
+; RPC available.
+; blockstatsindex=1
+
+; Build and maintain a full ticket lifecycle index which makes the getticketinfo
+; and getaddresstickets RPCs available.
+; ticketindex=1
+
+; Build and maintain a full coin statistics index which makes the
+; gettxoutsetinfo RPC respond instantly and for any block height.
+; coinstatsindex=1
+
+; Build and maintain a full filter header index which makes the
+; getcfilterheaders RPC available.
+; cfheaderindex=1
+
+; Build and maintain a full index of the outputs with scripts that do not decode
+; to addresses, including null data outputs, which makes the searchscripts RPC
+; available.
+; scriptindex=1
+
+
+; ------------------------------------------------------------------------------
+; Block Database
+; ------------------------------------------------------------------------------
+
+; Compress blocks written to the block database to reduce disk usage.  Blocks
+; that were previously written are not affected.  The dbtool compressblocks
+; command may be used to compress them.
+; compressblocks=1
 
 ; ------------------------------------------------------------------------------
 ; Signature Verification Cache
@@ -300,6 +382,10 @@ const fileContents = `[Application Options]
 ; Limit the utxo cache to a max of 100 MiB.
 ; utxocachemaxsize=150
 
+; Flush the utxo cache whenever the memory in use by the process reaches 4 GiB.
+; This is disabled by default.
+; utxocachememlimit=4096
+
 ; ------------------------------------------------------------------------------
 ; Coin Generation (Mining) Settings - The following options control the
 ; generation of block templates used by external mining applications through RPC
@@ -404,6 +490,26 @@ const DcrctlSampleConfig = `[Application Options]
 ; Valid time units are {s,m,h}. Minimum 15 seconds.
 ; peeridletimeout=120s
 
+; Disable the encrypted and authenticated v2 P2P transport.  Peers that both
+; support it use it automatically to prevent observers from seeing the traffic.
+; nov2transport=1
+
+; Try to keep the data sent to peers under the given target in MiB per 24
+; hours.  Once the data that remains in the current 24 hour cycle is only enough
+; to relay the new blocks expected during it, blocks older than a week are no
+; longer served to peers that are not whitelisted.  New blocks are always
+; relayed.  Since the data needed to relay new blocks is reserved, targets
+; smaller than a full day of maximum size blocks prevent serving historical
+; blocks entirely.  0 disables the target.
+; maxuploadtarget=5000
+
+; Path to a file that maps IP addresses to the autonomous system (AS) that
+; announces them.  When specified, addresses and outbound peers are grouped by
+; AS instead of by network prefix which makes it harder for a single network
+; operator to control all of the connections.  The file uses the same compact
+; format as the asmap files produced for Bitcoin Core.
+; asmap=~/.exccd/asmap.dat
+
 
 ; ------------------------------------------------------------------------------
 ; RPC client settings
@@ -12,12 +12,14 @@ import (
 	"crypto/tls"
 	"crypto/x509"
 	"encoding/binary"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"math"
 	"net"
 	"os"
 	"path"
+	"path/filepath"
 	"runtime"
 	"strconv"
 	"strings"
@@ -43,6 +45,7 @@ import (
 	"github.com/EXCCoin/exccd/internal/mining/cpuminer"
 	"github.com/EXCCoin/exccd/internal/netsync"
 	"github.com/EXCCoin/exccd/internal/rpcserver"
+	"github.com/EXCCoin/exccd/internal/staging/banmanager"
 	"github.com/EXCCoin/exccd/internal/version"
 	"github.com/EXCCoin/exccd/peer/v3"
 	"github.com/EXCCoin/exccd/txscript/v4"
@@ -73,7 +76,7 @@ const (
 	connectionRetryInterval = time.Second * 5
 
 	// maxProtocolVersion is the max protocol version the server supports.
-	maxProtocolVersion = wire.InitStateVersion
+	maxProtocolVersion = wire.AddrV2Version
 
 	// These fields are used to track known addresses on a per-peer basis.
 	//
@@ -123,6 +126,35 @@ const (
 	// These values result in about 183 KiB memory usage including overhead.
 	maxRecentlyConfirmedTxns    = 23000
 	recentlyConfirmedTxnsFPRate = 0.000001
+
+	// banListFilename is the name of the file in the data directory that
+	// houses the persisted ban list.
+	banListFilename = "banlist.json"
+
+	// torKeyFilename is the name of the file in the data directory that
+	// houses the private key of the Tor hidden service created via the Tor
+	// control port so the same address is used across restarts.
+	torKeyFilename = "onion_v3_private_key"
+
+	// torControlTimeout is the maximum amount of time to wait for the Tor
+	// control port when creating the hidden service.
+	torControlTimeout = time.Second * 30
+
+	// torControlRetryInterval is the amount of time to wait before trying
+	// to create the Tor hidden service again after a failed attempt.
+	torControlRetryInterval = time.Minute
+
+	// anchorsFilename is the name of the file in the data directory that
+	// houses the addresses of the block relay only peers that were connected
+	// at shutdown so they are connected to first on the next startup.
+	anchorsFilename = "anchors.json"
+
+	// maxAnchors is the maximum number of block relay only peers that are
+	// persisted as anchors at shutdown.
+	maxAnchors = 2
+
+	// anchorsVersion is the current version of the serialized anchors.
+	anchorsVersion = 1
 )
 
 var (
@@ -180,12 +212,19 @@ type broadcastPruneInventory struct{}
 // relayMsg packages an inventory vector along with the newly discovered
 // inventory and a flag that determines if the relay should happen immediately
 // (it will be put into a trickle queue if false) so the relay has access to
-// that information.
+// that information.  The local flag indicates the inventory originated locally
+// which results in it being trickled after a shorter delay.
 type relayMsg struct {
 	invVect     *wire.InvVect
 	data        interface{}
 	immediate   bool
+	local       bool
 	reqServices wire.ServiceFlag
+
+	// cmpctBlock is the compact block to send to peers that requested new
+	// blocks be announced via compact blocks.  It is only set for block
+	// announcements.
+	cmpctBlock *wire.MsgCmpctBlock
 }
 
 // naSubmission represents a network address submission from an outbound peer.
@@ -302,12 +341,11 @@ func (sc *naSubmissionCache) bestSubmission(net addrmgr.NetAddressType) *naSubmi
 }
 
 // peerState maintains state of inbound, persistent, outbound peers as well
-// as banned peers and outbound groups.
+// as outbound groups.
 type peerState struct {
 	inboundPeers    map[int32]*serverPeer
 	outboundPeers   map[int32]*serverPeer
 	persistentPeers map[int32]*serverPeer
-	banned          map[string]time.Time
 	outboundGroups  map[string]int
 	subCache        *naSubmissionCache
 }
@@ -473,6 +511,7 @@ type server struct {
 
 	chainParams          *chaincfg.Params
 	addrManager          *addrmgr.AddrManager
+	banList              *banmanager.BanList
 	connManager          *connmgr.ConnManager
 	sigCache             *txscript.SigCache
 	subsidyCache         *standalone.SubsidyCache
@@ -492,11 +531,29 @@ type server struct {
 	broadcast            chan broadcastMsg
 	wg                   sync.WaitGroup
 	nat                  *upnpNAT
+	torTarget            string
 	db                   database.DB
 	timeSource           blockchain.MedianTimeSource
 	services             wire.ServiceFlag
 	quit                 chan struct{}
 
+	// msgStats tracks the number of messages and bytes sent and received
+	// across the network for all peers per message type.
+	msgStats *msgStats
+
+	// uploadTarget tracks the bytes sent to peers in order to stop serving
+	// historical blocks once the configured upload target is reached.
+	uploadTarget *uploadTarget
+
+	// inboundTrickle is the inventory announcement schedule shared by all
+	// inbound peers so that making several inbound connections does not
+	// reveal any additional timing information about relayed inventory.
+	inboundTrickle *peer.TrickleSchedule
+
+	// inboundLocalTrickle is the announcement schedule for inventory that
+	// originated locally shared by all inbound peers for the same reason.
+	inboundLocalTrickle *peer.TrickleSchedule
+
 	// The following fields are used for optional indexes.  They will be nil
 	// if the associated index is not enabled.  These fields are set during
 	// initial creation of the server and never changed afterwards, so they
@@ -505,6 +562,14 @@ type server struct {
 	txIndex         *indexers.TxIndex
 	addrIndex       *indexers.AddrIndex
 	existsAddrIndex *indexers.ExistsAddrIndex
+	spenderIndex    *indexers.SpenderIndex
+	addrBalIndex    *indexers.AddrBalanceIndex
+	blockStatsIndex *indexers.BlockStatsIndex
+	ticketIndex     *indexers.TicketIndex
+	coinStatsIndex  *indexers.CoinStatsIndex
+	cfHeaderIndex   *indexers.CFHeaderIndex
+	scriptIndex     *indexers.ScriptIndex
+	indexMgr        *indexManager
 
 	// These following fields are used to filter duplicate block lottery data
 	// anouncements.
@@ -521,6 +586,7 @@ type serverPeer struct {
 	*peer.Peer
 
 	connReq        *connmgr.ConnReq
+	connType       connmgr.ConnType
 	server         *server
 	persistent     bool
 	continueHash   *chainhash.Hash
@@ -549,9 +615,19 @@ type serverPeer struct {
 	peerNa    *wire.NetAddress
 	peerNaMtx sync.Mutex
 
+	// outboundNetAddr is the address manager network address that was used
+	// to create outbound peers.  It is tracked separately from the network
+	// address of the underlying peer since addresses on networks such as Tor
+	// v3 and I2P can't be represented by a wire.NetAddress.
+	outboundNetAddr *addrmgr.NetAddress
+
 	// announcedBlock tracks the most recent block announced to this peer and is
 	// used to filter duplicates.
 	announcedBlock *chainhash.Hash
+
+	// msgStats tracks the number of messages and bytes sent to and received
+	// from the peer per message type.
+	msgStats *msgStats
 }
 
 // newServerPeer returns a new serverPeer instance. The peer needs to be set by
@@ -564,6 +640,7 @@ func newServerPeer(s *server, isPersistent bool) *serverPeer {
 		quit:           make(chan struct{}),
 		txProcessed:    make(chan struct{}, 1),
 		blockProcessed: make(chan struct{}, 1),
+		msgStats:       newMsgStats(),
 	}
 }
 
@@ -593,6 +670,22 @@ func (sp *serverPeer) addressKnown(na *addrmgr.NetAddress) bool {
 	return sp.knownAddresses.Contains([]byte(na.Key()))
 }
 
+// blockRelayOnly returns whether or not the peer is an outbound block relay only
+// peer which means transactions and addresses are neither relayed to nor
+// accepted from it.
+func (sp *serverPeer) blockRelayOnly() bool {
+	return sp.connType == connmgr.ConnTypeBlockRelay
+}
+
+// connTypeString returns a human-readable description of the type of the
+// connection to the peer.
+func (sp *serverPeer) connTypeString() string {
+	if sp.Inbound() {
+		return "inbound"
+	}
+	return sp.connType.String()
+}
+
 // setDisableRelayTx toggles relaying of transactions for the given peer.
 // It is safe for concurrent access.
 func (sp *serverPeer) setDisableRelayTx(disable bool) {
@@ -630,19 +723,116 @@ func wireToAddrmgrNetAddresses(netAddr []*wire.NetAddress) []*addrmgr.NetAddress
 	return addrs
 }
 
+// wireV2ToAddrmgrNetAddress converts a wire NetAddressV2 to an address manager
+// NetAddress.  An error is returned when the address belongs to a network that
+// is not supported by the address manager or is invalid for its network.
+func wireV2ToAddrmgrNetAddress(netAddr *wire.NetAddressV2) (*addrmgr.NetAddress, error) {
+	var netAddrType addrmgr.NetAddressType
+	switch netAddr.Type {
+	case wire.IPv4Address, wire.IPv6Address:
+		newNetAddr := addrmgr.NewNetAddressIPPort(net.IP(netAddr.Addr),
+			netAddr.Port, netAddr.Services)
+		newNetAddr.Timestamp = netAddr.Timestamp
+		return newNetAddr, nil
+	case wire.TORv3Address:
+		netAddrType = addrmgr.TORv3Address
+	case wire.I2PAddress:
+		netAddrType = addrmgr.I2PAddress
+	case wire.CJDNSAddress:
+		netAddrType = addrmgr.CJDNSAddress
+	default:
+		return nil, fmt.Errorf("unsupported network address type %v",
+			netAddr.Type)
+	}
+	return addrmgr.NewNetAddressFromParams(netAddrType, netAddr.Addr,
+		netAddr.Port, netAddr.Timestamp, netAddr.Services)
+}
+
 // addrmgrToWireNetAddress converts an address manager net address to a wire net
+// address.  Addresses on networks that can't be represented by a wire net
+// address, such as Tor v3 and I2P, are converted to the unspecified IPv6
 // address.
 func addrmgrToWireNetAddress(netAddr *addrmgr.NetAddress) *wire.NetAddress {
+	ip := net.IP(netAddr.IP)
+	if !isLegacyAddrType(netAddr.Type) {
+		ip = net.IPv6unspecified
+	}
 	return wire.NewNetAddressTimestamp(netAddr.Timestamp, netAddr.Services,
-		netAddr.IP, netAddr.Port)
+		ip, netAddr.Port)
 }
 
-// pushAddrMsg sends an addr message to the connected peer using the provided
-// addresses.
+// addrmgrToWireNetAddressV2 converts an address manager net address to a wire
+// addrv2 net address.  It returns nil for addresses that can't be relayed via
+// the addrv2 message such as Tor v2 addresses.
+func addrmgrToWireNetAddressV2(netAddr *addrmgr.NetAddress) *wire.NetAddressV2 {
+	var netAddrType wire.NetAddressType
+	addrBytes := netAddr.IP
+	switch netAddr.Type {
+	case addrmgr.IPv4Address:
+		netAddrType = wire.IPv4Address
+		addrBytes = net.IP(netAddr.IP).To4()
+	case addrmgr.IPv6Address:
+		netAddrType = wire.IPv6Address
+	case addrmgr.TORv3Address:
+		netAddrType = wire.TORv3Address
+	case addrmgr.I2PAddress:
+		netAddrType = wire.I2PAddress
+	case addrmgr.CJDNSAddress:
+		netAddrType = wire.CJDNSAddress
+	default:
+		return nil
+	}
+	return wire.NewNetAddressV2(netAddr.Timestamp, netAddr.Services,
+		netAddrType, addrBytes, netAddr.Port)
+}
+
+// isLegacyAddrType returns whether or not addresses of the provided address
+// manager network address type can be relayed via the addr message as opposed
+// to requiring the addrv2 message.
+func isLegacyAddrType(netAddrType addrmgr.NetAddressType) bool {
+	switch netAddrType {
+	case addrmgr.IPv4Address, addrmgr.IPv6Address, addrmgr.TORv2Address:
+		return true
+	}
+	return false
+}
+
+// remoteNetAddr returns the address manager network address of the remote
+// peer.  For outbound peers, this is the address the connection was made to
+// which, unlike the network address of the underlying peer, is able to
+// represent addresses on networks such as Tor v3 and I2P.
+func (sp *serverPeer) remoteNetAddr() *addrmgr.NetAddress {
+	netAddr := wireToAddrmgrNetAddress(sp.NA())
+	if sp.outboundNetAddr == nil {
+		return netAddr
+	}
+	outboundNetAddr := sp.outboundNetAddr.Clone()
+	outboundNetAddr.Services = netAddr.Services
+	outboundNetAddr.Timestamp = netAddr.Timestamp
+	return outboundNetAddr
+}
+
+// pushAddrMsg sends an addr message, or an addrv2 message when the peer
+// supports it, to the connected peer using the provided addresses.  Addresses
+// on networks that can't be relayed via the addr message are skipped for peers
+// that do not support the addrv2 message.
 func (sp *serverPeer) pushAddrMsg(addresses []*addrmgr.NetAddress) {
+	// Addresses are not relayed to block relay only peers.
+	if sp.blockRelayOnly() {
+		return
+	}
+
+	if sp.ProtocolVersion() >= wire.AddrV2Version {
+		sp.pushAddrV2Msg(addresses)
+		return
+	}
+
 	// Filter addresses already known to the peer.
 	addrs := make([]*wire.NetAddress, 0, len(addresses))
 	for _, addr := range addresses {
+		if !isLegacyAddrType(addr.Type) {
+			continue
+		}
 		if !sp.addressKnown(addr) {
 			wireNetAddr := addrmgrToWireNetAddress(addr)
 			addrs = append(addrs, wireNetAddr)
@@ -659,6 +849,38 @@ func (sp *serverPeer) pushAddrMsg(addresses []*addrmgr.NetAddress) {
 	sp.addKnownAddresses(knownNetAddrs)
 }
 
+// pushAddrV2Msg sends an addrv2 message to the connected peer using the
+// provided addresses.
+func (sp *serverPeer) pushAddrV2Msg(addresses []*addrmgr.NetAddress) {
+	// Filter addresses already known to the peer.
+	addrs := make([]*wire.NetAddressV2, 0, len(addresses))
+	addrsByWireAddr := make(map[*wire.NetAddressV2]*addrmgr.NetAddress,
+		len(addresses))
+	for _, addr := range addresses {
+		if sp.addressKnown(addr) {
+			continue
+		}
+		wireNetAddr := addrmgrToWireNetAddressV2(addr)
+		if wireNetAddr == nil {
+			continue
+		}
+		addrs = append(addrs, wireNetAddr)
+		addrsByWireAddr[wireNetAddr] = addr
+	}
+	known, err := sp.PushAddrV2Msg(addrs)
+	if err != nil {
+		peerLog.Errorf("Can't push address message to %s: %v", sp.Peer, err)
+		sp.Disconnect()
+		return
+	}
+
+	knownNetAddrs := make([]*addrmgr.NetAddress, 0, len(known))
+	for _, wireNetAddr := range known {
+		knownNetAddrs = append(knownNetAddrs, addrsByWireAddr[wireNetAddr])
+	}
+	sp.addKnownAddresses(knownNetAddrs)
+}
+
 // addBanScore increases the persistent and decaying ban score fields by the
 // values passed as parameters. If the resulting score exceeds half of the ban
 // threshold, a warning is logged including the reason provided. Further, if
@@ -721,7 +943,7 @@ func (sp *serverPeer) OnVersion(_ *peer.Peer, msg *wire.MsgVersion) {
 	// it is updated regardless in the case a new minimum protocol version is
 	// enforced and the remote node has not upgraded yet.
 	isInbound := sp.Inbound()
-	remoteAddr := wireToAddrmgrNetAddress(sp.NA())
+	remoteAddr := sp.remoteNetAddr()
 	addrManager := sp.server.addrManager
 	if !cfg.SimNet && !cfg.RegNet && !isInbound {
 		err := addrManager.SetServices(remoteAddr, msg.Services)
@@ -770,8 +992,9 @@ func (sp *serverPeer) OnVersion(_ *peer.Peer, msg *wire.MsgVersion) {
 		}
 
 		// Request known addresses if the server address manager needs
-		// more.
-		if addrManager.NeedMoreAddresses() {
+		// more.  Addresses are not relayed over block relay only
+		// connections.
+		if addrManager.NeedMoreAddresses() && !sp.blockRelayOnly() {
 			sp.QueueMessage(wire.NewMsgGetAddr(), nil)
 		}
 
@@ -786,8 +1009,9 @@ func (sp *serverPeer) OnVersion(_ *peer.Peer, msg *wire.MsgVersion) {
 	sp.peerNa = &msg.AddrYou
 	sp.peerNaMtx.Unlock()
 
-	// Choose whether or not to relay transactions.
-	sp.setDisableRelayTx(msg.DisableRelayTx)
+	// Choose whether or not to relay transactions.  They are never relayed
+	// to block relay only peers.
+	sp.setDisableRelayTx(msg.DisableRelayTx || sp.blockRelayOnly())
 
 	// Add the remote peer time as a sample for creating an offset against
 	// the local clock to keep the network time in sync.
@@ -808,6 +1032,12 @@ func (sp *serverPeer) OnVerAck(_ *peer.Peer, msg *wire.MsgVerAck) {
 // and sends an inventory message with the contents of the memory pool up to the
 // maximum inventory allowed per message.
 func (sp *serverPeer) OnMemPool(p *peer.Peer, msg *wire.MsgMemPool) {
+	// Transactions are not relayed to block relay only peers.
+	if sp.blockRelayOnly() {
+		peerLog.Tracef("Ignoring mempool from block relay only peer %v", p)
+		return
+	}
+
 	// A decaying ban score increase is applied to prevent flooding.
 	// The ban score accumulates and passes the ban threshold if a burst of
 	// mempool messages comes from a peer. The score decays each minute to
@@ -1058,6 +1288,15 @@ func (sp *serverPeer) OnTx(p *peer.Peer, msg *wire.MsgTx) {
 		return
 	}
 
+	// Block relay only peers are not allowed to send transactions since
+	// transaction relay was disabled when negotiating the connection.
+	if sp.blockRelayOnly() {
+		peerLog.Infof("Block relay only peer %v sent tx %v -- disconnecting",
+			p, msg.TxHash())
+		p.Disconnect()
+		return
+	}
+
 	// Add the transaction to the known inventory for the peer.
 	// Convert the raw MsgTx to a dcrutil.Tx which provides some convenience
 	// methods and things such as hash caching.
@@ -1097,6 +1336,58 @@ func (sp *serverPeer) OnBlock(p *peer.Peer, msg *wire.MsgBlock, buf []byte) {
 	<-sp.blockProcessed
 }
 
+// OnCmpctBlock is invoked when a peer receives a cmpctblock wire message.  It
+// blocks until the block has either been fully reconstructed and processed or
+// the missing transactions have been requested.
+func (sp *serverPeer) OnCmpctBlock(_ *peer.Peer, msg *wire.MsgCmpctBlock) {
+	sp.server.syncManager.QueueCmpctBlock(msg, sp.Peer, sp.blockProcessed)
+	<-sp.blockProcessed
+}
+
+// OnBlockTxn is invoked when a peer receives a blocktxn wire message.  It
+// blocks until the block the transactions complete has been fully processed.
+func (sp *serverPeer) OnBlockTxn(_ *peer.Peer, msg *wire.MsgBlockTxn) {
+	sp.server.syncManager.QueueBlockTxn(msg, sp.Peer, sp.blockProcessed)
+	<-sp.blockProcessed
+}
+
+// OnGetBlockTxn is invoked when a peer receives a getblocktxn wire message and
+// is used to deliver the requested transactions of a block that was previously
+// sent to the peer as a compact block.
+func (sp *serverPeer) OnGetBlockTxn(_ *peer.Peer, msg *wire.MsgGetBlockTxn) {
+	block, err := sp.server.chain.BlockByHash(&msg.BlockHash)
+	if err != nil {
+		peerLog.Debugf("Unable to fetch block %v requested via getblocktxn "+
+			"from %s: %v", msg.BlockHash, sp, err)
+		return
+	}
+
+	// Ban peers requesting transactions that are not in the block.
+	selectTxns := func(txns []*wire.MsgTx, indexes []uint32) []*wire.MsgTx {
+		selected := make([]*wire.MsgTx, 0, len(indexes))
+		for _, idx := range indexes {
+			if idx >= uint32(len(txns)) {
+				return nil
+			}
+			selected = append(selected, txns[idx])
+		}
+		return selected
+	}
+	msgBlock := block.MsgBlock()
+	blockTxn := wire.NewMsgBlockTxn(&msg.BlockHash)
+	blockTxn.Transactions = selectTxns(msgBlock.Transactions, msg.Indexes)
+	blockTxn.STransactions = selectTxns(msgBlock.STransactions,
+		msg.STxIndexes)
+	if blockTxn.Transactions == nil || blockTxn.STransactions == nil {
+		peerLog.Debugf("Peer %s requested out of range transactions for "+
+			"block %v via getblocktxn", sp, msg.BlockHash)
+		sp.server.BanPeer(sp)
+		return
+	}
+
+	sp.QueueMessage(blockTxn, nil)
+}
+
 // OnInv is invoked when a peer receives an inv wire message and is used to
 // examine the inventory being advertised by the remote peer and react
 // accordingly.  We pass the message down to the net sync manager which will
@@ -1108,7 +1399,7 @@ func (sp *serverPeer) OnInv(p *peer.Peer, msg *wire.MsgInv) {
 		return
 	}
 
-	if !cfg.BlocksOnly {
+	if !cfg.BlocksOnly && !sp.blockRelayOnly() {
 		sp.server.syncManager.QueueInv(msg, sp.Peer)
 		return
 	}
@@ -1183,6 +1474,8 @@ func (sp *serverPeer) OnGetData(p *peer.Peer, msg *wire.MsgGetData) {
 			err = sp.server.pushTxMsg(sp, &iv.Hash, c, waitChan)
 		case wire.InvTypeBlock:
 			err = sp.server.pushBlockMsg(sp, &iv.Hash, c, waitChan)
+		case wire.InvTypeCmpctBlock:
+			err = sp.server.pushCmpctBlockMsg(sp, &iv.Hash, c, waitChan)
 		default:
 			peerLog.Warnf("Unknown type '%d' in inventory request from %s",
 				iv.Type, sp)
@@ -1405,6 +1698,14 @@ func (sp *serverPeer) OnAddr(p *peer.Peer, msg *wire.MsgAddr) {
 		return
 	}
 
+	// Ignore addresses from block relay only peers since addresses are not
+	// relayed over those connections.
+	if sp.blockRelayOnly() {
+		peerLog.Tracef("Ignoring %%s from block relay only peer %%v",
+			msg.Command(), p)
+		return
+	}
+
 	// A message that has no addresses is invalid.
 	if len(msg.AddrList) == 0 {
 		peerLog.Errorf("Command [%s] from %s does not contain any addresses",
@@ -1437,12 +1738,75 @@ func (sp *serverPeer) OnAddr(p *peer.Peer, msg *wire.MsgAddr) {
 	// Add addresses to server address manager.  The address manager handles
 	// the details of things such as preventing duplicate addresses, max
 	// addresses, and last seen updates.
-	remoteAddr := wireToAddrmgrNetAddress(p.NA())
+	remoteAddr := sp.remoteNetAddr()
+	sp.server.addrManager.AddAddresses(addrList, remoteAddr)
+}
+
+// OnAddrV2 is invoked when a peer receives an addrv2 wire message and is used
+// to notify the server about advertised addresses.  Addresses on networks that
+// are not supported by the address manager are ignored.
+func (sp *serverPeer) OnAddrV2(p *peer.Peer, msg *wire.MsgAddrV2) {
+	// Ignore addresses when running on the simulation and regression test
+	// networks.  This helps prevent the networks from becoming another public
+	// test network since they will not be able to learn about other peers that
+	// have not specifically been provided.
+	if cfg.SimNet || cfg.RegNet {
+		return
+	}
+
+	// Ignore addresses from block relay only peers since addresses are not
+	// relayed over those connections.
+	if sp.blockRelayOnly() {
+		peerLog.Tracef("Ignoring %%s from block relay only peer %%v",
+			msg.Command(), p)
+		return
+	}
+
+	// A message that has no addresses is invalid.
+	if len(msg.AddrList) == 0 {
+		peerLog.Errorf("Command [%s] from %s does not contain any addresses",
+			msg.Command(), p)
+
+		// Ban peers sending empty address requests.
+		sp.server.BanPeer(sp)
+		return
+	}
+
+	now := time.Now()
+	addrList := make([]*addrmgr.NetAddress, 0, len(msg.AddrList))
+	for _, wireNetAddr := range msg.AddrList {
+		// Don't add more address if we're disconnecting.
+		if !p.Connected() {
+			return
+		}
+
+		na, err := wireV2ToAddrmgrNetAddress(wireNetAddr)
+		if err != nil {
+			peerLog.Tracef("Ignoring address from %v: %v", p, err)
+			continue
+		}
+
+		// Set the timestamp to 5 days ago if it's more than 24 hours
+		// in the future so this address is one of the first to be
+		// removed when space is needed.
+		if na.Timestamp.After(now.Add(time.Minute * 10)) {
+			na.Timestamp = now.Add(-1 * time.Hour * 24 * 5)
+		}
+
+		// Add address to known addresses for this peer.
+		sp.addKnownAddress(na)
+		addrList = append(addrList, na)
+	}
+
+	// Add addresses to server address manager.  The address manager handles
+	// the details of things such as preventing duplicate addresses, max
+	// addresses, and last seen updates.
+	remoteAddr := sp.remoteNetAddr()
 	sp.server.addrManager.AddAddresses(addrList, remoteAddr)
 }
 
 // OnRead is invoked when a peer receives a message and it is used to update
-// the bytes received by the server.
+// the bytes and messages received by the peer and the server.
 func (sp *serverPeer) OnRead(p *peer.Peer, bytesRead int, msg wire.Message, err error) {
 	// Ban peers sending messages that do not conform to the wire protocol.
 	var errCode wire.ErrorCode
@@ -1452,12 +1816,16 @@ func (sp *serverPeer) OnRead(p *peer.Peer, bytesRead int, msg wire.Message, err
 	}
 
 	sp.server.AddBytesReceived(uint64(bytesRead))
+	sp.msgStats.add(false, msg, bytesRead)
+	sp.server.msgStats.add(false, msg, bytesRead)
 }
 
 // OnWrite is invoked when a peer sends a message and it is used to update
-// the bytes sent by the server.
+// the bytes and messages sent by the peer and the server.
 func (sp *serverPeer) OnWrite(p *peer.Peer, bytesWritten int, msg wire.Message, err error) {
 	sp.server.AddBytesSent(uint64(bytesWritten))
+	sp.msgStats.add(true, msg, bytesWritten)
+	sp.server.msgStats.add(true, msg, bytesWritten)
 }
 
 // OnNotFound is invoked when a peer sends a notfound message.
@@ -1469,7 +1837,7 @@ func (sp *serverPeer) OnNotFound(p *peer.Peer, msg *wire.MsgNotFound) {
 	var numBlocks, numTxns uint32
 	for _, inv := range msg.InvList {
 		switch inv.Type {
-		case wire.InvTypeBlock:
+		case wire.InvTypeBlock, wire.InvTypeCmpctBlock:
 			numBlocks++
 		case wire.InvTypeTx:
 			numTxns++
@@ -1543,10 +1911,16 @@ func (s *server) PruneRebroadcastInventory() {
 }
 
 // relayTransactions generates and relays inventory vectors for all of the
-// passed transactions to all connected peers.
-func (s *server) relayTransactions(txns []*dcrutil.Tx) {
+// passed transactions to all connected peers.  Transactions that originated
+// locally, such as those submitted via RPC, are announced after a shorter
+// delay when the local flag is set.
+func (s *server) relayTransactions(txns []*dcrutil.Tx, local bool) {
 	for _, tx := range txns {
 		iv := wire.NewInvVect(wire.InvTypeTx, tx.Hash())
+		if local {
+			s.relayLocalInventory(iv, tx)
+			continue
+		}
 		s.RelayInventory(iv, tx, false)
 	}
 }
@@ -1557,7 +1931,7 @@ func (s *server) relayTransactions(txns []*dcrutil.Tx) {
 func (s *server) AnnounceNewTransactions(txns []*dcrutil.Tx) {
 	// Generate and relay inventory vectors for all newly accepted
 	// transactions.
-	s.relayTransactions(txns)
+	s.relayTransactions(txns, false)
 
 	// Notify websocket clients of all newly accepted transactions.
 	if s.rpcServer != nil {
@@ -1623,6 +1997,20 @@ func (s *server) pushBlockMsg(sp *serverPeer, hash *chainhash.Hash, doneChan cha
 		return err
 	}
 
+	// Disconnect peers that are not whitelisted when they request historical
+	// blocks once the upload target no longer leaves enough room to serve
+	// them while still relaying new blocks.
+	blockTime := block.MsgBlock().Header.Timestamp
+	if !sp.isWhitelisted && !s.uploadTarget.serveBlock(blockTime, time.Now()) {
+		peerLog.Debugf("Historical block serving limit of the upload target "+
+			"reached -- disconnecting peer %s", sp)
+		sp.Disconnect()
+		if doneChan != nil {
+			doneChan <- struct{}{}
+		}
+		return errors.New("historical block serving limit reached")
+	}
+
 	// Once we have fetched data wait for any previous operation to finish.
 	if waitChan != nil {
 		<-waitChan
@@ -1654,6 +2042,38 @@ func (s *server) pushBlockMsg(sp *serverPeer, hash *chainhash.Hash, doneChan cha
 	return nil
 }
 
+// pushCmpctBlockMsg sends a cmpctblock message for the provided block hash to
+// the connected peer.  An error is returned if the block hash is not known.
+func (s *server) pushCmpctBlockMsg(sp *serverPeer, hash *chainhash.Hash, doneChan chan<- struct{}, waitChan <-chan struct{}) error {
+	block, err := sp.server.chain.BlockByHash(hash)
+	if err != nil {
+		peerLog.Tracef("Unable to fetch requested block hash %v: %v",
+			hash, err)
+
+		if doneChan != nil {
+			doneChan <- struct{}{}
+		}
+		return err
+	}
+
+	nonce, err := wire.RandomUint64()
+	if err != nil {
+		if doneChan != nil {
+			doneChan <- struct{}{}
+		}
+		return err
+	}
+	cmpctBlock := netsync.NewCmpctBlock(block.MsgBlock(), nonce)
+
+	// Once we have fetched data wait for any previous operation to finish.
+	if waitChan != nil {
+		<-waitChan
+	}
+
+	sp.QueueMessage(cmpctBlock, doneChan)
+	return nil
+}
+
 // handleAddPeerMsg deals with adding new peers.  It is invoked from the
 // peerHandler goroutine.
 func (s *server) handleAddPeerMsg(state *peerState, sp *serverPeer) bool {
@@ -1675,16 +2095,11 @@ func (s *server) handleAddPeerMsg(state *peerState, sp *serverPeer) bool {
 		sp.Disconnect()
 		return false
 	}
-	if banEnd, ok := state.banned[host]; ok {
-		if time.Now().Before(banEnd) {
-			srvrLog.Debugf("Peer %s is banned for another %v - disconnecting",
-				host, time.Until(banEnd))
-			sp.Disconnect()
-			return false
-		}
-
-		srvrLog.Infof("Peer %s is no longer banned", host)
-		delete(state.banned, host)
+	if banEnd, ok := s.banList.IsBanned(net.ParseIP(host)); ok {
+		srvrLog.Debugf("Peer %s is banned for another %v - disconnecting",
+			host, time.Until(banEnd))
+		sp.Disconnect()
+		return false
 	}
 
 	// Limit max number of connections from a single IP.  However, allow
@@ -1732,8 +2147,24 @@ func (s *server) handleAddPeerMsg(state *peerState, sp *serverPeer) bool {
 			}
 		}
 	} else {
-		remoteAddr := wireToAddrmgrNetAddress(sp.NA())
-		state.outboundGroups[remoteAddr.GroupKey()]++
+		// Limit automatic outbound peers to a single peer per network
+		// group in order to increase the diversity of the network
+		// segments the server is connected to.  Addresses in groups that
+		// already have a peer are skipped when selecting new peers,
+		// however, concurrent connection attempts may still result in
+		// multiple peers in the same group.
+		remoteAddr := sp.remoteNetAddr()
+		groupKey := s.addrManager.GroupKey(remoteAddr)
+		if sp.connType != connmgr.ConnTypeManual &&
+			state.outboundGroups[groupKey] > 0 {
+
+			srvrLog.Debugf("Already connected to a peer in network group "+
+				"%s - disconnecting peer %s", groupKey, sp)
+			sp.Disconnect()
+			return false
+		}
+
+		state.outboundGroups[groupKey]++
 		if sp.persistent {
 			state.persistentPeers[sp.ID()] = sp
 		} else {
@@ -1819,8 +2250,8 @@ func (s *server) handleDonePeerMsg(state *peerState, sp *serverPeer) {
 	}
 	if _, ok := list[sp.ID()]; ok {
 		if !sp.Inbound() && sp.VersionKnown() {
-			remoteAddr := wireToAddrmgrNetAddress(sp.NA())
-			state.outboundGroups[remoteAddr.GroupKey()]--
+			remoteAddr := sp.remoteNetAddr()
+			state.outboundGroups[s.addrManager.GroupKey(remoteAddr)]--
 		}
 		if !sp.Inbound() && sp.connReq != nil {
 			s.connManager.Disconnect(sp.connReq.ID())
@@ -1837,7 +2268,7 @@ func (s *server) handleDonePeerMsg(state *peerState, sp *serverPeer) {
 	// Update the address' last seen time if the peer has acknowledged
 	// our version and has sent us its version as well.
 	if sp.VerAckReceived() && sp.VersionKnown() && sp.NA() != nil {
-		remoteAddr := wireToAddrmgrNetAddress(sp.NA())
+		remoteAddr := sp.remoteNetAddr()
 		err := s.addrManager.Connected(remoteAddr)
 		if err != nil {
 			srvrLog.Debugf("Marking address as connected failed: %v", err)
@@ -1856,10 +2287,18 @@ func (s *server) handleBanPeerMsg(state *peerState, sp *serverPeer) {
 		srvrLog.Debugf("can't split ban peer %s %v", sp.Addr(), err)
 		return
 	}
+	subnet, err := banmanager.ParseSubnet(host)
+	if err != nil {
+		srvrLog.Debugf("can't parse ban peer %s %v", sp.Addr(), err)
+		return
+	}
 	direction := directionString(sp.Inbound())
 	srvrLog.Infof("Banned peer %s (%s) for %v", host, direction,
 		cfg.BanDuration)
-	state.banned[host] = time.Now().Add(cfg.BanDuration)
+	err = s.banList.Ban(subnet, time.Now().Add(cfg.BanDuration))
+	if err != nil {
+		srvrLog.Errorf("Unable to persist ban for peer %s: %v", host, err)
+	}
 }
 
 // handleRelayInvMsg deals with relaying inventory to peers that are not already
@@ -1886,6 +2325,15 @@ func (s *server) handleRelayInvMsg(state *peerState, msg relayMsg) {
 			sp.announcedBlock = &iv.Hash
 		}
 
+		// Send the compact block instead of an inventory message for block
+		// announcements when the peer prefers compact blocks.
+		if isBlockAnnouncement && msg.cmpctBlock != nil &&
+			sp.WantsCmpctBlocks() {
+
+			sp.QueueMessage(msg.cmpctBlock, nil)
+			return
+		}
+
 		// Generate and send a headers message instead of an inventory message
 		// for block announcements when the peer prefers headers.
 		if isBlockAnnouncement && sp.WantsHeaders() {
@@ -1914,13 +2362,16 @@ func (s *server) handleRelayInvMsg(state *peerState, msg relayMsg) {
 		}
 
 		// Either queue the inventory to be relayed immediately or with
-		// the next batch depending on the immediate flag.
+		// the next batch depending on the immediate and local flags.
 		//
 		// It will be ignored in either case if the peer is already
 		// known to have the inventory.
-		if msg.immediate {
+		switch {
+		case msg.immediate:
 			sp.QueueInventoryImmediate(iv)
-		} else {
+		case msg.local:
+			sp.QueueLocalInventory(iv)
+		default:
 			sp.QueueInventory(iv)
 		}
 	})
@@ -2051,8 +2502,8 @@ func (s *server) handleQuery(state *peerState, querymsg interface{}) {
 		found := disconnectPeer(state.persistentPeers, msg.cmp, func(sp *serverPeer) {
 			// Keep group counts ok since we remove from
 			// the list now.
-			remoteAddr := wireToAddrmgrNetAddress(sp.NA())
-			state.outboundGroups[remoteAddr.GroupKey()]--
+			remoteAddr := sp.remoteNetAddr()
+			state.outboundGroups[s.addrManager.GroupKey(remoteAddr)]--
 
 			peerLog.Debugf("Removing persistent peer %s (reqid %d)", remoteAddr,
 				sp.connReq.ID())
@@ -2107,8 +2558,8 @@ func (s *server) handleQuery(state *peerState, querymsg interface{}) {
 		found = disconnectPeer(state.outboundPeers, msg.cmp, func(sp *serverPeer) {
 			// Keep group counts ok since we remove from
 			// the list now.
-			remoteAddr := wireToAddrmgrNetAddress(sp.NA())
-			state.outboundGroups[remoteAddr.GroupKey()]--
+			remoteAddr := sp.remoteNetAddr()
+			state.outboundGroups[s.addrManager.GroupKey(remoteAddr)]--
 		})
 		if found {
 			// If there are multiple outbound connections to the same
@@ -2116,8 +2567,8 @@ func (s *server) handleQuery(state *peerState, querymsg interface{}) {
 			// peers are found.
 			for found {
 				found = disconnectPeer(state.outboundPeers, msg.cmp, func(sp *serverPeer) {
-					remoteAddr := wireToAddrmgrNetAddress(sp.NA())
-					state.outboundGroups[remoteAddr.GroupKey()]--
+					remoteAddr := sp.remoteNetAddr()
+					state.outboundGroups[s.addrManager.GroupKey(remoteAddr)]--
 				})
 			}
 			msg.reply <- nil
@@ -2170,6 +2621,9 @@ func newPeerConfig(sp *serverPeer) *peer.Config {
 			OnInitState:      sp.OnInitState,
 			OnTx:             sp.OnTx,
 			OnBlock:          sp.OnBlock,
+			OnCmpctBlock:     sp.OnCmpctBlock,
+			OnGetBlockTxn:    sp.OnGetBlockTxn,
+			OnBlockTxn:       sp.OnBlockTxn,
 			OnInv:            sp.OnInv,
 			OnHeaders:        sp.OnHeaders,
 			OnGetData:        sp.OnGetData,
@@ -2181,6 +2635,7 @@ func newPeerConfig(sp *serverPeer) *peer.Config {
 			OnGetCFTypes:     sp.OnGetCFTypes,
 			OnGetAddr:        sp.OnGetAddr,
 			OnAddr:           sp.OnAddr,
+			OnAddrV2:         sp.OnAddrV2,
 			OnRead:           sp.OnRead,
 			OnWrite:          sp.OnWrite,
 			OnNotFound:       sp.OnNotFound,
@@ -2191,6 +2646,15 @@ func newPeerConfig(sp *serverPeer) *peer.Config {
 			if err != nil {
 				return nil, err
 			}
+
+			// Include the services last advertised by the address when it
+			// is known so the peer is able to determine whether or not the
+			// remote peer supports the v2 transport.
+			knownServices, err := sp.server.addrManager.Services(address)
+			if err == nil {
+				address.Services |= knownServices
+			}
+			sp.outboundNetAddr = address
 			return addrmgrToWireNetAddress(address), nil
 		},
 		Proxy:             cfg.Proxy,
@@ -2199,7 +2663,7 @@ func newPeerConfig(sp *serverPeer) *peer.Config {
 		UserAgentComments: userAgentComments,
 		Net:               sp.server.chainParams.Net,
 		Services:          sp.server.services,
-		DisableRelayTx:    cfg.BlocksOnly,
+		DisableRelayTx:    cfg.BlocksOnly || sp.blockRelayOnly(),
 		ProtocolVersion:   maxProtocolVersion,
 		IdleTimeout:       cfg.PeerIdleTimeout,
 	}
@@ -2212,7 +2676,10 @@ func newPeerConfig(sp *serverPeer) *peer.Config {
 func (s *server) inboundPeerConnected(conn net.Conn) {
 	sp := newServerPeer(s, false)
 	sp.isWhitelisted = isWhitelisted(conn.RemoteAddr())
-	sp.Peer = peer.NewInboundPeer(newPeerConfig(sp))
+	peerCfg := newPeerConfig(sp)
+	peerCfg.TrickleSchedule = s.inboundTrickle
+	peerCfg.LocalTrickleSchedule = s.inboundLocalTrickle
+	sp.Peer = peer.NewInboundPeer(peerCfg)
 	sp.AssociateConnection(conn)
 	go s.peerDoneHandler(sp)
 }
@@ -2224,6 +2691,7 @@ func (s *server) inboundPeerConnected(conn net.Conn) {
 // manager of the attempt.
 func (s *server) outboundPeerConnected(c *connmgr.ConnReq, conn net.Conn) {
 	sp := newServerPeer(s, c.Permanent)
+	sp.connType = c.Type
 	p, err := peer.NewOutboundPeer(newPeerConfig(sp), c.Addr.String())
 	if err != nil {
 		srvrLog.Debugf("Cannot create outbound peer %s: %v", c.Addr, err)
@@ -2236,7 +2704,7 @@ func (s *server) outboundPeerConnected(c *connmgr.ConnReq, conn net.Conn) {
 	sp.AssociateConnection(conn)
 	go s.peerDoneHandler(sp)
 
-	remoteAddr := wireToAddrmgrNetAddress(sp.NA())
+	remoteAddr := sp.remoteNetAddr()
 	err = s.addrManager.Attempt(remoteAddr)
 	if err != nil {
 		srvrLog.Debugf("Marking address as attempted failed: %v", err)
@@ -2247,6 +2715,24 @@ func (s *server) outboundPeerConnected(c *connmgr.ConnReq, conn net.Conn) {
 // done along with other performing other desirable cleanup.
 func (s *server) peerDoneHandler(sp *serverPeer) {
 	sp.WaitForDisconnect()
+
+	// Stop advertising the v2 transport for outbound peers that failed to
+	// negotiate it so that future connections to the address, such as those
+	// made when the connection manager retries persistent peers, fall back to
+	// the v1 transport.  This is done prior to notifying the server that the
+	// peer is done since that is what triggers the retries.
+	if !sp.Inbound() && sp.V2TransportFailed() {
+		remoteAddr := sp.remoteNetAddr()
+		err := s.addrManager.MarkV2TransportFailed(remoteAddr)
+		if err != nil {
+			srvrLog.Debugf("Marking v2 transport as failed for %s failed: %v",
+				sp, err)
+		} else {
+			srvrLog.Debugf("Failed to negotiate v2 transport with %s -- "+
+				"using v1 transport for future connections", sp)
+		}
+	}
+
 	s.donePeers <- sp
 
 	// Notify the net sync manager the peer is gone if it was ever notified that
@@ -2299,7 +2785,6 @@ func (s *server) peerHandler(ctx context.Context) {
 		inboundPeers:    make(map[int32]*serverPeer),
 		persistentPeers: make(map[int32]*serverPeer),
 		outboundPeers:   make(map[int32]*serverPeer),
-		banned:          make(map[string]time.Time),
 		outboundGroups:  make(map[string]int),
 		subCache: &naSubmissionCache{
 			cache: make(map[string]*naSubmission, maxCachedNaSubmissions),
@@ -2344,6 +2829,12 @@ out:
 			s.handleQuery(state, qmsg)
 
 		case <-ctx.Done():
+			// Persist the block relay only peers as anchors before they
+			// are disconnected.
+			if err := s.saveAnchors(state); err != nil {
+				srvrLog.Warnf("Unable to save anchor peers: %v", err)
+			}
+
 			// Disconnect all peers on server shutdown.
 			state.forAllPeers(func(sp *serverPeer) {
 				srvrLog.Tracef("Shutdown peer %s", sp)
@@ -2395,16 +2886,34 @@ func (s *server) RelayInventory(invVect *wire.InvVect, data interface{}, immedia
 	s.relayInv <- relayMsg{invVect: invVect, data: data, immediate: immediate}
 }
 
+// relayLocalInventory relays the passed inventory vector, which must have
+// originated locally, to all connected peers that are not already known to
+// have it.  It is trickled to the peers after a shorter delay than other
+// inventory.
+func (s *server) relayLocalInventory(invVect *wire.InvVect, data interface{}) {
+	s.relayInv <- relayMsg{invVect: invVect, data: data, local: true}
+}
+
 // RelayBlockAnnouncement creates a block announcement for the passed block and
 // relays that announcement immediately to all connected peers that advertise
 // the given required services and are not already known to have it.
 func (s *server) RelayBlockAnnouncement(block *dcrutil.Block, reqServices wire.ServiceFlag) {
+	// Create the compact block sent to peers that prefer compact block
+	// announcements.  Those peers will receive a headers announcement instead
+	// in the unlikely event a random nonce can't be generated.
+	var cmpctBlock *wire.MsgCmpctBlock
+	nonce, err := wire.RandomUint64()
+	if err == nil {
+		cmpctBlock = netsync.NewCmpctBlock(block.MsgBlock(), nonce)
+	}
+
 	invVect := wire.NewInvVect(wire.InvTypeBlock, block.Hash())
 	s.relayInv <- relayMsg{
 		invVect:     invVect,
 		data:        block.MsgBlock().Header,
 		immediate:   true,
 		reqServices: reqServices,
+		cmpctBlock:  cmpctBlock,
 	}
 }
 
@@ -2442,6 +2951,7 @@ func (s *server) AddedNodeInfo() []*serverPeer {
 // for the server.  It is safe for concurrent access.
 func (s *server) AddBytesSent(bytesSent uint64) {
 	atomic.AddUint64(&s.bytesSent, bytesSent)
+	s.uploadTarget.addBytesSent(bytesSent, time.Now())
 }
 
 // AddBytesReceived adds the passed number of bytes to the total bytes received
@@ -2745,7 +3255,7 @@ func (s *server) handleBlockchainNotification(notification *blockchain.Notificat
 			s.PruneRebroadcastInventory()
 
 			// Notify registered websocket clients of incoming block.
-			r.NotifyBlockConnected(block)
+			r.NotifyBlockConnected(block, ntfn.PrevScripts)
 		}
 
 		if s.bg != nil {
@@ -2760,6 +3270,8 @@ func (s *server) handleBlockchainNotification(notification *blockchain.Notificat
 				Parent:            parentBlock,
 				IsTreasuryEnabled: isTreasuryEnabled,
 				PrevScripts:       ntfn.PrevScripts,
+				TicketsMissed:     ntfn.TicketsMissed,
+				TicketsExpired:    ntfn.TicketsExpired,
 			})
 		}
 
@@ -3022,7 +3534,7 @@ func (s *server) rebroadcastHandler(ctx context.Context) {
 			// yet. We periodically resubmit them until they have.
 			for iv, data := range pendingInvs {
 				ivCopy := iv
-				s.RelayInventory(&ivCopy, data, false)
+				s.relayLocalInventory(&ivCopy, data)
 			}
 
 			// Process at a random time up to 30mins (in seconds)
@@ -3109,6 +3621,11 @@ func (s *server) Run(ctx context.Context) {
 		go s.upnpUpdateThread(ctx)
 	}
 
+	if s.torTarget != "" {
+		s.wg.Add(1)
+		go s.torHiddenServiceHandler(ctx)
+	}
+
 	if !cfg.DisableRPC {
 		// Start the rebroadcastHandler, which ensures user tx received by
 		// the RPC server are rebroadcast until being included in a block.
@@ -3163,6 +3680,10 @@ func (s *server) Run(ctx context.Context) {
 
 	srvrLog.Warnf("Server shutting down")
 
+	// Wait for any optional indexes that are being synced or removed in the
+	// background to stop.
+	s.indexMgr.Wait()
+
 	s.feeEstimator.Close()
 
 	s.chain.ShutdownUtxoCache()
@@ -3275,6 +3796,187 @@ out:
 	s.wg.Done()
 }
 
+// torHiddenServiceTarget returns the address Tor should forward connections to
+// the hidden service to for the provided P2P listener address.  Listeners on
+// all interfaces are reached via the loopback address.
+func torHiddenServiceTarget(addr net.Addr) string {
+	host, port, err := net.SplitHostPort(addr.String())
+	if err != nil {
+		return addr.String()
+	}
+	if ip := net.ParseIP(host); ip == nil || ip.IsUnspecified() {
+		host = "127.0.0.1"
+		if ip != nil && ip.To4() == nil {
+			host = "::1"
+		}
+	}
+	return net.JoinHostPort(host, port)
+}
+
+// createTorHiddenService connects to the Tor control port, creates a hidden
+// service that forwards connections to the P2P listener, and adds the address
+// of the hidden service to the local addresses advertised to peers.
+//
+// The private key of the hidden service is loaded from the data directory when
+// it exists so the same address is used across restarts.  Otherwise, a new key
+// is generated by Tor and stored in the data directory.
+//
+// The returned control port connection must remain open for as long as the
+// hidden service is to remain active.
+func (s *server) createTorHiddenService(ctx context.Context) (*connmgr.TorControl, error) {
+	keyPath := filepath.Join(cfg.DataDir, torKeyFilename)
+	keyBytes, err := os.ReadFile(keyPath)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	privKey := strings.TrimSpace(string(keyBytes))
+
+	port, err := strconv.ParseUint(s.chainParams.DefaultPort, 10, 16)
+	if err != nil {
+		return nil, err
+	}
+	control, err := connmgr.DialTorControl(ctx, cfg.TorControl,
+		cfg.TorControlPass)
+	if err != nil {
+		return nil, err
+	}
+	service, err := control.AddOnion(ctx, privKey, uint16(port), s.torTarget)
+	if err != nil {
+		control.Close()
+		return nil, err
+	}
+	if service.PrivateKey != privKey {
+		err := os.WriteFile(keyPath, []byte(service.PrivateKey+"\n"), 0600)
+		if err != nil {
+			control.Close()
+			return nil, err
+		}
+	}
+
+	host := service.ServiceID + ".onion"
+	na, err := s.addrManager.HostToNetAddress(host, uint16(port), s.services)
+	if err != nil {
+		control.Close()
+		return nil, err
+	}
+	if err := s.addrManager.AddLocalAddress(na, addrmgr.ManualPrio); err != nil {
+		control.Close()
+		return nil, err
+	}
+	srvrLog.Infof("Tor hidden service %s created", net.JoinHostPort(host,
+		s.chainParams.DefaultPort))
+	return control, nil
+}
+
+// torHiddenServiceHandler creates the Tor hidden service for the P2P listener
+// and keeps it active until the provided context is cancelled.  Failed attempts
+// to create the hidden service, such as when Tor is not running yet, are
+// retried periodically.
+//
+// This must be run as a goroutine.
+func (s *server) torHiddenServiceHandler(ctx context.Context) {
+	defer s.wg.Done()
+
+	var control *connmgr.TorControl
+	for control == nil {
+		attemptCtx, cancel := context.WithTimeout(ctx, torControlTimeout)
+		var err error
+		control, err = s.createTorHiddenService(attemptCtx)
+		cancel()
+		if err != nil {
+			srvrLog.Warnf("Unable to create Tor hidden service: %v", err)
+			select {
+			case <-time.After(torControlRetryInterval):
+			case <-ctx.Done():
+				return
+			}
+		}
+	}
+
+	<-ctx.Done()
+	if err := control.Close(); err != nil {
+		srvrLog.Debugf("Unable to close Tor control port connection: %v", err)
+	}
+}
+
+// serializedAnchors is the form of the anchor addresses that is persisted to
+// disk.
+type serializedAnchors struct {
+	Version int      `json:"version"`
+	Anchors []string `json:"anchors"`
+}
+
+// saveAnchors persists the addresses of up to maxAnchors of the connected block
+// relay only peers to the data directory so they are connected to first on the
+// next startup.  This helps prevent an attacker from taking advantage of a
+// restart to partition the node from the network.
+//
+// This function MUST be called from the peer handler goroutine.
+func (s *server) saveAnchors(state *peerState) error {
+	anchors := serializedAnchors{Version: anchorsVersion}
+	for _, sp := range state.outboundPeers {
+		if len(anchors.Anchors) == maxAnchors {
+			break
+		}
+		if sp.blockRelayOnly() && sp.Connected() && sp.VersionKnown() {
+			anchors.Anchors = append(anchors.Anchors, sp.Addr())
+		}
+	}
+	if len(anchors.Anchors) == 0 {
+		return nil
+	}
+
+	anchorsPath := filepath.Join(cfg.DataDir, anchorsFilename)
+	serialized, err := json.Marshal(&anchors)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(anchorsPath, serialized, 0600); err != nil {
+		return err
+	}
+	srvrLog.Debugf("Saved %d anchor peers", len(anchors.Anchors))
+	return nil
+}
+
+// loadAnchors loads the anchor addresses previously persisted to the data
+// directory and removes the file.  The file is removed so the anchors are only
+// used for a single startup which prevents repeatedly connecting to them in the
+// event they are able to cause the node to crash.  A missing file is not an
+// error.
+func loadAnchors() ([]net.Addr, error) {
+	anchorsPath := filepath.Join(cfg.DataDir, anchorsFilename)
+	serialized, err := os.ReadFile(anchorsPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Remove(anchorsPath); err != nil {
+		return nil, err
+	}
+
+	var anchors serializedAnchors
+	if err := json.Unmarshal(serialized, &anchors); err != nil {
+		return nil, fmt.Errorf("unable to decode anchors file %s: %w",
+			anchorsPath, err)
+	}
+	if anchors.Version != anchorsVersion {
+		return nil, fmt.Errorf("unsupported anchors file version %d",
+			anchors.Version)
+	}
+	addrs := make([]net.Addr, 0, len(anchors.Anchors))
+	for _, anchor := range anchors.Anchors {
+		addr, err := addrStringToNetAddr(anchor)
+		if err != nil {
+			srvrLog.Debugf("Ignoring anchor %s: %v", anchor, err)
+			continue
+		}
+		addrs = append(addrs, addr)
+	}
+	return addrs, nil
+}
+
 // standardScriptVerifyFlags returns the script flags that should be used when
 // executing transaction scripts to enforce additional checks which are required
 // for the script to be considered standard.  Note these flags are different
@@ -3408,11 +4110,29 @@ func setupRPCListeners() ([]net.Listener, error) {
 // decred network type specified by chainParams.  Use start to begin accepting
 // connections from peers.
 func newServer(ctx context.Context, listenAddrs []string, db database.DB,
-	utxoDb *leveldb.DB, chainParams *chaincfg.Params,
+	utxoDb *leveldb.DB, indexDb database.DB, chainParams *chaincfg.Params,
 	dataDir string) (*server, error) {
 
 	amgr := addrmgr.New(cfg.DataDir, dcrdLookup)
+	if cfg.ASMap != "" {
+		asmap, err := addrmgr.LoadASMap(cfg.ASMap)
+		if err != nil {
+			return nil, fmt.Errorf("unable to load AS map: %w", err)
+		}
+		amgr.SetASMap(asmap)
+		srvrLog.Infof("Using AS map %s (checksum %s)", cfg.ASMap,
+			asmap.Checksum())
+	}
 	services := defaultServices
+	if !cfg.NoV2Transport {
+		services |= wire.SFNodeP2PV2
+	}
+
+	// Load the persisted ban list.
+	banList := banmanager.NewBanList(path.Join(cfg.DataDir, banListFilename))
+	if err := banList.Load(); err != nil {
+		return nil, err
+	}
 
 	var listeners []net.Listener
 	var nat *upnpNAT
@@ -3428,15 +4148,37 @@ func newServer(ctx context.Context, listenAddrs []string, db database.DB,
 		}
 	}
 
+	// Tor forwards connections to the hidden service created via the Tor
+	// control port to the first listener.
+	var torTarget string
+	if cfg.TorControl != "" {
+		if len(listeners) > 0 {
+			torTarget = torHiddenServiceTarget(listeners[0].Addr())
+		} else {
+			srvrLog.Warnf("Not creating Tor hidden service since listening " +
+				"is disabled")
+		}
+	}
+
 	// Create a SigCache instance.
 	sigCache, err := txscript.NewSigCache(cfg.SigCacheMaxSize)
 	if err != nil {
 		return nil, err
 	}
 
+	// Determine the maximum possible block size which is used to reserve
+	// enough of the upload target to relay new blocks.
+	var maxBlockSize int
+	for _, size := range chainParams.MaximumBlockSizes {
+		if size > maxBlockSize {
+			maxBlockSize = size
+		}
+	}
+
 	s := server{
 		chainParams:          chainParams,
 		addrManager:          amgr,
+		banList:              banList,
 		newPeers:             make(chan *serverPeer, cfg.MaxPeers),
 		donePeers:            make(chan *serverPeer, cfg.MaxPeers),
 		banPeers:             make(chan *serverPeer, cfg.MaxPeers),
@@ -3445,6 +4187,7 @@ func newServer(ctx context.Context, listenAddrs []string, db database.DB,
 		broadcast:            make(chan broadcastMsg, cfg.MaxPeers),
 		modifyRebroadcastInv: make(chan interface{}),
 		nat:                  nat,
+		torTarget:            torTarget,
 		db:                   db,
 		timeSource:           blockchain.NewMedianTime(),
 		services:             services,
@@ -3455,6 +4198,12 @@ func newServer(ctx context.Context, listenAddrs []string, db database.DB,
 			recentlyConfirmedTxnsFPRate),
 		indexSubscriber: indexers.NewIndexSubscriber(ctx),
 		quit:            make(chan struct{}),
+		inboundTrickle:  peer.NewTrickleSchedule(peer.DefaultInboundTrickleInterval),
+		inboundLocalTrickle: peer.NewTrickleSchedule(
+			peer.DefaultLocalTrickleInterval),
+		msgStats: newMsgStats(),
+		uploadTarget: newUploadTarget(cfg.MaxUploadTarget*1024*1024,
+			maxBlockSize, chainParams.TargetTimePerBlock),
 	}
 
 	feC := fees.EstimatorConfig{
@@ -3512,27 +4261,29 @@ func newServer(ctx context.Context, listenAddrs []string, db database.DB,
 		Backend:      utxoBackend,
 		FlushBlockDB: s.db.Flush,
 		MaxSize:      uint64(cfg.UtxoCacheMaxSize) * 1024 * 1024,
+		MemoryLimit:  uint64(cfg.UtxoCacheMemLimit) * 1024 * 1024,
 	})
 	s.chain, err = blockchain.New(ctx,
 		&blockchain.Config{
-			DB:               s.db,
-			UtxoBackend:      utxoBackend,
-			ChainParams:      s.chainParams,
-			AssumeValid:      assumeValid,
-			LatestCheckpoint: latestCheckpoint,
-			TimeSource:       s.timeSource,
-			Notifications:    s.handleBlockchainNotification,
-			SigCache:         s.sigCache,
-			SubsidyCache:     s.subsidyCache,
-			IndexSubscriber:  s.indexSubscriber,
-			UtxoCache:        utxoCache,
+			DB:                s.db,
+			UtxoBackend:       utxoBackend,
+			ChainParams:       s.chainParams,
+			AssumeValid:       assumeValid,
+			LatestCheckpoint:  latestCheckpoint,
+			TimeSource:        s.timeSource,
+			Notifications:     s.handleBlockchainNotification,
+			SigCache:          s.sigCache,
+			SubsidyCache:      s.subsidyCache,
+			IndexSubscriber:   s.indexSubscriber,
+			UtxoCache:         utxoCache,
+			ReindexChainState: cfg.ReindexChainState,
 		})
 	if err != nil {
 		return nil, err
 	}
 
 	queryer := &blockchain.ChainQueryerAdapter{BlockChain: s.chain}
-	err = indexers.AddIndexSpendConsumers(s.db, queryer)
+	err = indexers.AddIndexSpendConsumers(indexDb, queryer)
 	if err != nil {
 		return nil, err
 	}
@@ -3548,14 +4299,16 @@ func newServer(ctx context.Context, listenAddrs []string, db database.DB,
 			indxLog.Info("Transaction index is enabled")
 		}
 
-		s.txIndex, err = indexers.NewTxIndex(s.indexSubscriber, db, queryer)
+		s.txIndex, err = indexers.NewTxIndex(s.indexSubscriber, indexDb,
+			queryer)
 		if err != nil {
 			return nil, err
 		}
 	}
 	if cfg.AddrIndex {
 		indxLog.Info("Address index is enabled")
-		s.addrIndex, err = indexers.NewAddrIndex(s.indexSubscriber, db, queryer)
+		s.addrIndex, err = indexers.NewAddrIndex(s.indexSubscriber, indexDb,
+			queryer)
 		if err != nil {
 			return nil, err
 		}
@@ -3563,15 +4316,72 @@ func newServer(ctx context.Context, listenAddrs []string, db database.DB,
 	if !cfg.NoExistsAddrIndex {
 		indxLog.Info("Exists address index is enabled")
 		s.existsAddrIndex, err = indexers.NewExistsAddrIndex(s.indexSubscriber,
-			db, queryer)
+			indexDb, queryer)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if cfg.SpenderIndex {
+		indxLog.Info("Spender index is enabled")
+		s.spenderIndex, err = indexers.NewSpenderIndex(s.indexSubscriber,
+			indexDb, queryer)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if cfg.AddrBalanceIndex {
+		indxLog.Info("Address balance index is enabled")
+		s.addrBalIndex, err = indexers.NewAddrBalanceIndex(s.indexSubscriber,
+			indexDb, queryer)
 		if err != nil {
 			return nil, err
 		}
 	}
-	err = s.indexSubscriber.CatchUp(ctx, s.db, queryer)
+	if cfg.BlockStatsIndex {
+		indxLog.Info("Block statistics index is enabled")
+		s.blockStatsIndex, err = indexers.NewBlockStatsIndex(s.indexSubscriber,
+			indexDb, queryer)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if cfg.TicketIndex {
+		indxLog.Info("Ticket index is enabled")
+		s.ticketIndex, err = indexers.NewTicketIndex(s.indexSubscriber,
+			indexDb, queryer)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if cfg.CoinStatsIndex {
+		indxLog.Info("Coin statistics index is enabled")
+		s.coinStatsIndex, err = indexers.NewCoinStatsIndex(s.indexSubscriber,
+			indexDb, queryer)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if cfg.CFHeaderIndex {
+		indxLog.Info("Filter header index is enabled")
+		s.cfHeaderIndex, err = indexers.NewCFHeaderIndex(s.indexSubscriber,
+			indexDb, queryer)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if cfg.ScriptIndex {
+		indxLog.Info("Script index is enabled")
+		s.scriptIndex, err = indexers.NewScriptIndex(s.indexSubscriber,
+			indexDb, queryer)
+		if err != nil {
+			return nil, err
+		}
+	}
+	err = s.indexSubscriber.CatchUp(ctx, queryer)
 	if err != nil {
 		return nil, err
 	}
+	s.indexMgr = newIndexManager(ctx, &s, indexDb, queryer)
 
 	txC := mempool.Config{
 		Policy: mempool.Policy{
@@ -3617,6 +4427,7 @@ func newServer(ctx context.Context, listenAddrs []string, db database.DB,
 		},
 		AddrIndex:                 s.addrIndex,
 		ExistsAddrIndex:           s.existsAddrIndex,
+		AddrBalanceIndex:          s.addrBalIndex,
 		AddTxToFeeEstimation:      s.feeEstimator.AddMemPoolTransaction,
 		RemoveTxFromFeeEstimation: s.feeEstimator.RemoveMemPoolTransaction,
 		OnVoteReceived: func(voteTx *dcrutil.Tx) {
@@ -3769,10 +4580,22 @@ func newServer(ctx context.Context, listenAddrs []string, db database.DB,
 				// to the same network segment at the expense of
 				// others.
 				netAddr := addr.NetAddress()
-				if s.OutboundGroupCount(netAddr.GroupKey()) != 0 {
+				if s.OutboundGroupCount(s.addrManager.GroupKey(netAddr)) != 0 {
 					continue
 				}
 
+				// Skip addresses on networks that can't be dialed.  There
+				// is no support for connecting to I2P destinations and Tor
+				// hidden services may be disabled.
+				switch netAddr.Type {
+				case addrmgr.I2PAddress:
+					continue
+				case addrmgr.TORv3Address:
+					if cfg.NoOnion {
+						continue
+					}
+				}
+
 				// only allow recent nodes (10mins) after we failed 30
 				// times
 				if tries < 30 && time.Since(addr.LastAttempt()) < 10*time.Minute {
@@ -3792,20 +4615,34 @@ func newServer(ctx context.Context, listenAddrs []string, db database.DB,
 		}
 	}
 
-	// Create a connection manager.
+	// Load the anchor peers that were connected at the last shutdown so they
+	// are connected to first when automatically connecting to peers.
+	var anchors []net.Addr
+	if newAddressFunc != nil {
+		anchors, err = loadAnchors()
+		if err != nil {
+			srvrLog.Warnf("Unable to load anchor peers: %v", err)
+		}
+	}
+
+	// Create a connection manager.  A quarter of the outbound peers are
+	// block relay only peers.
 	targetOutbound := defaultTargetOutbound
 	if cfg.MaxPeers < targetOutbound {
 		targetOutbound = cfg.MaxPeers
 	}
 	cmgr, err := connmgr.New(&connmgr.Config{
-		Listeners:      listeners,
-		OnAccept:       s.inboundPeerConnected,
-		RetryDuration:  connectionRetryInterval,
-		TargetOutbound: uint32(targetOutbound),
-		Dial:           dcrdDial,
-		Timeout:        cfg.DialTimeout,
-		OnConnection:   s.outboundPeerConnected,
-		GetNewAddress:  newAddressFunc,
+		Listeners:        listeners,
+		OnAccept:         s.inboundPeerConnected,
+		IsBanned:         s.isAddrBanned,
+		RetryDuration:    connectionRetryInterval,
+		TargetOutbound:   uint32(targetOutbound),
+		TargetBlockRelay: uint32(targetOutbound / 4),
+		Anchors:          anchors,
+		Dial:             dcrdDial,
+		Timeout:          cfg.DialTimeout,
+		OnConnection:     s.outboundPeerConnected,
+		GetNewAddress:    newAddressFunc,
 	})
 	if err != nil {
 		return nil, err
@@ -3865,6 +4702,7 @@ func newServer(ctx context.Context, listenAddrs []string, db database.DB,
 			NetInfo:              cfg.generateNetworkInfo(),
 			MinRelayTxFee:        cfg.minRelayTxFee,
 			Proxy:                cfg.Proxy,
+			BanDuration:          cfg.BanDuration,
 			RPCUser:              cfg.RPCUser,
 			RPCPass:              cfg.RPCPass,
 			RPCLimitUser:         cfg.RPCLimitUser,
@@ -3879,6 +4717,7 @@ func newServer(ctx context.Context, listenAddrs []string, db database.DB,
 			UserAgentVersion:     userAgentVersion,
 			LogManager:           &rpcLogManager{},
 			FiltererV2:           s.chain,
+			IndexManager:         s.indexMgr,
 		}
 		if s.existsAddrIndex != nil {
 			rpcsConfig.ExistsAddresser = s.existsAddrIndex
@@ -3892,6 +4731,27 @@ func newServer(ctx context.Context, listenAddrs []string, db database.DB,
 		if s.addrIndex != nil {
 			rpcsConfig.AddrIndexer = s.addrIndex
 		}
+		if s.spenderIndex != nil {
+			rpcsConfig.SpenderIndexer = s.spenderIndex
+		}
+		if s.addrBalIndex != nil {
+			rpcsConfig.AddrBalanceIndexer = s.addrBalIndex
+		}
+		if s.blockStatsIndex != nil {
+			rpcsConfig.BlockStatsIndexer = s.blockStatsIndex
+		}
+		if s.ticketIndex != nil {
+			rpcsConfig.TicketIndexer = s.ticketIndex
+		}
+		if s.coinStatsIndex != nil {
+			rpcsConfig.CoinStatsIndexer = s.coinStatsIndex
+		}
+		if s.cfHeaderIndex != nil {
+			rpcsConfig.CFHeaderIndexer = s.cfHeaderIndex
+		}
+		if s.scriptIndex != nil {
+			rpcsConfig.ScriptIndexer = s.scriptIndex
+		}
 
 		s.rpcServer, err = rpcserver.New(&rpcsConfig)
 		if err != nil {
@@ -3990,13 +4850,22 @@ func initListeners(ctx context.Context, params *chaincfg.Params, amgr *addrmgr.A
 
 // addrStringToNetAddr takes an address in the form of 'host:port' and returns
 // a net.Addr which maps to the original address with any host names resolved
-// to IP addresses.
+// to IP addresses.  Tor hidden service addresses can't be resolved to an IP
+// address, so they are returned as is to be dialed via the onion proxy.
 func addrStringToNetAddr(addr string) (net.Addr, error) {
 	host, strPort, err := net.SplitHostPort(addr)
 	if err != nil {
 		return nil, err
 	}
 
+	if strings.HasSuffix(host, ".onion") {
+		if cfg.NoOnion {
+			return nil, fmt.Errorf("unable to connect to %s: tor hidden "+
+				"services are disabled", host)
+		}
+		return simpleAddr{net: "tcp", addr: addr}, nil
+	}
+
 	// Attempt to look up an IP address associated with the parsed host.
 	// The dcrdLookup function will transparently handle performing the
 	// lookup over Tor if necessary.
@@ -4066,6 +4935,22 @@ func addLocalAddress(addrMgr *addrmgr.AddrManager, addr string, services wire.Se
 	return nil
 }
 
+// isAddrBanned returns whether or not the IP of the provided address is
+// banned.  It is used by the connection manager to reject inbound connections
+// from banned addresses before any handshake takes place.
+func (s *server) isAddrBanned(addr net.Addr) bool {
+	host, _, err := net.SplitHostPort(addr.String())
+	if err != nil {
+		return false
+	}
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return false
+	}
+	_, banned := s.banList.IsBanned(ip)
+	return banned
+}
+
 // isWhitelisted returns whether the IP address is included in the whitelisted
 // networks and IPs.
 func isWhitelisted(addr net.Addr) bool {
@@ -48,12 +48,15 @@ interactions in no particular order.
 
 	Peer A Sends                          Peer B Responds
 	----------------------------------------------------------------------------
-	getaddr message (MsgGetAddr)          addr message (MsgAddr)
+	getaddr message (MsgGetAddr)          addr message (MsgAddr) -or-
+	                                      addrv2 message (MsgAddrV2)***
 	getblocks message (MsgGetBlocks)      inv message (MsgInv)
 	inv message (MsgInv)                  getdata message (MsgGetData)
 	getdata message (MsgGetData)          block message (MsgBlock) -or-
+	                                      cmpctblock message (MsgCmpctBlock)** -or-
 	                                      tx message (MsgTx) -or-
 	                                      notfound message (MsgNotFound)
+	getblocktxn message (MsgGetBlockTxn)  blocktxn message (MsgBlockTxn)**
 	getheaders message (MsgGetHeaders)    headers message (MsgHeaders)
 	ping message (MsgPing)                pong message (MsgHeaders)* -or-
 	                                      (none -- Ability to send message is enough)
@@ -62,6 +65,14 @@ interactions in no particular order.
 	* The pong message was not added until later protocol versions as defined
 	  in BIP0031.  The BIP0031Version constant can be used to detect a recent
 	  enough protocol version for this purpose (version > BIP0031Version).
+	** The compact block messages were not added until protocol version
+	   CompactBlocksVersion.  A cmpctblock message is only sent in response to
+	   a getdata message requesting inventory of type InvTypeCmpctBlock or,
+	   when requested via a sendcmpct message (MsgSendCmpct), to announce new
+	   blocks.
+	*** The addrv2 message was not added until protocol version
+	    AddrV2Version.  It is sent in place of the addr message to peers that
+	    negotiated that version or later.
 
 Common Parameters
 
@@ -80,6 +80,19 @@ func TestMessage(t *testing.T) {
 	msgReject := NewMsgReject("block", RejectDuplicate, "duplicate block")
 	msgGetInitState := NewMsgGetInitState()
 	msgInitState := NewMsgInitState()
+	msgSendCmpct := NewMsgSendCmpct(true, CmpctBlockEncodingVersion)
+	msgCmpctBlock := NewMsgCmpctBlock(&testBlock.Header, 1)
+	msgCmpctBlock.ShortIDs = []uint64{1}
+	msgCmpctBlock.PrefilledTxns = []PrefilledTx{{Index: 0, Tx: NewMsgTx()}}
+	msgCmpctBlock.STxShortIDs = []uint64{}
+	msgCmpctBlock.PrefilledSTxns = []PrefilledTx{}
+	msgGetBlockTxn := NewMsgGetBlockTxn(&chainhash.Hash{})
+	msgGetBlockTxn.Indexes = []uint32{0, 2}
+	msgGetBlockTxn.STxIndexes = []uint32{1}
+	msgBlockTxn := NewMsgBlockTxn(&chainhash.Hash{})
+	msgBlockTxn.Transactions = []*MsgTx{NewMsgTx()}
+	msgBlockTxn.STransactions = []*MsgTx{}
+	msgAddrV2 := NewMsgAddrV2()
 
 	tests := []struct {
 		in     Message     // Value to encode
@@ -112,6 +125,11 @@ func TestMessage(t *testing.T) {
 		{msgCFTypes, msgCFTypes, pver, MainNet, 26},
 		{msgGetInitState, msgGetInitState, pver, MainNet, 25},
 		{msgInitState, msgInitState, pver, MainNet, 27},
+		{msgSendCmpct, msgSendCmpct, pver, MainNet, 33},
+		{msgCmpctBlock, msgCmpctBlock, pver, MainNet, 338},
+		{msgGetBlockTxn, msgGetBlockTxn, pver, MainNet, 61},
+		{msgBlockTxn, msgBlockTxn, pver, MainNet, 73},
+		{msgAddrV2, msgAddrV2, pver, MainNet, 25},
 	}
 
 	t.Logf("Running %d tests", len(tests))
@@ -0,0 +1,307 @@
+// Copyright (c) 2023 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package wire
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/davecgh/go-spew/spew"
+)
+
+// TestAddrV2 tests the MsgAddrV2 API.
+func TestAddrV2(t *testing.T) {
+	pver := ProtocolVersion
+
+	// Ensure the command is expected value.
+	wantCmd := "addrv2"
+	msg := NewMsgAddrV2()
+	if cmd := msg.Command(); cmd != wantCmd {
+		t.Errorf("NewMsgAddrV2: wrong command - got %v want %v",
+			cmd, wantCmd)
+	}
+
+	// Ensure max payload is expected value for latest protocol version.
+	// Num addresses (size of varInt for max address) + max allowed addresses
+	// of the max size (8 byte timestamp, 8 byte services, 1 byte network id,
+	// 3 byte varint address length, 512 byte address, and 2 byte port).
+	wantPayload := uint32(3 + 1000*534)
+	maxPayload := msg.MaxPayloadLength(pver)
+	if maxPayload != wantPayload {
+		t.Errorf("MaxPayloadLength: wrong max payload length for "+
+			"protocol version %d - got %v, want %v", pver,
+			maxPayload, wantPayload)
+	}
+
+	// Ensure max payload length is not more than MaxMessagePayload.
+	if maxPayload > MaxMessagePayload {
+		t.Fatalf("MaxPayloadLength: payload length (%v) for protocol "+
+			"version %d exceeds MaxMessagePayload (%v).", maxPayload, pver,
+			MaxMessagePayload)
+	}
+
+	// Ensure the max payload is zero for protocol versions prior to the
+	// introduction of the message.
+	if got := msg.MaxPayloadLength(AddrV2Version - 1); got != 0 {
+		t.Errorf("MaxPayloadLength: wrong max payload length for protocol "+
+			"version %d - got %v, want 0", AddrV2Version-1, got)
+	}
+
+	// Ensure IP addresses are converted to the expected network types.
+	na := NewNetAddressV2IPPort(net.ParseIP("127.0.0.1"), 8333, SFNodeNetwork)
+	if na.Type != IPv4Address || len(na.Addr) != 4 {
+		t.Errorf("NewNetAddressV2IPPort: wrong IPv4 address - got type %v "+
+			"len %d", na.Type, len(na.Addr))
+	}
+	na6 := NewNetAddressV2IPPort(net.ParseIP("2001:db8::1"), 8333, 0)
+	if na6.Type != IPv6Address || len(na6.Addr) != 16 {
+		t.Errorf("NewNetAddressV2IPPort: wrong IPv6 address - got type %v "+
+			"len %d", na6.Type, len(na6.Addr))
+	}
+	if !na.HasService(SFNodeNetwork) || na6.HasService(SFNodeNetwork) {
+		t.Errorf("HasService: unexpected services %v and %v", na.Services,
+			na6.Services)
+	}
+	na6.AddService(SFNodeNetwork)
+	if !na6.HasService(SFNodeNetwork) {
+		t.Errorf("AddService: service not added")
+	}
+
+	// Ensure the network address types are printed as expected.
+	if s := TORv3Address.String(); s != "TORv3Address" {
+		t.Errorf("String: unexpected string %q", s)
+	}
+	if s := NetAddressType(3).String(); s != "Unknown NetAddressType (3)" {
+		t.Errorf("String: unexpected string %q", s)
+	}
+
+	// Ensure addresses are added properly.
+	err := msg.AddAddress(na)
+	if err != nil {
+		t.Errorf("AddAddress: %v", err)
+	}
+	if msg.AddrList[0] != na {
+		t.Errorf("AddAddress: wrong address added - got %v, want %v",
+			spew.Sprint(msg.AddrList[0]), spew.Sprint(na))
+	}
+
+	// Ensure the address list is cleared properly.
+	msg.ClearAddresses()
+	if len(msg.AddrList) != 0 {
+		t.Errorf("ClearAddresses: address list is not empty - "+
+			"got %v [%v], want %v", len(msg.AddrList),
+			spew.Sprint(msg.AddrList[0]), 0)
+	}
+
+	// Ensure adding more than the max allowed addresses per message returns
+	// error.
+	for i := 0; i < MaxAddrPerV2Msg+1; i++ {
+		err = msg.AddAddress(na)
+	}
+	if !errors.Is(err, ErrTooManyAddrs) {
+		t.Errorf("AddAddress: expected error on too many addresses " +
+			"not received")
+	}
+	err = msg.AddAddresses(na)
+	if !errors.Is(err, ErrTooManyAddrs) {
+		t.Errorf("AddAddresses: expected error on too many addresses " +
+			"not received")
+	}
+}
+
+// TestAddrV2Wire tests the MsgAddrV2 wire encode and decode for all of the
+// supported network types as well as unknown networks.
+func TestAddrV2Wire(t *testing.T) {
+	pver := ProtocolVersion
+	ts := time.Unix(0x495fab29, 0) // 2009-01-03 12:15:05 -0600 CST
+	torKey := bytes.Repeat([]byte{0xaa}, 32)
+	i2pHash := bytes.Repeat([]byte{0xbb}, 32)
+	cjdnsIP := net.ParseIP("fc00::1")
+
+	msg := NewMsgAddrV2()
+	msg.AddAddresses(
+		NewNetAddressV2(ts, SFNodeNetwork, IPv4Address,
+			net.ParseIP("127.0.0.1").To4(), 8333),
+		NewNetAddressV2(ts, 0, IPv6Address, net.ParseIP("::1"), 8334),
+		NewNetAddressV2(ts, SFNodeNetwork, TORv3Address, torKey, 9108),
+		NewNetAddressV2(ts, SFNodeNetwork, I2PAddress, i2pHash, 0),
+		NewNetAddressV2(ts, SFNodeNetwork, CJDNSAddress, cjdnsIP, 9108),
+		NewNetAddressV2(ts, 0, NetAddressType(0xff), []byte{0x01, 0x02}, 1),
+	)
+	tsBytes := []byte{0x29, 0xab, 0x5f, 0x49, 0x00, 0x00, 0x00, 0x00}
+	var encoded []byte
+	encoded = append(encoded, 0x06) // Varint for number of addresses
+	encoded = append(encoded, tsBytes...)
+	encoded = append(encoded, 0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+		0x01,                   // Network id
+		0x04,                   // Varint for address length
+		0x7f, 0x00, 0x00, 0x01, // IP 127.0.0.1
+		0x20, 0x8d) // Port 8333 in big-endian
+	encoded = append(encoded, tsBytes...)
+	encoded = append(encoded, make([]byte, 8)...) // Services
+	encoded = append(encoded, 0x02, 0x10)
+	encoded = append(encoded, net.ParseIP("::1")...)
+	encoded = append(encoded, 0x20, 0x8e)
+	encoded = append(encoded, tsBytes...)
+	encoded = append(encoded, 0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+		0x04, 0x20)
+	encoded = append(encoded, torKey...)
+	encoded = append(encoded, 0x23, 0x94)
+	encoded = append(encoded, tsBytes...)
+	encoded = append(encoded, 0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+		0x05, 0x20)
+	encoded = append(encoded, i2pHash...)
+	encoded = append(encoded, 0x00, 0x00)
+	encoded = append(encoded, tsBytes...)
+	encoded = append(encoded, 0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+		0x06, 0x10)
+	encoded = append(encoded, cjdnsIP...)
+	encoded = append(encoded, 0x23, 0x94)
+	encoded = append(encoded, tsBytes...)
+	encoded = append(encoded, make([]byte, 8)...)
+	encoded = append(encoded, 0xff, 0x02, 0x01, 0x02, 0x00, 0x01)
+
+	tests := []struct {
+		in  *MsgAddrV2 // Message to encode
+		out *MsgAddrV2 // Expected decoded message
+		buf []byte     // Wire encoding
+	}{
+		{NewMsgAddrV2(), NewMsgAddrV2(), []byte{0x00}},
+		{msg, msg, encoded},
+	}
+
+	t.Logf("Running %d tests", len(tests))
+	for i, test := range tests {
+		// Encode the message to wire format.
+		var buf bytes.Buffer
+		err := test.in.BtcEncode(&buf, pver)
+		if err != nil {
+			t.Errorf("BtcEncode #%d error %v", i, err)
+			continue
+		}
+		if !bytes.Equal(buf.Bytes(), test.buf) {
+			t.Errorf("BtcEncode #%d\n got: %s want: %s", i,
+				spew.Sdump(buf.Bytes()), spew.Sdump(test.buf))
+			continue
+		}
+
+		// Decode the message from wire format.
+		var msg MsgAddrV2
+		rbuf := bytes.NewReader(test.buf)
+		err = msg.BtcDecode(rbuf, pver)
+		if err != nil {
+			t.Errorf("BtcDecode #%d error %v", i, err)
+			continue
+		}
+		if !reflect.DeepEqual(&msg, test.out) {
+			t.Errorf("BtcDecode #%d\n got: %s want: %s", i,
+				spew.Sdump(msg), spew.Sdump(test.out))
+			continue
+		}
+	}
+}
+
+// TestAddrV2WireErrors performs negative tests against wire encode and decode
+// of MsgAddrV2 to confirm error paths work correctly.
+func TestAddrV2WireErrors(t *testing.T) {
+	pver := ProtocolVersion
+	ts := time.Unix(0x495fab29, 0) // 2009-01-03 12:15:05 -0600 CST
+
+	// A message with a single IPv4 address and its encoding.
+	baseMsg := NewMsgAddrV2()
+	baseMsg.AddAddress(NewNetAddressV2(ts, SFNodeNetwork, IPv4Address,
+		net.ParseIP("127.0.0.1").To4(), 8333))
+	var baseMsgEncoded bytes.Buffer
+	if err := baseMsg.BtcEncode(&baseMsgEncoded, pver); err != nil {
+		t.Fatalf("unexpected error encoding base message: %v", err)
+	}
+
+	// Message that forces an error by having more than the max allowed
+	// addresses.
+	maxAddrMsg := NewMsgAddrV2()
+	for i := 0; i < MaxAddrPerV2Msg; i++ {
+		maxAddrMsg.AddAddress(baseMsg.AddrList[0])
+	}
+	maxAddrMsg.AddrList = append(maxAddrMsg.AddrList, baseMsg.AddrList[0])
+	maxAddrMsgEncoded := []byte{
+		0xfd, 0xe9, 0x03, // Varint for number of addresses (1001)
+	}
+
+	// Message that forces an error by having an address of a known network
+	// with the wrong size.
+	badSizeMsg := NewMsgAddrV2()
+	badSizeMsg.AddAddress(NewNetAddressV2(ts, SFNodeNetwork, TORv3Address,
+		make([]byte, 16), 9108))
+	badSizeEncoded := append([]byte{0x01}, make([]byte, 16)...)
+	badSizeEncoded = append(badSizeEncoded, 0x04, 0x10)
+	badSizeEncoded = append(badSizeEncoded, make([]byte, 18)...)
+
+	// Message that forces an error by having an address of an unknown
+	// network that is larger than the max allowed.
+	largeAddrMsg := NewMsgAddrV2()
+	largeAddrMsg.AddAddress(NewNetAddressV2(ts, 0, NetAddressType(0xff),
+		make([]byte, maxNetAddressV2AddrSize+1), 0))
+	largeAddrEncoded := append([]byte{0x01}, make([]byte, 16)...)
+	largeAddrEncoded = append(largeAddrEncoded, 0xff, 0xfd, 0x01, 0x02)
+
+	tests := []struct {
+		in       *MsgAddrV2 // Value to encode
+		buf      []byte     // Wire encoding
+		pver     uint32     // Protocol version for wire encoding
+		max      int        // Max size of fixed buffer to induce errors
+		writeErr error      // Expected write error
+		readErr  error      // Expected read error
+	}{
+		// Force error in addresses count.
+		{baseMsg, baseMsgEncoded.Bytes(), pver, 0, io.ErrShortWrite, io.EOF},
+		// Force error in timestamp.
+		{baseMsg, baseMsgEncoded.Bytes(), pver, 1, io.ErrShortWrite, io.EOF},
+		// Force error in services.
+		{baseMsg, baseMsgEncoded.Bytes(), pver, 9, io.ErrShortWrite, io.EOF},
+		// Force error in network id.
+		{baseMsg, baseMsgEncoded.Bytes(), pver, 17, io.ErrShortWrite, io.EOF},
+		// Force error in address length.
+		{baseMsg, baseMsgEncoded.Bytes(), pver, 18, io.ErrShortWrite, io.EOF},
+		// Force error in address.
+		{baseMsg, baseMsgEncoded.Bytes(), pver, 19, io.ErrShortWrite, io.EOF},
+		// Force error in port.
+		{baseMsg, baseMsgEncoded.Bytes(), pver, 23, io.ErrShortWrite, io.EOF},
+		// Force error with greater than max addresses.
+		{maxAddrMsg, maxAddrMsgEncoded, pver, 3, ErrTooManyAddrs, ErrTooManyAddrs},
+		// Force error with an address of the wrong size for its network.
+		{badSizeMsg, badSizeEncoded, pver, len(badSizeEncoded), ErrInvalidMsg, ErrInvalidMsg},
+		// Force error with an address larger than the max allowed.
+		{largeAddrMsg, largeAddrEncoded, pver, len(largeAddrEncoded), ErrVarBytesTooLong, ErrVarBytesTooLong},
+		// Force error due to unsupported protocol version.
+		{baseMsg, baseMsgEncoded.Bytes(), AddrV2Version - 1, baseMsgEncoded.Len(), ErrMsgInvalidForPVer, ErrMsgInvalidForPVer},
+	}
+
+	t.Logf("Running %d tests", len(tests))
+	for i, test := range tests {
+		// Encode to wire format.
+		w := newFixedWriter(test.max)
+		err := test.in.BtcEncode(w, test.pver)
+		if !errors.Is(err, test.writeErr) {
+			t.Errorf("BtcEncode #%d wrong error - got: %v, want: %v", i, err,
+				test.writeErr)
+			continue
+		}
+
+		// Decode from wire format.
+		var msg MsgAddrV2
+		r := newFixedReader(test.max, test.buf)
+		err = msg.BtcDecode(r, test.pver)
+		if !errors.Is(err, test.readErr) {
+			t.Errorf("BtcDecode #%d wrong error - got: %v, want: %v", i, err,
+				test.readErr)
+			continue
+		}
+	}
+}
@@ -0,0 +1,144 @@
+// Copyright (c) 2023 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package wire
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/EXCCoin/exccd/chaincfg/chainhash"
+)
+
+// MsgBlockTxn implements the Message interface and represents a blocktxn
+// message.  It is used to deliver the transactions requested by a getblocktxn
+// message in the same order they were requested.
+//
+// This message was not added until protocol versions starting with
+// CompactBlocksVersion.
+type MsgBlockTxn struct {
+	BlockHash     chainhash.Hash
+	Transactions  []*MsgTx
+	STransactions []*MsgTx
+}
+
+// readBlockTxnTree reads the count prefixed transactions for a single
+// transaction tree of a blocktxn message from r.
+func readBlockTxnTree(op string, r io.Reader, pver uint32) ([]*MsgTx, error) {
+	count, err := ReadVarInt(r, pver)
+	if err != nil {
+		return nil, err
+	}
+
+	// Prevent more transactions than could possibly fit into a tree.  It
+	// would be possible to cause memory exhaustion and panics without a
+	// sane upper bound on this count.
+	maxTxPerTree := MaxTxPerTxTree(pver)
+	if count > maxTxPerTree {
+		msg := fmt.Sprintf("too many transactions to fit into a block "+
+			"[count %d, max %d]", count, maxTxPerTree)
+		return nil, messageError(op, ErrTooManyTxs, msg)
+	}
+
+	txns := make([]*MsgTx, 0, count)
+	for i := uint64(0); i < count; i++ {
+		var tx MsgTx
+		if err := tx.BtcDecode(r, pver); err != nil {
+			return nil, err
+		}
+		txns = append(txns, &tx)
+	}
+	return txns, nil
+}
+
+// writeBlockTxnTree writes the count prefixed transactions for a single
+// transaction tree of a blocktxn message to w.
+func writeBlockTxnTree(op string, w io.Writer, pver uint32, txns []*MsgTx) error {
+	count := uint64(len(txns))
+	maxTxPerTree := MaxTxPerTxTree(pver)
+	if count > maxTxPerTree {
+		msg := fmt.Sprintf("too many transactions to fit into a block "+
+			"[count %d, max %d]", count, maxTxPerTree)
+		return messageError(op, ErrTooManyTxs, msg)
+	}
+
+	if err := WriteVarInt(w, pver, count); err != nil {
+		return err
+	}
+	for _, tx := range txns {
+		if err := tx.BtcEncode(w, pver); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// BtcDecode decodes r using the protocol encoding into the receiver.
+// This is part of the Message interface implementation.
+func (msg *MsgBlockTxn) BtcDecode(r io.Reader, pver uint32) error {
+	const op = "MsgBlockTxn.BtcDecode"
+	if pver < CompactBlocksVersion {
+		msg := fmt.Sprintf("blocktxn message invalid for protocol "+
+			"version %d", pver)
+		return messageError(op, ErrMsgInvalidForPVer, msg)
+	}
+
+	if err := readElement(r, &msg.BlockHash); err != nil {
+		return err
+	}
+
+	var err error
+	msg.Transactions, err = readBlockTxnTree(op, r, pver)
+	if err != nil {
+		return err
+	}
+	msg.STransactions, err = readBlockTxnTree(op, r, pver)
+	return err
+}
+
+// BtcEncode encodes the receiver to w using the protocol encoding.
+// This is part of the Message interface implementation.
+func (msg *MsgBlockTxn) BtcEncode(w io.Writer, pver uint32) error {
+	const op = "MsgBlockTxn.BtcEncode"
+	if pver < CompactBlocksVersion {
+		msg := fmt.Sprintf("blocktxn message invalid for protocol "+
+			"version %d", pver)
+		return messageError(op, ErrMsgInvalidForPVer, msg)
+	}
+
+	if err := writeElement(w, &msg.BlockHash); err != nil {
+		return err
+	}
+	err := writeBlockTxnTree(op, w, pver, msg.Transactions)
+	if err != nil {
+		return err
+	}
+	return writeBlockTxnTree(op, w, pver, msg.STransactions)
+}
+
+// Command returns the protocol command string for the message.  This is part
+// of the Message interface implementation.
+func (msg *MsgBlockTxn) Command() string {
+	return CmdBlockTxn
+}
+
+// MaxPayloadLength returns the maximum length the payload can be for the
+// receiver.  This is part of the Message interface implementation.
+func (msg *MsgBlockTxn) MaxPayloadLength(pver uint32) uint32 {
+	if pver < CompactBlocksVersion {
+		return 0
+	}
+
+	// The transactions can never exceed the size of the block they are
+	// from, so the block hash plus the max block payload is sufficient.
+	return chainhash.HashSize + MaxBlockPayload
+}
+
+// NewMsgBlockTxn returns a new blocktxn message that conforms to the Message
+// interface using the provided block hash.  See MsgBlockTxn for details.
+func NewMsgBlockTxn(blockHash *chainhash.Hash) *MsgBlockTxn {
+	return &MsgBlockTxn{
+		BlockHash: *blockHash,
+	}
+}
@@ -0,0 +1,184 @@
+// Copyright (c) 2023 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package wire
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"reflect"
+	"testing"
+
+	"github.com/EXCCoin/exccd/chaincfg/chainhash"
+	"github.com/davecgh/go-spew/spew"
+)
+
+// TestBlockTxn tests the MsgBlockTxn API.
+func TestBlockTxn(t *testing.T) {
+	pver := ProtocolVersion
+
+	// Ensure the command is expected value.
+	wantCmd := "blocktxn"
+	msg := NewMsgBlockTxn(&chainhash.Hash{})
+	if cmd := msg.Command(); cmd != wantCmd {
+		t.Errorf("NewMsgBlockTxn: wrong command - got %v want %v",
+			cmd, wantCmd)
+	}
+
+	// Ensure max payload is expected value for latest protocol version.
+	wantPayload := uint32(32 + MaxBlockPayload)
+	maxPayload := msg.MaxPayloadLength(pver)
+	if maxPayload != wantPayload {
+		t.Errorf("MaxPayloadLength: wrong max payload length for "+
+			"protocol version %d - got %v, want %v", pver,
+			maxPayload, wantPayload)
+	}
+
+	// Ensure max payload length is not more than MaxMessagePayload.
+	if maxPayload > MaxMessagePayload {
+		t.Fatalf("MaxPayloadLength: payload length (%v) for protocol "+
+			"version %d exceeds MaxMessagePayload (%v).", maxPayload, pver,
+			MaxMessagePayload)
+	}
+}
+
+// TestBlockTxnWire tests the MsgBlockTxn wire encode and decode.
+func TestBlockTxnWire(t *testing.T) {
+	pver := ProtocolVersion
+
+	hash, _ := chainhash.NewHashFromStr("4433221144332211443322114" +
+		"433221144332211443322114433221144332211")
+	hashBytes := []byte{
+		0x11, 0x22, 0x33, 0x44, 0x11, 0x22, 0x33, 0x44, // Block hash
+		0x11, 0x22, 0x33, 0x44, 0x11, 0x22, 0x33, 0x44,
+		0x11, 0x22, 0x33, 0x44, 0x11, 0x22, 0x33, 0x44,
+		0x11, 0x22, 0x33, 0x44, 0x11, 0x22, 0x33, 0x44,
+	}
+
+	// MsgBlockTxn message with no transactions.
+	emptyMsg := NewMsgBlockTxn(hash)
+	emptyMsg.Transactions = []*MsgTx{}
+	emptyMsg.STransactions = []*MsgTx{}
+	emptyMsgEncoded := append([]byte{}, hashBytes...)
+	emptyMsgEncoded = append(emptyMsgEncoded,
+		0x00, // Varint for number of transactions
+		0x00, // Varint for number of stake transactions
+	)
+
+	// MsgBlockTxn message with transactions from both trees.
+	multiMsg := NewMsgBlockTxn(hash)
+	multiMsg.Transactions = []*MsgTx{multiTx, multiTx}
+	multiMsg.STransactions = []*MsgTx{multiTx}
+	multiMsgEncoded := append([]byte{}, hashBytes...)
+	multiMsgEncoded = append(multiMsgEncoded, 0x02)
+	multiMsgEncoded = append(multiMsgEncoded, multiTxEncoded...)
+	multiMsgEncoded = append(multiMsgEncoded, multiTxEncoded...)
+	multiMsgEncoded = append(multiMsgEncoded, 0x01)
+	multiMsgEncoded = append(multiMsgEncoded, multiTxEncoded...)
+
+	tests := []struct {
+		in  *MsgBlockTxn // Message to encode
+		buf []byte       // Wire encoding
+	}{
+		{emptyMsg, emptyMsgEncoded},
+		{multiMsg, multiMsgEncoded},
+	}
+
+	for i, test := range tests {
+		// Encode the message to wire format.
+		var buf bytes.Buffer
+		err := test.in.BtcEncode(&buf, pver)
+		if err != nil {
+			t.Errorf("BtcEncode #%d error %v", i, err)
+			continue
+		}
+		if !bytes.Equal(buf.Bytes(), test.buf) {
+			t.Errorf("BtcEncode #%d - got %s, want: %s", i,
+				spew.Sdump(buf.Bytes()), spew.Sdump(test.buf))
+			continue
+		}
+
+		// Decode the message from wire format.
+		var msg MsgBlockTxn
+		err = msg.BtcDecode(bytes.NewReader(test.buf), pver)
+		if err != nil {
+			t.Errorf("BtcDecode #%d error %v", i, err)
+			continue
+		}
+		if !reflect.DeepEqual(&msg, test.in) {
+			t.Errorf("BtcDecode #%d - got: %s want: %s", i,
+				spew.Sdump(&msg), spew.Sdump(test.in))
+			continue
+		}
+	}
+}
+
+// TestBlockTxnWireErrors performs negative tests against wire encode and
+// decode of MsgBlockTxn to confirm error paths work correctly.
+func TestBlockTxnWireErrors(t *testing.T) {
+	pver := ProtocolVersion
+
+	baseMsg := NewMsgBlockTxn(&chainhash.Hash{})
+	baseMsg.Transactions = []*MsgTx{multiTx}
+	baseMsg.STransactions = []*MsgTx{multiTx}
+	var baseMsgEncoded bytes.Buffer
+	if err := baseMsg.BtcEncode(&baseMsgEncoded, pver); err != nil {
+		t.Fatalf("unexpected error encoding base message: %v", err)
+	}
+	txLen := len(multiTxEncoded)
+
+	// Message that forces an error by having more than the max allowed
+	// number of transactions.
+	maxTxnsMsg := NewMsgBlockTxn(&chainhash.Hash{})
+	maxTxnsMsg.Transactions = make([]*MsgTx, MaxTxPerTxTree(pver)+1)
+	maxTxnsEncoded := make([]byte, 32+3)
+	copy(maxTxnsEncoded[32:], []byte{0xfd, 0xac, 0xaa})
+
+	tests := []struct {
+		in       *MsgBlockTxn // Value to encode
+		buf      []byte       // Wire encoding
+		pver     uint32       // Protocol version for wire encoding
+		max      int          // Max size of fixed buffer to induce errors
+		writeErr error        // Expected write error
+		readErr  error        // Expected read error
+	}{
+		// Force error in block hash.
+		{baseMsg, baseMsgEncoded.Bytes(), pver, 0, io.ErrShortWrite, io.EOF},
+		// Force error in number of transactions varint.
+		{baseMsg, baseMsgEncoded.Bytes(), pver, 32, io.ErrShortWrite, io.EOF},
+		// Force error in first transaction.
+		{baseMsg, baseMsgEncoded.Bytes(), pver, 33, io.ErrShortWrite, io.EOF},
+		// Force error in number of stake transactions varint.
+		{baseMsg, baseMsgEncoded.Bytes(), pver, 33 + txLen, io.ErrShortWrite, io.EOF},
+		// Force error in first stake transaction.
+		{baseMsg, baseMsgEncoded.Bytes(), pver, 34 + txLen, io.ErrShortWrite, io.EOF},
+		// Force error with greater than allowed number of transactions.
+		{maxTxnsMsg, maxTxnsEncoded, pver, len(maxTxnsEncoded), ErrTooManyTxs, ErrTooManyTxs},
+		// Force error due to unsupported protocol version.
+		{baseMsg, baseMsgEncoded.Bytes(), CompactBlocksVersion - 1, baseMsgEncoded.Len(), ErrMsgInvalidForPVer, ErrMsgInvalidForPVer},
+	}
+
+	t.Logf("Running %d tests", len(tests))
+	for i, test := range tests {
+		// Encode to wire format.
+		w := newFixedWriter(test.max)
+		err := test.in.BtcEncode(w, test.pver)
+		if !errors.Is(err, test.writeErr) {
+			t.Errorf("BtcEncode #%d wrong error - got: %v, want: %v", i, err,
+				test.writeErr)
+			continue
+		}
+
+		// Decode from wire format.
+		var msg MsgBlockTxn
+		r := newFixedReader(test.max, test.buf)
+		err = msg.BtcDecode(r, test.pver)
+		if !errors.Is(err, test.readErr) {
+			t.Errorf("BtcDecode #%d wrong error - got: %v, want: %v", i, err,
+				test.readErr)
+			continue
+		}
+	}
+}
@@ -0,0 +1,285 @@
+// Copyright (c) 2023 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package wire
+
+import (
+	"fmt"
+	"io"
+)
+
+const (
+	// CmpctBlockShortIDSize is the number of bytes used to encode each short
+	// transaction ID in a cmpctblock message.
+	CmpctBlockShortIDSize = 6
+
+	// maxCmpctBlockShortID is the maximum value a short transaction ID can
+	// have given it is encoded with CmpctBlockShortIDSize bytes.
+	maxCmpctBlockShortID = 1<<(CmpctBlockShortIDSize*8) - 1
+)
+
+// PrefilledTx houses a transaction that is sent in full as part of a
+// cmpctblock message along with its index in the associated transaction tree
+// of the block.
+type PrefilledTx struct {
+	Index uint32
+	Tx    *MsgTx
+}
+
+// MsgCmpctBlock implements the Message interface and represents a cmpctblock
+// message.  It is used to relay a block by only sending its header along with
+// short transaction IDs for the transactions in each of its transaction trees
+// that the receiving peer is expected to already have, such as those in its
+// mempool, and the full transactions it is not expected to have, such as the
+// coinbase.
+//
+// The short transaction IDs are keyed by the header and the Nonce field so
+// they are specific to each message.  The index of each prefilled transaction
+// is its position in the associated transaction tree of the full block and the
+// short transaction IDs fill the remaining positions in order.
+//
+// This message was not added until protocol versions starting with
+// CompactBlocksVersion.
+type MsgCmpctBlock struct {
+	Header         BlockHeader
+	Nonce          uint64
+	ShortIDs       []uint64
+	PrefilledTxns  []PrefilledTx
+	STxShortIDs    []uint64
+	PrefilledSTxns []PrefilledTx
+}
+
+// NumTransactions returns the total number of transactions in the regular
+// transaction tree of the block the message describes.
+func (msg *MsgCmpctBlock) NumTransactions() int {
+	return len(msg.ShortIDs) + len(msg.PrefilledTxns)
+}
+
+// NumSTransactions returns the total number of transactions in the stake
+// transaction tree of the block the message describes.
+func (msg *MsgCmpctBlock) NumSTransactions() int {
+	return len(msg.STxShortIDs) + len(msg.PrefilledSTxns)
+}
+
+// readTxIndex reads a transaction index from r that is differentially encoded
+// with respect to the previous index, which is tracked via next, and updates
+// next accordingly.  Each index is encoded as the difference from the previous
+// index plus one which means the resulting indexes are strictly increasing.
+// An error is returned when the index is not less than the provided maximum
+// number of transactions.
+func readTxIndex(op string, r io.Reader, pver uint32, maxTxns uint64, next *uint64) (uint32, error) {
+	diff, err := ReadVarInt(r, pver)
+	if err != nil {
+		return 0, err
+	}
+	if diff >= maxTxns || *next+diff >= maxTxns {
+		msg := fmt.Sprintf("transaction index exceeds the maximum "+
+			"number of transactions in a tree [max %d]", maxTxns)
+		return 0, messageError(op, ErrInvalidMsg, msg)
+	}
+	index := *next + diff
+	*next = index + 1
+	return uint32(index), nil
+}
+
+// writeTxIndex writes the provided transaction index to w differentially
+// encoded with respect to the previous index, which is tracked via next, and
+// updates next accordingly.  The indexes must be strictly increasing.
+func writeTxIndex(op string, w io.Writer, pver uint32, index uint32, next *uint64) error {
+	if uint64(index) < *next {
+		msg := fmt.Sprintf("transaction index %d is not strictly "+
+			"increasing", index)
+		return messageError(op, ErrInvalidMsg, msg)
+	}
+	if err := WriteVarInt(w, pver, uint64(index)-*next); err != nil {
+		return err
+	}
+	*next = uint64(index) + 1
+	return nil
+}
+
+// readCmpctTxTree reads the short transaction IDs and prefilled transactions
+// for a single transaction tree of a cmpctblock message from r.
+func readCmpctTxTree(op string, r io.Reader, pver uint32) ([]uint64, []PrefilledTx, error) {
+	maxTxPerTree := MaxTxPerTxTree(pver)
+	numShortIDs, err := ReadVarInt(r, pver)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Prevent more short IDs than there could possibly be transactions in a
+	// tree.  It would be possible to cause memory exhaustion and panics
+	// without a sane upper bound on this count.
+	if numShortIDs > maxTxPerTree {
+		msg := fmt.Sprintf("too many short transaction ids to fit into a "+
+			"block [count %d, max %d]", numShortIDs, maxTxPerTree)
+		return nil, nil, messageError(op, ErrTooManyTxs, msg)
+	}
+
+	shortIDs := make([]uint64, 0, numShortIDs)
+	var buf [8]byte
+	for i := uint64(0); i < numShortIDs; i++ {
+		if _, err := io.ReadFull(r, buf[:CmpctBlockShortIDSize]); err != nil {
+			return nil, nil, err
+		}
+		shortIDs = append(shortIDs, littleEndian.Uint64(buf[:]))
+	}
+
+	numPrefilled, err := ReadVarInt(r, pver)
+	if err != nil {
+		return nil, nil, err
+	}
+	numTxns := numShortIDs + numPrefilled
+	if numTxns > maxTxPerTree {
+		msg := fmt.Sprintf("too many transactions to fit into a block "+
+			"[count %d, max %d]", numTxns, maxTxPerTree)
+		return nil, nil, messageError(op, ErrTooManyTxs, msg)
+	}
+
+	prefilled := make([]PrefilledTx, 0, numPrefilled)
+	var next uint64
+	for i := uint64(0); i < numPrefilled; i++ {
+		index, err := readTxIndex(op, r, pver, numTxns, &next)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		var tx MsgTx
+		if err := tx.BtcDecode(r, pver); err != nil {
+			return nil, nil, err
+		}
+		prefilled = append(prefilled, PrefilledTx{
+			Index: index,
+			Tx:    &tx,
+		})
+	}
+
+	return shortIDs, prefilled, nil
+}
+
+// writeCmpctTxTree writes the short transaction IDs and prefilled transactions
+// for a single transaction tree of a cmpctblock message to w.
+func writeCmpctTxTree(op string, w io.Writer, pver uint32, shortIDs []uint64, prefilled []PrefilledTx) error {
+	maxTxPerTree := MaxTxPerTxTree(pver)
+	numTxns := uint64(len(shortIDs) + len(prefilled))
+	if numTxns > maxTxPerTree {
+		msg := fmt.Sprintf("too many transactions to fit into a block "+
+			"[count %d, max %d]", numTxns, maxTxPerTree)
+		return messageError(op, ErrTooManyTxs, msg)
+	}
+
+	if err := WriteVarInt(w, pver, uint64(len(shortIDs))); err != nil {
+		return err
+	}
+	var buf [8]byte
+	for _, shortID := range shortIDs {
+		if shortID > maxCmpctBlockShortID {
+			msg := fmt.Sprintf("short transaction id %x exceeds %d bytes",
+				shortID, CmpctBlockShortIDSize)
+			return messageError(op, ErrInvalidMsg, msg)
+		}
+		littleEndian.PutUint64(buf[:], shortID)
+		if _, err := w.Write(buf[:CmpctBlockShortIDSize]); err != nil {
+			return err
+		}
+	}
+
+	if err := WriteVarInt(w, pver, uint64(len(prefilled))); err != nil {
+		return err
+	}
+	var next uint64
+	for _, ptx := range prefilled {
+		if err := writeTxIndex(op, w, pver, ptx.Index, &next); err != nil {
+			return err
+		}
+		if err := ptx.Tx.BtcEncode(w, pver); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// BtcDecode decodes r using the protocol encoding into the receiver.
+// This is part of the Message interface implementation.
+func (msg *MsgCmpctBlock) BtcDecode(r io.Reader, pver uint32) error {
+	const op = "MsgCmpctBlock.BtcDecode"
+	if pver < CompactBlocksVersion {
+		msg := fmt.Sprintf("cmpctblock message invalid for protocol "+
+			"version %d", pver)
+		return messageError(op, ErrMsgInvalidForPVer, msg)
+	}
+
+	if err := readBlockHeader(r, pver, &msg.Header); err != nil {
+		return err
+	}
+	if err := readElement(r, &msg.Nonce); err != nil {
+		return err
+	}
+
+	var err error
+	msg.ShortIDs, msg.PrefilledTxns, err = readCmpctTxTree(op, r, pver)
+	if err != nil {
+		return err
+	}
+	msg.STxShortIDs, msg.PrefilledSTxns, err = readCmpctTxTree(op, r, pver)
+	return err
+}
+
+// BtcEncode encodes the receiver to w using the protocol encoding.
+// This is part of the Message interface implementation.
+func (msg *MsgCmpctBlock) BtcEncode(w io.Writer, pver uint32) error {
+	const op = "MsgCmpctBlock.BtcEncode"
+	if pver < CompactBlocksVersion {
+		msg := fmt.Sprintf("cmpctblock message invalid for protocol "+
+			"version %d", pver)
+		return messageError(op, ErrMsgInvalidForPVer, msg)
+	}
+
+	if err := writeBlockHeader(w, pver, &msg.Header); err != nil {
+		return err
+	}
+	if err := writeElement(w, msg.Nonce); err != nil {
+		return err
+	}
+
+	err := writeCmpctTxTree(op, w, pver, msg.ShortIDs, msg.PrefilledTxns)
+	if err != nil {
+		return err
+	}
+	return writeCmpctTxTree(op, w, pver, msg.STxShortIDs, msg.PrefilledSTxns)
+}
+
+// Command returns the protocol command string for the message.  This is part
+// of the Message interface implementation.
+func (msg *MsgCmpctBlock) Command() string {
+	return CmdCmpctBlock
+}
+
+// MaxPayloadLength returns the maximum length the payload can be for the
+// receiver.  This is part of the Message interface implementation.
+func (msg *MsgCmpctBlock) MaxPayloadLength(pver uint32) uint32 {
+	if pver < CompactBlocksVersion {
+		return 0
+	}
+
+	// A compact block is never larger than the full block it describes
+	// aside from the nonce and the prefilled transaction indexes since
+	// every short transaction ID is smaller than the smallest possible
+	// transaction.  Account for a max size varint index for every possible
+	// transaction in both trees along with the additional counts.
+	maxTxPerTree := MaxTxPerTxTree(pver)
+	varIntSize := uint64(VarIntSerializeSize(maxTxPerTree))
+	return uint32(MaxBlockPayload + 8 + 2*(varIntSize+maxTxPerTree*varIntSize))
+}
+
+// NewMsgCmpctBlock returns a new cmpctblock message that conforms to the
+// Message interface using the provided header and nonce.  See MsgCmpctBlock
+// for details.
+func NewMsgCmpctBlock(header *BlockHeader, nonce uint64) *MsgCmpctBlock {
+	return &MsgCmpctBlock{
+		Header: *header,
+		Nonce:  nonce,
+	}
+}
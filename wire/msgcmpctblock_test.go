@@ -0,0 +1,222 @@
+// Copyright (c) 2023 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package wire
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"reflect"
+	"testing"
+
+	"github.com/davecgh/go-spew/spew"
+)
+
+// TestCmpctBlock tests the MsgCmpctBlock API.
+func TestCmpctBlock(t *testing.T) {
+	pver := ProtocolVersion
+
+	// Ensure the command is expected value.
+	wantCmd := "cmpctblock"
+	msg := NewMsgCmpctBlock(&testBlock.Header, 0)
+	if cmd := msg.Command(); cmd != wantCmd {
+		t.Errorf("NewMsgCmpctBlock: wrong command - got %v want %v",
+			cmd, wantCmd)
+	}
+
+	// Ensure max payload is expected value for latest protocol version.
+	// Max block payload + nonce + a 3 byte varint count and a max of 43691 3
+	// byte varint indexes for each tree.
+	wantPayload := uint32(MaxBlockPayload + 8 + 2*(3+43691*3))
+	maxPayload := msg.MaxPayloadLength(pver)
+	if maxPayload != wantPayload {
+		t.Errorf("MaxPayloadLength: wrong max payload length for "+
+			"protocol version %d - got %v, want %v", pver,
+			maxPayload, wantPayload)
+	}
+
+	// Ensure max payload length is not more than MaxMessagePayload.
+	if maxPayload > MaxMessagePayload {
+		t.Fatalf("MaxPayloadLength: payload length (%v) for protocol "+
+			"version %d exceeds MaxMessagePayload (%v).", maxPayload, pver,
+			MaxMessagePayload)
+	}
+
+	// Ensure the number of transactions in each tree accounts for both the
+	// short ids and the prefilled transactions.
+	msg.ShortIDs = []uint64{1, 2, 3}
+	msg.PrefilledTxns = []PrefilledTx{{Index: 0, Tx: multiTx}}
+	msg.PrefilledSTxns = []PrefilledTx{{Index: 0, Tx: multiTx}}
+	if got := msg.NumTransactions(); got != 4 {
+		t.Errorf("NumTransactions: wrong count - got %d, want 4", got)
+	}
+	if got := msg.NumSTransactions(); got != 1 {
+		t.Errorf("NumSTransactions: wrong count - got %d, want 1", got)
+	}
+}
+
+// TestCmpctBlockWire tests the MsgCmpctBlock wire encode and decode.
+func TestCmpctBlockWire(t *testing.T) {
+	pver := ProtocolVersion
+
+	headerBytes, err := testBlock.Header.Bytes()
+	if err != nil {
+		t.Fatalf("unexpected error serializing header: %v", err)
+	}
+
+	// MsgCmpctBlock message with short ids and prefilled transactions in
+	// both trees.
+	msg := NewMsgCmpctBlock(&testBlock.Header, 0x0807060504030201)
+	msg.ShortIDs = []uint64{0x060504030201, 0xffffffffffff}
+	msg.PrefilledTxns = []PrefilledTx{{Index: 0, Tx: multiTx},
+		{Index: 3, Tx: multiTx}}
+	msg.STxShortIDs = []uint64{0x0a}
+	msg.PrefilledSTxns = []PrefilledTx{{Index: 1, Tx: multiTx}}
+	var encoded []byte
+	encoded = append(encoded, headerBytes...)
+	encoded = append(encoded, 0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07,
+		0x08) // Nonce
+	encoded = append(encoded, 0x02) // Varint for number of short ids
+	encoded = append(encoded, 0x01, 0x02, 0x03, 0x04, 0x05, 0x06,
+		0xff, 0xff, 0xff, 0xff, 0xff, 0xff)
+	encoded = append(encoded, 0x02) // Varint for number of prefilled txns
+	encoded = append(encoded, 0x00) // Index 0
+	encoded = append(encoded, multiTxEncoded...)
+	encoded = append(encoded, 0x02) // Index 3 (differential)
+	encoded = append(encoded, multiTxEncoded...)
+	encoded = append(encoded, 0x01) // Varint for number of stake short ids
+	encoded = append(encoded, 0x0a, 0x00, 0x00, 0x00, 0x00, 0x00)
+	encoded = append(encoded, 0x01) // Varint for number of prefilled stxns
+	encoded = append(encoded, 0x01) // Index 1
+	encoded = append(encoded, multiTxEncoded...)
+
+	// Encode the message to wire format.
+	var buf bytes.Buffer
+	if err := msg.BtcEncode(&buf, pver); err != nil {
+		t.Fatalf("BtcEncode error %v", err)
+	}
+	if !bytes.Equal(buf.Bytes(), encoded) {
+		t.Fatalf("BtcEncode - got %s, want: %s", spew.Sdump(buf.Bytes()),
+			spew.Sdump(encoded))
+	}
+
+	// Decode the message from wire format.
+	var readMsg MsgCmpctBlock
+	if err := readMsg.BtcDecode(bytes.NewReader(encoded), pver); err != nil {
+		t.Fatalf("BtcDecode error %v", err)
+	}
+	if !reflect.DeepEqual(&readMsg, msg) {
+		t.Fatalf("BtcDecode - got: %s want: %s", spew.Sdump(&readMsg),
+			spew.Sdump(msg))
+	}
+}
+
+// TestCmpctBlockWireErrors performs negative tests against wire encode and
+// decode of MsgCmpctBlock to confirm error paths work correctly.
+func TestCmpctBlockWireErrors(t *testing.T) {
+	pver := ProtocolVersion
+
+	headerBytes, err := testBlock.Header.Bytes()
+	if err != nil {
+		t.Fatalf("unexpected error serializing header: %v", err)
+	}
+	hdrLen := len(headerBytes)
+
+	baseMsg := NewMsgCmpctBlock(&testBlock.Header, 1)
+	baseMsg.ShortIDs = []uint64{1}
+	baseMsg.PrefilledTxns = []PrefilledTx{{Index: 0, Tx: multiTx}}
+	var baseMsgEncoded bytes.Buffer
+	if err := baseMsg.BtcEncode(&baseMsgEncoded, pver); err != nil {
+		t.Fatalf("unexpected error encoding base message: %v", err)
+	}
+
+	// Message that forces an error by having a short id that is larger than
+	// the number of bytes used to encode them.
+	largeShortIDMsg := NewMsgCmpctBlock(&testBlock.Header, 1)
+	largeShortIDMsg.ShortIDs = []uint64{1 << 48}
+
+	// Message that forces an error by having prefilled transaction indexes
+	// that are not strictly increasing.
+	unorderedMsg := NewMsgCmpctBlock(&testBlock.Header, 1)
+	unorderedMsg.PrefilledTxns = []PrefilledTx{{Index: 1, Tx: multiTx},
+		{Index: 0, Tx: multiTx}}
+
+	// Message that forces an error by having more than the max allowed
+	// number of transactions in a tree.
+	maxTxnsMsg := NewMsgCmpctBlock(&testBlock.Header, 1)
+	maxTxnsMsg.ShortIDs = make([]uint64, MaxTxPerTxTree(pver))
+	maxTxnsMsg.PrefilledTxns = []PrefilledTx{{Index: 0, Tx: multiTx}}
+	maxTxnsEncoded := append([]byte{}, headerBytes...)
+	maxTxnsEncoded = append(maxTxnsEncoded, make([]byte, 8)...)
+	maxTxnsEncoded = append(maxTxnsEncoded, 0xfd, 0xac, 0xaa)
+
+	// Encoding that forces an error by having a prefilled transaction with
+	// an index beyond the number of transactions in the tree.
+	largeIndexEncoded := append([]byte{}, headerBytes...)
+	largeIndexEncoded = append(largeIndexEncoded, make([]byte, 8)...)
+	largeIndexEncoded = append(largeIndexEncoded,
+		0x00, // Varint for number of short ids
+		0x01, // Varint for number of prefilled txns
+		0x01, // Index 1
+	)
+
+	tests := []struct {
+		in       *MsgCmpctBlock // Value to encode
+		buf      []byte         // Wire encoding
+		pver     uint32         // Protocol version for wire encoding
+		max      int            // Max size of fixed buffer to induce errors
+		writeErr error          // Expected write error
+		readErr  error          // Expected read error
+	}{
+		// Force error in header.
+		{baseMsg, baseMsgEncoded.Bytes(), pver, 0, io.ErrShortWrite, io.EOF},
+		// Force error in nonce.
+		{baseMsg, baseMsgEncoded.Bytes(), pver, hdrLen, io.ErrShortWrite, io.EOF},
+		// Force error in number of short ids varint.
+		{baseMsg, baseMsgEncoded.Bytes(), pver, hdrLen + 8, io.ErrShortWrite, io.EOF},
+		// Force error in first short id.
+		{baseMsg, baseMsgEncoded.Bytes(), pver, hdrLen + 9, io.ErrShortWrite, io.EOF},
+		// Force error in number of prefilled txns varint.
+		{baseMsg, baseMsgEncoded.Bytes(), pver, hdrLen + 15, io.ErrShortWrite, io.EOF},
+		// Force error in first prefilled txn index.
+		{baseMsg, baseMsgEncoded.Bytes(), pver, hdrLen + 16, io.ErrShortWrite, io.EOF},
+		// Force error in first prefilled txn.
+		{baseMsg, baseMsgEncoded.Bytes(), pver, hdrLen + 17, io.ErrShortWrite, io.EOF},
+		// Force error in number of stake short ids varint.
+		{baseMsg, baseMsgEncoded.Bytes(), pver, hdrLen + 17 + len(multiTxEncoded), io.ErrShortWrite, io.EOF},
+		// Force error with a short id that is too large.
+		{largeShortIDMsg, baseMsgEncoded.Bytes(), pver, hdrLen + 9, ErrInvalidMsg, io.EOF},
+		// Force error with prefilled indexes that are not increasing.
+		{unorderedMsg, baseMsgEncoded.Bytes(), pver, baseMsgEncoded.Len(), ErrInvalidMsg, nil},
+		// Force error with greater than allowed number of transactions.
+		{maxTxnsMsg, maxTxnsEncoded, pver, len(maxTxnsEncoded), ErrTooManyTxs, ErrTooManyTxs},
+		// Force error with a prefilled index that is too large.
+		{baseMsg, largeIndexEncoded, pver, len(largeIndexEncoded), io.ErrShortWrite, ErrInvalidMsg},
+		// Force error due to unsupported protocol version.
+		{baseMsg, baseMsgEncoded.Bytes(), CompactBlocksVersion - 1, baseMsgEncoded.Len(), ErrMsgInvalidForPVer, ErrMsgInvalidForPVer},
+	}
+
+	t.Logf("Running %d tests", len(tests))
+	for i, test := range tests {
+		// Encode to wire format.
+		w := newFixedWriter(test.max)
+		err := test.in.BtcEncode(w, test.pver)
+		if !errors.Is(err, test.writeErr) {
+			t.Errorf("BtcEncode #%d wrong error - got: %v, want: %v", i, err,
+				test.writeErr)
+			continue
+		}
+
+		// Decode from wire format.
+		var msg MsgCmpctBlock
+		r := newFixedReader(test.max, test.buf)
+		err = msg.BtcDecode(r, test.pver)
+		if !errors.Is(err, test.readErr) {
+			t.Errorf("BtcDecode #%d wrong error - got: %v, want: %v", i, err,
+				test.readErr)
+			continue
+		}
+	}
+}
@@ -0,0 +1,152 @@
+// Copyright (c) 2023 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package wire
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/EXCCoin/exccd/chaincfg/chainhash"
+)
+
+// MsgGetBlockTxn implements the Message interface and represents a getblocktxn
+// message.  It is used to request the transactions at the specified indexes
+// of the regular and stake transaction trees of a block that could not be
+// reconstructed from a cmpctblock message.  The expected response is a
+// blocktxn message.
+//
+// The indexes for each tree must be strictly increasing.
+//
+// This message was not added until protocol versions starting with
+// CompactBlocksVersion.
+type MsgGetBlockTxn struct {
+	BlockHash  chainhash.Hash
+	Indexes    []uint32
+	STxIndexes []uint32
+}
+
+// readTxIndexes reads a count prefixed list of differentially encoded
+// transaction indexes for a single transaction tree from r.
+func readTxIndexes(op string, r io.Reader, pver uint32) ([]uint32, error) {
+	maxTxPerTree := MaxTxPerTxTree(pver)
+	count, err := ReadVarInt(r, pver)
+	if err != nil {
+		return nil, err
+	}
+
+	// Prevent more indexes than there could possibly be transactions in a
+	// tree.  It would be possible to cause memory exhaustion and panics
+	// without a sane upper bound on this count.
+	if count > maxTxPerTree {
+		msg := fmt.Sprintf("too many transaction indexes for message "+
+			"[count %d, max %d]", count, maxTxPerTree)
+		return nil, messageError(op, ErrTooManyTxs, msg)
+	}
+
+	indexes := make([]uint32, 0, count)
+	var next uint64
+	for i := uint64(0); i < count; i++ {
+		index, err := readTxIndex(op, r, pver, maxTxPerTree, &next)
+		if err != nil {
+			return nil, err
+		}
+		indexes = append(indexes, index)
+	}
+	return indexes, nil
+}
+
+// writeTxIndexes writes a count prefixed list of differentially encoded
+// transaction indexes for a single transaction tree to w.
+func writeTxIndexes(op string, w io.Writer, pver uint32, indexes []uint32) error {
+	maxTxPerTree := MaxTxPerTxTree(pver)
+	count := uint64(len(indexes))
+	if count > maxTxPerTree {
+		msg := fmt.Sprintf("too many transaction indexes for message "+
+			"[count %d, max %d]", count, maxTxPerTree)
+		return messageError(op, ErrTooManyTxs, msg)
+	}
+
+	if err := WriteVarInt(w, pver, count); err != nil {
+		return err
+	}
+	var next uint64
+	for _, index := range indexes {
+		if err := writeTxIndex(op, w, pver, index, &next); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// BtcDecode decodes r using the protocol encoding into the receiver.
+// This is part of the Message interface implementation.
+func (msg *MsgGetBlockTxn) BtcDecode(r io.Reader, pver uint32) error {
+	const op = "MsgGetBlockTxn.BtcDecode"
+	if pver < CompactBlocksVersion {
+		msg := fmt.Sprintf("getblocktxn message invalid for protocol "+
+			"version %d", pver)
+		return messageError(op, ErrMsgInvalidForPVer, msg)
+	}
+
+	if err := readElement(r, &msg.BlockHash); err != nil {
+		return err
+	}
+
+	var err error
+	msg.Indexes, err = readTxIndexes(op, r, pver)
+	if err != nil {
+		return err
+	}
+	msg.STxIndexes, err = readTxIndexes(op, r, pver)
+	return err
+}
+
+// BtcEncode encodes the receiver to w using the protocol encoding.
+// This is part of the Message interface implementation.
+func (msg *MsgGetBlockTxn) BtcEncode(w io.Writer, pver uint32) error {
+	const op = "MsgGetBlockTxn.BtcEncode"
+	if pver < CompactBlocksVersion {
+		msg := fmt.Sprintf("getblocktxn message invalid for protocol "+
+			"version %d", pver)
+		return messageError(op, ErrMsgInvalidForPVer, msg)
+	}
+
+	if err := writeElement(w, &msg.BlockHash); err != nil {
+		return err
+	}
+	if err := writeTxIndexes(op, w, pver, msg.Indexes); err != nil {
+		return err
+	}
+	return writeTxIndexes(op, w, pver, msg.STxIndexes)
+}
+
+// Command returns the protocol command string for the message.  This is part
+// of the Message interface implementation.
+func (msg *MsgGetBlockTxn) Command() string {
+	return CmdGetBlockTxn
+}
+
+// MaxPayloadLength returns the maximum length the payload can be for the
+// receiver.  This is part of the Message interface implementation.
+func (msg *MsgGetBlockTxn) MaxPayloadLength(pver uint32) uint32 {
+	if pver < CompactBlocksVersion {
+		return 0
+	}
+
+	// Block hash + a count and a max size varint index for every possible
+	// transaction in both trees.
+	maxTxPerTree := MaxTxPerTxTree(pver)
+	varIntSize := uint64(VarIntSerializeSize(maxTxPerTree))
+	return uint32(chainhash.HashSize + 2*(varIntSize+maxTxPerTree*varIntSize))
+}
+
+// NewMsgGetBlockTxn returns a new getblocktxn message that conforms to the
+// Message interface using the provided block hash.  See MsgGetBlockTxn for
+// details.
+func NewMsgGetBlockTxn(blockHash *chainhash.Hash) *MsgGetBlockTxn {
+	return &MsgGetBlockTxn{
+		BlockHash: *blockHash,
+	}
+}
@@ -0,0 +1,201 @@
+// Copyright (c) 2023 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package wire
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"reflect"
+	"testing"
+
+	"github.com/EXCCoin/exccd/chaincfg/chainhash"
+	"github.com/davecgh/go-spew/spew"
+)
+
+// TestGetBlockTxn tests the MsgGetBlockTxn API.
+func TestGetBlockTxn(t *testing.T) {
+	pver := ProtocolVersion
+
+	// Ensure the command is expected value.
+	wantCmd := "getblocktxn"
+	msg := NewMsgGetBlockTxn(&chainhash.Hash{})
+	if cmd := msg.Command(); cmd != wantCmd {
+		t.Errorf("NewMsgGetBlockTxn: wrong command - got %v want %v",
+			cmd, wantCmd)
+	}
+
+	// Ensure max payload is expected value for latest protocol version.
+	// Block hash + a 3 byte varint count and a max of 43691 3 byte varint
+	// indexes for each tree.
+	wantPayload := uint32(32 + 2*(3+43691*3))
+	maxPayload := msg.MaxPayloadLength(pver)
+	if maxPayload != wantPayload {
+		t.Errorf("MaxPayloadLength: wrong max payload length for "+
+			"protocol version %d - got %v, want %v", pver,
+			maxPayload, wantPayload)
+	}
+
+	// Ensure max payload length is not more than MaxMessagePayload.
+	if maxPayload > MaxMessagePayload {
+		t.Fatalf("MaxPayloadLength: payload length (%v) for protocol "+
+			"version %d exceeds MaxMessagePayload (%v).", maxPayload, pver,
+			MaxMessagePayload)
+	}
+}
+
+// TestGetBlockTxnWire tests the MsgGetBlockTxn wire encode and decode.
+func TestGetBlockTxnWire(t *testing.T) {
+	pver := ProtocolVersion
+
+	hash, _ := chainhash.NewHashFromStr("4433221144332211443322114" +
+		"433221144332211443322114433221144332211")
+
+	// MsgGetBlockTxn message with no requested transactions.
+	emptyMsg := NewMsgGetBlockTxn(hash)
+	emptyMsg.Indexes = []uint32{}
+	emptyMsg.STxIndexes = []uint32{}
+	emptyMsgEncoded := []byte{
+		0x11, 0x22, 0x33, 0x44, 0x11, 0x22, 0x33, 0x44, // Block hash
+		0x11, 0x22, 0x33, 0x44, 0x11, 0x22, 0x33, 0x44,
+		0x11, 0x22, 0x33, 0x44, 0x11, 0x22, 0x33, 0x44,
+		0x11, 0x22, 0x33, 0x44, 0x11, 0x22, 0x33, 0x44,
+		0x00, // Varint for number of regular tree indexes
+		0x00, // Varint for number of stake tree indexes
+	}
+
+	// MsgGetBlockTxn message with requested transactions from both trees.
+	multiMsg := NewMsgGetBlockTxn(hash)
+	multiMsg.Indexes = []uint32{0, 2, 300}
+	multiMsg.STxIndexes = []uint32{1}
+	multiMsgEncoded := []byte{
+		0x11, 0x22, 0x33, 0x44, 0x11, 0x22, 0x33, 0x44, // Block hash
+		0x11, 0x22, 0x33, 0x44, 0x11, 0x22, 0x33, 0x44,
+		0x11, 0x22, 0x33, 0x44, 0x11, 0x22, 0x33, 0x44,
+		0x11, 0x22, 0x33, 0x44, 0x11, 0x22, 0x33, 0x44,
+		0x03,             // Varint for number of regular tree indexes
+		0x00,             // Index 0
+		0x01,             // Index 2 (differential)
+		0xfd, 0x29, 0x01, // Index 300 (differential)
+		0x01, // Varint for number of stake tree indexes
+		0x01, // Index 1
+	}
+
+	tests := []struct {
+		in  *MsgGetBlockTxn // Message to encode
+		buf []byte          // Wire encoding
+	}{
+		{emptyMsg, emptyMsgEncoded},
+		{multiMsg, multiMsgEncoded},
+	}
+
+	for i, test := range tests {
+		// Encode the message to wire format.
+		var buf bytes.Buffer
+		err := test.in.BtcEncode(&buf, pver)
+		if err != nil {
+			t.Errorf("BtcEncode #%d error %v", i, err)
+			continue
+		}
+		if !bytes.Equal(buf.Bytes(), test.buf) {
+			t.Errorf("BtcEncode #%d - got %s, want: %s", i,
+				spew.Sdump(buf.Bytes()), spew.Sdump(test.buf))
+			continue
+		}
+
+		// Decode the message from wire format.
+		var msg MsgGetBlockTxn
+		err = msg.BtcDecode(bytes.NewReader(test.buf), pver)
+		if err != nil {
+			t.Errorf("BtcDecode #%d error %v", i, err)
+			continue
+		}
+		if !reflect.DeepEqual(&msg, test.in) {
+			t.Errorf("BtcDecode #%d - got: %s want: %s", i,
+				spew.Sdump(&msg), spew.Sdump(test.in))
+			continue
+		}
+	}
+}
+
+// TestGetBlockTxnWireErrors performs negative tests against wire encode and
+// decode of MsgGetBlockTxn to confirm error paths work correctly.
+func TestGetBlockTxnWireErrors(t *testing.T) {
+	pver := ProtocolVersion
+
+	baseMsg := NewMsgGetBlockTxn(&chainhash.Hash{})
+	baseMsg.Indexes = []uint32{0, 2}
+	baseMsg.STxIndexes = []uint32{1}
+	baseMsgEncoded := make([]byte, 32+6)
+	copy(baseMsgEncoded[32:], []byte{0x02, 0x00, 0x01, 0x01, 0x01})
+
+	// Message that forces an error by having indexes that are not strictly
+	// increasing.  Note that it is not possible to encode such indexes, so
+	// the encoding decodes fine as the differential indexes 2 and 3.
+	unorderedMsg := NewMsgGetBlockTxn(&chainhash.Hash{})
+	unorderedMsg.Indexes = []uint32{2, 2}
+	unorderedMsgEncoded := make([]byte, 32+4)
+	copy(unorderedMsgEncoded[32:], []byte{0x02, 0x02, 0x00, 0x00})
+
+	// Message that forces an error by having more than the max allowed
+	// number of indexes.
+	maxIndexesMsg := NewMsgGetBlockTxn(&chainhash.Hash{})
+	maxIndexesMsg.Indexes = make([]uint32, MaxTxPerTxTree(pver)+1)
+	maxIndexesMsgEncoded := make([]byte, 32+3)
+	copy(maxIndexesMsgEncoded[32:], []byte{0xfd, 0xac, 0xaa})
+
+	// Encoding that forces an error by having an index that exceeds the max
+	// number of transactions in a tree.
+	largeIndexEncoded := make([]byte, 32+4)
+	copy(largeIndexEncoded[32:], []byte{0x01, 0xfd, 0xab, 0xaa})
+
+	tests := []struct {
+		in       *MsgGetBlockTxn // Value to encode
+		buf      []byte          // Wire encoding
+		pver     uint32          // Protocol version for wire encoding
+		max      int             // Max size of fixed buffer to induce errors
+		writeErr error           // Expected write error
+		readErr  error           // Expected read error
+	}{
+		// Force error in block hash.
+		{baseMsg, baseMsgEncoded, pver, 0, io.ErrShortWrite, io.EOF},
+		// Force error in number of regular tree indexes varint.
+		{baseMsg, baseMsgEncoded, pver, 32, io.ErrShortWrite, io.EOF},
+		// Force error in first regular tree index.
+		{baseMsg, baseMsgEncoded, pver, 33, io.ErrShortWrite, io.EOF},
+		// Force error in number of stake tree indexes varint.
+		{baseMsg, baseMsgEncoded, pver, 35, io.ErrShortWrite, io.EOF},
+		// Force error with indexes that are not strictly increasing.
+		{unorderedMsg, unorderedMsgEncoded, pver, 38, ErrInvalidMsg, nil},
+		// Force error with greater than allowed number of indexes.
+		{maxIndexesMsg, maxIndexesMsgEncoded, pver, 35, ErrTooManyTxs, ErrTooManyTxs},
+		// Force error with an index that is too large.
+		{baseMsg, largeIndexEncoded, pver, 38, nil, ErrInvalidMsg},
+		// Force error due to unsupported protocol version.
+		{baseMsg, baseMsgEncoded, CompactBlocksVersion - 1, 38, ErrMsgInvalidForPVer, ErrMsgInvalidForPVer},
+	}
+
+	t.Logf("Running %d tests", len(tests))
+	for i, test := range tests {
+		// Encode to wire format.
+		w := newFixedWriter(test.max)
+		err := test.in.BtcEncode(w, test.pver)
+		if !errors.Is(err, test.writeErr) {
+			t.Errorf("BtcEncode #%d wrong error - got: %v, want: %v", i, err,
+				test.writeErr)
+			continue
+		}
+
+		// Decode from wire format.
+		var msg MsgGetBlockTxn
+		r := newFixedReader(test.max, test.buf)
+		err = msg.BtcDecode(r, test.pver)
+		if !errors.Is(err, test.readErr) {
+			t.Errorf("BtcDecode #%d wrong error - got: %v, want: %v", i, err,
+				test.readErr)
+			continue
+		}
+	}
+}
@@ -0,0 +1,85 @@
+// Copyright (c) 2023 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package wire
+
+import (
+	"fmt"
+	"io"
+)
+
+// CmpctBlockEncodingVersion is the version of the compact block encoding
+// described by the cmpctblock, getblocktxn, and blocktxn messages.
+const CmpctBlockEncodingVersion = 1
+
+// MsgSendCmpct implements the Message interface and represents a sendcmpct
+// message.  It is used to signal support for relaying blocks via compact
+// blocks as well as whether or not the sending peer would like new blocks to
+// be announced by sending a cmpctblock message directly (high-bandwidth mode)
+// as opposed to announcing them via inv or headers messages (low-bandwidth
+// mode).
+//
+// This message was not added until protocol versions starting with
+// CompactBlocksVersion.
+type MsgSendCmpct struct {
+	// AnnounceUsingCmpctBlock requests the receiving peer to announce new
+	// blocks by sending a cmpctblock message without waiting for a request.
+	AnnounceUsingCmpctBlock bool
+
+	// Version is the compact block encoding version the sending peer
+	// supports.
+	Version uint64
+}
+
+// BtcDecode decodes r using the protocol encoding into the receiver.
+// This is part of the Message interface implementation.
+func (msg *MsgSendCmpct) BtcDecode(r io.Reader, pver uint32) error {
+	const op = "MsgSendCmpct.BtcDecode"
+	if pver < CompactBlocksVersion {
+		msg := fmt.Sprintf("sendcmpct message invalid for protocol "+
+			"version %d", pver)
+		return messageError(op, ErrMsgInvalidForPVer, msg)
+	}
+
+	return readElements(r, &msg.AnnounceUsingCmpctBlock, &msg.Version)
+}
+
+// BtcEncode encodes the receiver to w using the protocol encoding.
+// This is part of the Message interface implementation.
+func (msg *MsgSendCmpct) BtcEncode(w io.Writer, pver uint32) error {
+	const op = "MsgSendCmpct.BtcEncode"
+	if pver < CompactBlocksVersion {
+		msg := fmt.Sprintf("sendcmpct message invalid for protocol "+
+			"version %d", pver)
+		return messageError(op, ErrMsgInvalidForPVer, msg)
+	}
+
+	return writeElements(w, msg.AnnounceUsingCmpctBlock, msg.Version)
+}
+
+// Command returns the protocol command string for the message.  This is part
+// of the Message interface implementation.
+func (msg *MsgSendCmpct) Command() string {
+	return CmdSendCmpct
+}
+
+// MaxPayloadLength returns the maximum length the payload can be for the
+// receiver.  This is part of the Message interface implementation.
+func (msg *MsgSendCmpct) MaxPayloadLength(pver uint32) uint32 {
+	if pver < CompactBlocksVersion {
+		return 0
+	}
+
+	// 1 byte announce flag + 8 bytes version.
+	return 9
+}
+
+// NewMsgSendCmpct returns a new sendcmpct message that conforms to the Message
+// interface.  See MsgSendCmpct for details.
+func NewMsgSendCmpct(announce bool, version uint64) *MsgSendCmpct {
+	return &MsgSendCmpct{
+		AnnounceUsingCmpctBlock: announce,
+		Version:                 version,
+	}
+}
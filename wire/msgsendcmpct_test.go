@@ -0,0 +1,117 @@
+// Copyright (c) 2023 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package wire
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"reflect"
+	"testing"
+
+	"github.com/davecgh/go-spew/spew"
+)
+
+// TestSendCmpct tests the MsgSendCmpct API against the latest protocol
+// version.
+func TestSendCmpct(t *testing.T) {
+	pver := ProtocolVersion
+
+	// Ensure the command is expected value.
+	wantCmd := "sendcmpct"
+	msg := NewMsgSendCmpct(true, CmpctBlockEncodingVersion)
+	if cmd := msg.Command(); cmd != wantCmd {
+		t.Errorf("NewMsgSendCmpct: wrong command - got %v want %v",
+			cmd, wantCmd)
+	}
+
+	// Ensure max payload is expected value.
+	wantPayload := uint32(9)
+	maxPayload := msg.MaxPayloadLength(pver)
+	if maxPayload != wantPayload {
+		t.Errorf("MaxPayloadLength: wrong max payload length for "+
+			"protocol version %d - got %v, want %v", pver,
+			maxPayload, wantPayload)
+	}
+
+	// Older protocol versions should fail encode and decode since the
+	// message didn't exist yet.
+	oldPver := CompactBlocksVersion - 1
+	var buf bytes.Buffer
+	err := msg.BtcEncode(&buf, oldPver)
+	if !errors.Is(err, ErrMsgInvalidForPVer) {
+		t.Errorf("BtcEncode: wrong error for old protocol version - got "+
+			"%v, want %v", err, ErrMsgInvalidForPVer)
+	}
+	var readmsg MsgSendCmpct
+	err = readmsg.BtcDecode(bytes.NewReader(make([]byte, 9)), oldPver)
+	if !errors.Is(err, ErrMsgInvalidForPVer) {
+		t.Errorf("BtcDecode: wrong error for old protocol version - got "+
+			"%v, want %v", err, ErrMsgInvalidForPVer)
+	}
+	if maxPayload := msg.MaxPayloadLength(oldPver); maxPayload != 0 {
+		t.Errorf("MaxPayloadLength: wrong max payload length for "+
+			"protocol version %d - got %v, want 0", oldPver, maxPayload)
+	}
+}
+
+// TestSendCmpctWire tests the MsgSendCmpct wire encode and decode for various
+// settings.
+func TestSendCmpctWire(t *testing.T) {
+	pver := ProtocolVersion
+
+	tests := []struct {
+		in  *MsgSendCmpct // Message to encode
+		buf []byte        // Wire encoding
+	}{{
+		in: NewMsgSendCmpct(true, CmpctBlockEncodingVersion),
+		buf: []byte{
+			0x01,                                           // Announce
+			0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, // Version
+		},
+	}, {
+		in: NewMsgSendCmpct(false, 2),
+		buf: []byte{
+			0x00,                                           // Announce
+			0x02, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, // Version
+		},
+	}}
+
+	for i, test := range tests {
+		// Encode the message to wire format.
+		var buf bytes.Buffer
+		err := test.in.BtcEncode(&buf, pver)
+		if err != nil {
+			t.Errorf("BtcEncode #%d error %v", i, err)
+			continue
+		}
+		if !bytes.Equal(buf.Bytes(), test.buf) {
+			t.Errorf("BtcEncode #%d - got %s, want: %s", i,
+				spew.Sdump(buf.Bytes()), spew.Sdump(test.buf))
+			continue
+		}
+
+		// Decode the message from wire format.
+		var msg MsgSendCmpct
+		err = msg.BtcDecode(bytes.NewReader(test.buf), pver)
+		if err != nil {
+			t.Errorf("BtcDecode #%d error %v", i, err)
+			continue
+		}
+		if !reflect.DeepEqual(&msg, test.in) {
+			t.Errorf("BtcDecode #%d - got: %s want: %s", i,
+				spew.Sdump(&msg), spew.Sdump(test.in))
+			continue
+		}
+
+		// Ensure a truncated encoding is rejected.
+		r := newFixedReader(len(test.buf)-1, test.buf)
+		err = msg.BtcDecode(r, pver)
+		if !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+			t.Errorf("BtcDecode #%d wrong error for truncated message - "+
+				"got %v", i, err)
+		}
+	}
+}
@@ -0,0 +1,210 @@
+// Copyright (c) 2023 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package wire
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"time"
+)
+
+// NetAddressType identifies the network a NetAddressV2 belongs to.  The values
+// are the network ids used to encode the address on the wire.
+type NetAddressType uint8
+
+// These constants define the network ids of the address types that are
+// currently supported.  Note that the network id 3 was used for the long
+// deprecated Tor v2 addresses and is intentionally not defined.
+const (
+	// IPv4Address identifies an IPv4 address.  The address is 4 bytes.
+	IPv4Address NetAddressType = 1
+
+	// IPv6Address identifies an IPv6 address.  The address is 16 bytes.
+	IPv6Address NetAddressType = 2
+
+	// TORv3Address identifies a Tor v3 hidden service.  The address is the
+	// 32 byte ed25519 public key of the service.
+	TORv3Address NetAddressType = 4
+
+	// I2PAddress identifies an I2P destination.  The address is the 32 byte
+	// SHA256 hash of the destination.
+	I2PAddress NetAddressType = 5
+
+	// CJDNSAddress identifies a CJDNS address.  The address is a 16 byte
+	// IPv6 address in the fc00::/8 range.
+	CJDNSAddress NetAddressType = 6
+)
+
+// maxNetAddressV2AddrSize is the maximum number of bytes an address of any
+// network, including the networks that are not known, may have in a
+// NetAddressV2.
+const maxNetAddressV2AddrSize = 512
+
+// netAddressTypeStrings is a map of the known network address types back to
+// their constant names for pretty printing.
+var netAddressTypeStrings = map[NetAddressType]string{
+	IPv4Address:  "IPv4Address",
+	IPv6Address:  "IPv6Address",
+	TORv3Address: "TORv3Address",
+	I2PAddress:   "I2PAddress",
+	CJDNSAddress: "CJDNSAddress",
+}
+
+// String returns the NetAddressType in human-readable form.
+func (t NetAddressType) String() string {
+	if s, ok := netAddressTypeStrings[t]; ok {
+		return s
+	}
+	return fmt.Sprintf("Unknown NetAddressType (%d)", uint8(t))
+}
+
+// AddrSize returns the number of bytes an address of the network address type
+// is required to have along with whether or not the type is known.
+func (t NetAddressType) AddrSize() (int, bool) {
+	switch t {
+	case IPv4Address:
+		return 4, true
+	case IPv6Address, CJDNSAddress:
+		return 16, true
+	case TORv3Address, I2PAddress:
+		return 32, true
+	}
+	return 0, false
+}
+
+// maxNetAddressV2Payload returns the max payload size for a NetAddressV2.
+func maxNetAddressV2Payload() uint32 {
+	// Timestamp 8 bytes + services 8 bytes + network id 1 byte + address
+	// length varint + max address size + port 2 bytes.
+	return 8 + 8 + 1 + uint32(VarIntSerializeSize(maxNetAddressV2AddrSize)) +
+		maxNetAddressV2AddrSize + 2
+}
+
+// NetAddressV2 defines information about a peer on the network including the
+// time it was last seen, the services it supports, the network it belongs to,
+// its address on that network, and port.
+//
+// Unlike NetAddress, the address is not limited to an IP address and its
+// length depends on the network.  Addresses for networks that are not known
+// are still decoded so they can be skipped by callers as opposed to causing
+// the entire message to be rejected.
+type NetAddressV2 struct {
+	// Last time the address was seen.  It is encoded as an int64 on the
+	// wire.
+	Timestamp time.Time
+
+	// Bitfield which identifies the services supported by the address.
+	Services ServiceFlag
+
+	// Type is the network the address belongs to.
+	Type NetAddressType
+
+	// Addr is the address of the peer on the network identified by Type.
+	Addr []byte
+
+	// Port the peer is using.  This is encoded in big endian on the wire
+	// for consistency with NetAddress.
+	Port uint16
+}
+
+// HasService returns whether the specified service is supported by the address.
+func (na *NetAddressV2) HasService(service ServiceFlag) bool {
+	return na.Services&service == service
+}
+
+// AddService adds service as a supported service by the peer generating the
+// message.
+func (na *NetAddressV2) AddService(service ServiceFlag) {
+	na.Services |= service
+}
+
+// NewNetAddressV2 returns a new NetAddressV2 using the provided timestamp,
+// supported services, network address type, address, and port.  The timestamp
+// is rounded to single second precision.
+func NewNetAddressV2(timestamp time.Time, services ServiceFlag,
+	netType NetAddressType, addr []byte, port uint16) *NetAddressV2 {
+
+	return &NetAddressV2{
+		Timestamp: time.Unix(timestamp.Unix(), 0),
+		Services:  services,
+		Type:      netType,
+		Addr:      addr,
+		Port:      port,
+	}
+}
+
+// NewNetAddressV2IPPort returns a new NetAddressV2 of type IPv4Address or
+// IPv6Address, depending on the provided IP, using the provided port and
+// supported services with defaults for the remaining fields.
+func NewNetAddressV2IPPort(ip net.IP, port uint16, services ServiceFlag) *NetAddressV2 {
+	if ip4 := ip.To4(); ip4 != nil {
+		return NewNetAddressV2(time.Now(), services, IPv4Address, ip4, port)
+	}
+	return NewNetAddressV2(time.Now(), services, IPv6Address, ip.To16(), port)
+}
+
+// readNetAddressV2 reads an encoded NetAddressV2 from r.  An error is returned
+// when the address of a known network does not have the size required by that
+// network.
+func readNetAddressV2(op string, r io.Reader, pver uint32, na *NetAddressV2) error {
+	var services uint64
+	var netType uint8
+	err := readElements(r, (*int64Time)(&na.Timestamp), &services, &netType)
+	if err != nil {
+		return err
+	}
+
+	addr, err := ReadVarBytes(r, pver, maxNetAddressV2AddrSize, "network address")
+	if err != nil {
+		return err
+	}
+	if size, ok := NetAddressType(netType).AddrSize(); ok && len(addr) != size {
+		msg := fmt.Sprintf("network address of type %v is %d bytes instead "+
+			"of the required %d bytes", NetAddressType(netType), len(addr),
+			size)
+		return messageError(op, ErrInvalidMsg, msg)
+	}
+
+	port, err := binarySerializer.Uint16(r, bigEndian)
+	if err != nil {
+		return err
+	}
+
+	na.Services = ServiceFlag(services)
+	na.Type = NetAddressType(netType)
+	na.Addr = addr
+	na.Port = port
+	return nil
+}
+
+// writeNetAddressV2 serializes a NetAddressV2 to w.  An error is returned when
+// the address of a known network does not have the size required by that
+// network.
+func writeNetAddressV2(op string, w io.Writer, pver uint32, na *NetAddressV2) error {
+	if size, ok := na.Type.AddrSize(); ok && len(na.Addr) != size {
+		msg := fmt.Sprintf("network address of type %v is %d bytes instead "+
+			"of the required %d bytes", na.Type, len(na.Addr), size)
+		return messageError(op, ErrInvalidMsg, msg)
+	}
+	if len(na.Addr) > maxNetAddressV2AddrSize {
+		msg := fmt.Sprintf("network address is larger than the max allowed "+
+			"size [count %d, max %d]", len(na.Addr), maxNetAddressV2AddrSize)
+		return messageError(op, ErrVarBytesTooLong, msg)
+	}
+
+	err := writeElements(w, na.Timestamp.Unix(), uint64(na.Services),
+		uint8(na.Type))
+	if err != nil {
+		return err
+	}
+	if err := WriteVarBytes(w, pver, na.Addr); err != nil {
+		return err
+	}
+
+	// Sigh.  Decred protocol mixes little and big endian.
+	return binary.Write(w, bigEndian, na.Port)
+}
@@ -17,7 +17,7 @@ const (
 	InitialProcotolVersion uint32 = 1
 
 	// ProtocolVersion is the latest protocol version this package supports.
-	ProtocolVersion uint32 = 9
+	ProtocolVersion uint32 = 11
 
 	// NodeBloomVersion is the protocol version which added the SFNodeBloom
 	// service flag (unused).
@@ -51,6 +51,16 @@ const (
 	// RemoveRejectVersion is the protocol version which removes support for the
 	// reject message.
 	RemoveRejectVersion uint32 = 9
+
+	// CompactBlocksVersion is the protocol version which adds the sendcmpct,
+	// cmpctblock, getblocktxn, and blocktxn messages along with the
+	// cmpctblock inventory vector type.
+	CompactBlocksVersion uint32 = 10
+
+	// AddrV2Version is the protocol version which adds the addrv2 message
+	// that is used in place of the addr message to relay addresses on
+	// networks other than IPv4 and IPv6 such as Tor v3, I2P, and CJDNS.
+	AddrV2Version uint32 = 11
 )
 
 // ServiceFlag identifies services supported by a Decred peer.
@@ -67,6 +77,10 @@ const (
 	// SFNodeCF is a flag used to indicate a peer supports v1 gcs filters
 	// (CFs).
 	SFNodeCF
+
+	// SFNodeP2PV2 is a flag used to indicate a peer supports the encrypted
+	// and authenticated v2 P2P transport.
+	SFNodeP2PV2
 )
 
 // Map of service flags back to their constant names for pretty printing.
@@ -74,6 +88,7 @@ var sfStrings = map[ServiceFlag]string{
 	SFNodeNetwork: "SFNodeNetwork",
 	SFNodeBloom:   "SFNodeBloom",
 	SFNodeCF:      "SFNodeCF",
+	SFNodeP2PV2:   "SFNodeP2PV2",
 }
 
 // orderedSFStrings is an ordered list of service flags from highest to
@@ -82,6 +97,7 @@ var orderedSFStrings = []ServiceFlag{
 	SFNodeNetwork,
 	SFNodeBloom,
 	SFNodeCF,
+	SFNodeP2PV2,
 }
 
 // String returns the ServiceFlag in human-readable form.